@@ -1,34 +1,539 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"net"
+	"net/http"
+	_ "net/http/pprof"
 	"os"
+	"strconv"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/term"
 
 	"diffman/internal/app"
+	"diffman/internal/debuglog"
+	gitint "diffman/internal/git"
+	"diffman/internal/util"
 )
 
 func main() {
+	if err := gitint.AbsoluteGitEnv(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to resolve GIT_DIR/GIT_WORK_TREE: %v\n", err)
+		os.Exit(1)
+	}
+	if len(os.Args) > 1 && os.Args[1] == "pr" {
+		os.Exit(withDebugLog("", func() int { return runPR(os.Args[2:]) }))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "comment" {
+		os.Exit(withDebugLog("", func() int { return runComment(os.Args[2:]) }))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "clean" {
+		os.Exit(withDebugLog("", func() int { return runClean(os.Args[2:]) }))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "check" {
+		os.Exit(withDebugLog("", func() int { return runCheck(os.Args[2:]) }))
+	}
+
 	var prMode bool
 	var prRef string
+	var plain bool
+	var export bool
+	var output string
+	var exportSettings app.ExportSettings
+	var rpc bool
+	var repo string
+	var recentRepos bool
+	var logFile string
+	var pprofAddr string
+	var startAt string
+	var printDiff bool
+	var pager bool
+	var noIndex bool
+	var width int
 	flag.BoolVar(&prMode, "pr", false, "Launch in GitHub PR mode (open PR picker)")
 	flag.StringVar(&prRef, "pr-ref", "", "GitHub pull request number or URL")
+	flag.BoolVar(&plain, "plain", false, "Use plain ASCII rendering without colors or box-drawing borders")
+	flag.BoolVar(&export, "export", false, "Print the comment export without starting the UI")
+	flag.StringVar(&output, "output", "", "With -export, write the export to this file instead of stdout")
+	flag.StringVar(&exportSettings.Format, "format", app.ExportFormatPlain, "With -export, the export format: plain or quickfix (path:line:col: message)")
+	flag.BoolVar(&exportSettings.Redact, "redact", false, "With -export, leave out the code context and keep only each comment's location and body")
+	flag.StringVar(&exportSettings.Group, "group", "", "With -export, list comments under a heading per file or severity, or by time written; overrides export_group in the config")
+	flag.BoolVar(&exportSettings.NoContext, "no-context", false, "With -export, leave the code block out under each comment")
+	flag.StringVar(&repo, "repo", "", "Review the repository containing this directory instead of the current one")
+	flag.BoolVar(&recentRepos, "recent", false, "Open the repository switcher at startup; outside a repository, start in the most recently opened one")
+	flag.BoolVar(&rpc, "rpc", false, "Serve JSON-RPC 2.0 on stdin/stdout for editor integrations instead of starting the UI")
+	flag.StringVar(&logFile, "log-file", "", "Append debug logs (git commands, parse and render timings, errors) to this file; see also "+debuglog.EnvVar)
+	flag.StringVar(&pprofAddr, "pprof", "", "Serve Go pprof profiling endpoints on this loopback address (e.g. localhost:6060) while the UI runs")
+	flag.BoolVar(&pager, "pager", false, "Print the diffs to stdout as when stdout is not a terminal, even on one; combine with -plain for ASCII")
+	flag.BoolVar(&printDiff, "print", false, "Print the diffs side by side as plain text without starting the UI, e.g. for CI logs")
+	flag.BoolVar(&noIndex, "no-index", false, "Print the differences between two files or directories, which need not be in a repository, as git diff --no-index does")
+	flag.IntVar(&width, "width", 0, "Width in columns of printed diffs; defaults to the terminal's, $COLUMNS, or 120")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: diffman [flags] [path[:line[:col]]]\n       diffman [flags] <path>...\n       diffman -no-index <old> <new>\n")
+		flag.PrintDefaults()
+	}
+	flag.StringVar(&startAt, "start", "", "Where to open: files (first changed file), comment (first file with comments), stale (first stale comment), or resume (last position); overrides start_at in the config")
 	flag.Parse()
-	if prRef != "" {
-		prMode = true
+	if noIndex {
+		if flag.NArg() != 2 {
+			flag.Usage()
+			os.Exit(2)
+		}
+		os.Exit(withDebugLog(logFile, func() int {
+			return runNoIndex(flag.Arg(0), flag.Arg(1), app.PagerOptions{Width: width, Plain: plain})
+		}))
 	}
+	if width < 0 {
+		fmt.Fprintf(os.Stderr, "invalid -width %d\n", width)
+		os.Exit(2)
+	}
+	open, paths, err := app.ParseArgs(flag.Args())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid location: %v\n", err)
+		os.Exit(2)
+	}
+	if len(paths) > 0 && (rpc || export || output != "" || printDiff) {
+		fmt.Fprintf(os.Stderr, "path arguments only limit the review UI\n")
+		os.Exit(2)
+	}
+	os.Exit(withDebugLog(logFile, func() int {
+		if rpc {
+			return runRPC(repo)
+		}
+		if export || output != "" {
+			return runExport(repo, output, exportSettings)
+		}
+		if printDiff {
+			diff, _ := pagerInput(true)
+			return runPager(repo, open.Path, diff, app.PagerOptions{Width: width, Plain: true, SideBySide: true})
+		}
+		if !prMode && prRef == "" && !recentRepos {
+			if diff, ok := pagerInput(pager); ok {
+				return runPager(repo, open.Path, diff, app.PagerOptions{Width: width, Plain: plain})
+			}
+		}
+		if prRef != "" {
+			prMode = true
+		}
+		if pprofAddr != "" {
+			addr, err := startPprof(pprofAddr)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "failed to start pprof: %v\n", err)
+				return 1
+			}
+			pprofAddr = addr
+		}
+		return runUI(app.Options{Repo: repo, PR: prRef, PRPicker: prMode && prRef == "", Plain: plain, Recent: recentRepos, PprofAddr: pprofAddr, Open: open, StartAt: startAt, Paths: paths})
+	}))
+}
 
-	model, err := app.NewModelWithOptions(app.Options{PR: prRef, PRPicker: prMode && prRef == ""})
+// withDebugLog runs fn with debug logging to path, or to the file named by
+// DIFFMAN_DEBUG when path is empty, and returns fn's exit code.
+func withDebugLog(path string, fn func() int) int {
+	if path == "" {
+		var err error
+		if path, err = debuglog.PathFromEnv(); err != nil {
+			fmt.Fprintf(os.Stderr, "debug log disabled: %v\n", err)
+		}
+	}
+	if path == "" {
+		return fn()
+	}
+	if err := debuglog.Open(path); err != nil {
+		fmt.Fprintf(os.Stderr, "debug log disabled: %v\n", err)
+		return fn()
+	}
+	debuglog.Printf("args: %q", os.Args[1:])
+	code := fn()
+	debuglog.Printf("exit %d", code)
+	_ = debuglog.Close()
+	return code
+}
+
+func runUI(opts app.Options) int {
+	model, err := app.NewModelWithOptions(opts)
+	var notRepo *app.NotRepoError
+	if errors.As(err, &notRepo) {
+		debuglog.Printf("initialize: %v", err)
+		return runStartScreen(notRepo.Dir, opts)
+	}
 	if err != nil {
+		debuglog.Printf("initialize failed: %v", err)
 		fmt.Fprintf(os.Stderr, "failed to initialize app: %v\n", err)
-		os.Exit(1)
+		return 1
 	}
 
 	program := tea.NewProgram(model, tea.WithAltScreen())
-	if _, err := program.Run(); err != nil {
+	final, err := program.Run()
+	if err != nil {
+		debuglog.Printf("application error: %v", err)
 		fmt.Fprintf(os.Stderr, "application error: %v\n", err)
-		os.Exit(1)
+		return 1
+	}
+	if m, ok := final.(app.Model); ok {
+		if err := m.SaveSessionPosition(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to save session state: %v\n", err)
+		}
+		if done, err := m.ExportOnQuit(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to export comments on quit: %v\n", err)
+		} else if done != "" {
+			fmt.Fprintln(os.Stderr, done)
+		}
+		if report, err := m.CrashReport(); err != nil {
+			fmt.Fprintf(os.Stderr, "diffman crashed and %v\n", err)
+			return 1
+		} else if report != "" {
+			fmt.Fprintf(os.Stderr, "diffman crashed; details and any unsaved comment text are in %s\n", report)
+			return 1
+		}
+	}
+	return 0
+}
+
+// runStartScreen asks what to do when diffman starts outside a repository:
+// review another repository, compare two paths, or quit.
+func runStartScreen(dir string, opts app.Options) int {
+	final, err := tea.NewProgram(app.NewStartScreen(dir), tea.WithAltScreen()).Run()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "application error: %v\n", err)
+		return 1
+	}
+	choice := final.(app.StartScreen).Choice()
+	switch {
+	case choice.Repo != "":
+		opts.Repo = choice.Repo
+		return runUI(opts)
+	case choice.OldPath != "":
+		return runNoIndex(choice.OldPath, choice.NewPath, app.PagerOptions{Plain: opts.Plain})
+	}
+	return 0
+}
+
+// runNoIndex prints the differences between two paths outside any
+// repository the way the diff panes draw them.
+func runNoIndex(oldPath, newPath string, opts app.PagerOptions) int {
+	d, err := gitint.DiffNoIndex(context.Background(), oldPath, newPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "diff failed: %v\n", err)
+		return 1
+	}
+	if d == "" {
+		fmt.Println("No differences.")
+		return 0
+	}
+	return runPager("", "", strings.NewReader(d), opts)
+}
+
+// pagerInput reports whether diffman should print diffs instead of starting
+// the UI: when force is set (-pager), stdout is not a terminal, or a diff is
+// piped in. It returns the piped diff, or nil to print the repository's
+// changes.
+func pagerInput(force bool) (io.Reader, bool) {
+	return choosePagerInput(os.Stdin, term.IsTerminal(os.Stdout.Fd()), force)
+}
+
+func choosePagerInput(stdin *os.File, stdoutTerminal, force bool) (io.Reader, bool) {
+	if !term.IsTerminal(stdin.Fd()) {
+		if info, err := stdin.Stat(); err == nil && (info.Mode()&os.ModeNamedPipe != 0 || info.Mode().IsRegular()) {
+			raw, err := io.ReadAll(stdin)
+			if err == nil && len(bytes.TrimSpace(raw)) > 0 {
+				return bytes.NewReader(raw), true
+			}
+		}
+	}
+	if force || !stdoutTerminal {
+		return nil, true
+	}
+	return nil, false
+}
+
+// runPager prints diff, or the repository's changes when diff is nil, to
+// stdout the way the diff panes draw them. A zero opts.Width is filled in
+// from the terminal.
+func runPager(repo, path string, diff io.Reader, opts app.PagerOptions) int {
+	cwd, err := gitint.StartDir(repo)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "diff failed: %v\n", err)
+		return 1
+	}
+	opts.Diff, opts.Repo, opts.Path = diff, cwd, path
+	if opts.Width == 0 {
+		opts.Width = pagerWidth()
+	}
+	w := bufio.NewWriter(os.Stdout)
+	err = app.WriteDiff(context.Background(), w, opts)
+	if flushErr := w.Flush(); err == nil {
+		err = flushErr
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "diff failed: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// pagerWidth is the terminal's width when stdout or stderr is one, then
+// $COLUMNS, then 120 columns.
+func pagerWidth() int {
+	for _, f := range []*os.File{os.Stdout, os.Stderr} {
+		if w, _, err := term.GetSize(f.Fd()); err == nil && w > 0 {
+			return w
+		}
+	}
+	if w, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && w > 0 {
+		return w
+	}
+	return 120
+}
+
+// startPprof serves net/http/pprof on addr in the background and returns
+// the address it listens on.
+func startPprof(addr string) (string, error) {
+	addr, err := pprofListenAddr(addr)
+	if err != nil {
+		return "", err
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return "", err
+	}
+	go func() {
+		if err := http.Serve(ln, nil); err != nil {
+			debuglog.Printf("pprof server stopped: %v", err)
+		}
+	}()
+	debuglog.Printf("pprof listening on %s", ln.Addr())
+	return ln.Addr().String(), nil
+}
+
+// pprofListenAddr keeps pprof on the loopback interface: its heap dumps and
+// goroutine stacks hold comment text. An empty host means 127.0.0.1.
+func pprofListenAddr(addr string) (string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case host == "":
+		host = "127.0.0.1"
+	case host == "localhost":
+	default:
+		if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+			return "", fmt.Errorf("%s is not a loopback address; use localhost:%s", host, port)
+		}
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// runPR handles "diffman pr <number|url>": check out the PR branch with gh
+// and review it against its base branch.
+func runPR(args []string) int {
+	fs := flag.NewFlagSet("pr", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: diffman pr [flags] <number|url>\n")
+		fs.PrintDefaults()
+	}
+	checkout := fs.Bool("checkout", true, "Check out the PR branch with gh before opening it; refused with uncommitted changes unless given explicitly")
+	importComments := fs.Bool("comments", false, "Import the PR's existing review comments")
+	repo := fs.String("repo", "", "Check out and review in the repository containing this directory")
+	plain := fs.Bool("plain", false, "Use plain ASCII rendering without colors or box-drawing borders")
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	explicit := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "checkout" {
+			explicit = true
+		}
+	})
+	if *checkout && !explicit {
+		// gh pr checkout carries uncommitted changes onto the PR branch or
+		// fails halfway, so only do it on a clean tree unless asked to.
+		if cwd, err := gitint.StartDir(*repo); err == nil {
+			if items, err := gitint.NewStatusService().ListChangedFiles(context.Background(), cwd); err == nil && len(items) > 0 {
+				fmt.Fprintf(os.Stderr, "diffman pr: %d uncommitted change(s); commit or stash them, pass -checkout to check out anyway, or -checkout=false to review without switching branches\n", len(items))
+				return 1
+			}
+		}
+	}
+	return runUI(app.Options{Repo: *repo, PR: fs.Arg(0), Checkout: *checkout, ImportComments: *importComments, Plain: *plain})
+}
+
+// runComment handles "diffman comment add": store a review comment without
+// starting the UI, for scripts, linters, and editor plugins.
+func runComment(args []string) int {
+	fs := flag.NewFlagSet("comment add", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: diffman comment add -file F -line N [-side new|old] -body TEXT\n")
+		fs.PrintDefaults()
+	}
+	if len(args) == 0 || args[0] != "add" {
+		fs.Usage()
+		return 2
+	}
+	file := fs.String("file", "", "File to comment on, absolute or relative to the current directory")
+	line := fs.Int("line", 0, "Line number in the file on the chosen side")
+	side := fs.String("side", "new", "Side of the diff the line number refers to: new or old")
+	body := fs.String("body", "", "Comment text; - reads it from stdin")
+	mode := fs.String("mode", "all", "Diff the line must appear in: all, unstaged, or staged")
+	repo := fs.String("repo", "", "Add the comment in the repository containing this directory")
+	_ = fs.Parse(args[1:])
+	if fs.NArg() != 0 || *file == "" || *line <= 0 {
+		fs.Usage()
+		return 2
+	}
+	text := *body
+	if text == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "comment add failed: %v\n", err)
+			return 1
+		}
+		text = string(data)
+	}
+
+	cwd, err := gitint.StartDir(*repo)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "comment add failed: %v\n", err)
+		return 1
+	}
+	// -file is relative to where the command runs, not to -repo.
+	wd, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "comment add failed: %v\n", err)
+		return 1
+	}
+	c, err := app.AddComment(context.Background(), cwd, app.CommentInput{File: *file, Dir: wd, Line: *line, Side: *side, Body: text, Mode: *mode})
+	if err != nil {
+		if c.Path != "" {
+			fmt.Fprintf(os.Stderr, "comment saved, but %v\n", err)
+		} else {
+			fmt.Fprintf(os.Stderr, "comment add failed: %v\n", err)
+		}
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "Added comment on %s:%d (%s)\n", c.Path, c.Line, c.Side)
+	return 0
+}
+
+// runClean handles "diffman clean": prune stale comments and leftover state
+// files, for scripts and post-merge hooks.
+func runClean(args []string) int {
+	fs := flag.NewFlagSet("clean", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: diffman clean [-dry-run] [-repo DIR]\n")
+		fs.PrintDefaults()
+	}
+	dryRun := fs.Bool("dry-run", false, "Report what would be removed without changing anything")
+	repo := fs.String("repo", "", "Clean the repository containing this directory")
+	_ = fs.Parse(args)
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return 2
+	}
+
+	cwd, err := gitint.StartDir(*repo)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "clean failed: %v\n", err)
+		return 1
+	}
+	if _, err := app.Clean(context.Background(), cwd, os.Stdout, *dryRun); err != nil {
+		fmt.Fprintf(os.Stderr, "clean failed: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// runCheck handles "diffman check": list open follow-up comments that have
+// waited too long. It exits 1 when there are any, for shell prompts and
+// scheduled jobs.
+func runCheck(args []string) int {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: diffman check [-days N] [-repo DIR]\n")
+		fs.PrintDefaults()
+	}
+	days := fs.Int("days", -1, "Report follow-ups at least this many days old; defaults to follow_up_days in the config")
+	repo := fs.String("repo", "", "Check the repository containing this directory")
+	_ = fs.Parse(args)
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return 2
+	}
+
+	cwd, err := gitint.StartDir(*repo)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "check failed: %v\n", err)
+		return 1
+	}
+	count, err := app.Check(context.Background(), cwd, os.Stdout, *days)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "check failed: %v\n", err)
+		return 1
+	}
+	if count > 0 {
+		return 1
+	}
+	return 0
+}
+
+// runExport writes the export headlessly and returns the process exit code.
+func runExport(repo, output string, settings app.ExportSettings) int {
+	if !app.IsExportFormat(settings.Format) {
+		fmt.Fprintf(os.Stderr, "export failed: unknown format %q (want plain or quickfix)\n", settings.Format)
+		return 2
+	}
+	cwd, err := gitint.StartDir(repo)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "export failed: %v\n", err)
+		return 1
+	}
+
+	// Render the whole export first so a failed one leaves an existing
+	// output file alone.
+	toFile := output != "" && output != "-"
+	var w io.Writer = os.Stdout
+	var buf bytes.Buffer
+	dest := ""
+	if toFile {
+		w = &buf
+		dest = output
+	}
+	count, err := app.ExportComments(context.Background(), cwd, w, dest, settings)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "export failed: %v\n", err)
+		return 1
+	}
+	if toFile {
+		if err := util.WriteFileAtomic(output, buf.Bytes(), 0o644); err != nil {
+			fmt.Fprintf(os.Stderr, "export failed: %v\n", err)
+			return 1
+		}
+		fmt.Fprintf(os.Stderr, "Wrote %d comment(s) to %s\n", count, output)
+	}
+	return 0
+}
+
+// runRPC serves JSON-RPC on stdio until stdin closes or a shutdown request arrives.
+func runRPC(repo string) int {
+	cwd, err := gitint.StartDir(repo)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "rpc server error: %v\n", err)
+		return 1
+	}
+	if err := app.ServeRPC(context.Background(), cwd, os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "rpc server error: %v\n", err)
+		return 1
 	}
+	return 0
 }
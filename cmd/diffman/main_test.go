@@ -0,0 +1,59 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"diffman/internal/app"
+)
+
+func TestChoosePagerInput(t *testing.T) {
+	open := func(content string) *os.File {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "stdin")
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { f.Close() })
+		return f
+	}
+
+	if diff, ok := choosePagerInput(open(""), true, false); ok || diff != nil {
+		t.Fatalf("expected the UI on a terminal without -pager or piped input")
+	}
+	if diff, ok := choosePagerInput(open(""), true, true); !ok || diff != nil {
+		t.Fatalf("expected -pager to print the repository's changes on a terminal, got ok=%v diff=%v", ok, diff)
+	}
+	if diff, ok := choosePagerInput(open(""), false, false); !ok || diff != nil {
+		t.Fatalf("expected pager output when stdout is not a terminal")
+	}
+	diff, ok := choosePagerInput(open("diff --git a/x b/x\n"), true, false)
+	if !ok || diff == nil {
+		t.Fatalf("expected a piped diff to be printed")
+	}
+	if raw, _ := io.ReadAll(diff); string(raw) != "diff --git a/x b/x\n" {
+		t.Fatalf("unexpected piped diff %q", raw)
+	}
+}
+
+func TestRunExportKeepsOutputOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("GIT_CEILING_DIRECTORIES", dir)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	output := filepath.Join(t.TempDir(), "review.txt")
+	if err := os.WriteFile(output, []byte("earlier export\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if code := runExport(dir, output, app.ExportSettings{Format: app.ExportFormatPlain}); code != 1 {
+		t.Fatalf("expected the export outside a repository to fail, got exit code %d", code)
+	}
+	if data, err := os.ReadFile(output); err != nil || string(data) != "earlier export\n" {
+		t.Fatalf("expected the earlier export left alone, got %q (err=%v)", data, err)
+	}
+}
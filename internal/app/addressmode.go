@@ -0,0 +1,221 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"diffman/internal/comments"
+	"diffman/internal/diffview"
+)
+
+// addressState walks review feedback one comment at a time, for responding
+// to a review instead of writing one.
+type addressState struct {
+	// keys are the comments that were open when the mode started, in file
+	// order; resolving one keeps its place so n/p can come back to it.
+	keys []string
+	pos  int
+	// resolving is the resolution the reply dock is asking a reply for.
+	resolving string
+	input     textinput.Model
+}
+
+func newReplyInput() textinput.Model {
+	input := textinput.New()
+	input.Prompt = ""
+	input.Placeholder = "reply (optional)"
+	input.CharLimit = 4096
+	input.Cursor.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("51"))
+	input.PlaceholderStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+	return input
+}
+
+// startAddressMode opens the first open comment, mine or imported.
+func (m Model) startAddressMode() (tea.Model, tea.Cmd) {
+	var keys []string
+	for _, c := range m.sortedComments() {
+		if c.Resolution == "" {
+			keys = append(keys, commentKey(c))
+		}
+	}
+	if len(keys) == 0 {
+		m.setAlert("No open comments to address.")
+		return m, nil
+	}
+	m.address = &addressState{keys: keys, input: newReplyInput()}
+	return m, m.showAddressComment()
+}
+
+// addressComment is the comment address mode is on, if it still exists.
+func (m Model) addressComment() (comments.Comment, bool) {
+	if m.address == nil {
+		return comments.Comment{}, false
+	}
+	c, ok := m.comments[m.address.keys[m.address.pos]]
+	return c, ok
+}
+
+// showAddressComment opens the diff at the current comment. A stale comment
+// opens its stale popup instead, as it does from the comments view.
+func (m *Model) showAddressComment() tea.Cmd {
+	c, ok := m.addressComment()
+	if !ok {
+		return nil
+	}
+	if m.isCommentStale(c) {
+		m.openStalePopup(c)
+		return nil
+	}
+	return m.jumpToCommentInDiff(c)
+}
+
+// handleAddressKey handles the keys address mode adds; others fall through
+// to the panes, so the diff can still be read around the comment.
+func (m Model) handleAddressKey(msg tea.KeyMsg) (tea.Model, tea.Cmd, bool) {
+	switch {
+	case msg.Type == tea.KeyEsc, key.Matches(msg, m.keys.AddressMode):
+		m.leaveAddressMode()
+		return m, nil, true
+	case key.Matches(msg, m.keys.NextComment):
+		m.address.pos = (m.address.pos + 1) % len(m.address.keys)
+		return m, m.showAddressComment(), true
+	case key.Matches(msg, m.keys.PrevComment):
+		m.address.pos = (m.address.pos + len(m.address.keys) - 1) % len(m.address.keys)
+		return m, m.showAddressComment(), true
+	case isRuneKey(msg, "a"), isRuneKey(msg, "x"):
+		c, ok := m.addressComment()
+		if !ok {
+			m.setAlert("This comment was deleted.")
+			return m, nil, true
+		}
+		m.address.resolving = comments.ResolutionAddressed
+		if isRuneKey(msg, "x") {
+			m.address.resolving = comments.ResolutionWontFix
+		}
+		m.address.input.SetValue(c.Reply)
+		cmd := m.address.input.Focus()
+		m.address.input.CursorEnd()
+		return m, cmd, true
+	case isRuneKey(msg, "u"):
+		m.resolveAddressComment("", "")
+		return m, nil, true
+	}
+	return m, nil, false
+}
+
+// handleAddressReply reads the optional reply; Enter resolves the comment
+// and moves on to the next open one.
+func (m Model) handleAddressReply(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.address.resolving = ""
+		m.address.input.Blur()
+		return m, nil
+	case tea.KeyEnter:
+		resolution := m.address.resolving
+		m.address.resolving = ""
+		m.address.input.Blur()
+		if !m.resolveAddressComment(resolution, strings.TrimSpace(m.address.input.Value())) {
+			return m, nil
+		}
+		return m, m.nextOpenAddressComment()
+	}
+	var cmd tea.Cmd
+	m.address.input, cmd = m.address.input.Update(msg)
+	return m, cmd
+}
+
+// resolveAddressComment saves resolution and reply on the current comment;
+// an empty resolution reopens it.
+func (m *Model) resolveAddressComment(resolution, reply string) bool {
+	key := m.address.keys[m.address.pos]
+	c, ok := m.comments[key]
+	if !ok {
+		m.setAlert("This comment was deleted.")
+		return false
+	}
+	c.Resolution, c.Reply = resolution, reply
+	m.comments[key] = c
+	if err := m.persistComments(); err != nil {
+		m.setAlert(fmt.Sprintf("failed to save comments: %v", err))
+		return false
+	}
+	return true
+}
+
+// nextOpenAddressComment moves to the next comment still open, and leaves
+// address mode once every one has been resolved.
+func (m *Model) nextOpenAddressComment() tea.Cmd {
+	n := len(m.address.keys)
+	for step := 1; step <= n; step++ {
+		pos := (m.address.pos + step) % n
+		if c, ok := m.comments[m.address.keys[pos]]; ok && c.Resolution == "" {
+			m.address.pos = pos
+			return m.showAddressComment()
+		}
+	}
+	m.leaveAddressMode()
+	return nil
+}
+
+func (m *Model) leaveAddressMode() {
+	resolved := 0
+	for _, key := range m.address.keys {
+		if c, ok := m.comments[key]; ok && c.Resolution != "" {
+			resolved++
+		}
+	}
+	m.setAlert(fmt.Sprintf("Resolved %d of %d comment(s).", resolved, len(m.address.keys)))
+	m.address = nil
+}
+
+func (m Model) renderAddressDock() string {
+	contentW := max(10, m.width-2)
+	bodyInnerW := max(1, contentW-4)
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+	title := fmt.Sprintf("Address Feedback %d/%d", m.address.pos+1, len(m.address.keys))
+
+	c, ok := m.addressComment()
+	if !ok {
+		body := strings.Join([]string{"This comment was deleted.", "", dim.Render("n/p next/prev | esc leave")}, "\n")
+		return m.renderDockPanel(title, lipgloss.Color("141"), lipgloss.Color("141"), body)
+	}
+	location := fmt.Sprintf("%s %s:%d", c.Path, c.Side.String(), c.Line)
+	if c.Author != "" {
+		location += " @" + c.Author
+	}
+	if label := comments.ResolutionLabel(c.Resolution); label != "" {
+		location += " [" + label + "]"
+	}
+	lines := []string{ansi.Truncate(location, bodyInnerW, "…")}
+	for _, line := range strings.Split(strings.TrimSpace(c.Body), "\n") {
+		lines = append(lines, ansi.Truncate(line, bodyInnerW, "…"))
+	}
+	if m.address.resolving != "" {
+		input := m.address.input
+		input.Width = max(1, bodyInnerW-4)
+		lines = append(lines, "", lipgloss.NewStyle().
+			Width(bodyInnerW).
+			MaxWidth(bodyInnerW).
+			Border(diffview.Border(lipgloss.NormalBorder())).
+			BorderForeground(lipgloss.Color("141")).
+			Padding(0, 1).
+			Render(input.View()))
+		lines = append(lines, "", dim.Render(ansi.Truncate("Enter mark "+comments.ResolutionLabel(m.address.resolving)+" (reply optional) | Esc cancel", bodyInnerW, "")))
+	} else {
+		if c.Reply != "" {
+			lines = append(lines, dim.Render(ansi.Truncate("Reply: "+c.Reply, bodyInnerW, "…")))
+		}
+		lines = append(lines, "", dim.Render(ansi.Truncate("a addressed | x won't fix | u reopen | n/p next/prev | esc leave", bodyInnerW, "")))
+	}
+	if m.alertMsg != "" {
+		lines = append(lines, lipgloss.NewStyle().Foreground(lipgloss.Color("220")).Render(ansi.Truncate(m.alertMsg, bodyInnerW, "…")))
+	}
+	return m.renderDockPanel(title, lipgloss.Color("141"), lipgloss.Color("141"), strings.Join(lines, "\n"))
+}
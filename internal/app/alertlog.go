@@ -0,0 +1,109 @@
+package app
+
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"diffman/internal/diffview"
+)
+
+const alertLogLimit = 200
+
+type alertLogEntry struct {
+	At  time.Time
+	Msg string
+}
+
+func (m *Model) appendAlertLog(msg string) {
+	m.alertLog = append(m.alertLog, alertLogEntry{At: time.Now(), Msg: msg})
+	if len(m.alertLog) > alertLogLimit {
+		m.alertLog = append([]alertLogEntry(nil), m.alertLog[len(m.alertLog)-alertLogLimit:]...)
+	}
+}
+
+func (m Model) handleAlertLog(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	page := m.alertLogPageSize()
+	// Entries wrap over several lines, so scroll by entry down to the oldest.
+	maxScroll := max(0, len(m.alertLog)-1)
+	switch {
+	case msg.Type == tea.KeyEsc, isRuneKey(msg, "q"), key.Matches(msg, m.keys.AlertLog):
+		m.alertLogOpen = false
+	case key.Matches(msg, m.keys.Down), key.Matches(msg, m.keys.ScrollDown):
+		m.alertLogScroll++
+	case key.Matches(msg, m.keys.Up), key.Matches(msg, m.keys.ScrollUp):
+		m.alertLogScroll--
+	case key.Matches(msg, m.keys.PageDown):
+		m.alertLogScroll += max(1, page-1)
+	case key.Matches(msg, m.keys.PageUp):
+		m.alertLogScroll -= max(1, page-1)
+	case key.Matches(msg, m.keys.Top):
+		m.alertLogScroll = 0
+	case key.Matches(msg, m.keys.Bottom):
+		m.alertLogScroll = maxScroll
+	}
+	if m.alertLogScroll > maxScroll {
+		m.alertLogScroll = maxScroll
+	}
+	if m.alertLogScroll < 0 {
+		m.alertLogScroll = 0
+	}
+	return m, nil
+}
+
+func (m Model) alertLogPageSize() int {
+	return max(1, m.height*2/3-6)
+}
+
+func (m Model) renderAlertLogModal() string {
+	width := max(24, m.width-10)
+	innerW := max(1, width-6)
+	page := m.alertLogPageSize()
+
+	lines := make([]string, 0, page+2)
+	if len(m.alertLog) == 0 {
+		lines = append(lines, "No notices yet.")
+	}
+	// Newest first, so the most recent failure is at the top. Messages are
+	// wrapped in full under their time, since the status line cut them.
+	timeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+	for i := len(m.alertLog) - 1 - m.alertLogScroll; i >= 0 && len(lines) < page; i-- {
+		entry := m.alertLog[i]
+		stamp := entry.At.Format("15:04:05")
+		indent := strings.Repeat(" ", len(stamp)+1)
+		wrapped := lipgloss.NewStyle().Width(max(1, innerW-len(stamp)-1)).Render(entry.Msg)
+		for j, line := range strings.Split(wrapped, "\n") {
+			prefix := indent
+			if j == 0 {
+				prefix = timeStyle.Render(stamp) + " "
+			}
+			lines = append(lines, prefix+strings.TrimRight(line, " "))
+		}
+	}
+	if len(lines) > page {
+		lines = lines[:page]
+	}
+	lines = append(lines, "", lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Render("j/k scroll | g/G newest/oldest | L/Esc close"))
+
+	title := lipgloss.NewStyle().
+		Width(max(1, width-2)).
+		Padding(0, 1).
+		Bold(true).
+		Foreground(lipgloss.Color("230")).
+		Background(lipgloss.Color("220")).
+		Render("Notice Log")
+
+	bodyBlock := lipgloss.NewStyle().
+		Width(max(1, width-2)).
+		Padding(1, 2).
+		Render(strings.Join(lines, "\n"))
+
+	return lipgloss.NewStyle().
+		Width(width).
+		Border(diffview.Border(lipgloss.RoundedBorder())).
+		BorderForeground(lipgloss.Color("220")).
+		Render(title + "\n" + bodyBlock)
+}
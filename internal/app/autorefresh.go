@@ -0,0 +1,98 @@
+package app
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"diffman/internal/debuglog"
+)
+
+// autoRefreshMsg asks for a background refresh of the file list.
+type autoRefreshMsg struct{}
+
+// autoRefreshCmd schedules the next background refresh, or returns nil when
+// refresh_seconds is unset.
+func (m Model) autoRefreshCmd() tea.Cmd {
+	if m.refreshInterval <= 0 {
+		return nil
+	}
+	return tea.Tick(m.refreshInterval, func(time.Time) tea.Msg {
+		return autoRefreshMsg{}
+	})
+}
+
+// handleAutoRefresh lists the changed files again unless a load is already
+// running or the review is not of the working tree.
+func (m Model) handleAutoRefresh() (tea.Model, tea.Cmd) {
+	next := m.autoRefreshCmd()
+	if m.reviewMode != reviewModeLocal || m.loadingFiles || m.autoRefreshing {
+		return m, next
+	}
+	m.autoRefreshing = true
+	cwd := m.cwd
+	service := m.statusSvc
+	return m, tea.Batch(next, m.loadFileStatsCmd(), m.deltaFilterCmd(func() filesLoadedMsg {
+		items, err := service.ListChangedFiles(context.Background(), cwd)
+		return filesLoadedMsg{items: items, err: err, background: true}
+	}))
+}
+
+// mergeBackgroundFiles applies a background file listing without moving the
+// file cursor or reloading the open diff. Only when the selected file is
+// gone is the listing handled like a manual refresh.
+func (m Model) mergeBackgroundFiles(msg filesLoadedMsg) (tea.Model, tea.Cmd) {
+	m.autoRefreshing = false
+	if msg.err != nil {
+		debuglog.Printf("background refresh failed: %v", msg.err)
+		return m, nil
+	}
+	if indexOfFilePath(msg.items, m.selectedF) < 0 {
+		msg.background = false
+		return m.update(msg)
+	}
+	if !reflect.DeepEqual(msg.items, m.fileItems) || msg.deltaHidden != m.deltaHidden {
+		cursor := m.fileCursorAnchor()
+		m.fileItems = msg.items
+		m.loadFileMTimes()
+		m.deltaAllItems = msg.all
+		m.deltaHidden = msg.deltaHidden
+		m.followRenamedComments(m.staleCheckItems())
+		m.selected = indexOfFilePath(m.fileItems, m.selectedF)
+		m.restoreFileCursor(cursor)
+	}
+	return m, m.loadCommentStaleCmd(m.staleCheckItems(), m.comments, m.diffMode)
+}
+
+// fileCursorAnchor remembers the tree entry under the file cursor and how far
+// below the top of the pane it is.
+type fileCursorAnchor struct {
+	path  string
+	isDir bool
+	row   int
+}
+
+func (m Model) fileCursorAnchor() fileCursorAnchor {
+	entries := m.fileTreeEntries()
+	if m.fileCursor < 0 || m.fileCursor >= len(entries) {
+		return fileCursorAnchor{row: m.fileCursor - m.fileScroll}
+	}
+	e := entries[m.fileCursor]
+	return fileCursorAnchor{path: e.Path, isDir: e.IsDir, row: m.fileCursor - m.fileScroll}
+}
+
+// restoreFileCursor puts the file cursor back on the anchored entry at the
+// same height in the pane. When the entry is gone the cursor keeps its index.
+func (m *Model) restoreFileCursor(a fileCursorAnchor) {
+	entries := m.fileTreeEntries()
+	for i, e := range entries {
+		if e.Path == a.path && e.IsDir == a.isDir {
+			m.fileCursor = i
+			break
+		}
+	}
+	m.fileScroll = m.fileCursor - a.row
+	m.ensureFileCursorVisible(entries)
+}
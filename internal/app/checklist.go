@@ -0,0 +1,158 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"diffman/internal/diffview"
+)
+
+func (m Model) checklistDoneCount() int {
+	done := 0
+	for _, item := range m.checklist {
+		if m.checklistDone[item] {
+			done++
+		}
+	}
+	return done
+}
+
+// checkedItems lists ticked items in checklist order, for the session file.
+func (m Model) checkedItems() []string {
+	var out []string
+	for _, item := range m.checklist {
+		if m.checklistDone[item] {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+func (m Model) openChecklist() (tea.Model, tea.Cmd) {
+	if len(m.checklist) == 0 {
+		m.setAlert(`No review checklist configured. Add "checklist": ["Tests added?", ...] to the config.`)
+		return m, nil
+	}
+	m.checklistOpen = true
+	m.checklistCursor = min(m.checklistCursor, len(m.checklist)-1)
+	return m, nil
+}
+
+func (m Model) handleChecklist(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.Type == tea.KeyEsc, isRuneKey(msg, "q"), key.Matches(msg, m.keys.Checklist):
+		m.checklistOpen = false
+	case key.Matches(msg, m.keys.Down):
+		if m.checklistCursor < len(m.checklist)-1 {
+			m.checklistCursor++
+		}
+	case key.Matches(msg, m.keys.Up):
+		if m.checklistCursor > 0 {
+			m.checklistCursor--
+		}
+	case msg.Type == tea.KeySpace, msg.Type == tea.KeyEnter, isRuneKey(msg, "x"):
+		if len(m.checklist) == 0 {
+			return m, nil
+		}
+		item := m.checklist[m.checklistCursor]
+		if m.checklistDone == nil {
+			m.checklistDone = make(map[string]bool)
+		}
+		m.checklistDone[item] = !m.checklistDone[item]
+		m.saveSessionState()
+	}
+	return m, nil
+}
+
+// resetChecklist unticks every item, for a fresh review.
+func (m *Model) resetChecklist() {
+	if len(m.checklistDone) == 0 {
+		return
+	}
+	m.checklistDone = nil
+	m.saveSessionState()
+}
+
+// checklistText is the checklist as it appears in exports and the summary.
+func (m Model) checklistText() string {
+	if len(m.checklist) == 0 {
+		return ""
+	}
+	lines := []string{fmt.Sprintf("Checklist (%d/%d done):", m.checklistDoneCount(), len(m.checklist))}
+	for _, item := range m.checklist {
+		mark := "[ ]"
+		if m.checklistDone[item] {
+			mark = "[x]"
+		}
+		lines = append(lines, mark+" "+item)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// checklistStatusSegment is shown in the status bar while a checklist is configured.
+func (m Model) checklistStatusSegment() string {
+	if len(m.checklist) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("checklist %d/%d", m.checklistDoneCount(), len(m.checklist))
+}
+
+// exportSummary is appended to plain exports: the checklist when one is
+// configured, then the review statistics when the config asks for them.
+func (m Model) exportSummary() string {
+	parts := make([]string, 0, 2)
+	if text := m.checklistText(); text != "" {
+		parts = append(parts, text)
+	}
+	if text := m.exportReviewStats(); text != "" {
+		parts = append(parts, text)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+func (m Model) renderChecklistModal() string {
+	width := max(24, min(80, m.width-10))
+	innerW := max(1, width-6)
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+	doneStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("78"))
+
+	lines := make([]string, 0, len(m.checklist)+2)
+	for i, item := range m.checklist {
+		marker := "  "
+		style := lipgloss.NewStyle()
+		if i == m.checklistCursor {
+			marker = "> "
+			style = style.Foreground(lipgloss.Color("39")).Bold(true)
+		}
+		box := "[ ] "
+		if m.checklistDone[item] {
+			box = doneStyle.Render("[x] ")
+		}
+		lines = append(lines, style.Render(marker)+box+style.Render(ansi.Truncate(item, max(1, innerW-6), "…")))
+	}
+	lines = append(lines, "", dim.Render("j/k move | space/x toggle | T/Esc close"))
+
+	title := lipgloss.NewStyle().
+		Width(max(1, width-2)).
+		Padding(0, 1).
+		Bold(true).
+		Foreground(lipgloss.Color("230")).
+		Background(lipgloss.Color("63")).
+		Render(fmt.Sprintf("Review checklist (%d/%d)", m.checklistDoneCount(), len(m.checklist)))
+
+	bodyBlock := lipgloss.NewStyle().
+		Width(max(1, width-2)).
+		Padding(1, 2).
+		Render(strings.Join(lines, "\n"))
+
+	return lipgloss.NewStyle().
+		Width(width).
+		Border(diffview.Border(lipgloss.RoundedBorder())).
+		BorderForeground(lipgloss.Color("63")).
+		Render(title + "\n" + bodyBlock)
+}
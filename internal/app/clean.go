@@ -0,0 +1,159 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"diffman/internal/comments"
+	gitint "diffman/internal/git"
+	"diffman/internal/history"
+	"diffman/internal/session"
+)
+
+const (
+	// cleanTmpAge leaves temporary files alone while a running UI may
+	// still be writing them.
+	cleanTmpAge = time.Hour
+	// cleanCorruptAge keeps damaged comment files around long enough to
+	// recover anything from them by hand.
+	cleanCorruptAge = 30 * 24 * time.Hour
+)
+
+// Clean tidies the diffman state of the repository containing cwd without
+// starting the UI and writes what it did to w, one line per change:
+//
+//   - comments on files that no longer have changes, or whose line is gone
+//     from the diff, are archived to the review history and removed.
+//     Comments on a pull request or imported from one stay, since a PR's
+//     files are not changed locally; so do comments whose diff failed to
+//     load
+//   - the comments file is rewritten in sorted order, one comment per line
+//     of code
+//   - a draft (not one on a pull request) or resume position for a file
+//     that no longer has changes is dropped, with temporary files of
+//     interrupted writes and month-old copies of damaged comment files
+//
+// With dryRun it only reports. It returns how many things were removed.
+func Clean(ctx context.Context, cwd string, w io.Writer, dryRun bool) (int, error) {
+	s, err := newRPCServer(ctx, cwd)
+	if err != nil {
+		return 0, err
+	}
+	gitDir, err := gitint.DiscoverGitDir(ctx, s.root)
+	if err != nil {
+		return 0, err
+	}
+	all, err := s.store.Load()
+	if err != nil && !comments.IsRecovered(err) {
+		return 0, err
+	}
+	items, err := s.statusSvc.ListChangedFiles(ctx, s.root)
+	if err != nil {
+		return 0, fmt.Errorf("list changed files: %w", err)
+	}
+	reasons, err := buildCommentStaleReasons(ctx, s.root, s.diffSvc, items, all, gitint.DiffModeAll)
+	if err != nil {
+		return 0, fmt.Errorf("check comment anchors: %w", err)
+	}
+	changed := make(map[string]bool, len(items))
+	for _, it := range items {
+		changed[it.Path] = true
+	}
+
+	verb := "Removed"
+	if dryRun {
+		verb = "Would remove"
+	}
+	removed := 0
+	report := func(format string, args ...any) {
+		fmt.Fprintf(w, format+"\n", args...)
+	}
+
+	m := modelWithComments(all)
+	var pruned []comments.Comment
+	for _, c := range m.sortedComments() {
+		if c.Author != "" || c.PR != 0 {
+			continue
+		}
+		if r := reasons[commentKey(c)]; r == staleReasonLineGone || r == staleReasonFileUnchanged {
+			pruned = append(pruned, c)
+			delete(m.comments, commentKey(c))
+		}
+	}
+	if len(pruned) > 0 {
+		archived := ""
+		if !dryRun {
+			r, err := history.NewStore(gitDir).WithKey(s.key).Save(history.Review{Outcome: "pruned", Repo: s.root, Comments: pruned})
+			if err != nil {
+				return removed, fmt.Errorf("archive stale comments: %w", err)
+			}
+			archived = fmt.Sprintf(", archived as %s", r.ID)
+		}
+		report("%s %d stale comment(s)%s:", verb, len(pruned), archived)
+		for _, c := range pruned {
+			report("  %s %s:%d (%s)", c.Path, c.Side, c.Line, reasons[commentKey(c)])
+		}
+		removed += len(pruned)
+	}
+	if !dryRun && (len(all) > 0 || len(pruned) > 0) {
+		before := s.store.Size()
+		if err := s.store.Save(m.sortedComments()); err != nil {
+			return removed, err
+		}
+		if after := s.store.Size(); after != before {
+			report("Compacted the comments file from %d to %d bytes.", before, after)
+		}
+	}
+
+	draft, err := s.store.LoadDraft()
+	if err == nil && draft != nil && draft.PR == 0 && !changed[draft.Path] {
+		if !dryRun {
+			if err := s.store.ClearDraft(); err != nil {
+				return removed, err
+			}
+		}
+		report("%s the unsaved draft on %s, which has no changes.", verb, draft.Path)
+		removed++
+	}
+
+	sessions := session.NewStore(gitDir)
+	if state, err := sessions.Load(); err == nil && state.Position != nil && !changed[state.Position.Path] {
+		path := state.Position.Path
+		if !dryRun {
+			state.Position = nil
+			if err := sessions.Save(state); err != nil {
+				return removed, err
+			}
+		}
+		report("%s the resume position in %s, which has no changes.", verb, path)
+		removed++
+	}
+
+	now := time.Now()
+	leftovers, err := s.store.Leftovers(now.Add(-cleanTmpAge), now.Add(-cleanCorruptAge))
+	if err != nil {
+		return removed, err
+	}
+	for _, path := range leftovers {
+		if !dryRun {
+			if err := os.Remove(path); err != nil {
+				return removed, err
+			}
+		}
+		rel, relErr := filepath.Rel(gitDir, path)
+		if relErr != nil {
+			rel = path
+		}
+		report("%s %s.", verb, filepath.ToSlash(rel))
+		removed++
+	}
+
+	if removed == 0 {
+		report("Nothing to clean.")
+	}
+	return removed, nil
+}
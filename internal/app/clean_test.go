@@ -0,0 +1,148 @@
+package app
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"diffman/internal/comments"
+	gitint "diffman/internal/git"
+	"diffman/internal/history"
+	"diffman/internal/session"
+)
+
+func TestCleanPrunesStaleCommentsAndLeftovers(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if os.Getenv("GIT_DIR") != "" {
+		t.Skip("GIT_DIR is set")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	repo := t.TempDir()
+	gitCmd(t, repo, "init", "-q")
+	lines := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n"
+	for _, name := range []string{"a.go", "b.go"} {
+		if err := os.WriteFile(filepath.Join(repo, name), []byte(lines), 0o644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+	gitCmd(t, repo, "add", ".")
+	gitCmd(t, repo, "commit", "-q", "-m", "init")
+	if err := os.WriteFile(filepath.Join(repo, "a.go"), []byte(strings.Replace(lines, "10\n", "TEN\n", 1)), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	gitDir, err := gitint.DiscoverGitDir(t.Context(), repo)
+	if err != nil {
+		t.Fatalf("DiscoverGitDir() error = %v", err)
+	}
+	store := comments.NewStore(gitDir)
+	keep := comments.Comment{Path: "a.go", Side: comments.SideNew, Line: 10, Body: "keep"}
+	gone := comments.Comment{Path: "a.go", Side: comments.SideNew, Line: 1, Body: "moved away"}
+	committed := comments.Comment{Path: "b.go", Side: comments.SideNew, Line: 2, Body: "committed"}
+	if err := store.Save([]comments.Comment{gone, keep, committed}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := store.SaveDraft(comments.Draft{Path: "b.go", Line: 1, Body: "half"}); err != nil {
+		t.Fatalf("SaveDraft() error = %v", err)
+	}
+	if err := session.NewStore(gitDir).Save(session.State{SplitPercent: 60, Position: &session.Position{Path: "b.go", Side: "new", Line: 1}}); err != nil {
+		t.Fatalf("session Save() error = %v", err)
+	}
+	tmp := filepath.Join(gitDir, ".diffman", ".comments.json.tmp-123")
+	if err := os.WriteFile(tmp, []byte("{"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(tmp, old, old); err != nil {
+		t.Fatalf("Chtimes() error = %v", err)
+	}
+
+	var dry strings.Builder
+	if n, err := Clean(t.Context(), repo, &dry, true); err != nil || n != 5 {
+		t.Fatalf("Clean(dry run) = %d, %v\n%s", n, err, dry.String())
+	}
+	if stored, _ := store.Load(); len(stored) != 3 {
+		t.Fatalf("expected a dry run to change nothing, got %d comments", len(stored))
+	}
+
+	var out strings.Builder
+	n, err := Clean(t.Context(), repo, &out, false)
+	if err != nil || n != 5 {
+		t.Fatalf("Clean() = %d, %v\n%s", n, err, out.String())
+	}
+	for _, want := range []string{"Removed 2 stale comment(s), archived as", "a.go new:1 (line gone from diff)", "b.go new:2 (file no longer changed)", "draft on b.go", "resume position in b.go", ".diffman/.comments.json.tmp-123"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("expected %q in the report:\n%s", want, out.String())
+		}
+	}
+
+	stored, err := store.Load()
+	if err != nil || len(stored) != 1 || stored[0].Body != "keep" {
+		t.Fatalf("expected only the fresh comment kept, got %+v, %v", stored, err)
+	}
+	if d, _ := store.LoadDraft(); d != nil {
+		t.Fatalf("expected the orphaned draft removed")
+	}
+	if state, _ := session.NewStore(gitDir).Load(); state.Position != nil || state.SplitPercent != 60 {
+		t.Fatalf("expected only the resume position cleared, got %+v", state)
+	}
+	if _, err := os.Stat(tmp); !os.IsNotExist(err) {
+		t.Fatalf("expected the temporary file removed, got %v", err)
+	}
+	archived, err := history.NewStore(gitDir).List()
+	if err != nil || len(archived) != 1 || len(archived[0].Comments) != 2 || archived[0].Outcome != "pruned" {
+		t.Fatalf("expected the pruned comments archived, got %+v, %v", archived, err)
+	}
+
+	var again strings.Builder
+	if n, err := Clean(t.Context(), repo, &again, false); err != nil || n != 0 || !strings.Contains(again.String(), "Nothing to clean.") {
+		t.Fatalf("expected nothing left to clean, got %d, %v\n%s", n, err, again.String())
+	}
+}
+
+func TestCleanKeepsPullRequestComments(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if os.Getenv("GIT_DIR") != "" {
+		t.Skip("GIT_DIR is set")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	repo := t.TempDir()
+	gitCmd(t, repo, "init", "-q")
+	if err := os.WriteFile(filepath.Join(repo, "a.go"), []byte("one\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	gitCmd(t, repo, "add", ".")
+	gitCmd(t, repo, "commit", "-q", "-m", "init")
+
+	gitDir, err := gitint.DiscoverGitDir(t.Context(), repo)
+	if err != nil {
+		t.Fatalf("DiscoverGitDir() error = %v", err)
+	}
+	store := comments.NewStore(gitDir)
+	mine := comments.Comment{Path: "server/api.go", Side: comments.SideNew, Line: 3, Body: "mine", PR: 42}
+	theirs := comments.Comment{Path: "server/api.go", Side: comments.SideOld, Line: 7, Body: "theirs", Author: "octocat"}
+	if err := store.Save([]comments.Comment{mine, theirs}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := store.SaveDraft(comments.Draft{Path: "server/api.go", Line: 5, Body: "half", PR: 42}); err != nil {
+		t.Fatalf("SaveDraft() error = %v", err)
+	}
+
+	var out strings.Builder
+	if n, err := Clean(t.Context(), repo, &out, false); err != nil || n != 0 {
+		t.Fatalf("Clean() = %d, %v\n%s", n, err, out.String())
+	}
+	if stored, err := store.Load(); err != nil || len(stored) != 2 {
+		t.Fatalf("expected both PR comments kept, got %+v, %v", stored, err)
+	}
+	if d, _ := store.LoadDraft(); d == nil {
+		t.Fatalf("expected the PR draft kept")
+	}
+}
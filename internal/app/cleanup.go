@@ -0,0 +1,101 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"diffman/internal/diffview"
+)
+
+// maybeOfferCleanup asks to archive and clear the comments once none of them
+// anchor to the diff any more, which is what committing or merging the
+// reviewed changes looks like. Each comment set is offered only once.
+func (m *Model) maybeOfferCleanup() {
+	if !m.cleanupAfterCommit || m.reviewMode != reviewModeLocal || len(m.comments) == 0 {
+		return
+	}
+	// Don't pop up over a dock being typed in; the next refresh asks again.
+	if m.commentInputActive || m.exportInputActive || m.reviewInputActive || m.commentsFilterActive || m.commandInputActive || m.address != nil {
+		return
+	}
+	for _, c := range m.comments {
+		if !m.isCommentStale(c) || m.commentStaleReason(c) == staleReasonDiffFailed {
+			return
+		}
+	}
+	set := m.commentSetSignature()
+	if set == m.cleanupOffered {
+		return
+	}
+	m.cleanupOffered = set
+	m.cleanupConfirmModal = true
+}
+
+func (m Model) commentSetSignature() string {
+	keys := make([]string, 0, len(m.comments))
+	for k := range m.comments {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, "\n")
+}
+
+func (m Model) handleCleanupConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.Type == tea.KeyEsc, isRuneKey(msg, "k"), isRuneKey(msg, "K"):
+		m.cleanupConfirmModal = false
+		return m, nil
+	case msg.Type == tea.KeyEnter, isRuneKey(msg, "a"), isRuneKey(msg, "A"):
+		m.cleanupConfirmModal = false
+		all := m.sortedComments()
+		r, err := m.archiveReview("committed", all)
+		if err != nil {
+			m.setAlert(fmt.Sprintf("failed to archive review: %v", err))
+			return m, nil
+		}
+		cmd := m.clearAllComments()
+		if len(m.comments) == 0 {
+			m.setAlert(fmt.Sprintf("Archived %d comment(s) as %s and cleared them.", len(all), r.ID))
+		}
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m Model) renderCleanupConfirmModal() string {
+	body := strings.Join([]string{
+		fmt.Sprintf("None of the %d comment(s) match the diff any more;", len(m.comments)),
+		"the reviewed changes look committed or merged.",
+		"",
+		lipgloss.NewStyle().Foreground(lipgloss.Color("78")).Render("A/Enter archive and clear (H to browse later)"),
+		lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Render("K/Esc keep the comments"),
+	}, "\n")
+
+	width := 58
+	if m.width > 0 && m.width-6 < width {
+		width = max(24, m.width-6)
+	}
+
+	title := lipgloss.NewStyle().
+		Width(max(1, width-2)).
+		Padding(0, 1).
+		Bold(true).
+		Foreground(lipgloss.Color("230")).
+		Background(lipgloss.Color("99")).
+		Render("Review Finished?")
+
+	bodyBlock := lipgloss.NewStyle().
+		Width(max(1, width-2)).
+		Padding(1, 2).
+		Render(body)
+
+	return lipgloss.NewStyle().
+		Width(width).
+		Border(diffview.Border(lipgloss.RoundedBorder())).
+		BorderForeground(lipgloss.Color("99")).
+		Render(title + "\n" + bodyBlock)
+}
@@ -0,0 +1,136 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"diffman/internal/comments"
+	"diffman/internal/diffview"
+)
+
+func newCommandInput() textinput.Model {
+	input := textinput.New()
+	input.Prompt = ":"
+	input.Placeholder = "c 7"
+	input.CharLimit = 256
+	input.Cursor.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("51"))
+	input.PlaceholderStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+	return input
+}
+
+// recordExport remembers which comments the latest export numbered, in
+// order, so :c N finds comment N of it after the export has been shared.
+func (m *Model) recordExport(exported []comments.Comment) {
+	m.lastExport = make([]string, len(exported))
+	for i, c := range exported {
+		m.lastExport[i] = c.ID
+	}
+	m.saveSessionState()
+}
+
+func (m Model) startCommandInput() (tea.Model, tea.Cmd) {
+	m.commandInputActive = true
+	m.commandInputErr = ""
+	m.commandInput.SetValue("")
+	return m, m.commandInput.Focus()
+}
+
+func (m Model) handleCommandInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.closeCommandInput()
+		return m, nil
+	case tea.KeyEnter:
+		cmd, err := m.runCommand(m.commandInput.Value())
+		if err != nil {
+			m.commandInputErr = err.Error()
+			return m, nil
+		}
+		m.closeCommandInput()
+		return m, cmd
+	}
+
+	var cmd tea.Cmd
+	m.commandInput, cmd = m.commandInput.Update(msg)
+	m.commandInputErr = ""
+	return m, cmd
+}
+
+func (m *Model) closeCommandInput() {
+	m.commandInputActive = false
+	m.commandInputErr = ""
+	m.commandInput.Blur()
+}
+
+// runCommand runs a typed command: "c N" (or "cN") jumps to comment N of
+// the latest export, and "compare REF1 [REF2]" compares the selected file
+// between two revisions.
+func (m *Model) runCommand(line string) (tea.Cmd, error) {
+	line = strings.TrimSpace(line)
+	if fields := strings.Fields(line); len(fields) > 0 && fields[0] == "compare" {
+		return m.compareRefsCmd(fields[1:])
+	}
+	arg, ok := strings.CutPrefix(line, "c")
+	if !ok {
+		return nil, fmt.Errorf("unknown command %q", line)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(arg))
+	if err != nil || n < 1 {
+		return nil, fmt.Errorf("usage: c N, where N numbers a comment of the latest export")
+	}
+	return m.jumpToExportedComment(n)
+}
+
+// jumpToExportedComment opens comment n, counted from 1, of the latest
+// export in the diff. A stale comment opens its stale popup instead, as it
+// does from the comments view.
+func (m *Model) jumpToExportedComment(n int) (tea.Cmd, error) {
+	if len(m.lastExport) == 0 {
+		return nil, fmt.Errorf("no export yet: press y or W to export comments")
+	}
+	if n > len(m.lastExport) {
+		return nil, fmt.Errorf("the latest export has %d comment(s)", len(m.lastExport))
+	}
+	id := m.lastExport[n-1]
+	for _, c := range m.comments {
+		if id == "" || c.ID != id {
+			continue
+		}
+		if m.isCommentStale(c) {
+			m.openStalePopup(c)
+			return nil, nil
+		}
+		return m.jumpToCommentInDiff(c), nil
+	}
+	return nil, fmt.Errorf("comment %d of the latest export no longer exists", n)
+}
+
+func (m Model) renderCommandDock() string {
+	contentW := max(10, m.width-2)
+	bodyInnerW := max(1, contentW-4)
+	input := m.commandInput
+	input.Width = max(1, bodyInnerW-5)
+	inputBox := lipgloss.NewStyle().
+		Width(bodyInnerW).
+		MaxWidth(bodyInnerW).
+		Border(diffview.Border(lipgloss.NormalBorder())).
+		BorderForeground(lipgloss.Color("39")).
+		Padding(0, 1).
+		Render(input.View())
+	hint := lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Render(
+		ansi.Truncate("c N jump to comment N of the latest export | compare REF1 [REF2] | Enter run | Esc cancel", bodyInnerW, ""),
+	)
+	bodyLines := []string{inputBox, "", hint}
+	if m.commandInputErr != "" {
+		bodyLines = append(bodyLines, "", lipgloss.NewStyle().Foreground(lipgloss.Color("203")).Render(
+			ansi.Truncate("Error: "+m.commandInputErr, bodyInnerW, ""),
+		))
+	}
+	return m.renderDockPanel("Command", lipgloss.Color("39"), lipgloss.Color("39"), strings.Join(bodyLines, "\n"))
+}
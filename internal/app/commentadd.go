@@ -0,0 +1,96 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"diffman/internal/comments"
+)
+
+// CommentInput is a comment added from the command line.
+type CommentInput struct {
+	// File is the commented file, absolute or relative to Dir.
+	File string
+	// Dir is what a relative File is relative to, normally the caller's
+	// working directory, which differs from the start directory with -repo.
+	// Empty means the start directory.
+	Dir  string
+	Line int
+	// Side is "old" or "new".
+	Side string
+	Body string
+	// Mode is the diff the line must appear in: all (the default), unstaged, or staged.
+	Mode string
+}
+
+// AddComment stores a comment in the repository containing cwd without
+// starting the UI, so scripts and linters can leave review notes. As in the
+// UI the line must be part of the diff. A comment already on that line keeps
+// its text and gets the new body as another paragraph. The comment hook runs
+// as usual; its failure is returned alongside the saved comment.
+func AddComment(ctx context.Context, cwd string, in CommentInput) (comments.Comment, error) {
+	side, err := parseRPCSide(in.Side)
+	if err != nil {
+		return comments.Comment{}, err
+	}
+	body := strings.TrimSpace(in.Body)
+	if in.File == "" || in.Line <= 0 || body == "" {
+		return comments.Comment{}, fmt.Errorf("a file, a positive line, and a non-empty body are required")
+	}
+	mode, err := parseRPCMode(in.Mode)
+	if err != nil {
+		return comments.Comment{}, err
+	}
+	s, err := newRPCServer(ctx, cwd)
+	if err != nil {
+		return comments.Comment{}, err
+	}
+	dir := in.Dir
+	if dir == "" {
+		dir = cwd
+	}
+	path, err := repoRelativePath(s.root, dir, in.File)
+	if err != nil {
+		return comments.Comment{}, err
+	}
+
+	c, existed, err := s.saveComment(ctx, comments.Comment{Path: path, Side: side, Line: in.Line, Body: body}, mode, true)
+	if err != nil {
+		return comments.Comment{}, err
+	}
+	if err := s.runHook(ctx, hookPayload{Event: commentCreateOrEditEvent(existed), Comment: &c}); err != nil {
+		return c, fmt.Errorf("%s hook: %w", commentCreateOrEditEvent(existed), err)
+	}
+	return c, nil
+}
+
+// repoRelativePath turns file, given the way a shell user would (absolute or
+// relative to cwd), into the slash-separated repository path comments use.
+func repoRelativePath(root, cwd, file string) (string, error) {
+	abs := file
+	if !filepath.IsAbs(abs) {
+		dir, err := filepath.Abs(cwd)
+		if err != nil {
+			return "", err
+		}
+		abs = filepath.Join(dir, file)
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil || !isWithinDir(abs, root) {
+		// git reports the root with symlinks resolved; the file may be
+		// deleted, so resolve its directory instead.
+		if dir, derr := filepath.EvalSymlinks(filepath.Dir(abs)); derr == nil {
+			abs = filepath.Join(dir, filepath.Base(abs))
+			rel, err = filepath.Rel(root, abs)
+		}
+	}
+	if err != nil || !isWithinDir(abs, root) {
+		return "", fmt.Errorf("%s is outside the repository %s", file, root)
+	}
+	if rel == "." {
+		return "", fmt.Errorf("%s is the repository root, not a file", file)
+	}
+	return filepath.ToSlash(rel), nil
+}
@@ -0,0 +1,95 @@
+package app
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"diffman/internal/comments"
+	gitint "diffman/internal/git"
+)
+
+func TestAddCommentStoresAndAppendsOnTheSameLine(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if os.Getenv("GIT_DIR") != "" {
+		t.Skip("GIT_DIR is set")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	repo := t.TempDir()
+	gitCmd(t, repo, "init", "-q")
+	if err := os.MkdirAll(filepath.Join(repo, "src"), 0o755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	file := filepath.Join(repo, "src", "a.go")
+	if err := os.WriteFile(file, []byte("one\ntwo\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	gitCmd(t, repo, "add", ".")
+	gitCmd(t, repo, "commit", "-q", "-m", "init")
+	if err := os.WriteFile(file, []byte("one\nTWO\nthree\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	sub := filepath.Join(repo, "src")
+	c, err := AddComment(t.Context(), sub, CommentInput{File: "a.go", Line: 2, Side: "new", Body: "first"})
+	if err != nil {
+		t.Fatalf("AddComment() error = %v", err)
+	}
+	if c.Path != "src/a.go" || c.HunkHeader == "" {
+		t.Fatalf("expected a repository path and hunk header, got %+v", c)
+	}
+	if _, err := AddComment(t.Context(), repo, CommentInput{File: file, Line: 2, Side: "new", Body: "second"}); err != nil {
+		t.Fatalf("AddComment() error = %v", err)
+	}
+
+	gitDir, err := gitint.DiscoverGitDir(t.Context(), repo)
+	if err != nil {
+		t.Fatalf("DiscoverGitDir() error = %v", err)
+	}
+	stored, err := comments.NewStore(gitDir).Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(stored) != 1 || stored[0].Body != "first\n\nsecond" || stored[0].UpdatedAt.IsZero() {
+		t.Fatalf("expected one comment with both bodies, got %+v", stored)
+	}
+
+	if _, err := AddComment(t.Context(), repo, CommentInput{File: "src/a.go", Line: 40, Side: "new", Body: "x"}); err == nil || !strings.Contains(err.Error(), "not in the diff") {
+		t.Fatalf("expected a line outside the diff to be rejected, got %v", err)
+	}
+	if _, err := AddComment(t.Context(), repo, CommentInput{File: "../elsewhere.go", Line: 1, Side: "new", Body: "x"}); err == nil {
+		t.Fatalf("expected a file outside the repository to be rejected")
+	}
+}
+
+func TestAddCommentResolvesFileAgainstDirNotRepo(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if os.Getenv("GIT_DIR") != "" {
+		t.Skip("GIT_DIR is set")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	repo := t.TempDir()
+	gitCmd(t, repo, "init", "-q")
+	sub := filepath.Join(repo, "src")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(sub, "a.go"), []byte("one\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	// Like "cd src && diffman comment add -repo <root> -file a.go".
+	c, err := AddComment(t.Context(), repo, CommentInput{File: "a.go", Dir: sub, Line: 1, Side: "new", Body: "note"})
+	if err != nil {
+		t.Fatalf("AddComment() error = %v", err)
+	}
+	if c.Path != "src/a.go" {
+		t.Fatalf("expected the file relative to Dir, got %q", c.Path)
+	}
+}
@@ -0,0 +1,55 @@
+package app
+
+import (
+	"fmt"
+
+	"diffman/internal/comments"
+)
+
+// rowIndexForComment finds the diff row a comment is anchored to in the loaded diff.
+func (m Model) rowIndexForComment(c comments.Comment) (int, bool) {
+	for i, row := range m.diffRows {
+		if row.Path != c.Path {
+			continue
+		}
+		line := row.NewLine
+		if c.Side == comments.SideOld {
+			line = row.OldLine
+		}
+		if line > 0 && line == c.Line {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// recaptureCommentContext refreshes the stored hunk header and context of every
+// comment anchored in the loaded diff, using the current context_lines setting.
+func (m *Model) recaptureCommentContext() {
+	prev := make(map[string]comments.Comment, len(m.comments))
+	updated := 0
+	for key, c := range m.comments {
+		idx, ok := m.rowIndexForComment(c)
+		if !ok {
+			continue
+		}
+		anchor := commentAnchor{Path: c.Path, Side: c.Side, Line: c.Line, RowIdx: idx}
+		prev[key] = c
+		c.HunkHeader = m.hunkHeaderForRow(idx, c.Path)
+		c.ContextBefore, c.ContextAfter = m.contextAround(anchor)
+		m.comments[key] = c
+		updated++
+	}
+	if updated == 0 {
+		m.setAlert("No comments in current diff.")
+		return
+	}
+	if err := m.persistComments(); err != nil {
+		for key, c := range prev {
+			m.comments[key] = c
+		}
+		m.setAlert(fmt.Sprintf("failed to save comments: %v", err))
+		return
+	}
+	m.setAlert(fmt.Sprintf("Re-captured context for %d comment(s).", updated))
+}
@@ -0,0 +1,93 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"diffman/internal/comments"
+)
+
+const commentTimeLayout = "2006-01-02 15:04"
+
+// commentDetailBudget is how many lines the detail panel below the comments
+// list may take in a pane of paneHeight, spacer included; the list keeps at
+// least half of the pane.
+func commentDetailBudget(paneHeight int) int {
+	return max(0, (paneHeight-2)/2)
+}
+
+// commentDetailLines describes the selected comment below the comments list:
+// where it is, who wrote it and when, its full body wrapped to width, and the
+// code it was written against.
+func (m Model) commentDetailLines(c comments.Comment, width int) []string {
+	width = max(1, width)
+	headStyle := lipgloss.NewStyle().Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+	staleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+
+	head := fmt.Sprintf("%s %s:%d", c.Path, c.Side, c.Line)
+	if c.Author != "" {
+		head += " by @" + c.Author
+	}
+	out := []string{headStyle.Render(head)}
+	if !c.CreatedAt.IsZero() {
+		when := "Created " + c.CreatedAt.Local().Format(commentTimeLayout)
+		if c.UpdatedAt.After(c.CreatedAt) {
+			when += ", edited " + c.UpdatedAt.Local().Format(commentTimeLayout)
+		}
+		if age := commentAge(c, time.Now()); age != "" {
+			when += " (" + age + ")"
+		}
+		out = append(out, dimStyle.Render(when))
+	}
+	if reason := m.commentStaleReason(c); reason != staleReasonNone {
+		out = append(out, staleStyle.Render(fmt.Sprintf("Stale: %s (enter to delete, edit, or keep)", reason)))
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(c.Body), "\n") {
+		out = append(out, strings.Split(ansi.Wrap(line, width, ""), "\n")...)
+	}
+
+	if len(c.ContextBefore) == 0 && len(c.ContextAfter) == 0 {
+		return append(out, dimStyle.Render("(no context captured)"))
+	}
+	if c.HunkHeader != "" {
+		out = append(out, dimStyle.Render("  "+c.HunkHeader))
+	}
+	for _, line := range c.ContextBefore {
+		out = append(out, dimStyle.Render("  "+line))
+	}
+	for i, line := range c.ContextAfter {
+		if i == 0 {
+			out = append(out, "> "+line)
+			continue
+		}
+		out = append(out, dimStyle.Render("  "+line))
+	}
+	return out
+}
+
+// commentDetail is the detail panel for the comment under the cursor,
+// spacer included, cut to the budget for a pane of paneHeight.
+func (m Model) commentDetail(width, paneHeight int) []string {
+	c, ok := m.commentAtCursor()
+	budget := commentDetailBudget(paneHeight)
+	if !ok || budget < 2 {
+		return nil
+	}
+	lines := m.commentDetailLines(c, width)
+	if len(lines) > budget-1 {
+		more := len(lines) - (budget - 2)
+		lines = append(lines[:budget-2], lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Render(fmt.Sprintf("… %d more lines", more)))
+	}
+	out := make([]string, 0, len(lines)+1)
+	out = append(out, "")
+	for _, line := range lines {
+		out = append(out, ansi.Truncate(line, width, ""))
+	}
+	return out
+}
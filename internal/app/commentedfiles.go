@@ -0,0 +1,67 @@
+package app
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// commentedFileOrder lists file paths in file-tree order, ignoring collapsed
+// directories so a jump can land inside one.
+func (m Model) commentedFileOrder() []string {
+	m.treeCollapsed = nil
+	entries := m.fileTreeEntries()
+	out := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if !e.IsDir {
+			out = append(out, e.Path)
+		}
+	}
+	return out
+}
+
+// nextCommentedFile returns the first file with comments after (direction > 0)
+// or before (direction < 0) the selected file, wrapping around the list.
+func (m Model) nextCommentedFile(direction int) (string, bool) {
+	order := m.commentedFileOrder()
+	if len(order) == 0 {
+		return "", false
+	}
+	commented := m.commentedPaths()
+	start := -1
+	for i, path := range order {
+		if path == m.selectedF {
+			start = i
+			break
+		}
+	}
+	if start < 0 && direction < 0 {
+		start = len(order)
+	}
+	for step := 1; step <= len(order); step++ {
+		i := ((start+direction*step)%len(order) + len(order)) % len(order)
+		if commented[order[i]] > 0 && order[i] != m.selectedF {
+			return order[i], true
+		}
+	}
+	return "", false
+}
+
+func (m *Model) jumpToCommentedFile(direction int) tea.Cmd {
+	path, ok := m.nextCommentedFile(direction)
+	if !ok {
+		if m.commentedPaths()[m.selectedF] > 0 {
+			m.setAlert("No other files with comments.")
+		} else {
+			m.setAlert("No files with comments.")
+		}
+		return nil
+	}
+	idx := indexOfFilePath(m.fileItems, path)
+	if idx < 0 {
+		return nil
+	}
+	m.expandDirsForFilePath(path)
+	m.selected = idx
+	m.selectedF = path
+	m.syncFileCursorToSelectedPath()
+	m.ensureFileCursorVisible(m.fileTreeEntries())
+	m.loadingDiff = true
+	return m.loadDiffCmd(path)
+}
@@ -0,0 +1,146 @@
+package app
+
+import (
+	"fmt"
+
+	"diffman/internal/comments"
+	"diffman/internal/diffview"
+)
+
+// commentsRow is one line of the comments view: a file heading or a comment
+// under it. Pinned marks the pinned group's heading, whose Path is empty, and
+// the comments in it.
+type commentsRow struct {
+	Header  bool
+	Pinned  bool
+	Path    string
+	Count   int
+	Comment comments.Comment
+}
+
+// commentsViewRows groups commentsViewItems under per-file headings, after a
+// "Pinned" group holding the pinned comments of every file. Files appear in
+// the order of their first comment, so sort modes still apply; collapsed
+// groups show only their heading.
+func (m Model) commentsViewRows() []commentsRow {
+	items := m.commentsViewItems()
+	order := make([]string, 0)
+	byPath := make(map[string][]comments.Comment)
+	var pinned []comments.Comment
+	for _, c := range items {
+		if c.Pinned {
+			pinned = append(pinned, c)
+			continue
+		}
+		if _, ok := byPath[c.Path]; !ok {
+			order = append(order, c.Path)
+		}
+		byPath[c.Path] = append(byPath[c.Path], c)
+	}
+
+	rows := make([]commentsRow, 0, len(order)+len(items)+1)
+	if len(pinned) > 0 {
+		rows = append(rows, commentsRow{Header: true, Pinned: true, Count: len(pinned)})
+		if !m.commentsCollapsed[""] {
+			for _, c := range pinned {
+				rows = append(rows, commentsRow{Pinned: true, Path: c.Path, Comment: c})
+			}
+		}
+	}
+	for _, path := range order {
+		group := byPath[path]
+		rows = append(rows, commentsRow{Header: true, Path: path, Count: len(group)})
+		if m.commentsCollapsed[path] {
+			continue
+		}
+		for _, c := range group {
+			rows = append(rows, commentsRow{Path: path, Comment: c})
+		}
+	}
+	return rows
+}
+
+// inCommentGroup reports whether c is listed under the heading row.
+func inCommentGroup(row commentsRow, c comments.Comment) bool {
+	if row.Pinned {
+		return c.Pinned
+	}
+	return !c.Pinned && c.Path == row.Path
+}
+
+// commentAtCursor returns the comment under the comments view cursor, if the cursor is not on a heading.
+func (m Model) commentAtCursor() (comments.Comment, bool) {
+	rows := m.commentsViewRows()
+	if m.commentsCursor < 0 || m.commentsCursor >= len(rows) || rows[m.commentsCursor].Header {
+		return comments.Comment{}, false
+	}
+	return rows[m.commentsCursor].Comment, true
+}
+
+func (m *Model) setCommentGroupCollapsed(path string, collapsed bool) {
+	if m.commentsCollapsed == nil {
+		m.commentsCollapsed = make(map[string]bool)
+	}
+	if collapsed {
+		m.commentsCollapsed[path] = true
+	} else {
+		delete(m.commentsCollapsed, path)
+	}
+	rows := m.commentsViewRows()
+	for i, row := range rows {
+		if row.Header && row.Path == path {
+			m.commentsCursor = i
+			break
+		}
+	}
+	m.ensureCommentsCursorVisible(rows)
+}
+
+// commentsLeft collapses the heading under the cursor, or moves from a comment up to its heading.
+func (m *Model) commentsLeft(rows []commentsRow) {
+	row := rows[m.commentsCursor]
+	if row.Header {
+		m.setCommentGroupCollapsed(row.Path, true)
+		return
+	}
+	for i := m.commentsCursor - 1; i >= 0; i-- {
+		if rows[i].Header {
+			m.commentsCursor = i
+			break
+		}
+	}
+	m.ensureCommentsCursorVisible(rows)
+}
+
+func (m *Model) commentsRight(rows []commentsRow) {
+	row := rows[m.commentsCursor]
+	if row.Header && m.commentsCollapsed[row.Path] {
+		m.setCommentGroupCollapsed(row.Path, false)
+	}
+}
+
+func (m Model) commentGroupHeading(row commentsRow) string {
+	marker := "▾"
+	if m.commentsCollapsed[row.Path] {
+		marker = "▸"
+	}
+	if diffview.PlainMode() {
+		marker = "[-]"
+		if m.commentsCollapsed[row.Path] {
+			marker = "[+]"
+		}
+	}
+	name := row.Path
+	if row.Pinned {
+		name = "Pinned"
+	}
+	return fmt.Sprintf("%s %s (%d)", marker, name, row.Count)
+}
+
+func commentFileCount(items []comments.Comment) int {
+	paths := make(map[string]bool)
+	for _, c := range items {
+		paths[c.Path] = true
+	}
+	return len(paths)
+}
@@ -0,0 +1,22 @@
+package app
+
+import (
+	"os"
+	"strings"
+
+	"diffman/internal/comments"
+	"diffman/internal/config"
+)
+
+// commentsKey returns the key that encrypts the comment store, or nil when
+// neither the config nor $DIFFMAN_COMMENTS_KEY asks for encryption.
+func commentsKey(cfg config.AppConfig) ([]byte, error) {
+	if !cfg.EncryptComments && strings.TrimSpace(os.Getenv(comments.KeyEnvVar)) == "" {
+		return nil, nil
+	}
+	path, err := config.KeyPath()
+	if err != nil {
+		return nil, err
+	}
+	return comments.LoadOrCreateKey(path)
+}
@@ -0,0 +1,45 @@
+package app
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"diffman/internal/diffview"
+)
+
+// renderCommentSummary flattens a comment body onto one line, joining its
+// lines with " / " and styling inline markdown on top of base.
+func renderCommentSummary(body string, base lipgloss.Style) string {
+	lines := strings.Split(strings.TrimSpace(body), "\n")
+	if diffview.PlainMode() {
+		return base.Render(strings.Join(lines, " / "))
+	}
+	var b strings.Builder
+	for i, line := range lines {
+		if i > 0 {
+			b.WriteString(base.Render(" / "))
+		}
+		for _, span := range diffview.ParseMarkdownLine(line) {
+			b.WriteString(diffview.MarkdownStyle(base, span).Render(span.Text))
+		}
+	}
+	return b.String()
+}
+
+// renderCommentBody renders each line of a comment body with its inline markdown styled.
+func renderCommentBody(body string) string {
+	lines := strings.Split(strings.TrimSpace(body), "\n")
+	if diffview.PlainMode() {
+		return strings.Join(lines, "\n")
+	}
+	out := make([]string, 0, len(lines))
+	for _, line := range lines {
+		var b strings.Builder
+		for _, span := range diffview.ParseMarkdownLine(line) {
+			b.WriteString(diffview.MarkdownStyle(lipgloss.NewStyle(), span).Render(span.Text))
+		}
+		out = append(out, b.String())
+	}
+	return strings.Join(out, "\n")
+}
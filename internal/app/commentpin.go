@@ -0,0 +1,39 @@
+package app
+
+import "fmt"
+
+// toggleCommentPin pins or unpins the comment under the comments view cursor
+// and keeps the cursor on it as it moves in or out of the pinned group.
+func (m *Model) toggleCommentPin(rows []commentsRow) {
+	row := rows[m.commentsCursor]
+	if row.Header {
+		m.setAlert("Select a comment to pin.")
+		return
+	}
+	key := commentKey(row.Comment)
+	c, ok := m.comments[key]
+	if !ok {
+		return
+	}
+	c.Pinned = !c.Pinned
+	m.comments[key] = c
+	if err := m.persistComments(); err != nil {
+		m.setAlert(fmt.Sprintf("failed to save comments: %v", err))
+		return
+	}
+
+	next := m.commentsViewRows()
+	for i, r := range next {
+		if !r.Header && commentKey(r.Comment) == key {
+			m.commentsCursor = i
+			break
+		}
+	}
+	m.clampCommentsCursor(next)
+	m.ensureCommentsCursorVisible(next)
+	if c.Pinned {
+		m.setAlert("Comment pinned.")
+	} else {
+		m.setAlert("Comment unpinned.")
+	}
+}
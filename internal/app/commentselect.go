@@ -0,0 +1,195 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"diffman/internal/comments"
+	"diffman/internal/diffview"
+)
+
+func newCommentsFilterInput() textinput.Model {
+	input := textinput.New()
+	input.Prompt = "/"
+	input.Placeholder = "path or text"
+	input.CharLimit = 256
+	input.Cursor.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("51"))
+	input.PlaceholderStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+	return input
+}
+
+// commentMatchesFilter matches the filter case-insensitively against the
+// path, the "side:line" location, and the body.
+func commentMatchesFilter(c comments.Comment, filter string) bool {
+	filter = strings.ToLower(strings.TrimSpace(filter))
+	if filter == "" {
+		return true
+	}
+	location := fmt.Sprintf("%s:%d", c.Side, c.Line)
+	for _, field := range []string{c.Path, location, c.Body} {
+		if strings.Contains(strings.ToLower(field), filter) {
+			return true
+		}
+	}
+	return false
+}
+
+// toggleCommentSelection selects or clears the comment under the cursor. On a
+// heading it selects every comment listed under it, or clears them when all
+// are already selected.
+func (m *Model) toggleCommentSelection(rows []commentsRow) {
+	if m.commentsCursor < 0 || m.commentsCursor >= len(rows) {
+		return
+	}
+	if m.commentsSelected == nil {
+		m.commentsSelected = make(map[string]bool)
+	}
+	row := rows[m.commentsCursor]
+	if !row.Header {
+		key := commentKey(row.Comment)
+		if m.commentsSelected[key] {
+			delete(m.commentsSelected, key)
+		} else {
+			m.commentsSelected[key] = true
+		}
+		return
+	}
+
+	var group []string
+	allSelected := true
+	for _, c := range m.commentsViewItems() {
+		if !inCommentGroup(row, c) {
+			continue
+		}
+		key := commentKey(c)
+		group = append(group, key)
+		allSelected = allSelected && m.commentsSelected[key]
+	}
+	for _, key := range group {
+		if allSelected {
+			delete(m.commentsSelected, key)
+		} else {
+			m.commentsSelected[key] = true
+		}
+	}
+}
+
+// selectedCommentCount counts selected comments that still exist.
+func (m Model) selectedCommentCount() int {
+	n := 0
+	for key := range m.commentsSelected {
+		if _, ok := m.comments[key]; ok {
+			n++
+		}
+	}
+	return n
+}
+
+// exportScope picks what y and W export: the selection if there is one,
+// otherwise the comments matching the filter, otherwise everything. Stale
+// comments are always left out, and code context too when exports are
+// redacted. The label describes the scope for notices.
+func (m Model) exportScope() ([]comments.Comment, string) {
+	all := comments.OrderForExport(m.exportableComments(), m.exportGroup)
+	if m.redactExports {
+		all = comments.Redact(all)
+	}
+	switch {
+	case m.selectedCommentCount() > 0:
+		out := make([]comments.Comment, 0, len(m.commentsSelected))
+		for _, c := range all {
+			if m.commentsSelected[commentKey(c)] {
+				out = append(out, c)
+			}
+		}
+		return out, "selected"
+	case strings.TrimSpace(m.commentsFilter) != "":
+		out := make([]comments.Comment, 0, len(all))
+		for _, c := range all {
+			if commentMatchesFilter(c, m.commentsFilter) {
+				out = append(out, c)
+			}
+		}
+		return out, "filtered"
+	default:
+		return all, ""
+	}
+}
+
+// exportScopeEmptyMessage explains why there is nothing to export in the current scope.
+func exportScopeEmptyMessage(scope string) string {
+	if scope == "" {
+		return "No non-stale comments to export."
+	}
+	return fmt.Sprintf("No non-stale %s comments to export.", scope)
+}
+
+func (m Model) startCommentsFilter() (tea.Model, tea.Cmd) {
+	m.commentsFilterActive = true
+	m.commentsFilterInput.SetValue(m.commentsFilter)
+	cmd := m.commentsFilterInput.Focus()
+	m.commentsFilterInput.CursorEnd()
+	return m, cmd
+}
+
+// handleCommentsFilterInput filters as the user types; Enter keeps the
+// filter and Esc clears it.
+func (m Model) handleCommentsFilterInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.commentsFilter = ""
+		m.commentsFilterActive = false
+		m.commentsFilterInput.Blur()
+		m.resetCommentsCursor()
+		return m, nil
+	case tea.KeyEnter:
+		m.commentsFilterActive = false
+		m.commentsFilterInput.Blur()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.commentsFilterInput, cmd = m.commentsFilterInput.Update(msg)
+	m.commentsFilter = m.commentsFilterInput.Value()
+	m.resetCommentsCursor()
+	return m, cmd
+}
+
+func (m *Model) resetCommentsCursor() {
+	m.commentsCursor = 0
+	m.commentsScroll = 0
+}
+
+func (m Model) renderCommentsFilterDock() string {
+	contentW := max(10, m.width-2)
+	bodyInnerW := max(1, contentW-4)
+	input := m.commentsFilterInput
+	input.Width = max(1, bodyInnerW-5)
+	inputBox := lipgloss.NewStyle().
+		Width(bodyInnerW).
+		MaxWidth(bodyInnerW).
+		Border(diffview.Border(lipgloss.NormalBorder())).
+		BorderForeground(lipgloss.Color("39")).
+		Padding(0, 1).
+		Render(input.View())
+	hint := lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Render(
+		ansi.Truncate("Enter keep filter | Esc clear filter", bodyInnerW, ""),
+	)
+	return m.renderDockPanel("Filter Comments", lipgloss.Color("39"), lipgloss.Color("39"), strings.Join([]string{inputBox, "", hint}, "\n"))
+}
+
+// selectionMark is the checkbox shown before comments while a selection exists.
+func (m Model) selectionMark(c comments.Comment) string {
+	if len(m.commentsSelected) == 0 {
+		return ""
+	}
+	if m.commentsSelected[commentKey(c)] {
+		return "[x] "
+	}
+	return "[ ] "
+}
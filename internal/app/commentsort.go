@@ -0,0 +1,98 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"diffman/internal/comments"
+)
+
+// commentsSortMode orders the comments view; export and storage always use file order.
+type commentsSortMode int
+
+const (
+	commentsSortByFile commentsSortMode = iota
+	commentsSortNewest
+	commentsSortSeverity
+)
+
+func (s commentsSortMode) String() string {
+	switch s {
+	case commentsSortNewest:
+		return "newest"
+	case commentsSortSeverity:
+		return "severity"
+	default:
+		return "file"
+	}
+}
+
+func (m *Model) cycleCommentsSort() {
+	m.commentsSort = (m.commentsSort + 1) % 3
+	m.commentsCursor = 0
+	m.commentsScroll = 0
+	m.setAlert(fmt.Sprintf("Comments sorted by %s.", m.commentsSort))
+}
+
+// commentsViewItems returns the comments the comments view shows, after the filter, in display order.
+func (m Model) commentsViewItems() []comments.Comment {
+	out := m.sortedComments()
+	if filter := strings.TrimSpace(m.commentsFilter); filter != "" {
+		matched := out[:0]
+		for _, c := range out {
+			if commentMatchesFilter(c, filter) {
+				matched = append(matched, c)
+			}
+		}
+		out = matched
+	}
+	switch m.commentsSort {
+	case commentsSortNewest:
+		sort.SliceStable(out, func(i, j int) bool {
+			return commentTouchedAt(out[i]).After(commentTouchedAt(out[j]))
+		})
+	case commentsSortSeverity:
+		sort.SliceStable(out, func(i, j int) bool {
+			return out[i].Severity() > out[j].Severity()
+		})
+	}
+	return out
+}
+
+// commentTouchedAt is the last time a comment was written or edited.
+func commentTouchedAt(c comments.Comment) time.Time {
+	if c.UpdatedAt.After(c.CreatedAt) {
+		return c.UpdatedAt
+	}
+	return c.CreatedAt
+}
+
+// commentAge describes when a comment was written, and edited if that came later.
+func commentAge(c comments.Comment, now time.Time) string {
+	if c.CreatedAt.IsZero() {
+		return ""
+	}
+	age := relativeTime(c.CreatedAt, now)
+	if c.UpdatedAt.After(c.CreatedAt) {
+		age += ", edited " + relativeTime(c.UpdatedAt, now)
+	}
+	return age
+}
+
+func relativeTime(t, now time.Time) string {
+	d := now.Sub(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d/time.Minute))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d/time.Hour))
+	case d < 30*24*time.Hour:
+		return fmt.Sprintf("%dd ago", int(d/(24*time.Hour)))
+	default:
+		return t.Format("2006-01-02")
+	}
+}
@@ -0,0 +1,177 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"diffman/internal/comments"
+	gitint "diffman/internal/git"
+	"diffman/internal/util"
+)
+
+// lineCommentSyntax maps file extensions to how a one-line comment is opened
+// and, for languages without line comments, closed.
+var lineCommentSyntax = map[string][2]string{
+	".go": {"//", ""}, ".c": {"//", ""}, ".h": {"//", ""}, ".cc": {"//", ""}, ".cpp": {"//", ""},
+	".hpp": {"//", ""}, ".cs": {"//", ""}, ".java": {"//", ""}, ".kt": {"//", ""}, ".kts": {"//", ""},
+	".scala": {"//", ""}, ".swift": {"//", ""}, ".rs": {"//", ""}, ".js": {"//", ""}, ".jsx": {"//", ""},
+	".mjs": {"//", ""}, ".cjs": {"//", ""}, ".ts": {"//", ""}, ".tsx": {"//", ""}, ".dart": {"//", ""},
+	".php": {"//", ""}, ".proto": {"//", ""}, ".zig": {"//", ""}, ".scss": {"//", ""}, ".groovy": {"//", ""},
+	".py": {"#", ""}, ".rb": {"#", ""}, ".sh": {"#", ""}, ".bash": {"#", ""}, ".zsh": {"#", ""},
+	".fish": {"#", ""}, ".pl": {"#", ""}, ".r": {"#", ""}, ".yaml": {"#", ""}, ".yml": {"#", ""},
+	".toml": {"#", ""}, ".tf": {"#", ""}, ".nix": {"#", ""}, ".ex": {"#", ""}, ".exs": {"#", ""},
+	".cmake": {"#", ""}, ".mk": {"#", ""}, ".conf": {"#", ""}, ".ini": {";", ""},
+	".sql": {"--", ""}, ".lua": {"--", ""}, ".hs": {"--", ""}, ".elm": {"--", ""},
+	".vim": {`"`, ""}, ".el": {";;", ""}, ".clj": {";;", ""}, ".lisp": {";;", ""}, ".erl": {"%", ""}, ".tex": {"%", ""},
+	".css": {"/*", "*/"}, ".html": {"<!--", "-->"}, ".xml": {"<!--", "-->"}, ".md": {"<!--", "-->"}, ".vue": {"<!--", "-->"},
+}
+
+// commentSyntaxByName covers files known by name rather than extension.
+var commentSyntaxByName = map[string][2]string{
+	"Makefile": {"#", ""}, "Dockerfile": {"#", ""}, "Containerfile": {"#", ""},
+	"Rakefile": {"#", ""}, "Gemfile": {"#", ""}, "Justfile": {"#", ""}, "justfile": {"#", ""},
+	"CMakeLists.txt": {"#", ""}, ".gitignore": {"#", ""}, ".editorconfig": {"#", ""},
+}
+
+func commentSyntaxFor(path string) ([2]string, bool) {
+	base := filepath.Base(path)
+	if s, ok := commentSyntaxByName[base]; ok {
+		return s, true
+	}
+	s, ok := lineCommentSyntax[strings.ToLower(filepath.Ext(base))]
+	return s, ok
+}
+
+// todoLine renders body as a single TODO comment line for path, indented
+// like the line it is inserted above.
+func todoLine(path, body, indent string) (string, bool) {
+	syntax, ok := commentSyntaxFor(path)
+	if !ok {
+		return "", false
+	}
+	line := indent + syntax[0] + " TODO(reviewer): " + strings.Join(strings.Fields(body), " ")
+	if syntax[1] != "" {
+		line += " " + syntax[1]
+	}
+	return line, true
+}
+
+// insertTodoLine writes a TODO line carrying body into the file at path,
+// directly above the 1-based line, keeping the file's line endings and mode.
+func insertTodoLine(path, relPath string, line int, body string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	text := string(data)
+	eol := "\n"
+	if strings.Contains(text, "\r\n") {
+		eol = "\r\n"
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if line < 1 || line > len(lines)+1 {
+		return fmt.Errorf("line %d is past the end of %s", line, relPath)
+	}
+
+	indent := ""
+	if line <= len(lines) {
+		anchor := lines[line-1]
+		indent = anchor[:len(anchor)-len(strings.TrimLeft(anchor, " \t"))]
+	}
+	todo, ok := todoLine(relPath, body, indent)
+	if !ok {
+		return fmt.Errorf("no known comment syntax for %s", relPath)
+	}
+	if line > len(lines) && len(lines) > 0 && !strings.HasSuffix(lines[len(lines)-1], "\n") {
+		lines[len(lines)-1] += eol
+	}
+
+	out := make([]string, 0, len(lines)+1)
+	out = append(out, lines[:line-1]...)
+	out = append(out, todo+eol)
+	out = append(out, lines[line-1:]...)
+	return util.WriteFileAtomic(path, []byte(strings.Join(out, "")), info.Mode().Perm())
+}
+
+// shiftCommentsBelow moves the other new-side comments on c's file that are
+// at or below c's line down one line, past the TODO inserted above c. The
+// caller persists them with the deletion of c.
+func (m *Model) shiftCommentsBelow(c comments.Comment) {
+	var moved []comments.Comment
+	for key, other := range m.comments {
+		if other.Path != c.Path || other.Side != comments.SideNew || other.Line < c.Line || key == commentKey(c) {
+			continue
+		}
+		moved = append(moved, other)
+		delete(m.comments, key)
+	}
+	stale := make(map[string]bool, len(moved))
+	selected := make(map[string]bool, len(moved))
+	for _, other := range moved {
+		stale[commentKey(other)] = m.commentStale[commentKey(other)]
+		selected[commentKey(other)] = m.commentsSelected[commentKey(other)]
+		delete(m.commentStale, commentKey(other))
+		delete(m.commentsSelected, commentKey(other))
+	}
+	for _, other := range moved {
+		oldKey := commentKey(other)
+		other.Line++
+		m.comments[commentKey(other)] = other
+		if m.commentStale != nil {
+			m.commentStale[commentKey(other)] = stale[oldKey]
+		}
+		if selected[oldKey] {
+			m.commentsSelected[commentKey(other)] = true
+		}
+	}
+}
+
+// convertCommentToTodo replaces the comment with a TODO(reviewer) line in the
+// working file, above the line it is anchored to, and reloads the diff. Only
+// comments on the new side of a diff against the working tree can be
+// converted, since that is the file on disk.
+func (m *Model) convertCommentToTodo(c comments.Comment) tea.Cmd {
+	switch {
+	case m.reviewMode == reviewModePR:
+		m.setAlert("TODO conversion edits the working tree and is unavailable in PR mode.")
+		return nil
+	case m.diffMode == gitint.DiffModeStaged:
+		m.setAlert("TODO conversion needs a diff against the working tree; press t to leave staged mode.")
+		return nil
+	case c.Side != comments.SideNew:
+		m.setAlert("Only comments on new-side lines can become TODOs.")
+		return nil
+	case m.isCommentStale(c):
+		m.setAlert("The comment is stale; its line changed since it was written.")
+		return nil
+	}
+
+	if err := insertTodoLine(filepath.Join(m.cwd, c.Path), c.Path, c.Line, c.Body); err != nil {
+		m.setAlert(fmt.Sprintf("TODO not inserted: %v", err))
+		return nil
+	}
+	m.shiftCommentsBelow(c)
+	hook := m.deleteCommentByKey(commentKey(c))
+	rows := m.commentsViewRows()
+	m.clampCommentsCursor(rows)
+	m.ensureCommentsCursorVisible(rows)
+	m.setAlert(fmt.Sprintf("Inserted TODO above %s:%d.", c.Path, c.Line))
+
+	cmds := []tea.Cmd{hook, m.loadCommentStaleCmd(m.staleCheckItems(), m.comments, m.diffMode)}
+	if m.selectedF == c.Path {
+		m.loadingDiff = true
+		cmds = append(cmds, m.loadDiffCmd(c.Path))
+	}
+	return tea.Batch(cmds...)
+}
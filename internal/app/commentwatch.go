@@ -0,0 +1,168 @@
+package app
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"diffman/internal/comments"
+)
+
+// commentsWatchInterval is how often the comments file is checked for writes
+// by other processes, such as an editor plugin talking to diffman -rpc.
+const commentsWatchInterval = time.Second
+
+// stampComments records the comments file as last read or written by this
+// process, so only other writers trigger a reload, and the comments it holds
+// as the base later saves merge other writers' changes against.
+func (m *Model) stampComments() {
+	stamp, err := m.commentStore.Stamp()
+	if err != nil {
+		return
+	}
+	m.commentsStamp = stamp
+	m.recordCommentsBase()
+}
+
+func (m *Model) recordCommentsBase() {
+	m.commentsBase = make(map[string]comments.Comment, len(m.comments))
+	for _, c := range m.comments {
+		m.commentsBase[mergeKey(c)] = c
+	}
+}
+
+// mergeKey matches a comment across versions of the file: by ID, which every
+// loaded or created comment has, else by anchor.
+func mergeKey(c comments.Comment) string {
+	if c.ID != "" {
+		return c.ID
+	}
+	return commentKey(c)
+}
+
+// mergeExternalComments folds in what other processes, such as diffman
+// comment add or an editor plugin, wrote since the file was last read, so a
+// save does not overwrite their comments. Comments are matched by ID: a
+// comment changed here keeps this version, one only changed or deleted on
+// disk takes the disk's, and additions on either side are kept.
+func (m *Model) mergeExternalComments() error {
+	stamp, err := m.commentStore.Stamp()
+	if err != nil || stamp.Equal(m.commentsStamp) {
+		return nil
+	}
+	loaded, err := m.commentStore.Load()
+	if err != nil && !comments.IsRecovered(err) {
+		return fmt.Errorf("comments changed on disk and could not be reloaded: %w", err)
+	}
+
+	mine := make(map[string]comments.Comment, len(m.comments))
+	for _, c := range m.comments {
+		mine[mergeKey(c)] = c
+	}
+	merged := make(map[string]comments.Comment, len(loaded)+len(mine))
+	added := 0
+	for _, disk := range loaded {
+		base, known := m.commentsBase[mergeKey(disk)]
+		c, kept := mine[mergeKey(disk)]
+		switch {
+		case !known:
+			merged[commentKey(disk)] = disk
+			added++
+		case !kept:
+			// Deleted here.
+		case reflect.DeepEqual(c, base):
+			merged[commentKey(disk)] = disk
+		default:
+			merged[commentKey(c)] = c
+		}
+	}
+	for key, c := range mine {
+		base, known := m.commentsBase[key]
+		if !known || !reflect.DeepEqual(c, base) {
+			merged[commentKey(c)] = c
+		}
+	}
+
+	m.comments = merged
+	for k := range m.commentsSelected {
+		if _, ok := merged[k]; !ok {
+			delete(m.commentsSelected, k)
+		}
+	}
+	m.diffDirty = true
+	if added > 0 {
+		m.setAlert(fmt.Sprintf("Kept %d comment(s) added by another process.", added))
+	}
+	return nil
+}
+
+// commentsFileMsg carries the comments file another process changed, read
+// off the UI loop. since is the stamp the check started from.
+type commentsFileMsg struct {
+	since  comments.Stamp
+	stamp  comments.Stamp
+	loaded []comments.Comment
+	err    error
+}
+
+// checkCommentsFile looks for writes by other processes at most once per
+// commentsWatchInterval. The file is checked and read in a command, since a
+// load decrypts it and may restore the backup.
+func (m *Model) checkCommentsFile() tea.Cmd {
+	now := time.Now()
+	if now.Sub(m.commentsCheckedAt) < commentsWatchInterval {
+		return nil
+	}
+	m.commentsCheckedAt = now
+
+	store := m.commentStore
+	since := m.commentsStamp
+	return func() tea.Msg {
+		stamp, err := store.Stamp()
+		if err != nil || stamp.Equal(since) {
+			return nil
+		}
+		loaded, err := store.Load()
+		return commentsFileMsg{since: since, stamp: stamp, loaded: loaded, err: err}
+	}
+}
+
+// handleCommentsFile reloads the comments another process wrote, then
+// re-runs stale detection for the new set. A result from before this process
+// last read or wrote the file is dropped; the next check sees the file anew.
+func (m *Model) handleCommentsFile(msg commentsFileMsg) tea.Cmd {
+	if !msg.since.Equal(m.commentsStamp) {
+		return nil
+	}
+	if msg.err != nil && !comments.IsRecovered(msg.err) {
+		// Remember the bad version so the alert is not repeated every tick.
+		m.commentsStamp = msg.stamp
+		m.setAlert(fmt.Sprintf("failed to reload comments: %v", msg.err))
+		return nil
+	}
+	if msg.err != nil {
+		m.setAlert(msg.err.Error())
+	}
+
+	next := make(map[string]comments.Comment, len(msg.loaded))
+	for _, c := range msg.loaded {
+		next[commentKey(c)] = c
+	}
+	m.commentsStamp = msg.stamp
+	if reflect.DeepEqual(next, m.comments) {
+		m.recordCommentsBase()
+		return nil
+	}
+	m.comments = next
+	m.recordCommentsBase()
+	for k := range m.commentsSelected {
+		if _, ok := next[k]; !ok {
+			delete(m.commentsSelected, k)
+		}
+	}
+	m.diffDirty = true
+	m.setAlert(fmt.Sprintf("Comments changed on disk; reloaded %d.", len(next)))
+	return m.loadCommentStaleCmd(m.staleCheckItems(), m.comments, m.diffMode)
+}
@@ -0,0 +1,185 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"diffman/internal/diffview"
+	"diffman/internal/githubpr"
+)
+
+// commitPanel holds the commits of the PR under review, kept after the panel
+// closes so reopening it does not fetch them again.
+type commitPanel struct {
+	number  int
+	loading bool
+	commits []githubpr.Commit
+	err     error
+	scroll  int
+}
+
+type prCommitsLoadedMsg struct {
+	number  int
+	commits []githubpr.Commit
+	err     error
+}
+
+// toggleCommitPanel shows or hides the PR's commit messages, loading them the
+// first time.
+func (m Model) toggleCommitPanel() (tea.Model, tea.Cmd) {
+	if m.commitPanelOpen {
+		m.commitPanelOpen = false
+		return m, nil
+	}
+	if m.reviewMode != reviewModePR || m.prCtx == nil {
+		m.setAlert("Commit messages are shown for PRs; local changes are not committed yet.")
+		return m, nil
+	}
+	m.commitPanelOpen = true
+	pr := *m.prCtx
+	if m.commitPanel != nil && m.commitPanel.number == pr.Number && m.commitPanel.err == nil {
+		m.commitPanel.scroll = 0
+		return m, nil
+	}
+	m.commitPanel = &commitPanel{number: pr.Number, loading: true}
+	service := m.prSvc
+	return m, func() tea.Msg {
+		commits, err := service.ListCommits(context.Background(), pr)
+		return prCommitsLoadedMsg{number: pr.Number, commits: commits, err: err}
+	}
+}
+
+func (m Model) handlePRCommitsLoaded(msg prCommitsLoadedMsg) (tea.Model, tea.Cmd) {
+	if m.commitPanel == nil || m.commitPanel.number != msg.number {
+		return m, nil
+	}
+	m.commitPanel = &commitPanel{number: msg.number, commits: msg.commits, err: msg.err}
+	return m, nil
+}
+
+func (m Model) handleCommitPanel(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := m.commitPanel
+	page := max(1, m.commitPanelVisible()-1)
+	switch {
+	case msg.Type == tea.KeyEsc, isRuneKey(msg, "q"), key.Matches(msg, m.keys.Commits):
+		m.commitPanelOpen = false
+		return m, nil
+	case p == nil:
+		return m, nil
+	case key.Matches(msg, m.keys.Down):
+		p.scroll++
+	case key.Matches(msg, m.keys.Up):
+		p.scroll--
+	case key.Matches(msg, m.keys.PageDown):
+		p.scroll += page
+	case key.Matches(msg, m.keys.PageUp):
+		p.scroll -= page
+	case isRuneKey(msg, "g"):
+		p.scroll = 0
+	case isRuneKey(msg, "G"):
+		p.scroll = len(m.commitPanelLines())
+	}
+	p.scroll = max(0, min(p.scroll, len(m.commitPanelLines())-m.commitPanelVisible()))
+	return m, nil
+}
+
+func (m Model) commitPanelWidth() int {
+	return max(24, min(100, m.width-10))
+}
+
+func (m Model) commitPanelVisible() int {
+	return max(3, m.height-12)
+}
+
+// commitPanelLines lays out each commit as its short hash, author and date
+// over the full message, wrapped to the panel.
+func (m Model) commitPanelLines() []string {
+	if m.commitPanel == nil {
+		return nil
+	}
+	innerW := max(1, m.commitPanelWidth()-6)
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+	hash := lipgloss.NewStyle().Foreground(lipgloss.Color("220"))
+	wrap := lipgloss.NewStyle().Width(max(1, innerW-2))
+
+	var lines []string
+	for i, c := range m.commitPanel.commits {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		sha := c.SHA
+		if len(sha) > 7 {
+			sha = sha[:7]
+		}
+		header := hash.Render(sha) + " " + lipgloss.NewStyle().Bold(true).Render(c.Author)
+		if !c.Date.IsZero() {
+			header += dim.Render(" " + c.Date.Local().Format("2006-01-02 15:04"))
+		}
+		lines = append(lines, ansi.Truncate(header, innerW, "…"))
+		for _, line := range strings.Split(strings.TrimRight(c.Message, "\n"), "\n") {
+			for _, wrapped := range strings.Split(wrap.Render(line), "\n") {
+				lines = append(lines, "  "+strings.TrimRight(wrapped, " "))
+			}
+		}
+	}
+	return lines
+}
+
+func (m Model) renderCommitPanelModal() string {
+	width := m.commitPanelWidth()
+	innerW := max(1, width-6)
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+	p := m.commitPanel
+
+	var lines []string
+	all := m.commitPanelLines()
+	switch {
+	case p == nil || p.loading:
+		lines = append(lines, dim.Render("Loading…"))
+	case p.err != nil:
+		lines = append(lines, lipgloss.NewStyle().Foreground(lipgloss.Color("203")).Render(ansi.Truncate("Failed to load commits: "+p.err.Error(), innerW, "…")))
+	case len(all) == 0:
+		lines = append(lines, dim.Render("No commits."))
+	default:
+		visible := m.commitPanelVisible()
+		start := max(0, min(p.scroll, len(all)-visible))
+		end := min(len(all), start+visible)
+		lines = append(lines, all[start:end]...)
+		if start > 0 || end < len(all) {
+			lines = append(lines, dim.Render(fmt.Sprintf("lines %d-%d of %d", start+1, end, len(all))))
+		}
+	}
+	lines = append(lines, "", dim.Render(ansi.Truncate("j/k scroll | ctrl+f/ctrl+b page | M/Esc close", innerW, "")))
+
+	title := "Commits"
+	if m.prCtx != nil {
+		title = fmt.Sprintf("PR #%d commits", m.prCtx.Number)
+		if p != nil && !p.loading && p.err == nil {
+			title += fmt.Sprintf(" (%d)", len(p.commits))
+		}
+	}
+	titleBar := lipgloss.NewStyle().
+		Width(max(1, width-2)).
+		Padding(0, 1).
+		Bold(true).
+		Foreground(lipgloss.Color("230")).
+		Background(lipgloss.Color("63")).
+		Render(title)
+
+	bodyBlock := lipgloss.NewStyle().
+		Width(max(1, width-2)).
+		Padding(1, 2).
+		Render(strings.Join(lines, "\n"))
+
+	return lipgloss.NewStyle().
+		Width(width).
+		Border(diffview.Border(lipgloss.RoundedBorder())).
+		BorderForeground(lipgloss.Color("63")).
+		Render(titleBar + "\n" + bodyBlock)
+}
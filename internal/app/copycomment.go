@@ -0,0 +1,43 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"diffman/internal/comments"
+)
+
+// copyCommentCmd copies c alone, in the format chosen in the export dock, for
+// pasting into chat. It counts as an export: hooks see it and :c 1 finds it.
+func (m Model) copyCommentCmd(c comments.Comment) tea.Cmd {
+	snapshot := []comments.Comment{c}
+	if m.redactExports {
+		snapshot = comments.Redact(snapshot)
+	}
+	format := m.exportFormat
+	links := m.permalinkSettings()
+	okMsg := fmt.Sprintf("Copied the comment on %s %s:%d to clipboard.", c.Path, c.Side.String(), c.Line)
+	notice := m.tmuxNotice(okMsg)
+	return func() tea.Msg {
+		text := formatExport(format, defaultExportTitle, snapshot, comments.ExportOptions{Link: links.linker(context.Background())})
+		err := copyExport(context.Background(), text, notice)
+		return clipboardResultMsg{okMsg: okMsg, exported: snapshot, err: err}
+	}
+}
+
+// copyCommentAtCursor copies the comment on the diff cursor's line.
+func (m *Model) copyCommentAtCursor() tea.Cmd {
+	anchor, ok := m.currentAnchor()
+	if !ok {
+		m.setAlert("No commentable line selected.")
+		return nil
+	}
+	c, exists := m.comments[comments.AnchorKey(anchor.Path, anchor.Side, anchor.Line)]
+	if !exists {
+		m.setAlert("No comment exists on selected line.")
+		return nil
+	}
+	return m.copyCommentCmd(c)
+}
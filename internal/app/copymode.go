@@ -0,0 +1,112 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"diffman/internal/clipboard"
+	"diffman/internal/diffview"
+)
+
+func (m *Model) startCopyMode() {
+	m.copyMode = true
+	m.copyAnchor = m.diffCursor
+	m.diffDirty = true
+	m.refreshDiffContent()
+	m.setAlert("Copy mode: j/k extend selection, y copy new side, Y copy old side, Esc cancel.")
+}
+
+func (m *Model) stopCopyMode() {
+	if !m.copyMode {
+		return
+	}
+	m.copyMode = false
+	m.diffDirty = true
+	m.refreshDiffContent()
+}
+
+// copySelection returns the selected row range in ascending order.
+func (m Model) copySelection() (int, int) {
+	lo, hi := m.copyAnchor, m.diffCursor
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	lo = max(0, lo)
+	hi = min(len(m.diffRows)-1, hi)
+	return lo, hi
+}
+
+func (m Model) renderOptions() diffview.RenderOptions {
+	opts := diffview.RenderOptions{
+		LineNumbers:      m.lineNumbers,
+		WordWrap:         m.wordWrap,
+		CommentPlacement: m.commentPlacement,
+		CommentExpanded:  m.commentExpanded,
+		HideComments:     m.hideComments,
+		MaxLineColumns:   m.maxLineColumns,
+		LineExpanded:     m.lineExpanded,
+	}
+	// Reading a comment across the panes only works when they sit side by side.
+	if opts.CommentPlacement == diffview.CommentsAcross && !m.sideBySide() {
+		opts.CommentPlacement = diffview.CommentsBelowSide
+	}
+	if m.copyMode {
+		opts.HasSelection = true
+		opts.SelectFrom, opts.SelectTo = m.copySelection()
+	}
+	return opts
+}
+
+// selectedSideText joins the plain text of one side of the selected rows.
+// Rows without a line on that side, such as hunk headers, are skipped.
+func (m Model) selectedSideText(side diffview.Side) (string, int) {
+	lo, hi := m.copySelection()
+	lines := make([]string, 0, hi-lo+1)
+	for i := lo; i <= hi; i++ {
+		row := m.diffRows[i]
+		switch {
+		case side == diffview.SideOld && row.OldLine > 0:
+			lines = append(lines, row.OldText)
+		case side == diffview.SideNew && row.NewLine > 0:
+			lines = append(lines, row.NewText)
+		}
+	}
+	return strings.Join(lines, "\n"), len(lines)
+}
+
+// handleCopyModeKey handles the keys that only mean something while selecting.
+// It reports false for everything else, so cursor movement keeps working.
+func (m *Model) handleCopyModeKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	switch {
+	case msg.Type == tea.KeyEsc, isRuneKey(msg, "v"):
+		m.stopCopyMode()
+		return nil, true
+	case isRuneKey(msg, "Y"):
+		return m.copySelectedSide(diffview.SideOld), true
+	case key.Matches(msg, m.keys.Export):
+		return m.copySelectedSide(diffview.SideNew), true
+	}
+	return nil, false
+}
+
+func (m *Model) copySelectedSide(side diffview.Side) tea.Cmd {
+	text, count := m.selectedSideText(side)
+	m.stopCopyMode()
+	sideName := "new"
+	if side == diffview.SideOld {
+		sideName = "old"
+	}
+	if count == 0 {
+		m.setAlert(fmt.Sprintf("Selection has no lines on the %s side.", sideName))
+		return nil
+	}
+	okMsg := fmt.Sprintf("Copied %d %s-side line(s) to clipboard.", count, sideName)
+	return func() tea.Msg {
+		err := clipboard.CopyText(context.Background(), text)
+		return clipboardResultMsg{err: err, okMsg: okMsg}
+	}
+}
@@ -0,0 +1,219 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"diffman/internal/debuglog"
+)
+
+// crashState remembers a panic caught in Update or View. It is shared by
+// pointer because View runs on a copy of the Model, and a panic there must
+// still make the next Update quit.
+type crashState struct {
+	mu     sync.Mutex
+	report string
+	err    error
+}
+
+func (c *crashState) crashed() bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.report != "" || c.err != nil
+}
+
+// CrashReport returns the crash file written after a panic, or "" when the
+// session ended normally. A non-nil error means the panic happened but the
+// file could not be written.
+func (m Model) CrashReport() (string, error) {
+	if m.crash == nil {
+		return "", nil
+	}
+	m.crash.mu.Lock()
+	defer m.crash.mu.Unlock()
+	return m.crash.report, m.crash.err
+}
+
+// crashReportDir is $XDG_STATE_HOME/diffman, falling back to
+// $HOME/.local/state/diffman.
+func crashReportDir() (string, error) {
+	if xdg := strings.TrimSpace(os.Getenv("XDG_STATE_HOME")); xdg != "" {
+		return filepath.Join(xdg, "diffman"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "state", "diffman"), nil
+}
+
+// handlePanic saves the comment dock's text as a draft, so the next start
+// offers it again, and writes the panic, stack, and a summary of the model to
+// a crash file. Only the first panic is recorded.
+func (m Model) handlePanic(where string, msg tea.Msg, r any) {
+	stack := debug.Stack()
+	if m.crash == nil || m.crash.crashed() {
+		return
+	}
+	debuglog.Printf("panic in %s: %v\n%s", where, r, stack)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "diffman crash report\n\n")
+	fmt.Fprintf(&b, "time:    %s\n", time.Now().Format(time.RFC3339))
+	fmt.Fprintf(&b, "go:      %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&b, "during:  %s", where)
+	if msg != nil {
+		fmt.Fprintf(&b, " of %T %s", msg, crashMsgSummary(msg))
+	}
+	fmt.Fprintf(&b, "\npanic:   %v\n\n", r)
+	b.WriteString(m.crashDraftSection())
+	b.WriteString("\nstate:\n")
+	b.WriteString(safeCrashText(m.crashStateSummary))
+	fmt.Fprintf(&b, "\nstack:\n%s", stack)
+
+	path, err := writeCrashReport(b.String())
+	m.crash.mu.Lock()
+	m.crash.report = path
+	if err != nil {
+		m.crash.err = fmt.Errorf("write crash report: %w", err)
+	}
+	m.crash.mu.Unlock()
+}
+
+func writeCrashReport(text string) (string, error) {
+	dir, err := crashReportDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, "crash-"+time.Now().UTC().Format("20060102T150405Z")+".log")
+	if err := os.WriteFile(path, []byte(text), 0o600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+func crashMsgSummary(msg tea.Msg) string {
+	if k, ok := msg.(tea.KeyMsg); ok {
+		return fmt.Sprintf("%q", k.String())
+	}
+	return ""
+}
+
+// safeCrashText runs fn, which reads a model that may be inconsistent after
+// the panic, without letting a second panic escape.
+func safeCrashText(fn func() string) (text string) {
+	defer func() {
+		if r := recover(); r != nil {
+			text = fmt.Sprintf("  (unavailable: %v)\n", r)
+		}
+	}()
+	return fn()
+}
+
+// crashDraftSection saves the open comment dock as a draft and lists it, and
+// any drafts stashed for other lines, in the report so no typed text is lost.
+// With encrypt_comments on, the report names the drafts without their bodies,
+// which would otherwise reach disk in plain text.
+func (m Model) crashDraftSection() string {
+	return safeCrashText(func() string {
+		var b strings.Builder
+		if draft, ok := m.currentDraft(); ok {
+			if err := m.commentStore.SaveDraft(draft); err != nil {
+				fmt.Fprintf(&b, "unsaved comment (saving the draft failed: %v):\n", err)
+			} else {
+				b.WriteString("unsaved comment (saved as a draft; diffman offers it on the next start):\n")
+			}
+			fmt.Fprintf(&b, "  %s:%s:%d\n%s\n", draft.Path, draft.Side, draft.Line, m.crashBodyText(draft.Body))
+		}
+		if len(m.anchorDrafts) > 0 {
+			keys := make([]string, 0, len(m.anchorDrafts))
+			for k := range m.anchorDrafts {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			b.WriteString("stashed drafts:\n")
+			for _, k := range keys {
+				fmt.Fprintf(&b, "  %s\n%s\n", k, m.crashBodyText(m.anchorDrafts[k].Body))
+			}
+		}
+		return b.String()
+	})
+}
+
+// crashBodyText is a draft body as the report shows it.
+func (m Model) crashBodyText(body string) string {
+	if m.commentsKey != nil {
+		return indentCrashText("(left out: comments are encrypted)")
+	}
+	return indentCrashText(body)
+}
+
+func indentCrashText(text string) string {
+	return "    " + strings.ReplaceAll(strings.TrimRight(text, "\n"), "\n", "\n    ")
+}
+
+// crashStateSummary describes where the user was, without file contents or
+// comment bodies beyond counts.
+func (m Model) crashStateSummary() string {
+	mode := "local"
+	if m.reviewMode == reviewModePR {
+		mode = "pr"
+		if m.prCtx != nil {
+			mode = fmt.Sprintf("pr %s/%s#%d", m.prCtx.Owner, m.prCtx.Repo, m.prCtx.Number)
+		}
+	}
+	state := map[string]any{
+		"review_mode":   mode,
+		"repo":          m.cwd,
+		"diff_mode":     m.diffMode.String(),
+		"focus":         int(m.focus),
+		"width":         m.width,
+		"height":        m.height,
+		"files":         len(m.fileItems),
+		"selected_file": m.selectedF,
+		"diff_rows":     len(m.diffRows),
+		"diff_cursor":   m.diffCursor,
+		"comments":      len(m.comments),
+		"comment_dock":  m.commentInputActive,
+		"loading_files": m.loadingFiles,
+		"loading_diff":  m.loadingDiff,
+		"delta_mode":    m.deltaMode,
+		"last_alert":    m.alertMsg,
+		"zoom_side":     int(m.zoomSide),
+		"file_pane_w":   m.filePaneW,
+	}
+	b, err := json.MarshalIndent(state, "  ", "  ")
+	if err != nil {
+		return fmt.Sprintf("  (unavailable: %v)\n", err)
+	}
+	return "  " + string(b) + "\n"
+}
+
+// renderCrashNotice replaces the UI after a panic until the program quits.
+func (m Model) renderCrashNotice() string {
+	report, err := m.CrashReport()
+	lines := []string{"diffman hit an internal error and is closing."}
+	if err != nil {
+		lines = append(lines, err.Error())
+	} else if report != "" {
+		lines = append(lines, "Details were written to "+report)
+	}
+	lines = append(lines, "Press any key to exit.")
+	return strings.Join(lines, "\n")
+}
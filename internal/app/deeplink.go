@@ -0,0 +1,112 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"diffman/internal/diffview"
+)
+
+// Location is a file, and optionally a line in it, to open at startup.
+type Location struct {
+	Path string
+	Line int
+}
+
+// ParseLocation reads "path", "path:line", or "path:line:col" as printed by
+// grep -n, compilers, and CI logs; a trailing colon is ignored.
+func ParseLocation(arg string) (Location, error) {
+	s := strings.TrimSuffix(strings.TrimSpace(arg), ":")
+	if s == "" {
+		return Location{}, fmt.Errorf("empty location")
+	}
+	loc := Location{Path: s}
+	parts := strings.Split(s, ":")
+	// Peel at most two trailing numbers, line then column.
+	for n := min(2, len(parts)-1); n > 0; n-- {
+		nums := parts[len(parts)-n:]
+		line, err := strconv.Atoi(nums[0])
+		if err != nil || line < 1 || !allDigits(nums[1:]) {
+			continue
+		}
+		loc = Location{Path: strings.Join(parts[:len(parts)-n], ":"), Line: line}
+		break
+	}
+	if loc.Path == "" {
+		return Location{}, fmt.Errorf("%q has no file path", arg)
+	}
+	return loc, nil
+}
+
+func allDigits(parts []string) bool {
+	for _, p := range parts {
+		if _, err := strconv.Atoi(p); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// locationPath resolves a path given on the command line, relative to the
+// directory diffman was started in, to a repository path. A relative path
+// that does not lead into the repository is tried against its root, which
+// is how CI logs and -repo users tend to write it.
+func locationPath(root, file string) (string, error) {
+	path, err := repoRelativePath(root, ".", file)
+	if err != nil && !filepath.IsAbs(file) {
+		if p, rootErr := repoRelativePath(root, root, file); rootErr == nil {
+			return p, nil
+		}
+	}
+	return path, err
+}
+
+// openLocation selects loc's file so the first file list opens it, and
+// remembers the line to move to once its diff loads.
+func (m *Model) openLocation(loc Location) {
+	m.selectedF = loc.Path
+	m.focus = focusDiff
+	l := loc
+	m.pendingLocation = &l
+}
+
+// checkPendingLocation drops a startup location whose file has no changes,
+// saying so instead of silently opening the first file.
+func (m *Model) checkPendingLocation() {
+	if m.pendingLocation == nil || indexOfFilePath(m.fileItems, m.pendingLocation.Path) >= 0 {
+		return
+	}
+	m.setAlert(fmt.Sprintf("%s has no changes to review.", m.pendingLocation.Path))
+	m.pendingLocation = nil
+	m.focus = focusFiles
+}
+
+// jumpToLocation moves the diff cursor to loc's new-side line or, when the
+// line is not in the diff, to the nearest line that is.
+func (m *Model) jumpToLocation(loc Location) {
+	if loc.Line <= 0 {
+		return
+	}
+	best, bestDist := -1, 0
+	for i, row := range m.diffRows {
+		if row.Path != loc.Path || row.NewLine == 0 || row.Kind == diffview.RowHunkHeader {
+			continue
+		}
+		dist := max(row.NewLine-loc.Line, loc.Line-row.NewLine)
+		if best < 0 || dist < bestDist {
+			best, bestDist = i, dist
+		}
+	}
+	if best < 0 {
+		return
+	}
+	m.diffCursor = best
+	m.diffDirty = true
+	m.refreshDiffContent()
+	m.scrollCursorWithPadding(10)
+	if bestDist > 0 {
+		m.setAlert(fmt.Sprintf("Line %d of %s is not in the diff; showing line %d.", loc.Line, loc.Path, m.diffRows[best].NewLine))
+	}
+}
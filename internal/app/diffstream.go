@@ -0,0 +1,91 @@
+package app
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"diffman/internal/debuglog"
+	"diffman/internal/diffview"
+)
+
+const (
+	// streamDiffBytes is the diff size past which the first hunks are shown
+	// while the rest is still being parsed.
+	streamDiffBytes = 1 << 20
+	// streamDiffBatchLines is about how many diff lines the first batch of
+	// a streamed diff holds; each later batch is as large as everything
+	// shown before it, so the whole diff renders a handful of times.
+	streamDiffBatchLines = 2000
+)
+
+// diffBatchMsg carries the next rows parsed from stream for path.
+type diffBatchMsg struct {
+	path   string
+	stream *diffview.DiffStream
+	rows   []diffview.DiffRow
+	done   bool
+	err    error
+}
+
+// nextDiffBatch parses batches from stream until one has rows for path or
+// the stream ends.
+func nextDiffBatch(stream *diffview.DiffStream, path string, lines int) ([]diffview.DiffRow, bool, error) {
+	var rows []diffview.DiffRow
+	for len(rows) == 0 {
+		batch, err := stream.Next(lines)
+		if err != nil {
+			return nil, false, err
+		}
+		rows = rowsForPath(batch, path)
+		if stream.Done() {
+			return rows, true, nil
+		}
+	}
+	return rows, false, nil
+}
+
+func diffBatchCmd(path string, stream *diffview.DiffStream, lines int) tea.Cmd {
+	return func() tea.Msg {
+		rows, done, err := nextDiffBatch(stream, path, lines)
+		return diffBatchMsg{path: path, stream: stream, rows: rows, done: done, err: err}
+	}
+}
+
+// handleDiffBatch appends the rows of a streamed diff to the diff pane. Batches
+// from a stream the pane has moved on from are dropped.
+func (m Model) handleDiffBatch(msg diffBatchMsg) (tea.Model, tea.Cmd) {
+	if msg.stream != m.diffStream || msg.path != m.selectedF {
+		return m, nil
+	}
+	if msg.err != nil {
+		debuglog.Printf("parse diff %s failed: %v", msg.path, msg.err)
+		m.diffStream = nil
+		m.setAlert(fmt.Sprintf("Showing part of %s: %v", msg.path, msg.err))
+		return m, m.finishDiffLoad(msg.path)
+	}
+	m.diffRows = append(m.diffRows, msg.rows...)
+	m.recordReviewLines(msg.path, m.diffRows)
+	m.diffDirty = true
+	m.refreshDiffContent()
+	if !msg.done {
+		return m, diffBatchCmd(msg.path, msg.stream, len(m.diffRows))
+	}
+	m.diffStream = nil
+	debuglog.Printf("load diff %s: streamed %d rows", msg.path, len(m.diffRows))
+	return m, m.finishDiffLoad(msg.path)
+}
+
+// finishDiffLoad runs what waits for the whole diff of path: jumps to a
+// comment or location in it and reopening a saved draft.
+func (m *Model) finishDiffLoad(path string) tea.Cmd {
+	if m.pendingCommentJump != nil && m.pendingCommentJump.Path == path {
+		m.jumpToCommentAnchor(*m.pendingCommentJump)
+		m.pendingCommentJump = nil
+	}
+	if m.pendingLocation != nil && m.pendingLocation.Path == path {
+		m.jumpToLocation(*m.pendingLocation)
+		m.pendingLocation = nil
+	}
+	return m.restorePendingDraft(path)
+}
@@ -0,0 +1,148 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	gitint "diffman/internal/git"
+)
+
+// diffToolLeaderKey opens the selected file in the configured difftool
+// unless a leader command is bound to the same key.
+const diffToolLeaderKey = "d"
+
+// diffToolReadyMsg carries the two versions of a file written out for the
+// difftool, in dir, which is removed once the tool exits.
+type diffToolReadyMsg struct {
+	dir      string
+	old, new string
+	err      error
+}
+
+type diffToolResultMsg struct {
+	dir string
+	err error
+}
+
+// diffToolCommand returns command with "$OLD" "$NEW" appended when it names
+// neither, so a bare tool name such as meld works.
+func diffToolCommand(command string) string {
+	if strings.Contains(command, "$OLD") || strings.Contains(command, "${OLD}") ||
+		strings.Contains(command, "$NEW") || strings.Contains(command, "${NEW}") {
+		return command
+	}
+	return command + ` "$OLD" "$NEW"`
+}
+
+// diffToolRevs returns the revisions the old and new sides of the diff mode
+// come from: "HEAD", "" for the index, or "worktree" for the working file.
+func diffToolRevs(mode gitint.DiffMode) (oldRev, newRev string) {
+	switch mode {
+	case gitint.DiffModeStaged:
+		return "HEAD", ""
+	case gitint.DiffModeUnstaged:
+		return "", "worktree"
+	default:
+		return "HEAD", "worktree"
+	}
+}
+
+// openDiffToolCmd writes the old side of the selected file's diff, and the
+// new side unless it is the working file, to a temporary directory for the
+// difftool. Renamed files are compared with the file they came from.
+func (m *Model) openDiffToolCmd() tea.Cmd {
+	switch {
+	case m.diffTool == "":
+		m.setAlert(`No difftool configured; set "difftool" in the config, e.g. "meld".`)
+		return nil
+	case m.reviewMode == reviewModePR:
+		m.setAlert("The difftool compares local files and is unavailable in PR mode.")
+		return nil
+	case m.selectedF == "":
+		m.setAlert("Select a file to open in the difftool.")
+		return nil
+	}
+	root, path, mode := m.cwd, m.selectedF, m.diffMode
+	origPath := path
+	if from := renamedFrom(m.staleCheckItems())[path]; from != "" && mode != gitint.DiffModeUnstaged {
+		origPath = from
+	}
+	return func() tea.Msg {
+		dir, err := os.MkdirTemp("", "diffman-difftool-")
+		if err != nil {
+			return diffToolReadyMsg{err: err}
+		}
+		oldRev, newRev := diffToolRevs(mode)
+		oldFile, err := writeDiffToolSide(context.Background(), root, dir, oldRev, origPath)
+		if err != nil {
+			return diffToolReadyMsg{dir: dir, err: err}
+		}
+		newFile := filepath.Join(root, path)
+		switch _, statErr := os.Stat(newFile); {
+		case newRev != "worktree":
+			newFile, err = writeDiffToolSide(context.Background(), root, dir, newRev, path)
+		case errors.Is(statErr, os.ErrNotExist):
+			newFile, err = writeDiffToolSide(context.Background(), root, dir, "deleted", path)
+		}
+		if err != nil {
+			return diffToolReadyMsg{dir: dir, err: err}
+		}
+		return diffToolReadyMsg{dir: dir, old: oldFile, new: newFile}
+	}
+}
+
+// writeDiffToolSide writes path as of rev into a subdirectory of dir named
+// after rev, keeping the file name so tools can detect its type. Files
+// missing at rev, and the "deleted" side, are written empty.
+func writeDiffToolSide(ctx context.Context, root, dir, rev, path string) (string, error) {
+	var content []byte
+	if rev != "deleted" {
+		var err error
+		if content, _, err = gitint.ReadBlob(ctx, root, rev, path); err != nil {
+			return "", err
+		}
+	}
+	label := rev
+	if label == "" {
+		label = "index"
+	}
+	file := filepath.Join(dir, label, filepath.Base(path))
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(file, content, 0o600); err != nil {
+		return "", err
+	}
+	return file, nil
+}
+
+// execDiffToolCmd runs the difftool like a leader command, with OLD and NEW
+// set to the two versions, and removes the temporary files when it exits.
+func (m Model) execDiffToolCmd(msg diffToolReadyMsg) tea.Cmd {
+	sh := strings.TrimSpace(os.Getenv("SHELL"))
+	if sh == "" {
+		sh = "/bin/sh"
+	}
+	cmd := exec.Command(sh, "-lc", diffToolCommand(m.diffTool))
+	cmd.Dir = m.cwd
+	cmd.Env = append(os.Environ(),
+		"ROOT="+m.cwd,
+		"FILE="+m.selectedF,
+		"OLD="+msg.old,
+		"NEW="+msg.new,
+	)
+	dir := msg.dir
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		if err != nil {
+			err = fmt.Errorf("%s: %w", m.diffTool, err)
+		}
+		return diffToolResultMsg{dir: dir, err: err}
+	})
+}
@@ -0,0 +1,217 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	gitint "diffman/internal/git"
+)
+
+// dirSummaryFile is one file of a directory that was added or deleted as a
+// whole; lines is what the file adds or deletes.
+type dirSummaryFile struct {
+	path   string
+	size   int64
+	lines  int
+	binary bool
+}
+
+// dirSummaryState is the aggregate shown in the diff pane while the file
+// cursor is on a wholly added or deleted directory.
+type dirSummaryState struct {
+	dir     string
+	deleted bool
+	loading bool
+	files   []dirSummaryFile
+	err     error
+}
+
+type dirSummaryLoadedMsg struct {
+	dir   string
+	files []dirSummaryFile
+	err   error
+}
+
+// wholeDirChange lists the changed files under dir, and reports whether
+// every one of them is new or every one deleted.
+func (m Model) wholeDirChange(dir string) (paths []string, deleted bool, ok bool) {
+	added, removed := 0, 0
+	for _, item := range m.fileItems {
+		if !strings.HasPrefix(item.Path, dir+"/") {
+			continue
+		}
+		paths = append(paths, item.Path)
+		switch {
+		case strings.ContainsAny(item.Status, "?A"):
+			added++
+		case strings.Contains(item.Status, "D"):
+			removed++
+		}
+	}
+	switch {
+	case len(paths) == 0:
+		return nil, false, false
+	case added == len(paths):
+		return paths, false, true
+	case removed == len(paths):
+		return paths, true, true
+	}
+	return nil, false, false
+}
+
+// openDirSummaryAtCursor loads the summary of the directory under the file
+// cursor when the whole directory is new or deleted.
+func (m *Model) openDirSummaryAtCursor(entries []fileTreeEntry) tea.Cmd {
+	if m.fileCursor < 0 || m.fileCursor >= len(entries) || !entries[m.fileCursor].IsDir {
+		return nil
+	}
+	dir := entries[m.fileCursor].Path
+	if m.dirSummary != nil && m.dirSummary.dir == dir {
+		return nil
+	}
+	m.dirSummary = nil
+	if m.reviewMode != reviewModeLocal {
+		return nil
+	}
+	paths, deleted, ok := m.wholeDirChange(dir)
+	if !ok {
+		return nil
+	}
+	m.dirSummary = &dirSummaryState{dir: dir, deleted: deleted, loading: true}
+	root := m.cwd
+	// Sizes and lines come from the side the files exist on.
+	oldRev, rev := diffToolRevs(m.diffMode)
+	if deleted {
+		rev = oldRev
+	}
+	return func() tea.Msg {
+		files := make([]dirSummaryFile, 0, len(paths))
+		for _, path := range paths {
+			content, err := readDirSummarySide(context.Background(), root, rev, path)
+			if err != nil {
+				return dirSummaryLoadedMsg{dir: dir, err: fmt.Errorf("%s: %w", path, err)}
+			}
+			files = append(files, summarizeDirFile(path, content))
+		}
+		return dirSummaryLoadedMsg{dir: dir, files: files}
+	}
+}
+
+// readDirSummarySide reads path as of rev, which is "worktree" for the
+// working file, "" for the index, or a commit.
+func readDirSummarySide(ctx context.Context, root, rev, path string) ([]byte, error) {
+	if rev == "worktree" {
+		return os.ReadFile(filepath.Join(root, filepath.FromSlash(path)))
+	}
+	content, _, err := gitint.ReadBlob(ctx, root, rev, path)
+	return content, err
+}
+
+// summarizeDirFile counts the lines of content, calling it binary by the
+// same NUL byte check git uses.
+func summarizeDirFile(path string, content []byte) dirSummaryFile {
+	f := dirSummaryFile{path: path, size: int64(len(content))}
+	if bytes.IndexByte(content[:min(len(content), 8000)], 0) >= 0 {
+		f.binary = true
+		return f
+	}
+	f.lines = bytes.Count(content, []byte("\n"))
+	if len(content) > 0 && content[len(content)-1] != '\n' {
+		f.lines++
+	}
+	return f
+}
+
+func (m Model) handleDirSummaryLoaded(msg dirSummaryLoadedMsg) (tea.Model, tea.Cmd) {
+	if m.dirSummary == nil || m.dirSummary.dir != msg.dir {
+		return m, nil
+	}
+	summary := *m.dirSummary
+	summary.loading = false
+	summary.files, summary.err = msg.files, msg.err
+	m.dirSummary = &summary
+	return m, nil
+}
+
+// dirSummaryShown reports whether the diff pane shows the directory summary
+// instead of the selected file's diff.
+func (m Model) dirSummaryShown() bool {
+	if m.dirSummary == nil || m.focus != focusFiles {
+		return false
+	}
+	entries := m.fileTreeEntries()
+	if m.fileCursor < 0 || m.fileCursor >= len(entries) {
+		return false
+	}
+	entry := entries[m.fileCursor]
+	return entry.IsDir && entry.Path == m.dirSummary.dir
+}
+
+// headline is the combined diffstat of the directory.
+func (s dirSummaryState) headline() string {
+	var size int64
+	lines, binary := 0, 0
+	for _, f := range s.files {
+		size += f.size
+		lines += f.lines
+		if f.binary {
+			binary++
+		}
+	}
+	kind, sign := "new", "+"
+	if s.deleted {
+		kind, sign = "deleted", "-"
+	}
+	text := fmt.Sprintf("%d %s file(s), %s, %s%d line(s)", len(s.files), kind, formatByteSize(size), sign, lines)
+	if binary > 0 {
+		text += fmt.Sprintf(", %d binary", binary)
+	}
+	return text
+}
+
+func (m Model) renderDirSummaryPane(width, height int) string {
+	s := m.dirSummary
+	innerW := max(1, width)
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+	color, sign, kind := lipgloss.Color("78"), "+", "new"
+	if s.deleted {
+		color, sign, kind = lipgloss.Color("203"), "-", "deleted"
+	}
+
+	var lines []string
+	switch {
+	case s.loading:
+		lines = append(lines, dim.Render("Loading…"))
+	case s.err != nil:
+		lines = append(lines, lipgloss.NewStyle().Foreground(lipgloss.Color("203")).Render(ansi.Truncate("Failed to summarize directory: "+s.err.Error(), innerW, "…")))
+	default:
+		lines = append(lines, lipgloss.NewStyle().Foreground(color).Bold(true).Render(ansi.Truncate(s.headline(), innerW, "…")), "")
+		// Leave room for the title, the headline and the hint, each with a blank line.
+		visible := max(1, height-6)
+		for i, f := range s.files {
+			if i == visible-1 && len(s.files) > visible {
+				lines = append(lines, dim.Render(fmt.Sprintf("… %d more", len(s.files)-i)))
+				break
+			}
+			count := lipgloss.NewStyle().Foreground(color).Render(fmt.Sprintf("%s%-6d", sign, f.lines))
+			if f.binary {
+				count = dim.Render(fmt.Sprintf("%-7s", "bin"))
+			}
+			size := dim.Render(fmt.Sprintf("%10s  ", formatByteSize(f.size)))
+			name := strings.TrimPrefix(f.path, s.dir+"/")
+			lines = append(lines, ansi.Truncate(count+size+name, innerW, "…"))
+		}
+	}
+	lines = append(lines, "", dim.Render(ansi.Truncate("l expands the directory; select a file for its diff", innerW, "")))
+
+	title := fmt.Sprintf("Directory: %s/ (%s)", s.dir, kind)
+	return m.renderDiffBox(width, height, title, strings.Join(lines, "\n"), true)
+}
@@ -0,0 +1,236 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"diffman/internal/comments"
+	"diffman/internal/diffview"
+)
+
+func (m *Model) closeCommentInput() {
+	m.commentInputActive = false
+	m.commentInputModel.SetValue("")
+	m.commentInputModel.Blur()
+	m.commentInputErr = ""
+	m.commentEditAnchor = nil
+	m.commentEditKey = ""
+	m.draftDirty = true
+}
+
+// stashCommentDraft remembers the dock text for its anchor when the dock is dismissed.
+func (m *Model) stashCommentDraft() {
+	key := m.commentEditKey
+	if key == "" {
+		return
+	}
+	draft, ok := m.currentDraft()
+	existing, exists := m.comments[key]
+	if !ok || (exists && existing.Body == draft.Body) {
+		m.dropStashedDraft(key)
+		return
+	}
+	if m.anchorDrafts == nil {
+		m.anchorDrafts = make(map[string]comments.Draft)
+	}
+	m.anchorDrafts[key] = draft
+	m.saveStashedDrafts()
+}
+
+// dropStashedDraft forgets the stashed draft for key, once it is saved or emptied.
+func (m *Model) dropStashedDraft(key string) {
+	if _, ok := m.anchorDrafts[key]; !ok {
+		return
+	}
+	delete(m.anchorDrafts, key)
+	m.saveStashedDrafts()
+}
+
+// saveStashedDrafts writes the stashed drafts next to the dock's draft file
+// so they survive exits. Drafts written on a pull request stay in memory,
+// like the dock's draft, since the next start reviews local changes.
+func (m *Model) saveStashedDrafts() {
+	if m.reviewMode != reviewModeLocal {
+		return
+	}
+	keys := make([]string, 0, len(m.anchorDrafts))
+	for k := range m.anchorDrafts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	drafts := make([]comments.Draft, 0, len(keys))
+	for _, k := range keys {
+		drafts = append(drafts, m.anchorDrafts[k])
+	}
+	if err := m.commentStore.SaveStashedDrafts(drafts); err != nil {
+		m.setAlert(fmt.Sprintf("failed to save comment drafts: %v", err))
+	}
+}
+
+// loadStashedDrafts reads the drafts stashed in a previous session.
+func (m *Model) loadStashedDrafts() {
+	if m.reviewMode != reviewModeLocal {
+		return
+	}
+	drafts, err := m.commentStore.LoadStashedDrafts()
+	if err != nil {
+		m.setAlert(fmt.Sprintf("failed to load comment drafts: %v", err))
+		return
+	}
+	for _, d := range drafts {
+		if d.Path == "" || d.Body == "" {
+			continue
+		}
+		if m.anchorDrafts == nil {
+			m.anchorDrafts = make(map[string]comments.Draft, len(drafts))
+		}
+		m.anchorDrafts[comments.AnchorKey(d.Path, d.Side, d.Line)] = d
+	}
+}
+
+// applyStashedDraft fills the dock with a previously dismissed draft for key.
+func (m *Model) applyStashedDraft(key string) {
+	draft, ok := m.anchorDrafts[key]
+	if !ok {
+		return
+	}
+	m.commentInputModel.SetValue(draft.Body)
+	m.commentInputModel.CursorEnd()
+	m.setAlert("Restored unsaved draft for this line.")
+}
+
+// commentPR is the pull request new comments are written on, or 0 when
+// reviewing local changes.
+func (m Model) commentPR() int {
+	if m.reviewMode != reviewModePR || m.prCtx == nil {
+		return 0
+	}
+	return m.prCtx.Number
+}
+
+// currentDraft describes the open comment dock, if it holds any text.
+func (m Model) currentDraft() (comments.Draft, bool) {
+	if !m.commentInputActive {
+		return comments.Draft{}, false
+	}
+	body := m.commentInputModel.Value()
+	if strings.TrimSpace(body) == "" {
+		return comments.Draft{}, false
+	}
+	if m.commentEditAnchor != nil {
+		a := m.commentEditAnchor
+		return comments.Draft{Path: a.Path, Side: a.Side, Line: a.Line, Body: body, PR: m.commentPR()}, true
+	}
+	if existing, ok := m.comments[m.commentEditKey]; ok {
+		return comments.Draft{Path: existing.Path, Side: existing.Side, Line: existing.Line, Body: body, PR: existing.PR}, true
+	}
+	return comments.Draft{}, false
+}
+
+// flushDraft mirrors the comment dock into the draft file so it survives exits.
+func (m *Model) flushDraft() {
+	if !m.draftDirty {
+		return
+	}
+	m.draftDirty = false
+
+	var err error
+	if draft, ok := m.currentDraft(); ok {
+		err = m.commentStore.SaveDraft(draft)
+	} else {
+		err = m.commentStore.ClearDraft()
+	}
+	if err != nil {
+		m.setAlert(fmt.Sprintf("failed to save comment draft: %v", err))
+	}
+}
+
+// restorePendingDraft reopens the comment dock with a draft left over from a previous session.
+func (m *Model) restorePendingDraft(path string) tea.Cmd {
+	if m.pendingDraft == nil || m.pendingDraft.Path != path {
+		return nil
+	}
+	draft := *m.pendingDraft
+	m.pendingDraft = nil
+	if !m.jumpToCommentAnchor(commentAnchor{Path: draft.Path, Side: draft.Side, Line: draft.Line}) {
+		m.setAlert(fmt.Sprintf("Comment draft for %s:%d no longer matches the diff.", draft.Path, draft.Line))
+		return nil
+	}
+	cmd := m.startCommentEdit(false)
+	if !m.commentInputActive {
+		return cmd
+	}
+	m.commentInputModel.SetValue(draft.Body)
+	m.commentInputModel.CursorEnd()
+	m.setAlert("Restored unsaved comment draft.")
+	return cmd
+}
+
+func (m Model) handleQuitConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.Type == tea.KeyEsc {
+		m.quitConfirmModal = false
+		return m, nil
+	}
+	if msg.Type != tea.KeyRunes {
+		return m, nil
+	}
+	switch msg.String() {
+	case "s", "S":
+		m.quitConfirmModal = false
+		hook := m.saveCommentInput()
+		if m.commentInputActive {
+			// Saving failed; keep the dock open so the error is visible.
+			return m, nil
+		}
+		m.flushDraft()
+		if hook != nil {
+			return m, tea.Sequence(hook, tea.Quit)
+		}
+		return m, tea.Quit
+	case "d", "D":
+		m.quitConfirmModal = false
+		m.closeCommentInput()
+		m.flushDraft()
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m Model) renderQuitConfirmModal() string {
+	body := strings.Join([]string{
+		"The comment dock has unsaved text.",
+		"",
+		lipgloss.NewStyle().Foreground(lipgloss.Color("78")).Render("S save comment and quit"),
+		lipgloss.NewStyle().Foreground(lipgloss.Color("203")).Render("D discard draft and quit"),
+		"",
+		lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Render("Esc keep editing"),
+	}, "\n")
+
+	width := 54
+	if m.width > 0 && m.width-6 < width {
+		width = max(24, m.width-6)
+	}
+
+	title := lipgloss.NewStyle().
+		Width(max(1, width-2)).
+		Padding(0, 1).
+		Bold(true).
+		Foreground(lipgloss.Color("230")).
+		Background(lipgloss.Color("214")).
+		Render("Quit")
+
+	bodyBlock := lipgloss.NewStyle().
+		Width(max(1, width-2)).
+		Padding(1, 2).
+		Render(body)
+
+	return lipgloss.NewStyle().
+		Width(width).
+		Border(diffview.Border(lipgloss.RoundedBorder())).
+		BorderForeground(lipgloss.Color("214")).
+		Render(title + "\n" + bodyBlock)
+}
@@ -0,0 +1,64 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"diffman/internal/util"
+)
+
+// loadErrorLines lays out a failed load for a pane of the given width: what
+// failed, the command that failed and what it printed, and how to retry.
+func loadErrorLines(title string, err error, width int) []string {
+	heading := lipgloss.NewStyle().Foreground(lipgloss.Color("203")).Bold(true)
+	label := lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+	wrap := lipgloss.NewStyle().Width(max(1, width))
+
+	lines := []string{heading.Render(title), ""}
+	var cmdErr *util.CommandError
+	if errors.As(err, &cmdErr) {
+		lines = append(lines, label.Render("Command:"), wrap.Render("  "+cmdErr.CommandLine()), "")
+		output := strings.TrimSpace(cmdErr.Output)
+		if output == "" {
+			output = cmdErr.Err.Error()
+		}
+		lines = append(lines, label.Render("Output:"))
+		for _, line := range strings.Split(output, "\n") {
+			lines = append(lines, wrap.Render("  "+line))
+		}
+	} else {
+		lines = append(lines, wrap.Render(err.Error()))
+	}
+	return append(lines, "", "Press F5 to retry.")
+}
+
+// showLoadError puts the error panel of the failed file list or diff load in
+// the diff panes.
+func (m *Model) showLoadError() {
+	var lines []string
+	switch {
+	case m.filesErr != nil:
+		lines = loadErrorLines("Failed to list changed files", m.filesErr, m.newView.Width)
+	case m.diffErr != nil:
+		lines = loadErrorLines(fmt.Sprintf("Failed to load diff for %s", m.diffErrPath), m.diffErr, m.newView.Width)
+	default:
+		return
+	}
+	content := strings.Join(lines, "\n")
+	m.oldView.SetContent(content)
+	m.newView.SetContent(content)
+}
+
+// retryFailedLoad runs the load shown in the error panel again.
+func (m Model) retryFailedLoad() (tea.Model, tea.Cmd) {
+	if m.filesErr != nil {
+		m.loadingFiles = true
+		return m, m.loadFilesCmd()
+	}
+	m.loadingDiff = true
+	return m, m.loadDiffCmd(m.diffErrPath)
+}
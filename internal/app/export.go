@@ -0,0 +1,368 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"diffman/internal/comments"
+	"diffman/internal/config"
+	"diffman/internal/diffview"
+	gitint "diffman/internal/git"
+)
+
+const (
+	defaultExportTitle = "Review comments:"
+	// defaultExportFile is offered in the export dock, relative to the repository root.
+	defaultExportFile = "diffman-review.txt"
+	// defaultQuickfixFile is offered instead when the dock is set to the quickfix format.
+	defaultQuickfixFile = "diffman-review.qf"
+)
+
+// Export formats for W and -export. Quickfix is the "path:line:col: message"
+// form read by vim's quickfix list and editor problem panes.
+const (
+	ExportFormatPlain    = "plain"
+	ExportFormatQuickfix = "quickfix"
+)
+
+// IsExportFormat reports whether format names a supported export format.
+func IsExportFormat(format string) bool {
+	return format == ExportFormatPlain || format == ExportFormatQuickfix
+}
+
+func exportDefaultFile(format string) string {
+	if format == ExportFormatQuickfix {
+		return defaultQuickfixFile
+	}
+	return defaultExportFile
+}
+
+// exportFrame is the title and footer of plain exports, from export_title
+// and export_footer. Both may use {name}, {date} and {branch}.
+type exportFrame struct {
+	title, footer string
+}
+
+// fill returns f with its placeholders replaced, and the default title when
+// none is set. branch stands for {branch} when set, as the PR's head does in
+// PR mode; otherwise the checked-out branch does. Reading the name and the
+// branch runs git, so fill runs off the UI loop.
+func (f exportFrame) fill(ctx context.Context, root, branch string) exportFrame {
+	if f.title == "" {
+		f.title = defaultExportTitle
+	}
+	text := f.title + f.footer
+	vars := []string{"{date}", time.Now().Format("2006-01-02")}
+	if strings.Contains(text, "{name}") {
+		name, _ := gitint.UserName(ctx, root)
+		vars = append(vars, "{name}", name)
+	}
+	if strings.Contains(text, "{branch}") {
+		if branch == "" {
+			head, _ := gitint.ReadHead(ctx, root)
+			branch = head.Branch
+			if head.Detached {
+				branch = head.ShortSHA
+			}
+		}
+		vars = append(vars, "{branch}", branch)
+	}
+	r := strings.NewReplacer(vars...)
+	f.title, f.footer = r.Replace(f.title), r.Replace(f.footer)
+	return f
+}
+
+// withFooter ends a non-empty plain export with the footer, after any
+// checklist or statistics summary.
+func (f exportFrame) withFooter(text string) string {
+	footer := strings.TrimSpace(f.footer)
+	if text == "" || footer == "" {
+		return text
+	}
+	return text + "\n\n" + footer
+}
+
+// exportFrameBranch is the {branch} of exports from m: the PR's head branch
+// in PR mode, or "" for the checked-out one.
+func (m Model) exportFrameBranch() string {
+	if m.reviewMode == reviewModePR && m.prCtx != nil {
+		return m.prCtx.HeadRef
+	}
+	return ""
+}
+
+// formatExport renders snapshot in format; the title, headings, context
+// blocks and permalinks only appear in the plain format.
+func formatExport(format, title string, snapshot []comments.Comment, opts comments.ExportOptions) string {
+	if format == ExportFormatQuickfix {
+		return comments.ExportQuickfix(snapshot)
+	}
+	return comments.ExportPlainWithOptions(snapshot, title, opts)
+}
+
+// exportOptions shapes plain exports as export_group and export_context
+// ask; callers add the permalink function off the UI loop.
+func (m Model) exportOptions() comments.ExportOptions {
+	return comments.ExportOptions{Group: m.exportGroup, NoContext: m.exportNoContext}
+}
+
+// exportFileContents ends non-empty exports with a newline.
+func exportFileContents(text string) string {
+	if text == "" {
+		return ""
+	}
+	return text + "\n"
+}
+
+type exportFileResultMsg struct {
+	path     string
+	exported []comments.Comment
+	err      error
+}
+
+func newExportInput() textinput.Model {
+	input := textinput.New()
+	input.Prompt = ""
+	input.Placeholder = defaultExportFile
+	input.CharLimit = 4096
+	input.Cursor.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("51"))
+	input.PlaceholderStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+	return input
+}
+
+// resolveExportPath expands a leading ~ and makes relative paths relative to root.
+func resolveExportPath(root, path string) (string, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return "", fmt.Errorf("export path is empty")
+	}
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(home, strings.TrimPrefix(path, "~"))
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+	return filepath.Clean(path), nil
+}
+
+// writeExportFile writes the export; summary and the frame's footer, if any,
+// are appended to the plain format only, since quickfix files hold one
+// location per line.
+func writeExportFile(path, format string, frame exportFrame, snapshot []comments.Comment, opts comments.ExportOptions, summary string) error {
+	text := formatExport(format, frame.title, snapshot, opts)
+	if format != ExportFormatQuickfix {
+		text = frame.withFooter(withExportSummary(text, summary))
+	}
+	return os.WriteFile(path, []byte(exportFileContents(text)), 0o644)
+}
+
+func (m Model) handleExportToFile() (tea.Model, tea.Cmd) {
+	if snapshot, scope := m.exportScope(); len(snapshot) == 0 {
+		m.setAlert(exportScopeEmptyMessage(scope))
+		return m, nil
+	}
+	m.exportInputActive = true
+	m.exportInputErr = ""
+	m.exportInputModel.Placeholder = exportDefaultFile(m.exportFormat)
+	m.exportInputModel.SetValue(m.exportPath)
+	cmd := m.exportInputModel.Focus()
+	m.exportInputModel.CursorEnd()
+	return m, cmd
+}
+
+func (m Model) handleExportInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.closeExportInput()
+		return m, nil
+	case tea.KeyTab:
+		if m.exportFormat == ExportFormatQuickfix {
+			m.exportFormat = ExportFormatPlain
+		} else {
+			m.exportFormat = ExportFormatQuickfix
+		}
+		m.exportInputModel.Placeholder = exportDefaultFile(m.exportFormat)
+		return m, nil
+	case tea.KeyEnter:
+		raw := m.exportInputModel.Value()
+		if strings.TrimSpace(raw) == "" {
+			raw = exportDefaultFile(m.exportFormat)
+		}
+		path, err := resolveExportPath(m.cwd, raw)
+		if err != nil {
+			m.exportInputErr = err.Error()
+			return m, nil
+		}
+		m.exportPath = strings.TrimSpace(raw)
+		m.closeExportInput()
+		return m, m.exportFileCmd(path)
+	}
+
+	var cmd tea.Cmd
+	m.exportInputModel, cmd = m.exportInputModel.Update(msg)
+	m.exportInputErr = ""
+	return m, cmd
+}
+
+func (m *Model) closeExportInput() {
+	m.exportInputActive = false
+	m.exportInputErr = ""
+	m.exportInputModel.Blur()
+}
+
+func (m Model) exportFileCmd(path string) tea.Cmd {
+	snapshot, _ := m.exportScope()
+	links := m.permalinkSettings()
+	format := m.exportFormat
+	summary := m.exportSummary()
+	opts := m.exportOptions()
+	frame, root, branch := m.exportFrame, m.cwd, m.exportFrameBranch()
+	return func() tea.Msg {
+		ctx := context.Background()
+		opts.Link = links.linker(ctx)
+		err := writeExportFile(path, format, frame.fill(ctx, root, branch), snapshot, opts, summary)
+		return exportFileResultMsg{path: path, exported: snapshot, err: err}
+	}
+}
+
+func redactedHint(redact bool) string {
+	if redact {
+		return ", code context redacted"
+	}
+	return ""
+}
+
+func (m Model) renderExportDock() string {
+	contentW := max(10, m.width-2)
+	bodyInnerW := max(1, contentW-4)
+	inputWidth := max(1, bodyInnerW-4)
+	input := m.exportInputModel
+	input.Width = inputWidth
+	inputBox := lipgloss.NewStyle().
+		Width(bodyInnerW).
+		MaxWidth(bodyInnerW).
+		Border(diffview.Border(lipgloss.NormalBorder())).
+		BorderForeground(lipgloss.Color("78")).
+		Padding(0, 1).
+		Render(input.View())
+	format := m.exportFormat
+	if format == "" {
+		format = ExportFormatPlain
+	}
+	hint := lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Render(
+		ansi.Truncate("Enter write (relative to repo root, overwrites) | Tab format: "+format+redactedHint(m.redactExports)+" | Esc cancel", bodyInnerW, ""),
+	)
+	bodyLines := []string{inputBox, "", hint}
+	if m.exportInputErr != "" {
+		bodyLines = append(bodyLines, "")
+		bodyLines = append(bodyLines, lipgloss.NewStyle().Foreground(lipgloss.Color("203")).Render(
+			ansi.Truncate("Error: "+m.exportInputErr, bodyInnerW, ""),
+		))
+	}
+	return m.renderDockPanel("Export to File", lipgloss.Color("78"), lipgloss.Color("78"), strings.Join(bodyLines, "\n"))
+}
+
+// ExportSettings are the -export flags, which add to the config's export
+// settings.
+type ExportSettings struct {
+	Format string
+	// Redact, or redact_exports in the config, leaves the code context and
+	// hunk header out of the export and the hook payload.
+	Redact bool
+	// Group overrides export_group when set.
+	Group string
+	// NoContext, or export_context false in the config, leaves the code
+	// block out of a plain export.
+	NoContext bool
+}
+
+// ExportComments writes the non-stale comments of the repository containing
+// cwd to w without starting the UI. output names the destination for the
+// export hook ("" for stdout). It returns how many comments were written.
+func ExportComments(ctx context.Context, cwd string, w io.Writer, output string, settings ExportSettings) (int, error) {
+	format := settings.Format
+	if !IsExportFormat(format) {
+		return 0, fmt.Errorf("unknown export format %q", format)
+	}
+	flagGroup, err := config.NormalizeExportGroup(settings.Group)
+	if err != nil {
+		return 0, err
+	}
+	repoRoot, err := gitint.DiscoverRepoRoot(ctx, cwd)
+	if err != nil {
+		return 0, err
+	}
+	gitDir, err := gitint.DiscoverGitDir(ctx, repoRoot)
+	if err != nil {
+		return 0, err
+	}
+	// A broken config should not block an export; fall back to the defaults like the UI does.
+	cfg, _, err := config.Load()
+	if err != nil {
+		cfg = config.Default()
+	}
+	key, err := commentsKey(cfg)
+	if err != nil {
+		return 0, fmt.Errorf("comments key: %w", err)
+	}
+	loaded, err := comments.NewStore(gitDir).WithKey(key).Load()
+	if err != nil && !comments.IsRecovered(err) {
+		return 0, fmt.Errorf("load comments: %w", err)
+	}
+	items, err := gitint.NewStatusService(cfg.Exclude...).ListChangedFiles(ctx, repoRoot)
+	if err != nil {
+		return 0, fmt.Errorf("list changed files: %w", err)
+	}
+	reasons, err := buildCommentStaleReasons(ctx, repoRoot, gitint.NewDiffService(cfg.Exclude...), items, loaded, gitint.DiffModeAll)
+	if err != nil {
+		return 0, fmt.Errorf("check comment anchors: %w", err)
+	}
+
+	m := Model{
+		comments:     make(map[string]comments.Comment, len(loaded)),
+		commentStale: staleMapFromReasons(reasons),
+	}
+	for _, c := range loaded {
+		m.comments[commentKey(c)] = c
+	}
+	links := permalinkSettings{enabled: cfg.Permalinks, templates: cfg.PermalinkTemplates, cwd: repoRoot}
+
+	group := cfg.ExportGroup
+	if flagGroup != "" {
+		group = flagGroup
+	}
+	snapshot := comments.OrderForExport(m.exportableComments(), group)
+	if settings.Redact || cfg.RedactExports {
+		snapshot = comments.Redact(snapshot)
+	}
+	opts := comments.ExportOptions{Group: group, NoContext: settings.NoContext || !cfg.ExportContext, Link: links.linker(ctx)}
+	frame := exportFrame{title: cfg.ExportTitle, footer: cfg.ExportFooter}.fill(ctx, repoRoot, "")
+	text := formatExport(format, frame.title, snapshot, opts)
+	if format != ExportFormatQuickfix {
+		text = frame.withFooter(text)
+	}
+	if _, err := io.WriteString(w, exportFileContents(text)); err != nil {
+		return 0, err
+	}
+	if command := cfg.Hooks[config.HookExport]; command != "" {
+		payload := hookPayload{Event: config.HookExport, Repo: repoRoot, Comments: snapshot, Output: output}
+		if err := runHook(ctx, repoRoot, command, payload); err != nil {
+			return len(snapshot), fmt.Errorf("%s hook: %w", config.HookExport, err)
+		}
+	}
+	return len(snapshot), nil
+}
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"diffman/internal/diffview"
+)
+
+// fileNameMinWidth is how much of the files pane names keep before metadata
+// columns give way.
+const fileNameMinWidth = 16
+
+// fileMetaColumn is one right-aligned column of the files pane, with a cell
+// for each visible entry.
+type fileMetaColumn struct {
+	cells []string
+	width int
+}
+
+// fileMetaColumns lays out the diffstat, comment count and review state of
+// entries[start:end] in aligned columns. A column no visible file has is left
+// out, and a narrow pane drops the diffstat first, then the comment count,
+// then the review state, so names keep fileNameMinWidth.
+func (m Model) fileMetaColumns(entries []fileTreeEntry, start, end, innerW int) []fileMetaColumn {
+	countStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("141")).Bold(true)
+	reviewStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("78"))
+	stats := fileMetaColumn{cells: make([]string, end-start)}
+	counts := fileMetaColumn{cells: make([]string, end-start)}
+	reviews := fileMetaColumn{cells: make([]string, end-start)}
+	for i := start; i < end; i++ {
+		entry := entries[i]
+		if entry.IsDir {
+			continue
+		}
+		stats.cells[i-start] = m.fileStatLabel(entry.Path)
+		if entry.Comments > 0 {
+			mark := "✎"
+			if diffview.PlainMode() {
+				mark = "*"
+			}
+			counts.cells[i-start] = countStyle.Render(fmt.Sprintf("%s%d", mark, entry.Comments))
+		}
+		if mark := m.fileReviewMark(entry.Path); mark != "" {
+			reviews.cells[i-start] = reviewStyle.Render(mark)
+		}
+	}
+
+	var columns []fileMetaColumn
+	for _, col := range []fileMetaColumn{stats, counts, reviews} {
+		for _, cell := range col.cells {
+			col.width = max(col.width, lipgloss.Width(cell))
+		}
+		if col.width > 0 {
+			columns = append(columns, col)
+		}
+	}
+	for len(columns) > 0 && innerW-fileMetaWidth(columns)-1 < fileNameMinWidth {
+		columns = columns[1:]
+	}
+	return columns
+}
+
+// fileMetaWidth is the width of columns joined by single spaces.
+func fileMetaWidth(columns []fileMetaColumn) int {
+	if len(columns) == 0 {
+		return 0
+	}
+	w := len(columns) - 1
+	for _, col := range columns {
+		w += col.width
+	}
+	return w
+}
+
+// fileMetaCells renders row of columns, each cell right-aligned.
+func fileMetaCells(columns []fileMetaColumn, row int) string {
+	cells := make([]string, len(columns))
+	for i, col := range columns {
+		cell := col.cells[row]
+		cells[i] = strings.Repeat(" ", col.width-lipgloss.Width(cell)) + cell
+	}
+	return strings.Join(cells, " ")
+}
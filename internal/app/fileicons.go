@@ -0,0 +1,83 @@
+package app
+
+import (
+	"path"
+	"strings"
+
+	"diffman/internal/diffview"
+)
+
+// fileIcon is how the file tree marks a kind of file: a Nerd Font glyph,
+// or a two-letter ASCII marker when icons are off or in plain mode.
+type fileIcon struct {
+	glyph, ascii string
+}
+
+var defaultFileIcon = fileIcon{"", "--"}
+
+// fileIconsByName match whole file names before fileIconsByExt is tried.
+var fileIconsByName = map[string]fileIcon{
+	"dockerfile":     {"", "dk"},
+	"makefile":       {"", "mk"},
+	"go.mod":         {"", "go"},
+	"go.sum":         {"", "go"},
+	".gitignore":     {"", "gt"},
+	".gitattributes": {"", "gt"},
+	".gitmodules":    {"", "gt"},
+}
+
+var fileIconsByExt = map[string]fileIcon{
+	".go":    {"", "go"},
+	".py":    {"", "py"},
+	".js":    {"", "js"},
+	".mjs":   {"", "js"},
+	".jsx":   {"", "js"},
+	".ts":    {"", "ts"},
+	".tsx":   {"", "ts"},
+	".rs":    {"", "rs"},
+	".c":     {"", "c "},
+	".h":     {"", "c "},
+	".cc":    {"", "c+"},
+	".cpp":   {"", "c+"},
+	".hpp":   {"", "c+"},
+	".java":  {"", "jv"},
+	".rb":    {"", "rb"},
+	".sh":    {"", "sh"},
+	".bash":  {"", "sh"},
+	".zsh":   {"", "sh"},
+	".html":  {"", "ht"},
+	".css":   {"", "cs"},
+	".scss":  {"", "cs"},
+	".json":  {"", "{}"},
+	".yaml":  {"", "cf"},
+	".yml":   {"", "cf"},
+	".toml":  {"", "cf"},
+	".ini":   {"", "cf"},
+	".md":    {"", "md"},
+	".txt":   {"", "tx"},
+	".sql":   {"", "db"},
+	".png":   {"", "im"},
+	".jpg":   {"", "im"},
+	".jpeg":  {"", "im"},
+	".gif":   {"", "im"},
+	".svg":   {"", "im"},
+	".lock":  {"", "lk"},
+	".proto": {"", "pb"},
+}
+
+// fileIconFor returns the marker for the file name, keyed by its name or
+// extension: the Nerd Font glyph with icons on, else the ASCII marker.
+func fileIconFor(name string, icons bool) string {
+	base := strings.ToLower(path.Base(name))
+	icon, ok := fileIconsByName[base]
+	if !ok {
+		icon, ok = fileIconsByExt[path.Ext(base)]
+	}
+	if !ok {
+		icon = defaultFileIcon
+	}
+	if !icons || diffview.PlainMode() {
+		return icon.ascii
+	}
+	return icon.glyph
+}
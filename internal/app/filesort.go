@@ -0,0 +1,113 @@
+package app
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// fileSortMode orders the files pane; exports and the comments view keep path
+// order.
+type fileSortMode int
+
+const (
+	fileSortByPath fileSortMode = iota
+	fileSortByStatus
+	fileSortBySize
+	fileSortByModified
+	fileSortByComments
+	fileSortModes
+)
+
+func (s fileSortMode) String() string {
+	switch s {
+	case fileSortByStatus:
+		return "status"
+	case fileSortBySize:
+		return "size"
+	case fileSortByModified:
+		return "modified"
+	case fileSortByComments:
+		return "comments"
+	default:
+		return "path"
+	}
+}
+
+// fileStatusRanks puts conflicts first and untracked files last.
+var fileStatusRanks = map[string]int{"U": 0, "M": 1, "A": 2, "R": 3, "D": 4, "?": 5}
+
+func fileStatusRank(status string) int {
+	if rank, ok := fileStatusRanks[plainFileStatusSymbol(status)]; ok {
+		return rank
+	}
+	return len(fileStatusRanks)
+}
+
+// compare orders two files by the sort key alone; callers break ties by name.
+// Larger changes, newer files and more comments come first.
+func (s fileSortMode) compare(a, b fileTreeFile) int {
+	switch s {
+	case fileSortByStatus:
+		return cmp.Compare(fileStatusRank(a.Status), fileStatusRank(b.Status))
+	case fileSortBySize:
+		return cmp.Compare(b.Size, a.Size)
+	case fileSortByModified:
+		return b.MTime.Compare(a.MTime)
+	case fileSortByComments:
+		return cmp.Compare(b.Comments, a.Comments)
+	}
+	return 0
+}
+
+// firstTreeFile is the file under dir that sorts first, which places the
+// directory among its siblings.
+func (s fileSortMode) firstTreeFile(dir *fileTreeDir) (fileTreeFile, bool) {
+	var first fileTreeFile
+	found := false
+	consider := func(f fileTreeFile) {
+		if !found || s.compare(f, first) < 0 {
+			first, found = f, true
+		}
+	}
+	for _, f := range dir.Files {
+		consider(f)
+	}
+	for _, child := range dir.Dirs {
+		if f, ok := s.firstTreeFile(child); ok {
+			consider(f)
+		}
+	}
+	return first, found
+}
+
+// cycleFileSort moves to the next sort mode, keeping the cursor on the
+// selected file. Modification times are only known for local changes.
+func (m *Model) cycleFileSort() {
+	m.fileSort = (m.fileSort + 1) % fileSortModes
+	if m.fileSort == fileSortByModified && m.reviewMode != reviewModeLocal {
+		m.fileSort++
+	}
+	m.loadFileMTimes()
+	m.syncFileCursorToSelectedPath()
+	m.ensureFileCursorVisible(m.fileTreeEntries())
+	m.setAlert(fmt.Sprintf("Files sorted by %s.", m.fileSort))
+}
+
+// loadFileMTimes reads the modification times the modified sort orders by.
+// Deleted files have none and sort last.
+func (m *Model) loadFileMTimes() {
+	if m.fileSort != fileSortByModified {
+		m.fileMTimes = nil
+		return
+	}
+	mtimes := make(map[string]time.Time, len(m.fileItems))
+	for _, item := range m.fileItems {
+		if info, err := os.Stat(filepath.Join(m.cwd, filepath.FromSlash(item.Path))); err == nil {
+			mtimes[item.Path] = info.ModTime()
+		}
+	}
+	m.fileMTimes = mtimes
+}
@@ -0,0 +1,69 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"diffman/internal/debuglog"
+	"diffman/internal/diffview"
+	gitint "diffman/internal/git"
+)
+
+// fileStatsLoadedMsg carries the per-file line counts, which load alongside
+// git status so the file tree can show before they arrive.
+type fileStatsLoadedMsg struct {
+	mode  gitint.DiffMode
+	stats map[string]gitint.FileStat
+	err   error
+}
+
+func (m Model) loadFileStatsCmd() tea.Cmd {
+	cwd := m.cwd
+	mode := m.diffMode
+	whitespace := m.skipWhitespaceHunks
+	service := m.statusSvc
+	return func() tea.Msg {
+		stats, err := service.DiffStats(context.Background(), cwd, mode, whitespace)
+		return fileStatsLoadedMsg{mode: mode, stats: stats, err: err}
+	}
+}
+
+// handleFileStatsLoaded stores the line counts. Counts for another diff mode
+// or review mode than the current one are dropped; a failure only costs the
+// counts, so it is logged rather than shown.
+func (m Model) handleFileStatsLoaded(msg fileStatsLoadedMsg) (tea.Model, tea.Cmd) {
+	if m.reviewMode != reviewModeLocal || msg.mode != m.diffMode {
+		return m, nil
+	}
+	if msg.err != nil {
+		debuglog.Printf("load file stats failed: %v", msg.err)
+		return m, nil
+	}
+	m.fileStats = msg.stats
+	return m, nil
+}
+
+// fileStatLabel renders the line counts of path for the file tree, or ""
+// while they are unknown.
+func (m Model) fileStatLabel(path string) string {
+	if m.reviewMode != reviewModeLocal {
+		return ""
+	}
+	stat, ok := m.fileStats[path]
+	if !ok {
+		return ""
+	}
+	gray := lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+	if stat.Binary {
+		return gray.Render("bin")
+	}
+	label := lipgloss.NewStyle().Foreground(lipgloss.Color("78")).Render(fmt.Sprintf("+%d", stat.Added)) +
+		" " + lipgloss.NewStyle().Foreground(lipgloss.Color("203")).Render(fmt.Sprintf("-%d", stat.Deleted))
+	if stat.WhitespaceOnly {
+		label += gray.Render(" " + diffview.WhitespaceBadge)
+	}
+	return label
+}
@@ -0,0 +1,59 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"diffman/internal/comments"
+	"diffman/internal/config"
+)
+
+// commentAgeDays is how many whole days ago c was written.
+func commentAgeDays(c comments.Comment, now time.Time) int {
+	return int(now.Sub(c.CreatedAt) / (24 * time.Hour))
+}
+
+// followUpNotice is the startup alert for overdue follow-ups, or "" when
+// there are none.
+func followUpNotice(overdue []comments.Comment, now time.Time) string {
+	if len(overdue) == 0 {
+		return ""
+	}
+	oldest := overdue[0]
+	return fmt.Sprintf("%d open follow-up(s); the oldest, on %s:%d, is %d day(s) old. Run diffman check to list them.",
+		len(overdue), oldest.Path, oldest.Line, commentAgeDays(oldest, now))
+}
+
+// Check writes the open follow-up comments of the repository containing cwd
+// that are at least days days old, one per line, oldest first, and returns
+// how many there are. A negative days uses follow_up_days from the config.
+func Check(ctx context.Context, cwd string, w io.Writer, days int) (int, error) {
+	s, err := newRPCServer(ctx, cwd)
+	if err != nil {
+		return 0, err
+	}
+	if days < 0 {
+		// A broken config should not block the check; newRPCServer falls back the same way.
+		days = config.DefaultFollowUpDays
+		if cfg, _, err := config.Load(); err == nil {
+			days = cfg.FollowUpDays
+		}
+	}
+	all, err := s.store.Load()
+	if err != nil && !comments.IsRecovered(err) {
+		return 0, fmt.Errorf("load comments: %w", err)
+	}
+	now := time.Now()
+	overdue := comments.OverdueFollowUps(all, now, days)
+	for _, c := range overdue {
+		body := strings.ReplaceAll(strings.TrimSpace(c.Body), "\n", " / ")
+		fmt.Fprintf(w, "%s %s:%d, %d day(s) old: %s\n", c.Path, c.Side, c.Line, commentAgeDays(c, now), body)
+	}
+	if len(overdue) == 0 {
+		fmt.Fprintf(w, "No open follow-ups at least %d day(s) old.\n", days)
+	}
+	return len(overdue), nil
+}
@@ -0,0 +1,96 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"diffman/internal/comments"
+	"diffman/internal/config"
+	gitint "diffman/internal/git"
+)
+
+func TestCheckListsOverdueFollowUps(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if os.Getenv("GIT_DIR") != "" {
+		t.Skip("GIT_DIR is set")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	repo := t.TempDir()
+	gitCmd(t, repo, "init", "-q")
+	gitDir, err := gitint.DiscoverGitDir(t.Context(), repo)
+	if err != nil {
+		t.Fatalf("DiscoverGitDir() error = %v", err)
+	}
+	now := time.Now()
+	list := []comments.Comment{
+		{Path: "a.go", Side: comments.SideNew, Line: 4, Body: "follow-up: rerun the benchmark", CreatedAt: now.AddDate(0, 0, -10)},
+		{Path: "b.go", Side: comments.SideNew, Line: 2, Body: "follow-up: ask about the API", CreatedAt: now.AddDate(0, 0, -1)},
+		{Path: "c.go", Side: comments.SideOld, Line: 9, Body: "why?", CreatedAt: now.AddDate(0, 0, -20)},
+	}
+	if err := comments.NewStore(gitDir).Save(list); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	var out bytes.Buffer
+	count, err := Check(t.Context(), repo, &out, -1)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if count != 1 || out.String() != "a.go new:4, 10 day(s) old: follow-up: rerun the benchmark\n" {
+		t.Fatalf("unexpected check with the default age: %d\n%s", count, out.String())
+	}
+
+	out.Reset()
+	if count, _ := Check(t.Context(), repo, &out, 0); count != 2 || !strings.Contains(out.String(), "b.go new:2") {
+		t.Fatalf("expected every open follow-up with -days 0, got %d\n%s", count, out.String())
+	}
+
+	if got := followUpNotice(comments.OverdueFollowUps(list, now, 7), now); !strings.Contains(got, "1 open follow-up(s)") || !strings.Contains(got, "a.go:4") {
+		t.Fatalf("unexpected startup notice %q", got)
+	}
+}
+
+func TestBrokenConfigFallsBackToDefaults(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if os.Getenv("GIT_DIR") != "" {
+		t.Skip("GIT_DIR is set")
+	}
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	if err := os.MkdirAll(filepath.Join(home, "diffman"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, "diffman", "config.json"), []byte("{broken"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	repo := t.TempDir()
+	gitCmd(t, repo, "init", "-q")
+	gitDir, err := gitint.DiscoverGitDir(t.Context(), repo)
+	if err != nil {
+		t.Fatalf("DiscoverGitDir() error = %v", err)
+	}
+	list := []comments.Comment{{Path: "a.go", Side: comments.SideNew, Line: 4, Body: "follow-up: rerun the benchmark", CreatedAt: time.Now().AddDate(0, 0, -1)}}
+	if err := comments.NewStore(gitDir).Save(list); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	m, err := NewModelWithOptions(Options{Repo: repo})
+	if err != nil {
+		t.Fatalf("NewModelWithOptions() error = %v", err)
+	}
+	if !strings.Contains(m.alertMsg, "failed to load config") {
+		t.Fatalf("expected the config error alert, got %q", m.alertMsg)
+	}
+	if !m.permalinks || m.exportNoContext || m.contextLines != config.DefaultContextLines {
+		t.Fatalf("expected default permalinks, export context and context lines, got %v %v %d", m.permalinks, m.exportNoContext, m.contextLines)
+	}
+}
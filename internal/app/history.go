@@ -0,0 +1,292 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"diffman/internal/comments"
+	"diffman/internal/diffview"
+	gitint "diffman/internal/git"
+	"diffman/internal/history"
+)
+
+// archiveReview saves list with the metadata of the current review target.
+func (m Model) archiveReview(outcome string, list []comments.Comment) (history.Review, error) {
+	r := history.Review{
+		Outcome:  outcome,
+		Repo:     m.cwd,
+		Comments: append([]comments.Comment(nil), list...),
+	}
+	if m.reviewMode == reviewModePR && m.prCtx != nil {
+		pr := m.prCtx
+		r.PR = &history.PR{
+			Owner:   pr.Owner,
+			Repo:    pr.Repo,
+			Number:  pr.Number,
+			Title:   pr.Title,
+			URL:     pr.URL,
+			BaseRef: pr.BaseRef,
+			HeadRef: pr.HeadRef,
+		}
+		r.Branch = pr.HeadRef
+		r.HeadSHA = pr.HeadSHA
+		r.BaseSHA = pr.BaseSHA
+	} else {
+		r.Branch = m.head.Branch
+		// Local diffs are against HEAD, so HEAD is the base.
+		if sha, err := gitint.ReadHeadSHA(context.Background(), m.cwd); err == nil {
+			r.BaseSHA = sha
+			r.HeadSHA = sha
+		}
+	}
+	return m.historyStore.Save(r)
+}
+
+func (m Model) handleArchiveReview() (tea.Model, tea.Cmd) {
+	all := m.sortedComments()
+	if len(all) == 0 {
+		m.setAlert("No comments to archive.")
+		return m, nil
+	}
+	r, err := m.archiveReview("archived", all)
+	if err != nil {
+		m.setAlert(fmt.Sprintf("failed to archive review: %v", err))
+		return m, nil
+	}
+	m.setAlert(fmt.Sprintf("Archived %d comment(s) as %s. C clears them to start fresh.", len(all), r.ID))
+	m.openStats()
+	return m, nil
+}
+
+func (m Model) openHistory() (tea.Model, tea.Cmd) {
+	items, err := m.historyStore.List()
+	if history.IsSkipped(err) {
+		m.setAlert(err.Error())
+	} else if err != nil {
+		m.setAlert(fmt.Sprintf("failed to load review history: %v", err))
+		return m, nil
+	}
+	m.historyOpen = true
+	m.historyItems = items
+	m.historyCursor = 0
+	m.historyViewing = false
+	m.historyScroll = 0
+	return m, nil
+}
+
+func (m Model) handleHistory(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.historyViewing {
+		return m.handleHistoryReview(msg)
+	}
+	switch {
+	case msg.Type == tea.KeyEsc, isRuneKey(msg, "q"), key.Matches(msg, m.keys.History):
+		m.historyOpen = false
+	case key.Matches(msg, m.keys.Down):
+		if m.historyCursor < len(m.historyItems)-1 {
+			m.historyCursor++
+		}
+	case key.Matches(msg, m.keys.Up):
+		if m.historyCursor > 0 {
+			m.historyCursor--
+		}
+	case key.Matches(msg, m.keys.Open):
+		if len(m.historyItems) > 0 {
+			m.historyViewing = true
+			m.historyScroll = 0
+		}
+	}
+	return m, nil
+}
+
+// handleHistoryReview drives the read-only view of one archived review.
+func (m Model) handleHistoryReview(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	r := m.historyItems[m.historyCursor]
+	page := m.historyPageSize()
+	maxScroll := max(0, len(m.historyReviewLines(r, 80))-page)
+	switch {
+	case msg.Type == tea.KeyEsc, isRuneKey(msg, "q"):
+		m.historyViewing = false
+		return m, nil
+	case key.Matches(msg, m.keys.History):
+		m.historyOpen = false
+		m.historyViewing = false
+		return m, nil
+	case key.Matches(msg, m.keys.Down), key.Matches(msg, m.keys.ScrollDown):
+		m.historyScroll++
+	case key.Matches(msg, m.keys.Up), key.Matches(msg, m.keys.ScrollUp):
+		m.historyScroll--
+	case key.Matches(msg, m.keys.PageDown):
+		m.historyScroll += max(1, page-1)
+	case key.Matches(msg, m.keys.PageUp):
+		m.historyScroll -= max(1, page-1)
+	case key.Matches(msg, m.keys.Export):
+		return m, m.copyArchivedReviewCmd(r)
+	case key.Matches(msg, m.keys.ExportFile):
+		return m, m.writeArchivedReviewCmd(r)
+	}
+	m.historyScroll = min(max(0, m.historyScroll), maxScroll)
+	return m, nil
+}
+
+func archivedReviewTitle(r history.Review) string {
+	if r.PR != nil {
+		return fmt.Sprintf("Review comments (PR #%d, %s):", r.PR.Number, r.ArchivedAt.Format("2006-01-02"))
+	}
+	return fmt.Sprintf("Review comments (%s):", r.ArchivedAt.Format("2006-01-02"))
+}
+
+func (m Model) copyArchivedReviewCmd(r history.Review) tea.Cmd {
+	okMsg := fmt.Sprintf("Copied %d archived comment(s) to clipboard.", len(r.Comments))
+	notice := m.tmuxNotice(okMsg)
+	opts := m.exportOptions()
+	snapshot := comments.OrderForExport(r.Comments, opts.Group)
+	return func() tea.Msg {
+		text := comments.ExportPlainWithOptions(snapshot, archivedReviewTitle(r), opts)
+		err := copyExport(context.Background(), text, notice)
+		return clipboardResultMsg{okMsg: okMsg, exported: snapshot, err: err}
+	}
+}
+
+// writeArchivedReviewCmd writes the archived review next to the default
+// export file, named after its archive ID. An existing file is never
+// overwritten; a numbered name is used instead.
+func (m Model) writeArchivedReviewCmd(r history.Review) tea.Cmd {
+	base := filepath.Join(m.cwd, fmt.Sprintf("diffman-review-%s", r.ID))
+	opts := m.exportOptions()
+	snapshot := comments.OrderForExport(r.Comments, opts.Group)
+	return func() tea.Msg {
+		text := comments.ExportPlainWithOptions(snapshot, archivedReviewTitle(r), opts)
+		path, err := writeNewFile(base, ".txt", []byte(text+"\n"))
+		return exportFileResultMsg{path: path, exported: snapshot, err: err}
+	}
+}
+
+// writeNewFile creates base+ext, or base-2+ext and so on when that exists,
+// and returns the path it wrote.
+func writeNewFile(base, ext string, data []byte) (string, error) {
+	path := base + ext
+	for i := 2; ; i++ {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if errors.Is(err, os.ErrExist) {
+			path = fmt.Sprintf("%s-%d%s", base, i, ext)
+			continue
+		}
+		if err != nil {
+			return path, err
+		}
+		if _, err := f.Write(data); err != nil {
+			f.Close()
+			return path, err
+		}
+		return path, f.Close()
+	}
+}
+
+func (m Model) historyPageSize() int {
+	return max(1, m.height*2/3-8)
+}
+
+func (m Model) historyReviewLines(r history.Review, width int) []string {
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+	var meta []string
+	if r.PR != nil {
+		meta = append(meta, fmt.Sprintf("%s/%s PR #%d %s → %s", r.PR.Owner, r.PR.Repo, r.PR.Number, r.PR.HeadRef, r.PR.BaseRef))
+	} else if r.Branch != "" {
+		meta = append(meta, "branch "+r.Branch)
+	}
+	if r.BaseSHA != "" {
+		meta = append(meta, "base "+shortSHA(r.BaseSHA))
+	}
+	if r.HeadSHA != "" && r.HeadSHA != r.BaseSHA {
+		meta = append(meta, "head "+shortSHA(r.HeadSHA))
+	}
+	meta = append(meta, r.Outcome)
+	lines := []string{dim.Render(ansi.Truncate(strings.Join(meta, " · "), width, "…")), ""}
+	for _, c := range r.Comments {
+		head := fmt.Sprintf("%s %s:%d | ", c.Path, c.Side.String(), c.Line)
+		if c.Author != "" {
+			head += "@" + c.Author + ": "
+		}
+		line := dim.Render(head) + renderCommentSummary(c.Body, lipgloss.NewStyle())
+		lines = append(lines, ansi.Truncate(line, width, "…"))
+	}
+	return lines
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 12 {
+		return sha[:12]
+	}
+	return sha
+}
+
+func (m Model) renderHistoryModal() string {
+	width := max(24, m.width-10)
+	innerW := max(1, width-6)
+	page := m.historyPageSize()
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+
+	title := "Review History"
+	var lines []string
+	var hint string
+	if m.historyViewing {
+		r := m.historyItems[m.historyCursor]
+		title = "Archived Review " + r.ID + " (read-only)"
+		all := m.historyReviewLines(r, innerW)
+		start := min(m.historyScroll, max(0, len(all)-1))
+		lines = all[start:min(len(all), start+page)]
+		hint = "j/k scroll | y copy export | W write to file | Esc back"
+	} else {
+		if len(m.historyItems) == 0 {
+			lines = append(lines, "No archived reviews yet. A archives the current comments.")
+		}
+		for i, r := range m.historyItems {
+			marker := "  "
+			style := lipgloss.NewStyle()
+			if i == m.historyCursor {
+				marker = "> "
+				style = style.Foreground(lipgloss.Color("39")).Bold(true)
+			}
+			lines = append(lines, style.Render(ansi.Truncate(marker+r.Label(), innerW, "…")))
+		}
+		hint = "j/k move | Enter open | H/Esc close"
+	}
+	lines = append(lines, "", dim.Render(hint))
+
+	titleBlock := lipgloss.NewStyle().
+		Width(max(1, width-2)).
+		Padding(0, 1).
+		Bold(true).
+		Foreground(lipgloss.Color("230")).
+		Background(lipgloss.Color("99")).
+		Render(title)
+
+	bodyBlock := lipgloss.NewStyle().
+		Width(max(1, width-2)).
+		Padding(1, 2).
+		Render(strings.Join(lines, "\n"))
+
+	return lipgloss.NewStyle().
+		Width(width).
+		Border(diffview.Border(lipgloss.RoundedBorder())).
+		BorderForeground(lipgloss.Color("99")).
+		Render(titleBlock + "\n" + bodyBlock)
+}
+
+// submittedOutcome describes a PR submission for the history archive.
+func submittedOutcome(event string) string {
+	event = strings.ToLower(strings.ReplaceAll(event, "_", " "))
+	if event == "" {
+		return "submitted"
+	}
+	return "submitted (" + event + ")"
+}
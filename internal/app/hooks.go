@@ -0,0 +1,86 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"diffman/internal/comments"
+	"diffman/internal/config"
+	"diffman/internal/util"
+)
+
+// hookTimeout bounds a hook command so a hung integration cannot pile up processes.
+const hookTimeout = 30 * time.Second
+
+// hookPayload is the JSON a hook command receives on stdin. Comment is set
+// for create and edit; Comments for delete (one or more) and export.
+type hookPayload struct {
+	Event    string             `json:"event"`
+	Repo     string             `json:"repo"`
+	Comment  *comments.Comment  `json:"comment,omitempty"`
+	Comments []comments.Comment `json:"comments,omitempty"`
+	// Output is "clipboard" or the file an export was written to.
+	Output string `json:"output,omitempty"`
+}
+
+type hookResultMsg struct {
+	event string
+	err   error
+}
+
+// hookCmd runs the configured hook for payload.Event in the background, or
+// returns nil when none is configured.
+func (m Model) hookCmd(payload hookPayload) tea.Cmd {
+	command := strings.TrimSpace(m.hooks[payload.Event])
+	if command == "" {
+		return nil
+	}
+	payload.Repo = m.cwd
+	cwd := m.cwd
+	return func() tea.Msg {
+		return hookResultMsg{event: payload.Event, err: runHook(context.Background(), cwd, command, payload)}
+	}
+}
+
+func runHook(ctx context.Context, cwd, command string, payload hookPayload) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	name, args := hookShell(runtime.GOOS, command)
+	ctx, cancel := context.WithTimeout(ctx, hookTimeout)
+	defer cancel()
+	_, err = util.RunWithStdin(ctx, cwd, string(data), name, args...)
+	return err
+}
+
+// hookShell returns the program and arguments that run command on goos:
+// cmd /C on Windows, which has no /bin/sh, and $SHELL -c elsewhere.
+func hookShell(goos, command string) (string, []string) {
+	if goos == "windows" {
+		return "cmd", []string{"/C", command}
+	}
+	sh := strings.TrimSpace(os.Getenv("SHELL"))
+	if sh == "" {
+		sh = "/bin/sh"
+	}
+	return sh, []string{"-c", command}
+}
+
+func commentCreateOrEditEvent(existed bool) string {
+	if existed {
+		return config.HookCommentEdit
+	}
+	return config.HookCommentCreate
+}
+
+func hookFailedMessage(msg hookResultMsg) string {
+	return fmt.Sprintf("%s hook failed: %v", msg.event, msg.err)
+}
@@ -0,0 +1,70 @@
+package app
+
+import "diffman/internal/diffview"
+
+// hunkRowIndices lists the row each hunk of the current diff starts at: its
+// first changed row, or the header when the hunk has none. Whitespace-only
+// hunks are left out when skipWhitespaceHunks is set.
+func (m Model) hunkRowIndices() []int {
+	var skip map[int]bool
+	if m.skipWhitespaceHunks {
+		skip = diffview.WhitespaceOnlyHunks(m.diffRows)
+	}
+	var rows []int
+	for i, row := range m.diffRows {
+		if row.Kind != diffview.RowHunkHeader || skip[i] {
+			continue
+		}
+		start := i
+		for j := i + 1; j < len(m.diffRows); j++ {
+			kind := m.diffRows[j].Kind
+			if kind == diffview.RowHunkHeader || kind == diffview.RowFileHeader {
+				break
+			}
+			if kind == diffview.RowDelete || kind == diffview.RowAdd || kind == diffview.RowChange {
+				start = j
+				break
+			}
+		}
+		rows = append(rows, start)
+	}
+	return rows
+}
+
+// jumpToHunk moves the diff cursor to the next or previous hunk, wrapping
+// around at either end as comment navigation does.
+func (m *Model) jumpToHunk(direction int) {
+	rows := m.hunkRowIndices()
+	if len(rows) == 0 {
+		if m.skipWhitespaceHunks {
+			m.setAlert("No hunks beyond whitespace changes in current diff.")
+		} else {
+			m.setAlert("No hunks in current diff.")
+		}
+		return
+	}
+
+	next := rows[0]
+	if direction < 0 {
+		next = rows[len(rows)-1]
+	}
+	for _, idx := range rows {
+		if direction > 0 && idx > m.diffCursor {
+			next = idx
+			break
+		}
+	}
+	if direction < 0 {
+		for i := len(rows) - 1; i >= 0; i-- {
+			if rows[i] < m.diffCursor {
+				next = rows[i]
+				break
+			}
+		}
+	}
+
+	m.diffCursor = next
+	m.diffDirty = true
+	m.refreshDiffContent()
+	m.scrollCursorWithPadding(10)
+}
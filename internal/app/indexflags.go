@@ -0,0 +1,24 @@
+package app
+
+import "fmt"
+
+// toggleHideIndexFlagged hides or shows files marked skip-worktree or
+// assume-unchanged, whose listed changes are usually staged leftovers that
+// git otherwise ignores.
+func (m *Model) toggleHideIndexFlagged() {
+	n := 0
+	for _, item := range m.fileItems {
+		if item.IndexFlags() != "" {
+			n++
+		}
+	}
+	m.hideIndexFlagged = !m.hideIndexFlagged
+	if m.hideIndexFlagged {
+		m.setAlert(fmt.Sprintf("Hiding %d skip-worktree/assume-unchanged file(s); alt+h shows them.", n))
+	} else {
+		m.setAlert("Showing skip-worktree/assume-unchanged files.")
+	}
+	entries := m.fileTreeEntries()
+	m.clampFileCursor(entries)
+	m.ensureFileCursorVisible(entries)
+}
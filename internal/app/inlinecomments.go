@@ -0,0 +1,95 @@
+package app
+
+import (
+	"diffman/internal/comments"
+	"diffman/internal/diffview"
+)
+
+var commentPlacementNames = map[diffview.CommentPlacement]string{
+	diffview.CommentsBelowSide: "side",
+	diffview.CommentsAcross:    "across",
+	diffview.CommentsCollapsed: "collapsed",
+}
+
+func commentPlacementFromConfig(name string) diffview.CommentPlacement {
+	for placement, n := range commentPlacementNames {
+		if n == name {
+			return placement
+		}
+	}
+	return diffview.CommentsBelowSide
+}
+
+func (m *Model) cycleCommentPlacement() {
+	m.commentPlacement = (m.commentPlacement + 1) % diffview.CommentPlacement(len(commentPlacementNames))
+	m.diffDirty = true
+	m.refreshDiffContent()
+	note := ""
+	if m.commentPlacement == diffview.CommentsAcross && !m.sideBySide() {
+		note = " (shown below the side until the panes are side by side)"
+	}
+	m.setAlert("Inline comments: " + commentPlacementNames[m.commentPlacement] + note)
+}
+
+func (m Model) commentExpanded(path string, line int, side diffview.Side) bool {
+	commentSide := comments.SideNew
+	if side == diffview.SideOld {
+		commentSide = comments.SideOld
+	}
+	return m.expandedComments[comments.AnchorKey(path, commentSide, line)]
+}
+
+// toggleCommentExpanded shows the collapsed comments of the cursor row in
+// full, or collapses them again.
+func (m *Model) toggleCommentExpanded() {
+	if m.hideComments {
+		m.setAlert("Inline comments are hidden; alt+o shows them.")
+		return
+	}
+	if m.commentPlacement != diffview.CommentsCollapsed {
+		m.setAlert("Inline comments are not collapsed; alt+c cycles the placement.")
+		return
+	}
+	if m.diffCursor < 0 || m.diffCursor >= len(m.diffRows) {
+		return
+	}
+	row := m.diffRows[m.diffCursor]
+	var keys []string
+	if row.OldLine > 0 && m.hasComment(row.Path, row.OldLine, diffview.SideOld) {
+		keys = append(keys, comments.AnchorKey(row.Path, comments.SideOld, row.OldLine))
+	}
+	if row.NewLine > 0 && m.hasComment(row.Path, row.NewLine, diffview.SideNew) {
+		keys = append(keys, comments.AnchorKey(row.Path, comments.SideNew, row.NewLine))
+	}
+	if len(keys) == 0 {
+		m.setAlert("No comment on the selected line.")
+		return
+	}
+
+	if m.expandedComments == nil {
+		m.expandedComments = make(map[string]bool)
+	}
+	expand := !m.expandedComments[keys[0]]
+	for _, key := range keys {
+		if expand {
+			m.expandedComments[key] = true
+		} else {
+			delete(m.expandedComments, key)
+		}
+	}
+	m.diffDirty = true
+	m.refreshDiffContent()
+}
+
+// toggleHideComments hides every inline comment body, leaving the gutter
+// markers, or shows them again.
+func (m *Model) toggleHideComments() {
+	m.hideComments = !m.hideComments
+	m.diffDirty = true
+	m.refreshDiffContent()
+	if m.hideComments {
+		m.setAlert("Inline comments hidden; alt+o shows them.")
+	} else {
+		m.setAlert("Inline comments shown.")
+	}
+}
@@ -4,57 +4,155 @@ import "github.com/charmbracelet/bubbles/key"
 
 // KeyMap defines global and pane-specific bindings.
 type KeyMap struct {
-	Quit         key.Binding
-	ToggleFocus  key.Binding
-	Up           key.Binding
-	Down         key.Binding
-	Open         key.Binding
-	ToggleFiles  key.Binding
-	Refresh      key.Binding
-	Top          key.Binding
-	Bottom       key.Binding
-	PageDown     key.Binding
-	PageUp       key.Binding
-	ScrollDown   key.Binding
-	ScrollUp     key.Binding
-	Help         key.Binding
-	ToggleMode   key.Binding
-	Create       key.Binding
-	Edit         key.Binding
-	Delete       key.Binding
-	NextComment  key.Binding
-	PrevComment  key.Binding
-	Export       key.Binding
-	SubmitReview key.Binding
-	ClearAll     key.Binding
-	CommentsView key.Binding
+	Quit              key.Binding
+	ToggleFocus       key.Binding
+	Up                key.Binding
+	Down              key.Binding
+	Open              key.Binding
+	ToggleFiles       key.Binding
+	Refresh           key.Binding
+	Top               key.Binding
+	Bottom            key.Binding
+	PageDown          key.Binding
+	PageUp            key.Binding
+	ScrollDown        key.Binding
+	ScrollUp          key.Binding
+	Help              key.Binding
+	ToggleMode        key.Binding
+	Create            key.Binding
+	Edit              key.Binding
+	Delete            key.Binding
+	NextComment       key.Binding
+	PrevComment       key.Binding
+	NextCommentedFile key.Binding
+	PrevCommentedFile key.Binding
+	Export            key.Binding
+	ExportFile        key.Binding
+	Publish           key.Binding
+	SubmitReview      key.Binding
+	ClearAll          key.Binding
+	CommentsView      key.Binding
+	AlertLog          key.Binding
+	GrowFiles         key.Binding
+	ShrinkFiles       key.Binding
+	GrowOld           key.Binding
+	ShrinkOld         key.Binding
+	ToggleLayout      key.Binding
+	ZoomNew           key.Binding
+	ZoomOld           key.Binding
+	LineNumbers       key.Binding
+	WordWrap          key.Binding
+	CommentPlacement  key.Binding
+	ExpandComment     key.Binding
+	HideComments      key.Binding
+	ExpandLine        key.Binding
+	RecaptureContext  key.Binding
+	SortComments      key.Binding
+	FilterComments    key.Binding
+	SelectComment     key.Binding
+	ClearSelection    key.Binding
+	PinComment        key.Binding
+	TodoComment       key.Binding
+	ReviewQueue       key.Binding
+	Snapshot          key.Binding
+	DeltaMode         key.Binding
+	Archive           key.Binding
+	History           key.Binding
+	Worktrees         key.Binding
+	Repos             key.Binding
+	PerfHUD           key.Binding
+	Stats             key.Binding
+	Checklist         key.Binding
+	Shell             key.Binding
+	RevertFile        key.Binding
+	AddFile           key.Binding
+	IntentToAdd       key.Binding
+	HideIndexFlagged  key.Binding
+	SortFiles         key.Binding
+	Retry             key.Binding
+	Command           key.Binding
+	CopyComment       key.Binding
+	AddressMode       key.Binding
+	CompareRefs       key.Binding
+	Commits           key.Binding
+	NextHunk          key.Binding
+	PrevHunk          key.Binding
 }
 
 func defaultKeyMap() KeyMap {
 	return KeyMap{
-		Quit:         key.NewBinding(key.WithKeys("q", "ctrl+c"), key.WithHelp("q", "quit")),
-		ToggleFocus:  key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "switch focus")),
-		Up:           key.NewBinding(key.WithKeys("k", "up"), key.WithHelp("k/up", "move up")),
-		Down:         key.NewBinding(key.WithKeys("j", "down"), key.WithHelp("j/down", "move down")),
-		Open:         key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "open diff")),
-		ToggleFiles:  key.NewBinding(key.WithKeys("z"), key.WithHelp("z", "toggle file pane width")),
-		Refresh:      key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "refresh files")),
-		Top:          key.NewBinding(key.WithKeys("g"), key.WithHelp("g", "top")),
-		Bottom:       key.NewBinding(key.WithKeys("G"), key.WithHelp("G", "bottom")),
-		PageDown:     key.NewBinding(key.WithKeys("ctrl+f"), key.WithHelp("ctrl+f", "page down")),
-		PageUp:       key.NewBinding(key.WithKeys("ctrl+b"), key.WithHelp("ctrl+b", "page up")),
-		ScrollDown:   key.NewBinding(key.WithKeys("ctrl+e"), key.WithHelp("ctrl+e", "scroll down")),
-		ScrollUp:     key.NewBinding(key.WithKeys("ctrl+y"), key.WithHelp("ctrl+y", "scroll up")),
-		Help:         key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "help")),
-		ToggleMode:   key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "toggle diff mode")),
-		Create:       key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "new comment")),
-		Edit:         key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "edit comment")),
-		Delete:       key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "delete comment")),
-		NextComment:  key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "next comment")),
-		PrevComment:  key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "prev comment")),
-		Export:       key.NewBinding(key.WithKeys("y"), key.WithHelp("y", "copy export")),
-		SubmitReview: key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "submit PR comments")),
-		ClearAll:     key.NewBinding(key.WithKeys("C"), key.WithHelp("C", "clear all comments")),
-		CommentsView: key.NewBinding(key.WithKeys("m"), key.WithHelp("m", "comments view")),
+		Quit:              key.NewBinding(key.WithKeys("q", "ctrl+c"), key.WithHelp("q", "quit")),
+		ToggleFocus:       key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "switch focus")),
+		Up:                key.NewBinding(key.WithKeys("k", "up"), key.WithHelp("k/up", "move up")),
+		Down:              key.NewBinding(key.WithKeys("j", "down"), key.WithHelp("j/down", "move down")),
+		Open:              key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "open diff")),
+		ToggleFiles:       key.NewBinding(key.WithKeys("z"), key.WithHelp("z", "toggle file pane width")),
+		Refresh:           key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "refresh files")),
+		Top:               key.NewBinding(key.WithKeys("g"), key.WithHelp("g", "top")),
+		Bottom:            key.NewBinding(key.WithKeys("G"), key.WithHelp("G", "bottom")),
+		PageDown:          key.NewBinding(key.WithKeys("ctrl+f"), key.WithHelp("ctrl+f", "page down")),
+		PageUp:            key.NewBinding(key.WithKeys("ctrl+b"), key.WithHelp("ctrl+b", "page up")),
+		ScrollDown:        key.NewBinding(key.WithKeys("ctrl+e"), key.WithHelp("ctrl+e", "scroll down")),
+		ScrollUp:          key.NewBinding(key.WithKeys("ctrl+y"), key.WithHelp("ctrl+y", "scroll up")),
+		Help:              key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "help")),
+		ToggleMode:        key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "toggle diff mode")),
+		Create:            key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "new comment")),
+		Edit:              key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "edit comment")),
+		Delete:            key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "delete comment")),
+		NextComment:       key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "next comment")),
+		PrevComment:       key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "prev comment")),
+		NextCommentedFile: key.NewBinding(key.WithKeys("N"), key.WithHelp("N", "next commented file")),
+		PrevCommentedFile: key.NewBinding(key.WithKeys("P"), key.WithHelp("P", "prev commented file")),
+		Export:            key.NewBinding(key.WithKeys("y"), key.WithHelp("y", "copy export")),
+		ExportFile:        key.NewBinding(key.WithKeys("W"), key.WithHelp("W", "export to file")),
+		Publish:           key.NewBinding(key.WithKeys("B"), key.WithHelp("B", "publish to webhook")),
+		SubmitReview:      key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "submit PR comments")),
+		ClearAll:          key.NewBinding(key.WithKeys("C"), key.WithHelp("C", "clear all comments")),
+		CommentsView:      key.NewBinding(key.WithKeys("m"), key.WithHelp("m", "comments view")),
+		AlertLog:          key.NewBinding(key.WithKeys("L"), key.WithHelp("L", "notice log")),
+		GrowFiles:         key.NewBinding(key.WithKeys(">"), key.WithHelp(">", "widen file pane")),
+		ShrinkFiles:       key.NewBinding(key.WithKeys("<"), key.WithHelp("<", "narrow file pane")),
+		GrowOld:           key.NewBinding(key.WithKeys("+", "="), key.WithHelp("+", "widen old pane")),
+		ShrinkOld:         key.NewBinding(key.WithKeys("-"), key.WithHelp("-", "widen new pane")),
+		ToggleLayout:      key.NewBinding(key.WithKeys("V"), key.WithHelp("V", "stack diff panes")),
+		ZoomNew:           key.NewBinding(key.WithKeys("Z"), key.WithHelp("Z", "zoom new pane")),
+		ZoomOld:           key.NewBinding(key.WithKeys("alt+z"), key.WithHelp("alt+z", "zoom old pane")),
+		LineNumbers:       key.NewBinding(key.WithKeys("#"), key.WithHelp("#", "cycle line numbers")),
+		WordWrap:          key.NewBinding(key.WithKeys("alt+w"), key.WithHelp("alt+w", "toggle word wrap")),
+		CommentPlacement:  key.NewBinding(key.WithKeys("alt+c"), key.WithHelp("alt+c", "cycle inline comment placement")),
+		ExpandComment:     key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "expand/collapse inline comment")),
+		HideComments:      key.NewBinding(key.WithKeys("alt+o"), key.WithHelp("alt+o", "hide/show all inline comments")),
+		ExpandLine:        key.NewBinding(key.WithKeys("E"), key.WithHelp("E", "expand/truncate long line")),
+		RecaptureContext:  key.NewBinding(key.WithKeys("u"), key.WithHelp("u", "re-capture comment context")),
+		SortComments:      key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "cycle comment sort")),
+		FilterComments:    key.NewBinding(key.WithKeys("/"), key.WithHelp("/", "filter comments")),
+		SelectComment:     key.NewBinding(key.WithKeys("x"), key.WithHelp("x", "select comment")),
+		ClearSelection:    key.NewBinding(key.WithKeys("X"), key.WithHelp("X", "clear selection")),
+		PinComment:        key.NewBinding(key.WithKeys("b"), key.WithHelp("b", "pin comment")),
+		TodoComment:       key.NewBinding(key.WithKeys("D"), key.WithHelp("D", "convert comment to TODO")),
+		ReviewQueue:       key.NewBinding(key.WithKeys("O"), key.WithHelp("O", "PRs awaiting your review")),
+		Snapshot:          key.NewBinding(key.WithKeys("S"), key.WithHelp("S", "snapshot reviewed state")),
+		DeltaMode:         key.NewBinding(key.WithKeys("R"), key.WithHelp("R", "re-review changes since snapshot")),
+		Archive:           key.NewBinding(key.WithKeys("A"), key.WithHelp("A", "archive review")),
+		History:           key.NewBinding(key.WithKeys("H"), key.WithHelp("H", "review history")),
+		Worktrees:         key.NewBinding(key.WithKeys("w"), key.WithHelp("w", "switch worktree")),
+		Repos:             key.NewBinding(key.WithKeys("ctrl+r"), key.WithHelp("ctrl+r", "switch repository")),
+		Stats:             key.NewBinding(key.WithKeys("I"), key.WithHelp("I", "review statistics")),
+		Checklist:         key.NewBinding(key.WithKeys("T"), key.WithHelp("T", "review checklist")),
+		PerfHUD:           key.NewBinding(key.WithKeys("f12"), key.WithHelp("f12", "performance overlay")),
+		Shell:             key.NewBinding(key.WithKeys("!"), key.WithHelp("!", "shell in repository root")),
+		RevertFile:        key.NewBinding(key.WithKeys("U"), key.WithHelp("U", "revert file to HEAD or delete untracked file")),
+		AddFile:           key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "git add untracked file")),
+		IntentToAdd:       key.NewBinding(key.WithKeys("alt+a"), key.WithHelp("alt+a", "git add -N untracked file")),
+		HideIndexFlagged:  key.NewBinding(key.WithKeys("alt+h"), key.WithHelp("alt+h", "hide/show skip-worktree and assume-unchanged files")),
+		SortFiles:         key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "cycle file sort")),
+		Retry:             key.NewBinding(key.WithKeys("f5"), key.WithHelp("f5", "retry a failed load")),
+		Command:           key.NewBinding(key.WithKeys(":"), key.WithHelp(":", "command, e.g. c 7 for comment 7 of the latest export")),
+		CopyComment:       key.NewBinding(key.WithKeys("Y"), key.WithHelp("Y", "copy this comment")),
+		AddressMode:       key.NewBinding(key.WithKeys("F"), key.WithHelp("F", "address review feedback")),
+		CompareRefs:       key.NewBinding(key.WithKeys("alt+r"), key.WithHelp("alt+r", "compare the file between two refs")),
+		Commits:           key.NewBinding(key.WithKeys("M"), key.WithHelp("M", "PR commit messages")),
+		NextHunk:          key.NewBinding(key.WithKeys("]"), key.WithHelp("]", "next hunk")),
+		PrevHunk:          key.NewBinding(key.WithKeys("["), key.WithHelp("[", "prev hunk")),
 	}
 }
@@ -1,5 +1,25 @@
 package app
 
+const (
+	filePaneWidthMin  = 12
+	filePaneWidthMax  = 200
+	filePaneWidthStep = 4
+
+	splitPercentDefault = 50
+	splitPercentMin     = 20
+	splitPercentMax     = 80
+	splitPercentStep    = 5
+
+	// stackedAutoWidth is the terminal width below which the "auto" layout stacks panes.
+	stackedAutoWidth = 110
+)
+
+const (
+	diffLayoutSideBySide = "side-by-side"
+	diffLayoutStacked    = "stacked"
+	diffLayoutAuto       = "auto"
+)
+
 func paneWidths(totalWidth int, desiredLeft int, hideLeft bool, splitRight bool) (int, int) {
 	if hideLeft {
 		// Hidden file list: only diff pane(s) are visible.
@@ -47,12 +67,17 @@ func paneWidths(totalWidth int, desiredLeft int, hideLeft bool, splitRight bool)
 	return left, right
 }
 
-func splitRightPanes(totalWidth int) (int, int) {
-	if totalWidth <= 1 {
+// splitPanes divides a length along either axis between the old and new
+// diff panes, giving the old pane percent of it.
+func splitPanes(total, percent int) (int, int) {
+	if total <= 1 {
 		return 1, 1
 	}
-	left := totalWidth / 2
-	right := totalWidth - left
+	if percent <= 0 || percent >= 100 {
+		percent = splitPercentDefault
+	}
+	left := total * percent / 100
+	right := total - left
 	if left < 1 {
 		left = 1
 	}
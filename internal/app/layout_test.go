@@ -29,3 +29,17 @@ func TestPaneWidthsWithSingleRightHiddenFiles(t *testing.T) {
 		t.Fatalf("paneWidths(hidden single) = (%d,%d), want (0,118)", left, right)
 	}
 }
+
+func TestSplitPanesHonorsPercent(t *testing.T) {
+	left, right := splitPanes(100, 30)
+	if left != 30 || right != 70 {
+		t.Fatalf("splitPanes(100, 30) = (%d,%d), want (30,70)", left, right)
+	}
+}
+
+func TestSplitPanesDefaultsToEvenSplit(t *testing.T) {
+	left, right := splitPanes(101, 0)
+	if left != 50 || right != 51 {
+		t.Fatalf("splitPanes(101, 0) = (%d,%d), want (50,51)", left, right)
+	}
+}
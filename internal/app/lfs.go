@@ -0,0 +1,84 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"diffman/internal/diffview"
+)
+
+const lfsPointerVersion = "version https://git-lfs.github.com/spec/v1"
+
+// lfsPointer is the content git stores for a file tracked by Git LFS.
+type lfsPointer struct {
+	OID  string
+	Size int64
+}
+
+// parseLFSPointer reads an LFS pointer file's lines; ok is false for any
+// other content.
+func parseLFSPointer(lines []string) (p lfsPointer, ok bool) {
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != lfsPointerVersion {
+		return lfsPointer{}, false
+	}
+	for _, line := range lines[1:] {
+		key, value, _ := strings.Cut(strings.TrimSpace(line), " ")
+		switch key {
+		case "oid":
+			p.OID = strings.TrimPrefix(value, "sha256:")
+		case "size":
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return lfsPointer{}, false
+			}
+			p.Size = n
+		}
+	}
+	return p, p.OID != ""
+}
+
+func (p lfsPointer) shortOID() string {
+	if len(p.OID) > 12 {
+		return p.OID[:12]
+	}
+	return p.OID
+}
+
+// lfsRows replaces the diff of an LFS pointer file with one line saying how
+// the object changed; ok is false when rows are not a pointer diff. Pointer
+// files are three lines, so the diff holds both sides in full.
+func lfsRows(path string, rows []diffview.DiffRow) ([]diffview.DiffRow, bool) {
+	var oldLines, newLines []string
+	for _, r := range rows {
+		switch r.Kind {
+		case diffview.RowContext:
+			oldLines = append(oldLines, r.OldText)
+			newLines = append(newLines, r.NewText)
+		case diffview.RowDelete:
+			oldLines = append(oldLines, r.OldText)
+		case diffview.RowAdd:
+			newLines = append(newLines, r.NewText)
+		case diffview.RowChange:
+			oldLines = append(oldLines, r.OldText)
+			newLines = append(newLines, r.NewText)
+		}
+	}
+	oldPtr, oldOK := parseLFSPointer(oldLines)
+	newPtr, newOK := parseLFSPointer(newLines)
+	var text string
+	switch {
+	case oldOK && newOK:
+		text = fmt.Sprintf("LFS object changed: %s → %s, size %s → %s", oldPtr.shortOID(), newPtr.shortOID(), formatByteSize(oldPtr.Size), formatByteSize(newPtr.Size))
+	case newOK && len(oldLines) == 0:
+		text = fmt.Sprintf("LFS object added: %s, size %s", newPtr.shortOID(), formatByteSize(newPtr.Size))
+	case oldOK && len(newLines) == 0:
+		text = fmt.Sprintf("LFS object deleted: %s, size %s", oldPtr.shortOID(), formatByteSize(oldPtr.Size))
+	default:
+		return nil, false
+	}
+	if diffview.PlainMode() {
+		text = strings.ReplaceAll(text, "→", "->")
+	}
+	return diffview.NoticeRows(path, text), true
+}
@@ -0,0 +1,45 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"diffman/internal/diffview"
+)
+
+func TestLFSRowsSummarizesPointerChanges(t *testing.T) {
+	oid1 := strings.Repeat("a", 64)
+	oid2 := strings.Repeat("b", 64)
+	for name, tc := range map[string]struct {
+		diff string
+		want string
+	}{
+		"changed": {
+			diff: "diff --git a/logo.png b/logo.png\n--- a/logo.png\n+++ b/logo.png\n@@ -1,3 +1,3 @@\n version https://git-lfs.github.com/spec/v1\n-oid sha256:" + oid1 + "\n-size 2048\n+oid sha256:" + oid2 + "\n+size 3145728\n",
+			want: "LFS object changed: aaaaaaaaaaaa → bbbbbbbbbbbb, size 2.0 KiB → 3.0 MiB",
+		},
+		"added": {
+			diff: "diff --git a/logo.png b/logo.png\nnew file mode 100644\n--- /dev/null\n+++ b/logo.png\n@@ -0,0 +1,3 @@\n+version https://git-lfs.github.com/spec/v1\n+oid sha256:" + oid1 + "\n+size 12\n",
+			want: "LFS object added: aaaaaaaaaaaa, size 12 bytes",
+		},
+	} {
+		rows, err := diffview.ParseUnifiedDiff([]byte(tc.diff))
+		if err != nil {
+			t.Fatalf("%s: ParseUnifiedDiff() error = %v", name, err)
+		}
+		got, ok := lfsRows("logo.png", rows)
+		if !ok || len(got) != 1 || got[0].OldText != tc.want {
+			t.Fatalf("%s: lfsRows() = %+v, %v; want %q", name, got, ok, tc.want)
+		}
+	}
+}
+
+func TestLFSRowsIgnoresOrdinaryDiffs(t *testing.T) {
+	rows, err := diffview.ParseUnifiedDiff([]byte("diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1 +1 @@\n-version one\n+version two\n"))
+	if err != nil {
+		t.Fatalf("ParseUnifiedDiff() error = %v", err)
+	}
+	if _, ok := lfsRows("a.go", rows); ok {
+		t.Fatalf("an ordinary diff was taken for an LFS pointer")
+	}
+}
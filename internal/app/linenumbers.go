@@ -0,0 +1,46 @@
+package app
+
+import "diffman/internal/diffview"
+
+var lineNumberModeNames = map[diffview.LineNumberMode]string{
+	diffview.LineNumbersAbsolute: "absolute",
+	diffview.LineNumbersRelative: "relative",
+	diffview.LineNumbersHidden:   "hidden",
+	diffview.LineNumbersBoth:     "both",
+}
+
+func lineNumberModeFromConfig(name string) diffview.LineNumberMode {
+	for mode, n := range lineNumberModeNames {
+		if n == name {
+			return mode
+		}
+	}
+	return diffview.LineNumbersAbsolute
+}
+
+func (m *Model) cycleLineNumbers() {
+	switch m.lineNumbers {
+	case diffview.LineNumbersAbsolute:
+		m.lineNumbers = diffview.LineNumbersRelative
+	case diffview.LineNumbersRelative:
+		m.lineNumbers = diffview.LineNumbersHidden
+	case diffview.LineNumbersHidden:
+		m.lineNumbers = diffview.LineNumbersBoth
+	default:
+		m.lineNumbers = diffview.LineNumbersAbsolute
+	}
+	m.diffDirty = true
+	m.refreshDiffContent()
+	m.setAlert("Line numbers: " + lineNumberModeNames[m.lineNumbers])
+}
+
+func (m *Model) toggleWordWrap() {
+	m.wordWrap = !m.wordWrap
+	m.diffDirty = true
+	m.refreshDiffContent()
+	if m.wordWrap {
+		m.setAlert("Word wrap: at word boundaries")
+	} else {
+		m.setAlert("Word wrap: at the pane edge")
+	}
+}
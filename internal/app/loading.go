@@ -0,0 +1,197 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"diffman/internal/diffview"
+)
+
+// loadingHintAfter is how long a load runs before its title shows the
+// elapsed time and how to cancel it.
+const loadingHintAfter = time.Second
+
+// diffLoadDebounce is how long the file cursor has to rest on a file before
+// its diff is loaded, so holding j or k doesn't start a git diff per step.
+const diffLoadDebounce = 120 * time.Millisecond
+
+// diffLoadDueMsg fires once the file cursor has rested on path; seq tells
+// apart the moves that came after.
+type diffLoadDueMsg struct {
+	seq  int
+	path string
+}
+
+// loadCanceler holds the cancel func of the in-flight diff load. It is shared
+// by pointer because loadDiffCmd runs on a copy of the Model.
+type loadCanceler struct {
+	mu     sync.Mutex
+	seq    int
+	cancel context.CancelFunc
+}
+
+// begin starts a cancellable load, canceling the one it supersedes, and
+// returns its context, its generation for current, and a release func to
+// call once the load is done.
+func (l *loadCanceler) begin() (context.Context, int, func()) {
+	if l == nil {
+		return context.Background(), 0, func() {}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	l.mu.Lock()
+	if l.cancel != nil {
+		l.cancel()
+	}
+	l.seq++
+	seq := l.seq
+	l.cancel = cancel
+	l.mu.Unlock()
+	return ctx, seq, func() {
+		cancel()
+		l.mu.Lock()
+		if l.seq == seq {
+			l.cancel = nil
+		}
+		l.mu.Unlock()
+	}
+}
+
+// current reports whether gen is the latest load begun, so results of the
+// loads it superseded can be dropped.
+func (l *loadCanceler) current(gen int) bool {
+	if l == nil || gen == 0 {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.seq == gen
+}
+
+// stop cancels the in-flight load, reporting whether there was one.
+func (l *loadCanceler) stop() bool {
+	if l == nil {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.cancel == nil {
+		return false
+	}
+	l.cancel()
+	l.cancel = nil
+	return true
+}
+
+func newLoadingSpinner() spinner.Model {
+	kind := spinner.MiniDot
+	if diffview.PlainMode() {
+		kind = spinner.Line
+	}
+	return spinner.New(
+		spinner.WithSpinner(kind),
+		spinner.WithStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("39"))),
+	)
+}
+
+func (m Model) isLoading() bool {
+	return m.loadingFiles || m.loadingDiff || m.loadingPRs
+}
+
+// trackLoading stamps when each kind of load started and keeps the spinner
+// ticking while anything is loading. prev is the model before the update.
+func (m Model) trackLoading(prev Model, cmd tea.Cmd) (Model, tea.Cmd) {
+	now := time.Now()
+	if m.loadingFiles && !prev.loadingFiles {
+		m.filesLoadStart = now
+	}
+	if m.loadingDiff && !prev.loadingDiff {
+		m.diffLoadStart = now
+	}
+	if m.loadingPRs && !prev.loadingPRs {
+		m.prsLoadStart = now
+	}
+	if m.isLoading() && !m.spinning && len(m.spinner.Spinner.Frames) > 0 {
+		m.spinning = true
+		cmd = tea.Batch(cmd, m.spinner.Tick)
+	}
+	return m, cmd
+}
+
+func (m Model) handleSpinnerTick(msg spinner.TickMsg) (tea.Model, tea.Cmd) {
+	if !m.isLoading() {
+		m.spinning = false
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.spinner, cmd = m.spinner.Update(msg)
+	return m, cmd
+}
+
+// loadingSuffix is appended to a pane title while its content loads. After a
+// moment it adds the elapsed time and, when the load can be cancelled, how.
+func (m Model) loadingSuffix(started time.Time, cancellable bool) string {
+	frame := "…"
+	if len(m.spinner.Spinner.Frames) > 0 {
+		frame = m.spinner.View()
+	}
+	suffix := " " + frame + " loading"
+	if started.IsZero() {
+		return suffix
+	}
+	elapsed := time.Since(started)
+	if elapsed < loadingHintAfter {
+		return suffix
+	}
+	suffix += fmt.Sprintf(" %ds", int(elapsed.Seconds()))
+	if cancellable {
+		suffix += " (esc cancels)"
+	}
+	return suffix
+}
+
+// cancelDiffLoad abandons the diff load in progress so a slow git call does
+// not hold the diff panes.
+func (m *Model) cancelDiffLoad() bool {
+	pending := m.diffLoadPending
+	if !m.loadingDiff || (!m.diffLoads.stop() && !pending) {
+		return false
+	}
+	m.diffLoadPending = false
+	m.loadingDiff = false
+	m.diffRows = nil
+	m.rowStarts = nil
+	m.rowHeights = nil
+	m.diffDirty = false
+	canceled := fmt.Sprintf("Loading the diff for %s was canceled. Press r to try again.", m.selectedF)
+	m.oldView.SetContent(canceled)
+	m.newView.SetContent(canceled)
+	return true
+}
+
+// debounceDiffLoad loads the selected file's diff once the file cursor has
+// rested on it for diffLoadDebounce.
+func (m *Model) debounceDiffLoad() tea.Cmd {
+	m.diffLoadSeq++
+	m.diffLoadPending = true
+	seq, path := m.diffLoadSeq, m.selectedF
+	return tea.Tick(diffLoadDebounce, func(time.Time) tea.Msg {
+		return diffLoadDueMsg{seq: seq, path: path}
+	})
+}
+
+func (m Model) handleDiffLoadDue(msg diffLoadDueMsg) (tea.Model, tea.Cmd) {
+	if !m.diffLoadPending || msg.seq != m.diffLoadSeq || msg.path != m.selectedF {
+		return m, nil
+	}
+	m.diffLoadPending = false
+	if !m.loadingDiff {
+		return m, nil
+	}
+	return m, m.loadDiffCmd(msg.path)
+}
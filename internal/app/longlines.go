@@ -0,0 +1,39 @@
+package app
+
+import (
+	"fmt"
+
+	"diffman/internal/diffview"
+)
+
+// lineKey identifies a diff row across reloads by its path and line numbers.
+func lineKey(row diffview.DiffRow) string {
+	return fmt.Sprintf("%s:%d:%d", row.Path, row.OldLine, row.NewLine)
+}
+
+func (m Model) lineExpanded(row diffview.DiffRow) bool {
+	return m.expandedLines[lineKey(row)]
+}
+
+// toggleLineExpanded shows the cursor row in full when it is cut at
+// maxLineColumns, or cuts it again.
+func (m *Model) toggleLineExpanded() {
+	if m.diffCursor < 0 || m.diffCursor >= len(m.diffRows) {
+		return
+	}
+	row := m.diffRows[m.diffCursor]
+	key := lineKey(row)
+	if m.expandedLines[key] {
+		delete(m.expandedLines, key)
+	} else if diffview.IsTruncated(row, m.maxLineColumns) {
+		if m.expandedLines == nil {
+			m.expandedLines = make(map[string]bool)
+		}
+		m.expandedLines[key] = true
+	} else {
+		m.setAlert("The selected line is not truncated.")
+		return
+	}
+	m.diffDirty = true
+	m.refreshDiffContent()
+}
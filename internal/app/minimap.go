@@ -0,0 +1,170 @@
+package app
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"diffman/internal/diffview"
+)
+
+const (
+	minimapWidth = 1
+	// minimapMinTermWidth hides the minimap on terminals too narrow to spare a column.
+	minimapMinTermWidth = 60
+)
+
+type minimapMark int
+
+const (
+	minimapMarkNone minimapMark = iota
+	minimapMarkDelete
+	minimapMarkAdd
+	minimapMarkChange
+	minimapMarkComment
+)
+
+type minimapCell struct {
+	mark   minimapMark
+	inView bool
+}
+
+func (m Model) showMinimap() bool {
+	return len(m.diffRows) > 0 && m.width >= minimapMinTermWidth
+}
+
+// minimapReserve is the number of diff-area columns taken by the minimap.
+func (m Model) minimapReserve() int {
+	if m.showMinimap() {
+		return minimapWidth
+	}
+	return 0
+}
+
+// minimapCells buckets the diff rows into height cells. Each cell keeps the most
+// notable mark among its rows, and records whether those rows are on screen.
+func (m Model) minimapCells(height int) []minimapCell {
+	cells := make([]minimapCell, max(0, height))
+	total := len(m.diffRows)
+	if total == 0 || height <= 0 {
+		return cells
+	}
+
+	firstVisible, lastVisible := m.visibleRowRange()
+	for i := range cells {
+		start := i * total / height
+		end := (i + 1) * total / height
+		if end <= start {
+			end = start + 1
+		}
+		if start >= total {
+			start = total - 1
+			end = total
+		}
+		cell := minimapCell{inView: start <= lastVisible && end-1 >= firstVisible}
+		for r := start; r < end && r < total; r++ {
+			if mark := m.minimapMarkForRow(m.diffRows[r]); mark > cell.mark {
+				cell.mark = mark
+			}
+		}
+		cells[i] = cell
+	}
+	return cells
+}
+
+func (m Model) minimapMarkForRow(row diffview.DiffRow) minimapMark {
+	if row.NewLine > 0 && m.hasComment(row.Path, row.NewLine, diffview.SideNew) {
+		return minimapMarkComment
+	}
+	if row.OldLine > 0 && m.hasComment(row.Path, row.OldLine, diffview.SideOld) {
+		return minimapMarkComment
+	}
+	switch row.Kind {
+	case diffview.RowChange:
+		return minimapMarkChange
+	case diffview.RowAdd:
+		return minimapMarkAdd
+	case diffview.RowDelete:
+		return minimapMarkDelete
+	default:
+		return minimapMarkNone
+	}
+}
+
+// visibleRowRange returns the first and last diff row indexes shown in the viewport.
+func (m Model) visibleRowRange() (int, int) {
+	view := m.newView
+	if m.diffPaneMode() == diffPaneModeOldOnly {
+		view = m.oldView
+	}
+	if len(m.rowStarts) != len(m.diffRows) || len(m.rowHeights) != len(m.diffRows) {
+		return 0, len(m.diffRows) - 1
+	}
+	top := view.YOffset
+	bottom := top + max(1, view.Height) - 1
+	first, last := -1, -1
+	for i, start := range m.rowStarts {
+		end := start + max(1, m.rowHeights[i]) - 1
+		if end < top || start > bottom {
+			continue
+		}
+		if first < 0 {
+			first = i
+		}
+		last = i
+	}
+	if first < 0 {
+		return 0, -1
+	}
+	return first, last
+}
+
+func (m Model) renderMinimap(height int) string {
+	cells := m.minimapCells(height)
+	addColor, deleteColor, changeColor := diffview.MarkerColors()
+	lines := make([]string, len(cells))
+	for i, cell := range cells {
+		glyph := "│"
+		style := lipgloss.NewStyle().Foreground(lipgloss.Color("238"))
+		switch cell.mark {
+		case minimapMarkComment:
+			glyph = "●"
+			style = style.Foreground(lipgloss.Color("39"))
+		case minimapMarkChange:
+			glyph = "▌"
+			style = style.Foreground(changeColor)
+		case minimapMarkAdd:
+			glyph = "▌"
+			style = style.Foreground(addColor)
+		case minimapMarkDelete:
+			glyph = "▌"
+			style = style.Foreground(deleteColor)
+		}
+		if cell.inView {
+			style = style.Background(lipgloss.Color("240"))
+		}
+		if diffview.PlainMode() {
+			glyph = plainMinimapGlyph(cell.mark, cell.inView)
+		}
+		lines[i] = style.Render(glyph)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// plainMinimapGlyph marks the visible band with "|" instead of a background color.
+func plainMinimapGlyph(mark minimapMark, inView bool) string {
+	switch mark {
+	case minimapMarkComment:
+		return "*"
+	case minimapMarkChange:
+		return "~"
+	case minimapMarkAdd:
+		return "+"
+	case minimapMarkDelete:
+		return "-"
+	}
+	if inView {
+		return "|"
+	}
+	return "."
+}
@@ -5,24 +5,31 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/x/ansi"
 
-	"diffman/internal/clipboard"
 	"diffman/internal/comments"
 	"diffman/internal/config"
+	"diffman/internal/debuglog"
 	"diffman/internal/diffview"
 	gitint "diffman/internal/git"
 	"diffman/internal/githubpr"
+	"diffman/internal/history"
+	"diffman/internal/recent"
+	"diffman/internal/session"
+	"diffman/internal/snapshot"
+	"diffman/internal/spell"
 )
 
 type focusPane int
@@ -49,8 +56,30 @@ const (
 )
 
 type Options struct {
+	// Repo is a directory inside the repository to review; empty means the
+	// current directory.
+	Repo     string
 	PR       string
 	PRPicker bool
+	Plain    bool
+	// Checkout switches the repository to the PR branch before opening it.
+	Checkout bool
+	// ImportComments pulls the PR's existing review comments into the store.
+	ImportComments bool
+	// Recent opens the repository switcher at startup and, outside a
+	// repository, starts in the most recently opened one.
+	Recent bool
+	// PprofAddr is where the pprof endpoints listen, shown in the
+	// performance overlay; empty when they are off.
+	PprofAddr string
+	// Open is a file and line to show first; a zero Location opens the
+	// first changed file as usual.
+	Open Location
+	// StartAt overrides the start_at setting when set.
+	StartAt string
+	// Paths limits a local review to these files, directories and
+	// patterns; see ParseArgs.
+	Paths []string
 }
 
 type prDiffCacheEntry struct {
@@ -66,6 +95,11 @@ const (
 type filesLoadedMsg struct {
 	items []gitint.FileItem
 	err   error
+	// all is the unfiltered list when delta mode hid deltaHidden files.
+	all         []gitint.FileItem
+	deltaHidden int
+	// background marks a listing from the refresh_seconds timer.
+	background bool
 }
 
 type prsLoadedMsg struct {
@@ -83,15 +117,25 @@ type diffLoadedMsg struct {
 	rows  []diffview.DiffRow
 	empty bool
 	err   error
+	// stream is set when rows are only the start of a large diff.
+	stream *diffview.DiffStream
+	// gen is the load's generation; a newer load makes the result stale.
+	gen int
+	// canceled is set when the load was abandoned with Esc.
+	canceled bool
 }
 
 type clipboardResultMsg struct {
-	err error
+	err   error
+	okMsg string
+	// exported is set when the clipboard got a comments export, for the export hook.
+	exported []comments.Comment
 }
 
 type commentStaleLoadedMsg struct {
-	stale map[string]bool
-	err   error
+	stale   map[string]bool
+	reasons map[string]staleReason
+	err     error
 }
 
 type alertTickMsg struct{}
@@ -103,6 +147,7 @@ type leaderCommandResultMsg struct {
 
 type submitReviewResultMsg struct {
 	submitted []comments.Comment
+	event     string
 	err       error
 }
 
@@ -138,15 +183,62 @@ type Model struct {
 	prCursor   int
 	prScroll   int
 	loadingPRs bool
+	head       gitint.HeadInfo
 
 	width  int
 	height int
 	ready  bool
 
-	fileItems      []gitint.FileItem
-	selected       int
-	selectedF      string
-	filePaneW      int
+	fileItems []gitint.FileItem
+	// fileStats holds each file's line counts, which arrive after fileItems.
+	fileStats    map[string]gitint.FileStat
+	selected     int
+	selectedF    string
+	filePaneW    int
+	splitPercent int
+	diffLayout   string
+	zoomSide     diffPaneMode
+	lineNumbers  diffview.LineNumberMode
+	// wordWrap wraps long diff lines and inline comments at spaces.
+	wordWrap bool
+	// commentPlacement is how inline comments are drawn; expandedComments
+	// holds the anchors shown in full while they are collapsed.
+	commentPlacement diffview.CommentPlacement
+	expandedComments map[string]bool
+	// hideComments leaves only the gutter markers of inline comments.
+	hideComments bool
+	// maxLineColumns cuts longer diff lines unless expandedLines holds them.
+	maxLineColumns int
+	expandedLines  map[string]bool
+	// refreshInterval is how often the file list reloads in the background;
+	// autoRefreshing is set while such a reload runs.
+	refreshInterval time.Duration
+	autoRefreshing  bool
+	// refreshAnchor is where the cursors were when r was pressed.
+	refreshAnchor *refreshAnchor
+
+	contextLines int
+	spell        *spell.Checker
+	// permalinks adds host URLs to exports; permalinkTemplates overrides the URL template per host.
+	permalinks         bool
+	permalinkTemplates map[string]string
+	// hooks maps config.Hook* events to shell commands.
+	hooks map[string]string
+	// webhookURL receives published exports; empty disables publishing.
+	webhookURL string
+	// tmuxMessage announces exports copied into a tmux paste buffer.
+	tmuxMessage bool
+	// exportGroup and exportNoContext shape plain exports; see config.
+	exportGroup     string
+	exportNoContext bool
+	// exportFrame is the configured title and footer of plain exports.
+	exportFrame exportFrame
+	// exportOnQuit is export_on_quit; see ExportOnQuit.
+	exportOnQuit string
+	// redactExports leaves code context out of y, W, and B exports.
+	redactExports  bool
+	copyMode       bool
+	copyAnchor     int
 	fileHidden     bool
 	fileCursor     int
 	fileScroll     int
@@ -154,7 +246,20 @@ type Model struct {
 	commentsCursor int
 	commentsScroll int
 	commentsReturn focusPane
-	commentStale   map[string]bool
+	commentsSort   commentsSortMode
+	// commentsCollapsed holds files whose comments are folded under their heading in the comments view.
+	commentsCollapsed map[string]bool
+	// commentsSelected marks comments picked for export in the comments view.
+	commentsSelected     map[string]bool
+	commentsFilter       string
+	commentsFilterActive bool
+	commentsFilterInput  textinput.Model
+	commandInputActive   bool
+	commandInput         textinput.Model
+	commandInputErr      string
+	commentStale         map[string]bool
+	// commentStaleReasons explains entries of commentStale; a missing entry reads as staleReasonFileUnchanged.
+	commentStaleReasons map[string]staleReason
 
 	diffRows   []diffview.DiffRow
 	diffCursor int
@@ -166,8 +271,28 @@ type Model struct {
 	diffDirty  bool
 	oldWidth   int
 	newWidth   int
+	// styledFrom and styledTo are the visual lines long wrapped rows were
+	// styled on; diffUnstyled reports that some of their lines were not.
+	styledFrom   int
+	styledTo     int
+	diffUnstyled bool
 
+	// commentsKey encrypts the comment store; nil leaves it plain.
 	commentStore       comments.Store
+	commentsKey        []byte
+	commentsStamp      comments.Stamp
+	commentsBase       map[string]comments.Comment
+	commentsCheckedAt  time.Time
+	sessionStore       session.Store
+	snapshotStore      snapshot.Store
+	historyStore       history.Store
+	recentStore        recent.Store
+	reviewSnapshot     *snapshot.Snapshot
+	reviewSnapshotKey  string
+	deltaMode          bool
+	deltaAllItems      []gitint.FileItem
+	deltaHidden        int
+	deltaHunksHidden   int
 	comments           map[string]comments.Comment
 	leaderPending      bool
 	leaderCommands     map[string]string
@@ -176,22 +301,163 @@ type Model struct {
 	commentInputErr    string
 	commentEditAnchor  *commentAnchor
 	commentEditKey     string
+	draftDirty         bool
+	anchorDrafts       map[string]comments.Draft
+	pendingDraft       *comments.Draft
+	quitConfirmModal   bool
 
 	reviewInputActive bool
+	exportInputActive bool
+	exportInputModel  textinput.Model
+	exportInputErr    string
+	exportPath        string
+	exportFormat      string
+	// lastExport holds the IDs of the comments the latest export numbered.
+	lastExport        []string
 	reviewInputModel  textinput.Model
 	reviewInputErr    string
 	reviewActionModal bool
 	reviewBodyDraft   string
 	reviewDraft       []comments.Comment
 
-	alertMsg           string
-	alertUntil         time.Time
-	clearConfirmModal  bool
-	pendingCommentJump *commentAnchor
+	// address is set while working through review feedback comment by comment.
+	address *addressState
+
+	// dirSummary is the latest wholly added or deleted directory the file
+	// cursor was on.
+	dirSummary *dirSummaryState
+
+	// refCompare is the selected file compared between two revisions.
+	refCompare *refCompareState
+
+	// commitPanel holds the PR's commits once loaded; commitPanelOpen shows
+	// them over the review.
+	commitPanel     *commitPanel
+	commitPanelOpen bool
+
+	// skipWhitespaceHunks makes hunk navigation pass over whitespace-only
+	// hunks.
+	skipWhitespaceHunks bool
+
+	// fileIcons shows a file type icon before each name in the file tree.
+	fileIcons bool
+
+	// fileSort orders the files pane; fileMTimes holds the modification
+	// times it sorts by in fileSortByModified.
+	fileSort   fileSortMode
+	fileMTimes map[string]time.Time
+
+	// scope is what the path arguments limit the review to; comments
+	// outside it are left out of stale detection.
+	scope gitint.Scope
+	// excludes is the exclude setting, to rebuild the git services for
+	// another repository.
+	excludes []string
+
+	alertMsg            string
+	alertUntil          time.Time
+	alertLog            []alertLogEntry
+	alertLogOpen        bool
+	prQueueOpen         bool
+	prQueueLoading      bool
+	prQueueItems        []githubpr.Summary
+	prQueueCursor       int
+	prQueueErr          string
+	historyOpen         bool
+	historyItems        []history.Review
+	historyCursor       int
+	historyViewing      bool
+	historyScroll       int
+	cleanupConfirmModal bool
+	// cleanupOffered is the comment set last offered for cleanup, so it is asked only once.
+	cleanupOffered      string
+	cleanupAfterCommit  bool
+	worktreeOpen        bool
+	worktreeItems       []gitint.Worktree
+	worktreeCursor      int
+	worktreeErr         string
+	workspaceRoot       string
+	repoPickerOpen      bool
+	repoPickerItems     []repoChoice
+	repoPickerCursor    int
+	repoPickerErr       string
+	stalePopupKey       string
+	alertLogScroll      int
+	clearConfirmModal   bool
+	publishConfirmModal bool
+	pendingCommentJump  *commentAnchor
+	pendingLocation     *Location
+	// prQueueConfirm is the PR awaiting confirmation to be checked out, and
+	// prQueueDirty the number of uncommitted changes in the working tree, or
+	// -1 when they could not be listed.
+	prQueueConfirm *githubpr.Summary
+	prQueueDirty   int
+	// startAt is the start_at setting until it has been applied.
+	startAt         string
+	sessionPosition *session.Position
+	// diffTool is the difftool command run by <space>d.
+	diffTool string
+	// revertConfirm is the file awaiting confirmation to be reverted to HEAD.
+	revertConfirm *gitint.FileItem
+	// hideIndexFlagged leaves skip-worktree and assume-unchanged files out
+	// of the file tree.
+	hideIndexFlagged bool
+	// sparseConfirm is the file outside the sparse checkout being opened,
+	// awaiting confirmation to check it out.
+	sparseConfirm *gitint.FileItem
 
 	loadingFiles bool
 	loadingDiff  bool
-	err          error
+	// err is the error of the last PR list load. filesErr and diffErr are
+	// those of the last file list and diff loads, shown in the diff panes
+	// until a load succeeds.
+	err         error
+	filesErr    error
+	diffErr     error
+	diffErrPath string
+
+	// spinner animates pane titles while anything loads; spinning is set
+	// while its tick is scheduled.
+	spinner        spinner.Model
+	spinning       bool
+	filesLoadStart time.Time
+	diffLoadStart  time.Time
+	prsLoadStart   time.Time
+	diffLoads      *loadCanceler
+	// diffStream parses the rest of a large diff whose start is shown.
+	diffStream *diffview.DiffStream
+	// diffLoadPending is set while a load started by moving the file cursor
+	// waits out diffLoadDebounce; diffLoadSeq drops the ticks of earlier moves.
+	diffLoadPending bool
+	diffLoadSeq     int
+
+	// perfHUD shows the performance overlay toggled with F12.
+	perfHUD   bool
+	perf      *perfStats
+	pprofAddr string
+
+	crash *crashState
+
+	// reviewLines holds the changed lines of each opened file's diff, and
+	// reviewed those the user has seen, for the reviewed percentages.
+	reviewLines map[string][]reviewedLine
+	reviewed    map[string]map[reviewedLine]bool
+
+	// reviewTimes is active time per file, reviewTotal all active time,
+	// counted between key presses up to reviewIdleLimit apart.
+	reviewTimes   map[string]time.Duration
+	reviewTotal   time.Duration
+	reviewTimerAt time.Time
+	statsOpen     bool
+	statsScroll   int
+	exportStats   bool
+
+	// checklist is the configured review checklist; checklistDone holds the
+	// ticked items and is remembered in the session file.
+	checklist       []string
+	checklistDone   map[string]bool
+	checklistOpen   bool
+	checklistCursor int
 }
 
 func NewModel() (Model, error) {
@@ -199,14 +465,44 @@ func NewModel() (Model, error) {
 }
 
 func NewModelWithOptions(opts Options) (Model, error) {
-	cwd, err := os.Getwd()
+	cwd, err := gitint.StartDir(opts.Repo)
 	if err != nil {
 		return Model{}, err
 	}
 
+	var recentStore recent.Store
+	if path, err := recent.DefaultPath(); err == nil {
+		recentStore = recent.NewStore(path)
+	}
+
 	repoRoot, err := gitint.DiscoverRepoRoot(context.Background(), cwd)
+	var nestedRepos []string
 	if err != nil {
-		return Model{}, err
+		// Outside a repository, a local review can still start in a
+		// workspace directory that holds several repositories, or with
+		// -recent in the last repository opened.
+		if opts.PR != "" || opts.PRPicker || os.Getenv("GIT_DIR") != "" {
+			return Model{}, err
+		}
+		nestedRepos, _ = gitint.FindRepos(cwd, gitint.NestedRepoDepth)
+		start := ""
+		if len(nestedRepos) > 0 {
+			start = nestedRepos[0]
+		} else if opts.Recent {
+			recents, _ := recentStore.Load()
+			for _, r := range recents {
+				if info, statErr := os.Stat(r.Path); statErr == nil && info.IsDir() {
+					start = r.Path
+					break
+				}
+			}
+		}
+		if start == "" {
+			return Model{}, &NotRepoError{Dir: cwd, Err: err}
+		}
+		if repoRoot, err = gitint.DiscoverRepoRoot(context.Background(), start); err != nil {
+			return Model{}, err
+		}
 	}
 
 	gitDir, err := gitint.DiscoverGitDir(context.Background(), repoRoot)
@@ -214,10 +510,26 @@ func NewModelWithOptions(opts Options) (Model, error) {
 		return Model{}, err
 	}
 
-	store := comments.NewStore(gitDir)
-	loadedComments, loadErr := store.Load()
 	appConfig, configPath, configErr := config.Load()
-	diffview.InitializeTheme(appConfig.Theme)
+	if configErr != nil {
+		// Keep working with the defaults; the error is shown as an alert below.
+		appConfig = config.Default()
+	}
+	startAt := appConfig.StartAt
+	if opts.StartAt != "" {
+		if startAt, err = config.NormalizeStartAt(opts.StartAt); err != nil {
+			return Model{}, err
+		}
+	}
+	key, keyErr := commentsKey(appConfig)
+	store := comments.NewStore(gitDir).WithKey(key)
+	sessionStore := session.NewStore(gitDir)
+	sessionState, sessionErr := sessionStore.Load()
+	loadedComments, loadErr := store.Load()
+	draft, draftErr := store.LoadDraft()
+	diffview.InitializeThemeWithPalette(appConfig.Theme, appConfig.Palette)
+	diffview.SetPlainMode(opts.Plain || appConfig.Plain)
+	diffview.SetHideEOLChanges(appConfig.HideEOLChanges)
 	if appConfig.LeaderCommands == nil {
 		appConfig.LeaderCommands = make(map[string]string)
 	}
@@ -239,6 +551,14 @@ func NewModelWithOptions(opts Options) (Model, error) {
 	reviewInput.Cursor.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("51"))
 	reviewInput.PlaceholderStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
 
+	if len(opts.Paths) > 0 && (strings.TrimSpace(opts.PR) != "" || opts.PRPicker) {
+		return Model{}, fmt.Errorf("path arguments limit local reviews and cannot be used with a PR")
+	}
+	scope, err := reviewScope(repoRoot, opts.Paths)
+	if err != nil {
+		return Model{}, err
+	}
+
 	prSvc := githubpr.NewService()
 	var prCtx *githubpr.Context
 	prDiffs := make(map[string]prDiffCacheEntry)
@@ -249,6 +569,11 @@ func NewModelWithOptions(opts Options) (Model, error) {
 		if err != nil {
 			return Model{}, err
 		}
+		if opts.Checkout {
+			if err := prSvc.Checkout(context.Background(), repoRoot, ctx); err != nil {
+				return Model{}, fmt.Errorf("check out PR #%d: %w", ctx.Number, err)
+			}
+		}
 		prCtx = &ctx
 		mode = reviewModePR
 	} else if opts.PRPicker {
@@ -257,37 +582,144 @@ func NewModelWithOptions(opts Options) (Model, error) {
 	}
 
 	m := Model{
-		keys:              defaultKeyMap(),
-		focus:             focusFiles,
-		cwd:               repoRoot,
-		diffMode:          gitint.DiffModeAll,
-		reviewMode:        mode,
-		statusSvc:         gitint.NewStatusService(),
-		diffSvc:           gitint.NewDiffService(),
-		prSvc:             prSvc,
-		prCtx:             prCtx,
-		prDiffs:           prDiffs,
-		prPicker:          prPicker,
-		helpOpen:          false,
-		filePaneW:         filePaneWidthDefault,
-		treeCollapsed:     make(map[string]bool),
-		commentsReturn:    focusDiff,
-		commentStale:      make(map[string]bool),
-		commentStore:      store,
-		comments:          commentMap,
-		leaderCommands:    appConfig.LeaderCommands,
-		commentInputModel: commentInput,
-		reviewInputModel:  reviewInput,
-		diffDirty:         true,
-		oldWidth:          -1,
-		newWidth:          -1,
-	}
-	if loadErr != nil {
+		keys:                defaultKeyMap(),
+		focus:               focusFiles,
+		cwd:                 repoRoot,
+		diffMode:            gitint.DiffModeAll,
+		reviewMode:          mode,
+		statusSvc:           gitint.NewScopedStatusService(scope, appConfig.Exclude...),
+		diffSvc:             gitint.NewDiffService(appConfig.Exclude...),
+		prSvc:               prSvc,
+		prCtx:               prCtx,
+		prDiffs:             prDiffs,
+		prPicker:            prPicker,
+		helpOpen:            false,
+		filePaneW:           filePaneWidthDefault,
+		splitPercent:        splitPercentDefault,
+		diffLayout:          appConfig.DiffLayout,
+		lineNumbers:         lineNumberModeFromConfig(appConfig.LineNumbers),
+		wordWrap:            appConfig.WordWrap,
+		startAt:             startAt,
+		diffTool:            appConfig.DiffTool,
+		commentPlacement:    commentPlacementFromConfig(appConfig.InlineComments),
+		contextLines:        appConfig.ContextLines,
+		maxLineColumns:      appConfig.MaxLineColumns,
+		refreshInterval:     time.Duration(appConfig.RefreshSeconds) * time.Second,
+		treeCollapsed:       make(map[string]bool),
+		commentsReturn:      focusDiff,
+		commentStale:        make(map[string]bool),
+		commentStore:        store,
+		commentsKey:         key,
+		sessionStore:        sessionStore,
+		snapshotStore:       snapshot.NewStore(gitDir),
+		historyStore:        history.NewStore(gitDir).WithKey(key),
+		comments:            commentMap,
+		leaderCommands:      appConfig.LeaderCommands,
+		commentInputModel:   commentInput,
+		reviewInputModel:    reviewInput,
+		exportInputModel:    newExportInput(),
+		commentsFilterInput: newCommentsFilterInput(),
+		commandInput:        newCommandInput(),
+		diffDirty:           true,
+		oldWidth:            -1,
+		newWidth:            -1,
+		spinner:             newLoadingSpinner(),
+		diffLoads:           &loadCanceler{},
+		perf:                &perfStats{},
+		crash:               &crashState{},
+		pprofAddr:           opts.PprofAddr,
+	}
+	// Init runs on a copy, so the first load, and the spinner tick Init
+	// schedules for it, are recorded here.
+	m.spinning = true
+	if prPicker {
+		m.loadingPRs = true
+		m.prsLoadStart = time.Now()
+	} else {
+		m.loadingFiles = true
+		m.filesLoadStart = time.Now()
+	}
+	m.stampComments()
+	if comments.IsRecovered(loadErr) {
+		m.setAlert(loadErr.Error())
+		loadErr = nil
+	} else if loadErr != nil {
 		m.setAlert(fmt.Sprintf("failed to load comments: %v", loadErr))
 	}
 	if configErr != nil {
 		m.setAlert(fmt.Sprintf("failed to load config %s: %v", configPath, configErr))
 	}
+	m.permalinks = appConfig.Permalinks
+	m.permalinkTemplates = appConfig.PermalinkTemplates
+	m.hooks = appConfig.Hooks
+	m.webhookURL = appConfig.WebhookURL
+	m.cleanupAfterCommit = appConfig.CleanupAfterCommit
+	m.exportStats = appConfig.ExportReviewStats
+	m.redactExports = appConfig.RedactExports
+	m.tmuxMessage = appConfig.TmuxMessage
+	m.exportGroup = appConfig.ExportGroup
+	m.exportNoContext = !appConfig.ExportContext
+	m.exportFrame = exportFrame{title: appConfig.ExportTitle, footer: appConfig.ExportFooter}
+	m.exportOnQuit = appConfig.ExportOnQuit
+	if keyErr != nil {
+		m.setAlert(fmt.Sprintf("comments are not encrypted: %v", keyErr))
+	}
+	m.checklist = appConfig.Checklist
+	// The follow-up reminder must not hide an error raised above.
+	if notice := followUpNotice(comments.OverdueFollowUps(loadedComments, time.Now(), appConfig.FollowUpDays), time.Now()); notice != "" && m.alertMsg == "" {
+		m.setAlert(notice)
+	}
+	m.skipWhitespaceHunks = appConfig.SkipWhitespaceHunks
+	m.fileIcons = appConfig.FileIcons
+	m.scope = scope
+	m.excludes = appConfig.Exclude
+	if appConfig.Spellcheck {
+		checker, err := spell.Load(appConfig.Dictionary)
+		if err != nil {
+			m.setAlert(fmt.Sprintf("spellcheck: %v", err))
+		}
+		m.spell = checker
+	}
+	if opts.ImportComments && loadErr == nil {
+		m.importPRComments()
+	}
+	if sessionErr != nil {
+		m.setAlert(fmt.Sprintf("failed to load session state: %v", sessionErr))
+	}
+	m.applySessionState(sessionState)
+	m.recentStore = recentStore
+	if err := recentStore.Touch(repoRoot); err != nil {
+		m.setAlert(fmt.Sprintf("failed to record recent repository: %v", err))
+	}
+	if len(nestedRepos) > 0 {
+		m.workspaceRoot = cwd
+	}
+	if mode == reviewModeLocal && (len(nestedRepos) > 1 || opts.Recent) {
+		if m.workspaceRoot == "" {
+			m.workspaceRoot = outermostRepoRoot(context.Background(), repoRoot)
+		}
+		if choices, err := m.repoChoices(); err != nil {
+			m.setAlert(fmt.Sprintf("failed to find repositories: %v", err))
+		} else if len(choices) > 1 {
+			m.showRepoPicker(choices)
+		}
+	}
+	if draftErr != nil {
+		m.setAlert(fmt.Sprintf("failed to load comment draft: %v", draftErr))
+	}
+	m.loadStashedDrafts()
+	if draft != nil && mode == reviewModeLocal {
+		m.pendingDraft = draft
+		m.selectedF = draft.Path
+		m.focus = focusDiff
+	}
+	if opts.Open.Path != "" {
+		path, err := locationPath(repoRoot, opts.Open.Path)
+		if err != nil {
+			return Model{}, err
+		}
+		m.openLocation(Location{Path: path, Line: opts.Open.Line})
+	}
 
 	m.oldView = viewport.New(1, 1)
 	m.newView = viewport.New(1, 1)
@@ -299,14 +731,37 @@ func NewModelWithOptions(opts Options) (Model, error) {
 func (m Model) Init() tea.Cmd {
 	if m.reviewMode == reviewModePR && m.prPicker {
 		m.loadingPRs = true
-		return tea.Batch(m.loadPRsCmd(), alertTickCmd())
+		return tea.Batch(m.loadPRsCmd(), alertTickCmd(), m.spinner.Tick)
 	}
 	m.loadingFiles = true
-	return tea.Batch(m.loadFilesCmd(), alertTickCmd())
+	return tea.Batch(m.loadFilesCmd(), m.loadHeadCmd(), alertTickCmd(), m.spinner.Tick, m.autoRefreshCmd(), m.offerReviewQueueCmd())
+}
+
+func (m Model) Update(msg tea.Msg) (next tea.Model, cmd tea.Cmd) {
+	if m.crash.crashed() {
+		return m, tea.Quit
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			m.handlePanic("update", msg, r)
+			next, cmd = m, tea.Quit
+		}
+	}()
+	next, cmd = m.update(msg)
+	if nm, ok := next.(Model); ok {
+		if _, isKey := msg.(tea.KeyMsg); isKey {
+			nm.trackReviewTime(m.selectedF, time.Now())
+		}
+		nm.markVisibleReviewed()
+		return nm.trackLoading(m, cmd)
+	}
+	return next, cmd
 }
 
-func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case spinner.TickMsg:
+		return m.handleSpinnerTick(msg)
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
@@ -317,9 +772,28 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case filesLoadedMsg:
+		if msg.background {
+			return m.mergeBackgroundFiles(msg)
+		}
+		if msg.err != nil {
+			debuglog.Printf("load files failed: %v", msg.err)
+		} else if !m.filesLoadStart.IsZero() {
+			debuglog.Timed(m.filesLoadStart, "load files: %d changed", len(msg.items))
+		}
 		m.loadingFiles = false
-		m.err = msg.err
+		m.filesErr = msg.err
+		m.dirSummary = nil
+		cursor := m.fileCursorAnchor()
 		m.fileItems = msg.items
+		m.loadFileMTimes()
+		m.deltaAllItems = msg.all
+		m.deltaHidden = msg.deltaHidden
+		if m.reviewMode == reviewModeLocal && msg.err == nil {
+			m.followRenamedComments(m.staleCheckItems())
+		}
+		if msg.err == nil {
+			m.applyStartAt()
+		}
 		if len(m.fileItems) == 0 {
 			m.selected = 0
 			m.selectedF = ""
@@ -330,14 +804,28 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.rowStarts = nil
 			m.rowHeights = nil
 			m.diffDirty = false
+			m.refreshAnchor = nil
 			m.oldView.GotoTop()
 			m.newView.GotoTop()
+			if msg.deltaHidden > 0 {
+				m.oldView.SetContent("No changes since the review snapshot.")
+				m.newView.SetContent("No changes since the review snapshot.")
+				return m, m.loadCommentStaleCmd(m.staleCheckItems(), m.comments, m.diffMode)
+			}
 			m.oldView.SetContent("No changed files found in this repository.")
 			m.newView.SetContent("No changed files found in this repository.")
+			if msg.err != nil {
+				m.showLoadError()
+				return m, nil
+			}
 			m.commentStale = m.staleAllComments()
+			m.commentStaleReasons = nil
+			m.openFirstStaleComment()
+			m.maybeOfferCleanup()
 			return m, nil
 		}
 
+		m.checkPendingLocation()
 		if idx := indexOfFilePath(m.fileItems, m.selectedF); idx >= 0 {
 			m.selected = idx
 		}
@@ -346,29 +834,50 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		m.selectedF = m.fileItems[m.selected].Path
 		m.syncFileCursorToSelectedPath()
+		m.fileScroll = m.fileCursor - cursor.row
 		m.ensureFileCursorVisible(m.fileTreeEntries())
 		return m, tea.Batch(
 			m.loadDiffCmd(m.selectedF),
-			m.loadCommentStaleCmd(m.fileItems, m.comments, m.diffMode),
+			m.loadCommentStaleCmd(m.staleCheckItems(), m.comments, m.diffMode),
 		)
 
+	case fileStatsLoadedMsg:
+		return m.handleFileStatsLoaded(msg)
+
+	case autoRefreshMsg:
+		return m.handleAutoRefresh()
+
 	case diffLoadedMsg:
+		if msg.canceled {
+			debuglog.Printf("load diff %s: canceled", msg.path)
+			return m, nil
+		}
+		if !m.diffLoads.current(msg.gen) {
+			debuglog.Printf("load diff %s: superseded", msg.path)
+			return m, nil
+		}
+		if msg.err != nil {
+			debuglog.Printf("load diff %s failed: %v", msg.path, msg.err)
+		} else if !m.diffLoadStart.IsZero() {
+			debuglog.Timed(m.diffLoadStart, "load diff %s: %d rows", msg.path, len(msg.rows))
+		}
 		m.loadingDiff = false
-		m.err = msg.err
+		m.copyMode = false
+		m.diffErr, m.diffErrPath = msg.err, msg.path
+		m.diffStream = msg.stream
 		if msg.err != nil {
 			m.diffRows = nil
 			m.rowStarts = nil
 			m.rowHeights = nil
 			m.diffDirty = false
-			errMsg := fmt.Sprintf("Failed to load diff for %s:\n%v", msg.path, msg.err)
-			m.oldView.SetContent(errMsg)
-			m.newView.SetContent(errMsg)
+			m.showLoadError()
 			return m, nil
 		}
 		if msg.empty || len(msg.rows) == 0 {
 			if m.reviewMode == reviewModePR {
 				m.prDiffs[msg.path] = prDiffCacheEntry{empty: true}
 			}
+			m.recordReviewLines(msg.path, nil)
 			m.diffRows = nil
 			m.diffCursor = 0
 			m.rowStarts = nil
@@ -382,15 +891,34 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.reviewMode == reviewModePR {
 			m.prDiffs[msg.path] = prDiffCacheEntry{rows: append([]diffview.DiffRow(nil), msg.rows...)}
 		}
-		m.diffRows = msg.rows
+		rows := m.filterDeltaRows(msg.path, msg.rows)
+		if len(rows) == 0 {
+			m.diffRows = nil
+			m.diffCursor = 0
+			m.rowStarts = nil
+			m.rowHeights = nil
+			m.diffDirty = false
+			unchanged := fmt.Sprintf("No changes in %s since the review snapshot.", msg.path)
+			m.oldView.SetContent(unchanged)
+			m.newView.SetContent(unchanged)
+			return m, nil
+		}
+		m.diffRows = rows
+		m.recordReviewLines(msg.path, rows)
 		m.diffCursor = firstRenderableRow(m.diffRows)
 		m.diffDirty = true
 		m.refreshDiffContent()
-		if m.pendingCommentJump != nil && m.pendingCommentJump.Path == msg.path {
-			m.jumpToCommentAnchor(*m.pendingCommentJump)
-			m.pendingCommentJump = nil
+		m.restoreDiffAnchor(msg.path)
+		if msg.stream != nil {
+			return m, diffBatchCmd(msg.path, msg.stream, len(rows))
 		}
-		return m, nil
+		return m, m.finishDiffLoad(msg.path)
+
+	case diffBatchMsg:
+		return m.handleDiffBatch(msg)
+
+	case diffLoadDueMsg:
+		return m.handleDiffLoadDue(msg)
 
 	case prsLoadedMsg:
 		m.loadingPRs = false
@@ -407,6 +935,18 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case snapshotSavedMsg:
+		return m.handleSnapshotSaved(msg)
+
+	case reviewQueueLoadedMsg:
+		return m.handleReviewQueueLoaded(msg)
+
+	case reviewQueueCheckoutMsg:
+		return m.handleReviewQueueCheckout(msg)
+
+	case reviewQueueOfferMsg:
+		return m.handleReviewQueueOffer(msg)
+
 	case prResolvedMsg:
 		m.loadingPRs = false
 		if msg.err != nil {
@@ -420,35 +960,120 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.loadingFiles = true
 		return m, m.loadFilesCmd()
 
+	case dirSummaryLoadedMsg:
+		return m.handleDirSummaryLoaded(msg)
+
+	case prCommitsLoadedMsg:
+		return m.handlePRCommitsLoaded(msg)
+
+	case refCompareLoadedMsg:
+		return m.handleRefCompareLoaded(msg)
+
 	case clipboardResultMsg:
 		if msg.err != nil {
 			m.setAlert(fmt.Sprintf("export failed: %v", msg.err))
 			return m, nil
 		}
+		var hook tea.Cmd
+		if msg.exported != nil {
+			m.recordExport(msg.exported)
+			hook = m.hookCmd(hookPayload{Event: config.HookExport, Comments: msg.exported, Output: "clipboard"})
+		}
+		if msg.okMsg != "" {
+			m.setAlert(msg.okMsg)
+			return m, hook
+		}
 		m.setAlert("Copied comments export to clipboard.")
+		return m, hook
+
+	case publishResultMsg:
+		return m, m.handlePublishResult(msg)
+
+	case hookResultMsg:
+		if msg.err != nil {
+			m.setAlert(hookFailedMessage(msg))
+		}
 		return m, nil
 
+	case exportFileResultMsg:
+		if msg.err != nil {
+			m.setAlert(fmt.Sprintf("export failed: %v", msg.err))
+			return m, nil
+		}
+		m.recordExport(msg.exported)
+		m.setAlert(fmt.Sprintf("Wrote %d comment(s) to %s.", len(msg.exported), msg.path))
+		return m, m.hookCmd(hookPayload{Event: config.HookExport, Comments: msg.exported, Output: msg.path})
+
 	case commentStaleLoadedMsg:
 		if msg.stale == nil {
 			m.commentStale = make(map[string]bool)
 		} else {
 			m.commentStale = msg.stale
 		}
+		m.commentStaleReasons = msg.reasons
+		m.restoreCommentsAnchor()
+		if msg.err == nil {
+			m.openFirstStaleComment()
+			m.maybeOfferCleanup()
+		}
 		return m, nil
 
+	case headLoadedMsg:
+		if msg.err == nil {
+			m.head = msg.head
+		}
+		return m, nil
+
+	case commentsFileMsg:
+		return m, m.handleCommentsFile(msg)
+
 	case alertTickMsg:
 		if m.alertMsg != "" && !m.alertUntil.IsZero() && time.Now().After(m.alertUntil) {
 			m.alertMsg = ""
 			m.alertUntil = time.Time{}
 		}
-		return m, alertTickCmd()
+		m.flushDraft()
+		return m, tea.Batch(alertTickCmd(), m.checkCommentsFile())
+
+	case diffToolReadyMsg:
+		if msg.err != nil {
+			_ = os.RemoveAll(msg.dir)
+			m.setAlert(fmt.Sprintf("difftool failed: %v", msg.err))
+			return m, nil
+		}
+		return m, m.execDiffToolCmd(msg)
+
+	case diffToolResultMsg:
+		_ = os.RemoveAll(msg.dir)
+		if msg.err != nil {
+			m.setAlert(fmt.Sprintf("difftool failed: %v", msg.err))
+		}
+		m.loadingFiles = true
+		return m, tea.Batch(m.loadFilesCmd(), m.loadHeadCmd())
+
+	case sparseAddResultMsg:
+		return m, m.handleSparseAddResult(msg)
+
+	case addToIndexResultMsg:
+		return m, m.handleAddToIndexResult(msg)
+
+	case revertResultMsg:
+		return m, m.handleRevertResult(msg)
+
+	case shellExitMsg:
+		if msg.err != nil {
+			m.setAlert(fmt.Sprintf("shell failed: %v", msg.err))
+		}
+		diffview.ClearSyntaxCache()
+		m.loadingFiles = true
+		return m, tea.Batch(m.loadFilesCmd(), m.loadHeadCmd())
 
 	case leaderCommandResultMsg:
 		if msg.err != nil {
 			m.setAlert(fmt.Sprintf("leader %s failed: %v", msg.key, msg.err))
 		}
 		m.loadingFiles = true
-		return m, m.loadFilesCmd()
+		return m, tea.Batch(m.loadFilesCmd(), m.loadHeadCmd())
 
 	case submitReviewResultMsg:
 		m.resetReviewSubmissionState()
@@ -460,26 +1085,88 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.setAlert("No comments submitted.")
 			return m, nil
 		}
+		// Submitted comments leave the local store, so keep a record of them.
+		if _, err := m.archiveReview(submittedOutcome(msg.event), msg.submitted); err != nil {
+			m.setAlert(fmt.Sprintf("failed to archive submitted review: %v", err))
+		}
 		if err := m.removeSubmittedComments(msg.submitted); err != nil {
 			m.setAlert(fmt.Sprintf("submitted to GitHub, but failed to update local drafts: %v", err))
 			return m, nil
 		}
 		m.setAlert(fmt.Sprintf("Submitted %d comment(s) to GitHub.", len(msg.submitted)))
+		m.openStats()
 		return m, nil
 
 	case tea.KeyMsg:
+		if m.quitConfirmModal {
+			return m.handleQuitConfirm(msg)
+		}
 		if m.commentInputActive {
 			return m.handleCommentInput(msg)
 		}
 		if m.reviewInputActive {
 			return m.handleReviewInput(msg)
 		}
+		if m.exportInputActive {
+			return m.handleExportInput(msg)
+		}
+		if m.commentsFilterActive {
+			return m.handleCommentsFilterInput(msg)
+		}
+		if m.commandInputActive {
+			return m.handleCommandInput(msg)
+		}
+		if m.address != nil && m.address.resolving != "" {
+			return m.handleAddressReply(msg)
+		}
 		if m.reviewActionModal {
 			return m.handleReviewAction(msg)
 		}
 		if m.clearConfirmModal {
 			return m.handleClearConfirm(msg)
 		}
+		if m.publishConfirmModal {
+			return m.handlePublishConfirm(msg)
+		}
+		if m.revertConfirm != nil {
+			return m.handleRevertConfirm(msg)
+		}
+		if m.sparseConfirm != nil {
+			return m.handleSparseConfirm(msg)
+		}
+		if m.cleanupConfirmModal {
+			return m.handleCleanupConfirm(msg)
+		}
+		if m.alertLogOpen {
+			return m.handleAlertLog(msg)
+		}
+		if m.prQueueOpen {
+			return m.handleReviewQueue(msg)
+		}
+		if m.historyOpen {
+			return m.handleHistory(msg)
+		}
+		if m.worktreeOpen {
+			return m.handleWorktreePicker(msg)
+		}
+		if m.repoPickerOpen {
+			return m.handleRepoPicker(msg)
+		}
+		if m.refCompare != nil {
+			return m.handleRefCompare(msg)
+		}
+		if m.commitPanelOpen {
+			return m.handleCommitPanel(msg)
+		}
+		if m.statsOpen {
+			return m.handleStats(msg)
+		}
+		if m.checklistOpen {
+			return m.handleChecklist(msg)
+		}
+		if m.stalePopupKey != "" {
+			return m.handleStalePopup(msg)
+		}
 		if m.leaderPending {
 			m.leaderPending = false
 			if msg.Type == tea.KeyEsc || isSpaceKey(msg) {
@@ -490,12 +1177,24 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 			command, found := m.leaderCommands[leaderKey]
+			if !found && leaderKey == diffToolLeaderKey {
+				return m, m.openDiffToolCmd()
+			}
 			if !found {
 				m.setAlert(fmt.Sprintf("No leader command for key %q.", leaderKey))
 				return m, nil
 			}
 			return m, m.execLeaderCommandCmd(leaderKey, command)
 		}
+		if m.address != nil {
+			if next, cmd, handled := m.handleAddressKey(msg); handled {
+				return next, cmd
+			}
+		}
+		if msg.Type == tea.KeyEsc && m.cancelDiffLoad() {
+			m.setAlert("Diff load canceled.")
+			return m, nil
+		}
 		if isSpaceKey(msg) {
 			m.leaderPending = true
 			return m, nil
@@ -556,13 +1255,69 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.helpOpen = !m.helpOpen
 			return m, nil
 		}
+		if key.Matches(msg, m.keys.AlertLog) {
+			m.alertLogOpen = true
+			m.alertLogScroll = 0
+			return m, nil
+		}
+		if key.Matches(msg, m.keys.ReviewQueue) {
+			return m.openReviewQueue()
+		}
+		if key.Matches(msg, m.keys.Worktrees) {
+			return m.openWorktreePicker()
+		}
+		if key.Matches(msg, m.keys.Repos) {
+			return m.openRepoPicker()
+		}
+		if key.Matches(msg, m.keys.Archive) {
+			return m.handleArchiveReview()
+		}
+		if key.Matches(msg, m.keys.History) {
+			return m.openHistory()
+		}
+		if key.Matches(msg, m.keys.Snapshot) {
+			return m.handleTakeSnapshot()
+		}
+		if key.Matches(msg, m.keys.Retry) && (m.filesErr != nil || m.diffErr != nil) {
+			return m.retryFailedLoad()
+		}
+		if key.Matches(msg, m.keys.DeltaMode) {
+			return m.handleToggleDelta()
+		}
+		if key.Matches(msg, m.keys.Checklist) {
+			return m.openChecklist()
+		}
+		if key.Matches(msg, m.keys.Stats) {
+			m.openStats()
+			return m, nil
+		}
+		if key.Matches(msg, m.keys.PerfHUD) {
+			m.togglePerfHUD()
+			return m, nil
+		}
+		if key.Matches(msg, m.keys.Shell) {
+			return m, m.execShellCmd()
+		}
+		if key.Matches(msg, m.keys.Command) {
+			return m.startCommandInput()
+		}
+		if key.Matches(msg, m.keys.AddressMode) {
+			return m.startAddressMode()
+		}
+		if key.Matches(msg, m.keys.CompareRefs) {
+			return m.startCompareRefs()
+		}
+		if key.Matches(msg, m.keys.Commits) {
+			return m.toggleCommitPanel()
+		}
 		if key.Matches(msg, m.keys.Refresh) {
 			diffview.ClearSyntaxCache()
 			if m.reviewMode == reviewModePR {
 				m.prDiffs = make(map[string]prDiffCacheEntry)
 			}
+			m.refreshAnchor = m.captureRefreshAnchor()
 			m.loadingFiles = true
-			return m, m.loadFilesCmd()
+			return m, tea.Batch(m.loadFilesCmd(), m.loadHeadCmd())
 		}
 		if key.Matches(msg, m.keys.ToggleMode) {
 			if m.reviewMode == reviewModePR {
@@ -574,10 +1329,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.loadingDiff = true
 				return m, tea.Batch(
 					m.loadDiffCmd(m.selectedF),
-					m.loadCommentStaleCmd(m.fileItems, m.comments, m.diffMode),
+					m.loadCommentStaleCmd(m.staleCheckItems(), m.comments, m.diffMode),
+					m.loadFileStatsCmd(),
 				)
 			}
-			return m, m.loadCommentStaleCmd(m.fileItems, m.comments, m.diffMode)
+			return m, tea.Batch(m.loadCommentStaleCmd(m.staleCheckItems(), m.comments, m.diffMode), m.loadFileStatsCmd())
 		}
 		if key.Matches(msg, m.keys.ClearAll) {
 			if len(m.comments) == 0 {
@@ -590,6 +1346,25 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if key.Matches(msg, m.keys.SubmitReview) {
 			return m.handleSubmitPRComments()
 		}
+		if m.focus != focusComments {
+			switch {
+			case key.Matches(msg, m.keys.GrowFiles):
+				m.resizeFilePane(filePaneWidthStep)
+				return m, nil
+			case key.Matches(msg, m.keys.ShrinkFiles):
+				m.resizeFilePane(-filePaneWidthStep)
+				return m, nil
+			case key.Matches(msg, m.keys.GrowOld):
+				m.rebalanceSplit(splitPercentStep)
+				return m, nil
+			case key.Matches(msg, m.keys.ShrinkOld):
+				m.rebalanceSplit(-splitPercentStep)
+				return m, nil
+			case key.Matches(msg, m.keys.ToggleLayout):
+				m.toggleDiffLayout()
+				return m, nil
+			}
+		}
 
 		if m.focus == focusFiles {
 			return m.updateFilesPane(msg)
@@ -608,6 +1383,23 @@ func (m Model) updateFilesPane(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.toggleFilePaneWidth()
 		return m, nil
 	}
+	if key.Matches(msg, m.keys.HideIndexFlagged) {
+		m.toggleHideIndexFlagged()
+		if entries := m.fileTreeEntries(); len(entries) > 0 {
+			return m.updateSelectedFileFromCursor(entries)
+		}
+		return m, nil
+	}
+	if key.Matches(msg, m.keys.SortFiles) {
+		m.cycleFileSort()
+		return m, nil
+	}
+	if key.Matches(msg, m.keys.NextCommentedFile) {
+		return m, m.jumpToCommentedFile(1)
+	}
+	if key.Matches(msg, m.keys.PrevCommentedFile) {
+		return m, m.jumpToCommentedFile(-1)
+	}
 
 	entries := m.fileTreeEntries()
 	if len(entries) == 0 {
@@ -654,6 +1446,11 @@ func (m Model) updateFilesPane(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.selectedF = m.fileItems[m.selected].Path
 			m.loadingDiff = true
 			m.focus = focusDiff
+			if m.missingOutsideSparse(m.selectedF) {
+				item := m.fileItems[m.selected]
+				m.sparseConfirm = &item
+				m.focus = focusFiles
+			}
 			return m, m.loadDiffCmd(m.selectedF)
 		}
 		return m, nil
@@ -661,6 +1458,21 @@ func (m Model) updateFilesPane(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case key.Matches(msg, m.keys.Export):
 		return m.handleExportComments()
 
+	case key.Matches(msg, m.keys.ExportFile):
+		return m.handleExportToFile()
+
+	case key.Matches(msg, m.keys.Publish):
+		return m.handlePublish()
+
+	case key.Matches(msg, m.keys.RevertFile):
+		return m.handleRevertFile(entries)
+
+	case key.Matches(msg, m.keys.AddFile):
+		return m.handleAddToIndex(entries, false)
+
+	case key.Matches(msg, m.keys.IntentToAdd):
+		return m.handleAddToIndex(entries, true)
+
 	}
 
 	return m, nil
@@ -745,8 +1557,7 @@ func (m *Model) prPageSize() int {
 	if m.height <= 0 {
 		return 1
 	}
-	footerPlain := truncateLinesToWidth(m.helpText(), m.width)
-	footerHeight := lineCount(footerPlain)
+	footerHeight := m.footerHeight()
 	dockHeight := 0
 	if m.alertMsg != "" {
 		dockHeight = lipgloss.Height(m.renderAlertDock())
@@ -847,7 +1658,10 @@ func (m *Model) pagePRs(direction int) {
 func (m *Model) updateSelectedFileFromCursor(entries []fileTreeEntry) (tea.Model, tea.Cmd) {
 	m.clampFileCursor(entries)
 	entry := entries[m.fileCursor]
-	if entry.IsDir || entry.FileIndex < 0 || entry.FileIndex >= len(m.fileItems) {
+	if entry.IsDir {
+		return *m, m.openDirSummaryAtCursor(entries)
+	}
+	if entry.FileIndex < 0 || entry.FileIndex >= len(m.fileItems) {
 		return *m, nil
 	}
 	if m.selected == entry.FileIndex && m.selectedF == entry.Path {
@@ -856,11 +1670,18 @@ func (m *Model) updateSelectedFileFromCursor(entries []fileTreeEntry) (tea.Model
 	m.selected = entry.FileIndex
 	m.selectedF = entry.Path
 	m.loadingDiff = true
-	return *m, m.loadDiffCmd(m.selectedF)
+	return *m, m.debounceDiffLoad()
 }
 
 func (m Model) updateCommentsPane(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	items := m.sortedComments()
+	if key.Matches(msg, m.keys.FilterComments) {
+		return m.startCommentsFilter()
+	}
+	if key.Matches(msg, m.keys.ClearSelection) {
+		m.commentsSelected = nil
+		return m, nil
+	}
+	items := m.commentsViewRows()
 	if len(items) == 0 {
 		switch {
 		case key.Matches(msg, m.keys.ScrollDown), key.Matches(msg, m.keys.ScrollUp):
@@ -878,6 +1699,10 @@ func (m Model) updateCommentsPane(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	m.clampCommentsCursor(items)
 	switch {
+	case key.Matches(msg, m.keys.SortComments):
+		m.cycleCommentsSort()
+		return m, nil
+
 	case key.Matches(msg, m.keys.Up):
 		if m.commentsCursor > 0 {
 			m.commentsCursor--
@@ -918,25 +1743,61 @@ func (m Model) updateCommentsPane(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.ensureCommentsCursorVisible(items)
 		return m, nil
 
-	case key.Matches(msg, m.keys.Edit):
-		return m, m.startCommentEditByComment(items[m.commentsCursor])
+	case isRuneKey(msg, "h"):
+		m.commentsLeft(items)
+		return m, nil
 
-	case key.Matches(msg, m.keys.Delete):
-		m.deleteCommentByKey(commentKey(items[m.commentsCursor]))
-		next := m.sortedComments()
-		m.clampCommentsCursor(next)
-		m.ensureCommentsCursorVisible(next)
+	case isRuneKey(msg, "l"):
+		m.commentsRight(items)
 		return m, nil
 
-	case key.Matches(msg, m.keys.Open):
-		if m.isCommentStale(items[m.commentsCursor]) {
-			m.setAlert("Selected comment is stale and cannot be jumped to.")
+	case key.Matches(msg, m.keys.SelectComment):
+		m.toggleCommentSelection(items)
+		return m, nil
+
+	case key.Matches(msg, m.keys.Open) && items[m.commentsCursor].Header:
+		path := items[m.commentsCursor].Path
+		m.setCommentGroupCollapsed(path, !m.commentsCollapsed[path])
+		return m, nil
+
+	case key.Matches(msg, m.keys.PinComment):
+		m.toggleCommentPin(items)
+		return m, nil
+
+	case key.Matches(msg, m.keys.Edit), key.Matches(msg, m.keys.Delete), key.Matches(msg, m.keys.Open), key.Matches(msg, m.keys.TodoComment), key.Matches(msg, m.keys.CopyComment):
+		if items[m.commentsCursor].Header {
+			m.setAlert("Select a comment under the file heading.")
+			return m, nil
+		}
+		c := items[m.commentsCursor].Comment
+		switch {
+		case key.Matches(msg, m.keys.CopyComment):
+			return m, m.copyCommentCmd(c)
+		case key.Matches(msg, m.keys.TodoComment):
+			return m, m.convertCommentToTodo(c)
+		case key.Matches(msg, m.keys.Edit):
+			return m, m.startCommentEditByComment(c)
+		case key.Matches(msg, m.keys.Delete):
+			cmd := m.deleteCommentByKey(commentKey(c))
+			next := m.commentsViewRows()
+			m.clampCommentsCursor(next)
+			m.ensureCommentsCursorVisible(next)
+			return m, cmd
+		}
+		if m.isCommentStale(c) {
+			m.openStalePopup(c)
 			return m, nil
 		}
-		return m, m.jumpToCommentInDiff(items[m.commentsCursor])
+		return m, m.jumpToCommentInDiff(c)
 
 	case key.Matches(msg, m.keys.Export):
 		return m.handleExportComments()
+
+	case key.Matches(msg, m.keys.ExportFile):
+		return m.handleExportToFile()
+
+	case key.Matches(msg, m.keys.Publish):
+		return m.handlePublish()
 	}
 
 	return m, nil
@@ -952,7 +1813,7 @@ func (m *Model) handleFilesLeft(entries []fileTreeEntry) (tea.Model, tea.Cmd) {
 		}
 		if parent != "" && m.setFileCursorByDir(entries, parent) {
 			m.ensureFileCursorVisible(entries)
-			return *m, nil
+			return *m, m.openDirSummaryAtCursor(entries)
 		}
 		return *m, nil
 	}
@@ -971,7 +1832,7 @@ func (m *Model) handleFilesLeft(entries []fileTreeEntry) (tea.Model, tea.Cmd) {
 		m.setFileCursorByDir(entries, parent)
 	}
 	m.ensureFileCursorVisible(entries)
-	return *m, nil
+	return *m, m.openDirSummaryAtCursor(entries)
 }
 
 func (m *Model) handleFilesRight(entries []fileTreeEntry) (tea.Model, tea.Cmd) {
@@ -1159,7 +2020,7 @@ func (m *Model) expandDirsForFilePath(filePath string) {
 	if m.treeCollapsed == nil {
 		return
 	}
-	parts := strings.Split(strings.TrimSuffix(filePath, "/"), "/")
+	parts := strings.Split(strings.TrimSuffix(filepath.ToSlash(filePath), "/"), "/")
 	if len(parts) <= 1 {
 		return
 	}
@@ -1235,7 +2096,20 @@ func commentKey(c comments.Comment) string {
 	return comments.AnchorKey(c.Path, c.Side, c.Line)
 }
 
-func (m *Model) clampCommentsCursor(items []comments.Comment) {
+// commentByID finds a comment by its stable ID, wherever it is anchored now.
+func (m Model) commentByID(id string) (comments.Comment, bool) {
+	if id == "" {
+		return comments.Comment{}, false
+	}
+	for _, c := range m.comments {
+		if c.ID == id {
+			return c, true
+		}
+	}
+	return comments.Comment{}, false
+}
+
+func (m *Model) clampCommentsCursor(items []commentsRow) {
 	if len(items) == 0 {
 		m.commentsCursor = 0
 		return
@@ -1252,25 +2126,32 @@ func (m *Model) commentsPageSize() int {
 	if m.height <= 0 {
 		return 1
 	}
-	footerPlain := truncateLinesToWidth(m.helpText(), m.width)
-	footerHeight := lineCount(footerPlain)
+	footerHeight := m.footerHeight()
 	dockHeight := 0
 	if m.commentInputActive {
 		dockHeight = lipgloss.Height(m.renderCommentDock())
 	} else if m.reviewInputActive {
 		dockHeight = lipgloss.Height(m.renderReviewDock())
+	} else if m.exportInputActive {
+		dockHeight = lipgloss.Height(m.renderExportDock())
+	} else if m.commentsFilterActive {
+		dockHeight = lipgloss.Height(m.renderCommentsFilterDock())
+	} else if m.commandInputActive {
+		dockHeight = lipgloss.Height(m.renderCommandDock())
+	} else if m.address != nil {
+		dockHeight = lipgloss.Height(m.renderAddressDock())
 	} else if m.alertMsg != "" {
 		dockHeight = lipgloss.Height(m.renderAlertDock())
 	}
 	paneContentHeight := max(1, m.height-footerHeight-dockHeight-2)
-	listHeight := paneContentHeight - 2
+	listHeight := paneContentHeight - 2 - len(m.commentDetail(max(1, m.width-2), paneContentHeight))
 	if listHeight < 1 {
 		listHeight = 1
 	}
 	return listHeight
 }
 
-func (m *Model) ensureCommentsCursorVisible(items []comments.Comment) {
+func (m *Model) ensureCommentsCursorVisible(items []commentsRow) {
 	m.clampCommentsCursor(items)
 	page := m.commentsPageSize()
 	if page < 1 {
@@ -1300,7 +2181,7 @@ func (m *Model) ensureCommentsCursorVisible(items []comments.Comment) {
 	}
 }
 
-func (m *Model) scrollCommentsWindow(delta int, items []comments.Comment) {
+func (m *Model) scrollCommentsWindow(delta int, items []commentsRow) {
 	if len(items) == 0 || delta == 0 {
 		return
 	}
@@ -1337,7 +2218,7 @@ func (m *Model) scrollCommentsWindow(delta int, items []comments.Comment) {
 	}
 }
 
-func (m *Model) pageComments(direction int, items []comments.Comment) {
+func (m *Model) pageComments(direction int, items []commentsRow) {
 	if len(items) == 0 || direction == 0 {
 		return
 	}
@@ -1382,7 +2263,7 @@ func (m *Model) jumpToCommentAnchor(anchor commentAnchor) bool {
 		}
 		switch anchor.Side {
 		case comments.SideOld:
-			if row.OldLine != nil && *row.OldLine == anchor.Line {
+			if row.OldLine > 0 && row.OldLine == anchor.Line {
 				m.diffCursor = i
 				m.diffDirty = true
 				m.refreshDiffContent()
@@ -1390,7 +2271,7 @@ func (m *Model) jumpToCommentAnchor(anchor commentAnchor) bool {
 				return true
 			}
 		case comments.SideNew:
-			if row.NewLine != nil && *row.NewLine == anchor.Line {
+			if row.NewLine > 0 && row.NewLine == anchor.Line {
 				m.diffCursor = i
 				m.diffDirty = true
 				m.refreshDiffContent()
@@ -1412,12 +2293,28 @@ func (m Model) updateDiffPane(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.ensureFilePaneVisible()
 		return m, nil
 	}
+	if key.Matches(msg, m.keys.NextCommentedFile) {
+		return m, m.jumpToCommentedFile(1)
+	}
+	if key.Matches(msg, m.keys.PrevCommentedFile) {
+		return m, m.jumpToCommentedFile(-1)
+	}
 
 	if len(m.diffRows) == 0 {
 		return m, nil
 	}
 
+	if m.copyMode {
+		if cmd, handled := m.handleCopyModeKey(msg); handled {
+			return m, cmd
+		}
+	}
+
 	switch {
+	case isRuneKey(msg, "v"):
+		m.startCopyMode()
+		return m, nil
+
 	case key.Matches(msg, m.keys.Up):
 		m.moveDiffCursor(-1)
 		return m, nil
@@ -1454,6 +2351,42 @@ func (m Model) updateDiffPane(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.refreshDiffContent()
 		return m, nil
 
+	case key.Matches(msg, m.keys.ZoomNew):
+		m.toggleZoom(diffPaneModeNewOnly)
+		return m, nil
+
+	case key.Matches(msg, m.keys.ZoomOld):
+		m.toggleZoom(diffPaneModeOldOnly)
+		return m, nil
+
+	case key.Matches(msg, m.keys.LineNumbers):
+		m.cycleLineNumbers()
+		return m, nil
+
+	case key.Matches(msg, m.keys.WordWrap):
+		m.toggleWordWrap()
+		return m, nil
+
+	case key.Matches(msg, m.keys.CommentPlacement):
+		m.cycleCommentPlacement()
+		return m, nil
+
+	case key.Matches(msg, m.keys.ExpandComment):
+		m.toggleCommentExpanded()
+		return m, nil
+
+	case key.Matches(msg, m.keys.HideComments):
+		m.toggleHideComments()
+		return m, nil
+
+	case key.Matches(msg, m.keys.ExpandLine):
+		m.toggleLineExpanded()
+		return m, nil
+
+	case key.Matches(msg, m.keys.RecaptureContext):
+		m.recaptureCommentContext()
+		return m, nil
+
 	case key.Matches(msg, m.keys.Create):
 		return m, m.startCommentEdit(false)
 
@@ -1461,8 +2394,10 @@ func (m Model) updateDiffPane(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, m.startCommentEdit(true)
 
 	case key.Matches(msg, m.keys.Delete):
-		m.deleteCommentAtCursor()
-		return m, nil
+		return m, m.deleteCommentAtCursor()
+
+	case key.Matches(msg, m.keys.CopyComment):
+		return m, m.copyCommentAtCursor()
 
 	case key.Matches(msg, m.keys.NextComment):
 		m.jumpToComment(1)
@@ -1472,15 +2407,29 @@ func (m Model) updateDiffPane(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.jumpToComment(-1)
 		return m, nil
 
+	case key.Matches(msg, m.keys.NextHunk):
+		m.jumpToHunk(1)
+		return m, nil
+
+	case key.Matches(msg, m.keys.PrevHunk):
+		m.jumpToHunk(-1)
+		return m, nil
+
 	case key.Matches(msg, m.keys.Export):
 		return m.handleExportComments()
+
+	case key.Matches(msg, m.keys.ExportFile):
+		return m.handleExportToFile()
+
+	case key.Matches(msg, m.keys.Publish):
+		return m.handlePublish()
 	}
 	return m, nil
 }
 
 func (m Model) handleExportComments() (tea.Model, tea.Cmd) {
-	if len(m.exportableComments()) == 0 {
-		m.setAlert("No non-stale comments to export.")
+	if snapshot, scope := m.exportScope(); len(snapshot) == 0 {
+		m.setAlert(exportScopeEmptyMessage(scope))
 		return m, nil
 	}
 	return m, m.exportCommentsCmd()
@@ -1492,7 +2441,7 @@ func (m Model) handleSubmitPRComments() (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
-	draft := m.exportableComments()
+	draft := ownComments(m.exportableComments())
 	if len(draft) == 0 {
 		m.setAlert("No non-stale comments to submit.")
 		return m, nil
@@ -1563,21 +2512,27 @@ func (m *Model) resetReviewSubmissionState() {
 func (m Model) handleCommentInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.Type {
 	case tea.KeyEsc:
-		m.commentInputActive = false
-		m.commentInputModel.SetValue("")
-		m.commentInputModel.Blur()
-		m.commentInputErr = ""
-		m.commentEditAnchor = nil
-		m.commentEditKey = ""
+		m.stashCommentDraft()
+		m.closeCommentInput()
 		return m, nil
 
 	case tea.KeyEnter:
 		return m, m.saveCommentInput()
+
+	case tea.KeyCtrlC:
+		if strings.TrimSpace(m.commentInputModel.Value()) == "" {
+			m.closeCommentInput()
+			m.flushDraft()
+			return m, tea.Quit
+		}
+		m.quitConfirmModal = true
+		return m, nil
 	}
 
 	var cmd tea.Cmd
 	m.commentInputModel, cmd = m.commentInputModel.Update(msg)
 	m.commentInputErr = ""
+	m.draftDirty = true
 	return m, cmd
 }
 
@@ -1588,14 +2543,12 @@ func (m Model) handleClearConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	case tea.KeyEnter:
 		m.clearConfirmModal = false
-		m.clearAllComments()
-		return m, nil
+		return m, m.clearAllComments()
 	case tea.KeyRunes:
 		switch msg.String() {
 		case "y", "Y":
 			m.clearConfirmModal = false
-			m.clearAllComments()
-			return m, nil
+			return m, m.clearAllComments()
 		case "n", "N":
 			m.clearConfirmModal = false
 			return m, nil
@@ -1606,10 +2559,7 @@ func (m Model) handleClearConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 func (m *Model) saveCommentInput() tea.Cmd {
 	if m.commentEditAnchor == nil && m.commentEditKey == "" {
-		m.commentInputActive = false
-		m.commentInputModel.SetValue("")
-		m.commentInputModel.Blur()
-		m.commentInputErr = ""
+		m.closeCommentInput()
 		return nil
 	}
 
@@ -1631,36 +2581,46 @@ func (m *Model) saveCommentInput() tea.Cmd {
 			m.commentInputErr = fmt.Sprintf("failed to save comment: %v", err)
 			return nil
 		}
-		m.commentInputActive = false
-		m.commentInputModel.SetValue("")
-		m.commentInputModel.Blur()
-		m.commentInputErr = ""
-		m.commentEditAnchor = nil
-		m.commentEditKey = ""
+		m.dropStashedDraft(m.commentEditKey)
+		m.closeCommentInput()
 		m.diffDirty = true
 		m.refreshDiffContent()
-		return nil
+		return m.hookCmd(hookPayload{Event: config.HookCommentEdit, Comment: &existing})
 	}
 
 	anchor := *m.commentEditAnchor
 	key := comments.AnchorKey(anchor.Path, anchor.Side, anchor.Line)
 	existing, exists := m.comments[key]
+	id := comments.NewID()
 	createdAt := time.Now()
+	var updatedAt time.Time
 	if exists {
+		id = existing.ID
 		createdAt = existing.CreatedAt
+		updatedAt = time.Now()
 	}
 
 	contextBefore, contextAfter := m.contextAround(anchor)
-	m.comments[key] = comments.Comment{
+	saved := comments.Comment{
+		ID:            id,
 		Path:          anchor.Path,
 		Side:          anchor.Side,
 		Line:          anchor.Line,
 		Body:          body,
 		CreatedAt:     createdAt,
+		UpdatedAt:     updatedAt,
 		HunkHeader:    m.hunkHeaderForRow(anchor.RowIdx, anchor.Path),
 		ContextBefore: contextBefore,
 		ContextAfter:  contextAfter,
+		Pinned:        existing.Pinned,
+		Resolution:    existing.Resolution,
+		Reply:         existing.Reply,
+		PR:            existing.PR,
 	}
+	if !exists {
+		saved.PR = m.commentPR()
+	}
+	m.comments[key] = saved
 	if m.commentStale == nil {
 		m.commentStale = make(map[string]bool)
 	}
@@ -1670,16 +2630,12 @@ func (m *Model) saveCommentInput() tea.Cmd {
 		m.commentInputErr = fmt.Sprintf("failed to save comment: %v", err)
 		return nil
 	}
+	m.dropStashedDraft(key)
 
-	m.commentInputActive = false
-	m.commentInputModel.SetValue("")
-	m.commentInputModel.Blur()
-	m.commentInputErr = ""
-	m.commentEditAnchor = nil
-	m.commentEditKey = ""
+	m.closeCommentInput()
 	m.diffDirty = true
 	m.refreshDiffContent()
-	return nil
+	return m.hookCmd(hookPayload{Event: commentCreateOrEditEvent(exists), Comment: &saved})
 }
 
 func (m *Model) startCommentEdit(requireExisting bool) tea.Cmd {
@@ -1707,6 +2663,7 @@ func (m *Model) startCommentEdit(requireExisting bool) tea.Cmd {
 	a := anchor
 	m.commentEditAnchor = &a
 	m.commentEditKey = key
+	m.applyStashedDraft(key)
 	return cmd
 }
 
@@ -1723,41 +2680,45 @@ func (m *Model) startCommentEditByComment(c comments.Comment) tea.Cmd {
 	m.commentEditKey = key
 	cmd := m.commentInputModel.Focus()
 	m.commentInputModel.CursorEnd()
+	m.applyStashedDraft(key)
 	return cmd
 }
 
-func (m *Model) deleteCommentAtCursor() {
+func (m *Model) deleteCommentAtCursor() tea.Cmd {
 	anchor, ok := m.currentAnchor()
 	if !ok {
 		m.setAlert("No commentable line selected.")
-		return
+		return nil
 	}
 
 	key := comments.AnchorKey(anchor.Path, anchor.Side, anchor.Line)
 	if _, exists := m.comments[key]; !exists {
 		m.setAlert("No comment exists on selected line.")
-		return
+		return nil
 	}
-	m.deleteCommentByKey(key)
+	return m.deleteCommentByKey(key)
 }
 
-func (m *Model) deleteCommentByKey(key string) {
-	if _, exists := m.comments[key]; !exists {
-		return
+func (m *Model) deleteCommentByKey(key string) tea.Cmd {
+	c, exists := m.comments[key]
+	if !exists {
+		return nil
 	}
 	delete(m.comments, key)
 	delete(m.commentStale, key)
+	delete(m.commentsSelected, key)
 	if err := m.persistComments(); err != nil {
 		m.setAlert(fmt.Sprintf("failed to save comments: %v", err))
-		return
+		return nil
 	}
 	m.diffDirty = true
 	m.refreshDiffContent()
+	return m.hookCmd(hookPayload{Event: config.HookCommentDelete, Comments: []comments.Comment{c}})
 }
 
-func (m *Model) clearAllComments() {
+func (m *Model) clearAllComments() tea.Cmd {
 	if len(m.comments) == 0 {
-		return
+		return nil
 	}
 	prev := m.comments
 	prevStale := m.commentStale
@@ -1767,10 +2728,14 @@ func (m *Model) clearAllComments() {
 		m.comments = prev
 		m.commentStale = prevStale
 		m.setAlert(fmt.Sprintf("failed to clear comments: %v", err))
-		return
+		return nil
 	}
+	m.commentsSelected = nil
+	m.resetChecklist()
 	m.diffDirty = true
 	m.refreshDiffContent()
+	cleared := Model{comments: prev}.sortedComments()
+	return m.hookCmd(hookPayload{Event: config.HookCommentDelete, Comments: cleared})
 }
 
 func (m *Model) removeSubmittedComments(submitted []comments.Comment) error {
@@ -1778,9 +2743,22 @@ func (m *Model) removeSubmittedComments(submitted []comments.Comment) error {
 		return nil
 	}
 
+	// Match by ID so a comment that moved while the review was submitted is
+	// still the one removed.
+	submittedIDs := make(map[string]bool, len(submitted))
+	for _, c := range submitted {
+		submittedIDs[c.ID] = true
+	}
 	remove := make(map[string]bool, len(submitted))
+	for k, c := range m.comments {
+		if c.ID != "" && submittedIDs[c.ID] {
+			remove[k] = true
+		}
+	}
 	for _, c := range submitted {
-		remove[commentKey(c)] = true
+		if c.ID == "" {
+			remove[commentKey(c)] = true
+		}
 	}
 
 	prevComments := m.comments
@@ -1846,27 +2824,41 @@ func (m *Model) jumpToComment(direction int) {
 	m.scrollCursorWithPadding(10)
 }
 
-func (m Model) View() string {
+func (m Model) View() (view string) {
+	if m.crash.crashed() {
+		return m.renderCrashNotice()
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			m.handlePanic("view", nil, r)
+			view = m.renderCrashNotice()
+		}
+	}()
+	return m.view()
+}
+
+func (m Model) view() string {
 	if !m.ready {
 		return "Loading..."
 	}
+	if m.perfHUD {
+		defer m.perf.timeFrame(time.Now())
+	}
 
 	help := m.helpText()
 
 	footerHelpPlain := truncateLinesToWidth(help, m.width)
 	footerLines := []string{
+		m.renderStatusBar(),
 		lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Render(footerHelpPlain),
 	}
-	if m.reviewMode == reviewModePR && m.prCtx != nil {
-		prLine := truncateLinesToWidth(
-			fmt.Sprintf("Review target: PR #%d %s/%s", m.prCtx.Number, m.prCtx.Owner, m.prCtx.Repo),
-			m.width,
-		)
-		footerLines = append(footerLines, lipgloss.NewStyle().Foreground(lipgloss.Color("111")).Render(prLine))
-	}
 	if staleCount := m.staleCommentCount(); staleCount > 0 {
+		staleMark := "⚠"
+		if diffview.PlainMode() {
+			staleMark = "!!"
+		}
 		warn := truncateLinesToWidth(
-			fmt.Sprintf("Warning: %d stale comment(s). They are marked with ⚠ and excluded from export.", staleCount),
+			fmt.Sprintf("Warning: %d stale comment(s). They are marked with %s and excluded from export.", staleCount, staleMark),
 			m.width,
 		)
 		footerLines = append(footerLines, lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true).Render(warn))
@@ -1882,15 +2874,28 @@ func (m Model) View() string {
 	} else if m.reviewInputActive {
 		dock = m.renderReviewDock()
 		dockHeight = lipgloss.Height(dock)
+	} else if m.exportInputActive {
+		dock = m.renderExportDock()
+		dockHeight = lipgloss.Height(dock)
+	} else if m.commentsFilterActive {
+		dock = m.renderCommentsFilterDock()
+		dockHeight = lipgloss.Height(dock)
+	} else if m.commandInputActive {
+		dock = m.renderCommandDock()
+		dockHeight = lipgloss.Height(dock)
+	} else if m.address != nil {
+		dock = m.renderAddressDock()
+		dockHeight = lipgloss.Height(dock)
 	} else if m.alertMsg != "" {
 		dock = m.renderAlertDock()
 		dockHeight = lipgloss.Height(dock)
 	}
 
-	leftW, rightW := paneWidths(m.width, m.filePaneW, m.fileHidden, m.diffPaneMode() == diffPaneModeSplit)
-	oldPaneW, newPaneW := m.diffSidePaneWidths(rightW)
+	leftW, rightW := paneWidths(m.width, m.filePaneW, m.filePaneHidden(), m.sideBySide())
+	oldPaneW, newPaneW := m.diffSidePaneWidths(max(1, rightW-m.minimapReserve()))
 	// lipgloss Height applies to content height; borders add 2 more rows.
 	paneContentHeight := max(1, m.height-footerHeight-dockHeight-2)
+	oldPaneH, newPaneH := m.diffSidePaneHeights(paneContentHeight)
 	newOldWidth := oldPaneW
 	if newOldWidth <= 0 {
 		newOldWidth = 1
@@ -1904,8 +2909,8 @@ func (m Model) View() string {
 	}
 	m.oldView.Width = newOldWidth
 	m.newView.Width = newNewWidth
-	m.oldView.Height = max(1, paneContentHeight-4)
-	m.newView.Height = max(1, paneContentHeight-4)
+	m.oldView.Height = max(1, oldPaneH-4)
+	m.newView.Height = max(1, newPaneH-4)
 	m.refreshDiffContent()
 
 	content := ""
@@ -1914,9 +2919,22 @@ func (m Model) View() string {
 	} else if m.focus == focusComments {
 		content = m.renderCommentsPane(m.width, paneContentHeight)
 	} else {
-		rightPane := m.renderDiffPanes(oldPaneW, newPaneW, paneContentHeight)
+		var rightPane string
+		if m.dirSummaryShown() {
+			// One box spans the area, so the divider of split panes is free.
+			summaryW := rightW
+			if m.sideBySide() {
+				summaryW++
+			}
+			rightPane = m.renderDirSummaryPane(summaryW, paneContentHeight)
+		} else {
+			rightPane = m.renderDiffPanes(oldPaneW, newPaneW, paneContentHeight)
+			if m.showMinimap() {
+				rightPane = lipgloss.JoinHorizontal(lipgloss.Top, rightPane, m.renderMinimap(lipgloss.Height(rightPane)))
+			}
+		}
 		content = rightPane
-		if !m.fileHidden {
+		if !m.filePaneHidden() {
 			leftPane := m.renderFilesPane(leftW, paneContentHeight)
 			content = lipgloss.JoinHorizontal(lipgloss.Top, leftPane, rightPane)
 		}
@@ -1929,9 +2947,58 @@ func (m Model) View() string {
 	if m.clearConfirmModal {
 		body = overlayCentered(body, m.renderClearAllConfirmModal(), m.width, lipgloss.Height(body))
 	}
+	if m.publishConfirmModal {
+		body = overlayCentered(body, m.renderPublishConfirmModal(), m.width, lipgloss.Height(body))
+	}
+	if m.revertConfirm != nil {
+		body = overlayCentered(body, m.renderRevertConfirmModal(), m.width, lipgloss.Height(body))
+	}
+	if m.sparseConfirm != nil {
+		body = overlayCentered(body, m.renderSparseConfirmModal(), m.width, lipgloss.Height(body))
+	}
+	if m.cleanupConfirmModal {
+		body = overlayCentered(body, m.renderCleanupConfirmModal(), m.width, lipgloss.Height(body))
+	}
 	if m.reviewActionModal {
 		body = overlayCentered(body, m.renderReviewActionModal(), m.width, lipgloss.Height(body))
 	}
+	if m.alertLogOpen {
+		body = overlayCentered(body, m.renderAlertLogModal(), m.width, lipgloss.Height(body))
+	}
+	if m.prQueueOpen {
+		body = overlayCentered(body, m.renderReviewQueueModal(), m.width, lipgloss.Height(body))
+	}
+	if m.historyOpen {
+		body = overlayCentered(body, m.renderHistoryModal(), m.width, lipgloss.Height(body))
+	}
+	if m.worktreeOpen {
+		body = overlayCentered(body, m.renderWorktreePicker(), m.width, lipgloss.Height(body))
+	}
+	if m.repoPickerOpen {
+		body = overlayCentered(body, m.renderRepoPicker(), m.width, lipgloss.Height(body))
+	}
+	if m.statsOpen {
+		body = overlayCentered(body, m.renderStatsModal(), m.width, lipgloss.Height(body))
+	}
+	if m.refCompare != nil {
+		body = overlayCentered(body, m.renderRefCompareModal(), m.width, lipgloss.Height(body))
+	}
+	if m.commitPanelOpen {
+		body = overlayCentered(body, m.renderCommitPanelModal(), m.width, lipgloss.Height(body))
+	}
+	if m.checklistOpen {
+		body = overlayCentered(body, m.renderChecklistModal(), m.width, lipgloss.Height(body))
+	}
+	if m.stalePopupKey != "" {
+		body = overlayCentered(body, m.renderStalePopup(), m.width, lipgloss.Height(body))
+	}
+	if m.quitConfirmModal {
+		body = overlayCentered(body, m.renderQuitConfirmModal(), m.width, lipgloss.Height(body))
+	}
+	if m.perfHUD {
+		hud := m.renderPerfHUD()
+		body = overlayAt(body, hud, max(0, m.width-lipgloss.Width(hud)-1), 1, m.width, lipgloss.Height(body))
+	}
 	return lipgloss.JoinVertical(lipgloss.Left, body, footer)
 }
 
@@ -1948,29 +3015,36 @@ func (m Model) helpText() string {
 			"PR picker: j/k move, ctrl-e/ctrl-y scroll, ctrl-f/ctrl-b page, g/G top/bottom, enter open PR, r refresh, q quit",
 		}, "\n")
 	}
-	modeHint := ""
-	if m.reviewMode == reviewModePR && m.prCtx != nil {
-		modeHint = fmt.Sprintf("PR #%d %s/%s | ", m.prCtx.Number, m.prCtx.Owner, m.prCtx.Repo)
-	}
 	if !m.helpOpen {
-		return leaderHint + modeHint + "tab focus | m comments view | j/k move | ctrl-f/b page | ctrl-e/y scroll | enter open diff | z zoom/hide files | <space> cmd | t mode | c/e/d comment | n/p comment nav | y export | s submit PR | C clear all | r refresh | ? help | q quit"
+		return leaderHint + "tab focus | m comments view | j/k move | ctrl-f/b page | ctrl-e/y scroll | enter open diff | z zoom/hide files | <space> cmd | t mode | c/e/d comment | n/p comment nav | y export | W export to file | B publish | s submit PR | O review queue | S snapshot | R re-review | A archive | H history | w worktrees | ctrl-r repositories | C clear all | r refresh | L notices | ? help | q quit"
 	}
 	return strings.Join([]string{
-		modeHint + "Global: q quit, tab switch focus, m comments view, t toggle diff mode, C clear all comments, ? toggle help",
-		"Leader: <space><key> runs configured command from ~/.config/diffman/config.json",
-		"Files pane: j/k move, ctrl-e/ctrl-y scroll, h/l tree nav, enter open diff, z toggle file pane width, r refresh",
-		"Diff pane: j/k move cursor, ctrl-e/ctrl-y scroll, ctrl-f/ctrl-b page, g/G top/bottom, h focus files, z/l hide/show file list",
-		"Comments view: j/k move, ctrl-e/ctrl-y scroll, ctrl-f/ctrl-b page, g/G top/bottom, e edit, d delete, enter jump to diff",
-		"Comments: c create, e edit, d delete, n/p next/prev, y export to clipboard, s submit PR comments",
+		"Global: q quit, tab switch focus, m comments view, t toggle diff mode, C clear all comments, O review queue, S snapshot reviewed state, R re-review changes since snapshot, F5 retry a failed load, A archive review, H review history, I review statistics, T review checklist, w switch worktree, ctrl+r switch repository, L notice log, ! shell in repository root, :c N jump to comment N of the latest export, alt+r compare the file between two refs, F address feedback, M PR commit messages, ? toggle help",
+		"Leader: <space><key> runs configured command from ~/.config/diffman/config.json; <space>d opens the selected file in the configured difftool",
+		"Files pane: j/k move, ctrl-e/ctrl-y scroll, h/l tree nav, enter open diff, z toggle file pane width, U revert file to HEAD (delete if untracked), a / alt+a git add / git add -N untracked file, alt+h hide/show skip-worktree and assume-unchanged files, o cycle sort (path/status/size/modified/comments), </> resize, r refresh",
+		"Layout: </> narrow/widen file pane, +/- grow old/new diff pane, V stack/unstack old and new panes (sizes are remembered per repository)",
+		"Copy: v select rows in diff, then y copy new side, Y copy old side, Esc cancel",
+		"Zoom: Z maximize/restore new pane, alt+z maximize/restore old pane, # cycle line numbers (absolute/relative/hidden/both), alt+w toggle word wrap, alt+c cycle inline comments (side/across/collapsed), o expand/collapse the comment on the line, alt+o hide/show all inline comments, E expand/truncate a long line",
+		"Diff pane: j/k move cursor, ctrl-e/ctrl-y scroll, ctrl-f/ctrl-b page, g/G top/bottom, ]/[ next/prev hunk, h focus files, z/l hide/show file list",
+		"Comments view: j/k move, ctrl-e/ctrl-y scroll, ctrl-f/ctrl-b page, g/G top/bottom, h/l collapse/expand file, e edit, d delete, enter jump to diff, o cycle sort (file/newest/severity), / filter, x select, X clear selection (y/W export the selection or filter), b pin/unpin, Y copy the comment, D convert to a TODO(reviewer) line in the file",
+		"Comments: c create, e edit, d delete, n/p next/prev, N/P next/prev commented file, u re-capture context, y export to clipboard, Y copy the comment on the line, W export to file, B publish to webhook, s submit PR comments",
 	}, "\n")
 }
 
+// footerHeight is the number of rows below the panes: status bar, help, and warnings.
+func (m *Model) footerHeight() int {
+	height := 1 + lineCount(truncateLinesToWidth(m.helpText(), m.width))
+	if m.staleCommentCount() > 0 {
+		height++
+	}
+	return height
+}
+
 func (m *Model) fileListPageSize() int {
 	if m.height <= 0 {
 		return 1
 	}
-	footerPlain := truncateLinesToWidth(m.helpText(), m.width)
-	footerHeight := lineCount(footerPlain)
+	footerHeight := m.footerHeight()
 	dockHeight := 0
 	if m.commentInputActive {
 		dockHeight = lipgloss.Height(m.renderCommentDock())
@@ -2002,15 +3076,19 @@ func (m Model) renderCommentDock() string {
 	inputBox := lipgloss.NewStyle().
 		Width(bodyInnerW).
 		MaxWidth(bodyInnerW).
-		Border(lipgloss.NormalBorder()).
+		Border(diffview.Border(lipgloss.NormalBorder())).
 		BorderForeground(lipgloss.Color("63")).
 		Padding(0, 1).
 		Render(input.View())
 	hint := lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Render(
-		ansi.Truncate("Enter save | Esc cancel | Backspace delete", bodyInnerW, ""),
+		ansi.Truncate("Enter save | Esc cancel | Backspace delete | ctrl+c quit", bodyInnerW, ""),
 	)
 
-	bodyLines := []string{inputBox, "", hint}
+	bodyLines := []string{inputBox}
+	if typos := m.renderSpellingLine(bodyInnerW); typos != "" {
+		bodyLines = append(bodyLines, typos)
+	}
+	bodyLines = append(bodyLines, "", hint)
 	if m.commentInputErr != "" {
 		bodyLines = append(bodyLines, "")
 		bodyLines = append(bodyLines, lipgloss.NewStyle().Foreground(lipgloss.Color("203")).Render(
@@ -2031,7 +3109,7 @@ func (m Model) renderReviewDock() string {
 	inputBox := lipgloss.NewStyle().
 		Width(bodyInnerW).
 		MaxWidth(bodyInnerW).
-		Border(lipgloss.NormalBorder()).
+		Border(diffview.Border(lipgloss.NormalBorder())).
 		BorderForeground(lipgloss.Color("111")).
 		Padding(0, 1).
 		Render(input.View())
@@ -2043,7 +3121,7 @@ func (m Model) renderReviewDock() string {
 }
 
 func (m Model) renderAlertDock() string {
-	hint := lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Render("Auto-hides after 3s")
+	hint := lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Render("Auto-hides after 3s | L notice log")
 	body := strings.Join([]string{
 		m.alertMsg,
 		"",
@@ -2079,7 +3157,7 @@ func (m Model) renderClearAllConfirmModal() string {
 
 	return lipgloss.NewStyle().
 		Width(width).
-		Border(lipgloss.RoundedBorder()).
+		Border(diffview.Border(lipgloss.RoundedBorder())).
 		BorderForeground(lipgloss.Color("196")).
 		Render(title + "\n" + bodyBlock)
 }
@@ -2115,7 +3193,7 @@ func (m Model) renderReviewActionModal() string {
 
 	return lipgloss.NewStyle().
 		Width(width).
-		Border(lipgloss.RoundedBorder()).
+		Border(diffview.Border(lipgloss.RoundedBorder())).
 		BorderForeground(lipgloss.Color("111")).
 		Render(title + "\n" + bodyBlock)
 }
@@ -2139,7 +3217,7 @@ func (m Model) renderDockPanel(title string, titleColor, borderColor lipgloss.Co
 
 	return lipgloss.NewStyle().
 		Width(contentW).
-		Border(lipgloss.RoundedBorder()).
+		Border(diffview.Border(lipgloss.RoundedBorder())).
 		BorderForeground(borderColor).
 		Render(titleBar + "\n" + bodyBlock)
 }
@@ -2149,12 +3227,12 @@ func (m Model) renderPRPickerPane(width, height int) string {
 	paneStyle := lipgloss.NewStyle().
 		Width(max(1, width)).
 		Height(max(1, height)).
-		Border(lipgloss.NormalBorder()).
+		Border(diffview.Border(lipgloss.NormalBorder())).
 		BorderForeground(borderColor)
 
 	title := "Open Pull Requests"
 	if m.loadingPRs {
-		title += " (loading...)"
+		title += m.loadingSuffix(m.prsLoadStart, false)
 	}
 
 	bodyLines := []string{title, ""}
@@ -2221,7 +3299,7 @@ func (m Model) renderPRPickerPane(width, height int) string {
 }
 
 func (m Model) renderFilesPane(width, height int) string {
-	border := lipgloss.NormalBorder()
+	border := diffview.Border(lipgloss.NormalBorder())
 	borderColor := lipgloss.Color("245")
 	if m.focus == focusFiles {
 		borderColor = lipgloss.Color("39")
@@ -2234,15 +3312,14 @@ func (m Model) renderFilesPane(width, height int) string {
 		BorderForeground(borderColor)
 
 	title := fmt.Sprintf("Files (%d)", len(m.fileItems))
-	if m.reviewMode == reviewModePR && m.prCtx != nil {
-		title += fmt.Sprintf(" | PR #%d", m.prCtx.Number)
+	if m.fileSort != fileSortByPath {
+		title = fmt.Sprintf("Files (%d, by %s)", len(m.fileItems), m.fileSort)
 	}
 	if m.loadingFiles {
-		title += " (loading...)"
+		title += m.loadingSuffix(m.filesLoadStart, false)
 	}
 
 	innerW := max(1, width)
-	commentMarkStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("141")).Bold(true)
 	bodyLines := make([]string, 0, len(m.fileItems)+2)
 	bodyLines = append(bodyLines, title)
 	bodyLines = append(bodyLines, "")
@@ -2279,6 +3356,8 @@ func (m Model) renderFilesPane(width, height int) string {
 			end = len(entries)
 		}
 
+		columns := m.fileMetaColumns(entries, start, end, innerW)
+		metaW := fileMetaWidth(columns)
 		for i := start; i < end; i++ {
 			entry := entries[i]
 			prefix := "  "
@@ -2292,13 +3371,31 @@ func (m Model) renderFilesPane(width, height int) string {
 				if m.isDirCollapsed(entry.Path) {
 					icon = ""
 				}
+				if diffview.PlainMode() {
+					icon = "[-]"
+					if m.isDirCollapsed(entry.Path) {
+						icon = "[+]"
+					}
+				}
 				line = fmt.Sprintf("%s%s%s %s/", prefix, indent, icon, entry.Name)
 			} else {
-				commentMark := "  "
-				if entry.HasComment {
-					commentMark = commentMarkStyle.Render("✎ ")
+				name := fileIconFor(entry.Name, m.fileIcons) + " " + entry.Name
+				line = fmt.Sprintf("%s%s%s %s", prefix, indent, fileStatusSymbolStyled(entry.Status), name)
+				if entry.OrigPath != "" {
+					arrow := " ← "
+					if diffview.PlainMode() {
+						arrow = " <- "
+					}
+					line += lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Render(arrow + entry.OrigPath)
+				}
+				if entry.IndexFlags != "" {
+					line += lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Italic(true).Render(" (" + entry.IndexFlags + ")")
+				}
+				if metaW > 0 {
+					nameW := innerW - metaW - 1
+					line = ansi.Truncate(line, nameW, "…")
+					line += strings.Repeat(" ", max(0, nameW-lipgloss.Width(line))) + " " + fileMetaCells(columns, i-start)
 				}
-				line = fmt.Sprintf("%s%s%s%s %s", prefix, indent, commentMark, fileStatusSymbolStyled(entry.Status), entry.Name)
 			}
 			line = ansi.Truncate(line, innerW, "")
 			lineStyle := lipgloss.NewStyle().Width(innerW).MaxWidth(innerW)
@@ -2312,16 +3409,17 @@ func (m Model) renderFilesPane(width, height int) string {
 		}
 	}
 
-	if m.err != nil {
+	if m.filesErr != nil {
 		bodyLines = append(bodyLines, "")
-		bodyLines = append(bodyLines, fmt.Sprintf("error: %v", m.err))
+		bodyLines = append(bodyLines, lipgloss.NewStyle().Foreground(lipgloss.Color("203")).Render("Failed to list changed files."))
+		bodyLines = append(bodyLines, "Press F5 to retry.")
 	}
 
 	return paneStyle.Render(strings.Join(bodyLines, "\n"))
 }
 
 func (m Model) renderCommentsPane(width, height int) string {
-	border := lipgloss.NormalBorder()
+	border := diffview.Border(lipgloss.NormalBorder())
 	borderColor := lipgloss.Color("245")
 	if m.focus == focusComments {
 		borderColor = lipgloss.Color("39")
@@ -2333,7 +3431,7 @@ func (m Model) renderCommentsPane(width, height int) string {
 		Border(border).
 		BorderForeground(borderColor)
 
-	items := m.sortedComments()
+	items := m.commentsViewRows()
 	cursor := m.commentsCursor
 	if cursor < 0 {
 		cursor = 0
@@ -2343,11 +3441,22 @@ func (m Model) renderCommentsPane(width, height int) string {
 	}
 
 	bodyLines := make([]string, 0, len(items)+2)
-	title := fmt.Sprintf("Comments (%d)", len(items))
+	title := fmt.Sprintf("Comments (%d in %d files, sorted by %s", len(m.comments), commentFileCount(m.commentsViewItems()), m.commentsSort)
+	if filter := strings.TrimSpace(m.commentsFilter); filter != "" {
+		title += fmt.Sprintf(", %d matching %q", len(m.commentsViewItems()), filter)
+	}
+	if n := m.selectedCommentCount(); n > 0 {
+		title += fmt.Sprintf(", %d selected", n)
+	}
+	title += ")"
 	bodyLines = append(bodyLines, title)
 	bodyLines = append(bodyLines, "")
 	if len(items) == 0 {
-		bodyLines = append(bodyLines, "No comments")
+		if strings.TrimSpace(m.commentsFilter) != "" {
+			bodyLines = append(bodyLines, "No comments match the filter")
+		} else {
+			bodyLines = append(bodyLines, "No comments")
+		}
 		return paneStyle.Render(strings.Join(bodyLines, "\n"))
 	}
 
@@ -2372,32 +3481,68 @@ func (m Model) renderCommentsPane(width, height int) string {
 	}
 
 	innerW := max(1, contentW)
+	now := time.Now()
 	for i := start; i < end; i++ {
-		c := items[i]
 		prefix := "  "
 		if i == cursor {
 			prefix = "> "
 		}
+		if items[i].Header {
+			style := lipgloss.NewStyle().Width(innerW).MaxWidth(innerW).Bold(true)
+			if i == cursor {
+				style = style.Foreground(lipgloss.Color("39"))
+			}
+			bodyLines = append(bodyLines, style.Render(prefix+m.commentGroupHeading(items[i])))
+			continue
+		}
+		c := items[i].Comment
+		prefix += "  "
 		side := c.Side.String()
-		summary := strings.ReplaceAll(strings.TrimSpace(c.Body), "\n", " / ")
 		stale := m.isCommentStale(c)
 		statusMark := "✓"
 		if stale {
 			statusMark = "⚠"
 		}
-		line := fmt.Sprintf("%s%s %s:%s:%d | %s", prefix, statusMark, c.Path, side, c.Line, summary)
-		style := lipgloss.NewStyle().Width(innerW).MaxWidth(innerW)
+		if diffview.PlainMode() {
+			statusMark = "ok"
+			if stale {
+				statusMark = "!!"
+			}
+		}
+		location := fmt.Sprintf("%s:%d", side, c.Line)
+		if items[i].Pinned {
+			location = c.Path + " " + location
+		}
+		if stale {
+			location += fmt.Sprintf(" [%s]", m.commentStaleReason(c))
+		}
+		if label := comments.ResolutionLabel(c.Resolution); label != "" {
+			location += " [" + label + "]"
+		}
+		if age := commentAge(c, now); age != "" {
+			location += " (" + age + ")"
+		}
+		head := fmt.Sprintf("%s%s%s %s | ", prefix, m.selectionMark(c), statusMark, location)
+		style := lipgloss.NewStyle()
 		if i == cursor {
 			style = style.Foreground(lipgloss.Color("39")).Bold(true)
 		} else if stale {
 			style = style.Foreground(lipgloss.Color("214"))
 		}
-		bodyLines = append(bodyLines, style.Render(line))
+		if c.Author != "" {
+			head += "@" + c.Author + ": "
+		}
+		line := style.Render(head) + renderCommentSummary(c.Body, style)
+		bodyLines = append(bodyLines, lipgloss.NewStyle().Width(innerW).MaxWidth(innerW).Render(line))
 	}
+	bodyLines = append(bodyLines, m.commentDetail(innerW, height)...)
 	return paneStyle.Render(strings.Join(bodyLines, "\n"))
 }
 
 func fileStatusSymbol(status string) string {
+	if diffview.PlainMode() {
+		return plainFileStatusSymbol(status)
+	}
 	switch {
 	case strings.Contains(status, "?"):
 		return "◌"
@@ -2416,6 +3561,16 @@ func fileStatusSymbol(status string) string {
 	}
 }
 
+// plainFileStatusSymbol uses the git status letter so screen readers announce something meaningful.
+func plainFileStatusSymbol(status string) string {
+	for _, code := range []string{"?", "A", "M", "D", "R", "U"} {
+		if strings.Contains(status, code) {
+			return code
+		}
+	}
+	return "-"
+}
+
 func fileStatusSymbolStyled(status string) string {
 	return lipgloss.NewStyle().
 		Foreground(fileStatusColor(status)).
@@ -2450,7 +3605,11 @@ type fileTreeEntry struct {
 	IsDir      bool
 	FileIndex  int
 	Status     string
-	HasComment bool
+	OrigPath   string
+	// Comments counts the comments on the file.
+	Comments int
+	// IndexFlags names skip-worktree and assume-unchanged flags.
+	IndexFlags string
 }
 
 type fileTreeDir struct {
@@ -2463,15 +3622,22 @@ type fileTreeDir struct {
 type fileTreeFile struct {
 	Name       string
 	Path       string
+	OrigPath   string
 	FileIndex  int
 	Status     string
-	HasComment bool
+	Comments   int
+	IndexFlags string
+	// Size is the changed line count and MTime the modification time, for
+	// sorting.
+	Size  int
+	MTime time.Time
 }
 
-func (m Model) commentedPaths() map[string]bool {
-	out := make(map[string]bool, len(m.comments))
+// commentedPaths counts the comments on each path.
+func (m Model) commentedPaths() map[string]int {
+	out := make(map[string]int, len(m.comments))
 	for _, c := range m.comments {
-		out[c.Path] = true
+		out[c.Path]++
 	}
 	return out
 }
@@ -2484,7 +3650,10 @@ func (m Model) fileTreeEntries() []fileTreeEntry {
 	}
 	commented := m.commentedPaths()
 	for i, item := range m.fileItems {
-		parts := strings.Split(strings.TrimSuffix(item.Path, "/"), "/")
+		if m.hideIndexFlagged && item.IndexFlags() != "" {
+			continue
+		}
+		parts := strings.Split(strings.TrimSuffix(filepath.ToSlash(item.Path), "/"), "/")
 		if len(parts) == 0 || parts[0] == "" {
 			continue
 		}
@@ -2510,44 +3679,67 @@ func (m Model) fileTreeEntries() []fileTreeEntry {
 		node.Files = append(node.Files, fileTreeFile{
 			Name:       name,
 			Path:       item.Path,
+			OrigPath:   item.OrigPath,
 			FileIndex:  i,
 			Status:     item.Status,
-			HasComment: commented[item.Path],
+			Comments:   commented[item.Path],
+			IndexFlags: item.IndexFlags(),
+			Size:       m.fileStats[item.Path].Added + m.fileStats[item.Path].Deleted,
+			MTime:      m.fileMTimes[item.Path],
 		})
 	}
 
 	out := make([]fileTreeEntry, 0, len(m.fileItems)*2)
-	flattenTreeEntries(root, 0, m.treeCollapsed, &out, "")
+	flattenTreeEntries(root, 0, m.treeCollapsed, m.fileSort, &out, "")
 	return out
 }
 
-func flattenTreeEntries(node *fileTreeDir, depth int, collapsed map[string]bool, out *[]fileTreeEntry, parentVisiblePath string) {
+// flattenTreeEntries lists node's directories and files depth first. By path,
+// directories come before files; other sort modes interleave them, placing a
+// directory by the first of its files.
+func flattenTreeEntries(node *fileTreeDir, depth int, collapsed map[string]bool, sortMode fileSortMode, out *[]fileTreeEntry, parentVisiblePath string) {
 	dirNames := make([]string, 0, len(node.Dirs))
 	for name := range node.Dirs {
 		dirNames = append(dirNames, name)
 	}
 	sort.Strings(dirNames)
-	for _, name := range dirNames {
-		child := node.Dirs[name]
-		leaf, displayName := collapseDirChain(child, collapsed)
-		*out = append(*out, fileTreeEntry{
-			Path:       leaf.Path,
-			ParentPath: parentVisiblePath,
-			Name:       displayName,
-			Depth:      depth,
-			IsDir:      true,
-			FileIndex:  -1,
-		})
-		if collapsed != nil && collapsed[leaf.Path] {
-			continue
+	first := make(map[string]fileTreeFile, len(dirNames))
+	if sortMode != fileSortByPath {
+		for _, name := range dirNames {
+			first[name], _ = sortMode.firstTreeFile(node.Dirs[name])
 		}
-		flattenTreeEntries(leaf, depth+1, collapsed, out, leaf.Path)
+		sort.SliceStable(dirNames, func(i, j int) bool {
+			return sortMode.compare(first[dirNames[i]], first[dirNames[j]]) < 0
+		})
 	}
-
 	sort.Slice(node.Files, func(i, j int) bool {
+		if c := sortMode.compare(node.Files[i], node.Files[j]); c != 0 {
+			return c < 0
+		}
 		return node.Files[i].Name < node.Files[j].Name
 	})
-	for _, f := range node.Files {
+
+	files := node.Files
+	for len(dirNames) > 0 || len(files) > 0 {
+		if len(files) == 0 || len(dirNames) > 0 && sortMode.compare(first[dirNames[0]], files[0]) <= 0 {
+			child := node.Dirs[dirNames[0]]
+			dirNames = dirNames[1:]
+			leaf, displayName := collapseDirChain(child, collapsed)
+			*out = append(*out, fileTreeEntry{
+				Path:       leaf.Path,
+				ParentPath: parentVisiblePath,
+				Name:       displayName,
+				Depth:      depth,
+				IsDir:      true,
+				FileIndex:  -1,
+			})
+			if collapsed == nil || !collapsed[leaf.Path] {
+				flattenTreeEntries(leaf, depth+1, collapsed, sortMode, out, leaf.Path)
+			}
+			continue
+		}
+		f := files[0]
+		files = files[1:]
 		*out = append(*out, fileTreeEntry{
 			Path:       f.Path,
 			ParentPath: parentVisiblePath,
@@ -2556,7 +3748,9 @@ func flattenTreeEntries(node *fileTreeDir, depth int, collapsed map[string]bool,
 			IsDir:      false,
 			FileIndex:  f.FileIndex,
 			Status:     f.Status,
-			HasComment: f.HasComment,
+			OrigPath:   f.OrigPath,
+			Comments:   f.Comments,
+			IndexFlags: f.IndexFlags,
 		})
 	}
 }
@@ -2592,20 +3786,72 @@ func (m Model) renderDiffPanes(oldWidth, newWidth, height int) string {
 	case diffPaneModeNewOnly:
 		return m.renderDiffSidePane(newWidth, height, "New", m.newView.View(), true)
 	default:
+		if m.stackedLayout() {
+			oldHeight, newHeight := m.diffSidePaneHeights(height)
+			oldPane := m.renderDiffSidePane(oldWidth, oldHeight, "Old", m.oldView.View(), true)
+			newPane := m.renderDiffSidePane(newWidth, newHeight, "New", m.newView.View(), true)
+			return lipgloss.JoinVertical(lipgloss.Left, oldPane, newPane)
+		}
 		oldPane := m.renderDiffSidePane(oldWidth, height, "Old", m.oldView.View(), false)
 		newPane := m.renderDiffSidePane(newWidth, height, "New", m.newView.View(), true)
 		return lipgloss.JoinHorizontal(lipgloss.Top, oldPane, newPane)
 	}
 }
 
+// stackedLayout reports whether split diffs show the old pane above the new one.
+func (m Model) stackedLayout() bool {
+	switch m.diffLayout {
+	case diffLayoutStacked:
+		return true
+	case diffLayoutAuto:
+		return m.width > 0 && m.width < stackedAutoWidth
+	default:
+		return false
+	}
+}
+
+// sideBySide reports whether two diff panes share the row, which adds a divider column.
+func (m Model) sideBySide() bool {
+	return m.diffPaneMode() == diffPaneModeSplit && !m.stackedLayout()
+}
+
+// diffSidePaneHeights returns content heights for the old and new panes
+// given the content height of the whole diff area.
+func (m Model) diffSidePaneHeights(total int) (int, int) {
+	if m.diffPaneMode() != diffPaneModeSplit || !m.stackedLayout() {
+		return total, total
+	}
+	// Stacking adds a second box, whose borders take two of the rows.
+	return splitPanes(max(2, total-2), m.splitPercent)
+}
+
+func (m *Model) toggleDiffLayout() {
+	if m.stackedLayout() {
+		m.diffLayout = diffLayoutSideBySide
+	} else {
+		m.diffLayout = diffLayoutStacked
+	}
+	m.diffDirty = true
+	m.resizePanes()
+}
+
 func (m Model) diffPaneMode() diffPaneMode {
+	mode := m.naturalDiffPaneMode()
+	if mode == diffPaneModeSplit && m.zoomSide != diffPaneModeSplit {
+		return m.zoomSide
+	}
+	return mode
+}
+
+// naturalDiffPaneMode reports which sides the current diff has content on, ignoring zoom.
+func (m Model) naturalDiffPaneMode() diffPaneMode {
 	hasOld := false
 	hasNew := false
 	for _, row := range m.diffRows {
-		if row.OldLine != nil {
+		if row.OldLine > 0 {
 			hasOld = true
 		}
-		if row.NewLine != nil {
+		if row.NewLine > 0 {
 			hasNew = true
 		}
 		if hasOld && hasNew {
@@ -2629,12 +3875,32 @@ func (m Model) diffSidePaneWidths(totalRight int) (int, int) {
 	case diffPaneModeNewOnly:
 		return 0, totalRight
 	default:
-		return splitRightPanes(totalRight)
+		if m.stackedLayout() {
+			return totalRight, totalRight
+		}
+		return splitPanes(totalRight, m.splitPercent)
 	}
 }
 
 func (m Model) renderDiffSidePane(width, height int, sideLabel, body string, withRightBorder bool) string {
-	border := lipgloss.NormalBorder()
+	title := sideLabel
+	if m.selectedF != "" {
+		title = sideLabel + ": " + m.selectedF
+	}
+	if m.loadingDiff {
+		title += m.loadingSuffix(m.diffLoadStart, true)
+	} else if m.diffStream != nil {
+		title += fmt.Sprintf(" (parsing, %d rows so far)", len(m.diffRows))
+	} else if done, total, ok := m.fileReviewProgress(m.selectedF); ok && total > 0 && len(m.diffRows) > 0 {
+		title += fmt.Sprintf(" (%d%% reviewed)", reviewPercent(done, total))
+	}
+
+	return m.renderDiffBox(width, height, title, body, withRightBorder)
+}
+
+// renderDiffBox draws a pane of the diff area with a bold title line.
+func (m Model) renderDiffBox(width, height int, title, body string, withRightBorder bool) string {
+	border := diffview.Border(lipgloss.NormalBorder())
 	borderColor := lipgloss.Color("245")
 	if m.focus == focusDiff {
 		borderColor = lipgloss.Color("39")
@@ -2646,15 +3912,6 @@ func (m Model) renderDiffSidePane(width, height int, sideLabel, body string, wit
 		Border(border, true, withRightBorder, true, true).
 		BorderForeground(borderColor)
 
-	title := sideLabel
-	if m.selectedF != "" {
-		title = sideLabel + ": " + m.selectedF
-	}
-	title += fmt.Sprintf(" [%s]", m.diffModeLabel())
-	if m.loadingDiff {
-		title += " (loading...)"
-	}
-
 	innerW := max(1, width)
 	header := lipgloss.NewStyle().Bold(true).Width(innerW).MaxWidth(innerW).Render(title)
 
@@ -2672,18 +3929,19 @@ func (m Model) diffModeLabel() string {
 }
 
 func (m *Model) resizePanes() {
-	_, rightW := paneWidths(m.width, m.filePaneW, m.fileHidden, m.diffPaneMode() == diffPaneModeSplit)
-	oldPaneW, newPaneW := m.diffSidePaneWidths(rightW)
+	_, rightW := paneWidths(m.width, m.filePaneW, m.filePaneHidden(), m.sideBySide())
+	oldPaneW, newPaneW := m.diffSidePaneWidths(max(1, rightW-m.minimapReserve()))
 	if oldPaneW <= 0 {
 		oldPaneW = 1
 	}
 	if newPaneW <= 0 {
 		newPaneW = 1
 	}
+	oldPaneH, newPaneH := m.diffSidePaneHeights(m.height - 2)
 	m.oldView.Width = oldPaneW
 	m.newView.Width = newPaneW
-	m.oldView.Height = max(1, m.height-6)
-	m.newView.Height = max(1, m.height-6)
+	m.oldView.Height = max(1, oldPaneH-4)
+	m.newView.Height = max(1, newPaneH-4)
 	m.diffDirty = true
 }
 
@@ -2691,18 +3949,20 @@ func (m Model) loadFilesCmd() tea.Cmd {
 	if m.reviewMode == reviewModePR && m.prCtx != nil {
 		pr := *m.prCtx
 		service := m.prSvc
-		return func() tea.Msg {
+		return m.deltaFilterCmd(func() filesLoadedMsg {
 			items, err := service.ListFiles(context.Background(), pr)
 			return filesLoadedMsg{items: items, err: err}
-		}
+		})
 	}
 
+	// Line counts load alongside git status, so the file tree shows before
+	// they are in.
 	cwd := m.cwd
 	service := m.statusSvc
-	return func() tea.Msg {
+	return tea.Batch(m.deltaFilterCmd(func() filesLoadedMsg {
 		items, err := service.ListChangedFiles(context.Background(), cwd)
 		return filesLoadedMsg{items: items, err: err}
-	}
+	}), m.loadFileStatsCmd())
 }
 
 func (m Model) loadPRsCmd() tea.Cmd {
@@ -2728,7 +3988,11 @@ func (m Model) loadCommentStaleCmd(items []gitint.FileItem, commentMap map[strin
 	itemSnapshot := append([]gitint.FileItem(nil), items...)
 	commentSnapshot := make([]comments.Comment, 0, len(commentMap))
 	for _, c := range commentMap {
-		commentSnapshot = append(commentSnapshot, c)
+		// The files outside the scope are not listed, which would make
+		// their comments look stale.
+		if m.scope.Contains(c.Path) {
+			commentSnapshot = append(commentSnapshot, c)
+		}
 	}
 
 	if m.reviewMode == reviewModePR && m.prCtx != nil {
@@ -2739,7 +4003,7 @@ func (m Model) loadCommentStaleCmd(items []gitint.FileItem, commentMap map[strin
 			cache[k] = v
 		}
 		return func() tea.Msg {
-			stale, err := buildCommentStaleMapFromRowsLoader(itemSnapshot, commentSnapshot, func(path string) ([]diffview.DiffRow, bool, error) {
+			reasons, err := buildCommentStaleReasonsFromRowsLoader(itemSnapshot, commentSnapshot, func(path string) ([]diffview.DiffRow, bool, error) {
 				if cached, ok := cache[path]; ok {
 					return append([]diffview.DiffRow(nil), cached.rows...), cached.empty, nil
 				}
@@ -2758,15 +4022,15 @@ func (m Model) loadCommentStaleCmd(items []gitint.FileItem, commentMap map[strin
 				}
 				return rows, false, nil
 			})
-			return commentStaleLoadedMsg{stale: stale, err: err}
+			return commentStaleLoadedMsg{stale: staleMapFromReasons(reasons), reasons: reasons, err: err}
 		}
 	}
 
 	cwd := m.cwd
 	service := m.diffSvc
 	return func() tea.Msg {
-		stale, err := buildCommentStaleMap(context.Background(), cwd, service, itemSnapshot, commentSnapshot, mode)
-		return commentStaleLoadedMsg{stale: stale, err: err}
+		reasons, err := buildCommentStaleReasons(context.Background(), cwd, service, itemSnapshot, commentSnapshot, mode)
+		return commentStaleLoadedMsg{stale: staleMapFromReasons(reasons), reasons: reasons, err: err}
 	}
 }
 
@@ -2774,14 +4038,19 @@ func (m Model) loadDiffCmd(path string) tea.Cmd {
 	if m.reviewMode == reviewModePR && m.prCtx != nil {
 		if cached, ok := m.prDiffs[path]; ok {
 			rows := append([]diffview.DiffRow(nil), cached.rows...)
-			return func() tea.Msg {
+			_, gen, done := m.diffLoads.begin()
+			return diffLoadCmd(gen, done, func() diffLoadedMsg {
 				return diffLoadedMsg{path: path, rows: rows, empty: cached.empty}
-			}
+			})
 		}
 		pr := *m.prCtx
 		service := m.prSvc
-		return func() tea.Msg {
-			d, err := service.Diff(context.Background(), pr, path)
+		ctx, gen, done := m.diffLoads.begin()
+		return diffLoadCmd(gen, done, func() diffLoadedMsg {
+			d, err := service.Diff(ctx, pr, path)
+			if ctx.Err() != nil {
+				return diffLoadedMsg{path: path, canceled: true}
+			}
 			if err != nil {
 				return diffLoadedMsg{path: path, err: err}
 			}
@@ -2793,36 +4062,70 @@ func (m Model) loadDiffCmd(path string) tea.Cmd {
 			if err != nil {
 				return diffLoadedMsg{path: path, err: err}
 			}
+			if lfs, ok := lfsRows(path, rows); ok {
+				rows = lfs
+			}
 			return diffLoadedMsg{path: path, rows: rows}
-		}
+		})
 	}
 
 	cwd := m.cwd
 	service := m.diffSvc
 	mode := m.diffMode
-	return func() tea.Msg {
-		d, err := service.Diff(context.Background(), cwd, path, mode)
+	// Git reports files missing outside the sparse checkout as deleted, so
+	// only their index can be compared.
+	outsideSparse := m.missingOutsideSparse(path)
+	if outsideSparse {
+		mode = gitint.DiffModeStaged
+	}
+	origPath := renamedFrom(m.staleCheckItems())[path]
+	// Review-snapshot filtering needs every hunk of the file at once.
+	stream := m.deltaSnapshot() == nil
+	ctx, gen, done := m.diffLoads.begin()
+	return diffLoadCmd(gen, done, func() diffLoadedMsg {
+		rows, rest, empty, err := startLocalDiff(ctx, service, cwd, path, origPath, mode, stream)
+		if ctx.Err() != nil {
+			return diffLoadedMsg{path: path, canceled: true}
+		}
 		if err != nil {
 			return diffLoadedMsg{path: path, err: err}
 		}
-		if strings.TrimSpace(d) == "" {
-			return diffLoadedMsg{path: path, empty: true}
+		if empty && outsideSparse {
+			rows, empty = sparseNoticeRows(path), false
 		}
+		return diffLoadedMsg{path: path, rows: rows, empty: empty, stream: rest}
+	})
+}
 
-		rows, err := diffview.ParseUnifiedDiff([]byte(d))
-		if err != nil {
-			return diffLoadedMsg{path: path, err: err}
-		}
-		return diffLoadedMsg{path: path, rows: rows}
+// diffLoadCmd runs load off the UI thread and stamps its result with the
+// generation of the load, then releases it with done.
+func diffLoadCmd(gen int, done func(), load func() diffLoadedMsg) tea.Cmd {
+	return func() tea.Msg {
+		defer done()
+		msg := load()
+		msg.gen = gen
+		return msg
 	}
 }
 
 func (m Model) exportCommentsCmd() tea.Cmd {
-	snapshot := m.exportableComments()
+	snapshot, scope := m.exportScope()
+	okMsg := ""
+	if scope != "" {
+		okMsg = fmt.Sprintf("Copied %d %s comment(s) to clipboard.", len(snapshot), scope)
+	}
+	links := m.permalinkSettings()
+	summary := m.exportSummary()
+	notice := m.tmuxNotice(okMsg)
+	opts := m.exportOptions()
+	frame, root, branch := m.exportFrame, m.cwd, m.exportFrameBranch()
 	return func() tea.Msg {
-		text := comments.ExportPlain(snapshot, "Review comments:")
-		err := clipboard.CopyText(context.Background(), text)
-		return clipboardResultMsg{err: err}
+		ctx := context.Background()
+		opts.Link = links.linker(ctx)
+		frame := frame.fill(ctx, root, branch)
+		text := frame.withFooter(withExportSummary(comments.ExportPlainWithOptions(snapshot, frame.title, opts), summary))
+		err := copyExport(context.Background(), text, notice)
+		return clipboardResultMsg{okMsg: okMsg, exported: snapshot, err: err}
 	}
 }
 
@@ -2837,7 +4140,7 @@ func (m Model) submitReviewCmd(draft []comments.Comment, body string, event revi
 			return submitReviewResultMsg{err: fmt.Errorf("missing PR context")}
 		}
 		err := service.SubmitReviewComments(context.Background(), *pr, bodySnapshot, eventSnapshot, snapshot)
-		return submitReviewResultMsg{submitted: snapshot, err: err}
+		return submitReviewResultMsg{submitted: snapshot, event: eventSnapshot, err: err}
 	}
 }
 
@@ -2927,6 +4230,7 @@ func (m *Model) pageDiff(direction int) {
 	m.refreshDiffContent()
 	m.oldView.SetYOffset(targetTop)
 	m.newView.SetYOffset(targetTop)
+	m.restyleVisibleDiff()
 }
 
 func (m *Model) scrollDiffWindow(delta int) {
@@ -2979,6 +4283,7 @@ func (m *Model) scrollDiffWindow(delta int) {
 	m.refreshDiffContent()
 	m.oldView.SetYOffset(newTop)
 	m.newView.SetYOffset(newTop)
+	m.restyleVisibleDiff()
 }
 
 func (m *Model) toggleFilePaneWidth() {
@@ -2989,6 +4294,37 @@ func (m *Model) toggleFilePaneWidth() {
 	}
 	m.diffDirty = true
 	m.resizePanes()
+	m.saveSessionState()
+}
+
+func (m *Model) resizeFilePane(delta int) {
+	m.fileHidden = false
+	m.filePaneW += delta
+	if m.filePaneW < filePaneWidthMin {
+		m.filePaneW = filePaneWidthMin
+	}
+	if m.filePaneW > filePaneWidthMax {
+		m.filePaneW = filePaneWidthMax
+	}
+	m.diffDirty = true
+	m.resizePanes()
+	m.saveSessionState()
+}
+
+func (m *Model) rebalanceSplit(delta int) {
+	if m.splitPercent <= 0 {
+		m.splitPercent = splitPercentDefault
+	}
+	m.splitPercent += delta
+	if m.splitPercent < splitPercentMin {
+		m.splitPercent = splitPercentMin
+	}
+	if m.splitPercent > splitPercentMax {
+		m.splitPercent = splitPercentMax
+	}
+	m.diffDirty = true
+	m.resizePanes()
+	m.saveSessionState()
 }
 
 func (m *Model) toggleFilePaneHidden() {
@@ -2998,10 +4334,31 @@ func (m *Model) toggleFilePaneHidden() {
 }
 
 func (m *Model) ensureFilePaneVisible() {
-	if !m.fileHidden {
+	if !m.fileHidden && m.zoomSide == diffPaneModeSplit {
 		return
 	}
 	m.fileHidden = false
+	m.zoomSide = diffPaneModeSplit
+	m.diffDirty = true
+	m.resizePanes()
+}
+
+// filePaneHidden reports whether the file pane is collapsed, either explicitly or by a zoomed diff side.
+func (m Model) filePaneHidden() bool {
+	return m.fileHidden || m.zoomSide != diffPaneModeSplit
+}
+
+// toggleZoom maximizes one side of a split diff, or restores the split when that side is already zoomed.
+func (m *Model) toggleZoom(side diffPaneMode) {
+	if m.zoomSide == side {
+		m.zoomSide = diffPaneModeSplit
+	} else {
+		if m.naturalDiffPaneMode() != diffPaneModeSplit {
+			m.setAlert("This diff only has one side; nothing to zoom.")
+			return
+		}
+		m.zoomSide = side
+	}
 	m.diffDirty = true
 	m.resizePanes()
 }
@@ -3015,6 +4372,7 @@ func (m *Model) advanceDiffMode() {
 	default:
 		m.diffMode = gitint.DiffModeAll
 	}
+	m.fileStats = nil
 	m.diffDirty = true
 }
 
@@ -3035,12 +4393,18 @@ func (m *Model) refreshDiffContent() {
 		renderNewW = max(1, m.oldView.Width)
 	}
 
-	if !m.diffDirty && m.oldWidth == renderOldW && m.newWidth == renderNewW {
-		m.ensureCursorVisible()
-		return
+	if m.diffDirty || m.oldWidth != renderOldW || m.newWidth != renderNewW {
+		m.renderDiff(renderOldW, renderNewW)
 	}
+	m.ensureCursorVisible()
+	m.restyleVisibleDiff()
+}
 
-	rendered := diffview.RenderSplitWithLayoutComments(
+func (m *Model) renderDiff(renderOldW, renderNewW int) {
+	start := time.Now()
+	opts := m.renderOptions()
+	opts.StyleFrom, opts.StyleTo = m.styleWindow()
+	rendered := diffview.RenderSplitWithOptions(
 		m.diffRows,
 		renderOldW,
 		renderNewW,
@@ -3051,6 +4415,7 @@ func (m *Model) refreshDiffContent() {
 		func(path string, line int, side diffview.Side) (string, bool) {
 			return m.commentText(path, line, side)
 		},
+		opts,
 	)
 	m.oldView.SetContent(strings.Join(rendered.OldLines, "\n"))
 	m.newView.SetContent(strings.Join(rendered.NewLines, "\n"))
@@ -3058,8 +4423,37 @@ func (m *Model) refreshDiffContent() {
 	m.rowHeights = rendered.RowHeights
 	m.oldWidth = renderOldW
 	m.newWidth = renderNewW
+	m.styledFrom, m.styledTo = opts.StyleFrom, opts.StyleTo
+	m.diffUnstyled = rendered.Unstyled
 	m.diffDirty = false
-	m.ensureCursorVisible()
+	m.perf.recordRender(time.Since(start), len(m.diffRows), len(rendered.NewLines))
+	debuglog.Timed(start, "render diff %s: %d rows at %d+%d columns", m.selectedF, len(m.diffRows), renderOldW, renderNewW)
+}
+
+// styleWindow is the range of visual lines long wrapped rows are styled on:
+// the current page and one page on either side.
+func (m Model) styleWindow() (int, int) {
+	page := min(m.oldView.Height, m.newView.Height)
+	if page <= 0 {
+		return 0, 0
+	}
+	return max(0, m.oldView.YOffset-page), m.oldView.YOffset + 2*page
+}
+
+// restyleVisibleDiff renders the diff again when the viewport has scrolled
+// onto lines of a long wrapped row that were left unstyled.
+func (m *Model) restyleVisibleDiff() {
+	if !m.diffUnstyled {
+		return
+	}
+	page := min(m.oldView.Height, m.newView.Height)
+	top := m.oldView.YOffset
+	if top >= m.styledFrom && top+page <= m.styledTo {
+		return
+	}
+	m.renderDiff(m.oldWidth, m.newWidth)
+	m.oldView.SetYOffset(top)
+	m.newView.SetYOffset(top)
 }
 
 func (m *Model) ensureCursorVisible() {
@@ -3131,6 +4525,7 @@ func (m *Model) scrollCursorWithPadding(padding int) {
 	}
 	m.oldView.SetYOffset(newTop)
 	m.newView.SetYOffset(newTop)
+	m.restyleVisibleDiff()
 }
 
 func (m *Model) cursorVisualRange() (int, int) {
@@ -3225,11 +4620,11 @@ func (m *Model) currentAnchor() (commentAnchor, bool) {
 func (m *Model) commentRowIndices() []int {
 	rows := make([]int, 0)
 	for i, row := range m.diffRows {
-		if row.OldLine != nil && m.hasComment(row.Path, *row.OldLine, diffview.SideOld) {
+		if row.OldLine > 0 && m.hasComment(row.Path, row.OldLine, diffview.SideOld) {
 			rows = append(rows, i)
 			continue
 		}
-		if row.NewLine != nil && m.hasComment(row.Path, *row.NewLine, diffview.SideNew) {
+		if row.NewLine > 0 && m.hasComment(row.Path, row.NewLine, diffview.SideNew) {
 			rows = append(rows, i)
 		}
 	}
@@ -3237,7 +4632,16 @@ func (m *Model) commentRowIndices() []int {
 }
 
 func (m *Model) persistComments() error {
-	return m.commentStore.Save(m.sortedComments())
+	// Save anyway when the other write cannot be read: the store keeps an
+	// unreadable file aside rather than overwriting it.
+	if err := m.mergeExternalComments(); err != nil {
+		m.setAlert(err.Error())
+	}
+	if err := m.commentStore.Save(m.sortedComments()); err != nil {
+		return err
+	}
+	m.stampComments()
+	return nil
 }
 
 func (m Model) sortedComments() []comments.Comment {
@@ -3294,54 +4698,50 @@ func (m Model) staleAllComments() map[string]bool {
 	return out
 }
 
+// contextAround captures m.contextLines lines on each side of the anchor. The
+// anchored line itself is the first entry of the "after" slice.
 func (m *Model) contextAround(anchor commentAnchor) ([]string, []string) {
-	target := m.sideText(m.diffRows[anchor.RowIdx], anchor.Side)
-	before := ""
-	after := ""
+	n := max(0, m.contextLines)
 
-	for i := anchor.RowIdx - 1; i >= 0; i-- {
-		if m.diffRows[i].Path != anchor.Path {
+	contextBefore := make([]string, 0, n)
+	for i := anchor.RowIdx - 1; i >= 0 && len(contextBefore) < n; i-- {
+		row := m.diffRows[i]
+		if row.Path != anchor.Path || !sideHasLine(row, anchor.Side) {
 			continue
 		}
-		text := m.sideText(m.diffRows[i], anchor.Side)
-		if text != "" {
-			before = text
-			break
-		}
+		contextBefore = append(contextBefore, m.sideText(row, anchor.Side))
+	}
+	for i, j := 0, len(contextBefore)-1; i < j; i, j = i+1, j-1 {
+		contextBefore[i], contextBefore[j] = contextBefore[j], contextBefore[i]
 	}
 
-	for i := anchor.RowIdx + 1; i < len(m.diffRows); i++ {
-		if m.diffRows[i].Path != anchor.Path {
+	contextAfter := make([]string, 0, n+1)
+	if sideHasLine(m.diffRows[anchor.RowIdx], anchor.Side) {
+		contextAfter = append(contextAfter, m.sideText(m.diffRows[anchor.RowIdx], anchor.Side))
+	}
+	for i := anchor.RowIdx + 1; i < len(m.diffRows) && len(contextAfter) < n+1; i++ {
+		row := m.diffRows[i]
+		if row.Path != anchor.Path || !sideHasLine(row, anchor.Side) {
 			continue
 		}
-		text := m.sideText(m.diffRows[i], anchor.Side)
-		if text != "" {
-			after = text
-			break
-		}
+		contextAfter = append(contextAfter, m.sideText(row, anchor.Side))
 	}
 
-	contextBefore := make([]string, 0, 1)
-	if before != "" {
-		contextBefore = append(contextBefore, before)
-	}
+	return contextBefore, contextAfter
+}
 
-	contextAfter := make([]string, 0, 2)
-	if target != "" {
-		contextAfter = append(contextAfter, target)
-	}
-	if after != "" {
-		contextAfter = append(contextAfter, after)
+func sideHasLine(row diffview.DiffRow, side comments.Side) bool {
+	if side == comments.SideOld {
+		return row.OldLine > 0
 	}
-
-	return contextBefore, contextAfter
+	return row.NewLine > 0
 }
 
 func (m *Model) sideText(row diffview.DiffRow, side comments.Side) string {
-	if side == comments.SideOld && row.OldLine != nil {
+	if side == comments.SideOld && row.OldLine > 0 {
 		return row.OldText
 	}
-	if side == comments.SideNew && row.NewLine != nil {
+	if side == comments.SideNew && row.NewLine > 0 {
 		return row.NewText
 	}
 	return ""
@@ -3366,19 +4766,19 @@ func (m *Model) hunkHeaderForRow(rowIdx int, path string) string {
 func pickAnchor(row diffview.DiffRow) (comments.Side, int, bool) {
 	switch row.Kind {
 	case diffview.RowDelete:
-		if row.OldLine != nil {
-			return comments.SideOld, *row.OldLine, true
+		if row.OldLine > 0 {
+			return comments.SideOld, row.OldLine, true
 		}
 	case diffview.RowAdd:
-		if row.NewLine != nil {
-			return comments.SideNew, *row.NewLine, true
+		if row.NewLine > 0 {
+			return comments.SideNew, row.NewLine, true
 		}
 	default:
-		if row.NewLine != nil {
-			return comments.SideNew, *row.NewLine, true
+		if row.NewLine > 0 {
+			return comments.SideNew, row.NewLine, true
 		}
-		if row.OldLine != nil {
-			return comments.SideOld, *row.OldLine, true
+		if row.OldLine > 0 {
+			return comments.SideOld, row.OldLine, true
 		}
 	}
 	return comments.SideNew, 0, false
@@ -3397,6 +4797,13 @@ func firstRenderableRow(rows []diffview.DiffRow) int {
 }
 
 func overlayCentered(base, overlay string, width, height int) string {
+	x := max(0, (width-lipgloss.Width(overlay))/2)
+	y := max(0, (height-lipgloss.Height(overlay))/2)
+	return overlayAt(base, overlay, x, y, width, height)
+}
+
+// overlayAt draws overlay over base with its top-left corner at column x, row y.
+func overlayAt(base, overlay string, x, y, width, height int) string {
 	baseLines := normalizeCanvas(base, width, height)
 	overlayLines := strings.Split(overlay, "\n")
 	overlayW := lipgloss.Width(overlay)
@@ -3405,8 +4812,6 @@ func overlayCentered(base, overlay string, width, height int) string {
 		return strings.Join(baseLines, "\n")
 	}
 
-	x := max(0, (width-overlayW)/2)
-	y := max(0, (height-overlayH)/2)
 	for i, ol := range overlayLines {
 		row := y + i
 		if row < 0 || row >= len(baseLines) {
@@ -3483,8 +4888,10 @@ func alertTickCmd() tea.Cmd {
 }
 
 func (m *Model) setAlert(msg string) {
+	debuglog.Printf("alert: %s", msg)
 	m.alertMsg = msg
 	m.alertUntil = time.Now().Add(3 * time.Second)
+	m.appendAlertLog(msg)
 }
 
 func truncateLinesToWidth(text string, width int) string {
@@ -3509,25 +4916,26 @@ func lineCount(text string) int {
 	return strings.Count(text, "\n") + 1
 }
 
-func buildCommentStaleMap(
+func buildCommentStaleReasons(
 	ctx context.Context,
 	cwd string,
 	diffSvc gitint.DiffService,
 	items []gitint.FileItem,
 	allComments []comments.Comment,
 	mode gitint.DiffMode,
-) (map[string]bool, error) {
-	return buildCommentStaleMapFromLoader(items, allComments, func(path string) (string, error) {
-		return diffSvc.Diff(ctx, cwd, path, mode)
+) (map[string]staleReason, error) {
+	origPaths := renamedFrom(items)
+	return buildCommentStaleReasonsFromRowsLoader(items, allComments, func(path string) ([]diffview.DiffRow, bool, error) {
+		return localDiffRows(ctx, diffSvc, cwd, path, origPaths[path], mode)
 	})
 }
 
-func buildCommentStaleMapFromRowsLoader(
+func buildCommentStaleReasonsFromRowsLoader(
 	items []gitint.FileItem,
 	allComments []comments.Comment,
 	loadRows func(path string) ([]diffview.DiffRow, bool, error),
-) (map[string]bool, error) {
-	stale := make(map[string]bool, len(allComments))
+) (map[string]staleReason, error) {
+	stale := make(map[string]staleReason, len(allComments))
 	if len(allComments) == 0 {
 		return stale, nil
 	}
@@ -3541,7 +4949,7 @@ func buildCommentStaleMapFromRowsLoader(
 	for _, c := range allComments {
 		k := commentKey(c)
 		if !fileSet[c.Path] {
-			stale[k] = true
+			stale[k] = staleReasonFileUnchanged
 			continue
 		}
 		byPath[c.Path] = append(byPath[c.Path], c)
@@ -3555,13 +4963,13 @@ func buildCommentStaleMapFromRowsLoader(
 				firstErr = err
 			}
 			for _, c := range group {
-				stale[commentKey(c)] = true
+				stale[commentKey(c)] = staleReasonDiffFailed
 			}
 			continue
 		}
 		if empty || len(rows) == 0 {
 			for _, c := range group {
-				stale[commentKey(c)] = true
+				stale[commentKey(c)] = staleReasonFileUnchanged
 			}
 			continue
 		}
@@ -3569,44 +4977,25 @@ func buildCommentStaleMapFromRowsLoader(
 		oldLines := make(map[int]bool)
 		newLines := make(map[int]bool)
 		for _, r := range rows {
-			if r.OldLine != nil {
-				oldLines[*r.OldLine] = true
+			if r.OldLine > 0 {
+				oldLines[r.OldLine] = true
 			}
-			if r.NewLine != nil {
-				newLines[*r.NewLine] = true
+			if r.NewLine > 0 {
+				newLines[r.NewLine] = true
 			}
 		}
 		for _, c := range group {
-			k := commentKey(c)
-			switch c.Side {
-			case comments.SideOld:
-				stale[k] = !oldLines[c.Line]
-			default:
-				stale[k] = !newLines[c.Line]
+			lines := newLines
+			if c.Side == comments.SideOld {
+				lines = oldLines
+			}
+			if lines[c.Line] {
+				stale[commentKey(c)] = staleReasonNone
+			} else {
+				stale[commentKey(c)] = staleReasonLineGone
 			}
 		}
 	}
 
 	return stale, firstErr
 }
-
-func buildCommentStaleMapFromLoader(
-	items []gitint.FileItem,
-	allComments []comments.Comment,
-	loadDiff func(path string) (string, error),
-) (map[string]bool, error) {
-	return buildCommentStaleMapFromRowsLoader(items, allComments, func(path string) ([]diffview.DiffRow, bool, error) {
-		d, err := loadDiff(path)
-		if err != nil {
-			return nil, false, err
-		}
-		if strings.TrimSpace(d) == "" {
-			return nil, true, nil
-		}
-		rows, err := diffview.ParseUnifiedDiff([]byte(d))
-		if err != nil {
-			return nil, false, err
-		}
-		return rows, false, nil
-	})
-}
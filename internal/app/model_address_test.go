@@ -0,0 +1,84 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"diffman/internal/comments"
+	gitint "diffman/internal/git"
+)
+
+func TestAddressModeResolvesCommentsInTurn(t *testing.T) {
+	store := comments.NewStore(t.TempDir())
+	mine := comments.Comment{ID: "c1", Path: "a.go", Side: comments.SideNew, Line: 2, Body: "rename"}
+	theirs := comments.Comment{ID: "c2", Path: "a.go", Side: comments.SideNew, Line: 5, Body: "add a test", Author: "octocat"}
+	done := comments.Comment{ID: "c3", Path: "a.go", Side: comments.SideNew, Line: 1, Body: "old", Resolution: comments.ResolutionAddressed}
+	m := Model{
+		keys:         defaultKeyMap(),
+		commentStore: store,
+		focus:        focusFiles,
+		fileItems:    []gitint.FileItem{{Path: "a.go"}},
+		comments:     map[string]comments.Comment{commentKey(mine): mine, commentKey(theirs): theirs, commentKey(done): done},
+	}
+
+	m = pressKeys(t, m, "F")
+	if m.address == nil || len(m.address.keys) != 2 {
+		t.Fatalf("expected address mode over the two open comments, got %+v", m.address)
+	}
+	if m.focus != focusDiff || m.pendingCommentJump == nil || m.pendingCommentJump.Line != 2 {
+		t.Fatalf("expected the diff opened at a.go:2, got %+v", m.pendingCommentJump)
+	}
+
+	m = pressKeys(t, m, "a", "done", "enter")
+	if got := m.comments[commentKey(mine)]; got.Resolution != comments.ResolutionAddressed || got.Reply != "done" {
+		t.Fatalf("expected a.go:2 addressed with a reply, got %+v", got)
+	}
+	if m.address == nil || m.pendingCommentJump == nil || m.pendingCommentJump.Line != 5 {
+		t.Fatalf("expected the next open comment opened, got %+v", m.pendingCommentJump)
+	}
+
+	m = pressKeys(t, m, "x", "enter")
+	if got := m.comments[commentKey(theirs)]; got.Resolution != comments.ResolutionWontFix || got.Reply != "" {
+		t.Fatalf("expected a.go:5 marked won't fix, got %+v", got)
+	}
+	if m.address != nil || m.alertMsg != "Resolved 2 of 2 comment(s)." {
+		t.Fatalf("expected address mode to end once all are resolved, got %q", m.alertMsg)
+	}
+
+	saved, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	text := comments.ExportPlain(saved, "")
+	if !strings.Contains(text, "-> addressed: done") || !strings.Contains(text, "-> won't fix") {
+		t.Fatalf("expected resolutions in the export, got:\n%s", text)
+	}
+}
+
+func TestAddressModeReopensAndLeaves(t *testing.T) {
+	c := comments.Comment{ID: "c1", Path: "a.go", Side: comments.SideNew, Line: 2, Body: "rename"}
+	m := Model{
+		keys:         defaultKeyMap(),
+		commentStore: comments.NewStore(t.TempDir()),
+		fileItems:    []gitint.FileItem{{Path: "a.go"}},
+		comments:     map[string]comments.Comment{commentKey(c): c},
+	}
+	m = pressKeys(t, m, "F", "a", "esc")
+	if m.address == nil || m.address.resolving != "" || m.comments[commentKey(c)].Resolution != "" {
+		t.Fatalf("expected Esc in the reply dock to cancel without resolving")
+	}
+	m = pressKeys(t, m, "x", "enter")
+	if m.address != nil {
+		t.Fatalf("expected address mode to end after the last comment")
+	}
+
+	m = pressKeys(t, m, "F")
+	if m.address != nil || m.alertMsg != "No open comments to address." {
+		t.Fatalf("expected nothing to address, got %q", m.alertMsg)
+	}
+	m.address = &addressState{keys: []string{commentKey(c)}, input: newReplyInput()}
+	m = pressKeys(t, m, "u", "esc")
+	if m.comments[commentKey(c)].Resolution != "" || m.address != nil {
+		t.Fatalf("expected u to reopen the comment and Esc to leave")
+	}
+}
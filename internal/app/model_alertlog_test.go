@@ -0,0 +1,60 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestSetAlertKeepsBoundedHistory(t *testing.T) {
+	m := Model{}
+	for i := 0; i < alertLogLimit+5; i++ {
+		m.setAlert(fmt.Sprintf("notice %d", i))
+	}
+
+	if got := len(m.alertLog); got != alertLogLimit {
+		t.Fatalf("len(alertLog)=%d want %d", got, alertLogLimit)
+	}
+	if got, want := m.alertLog[len(m.alertLog)-1].Msg, fmt.Sprintf("notice %d", alertLogLimit+4); got != want {
+		t.Fatalf("newest entry=%q want %q", got, want)
+	}
+}
+
+func TestAlertLogKeyOpensAndClosesLog(t *testing.T) {
+	m := Model{keys: defaultKeyMap()}
+	m.setAlert("export failed: boom")
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'L'}})
+	m2 := next.(Model)
+	if !m2.alertLogOpen {
+		t.Fatalf("expected L to open the notice log")
+	}
+
+	next, _ = m2.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if next.(Model).alertLogOpen {
+		t.Fatalf("expected esc to close the notice log")
+	}
+}
+
+func TestAlertLogWrapsLongNotices(t *testing.T) {
+	m := Model{keys: defaultKeyMap(), width: 50, height: 40}
+	m.setAlert("export failed: open /home/someone/projects/widgets/review-notes.txt: permission denied")
+	view := ansi.Strip(m.renderAlertLogModal())
+	if strings.Contains(view, "…") {
+		t.Fatalf("expected the notice wrapped, not cut:\n%s", view)
+	}
+	joined := strings.Join(strings.Fields(view), " ")
+	for _, want := range []string{"export failed:", "review-notes.txt:", "permission denied"} {
+		if !strings.Contains(joined, want) {
+			t.Fatalf("expected %q in the notice log:\n%s", want, view)
+		}
+	}
+	for _, line := range strings.Split(view, "\n") {
+		if w := ansi.StringWidth(line); w > m.width {
+			t.Fatalf("line wider than the screen (%d):\n%s", w, view)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+
+	gitint "diffman/internal/git"
+)
+
+func autoRefreshModel(items []gitint.FileItem, selected string) Model {
+	m := Model{
+		keys:            defaultKeyMap(),
+		focus:           focusFiles,
+		refreshInterval: 10 * time.Second,
+		statusSvc:       stubStatusService{items: items},
+		diffSvc:         stubDiffService{},
+		fileItems:       items,
+		selectedF:       selected,
+		diffRows:        addedRows(selected, 3),
+		oldView:         viewport.New(40, 10),
+		newView:         viewport.New(40, 10),
+		width:           100,
+		height:          30,
+	}
+	m.selected = indexOfFilePath(items, selected)
+	m.syncFileCursorToSelectedPath()
+	return m
+}
+
+func TestBackgroundRefreshKeepsCursorAndOpenDiff(t *testing.T) {
+	m := autoRefreshModel([]gitint.FileItem{{Path: "b.go", Status: "M."}, {Path: "c.go", Status: "M."}}, "c.go")
+	m.diffCursor = 2
+
+	next, cmd := m.Update(autoRefreshMsg{})
+	m = next.(Model)
+	if cmd == nil || !m.autoRefreshing {
+		t.Fatalf("expected the tick to start a background refresh")
+	}
+
+	items := []gitint.FileItem{{Path: "a.go", Status: "M."}, {Path: "b.go", Status: "M."}, {Path: "c.go", Status: "M."}}
+	next, _ = m.Update(filesLoadedMsg{items: items, background: true})
+	m = next.(Model)
+	if m.autoRefreshing || len(m.fileItems) != 3 {
+		t.Fatalf("expected the new file to be merged, got %+v", m.fileItems)
+	}
+	if m.selectedF != "c.go" || m.selected != 2 {
+		t.Fatalf("selection moved: %q at %d", m.selectedF, m.selected)
+	}
+	if e := m.fileTreeEntries()[m.fileCursor]; e.Path != "c.go" {
+		t.Fatalf("file cursor moved to %q", e.Path)
+	}
+	if m.loadingDiff || m.diffCursor != 2 || len(m.diffRows) != 4 {
+		t.Fatalf("expected the open diff to be left alone")
+	}
+}
+
+func TestBackgroundRefreshReloadsWhenSelectedFileIsGone(t *testing.T) {
+	m := autoRefreshModel([]gitint.FileItem{{Path: "b.go", Status: "M."}, {Path: "c.go", Status: "M."}}, "c.go")
+	m.autoRefreshing = true
+
+	next, cmd := m.Update(filesLoadedMsg{items: []gitint.FileItem{{Path: "b.go", Status: "M."}}, background: true})
+	m = next.(Model)
+	if m.selectedF != "b.go" || cmd == nil {
+		t.Fatalf("expected the remaining file to be opened, got %q", m.selectedF)
+	}
+}
+
+func TestBackgroundRefreshWaitsForRunningLoad(t *testing.T) {
+	m := autoRefreshModel([]gitint.FileItem{{Path: "a.go", Status: "M."}}, "a.go")
+	m.loadingFiles = true
+
+	next, cmd := m.Update(autoRefreshMsg{})
+	if next.(Model).autoRefreshing || cmd == nil {
+		t.Fatalf("expected the refresh to be skipped but the timer kept")
+	}
+
+	m.loadingFiles = false
+	m.refreshInterval = 0
+	if cmd := m.autoRefreshCmd(); cmd != nil {
+		t.Fatalf("expected no timer without refresh_seconds")
+	}
+}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"diffman/internal/comments"
+	"diffman/internal/session"
+)
+
+func TestChecklistTogglesItemsAndRemembersThem(t *testing.T) {
+	store := session.NewStore(t.TempDir())
+	m := Model{
+		keys:         defaultKeyMap(),
+		comments:     map[string]comments.Comment{},
+		sessionStore: store,
+		checklist:    []string{"Tests added?", "Docs updated?", "Error handling?"},
+	}
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("T")})
+	m = next.(Model)
+	if !m.checklistOpen {
+		t.Fatalf("expected T to open the checklist")
+	}
+	for _, k := range []tea.KeyMsg{
+		{Type: tea.KeySpace},
+		{Type: tea.KeyRunes, Runes: []rune("j")},
+		{Type: tea.KeyRunes, Runes: []rune("j")},
+		{Type: tea.KeyRunes, Runes: []rune("x")},
+	} {
+		next, _ = m.Update(k)
+		m = next.(Model)
+	}
+	if got := m.checklistStatusSegment(); got != "checklist 2/3" {
+		t.Fatalf("status segment = %q", got)
+	}
+	want := "Checklist (2/3 done):\n[x] Tests added?\n[ ] Docs updated?\n[x] Error handling?"
+	if got := m.checklistText(); got != want {
+		t.Fatalf("checklistText() = %q, want %q", got, want)
+	}
+	if !strings.HasPrefix(m.exportSummary(), want) {
+		t.Fatalf("expected the checklist in the export summary, got %q", m.exportSummary())
+	}
+
+	state, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	restored := Model{checklist: m.checklist}
+	restored.applySessionState(state)
+	if restored.checklistDoneCount() != 2 || !restored.checklistDone["Error handling?"] {
+		t.Fatalf("expected ticked items restored from the session, got %v", state.Checklist)
+	}
+
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if next.(Model).checklistOpen {
+		t.Fatalf("expected Esc to close the checklist")
+	}
+}
+
+func TestChecklistKeyWithoutConfigExplainsSetup(t *testing.T) {
+	m := Model{keys: defaultKeyMap(), comments: map[string]comments.Comment{}}
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("T")})
+	m = next.(Model)
+	if m.checklistOpen || !strings.Contains(m.alertMsg, `"checklist"`) {
+		t.Fatalf("expected a setup hint, got open=%v alert=%q", m.checklistOpen, m.alertMsg)
+	}
+	if m.exportSummary() != "" {
+		t.Fatalf("expected no export summary without a checklist")
+	}
+}
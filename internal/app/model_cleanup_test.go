@@ -0,0 +1,74 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"diffman/internal/comments"
+	"diffman/internal/history"
+)
+
+func TestCleanupOfferedOnceWhenAllCommentsGoStale(t *testing.T) {
+	a := comments.Comment{Path: "a.go", Side: comments.SideNew, Line: 1, Body: "one"}
+	b := comments.Comment{Path: "b.go", Side: comments.SideNew, Line: 2, Body: "two"}
+	historyStore := history.NewStore(t.TempDir())
+	m := Model{
+		keys:               defaultKeyMap(),
+		cleanupAfterCommit: true,
+		commentStore:       comments.NewStore(t.TempDir()),
+		historyStore:       historyStore,
+		comments:           map[string]comments.Comment{commentKey(a): a, commentKey(b): b},
+	}
+
+	// One comment still anchors: no offer.
+	next, _ := m.Update(commentStaleLoadedMsg{reasons: map[string]staleReason{
+		commentKey(a): staleReasonFileUnchanged,
+		commentKey(b): staleReasonNone,
+	}, stale: map[string]bool{commentKey(a): true}})
+	m = next.(Model)
+	if m.cleanupConfirmModal {
+		t.Fatalf("expected no cleanup offer while a comment still anchors")
+	}
+
+	allStale := commentStaleLoadedMsg{reasons: map[string]staleReason{
+		commentKey(a): staleReasonFileUnchanged,
+		commentKey(b): staleReasonLineGone,
+	}, stale: map[string]bool{commentKey(a): true, commentKey(b): true}}
+	next, _ = m.Update(allStale)
+	m = next.(Model)
+	if !m.cleanupConfirmModal {
+		t.Fatalf("expected cleanup offer once every comment is stale")
+	}
+
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("k")})
+	m = next.(Model)
+	next, _ = m.Update(allStale)
+	m = next.(Model)
+	if m.cleanupConfirmModal {
+		t.Fatalf("expected a declined comment set not to be offered again")
+	}
+
+	m.cleanupOffered = ""
+	next, _ = m.Update(allStale)
+	m = next.(Model)
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(Model)
+	if len(m.comments) != 0 || !strings.HasPrefix(m.alertMsg, "Archived 2 comment(s)") {
+		t.Fatalf("expected comments archived and cleared, got %d (%q)", len(m.comments), m.alertMsg)
+	}
+	archived, err := historyStore.List()
+	if err != nil || len(archived) != 1 || archived[0].Outcome != "committed" {
+		t.Fatalf("unexpected archive %+v (err=%v)", archived, err)
+	}
+}
+
+func TestCleanupNotOfferedWhenDisabled(t *testing.T) {
+	a := comments.Comment{Path: "a.go", Side: comments.SideNew, Line: 1, Body: "one"}
+	m := Model{comments: map[string]comments.Comment{commentKey(a): a}}
+	next, _ := m.Update(commentStaleLoadedMsg{stale: map[string]bool{commentKey(a): true}})
+	if next.(Model).cleanupConfirmModal {
+		t.Fatalf("expected no offer without cleanup_after_commit")
+	}
+}
@@ -0,0 +1,83 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"diffman/internal/comments"
+	gitint "diffman/internal/git"
+	"diffman/internal/session"
+)
+
+func typeCommand(t *testing.T, m Model, line string) Model {
+	t.Helper()
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(":")})
+	m = next.(Model)
+	if !m.commandInputActive {
+		t.Fatalf("expected : to open the command line")
+	}
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(line)})
+	next, _ = next.(Model).Update(tea.KeyMsg{Type: tea.KeyEnter})
+	return next.(Model)
+}
+
+func TestCommandJumpsToCommentOfLatestExport(t *testing.T) {
+	first := comments.Comment{ID: "c1", Path: "a.go", Side: comments.SideNew, Line: 3, Body: "first"}
+	second := comments.Comment{ID: "c2", Path: "b.go", Side: comments.SideNew, Line: 2, Body: "second"}
+	store := session.NewStore(t.TempDir())
+	m := Model{
+		keys:         defaultKeyMap(),
+		sessionStore: store,
+		focus:        focusFiles,
+		fileItems:    []gitint.FileItem{{Path: "a.go"}, {Path: "b.go"}},
+		comments:     map[string]comments.Comment{commentKey(first): first, commentKey(second): second},
+		commandInput: newCommandInput(),
+	}
+	next, _ := m.Update(clipboardResultMsg{exported: []comments.Comment{second, first}})
+	m = next.(Model)
+
+	m = typeCommand(t, m, "c 2")
+	if m.commandInputActive {
+		t.Fatalf("expected the command line closed, error %q", m.commandInputErr)
+	}
+	if m.selectedF != "a.go" || m.focus != focusDiff || m.pendingCommentJump == nil || m.pendingCommentJump.Line != 3 {
+		t.Fatalf("expected a jump to a.go:3, got %q %+v", m.selectedF, m.pendingCommentJump)
+	}
+
+	state, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if strings.Join(state.LastExport, ",") != "c2,c1" {
+		t.Fatalf("expected the export numbering in the session, got %v", state.LastExport)
+	}
+}
+
+func TestCommandReportsNumbersOutsideTheExport(t *testing.T) {
+	c := comments.Comment{ID: "c1", Path: "a.go", Side: comments.SideNew, Line: 3, Body: "first"}
+	m := Model{
+		keys:         defaultKeyMap(),
+		comments:     map[string]comments.Comment{commentKey(c): c},
+		commandInput: newCommandInput(),
+	}
+	m = typeCommand(t, m, "c 1")
+	if !strings.Contains(m.commandInputErr, "no export yet") {
+		t.Fatalf("expected a no-export error, got %q", m.commandInputErr)
+	}
+	m.closeCommandInput()
+
+	m.lastExport = []string{"c1", "gone"}
+	for line, want := range map[string]string{
+		"c 3":  "has 2 comment(s)",
+		"c 2":  "no longer exists",
+		"c x":  "usage: c N",
+		"jump": "unknown command",
+	} {
+		got := typeCommand(t, m, line)
+		if !got.commandInputActive || !strings.Contains(got.commandInputErr, want) {
+			t.Fatalf(":%s: expected error containing %q, got %q", line, want, got.commandInputErr)
+		}
+	}
+}
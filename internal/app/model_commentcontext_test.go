@@ -0,0 +1,71 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"diffman/internal/comments"
+	"diffman/internal/diffview"
+)
+
+func contextTestRows() []diffview.DiffRow {
+	return []diffview.DiffRow{
+		{Kind: diffview.RowHunkHeader, Path: "a.go", OldText: "@@ -1,5 +1,5 @@"},
+		{Kind: diffview.RowContext, Path: "a.go", OldLine: 1, NewLine: 1, OldText: "one", NewText: "one"},
+		{Kind: diffview.RowContext, Path: "a.go", OldLine: 2, NewLine: 2, OldText: "", NewText: ""},
+		{Kind: diffview.RowDelete, Path: "a.go", OldLine: 3, OldText: "gone"},
+		{Kind: diffview.RowAdd, Path: "a.go", NewLine: 3, NewText: "three"},
+		{Kind: diffview.RowContext, Path: "a.go", OldLine: 4, NewLine: 4, OldText: "four", NewText: "four"},
+		{Kind: diffview.RowContext, Path: "a.go", OldLine: 5, NewLine: 5, OldText: "five", NewText: "five"},
+	}
+}
+
+func TestContextAroundCapturesConfiguredLines(t *testing.T) {
+	m := Model{diffRows: contextTestRows(), contextLines: 2}
+
+	before, after := m.contextAround(commentAnchor{Path: "a.go", Side: comments.SideNew, Line: 3, RowIdx: 4})
+	if want := []string{"one", ""}; !reflect.DeepEqual(before, want) {
+		t.Fatalf("before = %q, want %q", before, want)
+	}
+	if want := []string{"three", "four", "five"}; !reflect.DeepEqual(after, want) {
+		t.Fatalf("after = %q, want %q", after, want)
+	}
+
+	m.contextLines = 0
+	before, after = m.contextAround(commentAnchor{Path: "a.go", Side: comments.SideNew, Line: 3, RowIdx: 4})
+	if len(before) != 0 || !reflect.DeepEqual(after, []string{"three"}) {
+		t.Fatalf("expected only the anchored line, got before=%q after=%q", before, after)
+	}
+}
+
+func TestRecaptureContextUpdatesCommentsInDiff(t *testing.T) {
+	c := comments.Comment{Path: "a.go", Side: comments.SideNew, Line: 3, Body: "why", ContextAfter: []string{"old"}}
+	other := comments.Comment{Path: "b.go", Side: comments.SideNew, Line: 1, Body: "elsewhere"}
+	m := Model{
+		keys:         defaultKeyMap(),
+		focus:        focusDiff,
+		diffRows:     contextTestRows(),
+		contextLines: 1,
+		commentStore: comments.NewStore(t.TempDir()),
+		comments: map[string]comments.Comment{
+			commentKey(c):     c,
+			commentKey(other): other,
+		},
+	}
+
+	updated, _ := m.updateDiffPane(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+	m = updated.(Model)
+
+	got := m.comments[commentKey(c)]
+	if got.HunkHeader != "@@ -1,5 +1,5 @@" || !reflect.DeepEqual(got.ContextBefore, []string{""}) || !reflect.DeepEqual(got.ContextAfter, []string{"three", "four"}) {
+		t.Fatalf("unexpected recaptured comment %+v", got)
+	}
+	if m.comments[commentKey(other)].ContextAfter != nil {
+		t.Fatalf("expected comment outside the diff to be untouched")
+	}
+	if m.alertMsg != "Re-captured context for 1 comment(s)." {
+		t.Fatalf("unexpected alert %q", m.alertMsg)
+	}
+}
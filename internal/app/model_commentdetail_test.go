@@ -0,0 +1,68 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+
+	"diffman/internal/comments"
+)
+
+func TestCommentsPaneShowsSelectedCommentDetail(t *testing.T) {
+	created := time.Date(2026, 3, 4, 10, 30, 0, 0, time.Local)
+	c := comments.Comment{
+		Path:          "a.go",
+		Side:          comments.SideNew,
+		Line:          9,
+		Body:          "first paragraph\n\nsecond paragraph that is long enough to need wrapping in a narrow pane",
+		Author:        "alice",
+		CreatedAt:     created,
+		UpdatedAt:     created.Add(time.Hour),
+		HunkHeader:    "@@ -5,3 +5,4 @@",
+		ContextBefore: []string{"func a() {"},
+		ContextAfter:  []string{"x := 1", "}"},
+	}
+	key := commentKey(c)
+	m := Model{
+		width:          60,
+		height:         40,
+		comments:       map[string]comments.Comment{key: c},
+		commentStale:   map[string]bool{key: false},
+		commentsCursor: 1,
+	}
+
+	out := ansi.Strip(m.renderCommentsPane(60, 40))
+	for _, want := range []string{
+		"a.go new:9 by @alice",
+		"Created 2026-03-04 10:30, edited 2026-03-04 11:30",
+		"first paragraph",
+		"need wrapping",
+		"@@ -5,3 +5,4 @@",
+		"> x := 1",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in comments pane:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Stale:") {
+		t.Fatalf("expected no stale notice for a fresh comment:\n%s", out)
+	}
+}
+
+func TestCommentDetailIsCutToHalfThePane(t *testing.T) {
+	c := comments.Comment{Path: "a.go", Side: comments.SideNew, Line: 1, Body: strings.Repeat("line\n", 30)}
+	m := Model{
+		comments:       map[string]comments.Comment{commentKey(c): c},
+		commentsCursor: 1,
+	}
+
+	detail := m.commentDetail(40, 12)
+	if len(detail) != commentDetailBudget(12) {
+		t.Fatalf("expected the detail cut to %d lines, got %d", commentDetailBudget(12), len(detail))
+	}
+	if last := ansi.Strip(detail[len(detail)-1]); !strings.Contains(last, "more lines") {
+		t.Fatalf("expected a note about the cut lines, got %q", last)
+	}
+}
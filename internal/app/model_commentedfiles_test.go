@@ -0,0 +1,68 @@
+package app
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"diffman/internal/comments"
+	gitint "diffman/internal/git"
+)
+
+func TestJumpToCommentedFileWrapsAndExpandsDirs(t *testing.T) {
+	m := Model{
+		keys:  defaultKeyMap(),
+		focus: focusFiles,
+		fileItems: []gitint.FileItem{
+			{Path: "a.go", Status: "M"},
+			{Path: "pkg/b.go", Status: "M"},
+			{Path: "pkg/c.go", Status: "M"},
+			{Path: "z.go", Status: "M"},
+		},
+		comments: map[string]comments.Comment{
+			"c1": {Path: "pkg/c.go", Side: comments.SideNew, Line: 3, Body: "check"},
+			"c2": {Path: "a.go", Side: comments.SideNew, Line: 1, Body: "nit"},
+		},
+		treeCollapsed: map[string]bool{"pkg": true},
+		selectedF:     "a.go",
+	}
+
+	updated, cmd := m.updateFilesPane(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("N")})
+	m = updated.(Model)
+	if m.selectedF != "pkg/c.go" || cmd == nil {
+		t.Fatalf("expected N to open pkg/c.go, got %q", m.selectedF)
+	}
+	if m.treeCollapsed["pkg"] {
+		t.Fatalf("expected pkg to be expanded to reveal the file")
+	}
+	if entries := m.fileTreeEntries(); entries[m.fileCursor].Path != "pkg/c.go" {
+		t.Fatalf("expected file cursor on pkg/c.go, got %q", entries[m.fileCursor].Path)
+	}
+
+	updated, _ = m.updateFilesPane(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("N")})
+	m = updated.(Model)
+	if m.selectedF != "a.go" {
+		t.Fatalf("expected N to wrap to a.go, got %q", m.selectedF)
+	}
+
+	updated, _ = m.updateFilesPane(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("P")})
+	m = updated.(Model)
+	if m.selectedF != "pkg/c.go" {
+		t.Fatalf("expected P to wrap back to pkg/c.go, got %q", m.selectedF)
+	}
+}
+
+func TestJumpToCommentedFileAlertsWithoutComments(t *testing.T) {
+	m := Model{
+		keys:      defaultKeyMap(),
+		focus:     focusFiles,
+		fileItems: []gitint.FileItem{{Path: "a.go", Status: "M"}},
+		selectedF: "a.go",
+	}
+
+	updated, cmd := m.updateFilesPane(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("N")})
+	m = updated.(Model)
+	if cmd != nil || m.alertMsg != "No files with comments." {
+		t.Fatalf("expected alert without a load, got alert %q", m.alertMsg)
+	}
+}
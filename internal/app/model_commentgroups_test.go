@@ -0,0 +1,54 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+
+	"diffman/internal/comments"
+)
+
+func TestCommentsViewGroupsByFileAndCollapses(t *testing.T) {
+	all := []comments.Comment{
+		{Path: "a.go", Side: comments.SideNew, Line: 1, Body: "one"},
+		{Path: "a.go", Side: comments.SideNew, Line: 7, Body: "two"},
+		{Path: "b.go", Side: comments.SideOld, Line: 3, Body: "three"},
+	}
+	m := Model{keys: defaultKeyMap(), focus: focusComments, width: 100, height: 30, comments: map[string]comments.Comment{}}
+	for _, c := range all {
+		m.comments[commentKey(c)] = c
+	}
+
+	rows := m.commentsViewRows()
+	if len(rows) != 5 || !rows[0].Header || rows[0].Count != 2 || !rows[3].Header || rows[3].Path != "b.go" {
+		t.Fatalf("unexpected rows %+v", rows)
+	}
+	out := ansi.Strip(m.renderCommentsPane(80, 20))
+	for _, want := range []string{"Comments (3 in 2 files", "a.go (2)", "b.go (1)", "new:7"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in comments pane:\n%s", want, out)
+		}
+	}
+
+	press := func(k tea.KeyMsg) {
+		updated, _ := m.updateCommentsPane(k)
+		m = updated.(Model)
+	}
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	if rows := m.commentsViewRows(); len(rows) != 3 || !rows[1].Header {
+		t.Fatalf("expected enter on a heading to collapse a.go, got %+v", rows)
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("l")})
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	if c, ok := m.commentAtCursor(); !ok || c.Line != 7 {
+		t.Fatalf("expected cursor on a.go:7 after expanding, got %+v", c)
+	}
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("h")})
+	if m.commentsCursor != 0 {
+		t.Fatalf("expected h on a comment to move to its heading, got cursor %d", m.commentsCursor)
+	}
+}
@@ -0,0 +1,162 @@
+package app
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+
+	"diffman/internal/comments"
+)
+
+func TestPinnedCommentsAreListedFirstAndPersisted(t *testing.T) {
+	store := comments.NewStore(t.TempDir())
+	all := []comments.Comment{
+		{Path: "a.go", Side: comments.SideNew, Line: 1, Body: "one"},
+		{Path: "b.go", Side: comments.SideNew, Line: 3, Body: "important"},
+	}
+	m := Model{keys: defaultKeyMap(), focus: focusComments, width: 100, height: 30, commentStore: store, comments: map[string]comments.Comment{}}
+	for _, c := range all {
+		m.comments[commentKey(c)] = c
+	}
+	press := func(k string) {
+		updated, _ := m.updateCommentsPane(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+		m = updated.(Model)
+	}
+
+	// Rows: a.go heading, a.go:1, b.go heading, b.go:3.
+	press("G")
+	press("b")
+	rows := m.commentsViewRows()
+	if len(rows) != 4 || !rows[0].Pinned || !rows[0].Header || rows[1].Comment.Body != "important" {
+		t.Fatalf("expected the pinned comment first, got %+v", rows)
+	}
+	if m.commentsCursor != 1 {
+		t.Fatalf("expected the cursor to follow the pinned comment, got %d", m.commentsCursor)
+	}
+	out := ansi.Strip(m.renderCommentsPane(80, 20))
+	for _, want := range []string{"Comments (2 in 2 files", "Pinned (1)", "b.go new:3", "a.go (1)"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in comments pane:\n%s", want, out)
+		}
+	}
+
+	saved, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	pinned := 0
+	for _, c := range saved {
+		if c.Pinned {
+			pinned++
+		}
+	}
+	if pinned != 1 {
+		t.Fatalf("expected the pin to be saved, got %+v", saved)
+	}
+
+	press("b")
+	if rows := m.commentsViewRows(); rows[0].Pinned {
+		t.Fatalf("expected b to unpin, got %+v", rows)
+	}
+}
+
+func TestConvertCommentToTodoInsertsLineAndDropsComment(t *testing.T) {
+	dir := t.TempDir()
+	src := "package x\n\nfunc f() {\n\treturn\n}\n"
+	if err := os.WriteFile(filepath.Join(dir, "x.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c := comments.Comment{Path: "x.go", Side: comments.SideNew, Line: 4, Body: "handle the\nerror here"}
+	m := Model{
+		keys:         defaultKeyMap(),
+		focus:        focusComments,
+		cwd:          dir,
+		commentStore: comments.NewStore(t.TempDir()),
+		comments:     map[string]comments.Comment{commentKey(c): c},
+	}
+
+	updated, _ := m.updateCommentsPane(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	m = updated.(Model)
+	updated, _ = m.updateCommentsPane(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("D")})
+	m = updated.(Model)
+
+	b, err := os.ReadFile(filepath.Join(dir, "x.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "package x\n\nfunc f() {\n\t// TODO(reviewer): handle the error here\n\treturn\n}\n"
+	if string(b) != want {
+		t.Fatalf("file = %q, want %q", b, want)
+	}
+	if len(m.comments) != 0 {
+		t.Fatalf("expected the comment removed after conversion, got %+v", m.comments)
+	}
+}
+
+func TestConvertCommentToTodoShiftsLaterComments(t *testing.T) {
+	dir := t.TempDir()
+	src := "package x\n\nfunc f() {\n\treturn\n}\n"
+	if err := os.WriteFile(filepath.Join(dir, "x.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	todo := comments.Comment{Path: "x.go", Side: comments.SideNew, Line: 4, Body: "fix"}
+	below := comments.Comment{Path: "x.go", Side: comments.SideNew, Line: 5, Body: "closing brace"}
+	above := comments.Comment{Path: "x.go", Side: comments.SideNew, Line: 3, Body: "name"}
+	oldSide := comments.Comment{Path: "x.go", Side: comments.SideOld, Line: 5, Body: "was here"}
+	other := comments.Comment{Path: "y.go", Side: comments.SideNew, Line: 5, Body: "elsewhere"}
+	store := comments.NewStore(t.TempDir())
+	m := Model{
+		keys:             defaultKeyMap(),
+		cwd:              dir,
+		commentStore:     store,
+		comments:         map[string]comments.Comment{},
+		commentsSelected: map[string]bool{commentKey(below): true},
+	}
+	for _, c := range []comments.Comment{todo, below, above, oldSide, other} {
+		m.comments[commentKey(c)] = c
+	}
+
+	m.convertCommentToTodo(todo)
+
+	stored, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := map[string]int{}
+	for _, c := range stored {
+		lines[c.Body] = c.Line
+	}
+	want := map[string]int{"closing brace": 6, "name": 3, "was here": 5, "elsewhere": 5}
+	if !maps.Equal(lines, want) {
+		t.Fatalf("stored lines = %v, want %v", lines, want)
+	}
+	moved := below
+	moved.Line = 6
+	if _, ok := m.comments[commentKey(moved)]; !ok || !m.commentsSelected[commentKey(moved)] {
+		t.Fatalf("expected the comment below keyed and selected at its new line, got %v", m.comments)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Fatalf("expected no temporary file left next to x.go, got %v", entries)
+	}
+}
+
+func TestTodoLineUsesTheFileCommentSyntax(t *testing.T) {
+	for path, want := range map[string]string{
+		"a.py":       "  # TODO(reviewer): fix",
+		"Makefile":   "  # TODO(reviewer): fix",
+		"q.sql":      "  -- TODO(reviewer): fix",
+		"index.html": "  <!-- TODO(reviewer): fix -->",
+	} {
+		if got, ok := todoLine(path, "fix", "  "); !ok || got != want {
+			t.Fatalf("todoLine(%q) = %q, %v; want %q", path, got, ok, want)
+		}
+	}
+	if _, ok := todoLine("notes.txt", "fix", ""); ok {
+		t.Fatalf("expected no syntax for plain text files")
+	}
+}
@@ -0,0 +1,97 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"diffman/internal/comments"
+)
+
+func newSelectionTestModel() Model {
+	list := []comments.Comment{
+		{Path: "a.go", Side: comments.SideNew, Line: 1, Body: "rename this"},
+		{Path: "a.go", Side: comments.SideNew, Line: 5, Body: "nit: spacing"},
+		{Path: "b.go", Side: comments.SideOld, Line: 2, Body: "why removed?"},
+	}
+	m := Model{
+		keys:                defaultKeyMap(),
+		focus:               focusComments,
+		comments:            make(map[string]comments.Comment, len(list)),
+		commentStale:        map[string]bool{},
+		commentsFilterInput: newCommentsFilterInput(),
+	}
+	for _, c := range list {
+		m.comments[commentKey(c)] = c
+	}
+	return m
+}
+
+func pressKeys(t *testing.T, m Model, keys ...string) Model {
+	t.Helper()
+	for _, k := range keys {
+		var msg tea.KeyMsg
+		switch k {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		}
+		next, _ := m.Update(msg)
+		m = next.(Model)
+	}
+	return m
+}
+
+func TestCommentSelectionLimitsExport(t *testing.T) {
+	m := newSelectionTestModel()
+
+	// Rows: a.go heading, a.go:1, a.go:5, b.go heading, b.go:2.
+	m = pressKeys(t, m, "j", "x", "j", "j", "j", "x")
+	got, scope := m.exportScope()
+	if scope != "selected" || len(got) != 2 || got[0].Line != 1 || got[1].Path != "b.go" {
+		t.Fatalf("unexpected selection export: scope=%q comments=%+v", scope, got)
+	}
+
+	// x on a heading selects the whole file, and again clears it.
+	m = pressKeys(t, m, "g", "x")
+	if got, _ := m.exportScope(); len(got) != 3 {
+		t.Fatalf("expected heading to select every a.go comment, got %d", len(got))
+	}
+	m = pressKeys(t, m, "x")
+	if got, _ := m.exportScope(); len(got) != 1 || got[0].Path != "b.go" {
+		t.Fatalf("expected heading to clear a.go comments, got %+v", got)
+	}
+
+	m = pressKeys(t, m, "X")
+	if got, scope := m.exportScope(); scope != "" || len(got) != 3 {
+		t.Fatalf("expected X to clear selection, got scope=%q n=%d", scope, len(got))
+	}
+}
+
+func TestCommentsFilterNarrowsViewAndExport(t *testing.T) {
+	m := newSelectionTestModel()
+
+	m = pressKeys(t, m, "/", "n", "i", "t", "enter")
+	if m.commentsFilterActive || m.commentsFilter != "nit" {
+		t.Fatalf("expected filter kept after enter, got active=%v filter=%q", m.commentsFilterActive, m.commentsFilter)
+	}
+	rows := m.commentsViewRows()
+	if len(rows) != 2 || rows[1].Comment.Line != 5 {
+		t.Fatalf("expected only the nit comment, got %+v", rows)
+	}
+	if got, scope := m.exportScope(); scope != "filtered" || len(got) != 1 {
+		t.Fatalf("expected filtered export, got scope=%q n=%d", scope, len(got))
+	}
+	if pane := m.renderCommentsPane(80, 10); !strings.Contains(pane, `1 matching "nit"`) {
+		t.Fatalf("expected filter in title, got:\n%s", pane)
+	}
+
+	m = pressKeys(t, m, "/", "esc")
+	if m.commentsFilter != "" || len(m.commentsViewRows()) != 5 {
+		t.Fatalf("expected esc to clear the filter")
+	}
+}
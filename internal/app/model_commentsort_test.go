@@ -0,0 +1,64 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"diffman/internal/comments"
+)
+
+func TestCommentsViewSortModes(t *testing.T) {
+	base := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	all := []comments.Comment{
+		{Path: "a.go", Side: comments.SideNew, Line: 1, Body: "nit: spacing", CreatedAt: base},
+		{Path: "b.go", Side: comments.SideNew, Line: 5, Body: "issue: leaks the file handle", CreatedAt: base.Add(time.Minute)},
+		{Path: "c.go", Side: comments.SideNew, Line: 2, Body: "why this?", CreatedAt: base.Add(-time.Hour), UpdatedAt: base.Add(time.Hour)},
+	}
+	m := Model{keys: defaultKeyMap(), focus: focusComments, comments: map[string]comments.Comment{}}
+	for _, c := range all {
+		m.comments[commentKey(c)] = c
+	}
+
+	paths := func() []string {
+		out := []string{}
+		for _, c := range m.commentsViewItems() {
+			out = append(out, c.Path)
+		}
+		return out
+	}
+	want := [][]string{
+		{"a.go", "b.go", "c.go"},
+		{"c.go", "b.go", "a.go"},
+		{"b.go", "c.go", "a.go"},
+		{"a.go", "b.go", "c.go"},
+	}
+	for i, w := range want {
+		if i > 0 {
+			updated, _ := m.updateCommentsPane(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")})
+			m = updated.(Model)
+		}
+		got := paths()
+		for j := range w {
+			if got[j] != w[j] {
+				t.Fatalf("sort %s: got %v, want %v", m.commentsSort, got, w)
+			}
+		}
+	}
+}
+
+func TestCommentAgeShowsEdits(t *testing.T) {
+	now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	c := comments.Comment{CreatedAt: now.Add(-3 * time.Hour), UpdatedAt: now.Add(-5 * time.Minute)}
+	if got := commentAge(c, now); got != "3h ago, edited 5m ago" {
+		t.Fatalf("commentAge() = %q", got)
+	}
+	c.UpdatedAt = time.Time{}
+	if got := commentAge(c, now); got != "3h ago" {
+		t.Fatalf("commentAge() = %q", got)
+	}
+	if got := relativeTime(now.Add(-40*24*time.Hour), now); got != "2025-11-23" {
+		t.Fatalf("relativeTime() = %q", got)
+	}
+}
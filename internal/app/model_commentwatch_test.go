@@ -0,0 +1,139 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"diffman/internal/comments"
+)
+
+func TestCommentsReloadAfterExternalWrite(t *testing.T) {
+	dir := t.TempDir()
+	own := comments.Comment{Path: "a.go", Side: comments.SideNew, Line: 1, Body: "mine"}
+	m := Model{
+		keys:         defaultKeyMap(),
+		commentStore: comments.NewStore(dir),
+		comments:     map[string]comments.Comment{commentKey(own): own},
+	}
+	if err := m.persistComments(); err != nil {
+		t.Fatalf("persistComments() error = %v", err)
+	}
+
+	// Our own write is not a change.
+	if check := m.checkCommentsFile(); check == nil || check() != nil {
+		t.Fatalf("expected no reload after our own save")
+	}
+
+	plugin := comments.Comment{Path: "b.go", Side: comments.SideOld, Line: 7, Body: "from the editor"}
+	if err := comments.NewStore(dir).Save([]comments.Comment{own, plugin}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// Checks are throttled; the first tick after the interval picks it up,
+	// reading the file in a command.
+	if m.checkCommentsFile() != nil {
+		t.Fatalf("expected the check to wait for the watch interval")
+	}
+	m.commentsCheckedAt = time.Now().Add(-commentsWatchInterval)
+	msg := m.checkCommentsFile()()
+	if len(m.comments) != 1 {
+		t.Fatalf("expected nothing applied before the command's result arrives")
+	}
+	next, cmd := m.Update(msg)
+	m = next.(Model)
+	if got, ok := m.comments[commentKey(plugin)]; !ok || got.Body != "from the editor" {
+		t.Fatalf("expected the external comment to be loaded, got %+v", m.comments)
+	}
+	if cmd == nil || !strings.Contains(m.alertMsg, "reloaded 2") {
+		t.Fatalf("expected a stale re-check and an alert, got cmd=%v alert=%q", cmd != nil, m.alertMsg)
+	}
+
+	// Once reloaded, the same file is not picked up again, and a duplicate
+	// result from an earlier check is dropped.
+	m.alertMsg = ""
+	m.commentsCheckedAt = time.Time{}
+	if again := m.checkCommentsFile()(); again != nil {
+		t.Fatalf("expected no second reload, got %+v", again)
+	}
+	next, _ = m.Update(msg)
+	if next.(Model).alertMsg != "" {
+		t.Fatalf("expected the stale result dropped, got %q", next.(Model).alertMsg)
+	}
+}
+
+func TestSaveGoesAheadWhenTheFileOnDiskIsUnreadable(t *testing.T) {
+	dir := t.TempDir()
+	own := comments.Comment{ID: comments.NewID(), Path: "a.go", Side: comments.SideNew, Line: 1, Body: "mine"}
+	m := Model{
+		keys:         defaultKeyMap(),
+		commentStore: comments.NewStore(dir),
+		comments:     map[string]comments.Comment{commentKey(own): own},
+	}
+	if err := m.persistComments(); err != nil {
+		t.Fatalf("persistComments() error = %v", err)
+	}
+	// Another writer leaves a damaged file and no backup behind.
+	path := filepath.Join(dir, ".diffman", "comments.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.persistComments(); err != nil {
+		t.Fatalf("expected the save to go ahead, got %v", err)
+	}
+	if !strings.Contains(m.alertMsg, "could not be reloaded") {
+		t.Fatalf("expected the merge failure reported, got %q", m.alertMsg)
+	}
+	stored, err := comments.NewStore(dir).Load()
+	if err != nil || len(stored) != 1 || stored[0].Body != "mine" {
+		t.Fatalf("expected this process's comments saved, got %+v, %v", stored, err)
+	}
+}
+
+func TestSaveKeepsCommentsAddedBetweenPolls(t *testing.T) {
+	dir := t.TempDir()
+	own := comments.Comment{ID: comments.NewID(), Path: "a.go", Side: comments.SideNew, Line: 1, Body: "mine"}
+	gone := comments.Comment{ID: comments.NewID(), Path: "a.go", Side: comments.SideNew, Line: 5, Body: "to delete"}
+	m := Model{
+		keys:         defaultKeyMap(),
+		commentStore: comments.NewStore(dir),
+		comments:     map[string]comments.Comment{commentKey(own): own, commentKey(gone): gone},
+	}
+	if err := m.persistComments(); err != nil {
+		t.Fatalf("persistComments() error = %v", err)
+	}
+
+	// diffman comment add writes before the next poll, and edits own too.
+	cli := comments.Comment{ID: comments.NewID(), Path: "b.go", Side: comments.SideNew, Line: 3, Body: "from the CLI"}
+	edited := own
+	edited.Body = "mine, edited elsewhere"
+	if err := comments.NewStore(dir).Save([]comments.Comment{edited, gone, cli}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// The UI then deletes one comment and adds another without polling.
+	delete(m.comments, commentKey(gone))
+	added := comments.Comment{ID: comments.NewID(), Path: "c.go", Side: comments.SideNew, Line: 2, Body: "from the UI"}
+	m.comments[commentKey(added)] = added
+	if err := m.persistComments(); err != nil {
+		t.Fatalf("persistComments() error = %v", err)
+	}
+
+	stored, err := comments.NewStore(dir).Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	var bodies []string
+	for _, c := range stored {
+		bodies = append(bodies, c.Body)
+	}
+	if got := strings.Join(bodies, ", "); got != "mine, edited elsewhere, from the CLI, from the UI" {
+		t.Fatalf("stored comments = %s", got)
+	}
+	if len(m.comments) != 3 || !strings.Contains(m.alertMsg, "Kept 1 comment(s) added by another process") {
+		t.Fatalf("expected the merged set in memory, got %d comments, alert %q", len(m.comments), m.alertMsg)
+	}
+}
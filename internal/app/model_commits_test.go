@@ -0,0 +1,63 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"diffman/internal/githubpr"
+)
+
+func TestCommitPanelShowsPRCommitMessages(t *testing.T) {
+	service := &mockPRService{commits: []githubpr.Commit{
+		{SHA: "abc1234def", Author: "alice", Date: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), Message: "Split the parser\n\nLexing moves out so the grammar can be tested alone."},
+		{SHA: "9876543210", Author: "bob", Message: "Fix typo"},
+	}}
+	m := Model{
+		keys:       defaultKeyMap(),
+		reviewMode: reviewModePR,
+		prCtx:      &githubpr.Context{Owner: "acme", Repo: "widgets", Number: 7},
+		prSvc:      service,
+		width:      120,
+		height:     40,
+	}
+	press := func(m Model, r rune) (Model, tea.Cmd) {
+		next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		return next.(Model), cmd
+	}
+
+	m, cmd := press(m, 'M')
+	if !m.commitPanelOpen || cmd == nil {
+		t.Fatalf("expected M to open the panel and load the commits")
+	}
+	next, _ := m.Update(cmd())
+	m = next.(Model)
+	view := m.renderCommitPanelModal()
+	for _, want := range []string{"PR #7 commits (2)", "abc1234", "alice", "Split the parser", "Lexing moves out", "9876543", "Fix typo"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("expected %q in the panel:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "abc1234def") {
+		t.Fatalf("expected short hashes:\n%s", view)
+	}
+
+	m, _ = press(m, 'M')
+	if m.commitPanelOpen {
+		t.Fatalf("expected M to close the panel")
+	}
+	if m, cmd = press(m, 'M'); !m.commitPanelOpen || cmd != nil {
+		t.Fatalf("expected reopening to reuse the loaded commits")
+	}
+}
+
+func TestCommitPanelNeedsAPR(t *testing.T) {
+	m := Model{keys: defaultKeyMap()}
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'M'}})
+	m = next.(Model)
+	if m.commitPanelOpen || !strings.Contains(m.alertMsg, "PRs") {
+		t.Fatalf("expected an alert in local mode, got open=%v %q", m.commitPanelOpen, m.alertMsg)
+	}
+}
@@ -0,0 +1,74 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"diffman/internal/comments"
+)
+
+func TestCopyCommentFromCommentsViewCopiesOnlyThatComment(t *testing.T) {
+	dir := fakeTmux(t)
+	t.Setenv("TMUX", "/tmp/tmux-1000/default,1,0")
+
+	a := comments.Comment{ID: "c1", Path: "a.go", Side: comments.SideNew, Line: 3, Body: "rename this", ContextAfter: []string{"x := 1"}}
+	b := comments.Comment{ID: "c2", Path: "b.go", Side: comments.SideNew, Line: 1, Body: "other"}
+	m := Model{
+		keys:     defaultKeyMap(),
+		focus:    focusComments,
+		comments: map[string]comments.Comment{commentKey(a): a, commentKey(b): b},
+	}
+	m.commentsCursor = 1 // the a.go heading is row 0
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Y")})
+	if cmd == nil {
+		t.Fatalf("expected Y to copy the comment")
+	}
+	msg, ok := cmd().(clipboardResultMsg)
+	if !ok || msg.err != nil || len(msg.exported) != 1 || msg.exported[0].ID != "c1" {
+		t.Fatalf("unexpected result %+v", msg)
+	}
+	buffer, err := os.ReadFile(filepath.Join(dir, "buffer"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(buffer), "1) a.go new:3: rename this") || !strings.Contains(string(buffer), "> x := 1") {
+		t.Fatalf("buffer = %q", buffer)
+	}
+	if strings.Contains(string(buffer), "b.go") {
+		t.Fatalf("expected only the selected comment, got %q", buffer)
+	}
+}
+
+func TestCopyCommentInQuickfixFormat(t *testing.T) {
+	dir := fakeTmux(t)
+	t.Setenv("TMUX", "/tmp/tmux-1000/default,1,0")
+
+	c := comments.Comment{Path: "a.go", Side: comments.SideOld, Line: 7, Body: "why?"}
+	m := Model{exportFormat: ExportFormatQuickfix}
+	if msg := m.copyCommentCmd(c)().(clipboardResultMsg); msg.err != nil {
+		t.Fatalf("copy failed: %v", msg.err)
+	}
+	buffer, _ := os.ReadFile(filepath.Join(dir, "buffer"))
+	if string(buffer) != "a.go:7:1: (old) why?" {
+		t.Fatalf("buffer = %q", buffer)
+	}
+}
+
+func TestCopyCommentWithoutCommentOnLine(t *testing.T) {
+	m := Model{
+		keys:     defaultKeyMap(),
+		focus:    focusDiff,
+		comments: map[string]comments.Comment{},
+		diffRows: addedRows("a.go", 3),
+	}
+	m.diffCursor = 1
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Y")})
+	if cmd != nil || next.(Model).alertMsg != "No comment exists on selected line." {
+		t.Fatalf("expected a notice, got %q", next.(Model).alertMsg)
+	}
+}
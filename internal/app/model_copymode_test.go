@@ -0,0 +1,45 @@
+package app
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"diffman/internal/diffview"
+)
+
+func TestCopyModeSelectsSideTextAcrossRows(t *testing.T) {
+	m := Model{
+		keys:  defaultKeyMap(),
+		focus: focusDiff,
+		diffRows: []diffview.DiffRow{
+			{Kind: diffview.RowHunkHeader, OldText: "@@ -1,3 +1,3 @@"},
+			{Kind: diffview.RowContext, OldLine: 1, NewLine: 1, OldText: "same", NewText: "same"},
+			{Kind: diffview.RowDelete, OldLine: 2, OldText: "gone"},
+			{Kind: diffview.RowAdd, NewLine: 2, NewText: "added"},
+		},
+	}
+
+	updated, _ := m.updateDiffPane(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("v")})
+	m = updated.(Model)
+	if !m.copyMode {
+		t.Fatalf("expected v to start copy mode")
+	}
+	for range 3 {
+		updated, _ = m.updateDiffPane(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+		m = updated.(Model)
+	}
+
+	if text, n := m.selectedSideText(diffview.SideNew); text != "same\nadded" || n != 2 {
+		t.Fatalf("new side = %q (%d lines)", text, n)
+	}
+	if text, n := m.selectedSideText(diffview.SideOld); text != "same\ngone" || n != 2 {
+		t.Fatalf("old side = %q (%d lines)", text, n)
+	}
+
+	updated, _ = m.updateDiffPane(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(Model)
+	if m.copyMode {
+		t.Fatalf("expected Esc to leave copy mode")
+	}
+}
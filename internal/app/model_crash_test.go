@@ -0,0 +1,93 @@
+package app
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"diffman/internal/comments"
+)
+
+func TestPanicInUpdateWritesCrashReportAndKeepsDraft(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	store := comments.NewStore(t.TempDir())
+	m := Model{
+		keys:               defaultKeyMap(),
+		reviewMode:         reviewModePR, // prDiffs is nil, so caching an empty diff panics
+		commentStore:       store,
+		comments:           map[string]comments.Comment{},
+		commentInputActive: true,
+		commentInputModel:  textinput.New(),
+		commentEditAnchor:  &commentAnchor{Path: "a.go", Side: comments.SideNew, Line: 3},
+		crash:              &crashState{},
+	}
+	m.commentInputModel.SetValue("half written")
+
+	next, cmd := m.Update(diffLoadedMsg{path: "a.go", empty: true})
+	if cmd == nil {
+		t.Fatalf("expected the panic to quit the program")
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Fatalf("expected tea.Quit after a panic")
+	}
+	report, err := next.(Model).CrashReport()
+	if err != nil || report == "" {
+		t.Fatalf("expected a crash report, got %q, %v", report, err)
+	}
+	b, err := os.ReadFile(report)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	for _, want := range []string{"during:  update of app.diffLoadedMsg", "assignment to entry in nil map", "half written", `"review_mode": "pr"`, "stack:"} {
+		if !strings.Contains(string(b), want) {
+			t.Fatalf("expected %q in crash report, got:\n%s", want, b)
+		}
+	}
+
+	draft, err := store.LoadDraft()
+	if err != nil || draft == nil || draft.Body != "half written" {
+		t.Fatalf("expected the dock text saved as a draft, got %#v, %v", draft, err)
+	}
+
+	// The next message only quits, and View shows where the report went.
+	if _, cmd := next.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")}); cmd == nil {
+		t.Fatalf("expected updates after a crash to quit")
+	}
+	if view := next.View(); !strings.Contains(view, report) {
+		t.Fatalf("expected the crash notice to name the report, got %q", view)
+	}
+}
+
+func TestCrashReportLeavesOutEncryptedDraftBodies(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	key := make([]byte, comments.KeySize)
+	m := Model{
+		keys:               defaultKeyMap(),
+		reviewMode:         reviewModePR,
+		commentStore:       comments.NewStore(t.TempDir()).WithKey(key),
+		commentsKey:        key,
+		comments:           map[string]comments.Comment{},
+		commentInputActive: true,
+		commentInputModel:  textinput.New(),
+		commentEditAnchor:  &commentAnchor{Path: "a.go", Side: comments.SideNew, Line: 3},
+		anchorDrafts:       map[string]comments.Draft{"b.go:new:1": {Path: "b.go", Side: comments.SideNew, Line: 1, Body: "stashed secret"}},
+		crash:              &crashState{},
+	}
+	m.commentInputModel.SetValue("typed secret")
+
+	next, _ := m.Update(diffLoadedMsg{path: "a.go", empty: true})
+	report, err := next.(Model).CrashReport()
+	if err != nil || report == "" {
+		t.Fatalf("expected a crash report, got %q, %v", report, err)
+	}
+	b, err := os.ReadFile(report)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if strings.Contains(string(b), "secret") || !strings.Contains(string(b), "a.go:new:3") {
+		t.Fatalf("expected draft locations without their bodies, got:\n%s", b)
+	}
+}
@@ -0,0 +1,79 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/viewport"
+
+	"diffman/internal/comments"
+	gitint "diffman/internal/git"
+)
+
+func TestParseLocation(t *testing.T) {
+	tests := []struct {
+		arg  string
+		want Location
+	}{
+		{"internal/app/model.go", Location{Path: "internal/app/model.go"}},
+		{"internal/app/model.go:245", Location{Path: "internal/app/model.go", Line: 245}},
+		{"internal/app/model.go:245:", Location{Path: "internal/app/model.go", Line: 245}},
+		{"internal/app/model.go:245:12", Location{Path: "internal/app/model.go", Line: 245}},
+		{"C:/src/a.go:3", Location{Path: "C:/src/a.go", Line: 3}},
+		{"notes:v2.txt", Location{Path: "notes:v2.txt"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseLocation(tt.arg)
+		if err != nil {
+			t.Fatalf("ParseLocation(%q) error = %v", tt.arg, err)
+		}
+		if got != tt.want {
+			t.Fatalf("ParseLocation(%q) = %+v, want %+v", tt.arg, got, tt.want)
+		}
+	}
+	for _, bad := range []string{"", ":12"} {
+		if _, err := ParseLocation(bad); err == nil {
+			t.Fatalf("ParseLocation(%q) expected an error", bad)
+		}
+	}
+}
+
+func TestOpenLocationJumpsToLineOnceTheDiffLoads(t *testing.T) {
+	m := Model{
+		keys:      defaultKeyMap(),
+		comments:  map[string]comments.Comment{},
+		fileItems: []gitint.FileItem{{Path: "a.go", Status: "A"}, {Path: "b.go", Status: "A"}},
+		oldView:   viewport.New(60, 5),
+		newView:   viewport.New(60, 5),
+		oldWidth:  -1,
+		newWidth:  -1,
+	}
+	m.openLocation(Location{Path: "b.go", Line: 12})
+	m.checkPendingLocation()
+	if m.pendingLocation == nil || m.focus != focusDiff || m.selectedF != "b.go" {
+		t.Fatalf("expected b.go selected with the line pending, got %q %+v", m.selectedF, m.pendingLocation)
+	}
+
+	next, _ := m.Update(diffLoadedMsg{path: "b.go", rows: addedRows("b.go", 20)})
+	m = next.(Model)
+	if row := m.diffRows[m.diffCursor]; row.NewLine == 0 || row.NewLine != 12 {
+		t.Fatalf("expected the cursor on line 12, got row %d", m.diffCursor)
+	}
+	if m.pendingLocation != nil {
+		t.Fatalf("expected the pending location used up")
+	}
+
+	m.jumpToLocation(Location{Path: "b.go", Line: 40})
+	if row := m.diffRows[m.diffCursor]; row.NewLine != 20 || !strings.Contains(m.alertMsg, "showing line 20") {
+		t.Fatalf("expected the nearest line with a notice, got line %d and %q", row.NewLine, m.alertMsg)
+	}
+}
+
+func TestOpenLocationReportsUnchangedFile(t *testing.T) {
+	m := Model{fileItems: []gitint.FileItem{{Path: "a.go", Status: "M"}}}
+	m.openLocation(Location{Path: "README.md", Line: 3})
+	m.checkPendingLocation()
+	if m.pendingLocation != nil || m.focus != focusFiles || !strings.Contains(m.alertMsg, "README.md has no changes") {
+		t.Fatalf("expected the location dropped with a notice, got %+v %q", m.pendingLocation, m.alertMsg)
+	}
+}
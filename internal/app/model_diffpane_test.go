@@ -1,6 +1,7 @@
 package app
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/charmbracelet/bubbles/viewport"
@@ -12,8 +13,8 @@ func TestDiffPaneModeNewOnly(t *testing.T) {
 	m := Model{
 		diffRows: []diffview.DiffRow{
 			{Kind: diffview.RowHunkHeader, OldText: "@@ -0,0 +1,2 @@"},
-			{Kind: diffview.RowAdd, NewLine: intPtr(1), NewText: "a"},
-			{Kind: diffview.RowAdd, NewLine: intPtr(2), NewText: "b"},
+			{Kind: diffview.RowAdd, NewLine: 1, NewText: "a"},
+			{Kind: diffview.RowAdd, NewLine: 2, NewText: "b"},
 		},
 	}
 
@@ -30,7 +31,7 @@ func TestDiffPaneModeNewOnly(t *testing.T) {
 func TestDiffPaneModeSplitWhenBothSidesPresent(t *testing.T) {
 	m := Model{
 		diffRows: []diffview.DiffRow{
-			{Kind: diffview.RowChange, OldLine: intPtr(10), NewLine: intPtr(10), OldText: "x", NewText: "y"},
+			{Kind: diffview.RowChange, OldLine: 10, NewLine: 10, OldText: "x", NewText: "y"},
 		},
 	}
 
@@ -39,12 +40,42 @@ func TestDiffPaneModeSplitWhenBothSidesPresent(t *testing.T) {
 	}
 }
 
+func TestToggleZoomMaximizesSideAndRestoresSplit(t *testing.T) {
+	m := Model{
+		width:     120,
+		height:    40,
+		filePaneW: filePaneWidthDefault,
+		diffRows: []diffview.DiffRow{
+			{Kind: diffview.RowChange, OldLine: 10, NewLine: 10, OldText: "x", NewText: "y"},
+		},
+	}
+
+	m.toggleZoom(diffPaneModeNewOnly)
+	if got := m.diffPaneMode(); got != diffPaneModeNewOnly {
+		t.Fatalf("diffPaneMode()=%v want %v after zoom", got, diffPaneModeNewOnly)
+	}
+	if !m.filePaneHidden() {
+		t.Fatalf("expected file pane hidden while zoomed")
+	}
+	if oldW, newW := m.diffSidePaneWidths(80); oldW != 0 || newW != 80 {
+		t.Fatalf("diffSidePaneWidths()=(%d,%d) want (0,80)", oldW, newW)
+	}
+
+	m.toggleZoom(diffPaneModeNewOnly)
+	if got := m.diffPaneMode(); got != diffPaneModeSplit {
+		t.Fatalf("diffPaneMode()=%v want %v after restore", got, diffPaneModeSplit)
+	}
+	if m.filePaneHidden() {
+		t.Fatalf("expected file pane visible after restore")
+	}
+}
+
 func TestRefreshDiffContentNewOnlyDoesNotInflateRowHeights(t *testing.T) {
 	m := Model{
 		diffRows: []diffview.DiffRow{
 			{Kind: diffview.RowHunkHeader, OldText: "@@ -0,0 +1,2 @@"},
-			{Kind: diffview.RowAdd, NewLine: intPtr(1), NewText: "package config"},
-			{Kind: diffview.RowAdd, NewLine: intPtr(2), NewText: ""},
+			{Kind: diffview.RowAdd, NewLine: 1, NewText: "package config"},
+			{Kind: diffview.RowAdd, NewLine: 2, NewText: ""},
 		},
 		diffDirty: true,
 	}
@@ -58,7 +89,25 @@ func TestRefreshDiffContentNewOnlyDoesNotInflateRowHeights(t *testing.T) {
 	}
 }
 
-func intPtr(v int) *int {
-	n := v
-	return &n
+func TestScrollingIntoLongRowRestylesVisibleLines(t *testing.T) {
+	m := Model{
+		diffRows: []diffview.DiffRow{
+			{Kind: diffview.RowAdd, Path: "bundle.js", NewLine: 1, NewText: strings.Repeat("minified ", 250)},
+		},
+		diffDirty: true,
+	}
+	m.oldView = viewport.New(40, 5)
+	m.newView = viewport.New(40, 5)
+
+	m.refreshDiffContent()
+	if !m.diffUnstyled {
+		t.Fatalf("expected the long row's lines below the window left unstyled")
+	}
+	start := m.oldView.YOffset
+	for i := 0; i < 20; i++ {
+		m.scrollDiffWindow(-1)
+	}
+	if top := m.oldView.YOffset; top != start-20 || top < m.styledFrom || top+5 > m.styledTo {
+		t.Fatalf("expected lines %d-%d styled after scrolling, styled %d-%d", top, top+5, m.styledFrom, m.styledTo)
+	}
 }
@@ -0,0 +1,88 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/viewport"
+
+	"diffman/internal/comments"
+	"diffman/internal/diffview"
+	gitint "diffman/internal/git"
+)
+
+// bigDiff changes every tenth line of a file with long lines, so its diff
+// is over streamDiffBytes.
+func bigDiff(path string, hunks int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "diff --git a/%s b/%s\n--- a/%s\n+++ b/%s\n", path, path, path, path)
+	long := strings.Repeat("x", 200)
+	for i := range hunks {
+		line := i*10 + 1
+		fmt.Fprintf(&b, "@@ -%d,2 +%d,2 @@\n %s context %d\n-%s old %d\n+%s new %d\n", line, line, long, i, long, i, long, i)
+	}
+	return b.String()
+}
+
+func TestLargeDiffShowsFirstRowsWhileTheRestStreams(t *testing.T) {
+	raw := bigDiff("big.txt", 2000)
+	if len(raw) <= streamDiffBytes {
+		t.Fatalf("test diff is only %d bytes", len(raw))
+	}
+	svc := stubDiffService{diffs: map[string]string{"big.txt": raw}}
+	want, err := diffview.ParseUnifiedDiff([]byte(raw))
+	if err != nil {
+		t.Fatalf("ParseUnifiedDiff() error = %v", err)
+	}
+
+	rows, rest, empty, err := startLocalDiff(t.Context(), svc, "/repo", "big.txt", "", gitint.DiffModeAll, true)
+	if err != nil || empty || rest == nil {
+		t.Fatalf("startLocalDiff() rest=%v empty=%v err=%v", rest, empty, err)
+	}
+	if len(rows) == 0 || len(rows) >= len(want) {
+		t.Fatalf("expected only the first rows, got %d of %d", len(rows), len(want))
+	}
+
+	m := Model{
+		keys:      defaultKeyMap(),
+		comments:  map[string]comments.Comment{},
+		focus:     focusDiff,
+		fileItems: []gitint.FileItem{{Path: "big.txt", Status: "M"}},
+		selectedF: "big.txt",
+		oldView:   viewport.New(60, 5),
+		newView:   viewport.New(60, 5),
+	}
+	next, cmd := m.Update(diffLoadedMsg{path: "big.txt", rows: rows, stream: rest})
+	m = next.(Model)
+	if m.diffStream == nil || cmd == nil {
+		t.Fatalf("expected the rest of the diff to be parsed next")
+	}
+	if title := m.renderDiffSidePane(60, 5, "New", m.newView.View(), true); !strings.Contains(title, "parsing") {
+		t.Fatalf("expected the pane title to show parsing progress, got %q", title)
+	}
+
+	for batches := 0; m.diffStream != nil; batches++ {
+		if batches > 10 {
+			t.Fatalf("expected the diff streamed in a few batches, still going with %d rows", len(m.diffRows))
+		}
+		next, _ = m.handleDiffBatch(diffBatchCmd("big.txt", m.diffStream, len(m.diffRows))().(diffBatchMsg))
+		m = next.(Model)
+	}
+	if len(m.diffRows) != len(want) || len(m.rowStarts) != len(want) {
+		t.Fatalf("expected all %d rows rendered, got %d rows and %d starts", len(want), len(m.diffRows), len(m.rowStarts))
+	}
+	last := m.diffRows[len(m.diffRows)-1]
+	if last.HunkID != want[len(want)-1].HunkID || last.NewText != want[len(want)-1].NewText {
+		t.Fatalf("expected the last streamed row to match a full parse, got %+v", last)
+	}
+}
+
+func TestDiffBatchFromAnotherFileIsDropped(t *testing.T) {
+	stream := diffview.NewDiffStream([]byte(bigDiff("a.txt", 1)))
+	m := Model{selectedF: "b.txt", diffStream: stream}
+	next, cmd := m.handleDiffBatch(diffBatchMsg{path: "a.txt", stream: stream, rows: addedRows("a.txt", 1)})
+	if got := next.(Model); len(got.diffRows) != 0 || cmd != nil {
+		t.Fatalf("expected a batch for another file to be ignored, got %d rows", len(got.diffRows))
+	}
+}
@@ -0,0 +1,93 @@
+package app
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gitint "diffman/internal/git"
+)
+
+func TestDiffToolCommandAppendsFiles(t *testing.T) {
+	for command, want := range map[string]string{
+		"meld":                           `meld "$OLD" "$NEW"`,
+		"code --wait --diff":             `code --wait --diff "$OLD" "$NEW"`,
+		`kdiff3 "$OLD" "$NEW" -o "$NEW"`: `kdiff3 "$OLD" "$NEW" -o "$NEW"`,
+	} {
+		if got := diffToolCommand(command); got != want {
+			t.Fatalf("diffToolCommand(%q) = %q, want %q", command, got, want)
+		}
+	}
+}
+
+func TestOpenDiffToolWithoutConfigAlerts(t *testing.T) {
+	m := Model{selectedF: "a.go"}
+	if cmd := m.openDiffToolCmd(); cmd != nil {
+		t.Fatalf("expected no command without a difftool")
+	}
+	if !strings.Contains(m.alertMsg, "difftool") {
+		t.Fatalf("alert = %q", m.alertMsg)
+	}
+}
+
+func TestOpenDiffToolWritesOldSide(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if os.Getenv("GIT_DIR") != "" {
+		t.Skip("GIT_DIR is set")
+	}
+	repo := t.TempDir()
+	write := func(body string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(repo, "a.go"), []byte(body), 0o644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+	write("committed\n")
+	gitCmd(t, repo, "init", "-q")
+	gitCmd(t, repo, "add", ".")
+	gitCmd(t, repo, "commit", "-q", "-m", "init")
+	write("staged\n")
+	gitCmd(t, repo, "add", ".")
+	write("working\n")
+
+	read := func(path string) string {
+		t.Helper()
+		b, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile() error = %v", err)
+		}
+		return string(b)
+	}
+	for _, tc := range []struct {
+		mode     gitint.DiffMode
+		old, new string
+	}{
+		{gitint.DiffModeAll, "committed\n", "working\n"},
+		{gitint.DiffModeUnstaged, "staged\n", "working\n"},
+		{gitint.DiffModeStaged, "committed\n", "staged\n"},
+	} {
+		m := Model{cwd: repo, selectedF: "a.go", diffMode: tc.mode, diffTool: "meld"}
+		cmd := m.openDiffToolCmd()
+		if cmd == nil {
+			t.Fatalf("%v: no command, alert %q", tc.mode, m.alertMsg)
+		}
+		msg, ok := cmd().(diffToolReadyMsg)
+		if !ok || msg.err != nil {
+			t.Fatalf("%v: msg = %#v", tc.mode, msg)
+		}
+		t.Cleanup(func() { os.RemoveAll(msg.dir) })
+		if got := read(msg.old); got != tc.old || filepath.Base(msg.old) != "a.go" {
+			t.Fatalf("%v: old %s = %q, want %q", tc.mode, msg.old, got, tc.old)
+		}
+		if got := read(msg.new); got != tc.new {
+			t.Fatalf("%v: new %s = %q, want %q", tc.mode, msg.new, got, tc.new)
+		}
+		if tc.mode != gitint.DiffModeStaged && msg.new != filepath.Join(repo, "a.go") {
+			t.Fatalf("%v: new = %s, want the working file", tc.mode, msg.new)
+		}
+	}
+}
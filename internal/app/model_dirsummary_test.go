@@ -0,0 +1,111 @@
+package app
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	gitint "diffman/internal/git"
+)
+
+func TestDirSummaryListsWhollyAddedAndDeletedDirectories(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if os.Getenv("GIT_DIR") != "" {
+		t.Skip("GIT_DIR is set")
+	}
+	repo := t.TempDir()
+	write := func(name, body string) {
+		t.Helper()
+		path := filepath.Join(repo, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("MkdirAll() error = %v", err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+	write("gone/a.txt", "one\ntwo\n")
+	write("gone/b.bin", "\x00\x01\x02")
+	write("keep.txt", "keep\n")
+	gitCmd(t, repo, "init", "-q")
+	gitCmd(t, repo, "add", ".")
+	gitCmd(t, repo, "commit", "-q", "-m", "init")
+	if err := os.RemoveAll(filepath.Join(repo, "gone")); err != nil {
+		t.Fatalf("RemoveAll() error = %v", err)
+	}
+	write("fresh/x.go", "a\nb\nc")
+	write("keep.txt", "kept\n")
+
+	m := Model{
+		keys:  defaultKeyMap(),
+		cwd:   repo,
+		focus: focusFiles,
+		fileItems: []gitint.FileItem{
+			{Path: "fresh/x.go", Status: "?"},
+			{Path: "gone/a.txt", Status: "D"},
+			{Path: "gone/b.bin", Status: "D"},
+			{Path: "keep.txt", Status: "M"},
+		},
+		selectedF: "fresh/x.go",
+	}
+	m.syncFileCursorToSelectedPath()
+
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'k'}})
+	m = next.(Model)
+	if cmd == nil || m.dirSummary == nil || m.dirSummary.dir != "fresh" {
+		t.Fatalf("expected moving onto fresh/ to load its summary, got %+v", m.dirSummary)
+	}
+	next, _ = m.Update(cmd())
+	m = next.(Model)
+	if !m.dirSummaryShown() {
+		t.Fatalf("expected the summary in the diff pane")
+	}
+	if got := m.dirSummary.headline(); got != "1 new file(s), 5 bytes, +3 line(s)" {
+		t.Fatalf("headline = %q", got)
+	}
+
+	entries := m.fileTreeEntries()
+	if !m.setFileCursorByDir(entries, "gone") {
+		t.Fatalf("no gone/ entry in %+v", entries)
+	}
+	cmd = m.openDirSummaryAtCursor(entries)
+	if cmd == nil {
+		t.Fatalf("expected gone/ to load its summary")
+	}
+	next, _ = m.Update(cmd())
+	m = next.(Model)
+	if got := m.dirSummary.headline(); got != "2 deleted file(s), 11 bytes, -2 line(s), 1 binary" {
+		t.Fatalf("headline = %q", got)
+	}
+	pane := m.renderDirSummaryPane(80, 20)
+	for _, want := range []string{"Directory: gone/ (deleted)", "a.txt", "b.bin", "bin"} {
+		if !strings.Contains(pane, want) {
+			t.Fatalf("expected %q in the summary:\n%s", want, pane)
+		}
+	}
+
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if next.(Model).dirSummaryShown() {
+		t.Fatalf("expected the diff back once the file pane loses focus")
+	}
+}
+
+func TestDirSummarySkipsDirectoriesWithOtherChanges(t *testing.T) {
+	m := Model{fileItems: []gitint.FileItem{
+		{Path: "pkg/new.go", Status: "A"},
+		{Path: "pkg/old.go", Status: "M"},
+		{Path: "pkgs/gone.go", Status: "D"},
+	}}
+	if _, _, ok := m.wholeDirChange("pkg"); ok {
+		t.Fatalf("expected a directory with a modified file to have no summary")
+	}
+	if paths, deleted, ok := m.wholeDirChange("pkgs"); !ok || !deleted || len(paths) != 1 {
+		t.Fatalf("wholeDirChange(pkgs) = %v, %v, %v", paths, deleted, ok)
+	}
+}
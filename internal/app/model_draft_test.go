@@ -0,0 +1,95 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"diffman/internal/comments"
+	"diffman/internal/diffview"
+)
+
+func TestCtrlCWithCommentTextAsksBeforeQuitting(t *testing.T) {
+	store := comments.NewStore(t.TempDir())
+	m := Model{
+		keys:               defaultKeyMap(),
+		commentStore:       store,
+		comments:           map[string]comments.Comment{},
+		commentInputActive: true,
+		commentInputModel:  textinput.New(),
+		commentEditAnchor:  &commentAnchor{Path: "a.go", Side: comments.SideNew, Line: 3},
+	}
+	m.commentInputModel.SetValue("half written")
+
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	m2 := next.(Model)
+	if cmd != nil {
+		t.Fatalf("expected ctrl+c with text to prompt instead of quitting")
+	}
+	if !m2.quitConfirmModal {
+		t.Fatalf("expected quit confirmation modal")
+	}
+
+	m2.draftDirty = true
+	m2.flushDraft()
+	draft, err := store.LoadDraft()
+	if err != nil {
+		t.Fatalf("LoadDraft() error = %v", err)
+	}
+	if draft == nil || draft.Body != "half written" || draft.Line != 3 {
+		t.Fatalf("expected draft to be autosaved, got %#v", draft)
+	}
+
+	next, cmd = m2.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	if cmd == nil {
+		t.Fatalf("expected discard to quit")
+	}
+	if next.(Model).commentInputActive {
+		t.Fatalf("expected discard to close the comment dock")
+	}
+	if draft, _ := store.LoadDraft(); draft != nil {
+		t.Fatalf("expected discard to remove the saved draft")
+	}
+}
+
+func TestEscKeepsDraftForAnchorAndRestoresIt(t *testing.T) {
+	store := comments.NewStore(t.TempDir())
+	m := Model{
+		keys:              defaultKeyMap(),
+		commentStore:      store,
+		comments:          map[string]comments.Comment{},
+		commentInputModel: textinput.New(),
+		diffRows: []diffview.DiffRow{
+			{Kind: diffview.RowAdd, Path: "a.go", NewLine: 1, NewText: "x"},
+		},
+	}
+
+	m.startCommentEdit(false)
+	m.commentInputModel.SetValue("not done yet")
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m2 := next.(Model)
+	if m2.commentInputActive {
+		t.Fatalf("expected esc to close the dock")
+	}
+
+	m2.startCommentEdit(false)
+	if got := m2.commentInputModel.Value(); got != "not done yet" {
+		t.Fatalf("reopened dock value=%q want the stashed draft", got)
+	}
+
+	// The stash is saved, so a new session offers it too.
+	restarted := m
+	restarted.anchorDrafts = nil
+	restarted.loadStashedDrafts()
+	restarted.startCommentEdit(false)
+	if got := restarted.commentInputModel.Value(); got != "not done yet" {
+		t.Fatalf("dock after restart value=%q want the stashed draft", got)
+	}
+
+	m2.commentInputModel.SetValue("")
+	m2.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if drafts, err := store.LoadStashedDrafts(); err != nil || len(drafts) != 0 {
+		t.Fatalf("expected emptying the dock to drop the saved stash, got %#v, %v", drafts, err)
+	}
+}
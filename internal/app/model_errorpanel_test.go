@@ -0,0 +1,86 @@
+package app
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+
+	gitint "diffman/internal/git"
+	"diffman/internal/util"
+)
+
+func TestFailedDiffLoadShowsCommandAndRetries(t *testing.T) {
+	m := Model{
+		keys:      defaultKeyMap(),
+		focus:     focusDiff,
+		statusSvc: stubStatusService{},
+		diffSvc:   stubDiffService{diffs: map[string]string{"a.go": "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1 +1 @@\n-x\n+y\n"}},
+		fileItems: []gitint.FileItem{{Path: "a.go", Status: ".M"}},
+		selectedF: "a.go",
+		oldView:   viewport.New(60, 20),
+		newView:   viewport.New(60, 20),
+	}
+	err := &util.CommandError{Name: "git", Args: []string{"diff", "-U3", "--", "a.go"}, Output: "fatal: unable to read blob", Err: errors.New("exit status 128")}
+	next, _ := m.Update(diffLoadedMsg{path: "a.go", err: err})
+	m = next.(Model)
+
+	view := ansi.Strip(m.newView.View())
+	for _, want := range []string{"Failed to load diff for a.go", "git diff -U3 -- a.go", "fatal: unable to read blob", "Press F5 to retry."} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("error panel is missing %q:\n%s", want, view)
+		}
+	}
+
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyF5})
+	m = next.(Model)
+	if cmd == nil || !m.loadingDiff || m.deltaMode {
+		t.Fatalf("expected F5 to retry the diff load")
+	}
+	next, _ = m.Update(cmd())
+	m = next.(Model)
+	if m.diffErr != nil || len(m.diffRows) == 0 {
+		t.Fatalf("expected the retried diff to load, got err %v", m.diffErr)
+	}
+}
+
+func TestFailedFileListShowsErrorPanel(t *testing.T) {
+	m := Model{
+		keys:      defaultKeyMap(),
+		focus:     focusFiles,
+		statusSvc: stubStatusService{items: []gitint.FileItem{{Path: "a.go", Status: ".M"}}},
+		diffSvc:   stubDiffService{},
+		oldView:   viewport.New(60, 20),
+		newView:   viewport.New(60, 20),
+		width:     100,
+		height:    30,
+	}
+	next, _ := m.Update(filesLoadedMsg{err: errors.New("not a git repository")})
+	m = next.(Model)
+	if view := ansi.Strip(m.newView.View()); !strings.Contains(view, "Failed to list changed files") || !strings.Contains(view, "not a git repository") {
+		t.Fatalf("expected the error panel in the diff pane:\n%s", view)
+	}
+	if pane := ansi.Strip(m.renderFilesPane(40, 20)); !strings.Contains(pane, "Press F5 to retry.") {
+		t.Fatalf("expected the files pane to point at the retry:\n%s", pane)
+	}
+
+	// R stays the delta-mode key while the error shows.
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("R")})
+	m = next.(Model)
+	if m.loadingFiles {
+		t.Fatalf("expected R not to retry the load")
+	}
+
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyF5})
+	m = next.(Model)
+	if cmd == nil || !m.loadingFiles {
+		t.Fatalf("expected F5 to list the files again")
+	}
+	next, _ = m.Update(filesLoadedFrom(t, cmd))
+	if got := next.(Model); got.filesErr != nil || len(got.fileItems) != 1 {
+		t.Fatalf("expected the retried listing to load, got err %v", got.filesErr)
+	}
+}
@@ -0,0 +1,179 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"diffman/internal/comments"
+)
+
+func TestExportToFileWritesNonStaleComments(t *testing.T) {
+	root := t.TempDir()
+	kept := comments.Comment{Path: "a.go", Side: comments.SideNew, Line: 3, Body: "keep me"}
+	stale := comments.Comment{Path: "b.go", Side: comments.SideNew, Line: 1, Body: "stale"}
+	m := Model{
+		keys:             defaultKeyMap(),
+		cwd:              root,
+		focus:            focusComments,
+		exportInputModel: newExportInput(),
+		comments: map[string]comments.Comment{
+			commentKey(kept):  kept,
+			commentKey(stale): stale,
+		},
+		commentStale: map[string]bool{commentKey(stale): true},
+	}
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("W")})
+	m = next.(Model)
+	if !m.exportInputActive {
+		t.Fatalf("expected export dock to open")
+	}
+	m.exportInputModel.SetValue("out/review.txt")
+	if err := os.MkdirAll(filepath.Join(root, "out"), 0o755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(Model)
+	if m.exportInputActive || cmd == nil {
+		t.Fatalf("expected enter to close the dock and start the export")
+	}
+	msg := cmd().(exportFileResultMsg)
+	if msg.err != nil || len(msg.exported) != 1 {
+		t.Fatalf("unexpected export result: %+v", msg)
+	}
+	data, err := os.ReadFile(filepath.Join(root, "out", "review.txt"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if !strings.Contains(string(data), "a.go new:3: keep me") || strings.Contains(string(data), "stale") {
+		t.Fatalf("unexpected export contents:\n%s", data)
+	}
+	if m.exportPath != "out/review.txt" {
+		t.Fatalf("expected export path remembered, got %q", m.exportPath)
+	}
+}
+
+func TestResolveExportPath(t *testing.T) {
+	if got, _ := resolveExportPath("/repo", "notes/r.txt"); got != filepath.Join("/repo", "notes", "r.txt") {
+		t.Fatalf("expected path relative to repo root, got %q", got)
+	}
+	if got, _ := resolveExportPath("/repo", "/tmp/r.txt"); got != "/tmp/r.txt" {
+		t.Fatalf("expected absolute path kept, got %q", got)
+	}
+	if _, err := resolveExportPath("/repo", "  "); err == nil {
+		t.Fatalf("expected error for empty path")
+	}
+}
+
+func TestExportToFileQuickfixFormat(t *testing.T) {
+	root := t.TempDir()
+	added := comments.Comment{Path: "a.go", Side: comments.SideNew, Line: 3, Body: "issue: check err\nhere"}
+	removed := comments.Comment{Path: "b.go", Side: comments.SideOld, Line: 7, Body: "why drop this?"}
+	m := Model{
+		keys:             defaultKeyMap(),
+		cwd:              root,
+		focus:            focusComments,
+		exportInputModel: newExportInput(),
+		comments: map[string]comments.Comment{
+			commentKey(added):   added,
+			commentKey(removed): removed,
+		},
+	}
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("W")})
+	m = next.(Model)
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m = next.(Model)
+	if m.exportFormat != ExportFormatQuickfix || m.exportInputModel.Placeholder != defaultQuickfixFile {
+		t.Fatalf("expected tab to switch to quickfix, got %q (%q)", m.exportFormat, m.exportInputModel.Placeholder)
+	}
+
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(Model)
+	if cmd == nil {
+		t.Fatalf("expected enter to start the export")
+	}
+	if msg := cmd().(exportFileResultMsg); msg.err != nil {
+		t.Fatalf("export error = %v", msg.err)
+	}
+	data, err := os.ReadFile(filepath.Join(root, defaultQuickfixFile))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	want := "a.go:3:1: issue: check err / here\nb.go:7:1: (old) why drop this?\n"
+	if string(data) != want {
+		t.Fatalf("unexpected quickfix export:\n%q\nwant\n%q", data, want)
+	}
+}
+
+func TestRedactedExportsKeepOnlyLocationAndBody(t *testing.T) {
+	c := comments.Comment{Path: "a.go", Side: comments.SideNew, Line: 3, Body: "why?", ContextBefore: []string{"secret := 1"}, ContextAfter: []string{"return secret"}}
+	m := Model{comments: map[string]comments.Comment{commentKey(c): c}, redactExports: true}
+
+	snapshot, _ := m.exportScope()
+	text := formatExport(ExportFormatPlain, defaultExportTitle, snapshot, comments.ExportOptions{})
+	if strings.Contains(text, "secret") || !strings.Contains(text, "a.go new:3: why?") {
+		t.Fatalf("unexpected redacted export:\n%s", text)
+	}
+	if len(m.comments[commentKey(c)].ContextBefore) != 1 {
+		t.Fatalf("expected the stored comment to keep its context")
+	}
+}
+
+func TestExportScopeNumbersCommentsInGroupOrder(t *testing.T) {
+	nit := comments.Comment{Path: "a.go", Side: comments.SideNew, Line: 1, Body: "nit: name"}
+	blocker := comments.Comment{Path: "b.go", Side: comments.SideNew, Line: 2, Body: "blocker: nil deref"}
+	m := Model{
+		comments:    map[string]comments.Comment{commentKey(nit): nit, commentKey(blocker): blocker},
+		exportGroup: comments.ExportGroupSeverity,
+	}
+
+	snapshot, _ := m.exportScope()
+	if len(snapshot) != 2 || snapshot[0].Path != "b.go" {
+		t.Fatalf("expected the blocker first, got %+v", snapshot)
+	}
+	text := formatExport(ExportFormatPlain, defaultExportTitle, snapshot, m.exportOptions())
+	if !strings.Contains(text, "Blockers:\n1) b.go new:2") || !strings.Contains(text, "Nits:\n2) a.go new:1") {
+		t.Fatalf("unexpected grouped export:\n%s", text)
+	}
+}
+
+func TestExportFrameFillsTitleAndFooter(t *testing.T) {
+	root := t.TempDir()
+	gitCmd(t, root, "init", "-q", "-b", "feature/login")
+	gitCmd(t, root, "config", "user.name", "Ada Lovelace")
+	c := comments.Comment{Path: "a.go", Side: comments.SideNew, Line: 3, Body: "keep me"}
+	m := Model{
+		keys:             defaultKeyMap(),
+		cwd:              root,
+		focus:            focusComments,
+		exportInputModel: newExportInput(),
+		comments:         map[string]comments.Comment{commentKey(c): c},
+		exportFrame:      exportFrame{title: "Review of {branch}", footer: "-- {name}, {date}"},
+	}
+
+	m = pressKeys(t, m, "W")
+	m.exportInputModel.SetValue("review.txt")
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(Model)
+	if msg := cmd().(exportFileResultMsg); msg.err != nil {
+		t.Fatalf("unexpected export error: %v", msg.err)
+	}
+	data, err := os.ReadFile(filepath.Join(root, "review.txt"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	text := string(data)
+	if !strings.HasPrefix(text, "Review of feature/login:\n") {
+		t.Fatalf("expected the configured title, got:\n%s", text)
+	}
+	if !strings.HasSuffix(text, "\n\n-- Ada Lovelace, "+time.Now().Format("2006-01-02")+"\n") {
+		t.Fatalf("expected the footer at the end, got:\n%s", text)
+	}
+}
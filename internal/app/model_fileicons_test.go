@@ -0,0 +1,36 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+
+	"diffman/internal/diffview"
+	gitint "diffman/internal/git"
+)
+
+func TestFileIconsFallBackToASCII(t *testing.T) {
+	m := Model{
+		keys:      defaultKeyMap(),
+		fileItems: []gitint.FileItem{{Path: "main.go", Status: "M."}, {Path: "Makefile", Status: "M."}},
+		width:     100,
+		height:    30,
+	}
+	glyph := fileIconFor("main.go", true)
+	if view := ansi.Strip(m.renderFilesPane(40, 20)); strings.Contains(view, glyph) || !strings.Contains(view, "go main.go") || !strings.Contains(view, "mk Makefile") {
+		t.Fatalf("expected ASCII markers without icons:\n%s", view)
+	}
+
+	m.fileIcons = true
+	if view := ansi.Strip(m.renderFilesPane(40, 20)); !strings.Contains(view, glyph+" main.go") {
+		t.Fatalf("expected an icon before main.go:\n%s", view)
+	}
+
+	diffview.SetPlainMode(true)
+	defer diffview.SetPlainMode(false)
+	view := ansi.Strip(m.renderFilesPane(40, 20))
+	if !strings.Contains(view, "go main.go") || !strings.Contains(view, "mk Makefile") {
+		t.Fatalf("expected ASCII markers in plain mode:\n%s", view)
+	}
+}
@@ -0,0 +1,57 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+
+	"diffman/internal/comments"
+	gitint "diffman/internal/git"
+)
+
+func filePaneLine(t *testing.T, view, name string) string {
+	t.Helper()
+	for _, line := range strings.Split(view, "\n") {
+		if strings.Contains(line, name) {
+			return line
+		}
+	}
+	t.Fatalf("no line for %s in:\n%s", name, view)
+	return ""
+}
+
+func TestFilePaneAlignsMetadataColumns(t *testing.T) {
+	m := Model{
+		keys: defaultKeyMap(),
+		fileItems: []gitint.FileItem{
+			{Path: "a.go", Status: "M."},
+			{Path: "internal_name.go", Status: "M."},
+		},
+		fileStats: map[string]gitint.FileStat{
+			"a.go":             {Added: 3, Deleted: 1},
+			"internal_name.go": {Added: 10, Deleted: 2},
+		},
+		comments: map[string]comments.Comment{
+			"1": {Path: "a.go", Line: 1},
+			"2": {Path: "a.go", Line: 2},
+		},
+		width:  100,
+		height: 30,
+	}
+
+	view := ansi.Strip(m.renderFilesPane(40, 20))
+	short, long := filePaneLine(t, view, "a.go"), filePaneLine(t, view, "internal_name.go")
+	if !strings.Contains(short, "+3 -1 ✎2") || !strings.Contains(long, "+10 -2") {
+		t.Fatalf("expected diffstat and comment count columns:\n%s", view)
+	}
+	if strings.Index(short, "-1") != strings.Index(long, "-2") {
+		t.Fatalf("expected the diffstat column to be right-aligned:\n%s", view)
+	}
+
+	view = ansi.Strip(m.renderFilesPane(24, 20))
+	short = filePaneLine(t, view, "a.go")
+	if strings.Contains(short, "+3") || !strings.Contains(short, "✎2") {
+		t.Fatalf("expected a narrow pane to drop the diffstat but keep the count:\n%s", view)
+	}
+}
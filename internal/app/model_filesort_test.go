@@ -0,0 +1,89 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"diffman/internal/comments"
+	gitint "diffman/internal/git"
+)
+
+func fileEntryPaths(m Model) []string {
+	var out []string
+	for _, e := range m.fileTreeEntries() {
+		out = append(out, e.Path)
+	}
+	return out
+}
+
+func TestFileSortCyclesThroughModes(t *testing.T) {
+	dir := t.TempDir()
+	m := Model{
+		keys:  defaultKeyMap(),
+		focus: focusFiles,
+		cwd:   dir,
+		fileItems: []gitint.FileItem{
+			{Path: "a.go", Status: "??"},
+			{Path: "b.go", Status: "M."},
+			{Path: "lib/c.go", Status: "A."},
+		},
+		fileStats: map[string]gitint.FileStat{
+			"a.go":     {Added: 1},
+			"b.go":     {Added: 2, Deleted: 2},
+			"lib/c.go": {Added: 40},
+		},
+		comments: map[string]comments.Comment{
+			"1": {Path: "b.go", Line: 1},
+		},
+		selectedF: "b.go",
+		width:     100,
+		height:    30,
+	}
+	now := time.Now()
+	for i, path := range []string{"b.go", "a.go", "lib/c.go"} {
+		full := filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		mtime := now.Add(-time.Duration(i) * time.Hour)
+		if err := os.Chtimes(full, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m.syncFileCursorToSelectedPath()
+
+	want := map[fileSortMode][]string{
+		fileSortByPath:     {"lib", "lib/c.go", "a.go", "b.go"},
+		fileSortByStatus:   {"b.go", "lib", "lib/c.go", "a.go"},
+		fileSortBySize:     {"lib", "lib/c.go", "b.go", "a.go"},
+		fileSortByModified: {"b.go", "a.go", "lib", "lib/c.go"},
+		fileSortByComments: {"b.go", "lib", "lib/c.go", "a.go"},
+	}
+	for mode := fileSortByPath; mode < fileSortModes; mode++ {
+		if got := fileEntryPaths(m); !slices.Equal(got, want[mode]) {
+			t.Fatalf("sorted by %s: got %v, want %v", mode, got, want[mode])
+		}
+		if entries := m.fileTreeEntries(); entries[m.fileCursor].Path != "b.go" {
+			t.Fatalf("sorted by %s: cursor on %s, want the selected b.go", mode, entries[m.fileCursor].Path)
+		}
+		m = pressKeys(t, m, "o")
+	}
+	if m.fileSort != fileSortByPath {
+		t.Fatalf("expected the sort to wrap around to path, got %s", m.fileSort)
+	}
+}
+
+func TestFileSortSkipsModifiedInPRMode(t *testing.T) {
+	m := Model{keys: defaultKeyMap(), focus: focusFiles, reviewMode: reviewModePR, fileSort: fileSortBySize}
+	m = pressKeys(t, m, "o")
+	if m.fileSort != fileSortByComments {
+		t.Fatalf("got %s, want comments", m.fileSort)
+	}
+}
@@ -0,0 +1,91 @@
+package app
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+
+	gitint "diffman/internal/git"
+)
+
+// filesLoadedFrom runs cmd, which may batch other loads with the file list,
+// and returns its filesLoadedMsg.
+func filesLoadedFrom(t *testing.T, cmd tea.Cmd) filesLoadedMsg {
+	t.Helper()
+	msgs := []tea.Msg{cmd()}
+	for len(msgs) > 0 {
+		msg := msgs[0]
+		msgs = msgs[1:]
+		switch msg := msg.(type) {
+		case filesLoadedMsg:
+			return msg
+		case tea.BatchMsg:
+			for _, c := range msg {
+				if c != nil {
+					msgs = append(msgs, c())
+				}
+			}
+		}
+	}
+	t.Fatalf("command did not load files")
+	return filesLoadedMsg{}
+}
+
+func TestFileTreeShowsBeforeLineCountsArrive(t *testing.T) {
+	items := []gitint.FileItem{{Path: "a.go", Status: "M."}, {Path: "logo.png", Status: "M."}}
+	m := Model{
+		keys:  defaultKeyMap(),
+		focus: focusFiles,
+		statusSvc: stubStatusService{items: items, stats: map[string]gitint.FileStat{
+			"a.go":     {Added: 3, Deleted: 1},
+			"logo.png": {Binary: true},
+		}},
+		diffSvc: stubDiffService{},
+		oldView: viewport.New(1, 1),
+		newView: viewport.New(1, 1),
+		width:   100,
+		height:  30,
+	}
+
+	batch, ok := m.loadFilesCmd()().(tea.BatchMsg)
+	if !ok || len(batch) != 2 {
+		t.Fatalf("expected status and line counts to load concurrently, got %T", batch)
+	}
+	var stats tea.Msg
+	for _, c := range batch {
+		msg := c()
+		if loaded, ok := msg.(filesLoadedMsg); ok {
+			next, _ := m.Update(loaded)
+			m = next.(Model)
+			continue
+		}
+		stats = msg
+	}
+
+	view := ansi.Strip(m.renderFilesPane(60, 20))
+	if !strings.Contains(view, "a.go") || strings.Contains(view, "+3") {
+		t.Fatalf("expected the tree without counts first:\n%s", view)
+	}
+
+	next, _ := m.Update(stats)
+	m = next.(Model)
+	view = ansi.Strip(m.renderFilesPane(60, 20))
+	if !regexp.MustCompile(`a\.go +\+3 -1`).MatchString(view) || !regexp.MustCompile(`logo\.png +bin`).MatchString(view) {
+		t.Fatalf("expected line counts in the tree:\n%s", view)
+	}
+}
+
+func TestLineCountsForAnotherDiffModeAreDropped(t *testing.T) {
+	m := Model{diffMode: gitint.DiffModeStaged}
+	next, _ := m.Update(fileStatsLoadedMsg{
+		mode:  gitint.DiffModeAll,
+		stats: map[string]gitint.FileStat{"a.go": {Added: 1}},
+	})
+	if got := next.(Model).fileStats; got != nil {
+		t.Fatalf("expected counts for another mode to be dropped, got %+v", got)
+	}
+}
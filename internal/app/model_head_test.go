@@ -0,0 +1,36 @@
+package app
+
+import (
+	"slices"
+	"testing"
+
+	gitint "diffman/internal/git"
+)
+
+func TestStatusBarDescribesDetachedAndUnbornHead(t *testing.T) {
+	m := Model{cwd: "/src/repo", head: gitint.HeadInfo{ShortSHA: "abc1234", Detached: true}}
+	if got := m.statusBarSegments(); !slices.Contains(got, "detached HEAD") || !slices.Contains(got, "abc1234") {
+		t.Fatalf("expected a detached HEAD segment, got %q", got)
+	}
+
+	m.head = gitint.HeadInfo{Branch: "main", Unborn: true}
+	if got := m.statusBarSegments(); !slices.Contains(got, "main") || !slices.Contains(got, "no commits yet") {
+		t.Fatalf("expected an unborn branch segment, got %q", got)
+	}
+}
+
+func TestStatusBarListsActiveFilters(t *testing.T) {
+	m := Model{cwd: "/src/repo", head: gitint.HeadInfo{Branch: "main", ShortSHA: "abc1234"}}
+	if got := m.filterStatusSegment(); got != "" {
+		t.Fatalf("expected no filter segment, got %q", got)
+	}
+
+	m.scope = []string{"internal/app"}
+	m.excludes = []string{"vendor/", "*.lock"}
+	m.hideIndexFlagged = true
+	m.commentsFilter = " todo "
+	want := "paths: internal/app, exclude: vendor/ *.lock, index-flagged hidden, comments: /todo"
+	if got := m.statusBarSegments(); !slices.Contains(got, want) {
+		t.Fatalf("expected the filter segment %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,102 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"diffman/internal/comments"
+	"diffman/internal/githubpr"
+	"diffman/internal/history"
+)
+
+func TestArchiveAndReopenReviewReadOnly(t *testing.T) {
+	root := t.TempDir()
+	c := comments.Comment{Path: "a.go", Side: comments.SideNew, Line: 3, Body: "rename this"}
+	m := Model{
+		keys:         defaultKeyMap(),
+		cwd:          root,
+		height:       40,
+		width:        100,
+		reviewMode:   reviewModePR,
+		prCtx:        &githubpr.Context{Owner: "acme", Repo: "widgets", Number: 5, HeadSHA: "abc", BaseSHA: "def"},
+		historyStore: history.NewStore(t.TempDir()),
+		comments:     map[string]comments.Comment{commentKey(c): c},
+	}
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("A")})
+	m = next.(Model)
+	if !strings.HasPrefix(m.alertMsg, "Archived 1 comment(s)") {
+		t.Fatalf("unexpected alert %q", m.alertMsg)
+	}
+	if !m.statsOpen {
+		t.Fatalf("expected review statistics after archiving")
+	}
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = next.(Model)
+
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("H")})
+	m = next.(Model)
+	if !m.historyOpen || len(m.historyItems) != 1 {
+		t.Fatalf("expected history with one review, got %+v", m.historyItems)
+	}
+	r := m.historyItems[0]
+	if r.PR == nil || r.PR.Number != 5 || r.BaseSHA != "def" || r.HeadSHA != "abc" {
+		t.Fatalf("unexpected archived metadata: %+v", r)
+	}
+
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(Model)
+	if !m.historyViewing || !strings.Contains(m.renderHistoryModal(), "rename this") {
+		t.Fatalf("expected archived review to open read-only")
+	}
+	// Editing keys do nothing while viewing the archive.
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	m = next.(Model)
+	if len(m.comments) != 1 || !m.historyViewing {
+		t.Fatalf("expected archive view to be read-only")
+	}
+
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("W")})
+	m = next.(Model)
+	msg := cmd().(exportFileResultMsg)
+	if msg.err != nil || filepath.Dir(msg.path) != root {
+		t.Fatalf("unexpected export result %+v", msg)
+	}
+	data, err := os.ReadFile(msg.path)
+	if err != nil || !strings.Contains(string(data), "a.go new:3: rename this") || !strings.Contains(string(data), "PR #5") {
+		t.Fatalf("unexpected archived export %q (err=%v)", data, err)
+	}
+
+	// Writing it again keeps the first file and picks a new name.
+	again := cmd().(exportFileResultMsg)
+	if again.err != nil || again.path == msg.path || !strings.HasSuffix(again.path, "-2.txt") {
+		t.Fatalf("expected a second file next to %s, got %+v", msg.path, again)
+	}
+	if kept, err := os.ReadFile(msg.path); err != nil || string(kept) != string(data) {
+		t.Fatalf("expected the first export left alone, got %q (err=%v)", kept, err)
+	}
+}
+
+func TestHistorySkipsUnreadableArchives(t *testing.T) {
+	gitDir := t.TempDir()
+	store := history.NewStore(gitDir)
+	if _, err := store.Save(history.Review{Outcome: "archived"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(gitDir, ".diffman", "history", "broken.json"), []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m := Model{keys: defaultKeyMap(), historyStore: store, height: 40, width: 100}
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("H")})
+	m = next.(Model)
+	if !m.historyOpen || len(m.historyItems) != 1 {
+		t.Fatalf("expected the readable archive listed, got open=%v %+v", m.historyOpen, m.historyItems)
+	}
+	if !strings.Contains(m.alertMsg, "skipped 1 unreadable archive(s)") || !strings.Contains(m.alertMsg, "broken.json") {
+		t.Fatalf("expected the broken archive reported, got %q", m.alertMsg)
+	}
+}
@@ -0,0 +1,79 @@
+package app
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"diffman/internal/comments"
+	"diffman/internal/config"
+)
+
+func TestDeleteCommentRunsHookWithJSONPayload(t *testing.T) {
+	root := t.TempDir()
+	out := filepath.Join(root, "hook.json")
+	c := comments.Comment{Path: "a.go", Side: comments.SideNew, Line: 4, Body: "drop this"}
+	m := Model{
+		keys:         defaultKeyMap(),
+		focus:        focusComments,
+		cwd:          root,
+		commentStore: comments.NewStore(t.TempDir()),
+		comments:     map[string]comments.Comment{commentKey(c): c},
+		commentStale: map[string]bool{},
+		hooks:        map[string]string{config.HookCommentDelete: "cat > hook.json"},
+	}
+	m.commentsCursor = 1
+
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	m = next.(Model)
+	if cmd == nil {
+		t.Fatalf("expected delete to start the hook")
+	}
+	if res := cmd().(hookResultMsg); res.err != nil {
+		t.Fatalf("hook error = %v", res.err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	var payload hookPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		t.Fatalf("hook payload is not JSON: %v\n%s", err, data)
+	}
+	if payload.Event != config.HookCommentDelete || payload.Repo != root || len(payload.Comments) != 1 || payload.Comments[0].Body != "drop this" {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+}
+
+func TestHookFailureRaisesAlert(t *testing.T) {
+	m := Model{cwd: t.TempDir(), hooks: map[string]string{config.HookExport: "exit 3"}}
+	cmd := m.hookCmd(hookPayload{Event: config.HookExport})
+	next, _ := m.Update(cmd())
+	if got := next.(Model).alertMsg; got == "" {
+		t.Fatalf("expected alert for failing hook")
+	}
+	if (Model{}).hookCmd(hookPayload{Event: config.HookExport}) != nil {
+		t.Fatalf("expected no command without a configured hook")
+	}
+}
+
+func TestHookShellUsesCmdOnWindows(t *testing.T) {
+	t.Setenv("SHELL", "/bin/zsh")
+	name, args := hookShell("windows", "notify.bat")
+	if name != "cmd" || !slices.Equal(args, []string{"/C", "notify.bat"}) {
+		t.Fatalf("hookShell(windows) = %q %q, want cmd /C", name, args)
+	}
+	name, args = hookShell("linux", "notify.sh")
+	if name != "/bin/zsh" || !slices.Equal(args, []string{"-c", "notify.sh"}) {
+		t.Fatalf("hookShell(linux) = %q %q, want $SHELL -c", name, args)
+	}
+	t.Setenv("SHELL", "")
+	if name, _ := hookShell("darwin", "notify.sh"); name != "/bin/sh" {
+		t.Fatalf("hookShell(darwin) without $SHELL = %q, want /bin/sh", name)
+	}
+}
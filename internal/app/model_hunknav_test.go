@@ -0,0 +1,71 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/viewport"
+
+	"diffman/internal/diffview"
+)
+
+func newHunkNavTestModel() Model {
+	m := Model{
+		keys:  defaultKeyMap(),
+		focus: focusDiff,
+		diffRows: []diffview.DiffRow{
+			{Kind: diffview.RowHunkHeader, OldText: "@@ -1,2 +1,2 @@"},
+			{Kind: diffview.RowContext, OldLine: 1, NewLine: 1, OldText: "func a() {", NewText: "func a() {"},
+			{Kind: diffview.RowChange, OldLine: 2, NewLine: 2, OldText: "return 1", NewText: "return 2"},
+			{Kind: diffview.RowHunkHeader, OldText: "@@ -10 +10 @@"},
+			{Kind: diffview.RowChange, OldLine: 10, NewLine: 10, OldText: "\tx := 1", NewText: "    x := 1"},
+			{Kind: diffview.RowHunkHeader, OldText: "@@ -20 +20,2 @@"},
+			{Kind: diffview.RowAdd, NewLine: 20, NewText: "y := 2"},
+		},
+		diffDirty: true,
+	}
+	m.oldView = viewport.New(60, 20)
+	m.newView = viewport.New(60, 20)
+	return m
+}
+
+func TestHunkNavigationWrapsAround(t *testing.T) {
+	m := newHunkNavTestModel()
+
+	for _, want := range []int{2, 4, 6, 2} {
+		m = pressKeys(t, m, "]")
+		if m.diffCursor != want {
+			t.Fatalf("] moved the cursor to %d, want %d", m.diffCursor, want)
+		}
+	}
+	m = pressKeys(t, m, "[")
+	if m.diffCursor != 6 {
+		t.Fatalf("[ from the first hunk moved the cursor to %d, want 6", m.diffCursor)
+	}
+}
+
+func TestHunkNavigationSkipsWhitespaceOnlyHunks(t *testing.T) {
+	m := newHunkNavTestModel()
+	m.skipWhitespaceHunks = true
+
+	m = pressKeys(t, m, "]", "]")
+	if m.diffCursor != 6 {
+		t.Fatalf("expected the reindented hunk to be skipped, cursor at %d", m.diffCursor)
+	}
+
+	m.diffRows = m.diffRows[3:5]
+	m.diffCursor = 0
+	m = pressKeys(t, m, "]")
+	if !strings.Contains(m.alertMsg, "whitespace") {
+		t.Fatalf("expected an alert about whitespace-only hunks, got %q", m.alertMsg)
+	}
+}
+
+func TestRenderBadgesWhitespaceOnlyHunk(t *testing.T) {
+	m := newHunkNavTestModel()
+	m.refreshDiffContent()
+
+	if got := strings.Count(m.oldView.View(), diffview.WhitespaceBadge); got != 1 {
+		t.Fatalf("expected one %s badge, got %d in:\n%s", diffview.WhitespaceBadge, got, m.oldView.View())
+	}
+}
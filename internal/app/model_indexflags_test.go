@@ -0,0 +1,42 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+
+	gitint "diffman/internal/git"
+)
+
+func TestIndexFlaggedFilesAreLabeledAndCanBeHidden(t *testing.T) {
+	m := Model{
+		keys:  defaultKeyMap(),
+		focus: focusFiles,
+		fileItems: []gitint.FileItem{
+			{Path: "a.go", Status: "M."},
+			{Path: "config.local", Status: "M.", SkipWorktree: true},
+		},
+		width:  100,
+		height: 30,
+	}
+	if view := ansi.Strip(m.renderFilesPane(60, 20)); !strings.Contains(view, "config.local (skip-worktree)") {
+		t.Fatalf("files pane does not label the flagged file:\n%s", view)
+	}
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("h"), Alt: true})
+	m = next.(Model)
+	entries := m.fileTreeEntries()
+	if len(entries) != 1 || entries[0].Path != "a.go" {
+		t.Fatalf("entries = %+v, want only a.go", entries)
+	}
+	if !strings.Contains(m.alertMsg, "Hiding 1") {
+		t.Fatalf("alert = %q", m.alertMsg)
+	}
+
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("h"), Alt: true})
+	if got := len(next.(Model).fileTreeEntries()); got != 2 {
+		t.Fatalf("got %d entries after showing them again, want 2", got)
+	}
+}
@@ -0,0 +1,94 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"diffman/internal/comments"
+	"diffman/internal/diffview"
+)
+
+func TestCommentPlacementCyclesAndExpandsCursorRow(t *testing.T) {
+	c := comments.Comment{Path: "a.go", Side: comments.SideNew, Line: 2, Body: "rename this"}
+	m := Model{
+		keys:  defaultKeyMap(),
+		focus: focusDiff,
+		diffRows: []diffview.DiffRow{
+			{Kind: diffview.RowContext, Path: "a.go", OldLine: 1, NewLine: 1, OldText: "same", NewText: "same"},
+			{Kind: diffview.RowAdd, Path: "a.go", NewLine: 2, NewText: "added"},
+		},
+		comments: map[string]comments.Comment{commentKey(c): c},
+	}
+
+	updated, _ := m.updateDiffPane(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")})
+	m = updated.(Model)
+	if !strings.Contains(m.alertMsg, "not collapsed") {
+		t.Fatalf("expected o to explain it needs collapsed comments, got %q", m.alertMsg)
+	}
+
+	for _, want := range []diffview.CommentPlacement{diffview.CommentsAcross, diffview.CommentsCollapsed} {
+		updated, _ = m.updateDiffPane(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c"), Alt: true})
+		m = updated.(Model)
+		if m.commentPlacement != want {
+			t.Fatalf("expected placement %v, got %v", want, m.commentPlacement)
+		}
+	}
+	if !strings.Contains(m.alertMsg, "collapsed") {
+		t.Fatalf("expected the placement in the alert, got %q", m.alertMsg)
+	}
+
+	updated, _ = m.updateDiffPane(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")})
+	m = updated.(Model)
+	if !strings.Contains(m.alertMsg, "No comment") {
+		t.Fatalf("expected a notice on a row without comments, got %q", m.alertMsg)
+	}
+
+	m.diffCursor = 1
+	updated, _ = m.updateDiffPane(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")})
+	m = updated.(Model)
+	if !m.commentExpanded("a.go", 2, diffview.SideNew) {
+		t.Fatalf("expected o to expand the comment on the cursor row")
+	}
+	updated, _ = m.updateDiffPane(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")})
+	m = updated.(Model)
+	if m.commentExpanded("a.go", 2, diffview.SideNew) {
+		t.Fatalf("expected a second o to collapse it again")
+	}
+
+	updated, _ = m.updateDiffPane(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c"), Alt: true})
+	m = updated.(Model)
+	if m.commentPlacement != diffview.CommentsBelowSide {
+		t.Fatalf("expected placement to wrap back to side, got %v", m.commentPlacement)
+	}
+}
+
+func TestHideCommentsKeepsGutterMarkers(t *testing.T) {
+	c := comments.Comment{Path: "a.go", Side: comments.SideNew, Line: 1, Body: "rename this"}
+	m := Model{
+		keys:  defaultKeyMap(),
+		focus: focusDiff,
+		diffRows: []diffview.DiffRow{
+			{Kind: diffview.RowAdd, Path: "a.go", NewLine: 1, NewText: "added"},
+		},
+		comments: map[string]comments.Comment{commentKey(c): c},
+	}
+
+	updated, _ := m.updateDiffPane(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o"), Alt: true})
+	m = updated.(Model)
+	if !m.hideComments || !m.renderOptions().HideComments {
+		t.Fatalf("expected alt+o to hide inline comments")
+	}
+	out := diffview.RenderSplitWithOptions(m.diffRows, 40, 40, -1, m.hasComment, m.commentText, m.renderOptions())
+	if len(out.NewLines) != 1 {
+		t.Fatalf("expected only the code line while comments are hidden, got %d lines", len(out.NewLines))
+	}
+
+	updated, _ = m.updateDiffPane(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o"), Alt: true})
+	m = updated.(Model)
+	out = diffview.RenderSplitWithOptions(m.diffRows, 40, 40, -1, m.hasComment, m.commentText, m.renderOptions())
+	if len(out.NewLines) != 2 {
+		t.Fatalf("expected the comment back under its line, got %d lines", len(out.NewLines))
+	}
+}
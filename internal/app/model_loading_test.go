@@ -0,0 +1,211 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"diffman/internal/comments"
+	gitint "diffman/internal/git"
+)
+
+// blockingDiffService stalls every diff until its context is canceled.
+type blockingDiffService struct{ started chan struct{} }
+
+func (s blockingDiffService) Diff(ctx context.Context, _ string, _ string, _ gitint.DiffMode) (string, error) {
+	close(s.started)
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func (s blockingDiffService) DiffRename(ctx context.Context, cwd, _ string, path string, mode gitint.DiffMode) (string, error) {
+	return s.Diff(ctx, cwd, path, mode)
+}
+
+func (blockingDiffService) Untracked(context.Context, string, string) (gitint.UntrackedFile, bool, error) {
+	return gitint.UntrackedFile{}, false, nil
+}
+
+func TestEscCancelsSlowDiffLoad(t *testing.T) {
+	svc := blockingDiffService{started: make(chan struct{})}
+	m := Model{
+		keys:        defaultKeyMap(),
+		comments:    map[string]comments.Comment{},
+		diffSvc:     svc,
+		diffLoads:   &loadCanceler{},
+		selectedF:   "slow.go",
+		loadingDiff: true,
+		oldView:     viewport.New(80, 5),
+		newView:     viewport.New(80, 5),
+	}
+	cmd := m.loadDiffCmd("slow.go")
+	msgs := make(chan tea.Msg, 1)
+	go func() { msgs <- cmd() }()
+	<-svc.started
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = next.(Model)
+	if m.loadingDiff || !strings.Contains(m.newView.View(), "canceled") {
+		t.Fatalf("expected the load to be canceled, got loading=%v view=%q", m.loadingDiff, m.newView.View())
+	}
+
+	select {
+	case msg := <-msgs:
+		loaded, ok := msg.(diffLoadedMsg)
+		if !ok || !loaded.canceled {
+			t.Fatalf("expected a canceled diffLoadedMsg, got %#v", msg)
+		}
+		next, _ = m.Update(loaded)
+		if after := next.(Model); after.err != nil || !strings.Contains(after.newView.View(), "canceled") {
+			t.Fatalf("expected the canceled result to be ignored, got err=%v", after.err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("diff load did not stop after cancel")
+	}
+
+	// With nothing in flight Esc is not swallowed by the loader.
+	if m.cancelDiffLoad() {
+		t.Fatalf("expected no load left to cancel")
+	}
+}
+
+func TestLoadCancelerReleaseKeepsNewerLoad(t *testing.T) {
+	var l loadCanceler
+	first, _, releaseFirst := l.begin()
+	second, _, releaseSecond := l.begin()
+	releaseFirst()
+	if first.Err() == nil {
+		t.Fatalf("expected release to end the first load's context")
+	}
+	if !l.stop() || second.Err() == nil {
+		t.Fatalf("expected stop to cancel the newer load")
+	}
+	releaseSecond()
+	if l.stop() {
+		t.Fatalf("expected nothing left to stop")
+	}
+}
+
+func TestSpinnerTicksOnlyWhileLoading(t *testing.T) {
+	m := Model{keys: defaultKeyMap(), comments: map[string]comments.Comment{}, spinner: newLoadingSpinner()}
+	loading := m
+	loading.loadingFiles = true
+	got, cmd := loading.trackLoading(m, nil)
+	if !got.spinning || cmd == nil || got.filesLoadStart.IsZero() {
+		t.Fatalf("expected the spinner to start with the load, got spinning=%v cmd=%v", got.spinning, cmd != nil)
+	}
+	if _, again := got.trackLoading(got, nil); again != nil {
+		t.Fatalf("expected a single tick chain")
+	}
+
+	next, cmd := got.Update(spinner.TickMsg{})
+	if cmd == nil {
+		t.Fatalf("expected the spinner to keep ticking while loading")
+	}
+	done := next.(Model)
+	done.loadingFiles = false
+	next, cmd = done.Update(spinner.TickMsg{})
+	if cmd != nil || next.(Model).spinning {
+		t.Fatalf("expected the spinner to stop once nothing loads")
+	}
+}
+
+func TestLoadingSuffixShowsElapsedTimeAndCancelHint(t *testing.T) {
+	m := Model{spinner: newLoadingSpinner()}
+	if got := m.loadingSuffix(time.Now(), true); strings.Contains(got, "esc") || !strings.Contains(got, "loading") {
+		t.Fatalf("expected a bare spinner at first, got %q", got)
+	}
+	got := m.loadingSuffix(time.Now().Add(-3*time.Second), true)
+	if !strings.Contains(got, "3s") || !strings.Contains(got, "esc cancels") {
+		t.Fatalf("expected elapsed time and cancel hint, got %q", got)
+	}
+	if got := m.loadingSuffix(time.Now().Add(-3*time.Second), false); strings.Contains(got, "esc") {
+		t.Fatalf("expected no cancel hint for loads that cannot be canceled, got %q", got)
+	}
+}
+
+func TestScrubbingFilesLoadsOnlyTheFileTheCursorRestsOn(t *testing.T) {
+	m := Model{
+		keys:      defaultKeyMap(),
+		comments:  map[string]comments.Comment{},
+		diffSvc:   stubDiffService{},
+		diffLoads: &loadCanceler{},
+		focus:     focusFiles,
+		fileItems: []gitint.FileItem{{Path: "a.go", Status: "M"}, {Path: "b.go", Status: "M"}, {Path: "c.go", Status: "M"}},
+	}
+
+	var ticks []tea.Cmd
+	for range 2 {
+		next, cmd := m.updateFilesPane(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+		m = next.(Model)
+		ticks = append(ticks, cmd)
+	}
+	if m.selectedF != "c.go" || !m.loadingDiff || !m.diffLoadPending {
+		t.Fatalf("expected c.go selected with its load pending, got %q loading=%v", m.selectedF, m.loadingDiff)
+	}
+
+	due := ticks[0]().(diffLoadDueMsg)
+	if _, cmd := m.handleDiffLoadDue(due); cmd != nil {
+		t.Fatalf("expected the load for %s to be dropped after the cursor moved on", due.path)
+	}
+	due = ticks[1]().(diffLoadDueMsg)
+	next, cmd := m.handleDiffLoadDue(due)
+	m = next.(Model)
+	if due.path != "c.go" || cmd == nil || m.diffLoadPending {
+		t.Fatalf("expected the resting file's diff to load, got %q cmd=%v", due.path, cmd != nil)
+	}
+	if loaded, ok := cmd().(diffLoadedMsg); !ok || loaded.path != "c.go" {
+		t.Fatalf("expected the diff of c.go, got %#v", loaded)
+	}
+}
+
+func TestNewDiffLoadCancelsTheOneItSupersedes(t *testing.T) {
+	loads := &loadCanceler{}
+	first, firstGen, done := loads.begin()
+	defer done()
+	_, secondGen, done2 := loads.begin()
+	defer done2()
+	select {
+	case <-first.Done():
+	case <-time.After(time.Second):
+		t.Fatalf("expected the superseded load to be canceled")
+	}
+	if loads.current(firstGen) || !loads.current(secondGen) {
+		t.Fatalf("expected only the newer load to be current")
+	}
+}
+
+func TestLateDiffResultForSupersededLoadIsDropped(t *testing.T) {
+	svc := stubDiffService{diffs: map[string]string{
+		"a.go": "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1 +1 @@\n-old a\n+new a\n",
+		"b.go": "diff --git a/b.go b/b.go\n--- a/b.go\n+++ b/b.go\n@@ -1 +1 @@\n-old b\n+new b\n",
+	}}
+	m := Model{
+		keys:        defaultKeyMap(),
+		comments:    map[string]comments.Comment{},
+		diffSvc:     svc,
+		diffLoads:   &loadCanceler{},
+		selectedF:   "b.go",
+		loadingDiff: true,
+		oldView:     viewport.New(80, 5),
+		newView:     viewport.New(80, 5),
+	}
+	// a.go's diff is done before b.go is selected, but its result is only
+	// handled after b.go's.
+	lateA := m.loadDiffCmd("a.go")().(diffLoadedMsg)
+	if lateA.canceled {
+		t.Fatalf("expected a.go's load to finish")
+	}
+	next, _ := m.Update(m.loadDiffCmd("b.go")())
+	m = next.(Model)
+	next, _ = m.Update(lateA)
+	m = next.(Model)
+	if len(m.diffRows) == 0 || m.diffRows[len(m.diffRows)-1].NewText != "new b" {
+		t.Fatalf("expected b.go's diff to stay, got %+v", m.diffRows)
+	}
+}
@@ -0,0 +1,44 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"diffman/internal/diffview"
+)
+
+func TestExpandLineTogglesTruncatedCursorRow(t *testing.T) {
+	m := Model{
+		keys:           defaultKeyMap(),
+		focus:          focusDiff,
+		maxLineColumns: 10,
+		diffRows: []diffview.DiffRow{
+			{Kind: diffview.RowContext, Path: "a.js", OldLine: 1, NewLine: 1, OldText: "short", NewText: "short"},
+			{Kind: diffview.RowAdd, Path: "a.js", NewLine: 2, NewText: strings.Repeat("a", 30)},
+		},
+	}
+
+	updated, _ := m.updateDiffPane(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("E")})
+	m = updated.(Model)
+	if !strings.Contains(m.alertMsg, "not truncated") {
+		t.Fatalf("expected a notice on a short line, got %q", m.alertMsg)
+	}
+
+	m.diffCursor = 1
+	updated, _ = m.updateDiffPane(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("E")})
+	m = updated.(Model)
+	if !m.lineExpanded(m.diffRows[1]) {
+		t.Fatalf("expected E to expand the cursor row")
+	}
+	if m.renderOptions().LineExpanded == nil || m.renderOptions().MaxLineColumns != 10 {
+		t.Fatalf("expected the render options to carry the limit and expanded rows")
+	}
+
+	updated, _ = m.updateDiffPane(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("E")})
+	m = updated.(Model)
+	if m.lineExpanded(m.diffRows[1]) {
+		t.Fatalf("expected a second E to truncate the line again")
+	}
+}
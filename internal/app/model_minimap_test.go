@@ -0,0 +1,45 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/bubbles/viewport"
+
+	"diffman/internal/comments"
+	"diffman/internal/diffview"
+)
+
+func TestMinimapCellsMarkChangesCommentsAndViewport(t *testing.T) {
+	m := Model{
+		diffRows: []diffview.DiffRow{
+			{Kind: diffview.RowContext, Path: "a.go", OldLine: 1, NewLine: 1},
+			{Kind: diffview.RowAdd, Path: "a.go", NewLine: 2},
+			{Kind: diffview.RowDelete, Path: "a.go", OldLine: 2},
+			{Kind: diffview.RowContext, Path: "a.go", OldLine: 3, NewLine: 3},
+		},
+		rowStarts:  []int{0, 1, 2, 3},
+		rowHeights: []int{1, 1, 1, 1},
+		comments: map[string]comments.Comment{
+			comments.AnchorKey("a.go", comments.SideNew, 3): {Path: "a.go", Side: comments.SideNew, Line: 3, Body: "x"},
+		},
+	}
+	m.newView = viewport.New(40, 2)
+
+	cells := m.minimapCells(4)
+	want := []minimapMark{minimapMarkNone, minimapMarkAdd, minimapMarkDelete, minimapMarkComment}
+	for i, mark := range want {
+		if cells[i].mark != mark {
+			t.Fatalf("cell %d mark=%v want %v", i, cells[i].mark, mark)
+		}
+	}
+	if !cells[0].inView || !cells[1].inView || cells[2].inView || cells[3].inView {
+		t.Fatalf("unexpected viewport cells: %+v", cells)
+	}
+
+	m.newView.SetContent("1\n2\n3\n4")
+	m.newView.SetYOffset(2)
+	cells = m.minimapCells(2)
+	if cells[0].inView || !cells[1].inView {
+		t.Fatalf("expected viewport band to follow scroll offset: %+v", cells)
+	}
+}
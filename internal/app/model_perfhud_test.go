@@ -0,0 +1,46 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"diffman/internal/comments"
+)
+
+func TestF12TogglesPerformanceOverlay(t *testing.T) {
+	m := Model{keys: defaultKeyMap(), comments: map[string]comments.Comment{}, perf: &perfStats{}, pprofAddr: "127.0.0.1:6060"}
+	m.perf.timeFrame(time.Now().Add(-40 * time.Millisecond))
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyF12})
+	m = next.(Model)
+	if !m.perfHUD {
+		t.Fatalf("expected F12 to open the overlay")
+	}
+	if stats := m.perf.snapshot(); stats.slowest != 0 || stats.frames != 0 {
+		t.Fatalf("expected frame counters reset on open, got slowest=%v frames=%d", stats.slowest, stats.frames)
+	}
+
+	m.perf.timeFrame(time.Now().Add(-12 * time.Millisecond))
+	m.perf.recordRender(3*time.Millisecond, 120, 150)
+	hud := m.renderPerfHUD()
+	for _, want := range []string{"frame  12.", "rows   120 -> 150 lines", "git", "http://127.0.0.1:6060/debug/pprof/"} {
+		if !strings.Contains(hud, want) {
+			t.Fatalf("expected %q in overlay, got:\n%s", want, hud)
+		}
+	}
+
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyF12})
+	if next.(Model).perfHUD {
+		t.Fatalf("expected F12 to close the overlay")
+	}
+}
+
+func TestOverlayAtPlacesBoxAtOffset(t *testing.T) {
+	got := overlayAt("aaaa\nbbbb\ncccc", "XY", 2, 1, 4, 3)
+	if got != "aaaa\nbbXY\ncccc" {
+		t.Fatalf("overlayAt() = %q", got)
+	}
+}
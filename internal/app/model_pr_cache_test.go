@@ -16,6 +16,12 @@ type mockPRService struct {
 	diffCalls   int
 	submitCalls int
 	patches     map[string]string
+	reviews     []comments.Comment
+	commits     []githubpr.Commit
+}
+
+func (m *mockPRService) ListReviewQueue(context.Context, string) ([]githubpr.Summary, error) {
+	return nil, nil
 }
 
 func (m *mockPRService) ResolvePR(context.Context, string, string) (githubpr.Context, error) {
@@ -40,6 +46,18 @@ func (m *mockPRService) SubmitReviewComments(context.Context, githubpr.Context,
 	return nil
 }
 
+func (m *mockPRService) Checkout(context.Context, string, githubpr.Context) error {
+	return nil
+}
+
+func (m *mockPRService) ListReviewComments(context.Context, githubpr.Context) ([]comments.Comment, error) {
+	return m.reviews, nil
+}
+
+func (m *mockPRService) ListCommits(context.Context, githubpr.Context) ([]githubpr.Commit, error) {
+	return m.commits, nil
+}
+
 func TestPRModeDiffCachingAvoidsSecondFetch(t *testing.T) {
 	service := &mockPRService{
 		patches: map[string]string{
@@ -86,7 +104,7 @@ func TestPRModeStaleCheckUsesDiffCacheWhenAvailable(t *testing.T) {
 		prDiffs: map[string]prDiffCacheEntry{
 			"a.go": {
 				rows: []diffview.DiffRow{
-					{Kind: diffview.RowChange, Path: "a.go", OldLine: intPtr(1), NewLine: intPtr(1), OldText: "old", NewText: "new"},
+					{Kind: diffview.RowChange, Path: "a.go", OldLine: 1, NewLine: 1, OldText: "old", NewText: "new"},
 				},
 			},
 		},
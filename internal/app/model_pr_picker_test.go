@@ -12,13 +12,19 @@ import (
 )
 
 type pickerPRService struct {
-	resolved githubpr.Context
+	resolved  githubpr.Context
+	queue     []githubpr.Summary
+	checkouts int
 }
 
 func (s *pickerPRService) ListOpenPRs(context.Context, string) ([]githubpr.Summary, error) {
 	return []githubpr.Summary{{Number: 42, Title: "Test PR"}}, nil
 }
 
+func (s *pickerPRService) ListReviewQueue(context.Context, string) ([]githubpr.Summary, error) {
+	return s.queue, nil
+}
+
 func (s *pickerPRService) ResolvePR(context.Context, string, string) (githubpr.Context, error) {
 	return s.resolved, nil
 }
@@ -35,6 +41,19 @@ func (s *pickerPRService) SubmitReviewComments(context.Context, githubpr.Context
 	return nil
 }
 
+func (s *pickerPRService) Checkout(context.Context, string, githubpr.Context) error {
+	s.checkouts++
+	return nil
+}
+
+func (s *pickerPRService) ListReviewComments(context.Context, githubpr.Context) ([]comments.Comment, error) {
+	return nil, nil
+}
+
+func (s *pickerPRService) ListCommits(context.Context, githubpr.Context) ([]githubpr.Commit, error) {
+	return nil, nil
+}
+
 func TestQuitFromPRReviewReturnsToPicker(t *testing.T) {
 	m := Model{
 		reviewMode: reviewModePR,
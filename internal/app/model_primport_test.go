@@ -0,0 +1,71 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/bubbles/textinput"
+
+	"diffman/internal/comments"
+	"diffman/internal/githubpr"
+)
+
+func TestImportPRCommentsMergesAndKeepsOwnComments(t *testing.T) {
+	own := comments.Comment{Path: "a.go", Side: comments.SideNew, Line: 1, Body: "mine"}
+	oldImport := comments.Comment{Path: "z.go", Side: comments.SideNew, Line: 9, Body: "gone", Author: "carol"}
+	service := &mockPRService{reviews: []comments.Comment{
+		{Path: "a.go", Side: comments.SideNew, Line: 1, Body: "theirs", Author: "alice"},
+		{Path: "b.go", Side: comments.SideOld, Line: 4, Body: "first", Author: "alice"},
+		{Path: "b.go", Side: comments.SideOld, Line: 4, Body: "second", Author: "bob"},
+	}}
+	store := comments.NewStore(t.TempDir())
+	m := Model{
+		prSvc:        service,
+		prCtx:        &githubpr.Context{Owner: "o", Repo: "r", Number: 7},
+		reviewMode:   reviewModePR,
+		commentStore: store,
+		comments: map[string]comments.Comment{
+			commentKey(own):       own,
+			commentKey(oldImport): oldImport,
+		},
+	}
+
+	m.importPRComments()
+
+	if len(m.comments) != 2 {
+		t.Fatalf("expected own comment plus one folded import, got %+v", m.comments)
+	}
+	if got := m.comments[commentKey(own)]; got.Body != "mine" || got.Author != "" {
+		t.Fatalf("expected own comment kept, got %+v", got)
+	}
+	folded := m.comments[comments.AnchorKey("b.go", comments.SideOld, 4)]
+	if folded.Body != "first\n\nsecond" || folded.Author != "alice, bob" {
+		t.Fatalf("unexpected folded import: %+v", folded)
+	}
+	if m.alertMsg != "Imported 2 review comment(s) from PR #7. 1 skipped on lines with your own comments." {
+		t.Fatalf("unexpected alert %q", m.alertMsg)
+	}
+	saved, err := store.Load()
+	if err != nil || len(saved) != 2 {
+		t.Fatalf("expected imports persisted, got %d (err=%v)", len(saved), err)
+	}
+}
+
+func TestSubmitPRCommentsSkipsImportedComments(t *testing.T) {
+	own := comments.Comment{Path: "a.go", Side: comments.SideNew, Line: 1, Body: "mine"}
+	imported := comments.Comment{Path: "a.go", Side: comments.SideNew, Line: 2, Body: "theirs", Author: "alice"}
+	m := Model{
+		reviewMode:       reviewModePR,
+		prCtx:            &githubpr.Context{Number: 7},
+		reviewInputModel: textinput.New(),
+		comments: map[string]comments.Comment{
+			commentKey(own):      own,
+			commentKey(imported): imported,
+		},
+	}
+
+	next, _ := m.handleSubmitPRComments()
+	m = next.(Model)
+	if len(m.reviewDraft) != 1 || m.reviewDraft[0].Body != "mine" {
+		t.Fatalf("expected only own comment in review draft, got %+v", m.reviewDraft)
+	}
+}
@@ -0,0 +1,165 @@
+package app
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"diffman/internal/diffview"
+	gitint "diffman/internal/git"
+	"diffman/internal/githubpr"
+)
+
+func TestReviewQueueChecksOutSelectedPR(t *testing.T) {
+	svc := &pickerPRService{
+		resolved: githubpr.Context{Owner: "acme", Repo: "widgets", Number: 9, HeadRef: "fix-bug"},
+		queue: []githubpr.Summary{
+			{Number: 12, Title: "Newer", Reason: "assigned"},
+			{Number: 9, Title: "Older", Reason: "review requested"},
+		},
+	}
+	m := Model{
+		keys:       defaultKeyMap(),
+		reviewMode: reviewModeLocal,
+		prSvc:      svc,
+		cwd:        "/tmp",
+		width:      120,
+	}
+
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("O")})
+	m = next.(Model)
+	if !m.prQueueOpen || cmd == nil {
+		t.Fatalf("expected O to open the queue and load it")
+	}
+	next, _ = m.Update(cmd())
+	m = next.(Model)
+	if len(m.prQueueItems) != 2 {
+		t.Fatalf("expected queue items, got %+v", m.prQueueItems)
+	}
+
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	m = next.(Model)
+	next, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(Model)
+	if cmd != nil || m.prQueueConfirm == nil || m.prQueueConfirm.Number != 9 {
+		t.Fatalf("expected enter to ask before checking out #9, got %+v", m.prQueueConfirm)
+	}
+	if !strings.Contains(m.renderReviewQueueModal(), "gh pr checkout") {
+		t.Fatalf("expected the confirmation in the queue modal")
+	}
+	next, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	m = next.(Model)
+	if cmd == nil {
+		t.Fatalf("expected y to start the checkout")
+	}
+	next, _ = m.Update(cmd())
+	m = next.(Model)
+	if svc.checkouts != 1 {
+		t.Fatalf("expected one checkout, got %d", svc.checkouts)
+	}
+	if m.prQueueOpen || m.reviewMode != reviewModePR || m.prCtx == nil || m.prCtx.Number != 9 {
+		t.Fatalf("expected PR mode for #9, got mode=%v ctx=%+v", m.reviewMode, m.prCtx)
+	}
+}
+
+func TestReviewQueueUnavailableInPRMode(t *testing.T) {
+	m := Model{keys: defaultKeyMap(), reviewMode: reviewModePR, prCtx: &githubpr.Context{Number: 3}}
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("O")})
+	m = next.(Model)
+	if m.prQueueOpen || cmd != nil {
+		t.Fatalf("expected queue to stay closed in PR mode")
+	}
+}
+
+func TestReviewQueueCheckoutResetsReviewState(t *testing.T) {
+	svc := &pickerPRService{
+		resolved: githubpr.Context{Owner: "acme", Repo: "widgets", Number: 9, HeadRef: "fix-bug"},
+	}
+	m := Model{
+		keys:             defaultKeyMap(),
+		reviewMode:       reviewModeLocal,
+		prSvc:            svc,
+		cwd:              "/tmp",
+		prQueueOpen:      true,
+		prQueueItems:     []githubpr.Summary{{Number: 9, Title: "Fix"}},
+		fileItems:        []gitint.FileItem{{Path: "old.go"}},
+		selectedF:        "old.go",
+		diffRows:         []diffview.DiffRow{{Kind: diffview.RowContext}},
+		commentStale:     map[string]bool{"old.go:new:1": true},
+		commentsSelected: map[string]bool{"old.go:new:1": true},
+		focus:            focusDiff,
+	}
+	next, _ := m.Update(reviewQueueCheckoutMsg{ctx: svc.resolved})
+	m = next.(Model)
+	if m.reviewMode != reviewModePR || m.prCtx == nil || m.prCtx.Number != 9 {
+		t.Fatalf("expected PR mode for #9, got mode=%v ctx=%+v", m.reviewMode, m.prCtx)
+	}
+	if m.fileItems != nil || m.selectedF != "" || m.diffRows != nil || len(m.commentStale) != 0 || m.commentsSelected != nil || m.focus != focusFiles {
+		t.Fatalf("expected the local review state dropped, got files=%v selected=%q rows=%d stale=%v", m.fileItems, m.selectedF, len(m.diffRows), m.commentStale)
+	}
+	if m.prQueueOpen || m.prQueueItems != nil {
+		t.Fatalf("expected the queue closed")
+	}
+}
+
+func TestReviewQueueConfirmMentionsUncommittedChanges(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if os.Getenv("GIT_DIR") != "" {
+		t.Skip("GIT_DIR is set")
+	}
+	repo := t.TempDir()
+	gitCmd(t, repo, "init", "-q")
+	if err := os.WriteFile(repo+"/wip.go", []byte("package wip\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m := Model{
+		keys:         defaultKeyMap(),
+		reviewMode:   reviewModeLocal,
+		prSvc:        &pickerPRService{},
+		cwd:          repo,
+		width:        120,
+		prQueueOpen:  true,
+		prQueueItems: []githubpr.Summary{{Number: 9, Title: "Fix"}},
+	}
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(Model)
+	if m.prQueueDirty != 1 || !strings.Contains(m.renderReviewQueueModal(), "1 uncommitted change(s)") {
+		t.Fatalf("expected one uncommitted change in the confirmation, got %d\n%s", m.prQueueDirty, m.renderReviewQueueModal())
+	}
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = next.(Model)
+	if cmd != nil || m.prQueueConfirm != nil || !m.prQueueOpen {
+		t.Fatalf("expected Esc to cancel the checkout and keep the queue open")
+	}
+}
+
+func TestReviewQueueOfferedOnStartup(t *testing.T) {
+	svc := &pickerPRService{queue: []githubpr.Summary{{Number: 9, Title: "Fix"}}}
+	m := Model{keys: defaultKeyMap(), reviewMode: reviewModeLocal, prSvc: svc, cwd: "/tmp"}
+	cmd := m.offerReviewQueueCmd()
+	if cmd == nil {
+		t.Fatalf("expected the queue to be listed on startup")
+	}
+	next, _ := m.Update(cmd())
+	m = next.(Model)
+	if m.prQueueOpen || !strings.Contains(m.alertMsg, "1 PR(s) await your review") {
+		t.Fatalf("expected an alert offering the queue, got open=%v alert=%q", m.prQueueOpen, m.alertMsg)
+	}
+
+	m.alertMsg = ""
+	next, _ = m.Update(reviewQueueOfferMsg{err: errors.New("no GitHub remote")})
+	m = next.(Model)
+	if m.alertMsg != "" {
+		t.Fatalf("expected no alert without a GitHub remote, got %q", m.alertMsg)
+	}
+	m.reviewMode = reviewModePR
+	if m.offerReviewQueueCmd() != nil {
+		t.Fatalf("expected no offer in PR mode")
+	}
+}
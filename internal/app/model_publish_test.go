@@ -0,0 +1,63 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"diffman/internal/comments"
+)
+
+func TestPublishPostsExportAfterConfirmation(t *testing.T) {
+	var text string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct{ Text string }
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		text = body.Text
+	}))
+	defer srv.Close()
+
+	c := comments.Comment{Path: "a.go", Side: comments.SideNew, Line: 7, Body: "ship it"}
+	m := Model{
+		keys:         defaultKeyMap(),
+		focus:        focusComments,
+		cwd:          t.TempDir(),
+		comments:     map[string]comments.Comment{commentKey(c): c},
+		commentStale: map[string]bool{},
+		webhookURL:   srv.URL + "/hook",
+	}
+
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("B")})
+	m = next.(Model)
+	if !m.publishConfirmModal || cmd != nil {
+		t.Fatalf("expected confirmation before publishing")
+	}
+
+	next, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	m = next.(Model)
+	if m.publishConfirmModal || cmd == nil {
+		t.Fatalf("expected confirm to start publishing")
+	}
+	next, _ = m.Update(cmd())
+	m = next.(Model)
+
+	if !strings.Contains(text, "a.go new:7: ship it") {
+		t.Fatalf("expected export posted, got %q", text)
+	}
+	if !strings.HasPrefix(m.alertMsg, "Published 1 comment(s) to 127.0.0.1") {
+		t.Fatalf("unexpected alert %q", m.alertMsg)
+	}
+}
+
+func TestPublishWithoutWebhookAlerts(t *testing.T) {
+	c := comments.Comment{Path: "a.go", Side: comments.SideNew, Line: 7, Body: "ship it"}
+	m := Model{keys: defaultKeyMap(), focus: focusComments, comments: map[string]comments.Comment{commentKey(c): c}}
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("B")})
+	if got := next.(Model).alertMsg; got != "No webhook_url configured." {
+		t.Fatalf("unexpected alert %q", got)
+	}
+}
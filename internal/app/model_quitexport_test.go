@@ -0,0 +1,55 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"diffman/internal/comments"
+	"diffman/internal/config"
+)
+
+func TestExportOnQuitWritesNonStaleComments(t *testing.T) {
+	root := t.TempDir()
+	kept := comments.Comment{Path: "a.go", Side: comments.SideNew, Line: 3, Body: "keep me"}
+	stale := comments.Comment{Path: "b.go", Side: comments.SideNew, Line: 1, Body: "stale"}
+	m := Model{
+		cwd: root,
+		comments: map[string]comments.Comment{
+			commentKey(kept):  kept,
+			commentKey(stale): stale,
+		},
+		commentStale: map[string]bool{commentKey(stale): true},
+		// The selection limits y and W, not the export on quit.
+		commentsSelected: map[string]bool{commentKey(stale): true},
+	}
+
+	if done, err := m.ExportOnQuit(); done != "" || err != nil {
+		t.Fatalf("expected no export without export_on_quit, got %q, %v", done, err)
+	}
+
+	m.exportOnQuit = config.ExportOnQuitFile
+	m.exportPath = "notes/review.txt"
+	if _, err := m.ExportOnQuit(); err == nil {
+		t.Fatalf("expected an error for a missing directory")
+	}
+	if err := os.MkdirAll(filepath.Join(root, "notes"), 0o755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	done, err := m.ExportOnQuit()
+	if err != nil {
+		t.Fatalf("ExportOnQuit() error = %v", err)
+	}
+	path := filepath.Join(root, "notes", "review.txt")
+	if done != "Wrote 1 comment(s) to "+path+"." {
+		t.Fatalf("unexpected result %q", done)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if !strings.Contains(string(data), "a.go new:3: keep me") || strings.Contains(string(data), "stale") {
+		t.Fatalf("unexpected export contents:\n%s", data)
+	}
+}
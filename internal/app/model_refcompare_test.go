@@ -0,0 +1,115 @@
+package app
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"diffman/internal/comments"
+)
+
+func TestCompareRefsShowsTheFileBetweenTwoRevisions(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if os.Getenv("GIT_DIR") != "" {
+		t.Skip("GIT_DIR is set")
+	}
+	repo := t.TempDir()
+	write := func(body string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(repo, "a.go"), []byte(body), 0o644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+	write("package a\n\nfunc before() {}\n")
+	gitCmd(t, repo, "init", "-q")
+	gitCmd(t, repo, "add", ".")
+	gitCmd(t, repo, "commit", "-q", "-m", "init")
+	gitCmd(t, repo, "tag", "pre-refactor")
+	write("package a\n\nfunc after() {}\n")
+	gitCmd(t, repo, "commit", "-q", "-am", "refactor")
+	write("package a\n\nfunc working() {}\n")
+
+	// A comment on the current diff must not show on the comparison.
+	c := comments.Comment{Path: "a.go", Side: comments.SideNew, Line: 3, Body: "review note"}
+	m := Model{
+		keys:         defaultKeyMap(),
+		cwd:          repo,
+		selectedF:    "a.go",
+		focus:        focusFiles,
+		width:        140,
+		height:       40,
+		comments:     map[string]comments.Comment{commentKey(c): c},
+		commandInput: newCommandInput(),
+	}
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}, Alt: true})
+	m = next.(Model)
+	if !m.commandInputActive || m.commandInput.Value() != "compare " {
+		t.Fatalf("expected alt+r to open the compare command, got %v %q", m.commandInputActive, m.commandInput.Value())
+	}
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("pre-refactor")})
+	next, cmd := next.(Model).Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(Model)
+	if m.refCompare == nil || cmd == nil {
+		t.Fatalf("expected a comparison to load, error %q", m.commandInputErr)
+	}
+	next, _ = m.Update(cmd())
+	m = next.(Model)
+
+	view := m.renderRefCompareModal()
+	for _, want := range []string{"a.go: pre-refactor → HEAD", "before", "after"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("expected %q in the comparison:\n%s", want, view)
+		}
+	}
+	for _, unwanted := range []string{"working", "review note"} {
+		if strings.Contains(view, unwanted) {
+			t.Fatalf("unexpected %q in the comparison:\n%s", unwanted, view)
+		}
+	}
+
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if next.(Model).refCompare != nil {
+		t.Fatalf("expected Esc to close the comparison")
+	}
+}
+
+func TestCompareRefsReportsUnknownRevisions(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if os.Getenv("GIT_DIR") != "" {
+		t.Skip("GIT_DIR is set")
+	}
+	repo := t.TempDir()
+	if err := os.WriteFile(filepath.Join(repo, "a.go"), []byte("package a\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	gitCmd(t, repo, "init", "-q")
+	gitCmd(t, repo, "add", ".")
+	gitCmd(t, repo, "commit", "-q", "-m", "init")
+
+	m := Model{keys: defaultKeyMap(), cwd: repo, selectedF: "a.go"}
+	cmd, err := m.compareRefsCmd([]string{"nope"})
+	if err != nil {
+		t.Fatalf("compareRefsCmd() error = %v", err)
+	}
+	next, _ := m.Update(cmd())
+	m = next.(Model)
+	if m.refCompare != nil || !strings.Contains(m.alertMsg, `unknown revision "nope"`) {
+		t.Fatalf("expected an unknown revision alert, got %q", m.alertMsg)
+	}
+}
+
+func TestCompareRefsNeedsASelectedFile(t *testing.T) {
+	m := Model{keys: defaultKeyMap(), commandInput: newCommandInput()}
+	m = typeCommand(t, m, "compare main")
+	if !m.commandInputActive || !strings.Contains(m.commandInputErr, "select a file") {
+		t.Fatalf("expected an error on the command line, got %q", m.commandInputErr)
+	}
+}
@@ -0,0 +1,97 @@
+package app
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"diffman/internal/comments"
+	gitint "diffman/internal/git"
+)
+
+func TestRefreshKeepsDiffLineAndFileScroll(t *testing.T) {
+	var items []gitint.FileItem
+	for i := range 30 {
+		items = append(items, gitint.FileItem{Path: fmt.Sprintf("f%02d.go", i), Status: "M."})
+	}
+	m := Model{
+		keys:      defaultKeyMap(),
+		focus:     focusDiff,
+		statusSvc: stubStatusService{items: items},
+		diffSvc:   stubDiffService{},
+		fileItems: items,
+		selectedF: "f20.go",
+		selected:  20,
+		diffRows:  addedRows("f20.go", 60),
+		oldView:   viewport.New(40, 10),
+		newView:   viewport.New(40, 10),
+		width:     100,
+		height:    20,
+	}
+	m.syncFileCursorToSelectedPath()
+	m.fileScroll = 15
+	m.diffCursor = 41
+	m.diffDirty = true
+	m.refreshDiffContent()
+	m.oldView.SetYOffset(35)
+	m.newView.SetYOffset(35)
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	m = next.(Model)
+
+	// A new file sorts above the selected one.
+	grown := append([]gitint.FileItem{{Path: "a.go", Status: "M."}}, items...)
+	next, _ = m.Update(filesLoadedMsg{items: grown})
+	m = next.(Model)
+	if m.selectedF != "f20.go" || m.fileScroll != 16 {
+		t.Fatalf("expected f20.go to stay in place, got %q with scroll %d", m.selectedF, m.fileScroll)
+	}
+
+	next, _ = m.Update(diffLoadedMsg{path: "f20.go", rows: addedRows("f20.go", 60)})
+	m = next.(Model)
+	if got := m.diffRows[m.diffCursor].NewLine; got != 41 {
+		t.Fatalf("expected the cursor back on line 41, got %d", got)
+	}
+	if m.oldView.YOffset != 35 {
+		t.Fatalf("expected the diff scroll kept at 35, got %d", m.oldView.YOffset)
+	}
+}
+
+func TestRefreshKeepsCommentsCursorOnItsComment(t *testing.T) {
+	list := []comments.Comment{
+		{ID: "b", Path: "b.go", Side: comments.SideNew, Line: 1, Body: "b"},
+		{ID: "c", Path: "c.go", Side: comments.SideNew, Line: 1, Body: "c"},
+	}
+	m := Model{
+		keys:      defaultKeyMap(),
+		focus:     focusComments,
+		statusSvc: stubStatusService{},
+		comments:  map[string]comments.Comment{},
+		oldView:   viewport.New(40, 10),
+		newView:   viewport.New(40, 10),
+		height:    30,
+	}
+	for _, c := range list {
+		m.comments[commentKey(c)] = c
+	}
+	rows := m.commentsViewRows()
+	for i, row := range rows {
+		if !row.Header && row.Comment.ID == "c" {
+			m.commentsCursor = i
+		}
+	}
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	m = next.(Model)
+
+	// A comment written elsewhere lands above the selected one.
+	a := comments.Comment{ID: "a", Path: "a.go", Side: comments.SideNew, Line: 1, Body: "a"}
+	m.comments[commentKey(a)] = a
+	next, _ = m.Update(commentStaleLoadedMsg{})
+	m = next.(Model)
+	if c, ok := m.commentAtCursor(); !ok || c.ID != "c" {
+		t.Fatalf("expected the cursor to stay on comment c, got %+v", c)
+	}
+}
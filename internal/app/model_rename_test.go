@@ -0,0 +1,74 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"diffman/internal/comments"
+	"diffman/internal/diffview"
+	gitint "diffman/internal/git"
+)
+
+func TestFollowRenamedCommentsMovesCommentsToNewPath(t *testing.T) {
+	onOld := comments.Comment{Path: "old.go", Side: comments.SideNew, Line: 3, Body: "rename me"}
+	oldSide := comments.Comment{Path: "old.go", Side: comments.SideOld, Line: 1, Body: "was here"}
+	onCopySource := comments.Comment{Path: "src.go", Side: comments.SideNew, Line: 2, Body: "stays"}
+	m := Model{commentStore: comments.NewStore(t.TempDir()), comments: map[string]comments.Comment{
+		commentKey(onOld):        onOld,
+		commentKey(oldSide):      oldSide,
+		commentKey(onCopySource): onCopySource,
+	}}
+
+	m.followRenamedComments([]gitint.FileItem{
+		{Path: "new.go", OrigPath: "old.go", Status: "R.", Similarity: 90},
+		{Path: "copy.go", OrigPath: "src.go", Status: "C.", Similarity: 100},
+	})
+
+	if len(m.comments) != 3 {
+		t.Fatalf("expected 3 comments, got %+v", m.comments)
+	}
+	for _, c := range []comments.Comment{onOld, oldSide} {
+		c.Path = "new.go"
+		if got, ok := m.comments[commentKey(c)]; !ok || got.Body != c.Body {
+			t.Fatalf("expected %q on new.go, got %+v", c.Body, m.comments)
+		}
+	}
+	if _, ok := m.comments[commentKey(onCopySource)]; !ok {
+		t.Fatalf("expected comments on a copy source to stay")
+	}
+	if !strings.Contains(m.alertMsg, "Moved 2 comment(s)") {
+		t.Fatalf("unexpected alert %q", m.alertMsg)
+	}
+}
+
+func TestFollowRenamedCommentsKeepsCommentsWhenOldPathIsStillListed(t *testing.T) {
+	c := comments.Comment{Path: "old.go", Side: comments.SideNew, Line: 3, Body: "note"}
+	m := Model{comments: map[string]comments.Comment{commentKey(c): c}}
+
+	m.followRenamedComments([]gitint.FileItem{
+		{Path: "new.go", OrigPath: "old.go", Status: "R."},
+		{Path: "old.go", Status: "??"},
+	})
+
+	if _, ok := m.comments[commentKey(c)]; !ok || m.alertMsg != "" {
+		t.Fatalf("expected the comment to stay on old.go, got %+v (%q)", m.comments, m.alertMsg)
+	}
+}
+
+func TestLocalDiffRowsForRenames(t *testing.T) {
+	svc := stubDiffService{diffs: map[string]string{
+		"new.go": "diff --git a/old.go b/new.go\nsimilarity index 100%\nrename from old.go\nrename to new.go\n",
+		"copy.go": "diff --git a/src.go b/src.go\n--- a/src.go\n+++ b/src.go\n@@ -1 +1 @@\n-a\n+b\n" +
+			"diff --git a/src.go b/copy.go\nsimilarity index 90%\ncopy from src.go\ncopy to copy.go\n--- a/src.go\n+++ b/copy.go\n@@ -1 +1 @@\n-a\n+c\n",
+	}}
+
+	rows, empty, err := localDiffRows(t.Context(), svc, "/repo", "new.go", "old.go", gitint.DiffModeAll)
+	if err != nil || empty || len(rows) != 1 || rows[0].OldText != "Renamed from old.go without content changes" {
+		t.Fatalf("unexpected pure-rename rows %+v (empty=%v err=%v)", rows, empty, err)
+	}
+
+	rows, _, err = localDiffRows(t.Context(), svc, "/repo", "copy.go", "src.go", gitint.DiffModeAll)
+	if err != nil || len(rows) != 2 || rows[1].Kind != diffview.RowChange || rows[1].NewText != "c" {
+		t.Fatalf("expected only copy.go's rows, got %+v (err=%v)", rows, err)
+	}
+}
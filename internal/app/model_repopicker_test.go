@@ -0,0 +1,135 @@
+package app
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"diffman/internal/comments"
+	"diffman/internal/recent"
+)
+
+func TestRepoPickerSwitchesToNestedRepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if os.Getenv("GIT_DIR") != "" {
+		t.Skip("GIT_DIR is set")
+	}
+	base, _ := filepath.EvalSymlinks(t.TempDir())
+	outer := filepath.Join(base, "mono")
+	inner := filepath.Join(outer, "services", "api")
+	if err := os.MkdirAll(inner, 0o755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	gitCmd(t, outer, "init", "-q")
+	gitCmd(t, inner, "init", "-q")
+	note := comments.Comment{Path: "main.go", Side: comments.SideNew, Line: 3, Body: "api note"}
+	if err := comments.NewStore(filepath.Join(inner, ".git")).Save([]comments.Comment{note}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// Opened from inside the nested repository, the picker still lists the
+	// enclosing one.
+	m := Model{keys: defaultKeyMap(), cwd: inner, comments: map[string]comments.Comment{}}
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	m = next.(Model)
+	if !m.repoPickerOpen || len(m.repoPickerItems) != 2 || m.workspaceRoot != outer {
+		t.Fatalf("expected both repositories under %s, got %+v (root %s)", outer, m.repoPickerItems, m.workspaceRoot)
+	}
+	if m.repoPickerItems[m.repoPickerCursor].Path != inner {
+		t.Fatalf("expected cursor on the current repository")
+	}
+	if got := m.repoLabel(inner); got != "services/api" {
+		t.Fatalf("repoLabel() = %q", got)
+	}
+
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("k")})
+	m = next.(Model)
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(Model)
+	if cmd == nil || m.repoPickerOpen || m.cwd != outer {
+		t.Fatalf("expected switch to %s, got cwd=%s err=%q", outer, m.cwd, m.repoPickerErr)
+	}
+	if len(m.comments) != 0 {
+		t.Fatalf("expected the outer repository's (empty) comments, got %+v", m.comments)
+	}
+
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	m = next.(Model)
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	m = next.(Model)
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(Model)
+	if got := m.comments[commentKey(note)]; m.cwd != inner || got.Body != "api note" {
+		t.Fatalf("expected the nested repository's comments, got cwd=%s %+v", m.cwd, m.comments)
+	}
+}
+
+func TestRepoPickerNeedsNestedRepositories(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if os.Getenv("GIT_DIR") != "" {
+		t.Skip("GIT_DIR is set")
+	}
+	dir, _ := filepath.EvalSymlinks(t.TempDir())
+	gitCmd(t, dir, "init", "-q")
+
+	m := Model{keys: defaultKeyMap(), cwd: dir, comments: map[string]comments.Comment{}}
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	m = next.(Model)
+	if m.repoPickerOpen || m.alertMsg == "" {
+		t.Fatalf("expected an alert instead of a picker, got open=%v alert=%q", m.repoPickerOpen, m.alertMsg)
+	}
+}
+
+func TestRepoPickerListsRecentRepositories(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if os.Getenv("GIT_DIR") != "" {
+		t.Skip("GIT_DIR is set")
+	}
+	base, _ := filepath.EvalSymlinks(t.TempDir())
+	first := filepath.Join(base, "first")
+	second := filepath.Join(base, "second")
+	gone := filepath.Join(base, "gone")
+	for _, dir := range []string{first, second} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("MkdirAll() error = %v", err)
+		}
+		gitCmd(t, dir, "init", "-q")
+	}
+	store := recent.NewStore(filepath.Join(base, "state", "recent.json"))
+	for _, dir := range []string{gone, second, first} {
+		if err := store.Touch(dir); err != nil {
+			t.Fatalf("Touch() error = %v", err)
+		}
+	}
+
+	m := Model{keys: defaultKeyMap(), cwd: first, recentStore: store, comments: map[string]comments.Comment{}}
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	m = next.(Model)
+	if !m.repoPickerOpen || len(m.repoPickerItems) != 2 {
+		t.Fatalf("expected the current repository and one recent one, got %+v", m.repoPickerItems)
+	}
+	if got := m.repoPickerItems[1]; got.Path != second || !got.Recent {
+		t.Fatalf("expected %s listed as recent, got %+v", second, got)
+	}
+
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	m = next.(Model)
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(Model)
+	if m.cwd != second {
+		t.Fatalf("expected switch to %s, got %s (%q)", second, m.cwd, m.repoPickerErr)
+	}
+	list, _ := store.Load()
+	if len(list) == 0 || list[0].Path != second {
+		t.Fatalf("expected the switch to move %s to the front, got %+v", second, list)
+	}
+}
@@ -0,0 +1,121 @@
+package app
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"diffman/internal/comments"
+	gitint "diffman/internal/git"
+	"diffman/internal/history"
+)
+
+func TestRevertFileRestoresHeadAndArchivesComments(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if os.Getenv("GIT_DIR") != "" {
+		t.Skip("GIT_DIR is set")
+	}
+	repo := t.TempDir()
+	for _, name := range []string{"a.go", "b.go"} {
+		if err := os.WriteFile(filepath.Join(repo, name), []byte("one\ntwo\n"), 0o644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+	gitCmd(t, repo, "init", "-q")
+	gitCmd(t, repo, "add", ".")
+	gitCmd(t, repo, "commit", "-q", "-m", "init")
+	for _, name := range []string{"a.go", "b.go"} {
+		if err := os.WriteFile(filepath.Join(repo, name), []byte("one\nTWO\n"), 0o644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+
+	kept := comments.Comment{ID: "b", Path: "b.go", Side: comments.SideNew, Line: 2, Body: "keep"}
+	reverted := comments.Comment{ID: "a", Path: "a.go", Side: comments.SideNew, Line: 2, Body: "gone"}
+	historyDir := t.TempDir()
+	m := Model{
+		keys:         defaultKeyMap(),
+		cwd:          repo,
+		focus:        focusFiles,
+		fileItems:    []gitint.FileItem{{Path: "a.go", Status: ".M"}, {Path: "b.go", Status: ".M"}},
+		commentStore: comments.NewStore(t.TempDir()),
+		historyStore: history.NewStore(historyDir),
+		comments:     map[string]comments.Comment{commentKey(kept): kept, commentKey(reverted): reverted},
+	}
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("U")})
+	m = next.(Model)
+	if m.revertConfirm == nil || m.revertConfirm.Path != "a.go" {
+		t.Fatalf("expected U to ask to revert a.go, got %+v", m.revertConfirm)
+	}
+	if view := m.renderRevertConfirmModal(); !strings.Contains(view, "1 comment(s)") {
+		t.Fatalf("modal does not mention the comment:\n%s", view)
+	}
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	m = next.(Model)
+	if m.revertConfirm != nil || cmd == nil {
+		t.Fatalf("expected y to start the revert")
+	}
+	next, _ = m.Update(cmd())
+	m = next.(Model)
+
+	if b, err := os.ReadFile(filepath.Join(repo, "a.go")); err != nil || string(b) != "one\ntwo\n" {
+		t.Fatalf("a.go = %q, %v; want HEAD's content", b, err)
+	}
+	if b, err := os.ReadFile(filepath.Join(repo, "b.go")); err != nil || string(b) != "one\nTWO\n" {
+		t.Fatalf("b.go = %q, %v; want it untouched", b, err)
+	}
+	if _, ok := m.comments[commentKey(reverted)]; ok || len(m.comments) != 1 {
+		t.Fatalf("comments = %+v, want only b.go's", m.comments)
+	}
+	items, err := history.NewStore(historyDir).List()
+	if err != nil || len(items) != 1 || items[0].Outcome != "reverted" {
+		t.Fatalf("history = %+v, %v; want one reverted review", items, err)
+	}
+	if !strings.Contains(m.alertMsg, "Reverted a.go") {
+		t.Fatalf("alert = %q", m.alertMsg)
+	}
+}
+
+func TestRevertFileDeletesUntracked(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if os.Getenv("GIT_DIR") != "" {
+		t.Skip("GIT_DIR is set")
+	}
+	repo := t.TempDir()
+	gitCmd(t, repo, "init", "-q")
+	if err := os.WriteFile(filepath.Join(repo, "junk.log"), []byte("x\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	m := Model{
+		keys:         defaultKeyMap(),
+		cwd:          repo,
+		focus:        focusFiles,
+		fileItems:    []gitint.FileItem{{Path: "junk.log", Status: "??"}},
+		commentStore: comments.NewStore(t.TempDir()),
+		comments:     map[string]comments.Comment{},
+	}
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("U")})
+	m = next.(Model)
+	if m.revertConfirm == nil || !strings.Contains(m.renderRevertConfirmModal(), "Delete untracked junk.log?") {
+		t.Fatalf("expected U to ask to delete junk.log")
+	}
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	next, _ = next.(Model).Update(cmd())
+	m = next.(Model)
+	if _, err := os.Stat(filepath.Join(repo, "junk.log")); !os.IsNotExist(err) {
+		t.Fatalf("junk.log should be deleted, stat error = %v", err)
+	}
+	if m.alertMsg != "Deleted junk.log." {
+		t.Fatalf("alert = %q", m.alertMsg)
+	}
+}
@@ -0,0 +1,76 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"diffman/internal/comments"
+	"diffman/internal/diffview"
+	gitint "diffman/internal/git"
+)
+
+func addedRows(path string, n int) []diffview.DiffRow {
+	rows := []diffview.DiffRow{{Kind: diffview.RowHunkHeader, OldText: "@@ -0,0 +1 @@", Path: path}}
+	for i := 1; i <= n; i++ {
+		rows = append(rows, diffview.DiffRow{Kind: diffview.RowAdd, NewLine: i, NewText: "line", Path: path})
+	}
+	return rows
+}
+
+func TestReviewedCoverageCountsRowsShownInTheDiffPane(t *testing.T) {
+	m := Model{
+		keys:      defaultKeyMap(),
+		comments:  map[string]comments.Comment{},
+		focus:     focusDiff,
+		fileItems: []gitint.FileItem{{Path: "a.go", Status: "A"}, {Path: "b.go", Status: "M"}},
+		selectedF: "a.go",
+		oldView:   viewport.New(60, 5),
+		newView:   viewport.New(60, 5),
+		oldWidth:  -1,
+		newWidth:  -1,
+	}
+	next, _ := m.Update(diffLoadedMsg{path: "a.go", rows: addedRows("a.go", 20)})
+	m = next.(Model)
+
+	done, total, ok := m.fileReviewProgress("a.go")
+	if !ok || total != 20 || done == 0 || done >= total {
+		t.Fatalf("expected the first screen reviewed, got %d/%d ok=%v", done, total, ok)
+	}
+	if _, _, ok := m.fileReviewProgress("b.go"); ok {
+		t.Fatalf("expected b.go to be unopened")
+	}
+	if seg := m.reviewStatusSegment(); !strings.Contains(seg, "1 unopened") {
+		t.Fatalf("expected the unopened file counted, got %q", seg)
+	}
+
+	// Jumping to the bottom shows only the last screen.
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("G")})
+	m = next.(Model)
+	if d, _, _ := m.fileReviewProgress("a.go"); d == total {
+		t.Fatalf("expected rows jumped over to stay unreviewed")
+	}
+	if mark := m.fileReviewMark("a.go"); !strings.HasSuffix(mark, "%") {
+		t.Fatalf("expected a partial percentage mark, got %q", mark)
+	}
+
+	for range 25 {
+		next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("k")})
+		m = next.(Model)
+	}
+	if d, _, _ := m.fileReviewProgress("a.go"); d != total {
+		t.Fatalf("expected every row reviewed after moving through them, got %d/%d", d, total)
+	}
+
+	// A reload keeps unchanged lines reviewed, but changed text is new.
+	rows := addedRows("a.go", 20)
+	rows[20].NewText = "edited"
+	m.focus = focusFiles
+	next, _ = m.Update(diffLoadedMsg{path: "a.go", rows: rows})
+	m = next.(Model)
+	if d, _, _ := m.fileReviewProgress("a.go"); d != total-1 {
+		t.Fatalf("expected only the edited line to need review, got %d/%d", d, total)
+	}
+}
@@ -0,0 +1,37 @@
+package app
+
+import (
+	"errors"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestShellKeySuspendsForShell(t *testing.T) {
+	m := Model{keys: defaultKeyMap()}
+	m.focus = focusFiles
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("!")})
+	if cmd == nil {
+		t.Fatalf("expected a command to run the shell")
+	}
+	if got := updated.(Model); got.loadingFiles {
+		t.Fatalf("files should not reload before the shell exits")
+	}
+}
+
+func TestShellExitRefreshesFiles(t *testing.T) {
+	m := Model{keys: defaultKeyMap()}
+	updated, cmd := m.Update(shellExitMsg{})
+	got := updated.(Model)
+	if cmd == nil || !got.loadingFiles {
+		t.Fatalf("expected a file reload after the shell exits")
+	}
+	if got.alertMsg != "" {
+		t.Fatalf("unexpected alert %q", got.alertMsg)
+	}
+
+	updated, _ = got.Update(shellExitMsg{err: errors.New("no such file")})
+	if got := updated.(Model); got.alertMsg != "shell failed: no such file" {
+		t.Fatalf("alert = %q", got.alertMsg)
+	}
+}
@@ -0,0 +1,82 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"diffman/internal/diffview"
+	gitint "diffman/internal/git"
+	"diffman/internal/snapshot"
+)
+
+func TestDeltaModeShowsOnlyChangesSinceSnapshot(t *testing.T) {
+	header := "diff --git a/%s b/%s\n--- a/%s\n+++ b/%s\n"
+	diffFor := func(path, body string) string {
+		return strings.ReplaceAll(header, "%s", path) + body
+	}
+	diffs := map[string]string{
+		"a.go": diffFor("a.go", "@@ -1,2 +1,3 @@\n one\n+two\n three\n"),
+		"b.go": diffFor("b.go", "@@ -1,2 +1,2 @@\n x\n-y\n+z\n"),
+	}
+	items := []gitint.FileItem{{Path: "a.go", Status: "M"}, {Path: "b.go", Status: "M"}}
+	m := Model{
+		keys:          defaultKeyMap(),
+		statusSvc:     stubStatusService{items: items},
+		diffSvc:       stubDiffService{diffs: diffs},
+		snapshotStore: snapshot.NewStore(t.TempDir()),
+		fileItems:     items,
+		oldView:       viewport.New(1, 1),
+		newView:       viewport.New(1, 1),
+	}
+
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("S")})
+	m = next.(Model)
+	next, _ = m.Update(cmd())
+	m = next.(Model)
+	if m.reviewSnapshot == nil || len(m.reviewSnapshot.Files) != 2 {
+		t.Fatalf("expected snapshot of both files, got %+v", m.reviewSnapshot)
+	}
+
+	// a.go gains a second hunk after the review pass; b.go is untouched.
+	diffs["a.go"] += "@@ -10,2 +11,3 @@\n p\n+q\n r\n"
+	next, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("R")})
+	m = next.(Model)
+	if !m.deltaMode || cmd == nil {
+		t.Fatalf("expected R to enter delta mode and reload files")
+	}
+	loaded := filesLoadedFrom(t, cmd)
+	if len(loaded.items) != 1 || loaded.items[0].Path != "a.go" || loaded.deltaHidden != 1 || len(loaded.all) != 2 {
+		t.Fatalf("expected only a.go listed, got %+v", loaded)
+	}
+	next, cmd = m.Update(loaded)
+	m = next.(Model)
+	if got := m.staleCheckItems(); len(got) != 2 {
+		t.Fatalf("expected stale check to see all files, got %d", len(got))
+	}
+
+	next, _ = m.Update(diffLoadedMsg{path: "a.go", rows: mustParseDiff(t, diffs["a.go"])})
+	m = next.(Model)
+	if m.deltaHunksHidden != 1 {
+		t.Fatalf("expected reviewed hunk hidden, got %d", m.deltaHunksHidden)
+	}
+	for _, r := range m.diffRows {
+		if r.HunkID != 1 {
+			t.Fatalf("expected only the new hunk, got %+v", r)
+		}
+	}
+	if seg := m.deltaStatusSegment(); !strings.Contains(seg, "1 file(s) hidden") {
+		t.Fatalf("unexpected status segment %q", seg)
+	}
+}
+
+func mustParseDiff(t *testing.T, diff string) []diffview.DiffRow {
+	t.Helper()
+	rows, err := diffview.ParseUnifiedDiff([]byte(diff))
+	if err != nil {
+		t.Fatalf("ParseUnifiedDiff() error = %v", err)
+	}
+	return rows
+}
@@ -0,0 +1,69 @@
+package app
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	gitint "diffman/internal/git"
+)
+
+func TestOpeningFileOutsideSparseCheckoutOffersToCheckItOut(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if os.Getenv("GIT_DIR") != "" {
+		t.Skip("GIT_DIR is set")
+	}
+	repo := t.TempDir()
+	for _, name := range []string{"a/x.txt", "b/y.txt"} {
+		path := filepath.Join(repo, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("MkdirAll() error = %v", err)
+		}
+		if err := os.WriteFile(path, []byte("one\n"), 0o644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+	gitCmd(t, repo, "init", "-q")
+	gitCmd(t, repo, "add", ".")
+	gitCmd(t, repo, "commit", "-q", "-m", "init")
+	cmd := exec.Command("git", "sparse-checkout", "set", "--cone", "a")
+	cmd.Dir = repo
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Skipf("git sparse-checkout unsupported: %v (%s)", err, out)
+	}
+
+	m := Model{
+		keys:      defaultKeyMap(),
+		cwd:       repo,
+		focus:     focusFiles,
+		diffSvc:   gitint.NewDiffService(),
+		fileItems: []gitint.FileItem{{Path: "b/y.txt", Status: "M.", SkipWorktree: true, OutsideSparse: true}},
+	}
+	m.fileCursor = len(m.fileTreeEntries()) - 1
+
+	next, load := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(Model)
+	if m.sparseConfirm == nil || m.sparseConfirm.Path != "b/y.txt" {
+		t.Fatalf("expected Enter to offer a sparse checkout of b/y.txt")
+	}
+	loaded, ok := load().(diffLoadedMsg)
+	if !ok || loaded.err != nil || loaded.empty || len(loaded.rows) == 0 || !strings.Contains(loaded.rows[0].OldText, "sparse checkout") {
+		t.Fatalf("diff = %+v, want the sparse notice instead of a deletion", loaded)
+	}
+
+	next, add := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	next, _ = next.(Model).Update(add())
+	m = next.(Model)
+	if !strings.Contains(m.alertMsg, "Added b to the sparse checkout") {
+		t.Fatalf("alert = %q", m.alertMsg)
+	}
+	if _, err := os.Stat(filepath.Join(repo, "b/y.txt")); err != nil {
+		t.Fatalf("b/y.txt should be checked out: %v", err)
+	}
+}
@@ -0,0 +1,34 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/x/ansi"
+
+	"diffman/internal/comments"
+	"diffman/internal/spell"
+)
+
+func TestCommentDockListsMisspelledWords(t *testing.T) {
+	m := Model{
+		width:              80,
+		comments:           map[string]comments.Comment{},
+		commentInputActive: true,
+		commentInputModel:  textinput.New(),
+		commentEditAnchor:  &commentAnchor{Path: "a.go", Side: comments.SideNew, Line: 3},
+		spell:              spell.NewFromWords([]string{"please", "check", "this"}),
+	}
+	m.commentInputModel.SetValue("please chekc this `chekc`, teh end")
+
+	dock := ansi.Strip(m.renderCommentDock())
+	if !strings.Contains(dock, "Spelling: chekc, teh -> the, end") {
+		t.Fatalf("expected typos listed in dock, got:\n%s", dock)
+	}
+
+	m.spell = nil
+	if strings.Contains(ansi.Strip(m.renderCommentDock()), "Spelling:") {
+		t.Fatalf("expected no spelling line when spellcheck is off")
+	}
+}
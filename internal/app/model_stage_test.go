@@ -0,0 +1,59 @@
+package app
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	gitint "diffman/internal/git"
+)
+
+func TestAddKeyStagesUntrackedFile(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if os.Getenv("GIT_DIR") != "" {
+		t.Skip("GIT_DIR is set")
+	}
+	repo := t.TempDir()
+	gitCmd(t, repo, "init", "-q")
+	if err := os.WriteFile(filepath.Join(repo, "new.go"), []byte("package x\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	m := Model{
+		keys:      defaultKeyMap(),
+		cwd:       repo,
+		focus:     focusFiles,
+		fileItems: []gitint.FileItem{{Path: "new.go", Status: "??"}},
+	}
+
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	if cmd == nil {
+		t.Fatalf("expected a to run git add, alert %q", next.(Model).alertMsg)
+	}
+	next, _ = next.(Model).Update(cmd())
+	m = next.(Model)
+	if !m.loadingFiles || !strings.Contains(m.alertMsg, "Added new.go") {
+		t.Fatalf("loadingFiles = %v, alert = %q", m.loadingFiles, m.alertMsg)
+	}
+	items, err := gitint.NewStatusService().ListChangedFiles(t.Context(), repo)
+	if err != nil || len(items) != 1 || !items[0].HasStaged {
+		t.Fatalf("status = %+v, %v; want new.go staged", items, err)
+	}
+}
+
+func TestAddKeyIgnoresTrackedFiles(t *testing.T) {
+	m := Model{
+		keys:      defaultKeyMap(),
+		focus:     focusFiles,
+		fileItems: []gitint.FileItem{{Path: "a.go", Status: ".M"}},
+	}
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a"), Alt: true})
+	if cmd != nil || next.(Model).alertMsg != "a.go is already tracked." {
+		t.Fatalf("alert = %q", next.(Model).alertMsg)
+	}
+}
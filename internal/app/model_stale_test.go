@@ -0,0 +1,116 @@
+package app
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+
+	"diffman/internal/comments"
+	"diffman/internal/diffview"
+	gitint "diffman/internal/git"
+)
+
+func TestBuildCommentStaleReasonsClassifiesEachCause(t *testing.T) {
+	items := []gitint.FileItem{{Path: "a.go"}, {Path: "broken.go"}, {Path: "empty.go"}}
+	all := []comments.Comment{
+		{Path: "a.go", Side: comments.SideNew, Line: 1},
+		{Path: "a.go", Side: comments.SideNew, Line: 9},
+		{Path: "gone.go", Side: comments.SideNew, Line: 1},
+		{Path: "broken.go", Side: comments.SideOld, Line: 2},
+		{Path: "empty.go", Side: comments.SideNew, Line: 3},
+	}
+
+	reasons, err := buildCommentStaleReasonsFromRowsLoader(items, all, func(path string) ([]diffview.DiffRow, bool, error) {
+		switch path {
+		case "broken.go":
+			return nil, false, errors.New("boom")
+		case "empty.go":
+			return nil, true, nil
+		}
+		return []diffview.DiffRow{{Kind: diffview.RowAdd, Path: path, NewLine: 1, NewText: "x"}}, false, nil
+	})
+	if err == nil {
+		t.Fatalf("expected the load error to be reported")
+	}
+
+	want := map[string]staleReason{
+		comments.AnchorKey("a.go", comments.SideNew, 1):      staleReasonNone,
+		comments.AnchorKey("a.go", comments.SideNew, 9):      staleReasonLineGone,
+		comments.AnchorKey("gone.go", comments.SideNew, 1):   staleReasonFileUnchanged,
+		comments.AnchorKey("broken.go", comments.SideOld, 2): staleReasonDiffFailed,
+		comments.AnchorKey("empty.go", comments.SideNew, 3):  staleReasonFileUnchanged,
+	}
+	for k, r := range want {
+		if reasons[k] != r {
+			t.Fatalf("reason for %s = %q, want %q", k, reasons[k], r)
+		}
+	}
+	if stale := staleMapFromReasons(reasons); stale[comments.AnchorKey("a.go", comments.SideNew, 1)] || !stale[comments.AnchorKey("a.go", comments.SideNew, 9)] {
+		t.Fatalf("unexpected stale map %v", stale)
+	}
+}
+
+func TestRenderCommentsPaneShowsStaleReasonAndCapturedContext(t *testing.T) {
+	c := comments.Comment{
+		Path:          "a.go",
+		Side:          comments.SideNew,
+		Line:          9,
+		Body:          "rename this",
+		HunkHeader:    "@@ -5,3 +5,4 @@",
+		ContextBefore: []string{"func a() {"},
+		ContextAfter:  []string{"x := 1", "}"},
+	}
+	key := commentKey(c)
+	m := Model{
+		width:               100,
+		height:              30,
+		comments:            map[string]comments.Comment{key: c},
+		commentStale:        map[string]bool{key: true},
+		commentStaleReasons: map[string]staleReason{key: staleReasonLineGone},
+		commentsCursor:      1,
+	}
+
+	out := ansi.Strip(m.renderCommentsPane(80, 20))
+	for _, want := range []string{"a.go (1)", "new:9 [line gone from diff]", "Stale: line gone from diff", "@@ -5,3 +5,4 @@", "> x := 1"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in comments pane:\n%s", want, out)
+		}
+	}
+}
+
+func TestStalePopupOpensOnEnterAndDeletes(t *testing.T) {
+	c := comments.Comment{Path: "a.go", Side: comments.SideNew, Line: 9, Body: "rename this", HunkHeader: "@@ -5,3 +5,4 @@"}
+	key := commentKey(c)
+	m := Model{
+		keys:                defaultKeyMap(),
+		focus:               focusComments,
+		width:               100,
+		height:              30,
+		commentStore:        comments.NewStore(t.TempDir()),
+		comments:            map[string]comments.Comment{key: c},
+		commentStale:        map[string]bool{key: true},
+		commentStaleReasons: map[string]staleReason{key: staleReasonDiffFailed},
+		commentsCursor:      1,
+	}
+
+	updated, _ := m.updateCommentsPane(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	if m.stalePopupKey != key {
+		t.Fatalf("expected enter on a stale comment to open the popup")
+	}
+	popup := ansi.Strip(m.renderStalePopup())
+	for _, want := range []string{"Reason: diff failed to load", "@@ -5,3 +5,4 @@", "rename this"} {
+		if !strings.Contains(popup, want) {
+			t.Fatalf("expected %q in popup:\n%s", want, popup)
+		}
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	m = updated.(Model)
+	if m.stalePopupKey != "" || len(m.comments) != 0 {
+		t.Fatalf("expected d to delete the comment and close the popup")
+	}
+}
@@ -0,0 +1,74 @@
+package app
+
+import (
+	"testing"
+
+	"diffman/internal/comments"
+	gitint "diffman/internal/git"
+	"diffman/internal/session"
+)
+
+func TestStartAtCommentOpensFirstChangedFileWithComments(t *testing.T) {
+	gone := comments.Comment{Path: "a.go", Side: comments.SideNew, Line: 1, Body: "old file"}
+	c := comments.Comment{Path: "c.go", Side: comments.SideOld, Line: 4, Body: "why?"}
+	m := Model{
+		startAt:   "comment",
+		fileItems: []gitint.FileItem{{Path: "b.go"}, {Path: "c.go"}},
+		comments:  map[string]comments.Comment{commentKey(gone): gone, commentKey(c): c},
+	}
+	m.applyStartAt()
+	if m.selectedF != "c.go" || m.focus != focusDiff || m.pendingCommentJump == nil || m.pendingCommentJump.Line != 4 {
+		t.Fatalf("expected c.go:4 pending, got %q %+v", m.selectedF, m.pendingCommentJump)
+	}
+	if m.startAt != "" {
+		t.Fatalf("expected start_at applied only once")
+	}
+}
+
+func TestStartAtStaleWaitsForTheStaleCheck(t *testing.T) {
+	fresh := comments.Comment{Path: "a.go", Side: comments.SideNew, Line: 1, Body: "fine"}
+	stale := comments.Comment{Path: "b.go", Side: comments.SideNew, Line: 2, Body: "moved"}
+	m := Model{
+		startAt:   "stale",
+		focus:     focusFiles,
+		fileItems: []gitint.FileItem{{Path: "a.go"}, {Path: "b.go"}},
+		comments:  map[string]comments.Comment{commentKey(fresh): fresh, commentKey(stale): stale},
+	}
+	m.applyStartAt()
+	if m.startAt != "stale" || m.focus != focusFiles {
+		t.Fatalf("expected stale start to wait for the stale check")
+	}
+
+	next, _ := m.Update(commentStaleLoadedMsg{stale: map[string]bool{commentKey(stale): true}})
+	m = next.(Model)
+	got, ok := m.commentAtCursor()
+	if m.focus != focusComments || !ok || commentKey(got) != commentKey(stale) {
+		t.Fatalf("expected the comments view on the stale comment, got focus %v and %+v", m.focus, got)
+	}
+	if m.commentsReturn != focusFiles {
+		t.Fatalf("expected closing the comments view to return to the files pane")
+	}
+}
+
+func TestResumePositionRoundTripsThroughTheSession(t *testing.T) {
+	store := session.NewStore(t.TempDir())
+	m := Model{
+		sessionStore: store,
+		diffRows:     addedRows("b.go", 10),
+		diffCursor:   7,
+	}
+	if err := m.SaveSessionPosition(); err != nil {
+		t.Fatalf("SaveSessionPosition() error = %v", err)
+	}
+	state, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	next := Model{startAt: "resume", fileItems: []gitint.FileItem{{Path: "a.go"}, {Path: "b.go"}}}
+	next.applySessionState(state)
+	next.applyStartAt()
+	if next.selectedF != "b.go" || next.pendingLocation == nil || next.pendingLocation.Line != 7 {
+		t.Fatalf("expected b.go:7 pending, got %q %+v", next.selectedF, next.pendingLocation)
+	}
+}
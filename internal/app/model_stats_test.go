@@ -0,0 +1,84 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"diffman/internal/comments"
+	gitint "diffman/internal/git"
+)
+
+func TestReviewTimeIsChargedToTheSelectedFileWithIdleCap(t *testing.T) {
+	m := Model{}
+	start := time.Now()
+	m.trackReviewTime("a.go", start)
+	m.trackReviewTime("a.go", start.Add(30*time.Second))
+	m.trackReviewTime("b.go", start.Add(time.Hour))
+	m.trackReviewTime("", start.Add(time.Hour+10*time.Second))
+
+	if got := m.reviewTimes["a.go"]; got != 30*time.Second {
+		t.Fatalf("a.go time = %v, want 30s", got)
+	}
+	if got := m.reviewTimes["b.go"]; got != reviewIdleLimit {
+		t.Fatalf("b.go time = %v, want the idle cap", got)
+	}
+	if want := 30*time.Second + reviewIdleLimit + 10*time.Second; m.reviewTotal != want {
+		t.Fatalf("total = %v, want %v", m.reviewTotal, want)
+	}
+}
+
+func TestStatsViewAndExportSummary(t *testing.T) {
+	c := comments.Comment{Path: "b.go", Side: comments.SideNew, Line: 1, Body: "why?"}
+	m := Model{
+		keys:        defaultKeyMap(),
+		width:       100,
+		height:      30,
+		fileItems:   []gitint.FileItem{{Path: "a.go"}, {Path: "b.go"}},
+		comments:    map[string]comments.Comment{commentKey(c): c},
+		reviewTimes: map[string]time.Duration{"b.go": 90 * time.Second},
+		reviewTotal: 2 * time.Minute,
+		reviewLines: map[string][]reviewedLine{"b.go": {{line: 1}, {line: 2}}},
+		reviewed:    map[string]map[reviewedLine]bool{"b.go": {{line: 1}: true}},
+	}
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("I")})
+	m = next.(Model)
+	if !m.statsOpen || !strings.Contains(m.renderStatsModal(), "Review statistics") {
+		t.Fatalf("expected I to open the statistics view")
+	}
+
+	text := m.reviewStatsText()
+	for _, want := range []string{
+		"Review summary: 2m00s on 1 of 2 file(s), 1 comment(s), 50% of opened lines reviewed",
+		"- b.go: 1m30s, 1 comment(s), 50% reviewed",
+		"- a.go: 0s, 0 comment(s), not opened",
+	} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("expected %q in summary, got:\n%s", want, text)
+		}
+	}
+	if strings.Index(text, "b.go") > strings.Index(text, "a.go") {
+		t.Fatalf("expected files ordered by time spent, got:\n%s", text)
+	}
+
+	if m.exportReviewStats() != "" {
+		t.Fatalf("expected no summary in exports unless configured")
+	}
+	m.exportStats = true
+	path := filepath.Join(t.TempDir(), "review.txt")
+	if err := writeExportFile(path, ExportFormatPlain, exportFrame{}, []comments.Comment{c}, comments.ExportOptions{}, m.exportReviewStats()); err != nil {
+		t.Fatalf("writeExportFile() error = %v", err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if !strings.Contains(string(b), "\n\nReview summary: ") {
+		t.Fatalf("expected the summary after the comments, got:\n%s", b)
+	}
+}
@@ -0,0 +1,49 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"diffman/internal/diffview"
+	gitint "diffman/internal/git"
+)
+
+func TestLocalDiffRowsShowsUntrackedFilesAsAdded(t *testing.T) {
+	svc := stubDiffService{
+		diffs:     map[string]string{"a.go": "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1 +1 @@\n-x\n+y\n"},
+		untracked: map[string]string{"new.go": "package new\n\nfunc F() {}\n"},
+	}
+
+	rows, empty, err := localDiffRows(t.Context(), svc, "/repo", "new.go", "", gitint.DiffModeAll)
+	if err != nil || empty {
+		t.Fatalf("localDiffRows() empty=%v err=%v", empty, err)
+	}
+	if len(rows) != 4 || rows[3].Kind != diffview.RowAdd || rows[3].NewLine != 3 {
+		t.Fatalf("expected 3 added lines, got %+v", rows)
+	}
+
+	if rows, _, _ := localDiffRows(t.Context(), svc, "/repo", "a.go", "", gitint.DiffModeAll); len(rows) != 2 || rows[1].Kind != diffview.RowChange {
+		t.Fatalf("expected the git diff for tracked files, got %+v", rows)
+	}
+	if _, empty, err := localDiffRows(t.Context(), svc, "/repo", "new.go", "", gitint.DiffModeStaged); !empty || err != nil {
+		t.Fatalf("expected nothing staged for an untracked file, got empty=%v err=%v", empty, err)
+	}
+	if _, empty, err := localDiffRows(t.Context(), svc, "/repo", "gone.go", "", gitint.DiffModeAll); !empty || err != nil {
+		t.Fatalf("expected an empty diff, got empty=%v err=%v", empty, err)
+	}
+}
+
+func TestUntrackedRowsForBinaryAndLargeFiles(t *testing.T) {
+	rows := untrackedRows(gitint.UntrackedFile{Path: "logo.png", Size: 2048, Binary: true})
+	if len(rows) != 1 || rows[0].Kind != diffview.RowHunkHeader || !strings.Contains(rows[0].OldText, "binary file (2.0 KiB)") {
+		t.Fatalf("unexpected binary rows %+v", rows)
+	}
+	rows = untrackedRows(gitint.UntrackedFile{Path: "dump.log", Size: 3 << 20, TooLarge: true})
+	if len(rows) != 1 || !strings.Contains(rows[0].OldText, "3.0 MiB") {
+		t.Fatalf("unexpected large-file rows %+v", rows)
+	}
+	rows = untrackedRows(gitint.UntrackedFile{Path: "empty.txt"})
+	if len(rows) != 1 || rows[0].OldText != "Empty untracked file" {
+		t.Fatalf("unexpected empty-file rows %+v", rows)
+	}
+}
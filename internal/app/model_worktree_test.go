@@ -0,0 +1,264 @@
+package app
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"unsafe"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"diffman/internal/comments"
+	gitint "diffman/internal/git"
+	"diffman/internal/githubpr"
+	"diffman/internal/history"
+)
+
+func gitCmd(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com",
+		"GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com",
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v (%s)", args, err, out)
+	}
+}
+
+func TestWorktreePickerSwitchesToLinkedWorktree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if os.Getenv("GIT_DIR") != "" {
+		t.Skip("GIT_DIR is set")
+	}
+	base := t.TempDir()
+	mainDir := filepath.Join(base, "main")
+	linked := filepath.Join(base, "linked")
+	if err := os.MkdirAll(mainDir, 0o755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	gitCmd(t, mainDir, "init", "-q")
+	if err := os.WriteFile(filepath.Join(mainDir, "a.txt"), []byte("a\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	gitCmd(t, mainDir, "add", ".")
+	gitCmd(t, mainDir, "commit", "-q", "-m", "init")
+	gitCmd(t, mainDir, "worktree", "add", "-q", "-b", "feature", linked)
+
+	// Resolve symlinks (e.g. /tmp on macOS) the way git reports paths.
+	mainDir, _ = filepath.EvalSymlinks(mainDir)
+	linked, _ = filepath.EvalSymlinks(linked)
+	linkedGitDir, err := gitint.DiscoverGitDir(t.Context(), linked)
+	if err != nil {
+		t.Fatalf("DiscoverGitDir() error = %v", err)
+	}
+	note := comments.Comment{Path: "a.txt", Side: comments.SideNew, Line: 1, Body: "feature note"}
+	if err := comments.NewStore(linkedGitDir).Save([]comments.Comment{note}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	m := Model{keys: defaultKeyMap(), cwd: mainDir, comments: map[string]comments.Comment{}}
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("w")})
+	m = next.(Model)
+	if !m.worktreeOpen || len(m.worktreeItems) != 2 {
+		t.Fatalf("expected both worktrees listed, got %+v", m.worktreeItems)
+	}
+	if m.worktreeItems[m.worktreeCursor].Path != mainDir {
+		t.Fatalf("expected cursor on the current worktree")
+	}
+
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	m = next.(Model)
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(Model)
+	if cmd == nil || m.worktreeOpen || m.cwd != linked {
+		t.Fatalf("expected switch to %s, got cwd=%s err=%q", linked, m.cwd, m.worktreeErr)
+	}
+	if got := m.comments[commentKey(note)]; got.Body != "feature note" {
+		t.Fatalf("expected the linked worktree's comments, got %+v", m.comments)
+	}
+}
+
+func TestSwitchRepoDropsScopeAndRepositoryState(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if os.Getenv("GIT_DIR") != "" {
+		t.Skip("GIT_DIR is set")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	base := t.TempDir()
+	mainDir := filepath.Join(base, "main")
+	other := filepath.Join(base, "other")
+	for _, dir := range []string{mainDir, other} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("MkdirAll() error = %v", err)
+		}
+		gitCmd(t, dir, "init", "-q")
+		for _, name := range []string{"a.txt", "b.txt"} {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte("one\n"), 0o644); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+		}
+		gitCmd(t, dir, "add", ".")
+		gitCmd(t, dir, "commit", "-q", "-m", "init")
+	}
+	if err := os.WriteFile(filepath.Join(other, "b.txt"), []byte("two\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	scope := gitint.Scope{"a.txt"}
+	m := Model{
+		keys:         defaultKeyMap(),
+		cwd:          mainDir,
+		comments:     map[string]comments.Comment{},
+		scope:        scope,
+		statusSvc:    gitint.NewScopedStatusService(scope),
+		diffSvc:      gitint.NewDiffService(),
+		fileStats:    map[string]gitint.FileStat{"a.txt": {Added: 1}},
+		dirSummary:   &dirSummaryState{dir: "lib"},
+		refCompare:   &refCompareState{path: "a.txt"},
+		prQueueItems: []githubpr.Summary{{Number: 7}},
+		historyItems: []history.Review{{ID: "old"}},
+		historyOpen:  true,
+	}
+	cmd, err := m.switchRepo(other)
+	if err != nil || cmd == nil {
+		t.Fatalf("switchRepo() = %v", err)
+	}
+	if m.scope != nil || m.scopeStatusSegment() != "" {
+		t.Fatalf("expected the path scope dropped, got %v", m.scope)
+	}
+	if m.fileStats != nil || m.dirSummary != nil || m.refCompare != nil || m.prQueueItems != nil || m.historyItems != nil || m.historyOpen {
+		t.Fatalf("expected the previous repository's state cleared")
+	}
+	items, err := m.statusSvc.ListChangedFiles(t.Context(), m.cwd)
+	if err != nil || len(items) != 1 || items[0].Path != "b.txt" {
+		t.Fatalf("expected b.txt listed outside the old scope, got %+v, %v", items, err)
+	}
+}
+
+// resetKeptFields are the Model fields resetReviewState leaves alone: settings,
+// UI layout and input state, the comments and their stores, which switchRepo
+// replaces itself, and state that is not tied to the files under review.
+var resetKeptFields = []string{
+	// Settings and services.
+	"keys", "cwd", "diffMode", "reviewMode", "statusSvc", "diffSvc", "prSvc",
+	"diffLayout", "zoomSide", "lineNumbers", "wordWrap", "commentPlacement",
+	"hideComments", "maxLineColumns", "refreshInterval", "autoRefreshing",
+	"contextLines", "spell", "permalinks", "permalinkTemplates", "hooks",
+	"webhookURL", "tmuxMessage", "exportGroup", "exportNoContext", "exportFrame",
+	"exportOnQuit", "redactExports", "skipWhitespaceHunks", "fileIcons",
+	"fileSort", "scope", "excludes", "leaderCommands", "diffTool",
+	"hideIndexFlagged", "exportStats", "checklist", "cleanupAfterCommit",
+	"pprofAddr", "startAt",
+	// PR mode, set up by whoever changes the review.
+	"prCtx", "prDiffs", "prPicker", "loadingPRs",
+	// Layout.
+	"width", "height", "ready", "filePaneW", "splitPercent", "oldView",
+	"newView", "oldWidth", "newWidth", "fileHidden", "helpOpen",
+	// Inputs and modals that are closed when a switch can happen.
+	"commentsReturn", "commentsSort", "commentsFilter", "commentsFilterActive",
+	"commentsFilterInput", "commandInputActive", "commandInput",
+	"commandInputErr", "leaderPending", "commentInputActive",
+	"commentInputModel", "commentInputErr", "commentEditAnchor",
+	"commentEditKey", "draftDirty", "quitConfirmModal", "reviewInputActive",
+	"exportInputActive", "exportInputModel", "exportInputErr", "exportPath",
+	"exportFormat", "reviewInputModel", "reviewInputErr", "reviewActionModal",
+	"reviewBodyDraft", "reviewDraft", "cleanupConfirmModal", "worktreeOpen",
+	"worktreeItems", "worktreeCursor", "worktreeErr", "workspaceRoot",
+	"repoPickerOpen", "repoPickerItems", "repoPickerCursor", "repoPickerErr",
+	"clearConfirmModal", "publishConfirmModal", "prQueueDirty", "statsOpen",
+	"statsScroll", "checklistOpen", "checklistCursor",
+	// Comments and per-repository stores, replaced by switchRepo.
+	"comments", "commentStore", "commentsKey", "commentsStamp", "commentsBase",
+	"commentsCheckedAt", "sessionStore", "snapshotStore", "historyStore",
+	"recentStore", "lastExport", "sessionPosition", "checklistDone",
+	// Alerts, loading, and diagnostics.
+	"alertMsg", "alertUntil", "alertLog", "alertLogOpen", "alertLogScroll",
+	"loadingFiles", "loadingDiff", "spinner", "spinning", "filesLoadStart",
+	"diffLoadStart", "prsLoadStart", "diffLoads", "diffLoadSeq", "perfHUD",
+	"perf", "crash", "reviewTimerAt",
+}
+
+func TestResetReviewStateCoversModel(t *testing.T) {
+	kept := make(map[string]bool, len(resetKeptFields))
+	for _, name := range resetKeptFields {
+		if _, ok := reflect.TypeOf(Model{}).FieldByName(name); !ok {
+			t.Errorf("resetKeptFields lists %s, which Model no longer has", name)
+		}
+		kept[name] = true
+	}
+
+	var m, fresh Model
+	mv := reflect.ValueOf(&m).Elem()
+	for i := range mv.NumField() {
+		fillNonZero(settable(mv.Field(i)), 0)
+	}
+	m.resetReviewState()
+	fresh.resetReviewState()
+
+	fv := reflect.ValueOf(&fresh).Elem()
+	for i := range mv.NumField() {
+		name := mv.Type().Field(i).Name
+		if kept[name] {
+			continue
+		}
+		if !reflect.DeepEqual(settable(mv.Field(i)).Interface(), settable(fv.Field(i)).Interface()) {
+			t.Errorf("resetReviewState leaves Model.%s as it was; reset it there or add it to resetKeptFields", name)
+		}
+	}
+}
+
+func settable(v reflect.Value) reflect.Value {
+	return reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem()
+}
+
+// fillNonZero sets v to a value that differs from its zero value where it
+// can; interfaces, funcs and channels are left alone.
+func fillNonZero(v reflect.Value, depth int) {
+	if depth > 3 {
+		return
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(7)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v.SetUint(7)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(7)
+	case reflect.String:
+		v.SetString("x")
+	case reflect.Slice:
+		s := reflect.MakeSlice(v.Type(), 1, 1)
+		fillNonZero(s.Index(0), depth+1)
+		v.Set(s)
+	case reflect.Map:
+		mp := reflect.MakeMap(v.Type())
+		k := reflect.New(v.Type().Key()).Elem()
+		fillNonZero(k, depth+1)
+		e := reflect.New(v.Type().Elem()).Elem()
+		fillNonZero(e, depth+1)
+		mp.SetMapIndex(k, e)
+		v.Set(mp)
+	case reflect.Pointer:
+		p := reflect.New(v.Type().Elem())
+		fillNonZero(p.Elem(), depth+1)
+		v.Set(p)
+	case reflect.Struct:
+		for i := range v.NumField() {
+			fillNonZero(settable(v.Field(i)), depth+1)
+		}
+	case reflect.Array:
+		for i := range v.Len() {
+			fillNonZero(v.Index(i), depth+1)
+		}
+	}
+}
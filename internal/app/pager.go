@@ -0,0 +1,172 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+
+	"diffman/internal/comments"
+	"diffman/internal/config"
+	"diffman/internal/diffview"
+	gitint "diffman/internal/git"
+)
+
+// pagerMinSplitWidth is the narrowest output that still shows old and new
+// side by side; narrower output stacks the new side under the old one.
+const pagerMinSplitWidth = 100
+
+// PagerOptions controls WriteDiff.
+type PagerOptions struct {
+	// Diff is a unified diff to render, such as `git diff` piped in. When
+	// nil, the changes of the repository containing Repo are rendered
+	// with their comments.
+	Diff io.Reader
+	Repo string
+	// Path limits the output to one file; empty renders every file.
+	Path string
+	// Width is the output width in cells.
+	Width int
+	// Plain renders ASCII without colors; otherwise colors are written
+	// even when w is not a terminal, as pagers like less -R expect.
+	Plain bool
+	// SideBySide keeps old and new side by side at any width, for output
+	// read later at a known width such as CI logs.
+	SideBySide bool
+}
+
+// WriteDiff renders diffs to w the way the diff panes do, without the
+// interactive UI, for use as a pager or in pipes.
+func WriteDiff(ctx context.Context, w io.Writer, opts PagerOptions) error {
+	cfg, _, err := config.Load()
+	if err != nil {
+		cfg = config.Default()
+	}
+	plain := opts.Plain || cfg.Plain
+	if !plain && lipgloss.ColorProfile() == termenv.Ascii && !termenv.EnvNoColor() {
+		lipgloss.SetColorProfile(termenv.ANSI256)
+	}
+	diffview.InitializeThemeWithPalette(cfg.Theme, cfg.Palette)
+	diffview.SetPlainMode(plain)
+	diffview.SetHideEOLChanges(cfg.HideEOLChanges)
+
+	var rows []diffview.DiffRow
+	path := filepath.ToSlash(filepath.Clean(opts.Path))
+	m := Model{comments: map[string]comments.Comment{}, lineNumbers: lineNumberModeFromConfig(cfg.LineNumbers), wordWrap: cfg.WordWrap}
+	if opts.Diff != nil {
+		raw, err := io.ReadAll(opts.Diff)
+		if err != nil {
+			return err
+		}
+		if rows, err = diffview.ParseUnifiedDiff(raw); err != nil {
+			return fmt.Errorf("parse diff: %w", err)
+		}
+	} else {
+		var root string
+		if rows, root, m, err = repoDiffRows(ctx, opts.Repo, m); err != nil {
+			return err
+		}
+		if opts.Path != "" {
+			if path, err = locationPath(root, opts.Path); err != nil {
+				return err
+			}
+		}
+	}
+
+	width := max(20, opts.Width)
+	for _, file := range splitRowsByFile(rows) {
+		if opts.Path != "" && file[0].Path != path {
+			continue
+		}
+		if lfs, ok := lfsRows(file[0].Path, file); ok {
+			file = lfs
+		}
+		if _, err := io.WriteString(w, m.renderPagerFile(file, width, opts.SideBySide)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// repoDiffRows loads the diff of every changed file in the repository
+// containing dir, its root, and m holding the repository's comments.
+func repoDiffRows(ctx context.Context, dir string, m Model) ([]diffview.DiffRow, string, Model, error) {
+	s, err := newRPCServer(ctx, dir)
+	if err != nil {
+		return nil, "", m, err
+	}
+	all, err := s.store.Load()
+	if err != nil && !comments.IsRecovered(err) {
+		return nil, "", m, err
+	}
+	for _, c := range all {
+		m.comments[commentKey(c)] = c
+	}
+	items, err := s.statusSvc.ListChangedFiles(ctx, s.root)
+	if err != nil {
+		return nil, "", m, err
+	}
+	renames := renamedFrom(items)
+	var rows []diffview.DiffRow
+	for _, it := range items {
+		fileRows, _, err := localDiffRows(ctx, s.diffSvc, s.root, it.Path, renames[it.Path], gitint.DiffModeAll)
+		if err != nil {
+			return nil, "", m, fmt.Errorf("diff %s: %w", it.Path, err)
+		}
+		rows = append(rows, fileRows...)
+	}
+	return rows, s.root, m, nil
+}
+
+// splitRowsByFile groups consecutive rows of the same file.
+func splitRowsByFile(rows []diffview.DiffRow) [][]diffview.DiffRow {
+	var out [][]diffview.DiffRow
+	for i, row := range rows {
+		if i == 0 || row.Path != rows[i-1].Path {
+			out = append(out, nil)
+		}
+		out[len(out)-1] = append(out[len(out)-1], row)
+	}
+	return out
+}
+
+// renderPagerFile renders one file's rows under a heading, side by side when
+// width allows or split is set and the new side below the old one otherwise.
+func (m Model) renderPagerFile(rows []diffview.DiffRow, width int, split bool) string {
+	heading := lipgloss.NewStyle().Bold(true).Render(rows[0].Path)
+	rule := strings.Repeat("─", width)
+	sep := " │ "
+	if diffview.PlainMode() {
+		rule = strings.Repeat("-", width)
+		sep = " | "
+	}
+
+	var b strings.Builder
+	b.WriteString(heading + "\n" + rule + "\n")
+	if split || width >= pagerMinSplitWidth {
+		half := (width - len([]rune(sep))) / 2
+		out := m.renderPagerRows(rows, half, half)
+		for i := range out.OldLines {
+			b.WriteString(out.OldLines[i] + sep + out.NewLines[i] + "\n")
+		}
+	} else {
+		out := m.renderPagerRows(rows, width, width)
+		for _, line := range out.OldLines {
+			b.WriteString(line + "\n")
+		}
+		b.WriteString("\n")
+		for _, line := range out.NewLines {
+			b.WriteString(line + "\n")
+		}
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+func (m Model) renderPagerRows(rows []diffview.DiffRow, oldW, newW int) diffview.SplitRender {
+	return diffview.RenderSplitWithOptions(rows, oldW, newW, -1, m.hasComment, m.commentText, m.renderOptions())
+}
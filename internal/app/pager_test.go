@@ -0,0 +1,81 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"diffman/internal/diffview"
+)
+
+const pagerTestDiff = `diff --git a/a.go b/a.go
+--- a/a.go
++++ b/a.go
+@@ -1,2 +1,2 @@
+ one
+-two
++TWO
+diff --git a/b.go b/b.go
+--- a/b.go
++++ b/b.go
+@@ -1 +1,2 @@
+ keep
++added
+`
+
+func TestWriteDiffRendersPipedDiffSideBySide(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Cleanup(func() { diffview.SetPlainMode(false) })
+
+	var out strings.Builder
+	err := WriteDiff(t.Context(), &out, PagerOptions{Diff: strings.NewReader(pagerTestDiff), Width: 120, Plain: true})
+	if err != nil {
+		t.Fatalf("WriteDiff() error = %v", err)
+	}
+	text := out.String()
+	if strings.Contains(text, "\x1b[") {
+		t.Fatalf("plain output has escape codes:\n%s", text)
+	}
+	for _, want := range []string{"a.go\n", "b.go\n", "two", "TWO", "added", " | "} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("output missing %q:\n%s", want, text)
+		}
+	}
+	for _, line := range strings.Split(text, "\n") {
+		if strings.Contains(line, "two") && !strings.Contains(line, "TWO") {
+			t.Fatalf("old and new lines not side by side in %q", line)
+		}
+	}
+}
+
+func TestWriteDiffLimitsOutputToPath(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Cleanup(func() { diffview.SetPlainMode(false) })
+
+	var out strings.Builder
+	err := WriteDiff(t.Context(), &out, PagerOptions{Diff: strings.NewReader(pagerTestDiff), Path: "b.go", Width: 60, Plain: true})
+	if err != nil {
+		t.Fatalf("WriteDiff() error = %v", err)
+	}
+	if text := out.String(); strings.Contains(text, "a.go") || !strings.Contains(text, "added") {
+		t.Fatalf("output = %q, want only b.go", text)
+	}
+}
+
+func TestWriteDiffSideBySideAtNarrowWidth(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Cleanup(func() { diffview.SetPlainMode(false) })
+
+	var out strings.Builder
+	err := WriteDiff(t.Context(), &out, PagerOptions{Diff: strings.NewReader(pagerTestDiff), Width: 60, Plain: true, SideBySide: true})
+	if err != nil {
+		t.Fatalf("WriteDiff() error = %v", err)
+	}
+	for _, line := range strings.Split(strings.TrimRight(out.String(), "\n"), "\n") {
+		if line != "" && len([]rune(line)) > 60 {
+			t.Fatalf("line %q is wider than 60 columns", line)
+		}
+		if strings.Contains(line, "two") && !strings.Contains(line, "TWO") {
+			t.Fatalf("old and new lines not side by side in %q", line)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"diffman/internal/diffview"
+	"diffman/internal/util"
+)
+
+// perfStats holds the numbers behind the performance overlay. It is shared
+// by pointer because View, which times frames, runs on a copy of the Model.
+type perfStats struct {
+	mu          sync.Mutex
+	frame       time.Duration
+	slowest     time.Duration
+	frames      int
+	render      time.Duration
+	renderRows  int
+	renderLines int
+}
+
+// timeFrame records a frame that started at start; call it deferred.
+func (p *perfStats) timeFrame(start time.Time) {
+	if p == nil {
+		return
+	}
+	d := time.Since(start)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.frame = d
+	if d > p.slowest {
+		p.slowest = d
+	}
+	p.frames++
+}
+
+func (p *perfStats) recordRender(d time.Duration, rows, lines int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.render = d
+	p.renderRows = rows
+	p.renderLines = lines
+}
+
+func (p *perfStats) snapshot() perfStats {
+	if p == nil {
+		return perfStats{}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return perfStats{
+		frame:       p.frame,
+		slowest:     p.slowest,
+		frames:      p.frames,
+		render:      p.render,
+		renderRows:  p.renderRows,
+		renderLines: p.renderLines,
+	}
+}
+
+func (m *Model) togglePerfHUD() {
+	m.perfHUD = !m.perfHUD
+	if m.perf == nil {
+		m.perf = &perfStats{}
+	}
+	if m.perfHUD {
+		// Count the slowest frame from when the overlay is opened.
+		m.perf.mu.Lock()
+		m.perf.slowest = 0
+		m.perf.frames = 0
+		m.perf.mu.Unlock()
+	}
+}
+
+func formatPerfDuration(d time.Duration) string {
+	if d <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+}
+
+// renderPerfHUD draws the small overlay in the top-right corner. Frame times
+// are those of the previous frame, since the current one is still drawing.
+func (m Model) renderPerfHUD() string {
+	stats := m.perf.snapshot()
+	_, git := util.LastCommand()
+	gitLine := "-"
+	if git.Name != "" {
+		gitLine = formatPerfDuration(git.Duration)
+		if len(git.Args) > 0 {
+			gitLine += " " + git.Args[0]
+		}
+		if git.Err != nil {
+			gitLine += " (failed)"
+		}
+	}
+
+	lines := []string{
+		fmt.Sprintf("frame  %s (max %s, %d)", formatPerfDuration(stats.frame), formatPerfDuration(stats.slowest), stats.frames),
+		fmt.Sprintf("diff   %s", formatPerfDuration(stats.render)),
+		fmt.Sprintf("rows   %d -> %d lines", stats.renderRows, stats.renderLines),
+		fmt.Sprintf("git    %s", gitLine),
+	}
+	if m.pprofAddr != "" {
+		lines = append(lines, "pprof  http://"+m.pprofAddr+"/debug/pprof/")
+	}
+	width := 0
+	for i, line := range lines {
+		lines[i] = ansi.Truncate(line, 44, "…")
+		width = max(width, ansi.StringWidth(lines[i]))
+	}
+
+	return lipgloss.NewStyle().
+		Width(width+2).
+		Padding(0, 1).
+		Border(diffview.Border(lipgloss.RoundedBorder())).
+		BorderForeground(lipgloss.Color("244")).
+		Foreground(lipgloss.Color("250")).
+		Render(strings.Join(lines, "\n"))
+}
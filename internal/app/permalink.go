@@ -0,0 +1,75 @@
+package app
+
+import (
+	"context"
+
+	"diffman/internal/comments"
+	gitint "diffman/internal/git"
+	"diffman/internal/githubpr"
+	"diffman/internal/permalink"
+)
+
+// permalinkSettings is what an export needs to link comments to the host.
+type permalinkSettings struct {
+	enabled   bool
+	templates map[string]string
+	cwd       string
+	pr        *githubpr.Context
+}
+
+func (m Model) permalinkSettings() permalinkSettings {
+	s := permalinkSettings{enabled: m.permalinks, templates: m.permalinkTemplates, cwd: m.cwd}
+	if m.reviewMode == reviewModePR && m.prCtx != nil {
+		pr := *m.prCtx
+		s.pr = &pr
+	}
+	return s
+}
+
+// linker resolves the remote and commit and returns a link function for
+// comments.ExportPlainWithLinks. It runs git, so call it off the UI loop. It
+// returns nil when permalinks are off or the remote is not recognised.
+//
+// In PR mode new-side lines link to the PR head commit and old-side lines to
+// the base commit, since the base branch moves on; a line whose commit is not
+// known gets no link. Locally both link to HEAD, so lines that are not committed
+// yet point at where they will land rather than at existing content.
+func (s permalinkSettings) linker(ctx context.Context) func(comments.Comment) string {
+	if !s.enabled {
+		return nil
+	}
+	if s.pr != nil {
+		pr := *s.pr
+		return func(c comments.Comment) string {
+			commit := pr.HeadSHA
+			if c.Side == comments.SideOld {
+				commit = pr.BaseSHA
+			}
+			if commit == "" {
+				return ""
+			}
+			u, _ := permalink.Build(s.templates, permalink.Target{
+				Host: "github.com", Repo: pr.Owner + "/" + pr.Repo, Commit: commit, Path: c.Path, Line: c.Line,
+			})
+			return u
+		}
+	}
+
+	remote, err := gitint.ReadOriginRemote(ctx, s.cwd)
+	if err != nil {
+		return nil
+	}
+	if _, ok := permalink.Template(s.templates, remote.Host); !ok {
+		return nil
+	}
+	sha, err := gitint.ReadHeadSHA(ctx, s.cwd)
+	if err != nil {
+		return nil
+	}
+	return func(c comments.Comment) string {
+		u, _ := permalink.Build(s.templates, permalink.Target{
+			Host: remote.Host, Repo: remote.Path, Commit: sha, Path: c.Path, Line: c.Line,
+		})
+		return u
+	}
+}
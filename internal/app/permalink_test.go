@@ -0,0 +1,28 @@
+package app
+
+import (
+	"testing"
+
+	"diffman/internal/comments"
+	"diffman/internal/githubpr"
+)
+
+func TestPRLinkerUsesBaseCommitForOldSide(t *testing.T) {
+	pr := githubpr.Context{Owner: "acme", Repo: "widgets", HeadSHA: "head123", BaseRef: "main", BaseSHA: "base456"}
+	link := permalinkSettings{enabled: true, pr: &pr}.linker(t.Context())
+	if link == nil {
+		t.Fatalf("expected a linker in PR mode")
+	}
+	if got, want := link(comments.Comment{Path: "a.go", Side: comments.SideOld, Line: 3}), "https://github.com/acme/widgets/blob/base456/a.go#L3"; got != want {
+		t.Fatalf("old-side link = %q want %q", got, want)
+	}
+	if got, want := link(comments.Comment{Path: "a.go", Side: comments.SideNew, Line: 5}), "https://github.com/acme/widgets/blob/head123/a.go#L5"; got != want {
+		t.Fatalf("new-side link = %q want %q", got, want)
+	}
+
+	pr.BaseSHA = ""
+	link = permalinkSettings{enabled: true, pr: &pr}.linker(t.Context())
+	if got := link(comments.Comment{Path: "a.go", Side: comments.SideOld, Line: 3}); got != "" {
+		t.Fatalf("expected no old-side link without the base commit, got %q", got)
+	}
+}
@@ -0,0 +1,76 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"diffman/internal/comments"
+)
+
+// importPRComments replaces previously imported review comments with the
+// PR's current ones. Your own comments win when both sit on the same line.
+func (m *Model) importPRComments() {
+	if m.prCtx == nil {
+		return
+	}
+	imported, err := m.prSvc.ListReviewComments(context.Background(), *m.prCtx)
+	if err != nil {
+		m.setAlert(fmt.Sprintf("failed to load PR review comments: %v", err))
+		return
+	}
+
+	for key, c := range m.comments {
+		if c.Author != "" {
+			delete(m.comments, key)
+		}
+	}
+	added, skipped := mergeImportedComments(m.comments, imported)
+	if err := m.persistComments(); err != nil {
+		m.setAlert(fmt.Sprintf("failed to save imported comments: %v", err))
+		return
+	}
+	msg := fmt.Sprintf("Imported %d review comment(s) from PR #%d.", added, m.prCtx.Number)
+	if skipped > 0 {
+		msg += fmt.Sprintf(" %d skipped on lines with your own comments.", skipped)
+	}
+	m.setAlert(msg)
+}
+
+// mergeImportedComments adds imported comments to dst. Comments that share a
+// line are folded into one, since the store keeps a single comment per anchor.
+func mergeImportedComments(dst map[string]comments.Comment, imported []comments.Comment) (added, skipped int) {
+	for _, c := range imported {
+		key := commentKey(c)
+		existing, ok := dst[key]
+		switch {
+		case !ok:
+			if c.ID == "" {
+				c.ID = comments.NewID()
+			}
+			dst[key] = c
+			added++
+		case existing.Author == "":
+			skipped++
+		default:
+			existing.Body = strings.TrimSpace(existing.Body) + "\n\n" + strings.TrimSpace(c.Body)
+			if !strings.Contains(", "+existing.Author+", ", ", "+c.Author+", ") {
+				existing.Author += ", " + c.Author
+			}
+			dst[key] = existing
+			added++
+		}
+	}
+	return added, skipped
+}
+
+// ownComments drops imported comments, which are already published on the PR.
+func ownComments(all []comments.Comment) []comments.Comment {
+	out := make([]comments.Comment, 0, len(all))
+	for _, c := range all {
+		if c.Author == "" {
+			out = append(out, c)
+		}
+	}
+	return out
+}
@@ -0,0 +1,246 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"diffman/internal/diffview"
+	gitint "diffman/internal/git"
+	"diffman/internal/githubpr"
+)
+
+type reviewQueueLoadedMsg struct {
+	items []githubpr.Summary
+	err   error
+}
+
+type reviewQueueCheckoutMsg struct {
+	ctx githubpr.Context
+	err error
+}
+
+// reviewQueueOfferMsg carries the queue listed on startup, which is offered
+// in an alert rather than opened.
+type reviewQueueOfferMsg struct {
+	items []githubpr.Summary
+	err   error
+}
+
+func (m Model) openReviewQueue() (tea.Model, tea.Cmd) {
+	if m.reviewMode == reviewModePR {
+		m.setAlert("Already reviewing a PR; q returns to the PR list.")
+		return m, nil
+	}
+	m.prQueueOpen = true
+	m.prQueueLoading = true
+	m.prQueueErr = ""
+	m.prQueueCursor = 0
+	return m, m.loadReviewQueueCmd()
+}
+
+func (m Model) loadReviewQueueCmd() tea.Cmd {
+	cwd := m.cwd
+	service := m.prSvc
+	return func() tea.Msg {
+		items, err := service.ListReviewQueue(context.Background(), cwd)
+		return reviewQueueLoadedMsg{items: items, err: err}
+	}
+}
+
+// offerReviewQueueCmd lists the queue in the background on startup. It fails
+// quietly without a GitHub remote or gh.
+func (m Model) offerReviewQueueCmd() tea.Cmd {
+	if m.prSvc == nil || m.reviewMode != reviewModeLocal {
+		return nil
+	}
+	cwd := m.cwd
+	service := m.prSvc
+	return func() tea.Msg {
+		items, err := service.ListReviewQueue(context.Background(), cwd)
+		return reviewQueueOfferMsg{items: items, err: err}
+	}
+}
+
+// checkoutReviewCmd resolves the PR and checks out its branch so it can be
+// reviewed locally.
+func (m Model) checkoutReviewCmd(number int) tea.Cmd {
+	cwd := m.cwd
+	service := m.prSvc
+	return func() tea.Msg {
+		ctx, err := service.ResolvePR(context.Background(), cwd, strconv.Itoa(number))
+		if err != nil {
+			return reviewQueueCheckoutMsg{err: err}
+		}
+		if err := service.Checkout(context.Background(), cwd, ctx); err != nil {
+			return reviewQueueCheckoutMsg{err: fmt.Errorf("check out PR #%d: %w", number, err)}
+		}
+		return reviewQueueCheckoutMsg{ctx: ctx}
+	}
+}
+
+func (m Model) handleReviewQueue(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.prQueueConfirm != nil {
+		return m.handleReviewQueueConfirm(msg)
+	}
+	switch {
+	case msg.Type == tea.KeyEsc, isRuneKey(msg, "q"), key.Matches(msg, m.keys.ReviewQueue):
+		m.prQueueOpen = false
+		return m, nil
+	case m.prQueueLoading:
+		return m, nil
+	case key.Matches(msg, m.keys.Refresh):
+		m.prQueueLoading = true
+		m.prQueueErr = ""
+		return m, m.loadReviewQueueCmd()
+	case key.Matches(msg, m.keys.Down):
+		if m.prQueueCursor < len(m.prQueueItems)-1 {
+			m.prQueueCursor++
+		}
+	case key.Matches(msg, m.keys.Up):
+		if m.prQueueCursor > 0 {
+			m.prQueueCursor--
+		}
+	case key.Matches(msg, m.keys.Open):
+		if len(m.prQueueItems) == 0 {
+			return m, nil
+		}
+		pr := m.prQueueItems[m.prQueueCursor]
+		m.prQueueConfirm = &pr
+		m.prQueueDirty = -1
+		if items, err := gitint.NewStatusService().ListChangedFiles(context.Background(), m.cwd); err == nil {
+			m.prQueueDirty = len(items)
+		}
+		return m, nil
+	}
+	return m, nil
+}
+
+// handleReviewQueueConfirm asks before gh pr checkout switches branches
+// under the working tree.
+func (m Model) handleReviewQueueConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.Type == tea.KeyEsc, isRuneKey(msg, "n"), isRuneKey(msg, "N"):
+		m.prQueueConfirm = nil
+	case msg.Type == tea.KeyEnter, isRuneKey(msg, "y"), isRuneKey(msg, "Y"):
+		number := m.prQueueConfirm.Number
+		m.prQueueConfirm = nil
+		m.prQueueLoading = true
+		m.prQueueErr = ""
+		return m, m.checkoutReviewCmd(number)
+	}
+	return m, nil
+}
+
+func (m Model) handleReviewQueueLoaded(msg reviewQueueLoadedMsg) (tea.Model, tea.Cmd) {
+	m.prQueueLoading = false
+	if msg.err != nil {
+		m.prQueueErr = fmt.Sprintf("failed to list PRs: %v", msg.err)
+		return m, nil
+	}
+	m.prQueueItems = msg.items
+	m.prQueueCursor = min(m.prQueueCursor, max(0, len(m.prQueueItems)-1))
+	return m, nil
+}
+
+func (m Model) handleReviewQueueOffer(msg reviewQueueOfferMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil || len(msg.items) == 0 || m.prQueueOpen || m.reviewMode != reviewModeLocal {
+		return m, nil
+	}
+	m.setAlert(fmt.Sprintf("%d PR(s) await your review; %s picks one.", len(msg.items), m.keys.ReviewQueue.Help().Key))
+	return m, nil
+}
+
+// handleReviewQueueCheckout switches from local review into PR mode for the
+// checked-out PR. The branch changed underneath the model, so everything
+// derived from the old files and diffs goes.
+func (m Model) handleReviewQueueCheckout(msg reviewQueueCheckoutMsg) (tea.Model, tea.Cmd) {
+	m.prQueueLoading = false
+	if msg.err != nil {
+		m.prQueueErr = msg.err.Error()
+		return m, nil
+	}
+	ctx := msg.ctx
+	m.resetReviewState()
+	m.reviewMode = reviewModePR
+	m.prCtx = &ctx
+	m.prPicker = false
+	m.prDiffs = make(map[string]prDiffCacheEntry)
+	m.loadingFiles = true
+	m.setAlert(fmt.Sprintf("Checked out PR #%d (%s).", ctx.Number, ctx.HeadRef))
+	return m, tea.Batch(m.loadFilesCmd(), m.loadHeadCmd())
+}
+
+func (m Model) renderReviewQueueModal() string {
+	width := max(24, min(100, m.width-10))
+	innerW := max(1, width-6)
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+
+	var lines []string
+	switch {
+	case m.prQueueLoading && len(m.prQueueItems) == 0:
+		lines = append(lines, "Loading pull requests…")
+	case len(m.prQueueItems) == 0 && m.prQueueErr == "":
+		lines = append(lines, "No open PRs request your review or are assigned to you.")
+	}
+	for i, pr := range m.prQueueItems {
+		marker := "  "
+		style := lipgloss.NewStyle()
+		if i == m.prQueueCursor {
+			marker = "> "
+			style = style.Foreground(lipgloss.Color("39")).Bold(true)
+		}
+		reason := ""
+		if pr.Reason != "" {
+			reason = " (" + pr.Reason + ")"
+		}
+		text := ansi.Truncate(fmt.Sprintf("%s#%d %s", marker, pr.Number, pr.Title), max(1, innerW-ansi.StringWidth(reason)), "…")
+		lines = append(lines, style.Render(text)+dim.Render(reason))
+	}
+	if m.prQueueLoading && len(m.prQueueItems) > 0 {
+		lines = append(lines, "", "Checking out…")
+	}
+	if m.prQueueErr != "" {
+		lines = append(lines, "", lipgloss.NewStyle().Foreground(lipgloss.Color("203")).Render(
+			ansi.Truncate("Error: "+m.prQueueErr, innerW, ""),
+		))
+	}
+	if m.prQueueConfirm != nil {
+		warn := lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+		lines = append(lines, "", ansi.Truncate(fmt.Sprintf("Check out PR #%d with gh pr checkout?", m.prQueueConfirm.Number), innerW, "…"))
+		switch {
+		case m.prQueueDirty > 0:
+			lines = append(lines, warn.Render(ansi.Truncate(fmt.Sprintf("%d uncommitted change(s) will be carried onto the PR branch or block the checkout.", m.prQueueDirty), innerW, "…")))
+		case m.prQueueDirty < 0:
+			lines = append(lines, warn.Render(ansi.Truncate("Could not check the working tree for uncommitted changes.", innerW, "…")))
+		}
+		lines = append(lines, "", dim.Render("Y/Enter check out | N/Esc cancel"))
+	} else {
+		lines = append(lines, "", dim.Render("j/k move | Enter check out and review | r reload | O/Esc close"))
+	}
+
+	title := lipgloss.NewStyle().
+		Width(max(1, width-2)).
+		Padding(0, 1).
+		Bold(true).
+		Foreground(lipgloss.Color("230")).
+		Background(lipgloss.Color("63")).
+		Render("Review Queue")
+
+	bodyBlock := lipgloss.NewStyle().
+		Width(max(1, width-2)).
+		Padding(1, 2).
+		Render(strings.Join(lines, "\n"))
+
+	return lipgloss.NewStyle().
+		Width(width).
+		Border(diffview.Border(lipgloss.RoundedBorder())).
+		BorderForeground(lipgloss.Color("63")).
+		Render(title + "\n" + bodyBlock)
+}
@@ -0,0 +1,110 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"diffman/internal/comments"
+	"diffman/internal/config"
+	"diffman/internal/diffview"
+	"diffman/internal/webhook"
+)
+
+type publishResultMsg struct {
+	host     string
+	exported []comments.Comment
+	err      error
+}
+
+// handlePublish asks before posting, since the export leaves the machine.
+func (m Model) handlePublish() (tea.Model, tea.Cmd) {
+	if m.webhookURL == "" {
+		m.setAlert("No webhook_url configured.")
+		return m, nil
+	}
+	if snapshot, scope := m.exportScope(); len(snapshot) == 0 {
+		m.setAlert(exportScopeEmptyMessage(scope))
+		return m, nil
+	}
+	m.publishConfirmModal = true
+	return m, nil
+}
+
+func (m Model) handlePublishConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.Type == tea.KeyEsc, isRuneKey(msg, "n"), isRuneKey(msg, "N"):
+		m.publishConfirmModal = false
+	case msg.Type == tea.KeyEnter, isRuneKey(msg, "y"), isRuneKey(msg, "Y"):
+		m.publishConfirmModal = false
+		m.setAlert(fmt.Sprintf("Publishing to %s...", webhook.Host(m.webhookURL)))
+		return m, m.publishCmd()
+	}
+	return m, nil
+}
+
+func (m Model) publishCmd() tea.Cmd {
+	snapshot, _ := m.exportScope()
+	links := m.permalinkSettings()
+	target := m.webhookURL
+	summary := m.exportSummary()
+	opts := m.exportOptions()
+	frame, root, branch := m.exportFrame, m.cwd, m.exportFrameBranch()
+	return func() tea.Msg {
+		ctx := context.Background()
+		opts.Link = links.linker(ctx)
+		frame := frame.fill(ctx, root, branch)
+		text := frame.withFooter(withExportSummary(comments.ExportPlainWithOptions(snapshot, frame.title, opts), summary))
+		err := webhook.Publish(ctx, nil, target, text)
+		return publishResultMsg{host: webhook.Host(target), exported: snapshot, err: err}
+	}
+}
+
+func (m *Model) handlePublishResult(msg publishResultMsg) tea.Cmd {
+	if msg.err != nil {
+		m.setAlert(fmt.Sprintf("publish failed: %v", msg.err))
+		return nil
+	}
+	m.setAlert(fmt.Sprintf("Published %d comment(s) to %s.", len(msg.exported), msg.host))
+	return m.hookCmd(hookPayload{Event: config.HookExport, Comments: msg.exported, Output: "webhook"})
+}
+
+func (m Model) renderPublishConfirmModal() string {
+	snapshot, scope := m.exportScope()
+	what := fmt.Sprintf("%d comment(s)", len(snapshot))
+	if scope != "" {
+		what = fmt.Sprintf("%d %s comment(s)", len(snapshot), scope)
+	}
+	body := strings.Join([]string{
+		fmt.Sprintf("Post %s to %s?", what, webhook.Host(m.webhookURL)),
+		"",
+		lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Render("Y/Enter publish | N/Esc cancel"),
+	}, "\n")
+
+	width := 54
+	if m.width > 0 && m.width-6 < width {
+		width = max(24, m.width-6)
+	}
+
+	title := lipgloss.NewStyle().
+		Width(max(1, width-2)).
+		Padding(0, 1).
+		Bold(true).
+		Foreground(lipgloss.Color("230")).
+		Background(lipgloss.Color("63")).
+		Render("Publish Review")
+
+	bodyBlock := lipgloss.NewStyle().
+		Width(max(1, width-2)).
+		Padding(1, 2).
+		Render(body)
+
+	return lipgloss.NewStyle().
+		Width(width).
+		Border(diffview.Border(lipgloss.RoundedBorder())).
+		BorderForeground(lipgloss.Color("63")).
+		Render(title + "\n" + bodyBlock)
+}
@@ -0,0 +1,52 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"diffman/internal/comments"
+	"diffman/internal/config"
+)
+
+// ExportOnQuit exports the non-stale comments as export_on_quit asks, so the
+// review text survives closing the terminal. It is called once the program
+// exits, and returns what it did, or "" when there was nothing to export.
+// Unlike y and W it ignores the comments view's selection and filter.
+func (m Model) ExportOnQuit() (string, error) {
+	if m.exportOnQuit == "" {
+		return "", nil
+	}
+	snapshot := comments.OrderForExport(m.exportableComments(), m.exportGroup)
+	if m.redactExports {
+		snapshot = comments.Redact(snapshot)
+	}
+	if len(snapshot) == 0 {
+		return "", nil
+	}
+	ctx := context.Background()
+	opts := m.exportOptions()
+	opts.Link = m.permalinkSettings().linker(ctx)
+	frame := m.exportFrame.fill(ctx, m.cwd, m.exportFrameBranch())
+	summary := m.exportSummary()
+
+	if m.exportOnQuit == config.ExportOnQuitClipboard {
+		text := frame.withFooter(withExportSummary(comments.ExportPlainWithOptions(snapshot, frame.title, opts), summary))
+		if err := copyExport(ctx, text, ""); err != nil {
+			return "", fmt.Errorf("copy %d comment(s) to clipboard: %w", len(snapshot), err)
+		}
+		return fmt.Sprintf("Copied %d comment(s) to clipboard.", len(snapshot)), nil
+	}
+	// The file and format last used with W, or the defaults.
+	raw := m.exportPath
+	if raw == "" {
+		raw = exportDefaultFile(m.exportFormat)
+	}
+	path, err := resolveExportPath(m.cwd, raw)
+	if err != nil {
+		return "", err
+	}
+	if err := writeExportFile(path, m.exportFormat, frame, snapshot, opts, summary); err != nil {
+		return "", fmt.Errorf("write %d comment(s) to %s: %w", len(snapshot), path, err)
+	}
+	return fmt.Sprintf("Wrote %d comment(s) to %s.", len(snapshot), path), nil
+}
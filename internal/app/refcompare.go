@@ -0,0 +1,173 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"diffman/internal/diffview"
+	gitint "diffman/internal/git"
+)
+
+// refCompareState is the selected file as it was at two revisions, shown
+// read-only over the review.
+type refCompareState struct {
+	path, from, to string
+	loading        bool
+	rows           []diffview.DiffRow
+	scroll         int
+}
+
+type refCompareLoadedMsg struct {
+	path, from, to string
+	rows           []diffview.DiffRow
+	err            error
+}
+
+// startCompareRefs opens the command line with the compare command typed.
+func (m Model) startCompareRefs() (tea.Model, tea.Cmd) {
+	next, cmd := m.startCommandInput()
+	m = next.(Model)
+	m.commandInput.SetValue("compare ")
+	m.commandInput.CursorEnd()
+	return m, cmd
+}
+
+// compareRefsCmd loads the selected file's diff between REF1 and REF2, which
+// defaults to HEAD.
+func (m *Model) compareRefsCmd(args []string) (tea.Cmd, error) {
+	switch {
+	case m.reviewMode == reviewModePR:
+		return nil, fmt.Errorf("comparing refs needs a local repository and is unavailable in PR mode")
+	case m.selectedF == "":
+		return nil, fmt.Errorf("select a file to compare")
+	case len(args) < 1 || len(args) > 2:
+		return nil, fmt.Errorf("usage: compare REF1 [REF2], where REF2 defaults to HEAD")
+	}
+	from, to := args[0], "HEAD"
+	if len(args) == 2 {
+		to = args[1]
+	}
+	root, path := m.cwd, m.selectedF
+	m.refCompare = &refCompareState{path: path, from: from, to: to, loading: true}
+	return func() tea.Msg {
+		d, err := gitint.DiffRefs(context.Background(), root, from, to, path)
+		msg := refCompareLoadedMsg{path: path, from: from, to: to, err: err}
+		if err == nil && strings.TrimSpace(d) != "" {
+			msg.rows, msg.err = diffview.ParseUnifiedDiff([]byte(d))
+		}
+		return msg
+	}, nil
+}
+
+func (m Model) handleRefCompareLoaded(msg refCompareLoadedMsg) (tea.Model, tea.Cmd) {
+	rc := m.refCompare
+	if rc == nil || rc.path != msg.path || rc.from != msg.from || rc.to != msg.to {
+		return m, nil
+	}
+	if msg.err != nil {
+		m.refCompare = nil
+		m.setAlert(fmt.Sprintf("failed to compare %s: %v", msg.path, msg.err))
+		return m, nil
+	}
+	rc.loading = false
+	rc.rows = msg.rows
+	return m, nil
+}
+
+func (m Model) handleRefCompare(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	rc := m.refCompare
+	page := max(1, m.refCompareVisible()-1)
+	switch {
+	case msg.Type == tea.KeyEsc, isRuneKey(msg, "q"), key.Matches(msg, m.keys.CompareRefs):
+		m.refCompare = nil
+		return m, nil
+	case key.Matches(msg, m.keys.Down):
+		rc.scroll++
+	case key.Matches(msg, m.keys.Up):
+		rc.scroll--
+	case key.Matches(msg, m.keys.PageDown):
+		rc.scroll += page
+	case key.Matches(msg, m.keys.PageUp):
+		rc.scroll -= page
+	case isRuneKey(msg, "g"):
+		rc.scroll = 0
+	case isRuneKey(msg, "G"):
+		rc.scroll = len(m.refCompareLines())
+	}
+	rc.scroll = max(0, min(rc.scroll, len(m.refCompareLines())-m.refCompareVisible()))
+	return m, nil
+}
+
+func (m Model) refCompareWidth() int {
+	return max(24, m.width-6)
+}
+
+func (m Model) refCompareVisible() int {
+	return max(3, m.height-12)
+}
+
+// refCompareLines renders the comparison without comments or a cursor: the
+// review's comments anchor to the current diff, not to these revisions.
+func (m Model) refCompareLines() []string {
+	rc := m.refCompare
+	if rc == nil || len(rc.rows) == 0 {
+		return nil
+	}
+	plain := m
+	plain.comments = nil
+	plain.copyMode = false
+	out := plain.renderPagerFile(rc.rows, max(1, m.refCompareWidth()-6), false)
+	// Skip the path heading and its rule, which the modal title repeats.
+	lines := strings.Split(strings.TrimRight(out, "\n"), "\n")
+	return lines[min(2, len(lines)):]
+}
+
+func (m Model) renderRefCompareModal() string {
+	rc := m.refCompare
+	width := m.refCompareWidth()
+	innerW := max(1, width-6)
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+
+	var lines []string
+	all := m.refCompareLines()
+	switch {
+	case rc.loading:
+		lines = append(lines, dim.Render("Loading…"))
+	case len(all) == 0:
+		lines = append(lines, dim.Render(ansi.Truncate(fmt.Sprintf("%s is identical at %s and %s.", rc.path, rc.from, rc.to), innerW, "…")))
+	default:
+		visible := m.refCompareVisible()
+		start := max(0, min(rc.scroll, len(all)-visible))
+		end := min(len(all), start+visible)
+		lines = append(lines, all[start:end]...)
+		if end < len(all) || start > 0 {
+			lines = append(lines, dim.Render(fmt.Sprintf("lines %d-%d of %d", start+1, end, len(all))))
+		}
+	}
+	lines = append(lines, "", dim.Render(ansi.Truncate("j/k scroll | ctrl+f/ctrl+b page | g/G top/bottom | Esc close", innerW, "")))
+
+	title := lipgloss.NewStyle().
+		Width(max(1, width-2)).
+		Padding(0, 1).
+		Bold(true).
+		Foreground(lipgloss.Color("230")).
+		Background(lipgloss.Color("63")).
+		Render(ansi.Truncate(fmt.Sprintf("%s: %s → %s", rc.path, rc.from, rc.to), max(1, width-4), "…"))
+
+	bodyBlock := lipgloss.NewStyle().
+		Width(max(1, width-2)).
+		Padding(1, 2).
+		Render(strings.Join(lines, "\n"))
+
+	return lipgloss.NewStyle().
+		Width(width).
+		Border(diffview.Border(lipgloss.RoundedBorder())).
+		BorderForeground(lipgloss.Color("63")).
+		Render(title + "\n" + bodyBlock)
+}
@@ -0,0 +1,103 @@
+package app
+
+import (
+	"diffman/internal/comments"
+	"diffman/internal/diffview"
+)
+
+// refreshAnchor remembers where the diff and comments views were when a
+// manual refresh started, so the reloaded views open at the same place.
+type refreshAnchor struct {
+	// path, side and line name the diff line under the cursor; top is how
+	// far below the top of the pane it was.
+	path string
+	side comments.Side
+	line int
+	top  int
+	// commentID is the comment under the comments view cursor and
+	// commentsTop its distance from the top of the list.
+	commentID   string
+	commentsTop int
+}
+
+// captureRefreshAnchor records the diff line and comment under the cursors.
+func (m Model) captureRefreshAnchor() *refreshAnchor {
+	a := &refreshAnchor{}
+	if m.diffCursor >= 0 && m.diffCursor < len(m.diffRows) {
+		row := m.diffRows[m.diffCursor]
+		switch {
+		case row.NewLine > 0:
+			a.path, a.side, a.line = row.Path, comments.SideNew, row.NewLine
+		case row.OldLine > 0:
+			a.path, a.side, a.line = row.Path, comments.SideOld, row.OldLine
+		}
+		start, _ := m.cursorVisualRange()
+		a.top = start - m.oldView.YOffset
+	}
+	if c, ok := m.commentAtCursor(); ok {
+		a.commentID = c.ID
+		a.commentsTop = m.commentsCursor - m.commentsScroll
+	}
+	return a
+}
+
+// restoreDiffAnchor puts the diff cursor back on the anchored line of path,
+// or the nearest line on the same side, at the same height in the pane.
+func (m *Model) restoreDiffAnchor(path string) {
+	a := m.refreshAnchor
+	if a == nil || a.line == 0 {
+		return
+	}
+	want := a.line
+	a.line = 0
+	if a.path != path {
+		return
+	}
+	best, bestDist := -1, 0
+	for i, row := range m.diffRows {
+		if row.Path != path || row.Kind == diffview.RowHunkHeader {
+			continue
+		}
+		line := row.NewLine
+		if a.side == comments.SideOld {
+			line = row.OldLine
+		}
+		if line == 0 {
+			continue
+		}
+		dist := max(line-want, want-line)
+		if best < 0 || dist < bestDist {
+			best, bestDist = i, dist
+		}
+	}
+	if best < 0 {
+		return
+	}
+	m.diffCursor = best
+	start, _ := m.cursorVisualRange()
+	top := max(0, start-a.top)
+	m.oldView.SetYOffset(top)
+	m.newView.SetYOffset(top)
+	m.ensureCursorVisible()
+	m.restyleVisibleDiff()
+}
+
+// restoreCommentsAnchor puts the comments view cursor back on the anchored
+// comment, which the stale check may have regrouped.
+func (m *Model) restoreCommentsAnchor() {
+	a := m.refreshAnchor
+	if a == nil || a.commentID == "" {
+		return
+	}
+	id := a.commentID
+	a.commentID = ""
+	rows := m.commentsViewRows()
+	for i, row := range rows {
+		if !row.Header && row.Comment.ID == id {
+			m.commentsCursor = i
+			m.commentsScroll = max(0, i-a.commentsTop)
+			m.ensureCommentsCursorVisible(rows)
+			return
+		}
+	}
+}
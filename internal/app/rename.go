@@ -0,0 +1,63 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	gitint "diffman/internal/git"
+)
+
+// renamedFrom maps each renamed or copied path to the path it came from.
+func renamedFrom(items []gitint.FileItem) map[string]string {
+	out := make(map[string]string)
+	for _, it := range items {
+		if it.Renamed() {
+			out[it.Path] = it.OrigPath
+		}
+	}
+	return out
+}
+
+// followRenamedComments moves comments left on a file's old path to its new
+// one once git reports the rename, so they stay attached to the file instead
+// of going stale. Copies keep their source, so their comments stay put.
+func (m *Model) followRenamedComments(items []gitint.FileItem) {
+	listed := make(map[string]bool, len(items))
+	for _, it := range items {
+		listed[it.Path] = true
+	}
+	moves := make(map[string]string)
+	for _, it := range items {
+		if it.Renamed() && strings.Contains(it.Status, "R") && !listed[it.OrigPath] {
+			moves[it.OrigPath] = it.Path
+		}
+	}
+	if len(moves) == 0 {
+		return
+	}
+
+	moved := 0
+	for _, c := range m.sortedComments() {
+		to, ok := moves[c.Path]
+		if !ok {
+			continue
+		}
+		k := commentKey(c)
+		c.Path = to
+		nk := commentKey(c)
+		if _, taken := m.comments[nk]; taken {
+			continue
+		}
+		delete(m.comments, k)
+		m.comments[nk] = c
+		moved++
+	}
+	if moved == 0 {
+		return
+	}
+	if err := m.persistComments(); err != nil {
+		m.setAlert(fmt.Sprintf("failed to save comments: %v", err))
+		return
+	}
+	m.setAlert(fmt.Sprintf("Moved %d comment(s) to renamed files.", moved))
+}
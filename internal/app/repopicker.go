@@ -0,0 +1,237 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"diffman/internal/diffview"
+	gitint "diffman/internal/git"
+)
+
+// outermostRepoRoot climbs from root to the top-level repository that
+// contains it, so a picker opened inside a nested repository still lists its
+// siblings.
+func outermostRepoRoot(ctx context.Context, root string) string {
+	for {
+		parent := filepath.Dir(root)
+		if parent == root {
+			return root
+		}
+		outer, err := gitint.DiscoverRepoRoot(ctx, parent)
+		if err != nil || outer == "" || filepath.Clean(outer) == filepath.Clean(root) {
+			return root
+		}
+		root = outer
+	}
+}
+
+// repoChoice is one entry of the repository picker: a repository nested in
+// the workspace, or one opened recently elsewhere.
+type repoChoice struct {
+	Path   string
+	Recent bool
+}
+
+func (m Model) openRepoPicker() (tea.Model, tea.Cmd) {
+	if m.reviewMode != reviewModeLocal {
+		m.setAlert("Repository switching is only available for local review.")
+		return m, nil
+	}
+	if os.Getenv("GIT_DIR") != "" {
+		m.setAlert("GIT_DIR is set, so diffman stays on that repository.")
+		return m, nil
+	}
+	if m.workspaceRoot == "" || !isWithinDir(m.cwd, m.workspaceRoot) {
+		m.workspaceRoot = outermostRepoRoot(context.Background(), m.cwd)
+	}
+	choices, err := m.repoChoices()
+	if err != nil {
+		m.setAlert(fmt.Sprintf("failed to find repositories: %v", err))
+		return m, nil
+	}
+	if len(choices) <= 1 {
+		m.setAlert("No nested or recently opened repositories to switch to.")
+		return m, nil
+	}
+	m.showRepoPicker(choices)
+	return m, nil
+}
+
+// repoChoices lists the repositories under the workspace root followed by
+// recently opened ones that still exist.
+func (m Model) repoChoices() ([]repoChoice, error) {
+	var choices []repoChoice
+	if m.workspaceRoot != "" {
+		nested, err := gitint.FindRepos(m.workspaceRoot, gitint.NestedRepoDepth)
+		if err != nil {
+			return nil, err
+		}
+		for _, dir := range nested {
+			choices = append(choices, repoChoice{Path: dir})
+		}
+	}
+	recents, err := m.recentStore.Load()
+	if err != nil {
+		return nil, fmt.Errorf("load recent repositories: %w", err)
+	}
+	for _, r := range recents {
+		if info, err := os.Stat(r.Path); err != nil || !info.IsDir() {
+			continue
+		}
+		listed := false
+		for _, c := range choices {
+			if sameDir(c.Path, r.Path) {
+				listed = true
+				break
+			}
+		}
+		if !listed {
+			choices = append(choices, repoChoice{Path: r.Path, Recent: true})
+		}
+	}
+	return choices, nil
+}
+
+func (m *Model) showRepoPicker(items []repoChoice) {
+	m.repoPickerOpen = true
+	m.repoPickerItems = items
+	m.repoPickerErr = ""
+	m.repoPickerCursor = 0
+	for i, c := range items {
+		if sameDir(c.Path, m.cwd) {
+			m.repoPickerCursor = i
+		}
+	}
+}
+
+func (m Model) handleRepoPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.Type == tea.KeyEsc, isRuneKey(msg, "q"), key.Matches(msg, m.keys.Repos):
+		m.repoPickerOpen = false
+	case key.Matches(msg, m.keys.Down):
+		if m.repoPickerCursor < len(m.repoPickerItems)-1 {
+			m.repoPickerCursor++
+		}
+	case key.Matches(msg, m.keys.Up):
+		if m.repoPickerCursor > 0 {
+			m.repoPickerCursor--
+		}
+	case key.Matches(msg, m.keys.Open):
+		if len(m.repoPickerItems) == 0 {
+			return m, nil
+		}
+		dir := m.repoPickerItems[m.repoPickerCursor].Path
+		if sameDir(dir, m.cwd) {
+			m.repoPickerOpen = false
+			return m, nil
+		}
+		cmd, err := m.switchRepo(dir)
+		if err != nil {
+			m.repoPickerErr = err.Error()
+			return m, nil
+		}
+		m.repoPickerOpen = false
+		m.setAlert("Switched to repository " + m.repoLabel(dir) + ".")
+		return m, cmd
+	}
+	return m, nil
+}
+
+func isWithinDir(dir, root string) bool {
+	rel, err := filepath.Rel(root, dir)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// sameDir compares directories after resolving symlinks, since git reports
+// resolved paths while a directory walk keeps the ones it was given.
+func sameDir(a, b string) bool {
+	if ra, err := filepath.EvalSymlinks(a); err == nil {
+		a = ra
+	}
+	if rb, err := filepath.EvalSymlinks(b); err == nil {
+		b = rb
+	}
+	return filepath.Clean(a) == filepath.Clean(b)
+}
+
+// repoLabel names a repository relative to the workspace root, or by its
+// home-relative path when it lies outside it.
+func (m Model) repoLabel(dir string) string {
+	if m.workspaceRoot == "" || !isWithinDir(dir, m.workspaceRoot) {
+		if home, err := os.UserHomeDir(); err == nil && isWithinDir(dir, home) {
+			rel, _ := filepath.Rel(home, dir)
+			return filepath.ToSlash(filepath.Join("~", rel))
+		}
+		return dir
+	}
+	rel, err := filepath.Rel(m.workspaceRoot, dir)
+	if err != nil {
+		return dir
+	}
+	if rel == "." {
+		return filepath.Base(m.workspaceRoot) + " (root)"
+	}
+	return filepath.ToSlash(rel)
+}
+
+func (m Model) renderRepoPicker() string {
+	width := max(24, min(100, m.width-10))
+	innerW := max(1, width-6)
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+
+	lines := make([]string, 0, len(m.repoPickerItems)+5)
+	lines = append(lines, dim.Render(ansi.Truncate(m.workspaceRoot, innerW, "…")), "")
+	for i, c := range m.repoPickerItems {
+		if c.Recent && (i == 0 || !m.repoPickerItems[i-1].Recent) {
+			if i > 0 {
+				lines = append(lines, "")
+			}
+			lines = append(lines, dim.Render("Recent"))
+		}
+		marker := "  "
+		style := lipgloss.NewStyle()
+		if i == m.repoPickerCursor {
+			marker = "> "
+			style = style.Foreground(lipgloss.Color("39")).Bold(true)
+		}
+		current := ""
+		if sameDir(c.Path, m.cwd) {
+			current = " (current)"
+		}
+		text := ansi.Truncate(marker+m.repoLabel(c.Path), max(1, innerW-ansi.StringWidth(current)), "…")
+		lines = append(lines, style.Render(text)+dim.Render(current))
+	}
+	if m.repoPickerErr != "" {
+		lines = append(lines, "", lipgloss.NewStyle().Foreground(lipgloss.Color("203")).Render(
+			ansi.Truncate("Error: "+m.repoPickerErr, innerW, ""),
+		))
+	}
+	lines = append(lines, "", dim.Render("j/k move | Enter review | ctrl+r/Esc close"))
+
+	title := lipgloss.NewStyle().
+		Width(max(1, width-2)).
+		Padding(0, 1).
+		Bold(true).
+		Foreground(lipgloss.Color("230")).
+		Background(lipgloss.Color("63")).
+		Render("Repositories")
+
+	bodyBlock := lipgloss.NewStyle().
+		Width(max(1, width-2)).
+		Padding(1, 2).
+		Render(strings.Join(lines, "\n"))
+
+	return lipgloss.NewStyle().
+		Width(width).
+		Border(diffview.Border(lipgloss.RoundedBorder())).
+		BorderForeground(lipgloss.Color("63")).
+		Render(title + "\n" + bodyBlock)
+}
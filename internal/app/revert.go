@@ -0,0 +1,177 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"diffman/internal/comments"
+	"diffman/internal/config"
+	"diffman/internal/diffview"
+	gitint "diffman/internal/git"
+)
+
+type revertResultMsg struct {
+	path    string
+	deleted bool
+	err     error
+}
+
+// handleRevertFile asks before reverting the file under the cursor, or
+// deleting it when it is untracked, since its changes cannot be recovered
+// afterwards.
+func (m Model) handleRevertFile(entries []fileTreeEntry) (tea.Model, tea.Cmd) {
+	if m.reviewMode == reviewModePR {
+		m.setAlert("Reverting edits the working tree and is unavailable in PR mode.")
+		return m, nil
+	}
+	entry := entries[m.fileCursor]
+	if entry.IsDir || entry.FileIndex < 0 || entry.FileIndex >= len(m.fileItems) {
+		m.setAlert("Select a file to revert.")
+		return m, nil
+	}
+	item := m.fileItems[entry.FileIndex]
+	m.revertConfirm = &item
+	return m, nil
+}
+
+func (m Model) handleRevertConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.Type == tea.KeyEsc, isRuneKey(msg, "n"), isRuneKey(msg, "N"):
+		m.revertConfirm = nil
+	case msg.Type == tea.KeyEnter, isRuneKey(msg, "y"), isRuneKey(msg, "Y"):
+		item := *m.revertConfirm
+		m.revertConfirm = nil
+		return m, m.revertFileCmd(item)
+	}
+	return m, nil
+}
+
+// revertFileCmd restores item, and the file it was renamed from, to HEAD,
+// or deletes it when it is untracked.
+func (m Model) revertFileCmd(item gitint.FileItem) tea.Cmd {
+	root := m.cwd
+	if isUntracked(item) {
+		return func() tea.Msg {
+			return revertResultMsg{path: item.Path, deleted: true, err: gitint.RemoveUntracked(context.Background(), root, item.Path)}
+		}
+	}
+	paths := []string{item.Path}
+	if item.Renamed() {
+		paths = append(paths, item.OrigPath)
+	}
+	return func() tea.Msg {
+		return revertResultMsg{path: item.Path, err: gitint.RestoreToHead(context.Background(), root, paths...)}
+	}
+}
+
+func isUntracked(item gitint.FileItem) bool {
+	return item.Status == "??"
+}
+
+// handleRevertResult archives the reverted or deleted file's comments to
+// the review history and removes them, since their lines left the diff with
+// the file, then reloads the file list.
+func (m *Model) handleRevertResult(msg revertResultMsg) tea.Cmd {
+	m.loadingFiles = true
+	reload := tea.Batch(m.loadFilesCmd(), m.loadHeadCmd())
+	verb, done, outcome := "revert", fmt.Sprintf("Reverted %s to HEAD", msg.path), "reverted"
+	if msg.deleted {
+		verb, done, outcome = "delete", fmt.Sprintf("Deleted %s", msg.path), "deleted"
+	}
+	if msg.err != nil {
+		m.setAlert(fmt.Sprintf("%s failed: %v", verb, msg.err))
+		return reload
+	}
+
+	var list []comments.Comment
+	for _, c := range m.sortedComments() {
+		if c.Path == msg.path {
+			list = append(list, c)
+		}
+	}
+	if len(list) == 0 {
+		m.setAlert(done + ".")
+		return reload
+	}
+	r, err := m.archiveReview(outcome, list)
+	if err != nil {
+		m.setAlert(fmt.Sprintf("%s; its comments are kept since archiving them failed: %v", done, err))
+		return reload
+	}
+	prev := make(map[string]comments.Comment, len(list))
+	for _, c := range list {
+		key := commentKey(c)
+		prev[key] = c
+		delete(m.comments, key)
+	}
+	if err := m.persistComments(); err != nil {
+		for key, c := range prev {
+			m.comments[key] = c
+		}
+		m.setAlert(fmt.Sprintf("%s; failed to remove its comments: %v", done, err))
+		return reload
+	}
+	for key := range prev {
+		delete(m.commentStale, key)
+		delete(m.commentsSelected, key)
+	}
+	m.diffDirty = true
+	m.refreshDiffContent()
+	m.setAlert(fmt.Sprintf("%s and archived its %d comment(s) as %s.", done, len(list), r.ID))
+	return tea.Batch(reload, m.hookCmd(hookPayload{Event: config.HookCommentDelete, Comments: list}))
+}
+
+func (m Model) renderRevertConfirmModal() string {
+	item := *m.revertConfirm
+	heading, action := "Revert File", "revert"
+	var lines []string
+	if isUntracked(item) {
+		heading, action = "Delete File", "delete"
+		lines = append(lines, fmt.Sprintf("Delete untracked %s?", item.Path), "It is not in git and cannot be recovered.")
+	} else {
+		lines = append(lines, fmt.Sprintf("Revert %s to HEAD?", item.Path))
+		if item.Renamed() {
+			lines = append(lines, fmt.Sprintf("%s is restored and the rename undone.", item.OrigPath))
+		}
+		lines = append(lines, "Its staged and unstaged changes are lost.")
+	}
+	n := 0
+	for _, c := range m.comments {
+		if c.Path == item.Path {
+			n++
+		}
+	}
+	if n > 0 {
+		lines = append(lines, fmt.Sprintf("Its %d comment(s) are archived (H) and removed.", n))
+	}
+	lines = append(lines, "", lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Render("Y/Enter "+action+" | N/Esc cancel"))
+	body := strings.Join(lines, "\n")
+
+	width := 54
+	if m.width > 0 && m.width-6 < width {
+		width = max(24, m.width-6)
+	}
+
+	title := lipgloss.NewStyle().
+		Width(max(1, width-2)).
+		Padding(0, 1).
+		Bold(true).
+		Foreground(lipgloss.Color("230")).
+		Background(lipgloss.Color("196")).
+		Render(heading)
+
+	bodyBlock := lipgloss.NewStyle().
+		Width(max(1, width-2)).
+		Padding(1, 2).
+		Render(body)
+
+	return lipgloss.NewStyle().
+		Width(width).
+		Border(diffview.Border(lipgloss.RoundedBorder())).
+		BorderForeground(lipgloss.Color("196")).
+		Render(title + "\n" + bodyBlock)
+}
@@ -0,0 +1,167 @@
+package app
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+
+	"diffman/internal/diffview"
+)
+
+// reviewedLine identifies one changed line by side, number, and text, so it
+// stays reviewed across reloads until its content changes.
+type reviewedLine struct {
+	side diffview.Side
+	line int
+	text uint64
+}
+
+func newReviewedLine(side diffview.Side, line int, text string) reviewedLine {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(text))
+	return reviewedLine{side: side, line: line, text: h.Sum64()}
+}
+
+// changedLines lists the added and removed lines a row shows; a changed row
+// counts as both, so totals match git's added plus deleted line counts.
+func changedLines(row diffview.DiffRow) []reviewedLine {
+	var out []reviewedLine
+	if (row.Kind == diffview.RowDelete || row.Kind == diffview.RowChange) && row.OldLine > 0 {
+		out = append(out, newReviewedLine(diffview.SideOld, row.OldLine, row.OldText))
+	}
+	if (row.Kind == diffview.RowAdd || row.Kind == diffview.RowChange) && row.NewLine > 0 {
+		out = append(out, newReviewedLine(diffview.SideNew, row.NewLine, row.NewText))
+	}
+	return out
+}
+
+// recordReviewLines remembers the changed lines of a freshly loaded diff,
+// which are what the file's reviewed percentage is measured against.
+func (m *Model) recordReviewLines(path string, rows []diffview.DiffRow) {
+	if m.reviewLines == nil {
+		m.reviewLines = make(map[string][]reviewedLine)
+	}
+	lines := make([]reviewedLine, 0, len(rows))
+	for _, row := range rows {
+		lines = append(lines, changedLines(row)...)
+	}
+	m.reviewLines[path] = lines
+}
+
+func (m *Model) markRowReviewed(idx int) {
+	if idx < 0 || idx >= len(m.diffRows) {
+		return
+	}
+	lines := changedLines(m.diffRows[idx])
+	if len(lines) == 0 {
+		return
+	}
+	path := m.selectedF
+	if m.reviewed == nil {
+		m.reviewed = make(map[string]map[reviewedLine]bool)
+	}
+	seen := m.reviewed[path]
+	if seen == nil {
+		seen = make(map[reviewedLine]bool)
+		m.reviewed[path] = seen
+	}
+	for _, l := range lines {
+		seen[l] = true
+	}
+}
+
+// markVisibleReviewed counts the cursor row and every row fully on screen as
+// reviewed while the diff pane has focus. Jumping past rows with G or n does
+// not mark them, since they were never shown.
+func (m *Model) markVisibleReviewed() {
+	if m.focus != focusDiff || m.loadingDiff || len(m.diffRows) == 0 || len(m.rowStarts) != len(m.diffRows) {
+		return
+	}
+	m.markRowReviewed(m.diffCursor)
+
+	height := min(m.oldView.Height, m.newView.Height)
+	if height <= 0 {
+		return
+	}
+	top := m.oldView.YOffset
+	bottom := top + height
+	first := sort.SearchInts(m.rowStarts, top)
+	for i := first; i < len(m.rowStarts) && m.rowStarts[i] < bottom; i++ {
+		end := m.rowStarts[i] + 1
+		if i < len(m.rowHeights) {
+			end = m.rowStarts[i] + max(1, m.rowHeights[i])
+		}
+		if end <= bottom {
+			m.markRowReviewed(i)
+		}
+	}
+}
+
+// fileReviewProgress reports how many of a file's changed lines have been
+// reviewed. ok is false until the file's diff has been opened.
+func (m Model) fileReviewProgress(path string) (done, total int, ok bool) {
+	lines, ok := m.reviewLines[path]
+	if !ok {
+		return 0, 0, false
+	}
+	seen := m.reviewed[path]
+	for _, l := range lines {
+		if seen[l] {
+			done++
+		}
+	}
+	return done, len(lines), true
+}
+
+// reviewProgress sums fileReviewProgress over the changed files and counts
+// the files not opened yet, whose size is unknown until they are.
+func (m Model) reviewProgress() (done, total, unopened int) {
+	for _, item := range m.fileItems {
+		d, t, ok := m.fileReviewProgress(item.Path)
+		if !ok {
+			unopened++
+			continue
+		}
+		done += d
+		total += t
+	}
+	return done, total, unopened
+}
+
+func reviewPercent(done, total int) int {
+	if total == 0 {
+		return 100
+	}
+	return done * 100 / total
+}
+
+// fileReviewMark is shown after a file in the tree once its diff was opened.
+func (m Model) fileReviewMark(path string) string {
+	done, total, ok := m.fileReviewProgress(path)
+	if !ok || total == 0 {
+		return ""
+	}
+	if done == total {
+		if diffview.PlainMode() {
+			return "[ok]"
+		}
+		return "✓"
+	}
+	return fmt.Sprintf("%d%%", reviewPercent(done, total))
+}
+
+// reviewStatusSegment is the overall coverage shown in the status bar.
+func (m Model) reviewStatusSegment() string {
+	if len(m.fileItems) == 0 {
+		return ""
+	}
+	done, total, unopened := m.reviewProgress()
+	segment := fmt.Sprintf("reviewed %d%%", reviewPercent(done, total))
+	if total == 0 && unopened > 0 {
+		segment = "reviewed 0%"
+	}
+	if unopened > 0 {
+		segment += fmt.Sprintf(", %d unopened", unopened)
+	}
+	return segment
+}
@@ -0,0 +1,625 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"diffman/internal/comments"
+	"diffman/internal/config"
+	"diffman/internal/diffview"
+	gitint "diffman/internal/git"
+)
+
+// JSON-RPC 2.0 error codes.
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcServerError    = -32000
+)
+
+// rpcMaxLine caps one request line; comment bodies are far smaller.
+const rpcMaxLine = 4 << 20
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string { return e.Message }
+
+func invalidParams(format string, args ...any) *rpcError {
+	return &rpcError{Code: rpcInvalidParams, Message: fmt.Sprintf(format, args...)}
+}
+
+// rpcComment is a comment as editor plugins see it, with its anchor key and staleness.
+type rpcComment struct {
+	ID            string    `json:"id"`
+	Key           string    `json:"key"`
+	Path          string    `json:"path"`
+	Side          string    `json:"side"`
+	Line          int       `json:"line"`
+	Body          string    `json:"body"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at,omitzero"`
+	HunkHeader    string    `json:"hunk_header,omitempty"`
+	ContextBefore []string  `json:"context_before,omitempty"`
+	ContextAfter  []string  `json:"context_after,omitempty"`
+	Stale         bool      `json:"stale"`
+	StaleReason   string    `json:"stale_reason,omitempty"`
+}
+
+type rpcFile struct {
+	Path     string `json:"path"`
+	Status   string `json:"status"`
+	OrigPath string `json:"orig_path,omitempty"`
+	Staged   bool   `json:"staged"`
+	Unstaged bool   `json:"unstaged"`
+}
+
+type rpcRow struct {
+	Kind    string `json:"kind"`
+	OldLine int    `json:"old_line,omitempty"`
+	NewLine int    `json:"new_line,omitempty"`
+	OldText string `json:"old_text,omitempty"`
+	NewText string `json:"new_text,omitempty"`
+	Hunk    int    `json:"hunk"`
+}
+
+// rpcAnchorParams names a comment anchor; Mode picks the diff it is checked against.
+type rpcAnchorParams struct {
+	// ID names a comment wherever it is anchored now; when set, the anchor
+	// fields are ignored.
+	ID   string `json:"id"`
+	Path string `json:"path"`
+	Side string `json:"side"`
+	Line int    `json:"line"`
+	Body string `json:"body"`
+	Mode string `json:"mode"`
+}
+
+// rpcServer answers requests against the same comment store, diffs, and
+// stale logic as the UI. Comments are re-read from disk for every request so
+// a running UI and an editor plugin can share the store.
+type rpcServer struct {
+	root  string
+	store comments.Store
+	// key encrypts the comment store and the review history; nil leaves
+	// them plain.
+	key          []byte
+	statusSvc    gitint.StatusService
+	diffSvc      gitint.DiffService
+	contextLines int
+	hooks        map[string]string
+	links        permalinkSettings
+	// redact leaves code context out of comments.export.
+	redact bool
+	// export shapes comments.export as export_group and export_context ask.
+	export comments.ExportOptions
+	// frame is export_title and export_footer.
+	frame exportFrame
+}
+
+// rpcMethods lists what "initialize" advertises.
+var rpcMethods = []string{
+	"initialize", "files.list", "diff.get",
+	"comments.list", "comments.save", "comments.delete", "comments.export",
+	"shutdown",
+}
+
+// ServeRPC runs a JSON-RPC 2.0 server over in/out for the repository
+// containing cwd: one request or response JSON object per line. It returns
+// when in is exhausted or a "shutdown" request arrives.
+func ServeRPC(ctx context.Context, cwd string, in io.Reader, out io.Writer) error {
+	s, err := newRPCServer(ctx, cwd)
+	if err != nil {
+		return err
+	}
+	return s.serve(ctx, in, out)
+}
+
+// newRPCServer opens the repository containing cwd with the user's config.
+func newRPCServer(ctx context.Context, cwd string) (*rpcServer, error) {
+	root, err := gitint.DiscoverRepoRoot(ctx, cwd)
+	if err != nil {
+		return nil, err
+	}
+	gitDir, err := gitint.DiscoverGitDir(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+	cfg, _, err := config.Load()
+	if err != nil {
+		cfg = config.Default()
+	}
+	key, err := commentsKey(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("comments key: %w", err)
+	}
+	return &rpcServer{
+		root:         root,
+		store:        comments.NewStore(gitDir).WithKey(key),
+		key:          key,
+		redact:       cfg.RedactExports,
+		export:       comments.ExportOptions{Group: cfg.ExportGroup, NoContext: !cfg.ExportContext},
+		frame:        exportFrame{title: cfg.ExportTitle, footer: cfg.ExportFooter},
+		statusSvc:    gitint.NewStatusService(cfg.Exclude...),
+		diffSvc:      gitint.NewDiffService(cfg.Exclude...),
+		contextLines: cfg.ContextLines,
+		hooks:        cfg.Hooks,
+		links:        permalinkSettings{enabled: cfg.Permalinks, templates: cfg.PermalinkTemplates, cwd: root},
+	}, nil
+}
+
+func (s *rpcServer) serve(ctx context.Context, in io.Reader, out io.Writer) error {
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+	write := func(resp rpcResponse) error {
+		return enc.Encode(resp)
+	}
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), rpcMaxLine)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var req rpcRequest
+		if err := json.Unmarshal([]byte(line), &req); err != nil {
+			if err := write(rpcResponse{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{Code: rpcParseError, Message: err.Error()}}); err != nil {
+				return err
+			}
+			continue
+		}
+		if req.JSONRPC != "2.0" || req.Method == "" {
+			if err := write(rpcResponse{JSONRPC: "2.0", ID: idOrNull(req.ID), Error: &rpcError{Code: rpcInvalidRequest, Message: "expected a JSON-RPC 2.0 request"}}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		result, rerr := s.dispatch(ctx, req)
+		if len(req.ID) > 0 {
+			resp := rpcResponse{JSONRPC: "2.0", ID: req.ID, Result: result, Error: rerr}
+			if rerr == nil && result == nil {
+				resp.Result = struct{}{}
+			}
+			if err := write(resp); err != nil {
+				return err
+			}
+		}
+		if req.Method == "shutdown" {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+func idOrNull(id json.RawMessage) json.RawMessage {
+	if len(id) == 0 {
+		return json.RawMessage("null")
+	}
+	return id
+}
+
+func (s *rpcServer) dispatch(ctx context.Context, req rpcRequest) (any, *rpcError) {
+	var (
+		result any
+		err    error
+	)
+	switch req.Method {
+	case "initialize":
+		result = map[string]any{"name": "diffman", "repo": s.root, "methods": rpcMethods}
+	case "shutdown":
+		return nil, nil
+	case "files.list":
+		result, err = s.filesList(ctx)
+	case "diff.get":
+		result, err = s.diffGet(ctx, req.Params)
+	case "comments.list":
+		result, err = s.commentsList(ctx, req.Params)
+	case "comments.save":
+		result, err = s.commentsSave(ctx, req.Params)
+	case "comments.delete":
+		result, err = s.commentsDelete(ctx, req.Params)
+	case "comments.export":
+		result, err = s.commentsExport(ctx, req.Params)
+	default:
+		return nil, &rpcError{Code: rpcMethodNotFound, Message: fmt.Sprintf("unknown method %q", req.Method)}
+	}
+	if err != nil {
+		var rerr *rpcError
+		if errors.As(err, &rerr) {
+			return nil, rerr
+		}
+		return nil, &rpcError{Code: rpcServerError, Message: err.Error()}
+	}
+	return result, nil
+}
+
+func decodeParams(raw json.RawMessage, v any) error {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return invalidParams("invalid params: %v", err)
+	}
+	return nil
+}
+
+func parseRPCMode(raw string) (gitint.DiffMode, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "", "all":
+		return gitint.DiffModeAll, nil
+	case "unstaged":
+		return gitint.DiffModeUnstaged, nil
+	case "staged":
+		return gitint.DiffModeStaged, nil
+	default:
+		return 0, invalidParams("unknown mode %q (want all, unstaged, or staged)", raw)
+	}
+}
+
+func parseRPCSide(raw string) (comments.Side, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "old":
+		return comments.SideOld, nil
+	case "new":
+		return comments.SideNew, nil
+	default:
+		return 0, invalidParams("side must be \"old\" or \"new\", got %q", raw)
+	}
+}
+
+func rpcRowKind(k diffview.RowKind) string {
+	switch k {
+	case diffview.RowDelete:
+		return "delete"
+	case diffview.RowAdd:
+		return "add"
+	case diffview.RowChange:
+		return "change"
+	case diffview.RowHunkHeader:
+		return "hunk_header"
+	case diffview.RowFileHeader:
+		return "file_header"
+	default:
+		return "context"
+	}
+}
+
+func (s *rpcServer) filesList(ctx context.Context) (any, error) {
+	items, err := s.statusSvc.ListChangedFiles(ctx, s.root)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]rpcFile, 0, len(items))
+	for _, it := range items {
+		out = append(out, rpcFile{Path: it.Path, Status: it.Status, OrigPath: it.OrigPath, Staged: it.HasStaged, Unstaged: it.HasUnstaged})
+	}
+	return out, nil
+}
+
+// loadComments reads the comment store for one request. A recovery from the
+// backup is not an error here; the restored comments are what is on disk now.
+func (s *rpcServer) loadComments() ([]comments.Comment, error) {
+	all, err := s.store.Load()
+	if err != nil && !comments.IsRecovered(err) {
+		return nil, err
+	}
+	return all, nil
+}
+
+func (s *rpcServer) loadRows(ctx context.Context, path string, mode gitint.DiffMode) ([]diffview.DiffRow, error) {
+	// Status tells whether path was renamed, which changes how it is diffed.
+	items, err := s.statusSvc.ListChangedFiles(ctx, s.root)
+	if err != nil {
+		return nil, err
+	}
+	rows, _, err := localDiffRows(ctx, s.diffSvc, s.root, path, renamedFrom(items)[path], mode)
+	return rows, err
+}
+
+func (s *rpcServer) diffGet(ctx context.Context, raw json.RawMessage) (any, error) {
+	var p struct {
+		Path string `json:"path"`
+		Mode string `json:"mode"`
+	}
+	if err := decodeParams(raw, &p); err != nil {
+		return nil, err
+	}
+	if p.Path == "" {
+		return nil, invalidParams("path is required")
+	}
+	mode, err := parseRPCMode(p.Mode)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.loadRows(ctx, p.Path, mode)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]rpcRow, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, rpcRow{Kind: rpcRowKind(r.Kind), OldLine: r.OldLine, NewLine: r.NewLine, OldText: r.OldText, NewText: r.NewText, Hunk: r.HunkID})
+	}
+	return map[string]any{"path": p.Path, "rows": out}, nil
+}
+
+// staleReasons runs the UI's anchor check over every stored comment.
+func (s *rpcServer) staleReasons(ctx context.Context, all []comments.Comment, mode gitint.DiffMode) (map[string]staleReason, error) {
+	items, err := s.statusSvc.ListChangedFiles(ctx, s.root)
+	if err != nil {
+		return nil, err
+	}
+	return buildCommentStaleReasons(ctx, s.root, s.diffSvc, items, all, mode)
+}
+
+func toRPCComment(c comments.Comment, reason staleReason) rpcComment {
+	return rpcComment{
+		ID:            c.ID,
+		Key:           commentKey(c),
+		Path:          c.Path,
+		Side:          c.Side.String(),
+		Line:          c.Line,
+		Body:          c.Body,
+		CreatedAt:     c.CreatedAt,
+		UpdatedAt:     c.UpdatedAt,
+		HunkHeader:    c.HunkHeader,
+		ContextBefore: c.ContextBefore,
+		ContextAfter:  c.ContextAfter,
+		Stale:         reason != staleReasonNone,
+		StaleReason:   reason.String(),
+	}
+}
+
+func (s *rpcServer) commentsList(ctx context.Context, raw json.RawMessage) (any, error) {
+	var p struct {
+		Path string `json:"path"`
+		Mode string `json:"mode"`
+	}
+	if err := decodeParams(raw, &p); err != nil {
+		return nil, err
+	}
+	mode, err := parseRPCMode(p.Mode)
+	if err != nil {
+		return nil, err
+	}
+	all, err := s.loadComments()
+	if err != nil {
+		return nil, err
+	}
+	reasons, err := s.staleReasons(ctx, all, mode)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]rpcComment, 0, len(all))
+	for _, c := range modelWithComments(all).sortedComments() {
+		if p.Path != "" && c.Path != p.Path {
+			continue
+		}
+		out = append(out, toRPCComment(c, reasons[commentKey(c)]))
+	}
+	return out, nil
+}
+
+func modelWithComments(all []comments.Comment) Model {
+	m := Model{comments: make(map[string]comments.Comment, len(all))}
+	for _, c := range all {
+		m.comments[commentKey(c)] = c
+	}
+	return m
+}
+
+// commentsSave creates or updates the comment at an anchor. Like the UI, the
+// line must be in the current diff, and its hunk header and context are captured.
+func (s *rpcServer) commentsSave(ctx context.Context, raw json.RawMessage) (any, error) {
+	var p rpcAnchorParams
+	if err := decodeParams(raw, &p); err != nil {
+		return nil, err
+	}
+	if p.ID != "" {
+		return s.commentsEditByID(ctx, p.ID, p.Body)
+	}
+	side, err := parseRPCSide(p.Side)
+	if err != nil {
+		return nil, err
+	}
+	body := strings.TrimSpace(p.Body)
+	if p.Path == "" || p.Line <= 0 || body == "" {
+		return nil, invalidParams("path, line, and a non-empty body are required")
+	}
+	mode, err := parseRPCMode(p.Mode)
+	if err != nil {
+		return nil, err
+	}
+	c, existed, err := s.saveComment(ctx, comments.Comment{Path: p.Path, Side: side, Line: p.Line, Body: body}, mode, false)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]any{"comment": toRPCComment(c, staleReasonNone)}
+	if err := s.runHook(ctx, hookPayload{Event: commentCreateOrEditEvent(existed), Comment: &c}); err != nil {
+		result["hook_error"] = err.Error()
+	}
+	return result, nil
+}
+
+// commentsEditByID replaces the body of the comment with id, leaving its
+// anchor and captured context as they are.
+func (s *rpcServer) commentsEditByID(ctx context.Context, id, body string) (any, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, invalidParams("a non-empty body is required")
+	}
+	all, err := s.loadComments()
+	if err != nil {
+		return nil, err
+	}
+	m := modelWithComments(all)
+	c, ok := m.commentByID(id)
+	if !ok {
+		return nil, invalidParams("no comment with id %s", id)
+	}
+	c.Body = body
+	c.UpdatedAt = time.Now()
+	m.comments[commentKey(c)] = c
+	if err := s.store.Save(m.sortedComments()); err != nil {
+		return nil, err
+	}
+
+	result := map[string]any{"comment": toRPCComment(c, staleReasonNone)}
+	if err := s.runHook(ctx, hookPayload{Event: config.HookCommentEdit, Comment: &c}); err != nil {
+		result["hook_error"] = err.Error()
+	}
+	return result, nil
+}
+
+// saveComment stores c after checking that its line is in the diff for mode,
+// capturing the hunk header and surrounding context the UI shows with it. An
+// existing comment on the same line is replaced, or with appendBody kept and
+// extended by c's body as a new paragraph. It reports whether one existed.
+func (s *rpcServer) saveComment(ctx context.Context, c comments.Comment, mode gitint.DiffMode, appendBody bool) (comments.Comment, bool, error) {
+	rows, err := s.loadRows(ctx, c.Path, mode)
+	if err != nil {
+		return comments.Comment{}, false, err
+	}
+
+	scratch := Model{diffRows: rows, contextLines: s.contextLines}
+	idx, ok := scratch.rowIndexForComment(c)
+	if !ok {
+		return comments.Comment{}, false, invalidParams("%s:%s:%d is not in the diff (mode %s)", c.Path, c.Side, c.Line, mode)
+	}
+
+	all, err := s.loadComments()
+	if err != nil {
+		return comments.Comment{}, false, err
+	}
+	m := modelWithComments(all)
+	key := commentKey(c)
+	existing, existed := m.comments[key]
+	now := time.Now()
+	c.ID = comments.NewID()
+	c.CreatedAt = now
+	if existed {
+		c.ID = existing.ID
+		c.CreatedAt = existing.CreatedAt
+		c.UpdatedAt = now
+		c.Pinned = existing.Pinned
+		c.Resolution, c.Reply = existing.Resolution, existing.Reply
+		if appendBody && strings.TrimSpace(existing.Body) != "" {
+			c.Body = existing.Body + "\n\n" + c.Body
+		}
+	}
+	c.HunkHeader = scratch.hunkHeaderForRow(idx, c.Path)
+	c.ContextBefore, c.ContextAfter = scratch.contextAround(commentAnchor{Path: c.Path, Side: c.Side, Line: c.Line, RowIdx: idx})
+	m.comments[key] = c
+	if err := s.store.Save(m.sortedComments()); err != nil {
+		return comments.Comment{}, false, err
+	}
+	return c, existed, nil
+}
+
+func (s *rpcServer) commentsDelete(ctx context.Context, raw json.RawMessage) (any, error) {
+	var p rpcAnchorParams
+	if err := decodeParams(raw, &p); err != nil {
+		return nil, err
+	}
+	all, err := s.loadComments()
+	if err != nil {
+		return nil, err
+	}
+	m := modelWithComments(all)
+	var key string
+	if p.ID != "" {
+		found, ok := m.commentByID(p.ID)
+		if !ok {
+			return map[string]any{"deleted": false}, nil
+		}
+		key = commentKey(found)
+	} else {
+		side, err := parseRPCSide(p.Side)
+		if err != nil {
+			return nil, err
+		}
+		key = comments.AnchorKey(p.Path, side, p.Line)
+	}
+	c, ok := m.comments[key]
+	if !ok {
+		return map[string]any{"deleted": false}, nil
+	}
+	delete(m.comments, key)
+	if err := s.store.Save(m.sortedComments()); err != nil {
+		return nil, err
+	}
+
+	result := map[string]any{"deleted": true}
+	if err := s.runHook(ctx, hookPayload{Event: config.HookCommentDelete, Comments: []comments.Comment{c}}); err != nil {
+		result["hook_error"] = err.Error()
+	}
+	return result, nil
+}
+
+// commentsExport renders the same text as the UI export, skipping stale comments.
+func (s *rpcServer) commentsExport(ctx context.Context, raw json.RawMessage) (any, error) {
+	var p struct {
+		Mode string `json:"mode"`
+	}
+	if err := decodeParams(raw, &p); err != nil {
+		return nil, err
+	}
+	mode, err := parseRPCMode(p.Mode)
+	if err != nil {
+		return nil, err
+	}
+	all, err := s.loadComments()
+	if err != nil {
+		return nil, err
+	}
+	reasons, err := s.staleReasons(ctx, all, mode)
+	if err != nil {
+		return nil, err
+	}
+	m := modelWithComments(all)
+	m.commentStale = staleMapFromReasons(reasons)
+	snapshot := comments.OrderForExport(m.exportableComments(), s.export.Group)
+	if s.redact {
+		snapshot = comments.Redact(snapshot)
+	}
+	opts := s.export
+	opts.Link = s.links.linker(ctx)
+	frame := s.frame.fill(ctx, s.root, "")
+	text := frame.withFooter(comments.ExportPlainWithOptions(snapshot, frame.title, opts))
+	return map[string]any{"text": text, "count": len(snapshot)}, nil
+}
+
+func (s *rpcServer) runHook(ctx context.Context, payload hookPayload) error {
+	command := strings.TrimSpace(s.hooks[payload.Event])
+	if command == "" {
+		return nil
+	}
+	payload.Repo = s.root
+	return runHook(ctx, s.root, command, payload)
+}
@@ -0,0 +1,178 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"diffman/internal/comments"
+	gitint "diffman/internal/git"
+)
+
+type stubStatusService struct {
+	items []gitint.FileItem
+	stats map[string]gitint.FileStat
+}
+
+func (s stubStatusService) ListChangedFiles(context.Context, string) ([]gitint.FileItem, error) {
+	return s.items, nil
+}
+
+func (s stubStatusService) DiffStats(context.Context, string, gitint.DiffMode, bool) (map[string]gitint.FileStat, error) {
+	return s.stats, nil
+}
+
+type stubDiffService struct {
+	diffs     map[string]string
+	untracked map[string]string
+}
+
+func (s stubDiffService) Diff(_ context.Context, _ string, path string, _ gitint.DiffMode) (string, error) {
+	return s.diffs[path], nil
+}
+
+func (s stubDiffService) DiffRename(_ context.Context, _ string, _ string, path string, _ gitint.DiffMode) (string, error) {
+	return s.diffs[path], nil
+}
+
+func (s stubDiffService) Untracked(_ context.Context, _ string, path string) (gitint.UntrackedFile, bool, error) {
+	content, ok := s.untracked[path]
+	if !ok {
+		return gitint.UntrackedFile{}, false, nil
+	}
+	return gitint.UntrackedFile{Path: path, Size: int64(len(content)), Content: []byte(content)}, true, nil
+}
+
+func newTestRPCServer(t *testing.T) *rpcServer {
+	t.Helper()
+	return &rpcServer{
+		root:         "/repo",
+		store:        comments.NewStore(t.TempDir()),
+		statusSvc:    stubStatusService{items: []gitint.FileItem{{Path: "a.go", Status: "M"}}},
+		diffSvc:      stubDiffService{diffs: map[string]string{"a.go": "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1,2 +1,3 @@\n one\n+two\n three\n"}},
+		contextLines: 1,
+	}
+}
+
+// rpcExchange sends one request per line and decodes one response per line.
+func rpcExchange(t *testing.T, s *rpcServer, requests ...string) []map[string]any {
+	t.Helper()
+	var out strings.Builder
+	if err := s.serve(context.Background(), strings.NewReader(strings.Join(requests, "\n")), &out); err != nil {
+		t.Fatalf("serve() error = %v", err)
+	}
+	var responses []map[string]any
+	scanner := bufio.NewScanner(strings.NewReader(out.String()))
+	for scanner.Scan() {
+		var resp map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &resp); err != nil {
+			t.Fatalf("response is not JSON: %v\n%s", err, scanner.Text())
+		}
+		responses = append(responses, resp)
+	}
+	return responses
+}
+
+func TestRPCSaveListDeleteComment(t *testing.T) {
+	s := newTestRPCServer(t)
+	responses := rpcExchange(t, s,
+		`{"jsonrpc":"2.0","id":1,"method":"comments.save","params":{"path":"a.go","side":"new","line":2,"body":"why two?"}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"comments.list","params":{"path":"a.go"}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"comments.export"}`,
+		`{"jsonrpc":"2.0","id":4,"method":"comments.delete","params":{"path":"a.go","side":"new","line":2}}`,
+		`{"jsonrpc":"2.0","method":"comments.list"}`,
+		`{"jsonrpc":"2.0","id":5,"method":"comments.list"}`,
+	)
+	if len(responses) != 5 {
+		t.Fatalf("expected 5 responses (notifications get none), got %d: %v", len(responses), responses)
+	}
+
+	saved := responses[0]["result"].(map[string]any)["comment"].(map[string]any)
+	if saved["key"] != "a.go:new:2" || saved["hunk_header"] != "@@ -1,2 +1,3 @@" {
+		t.Fatalf("unexpected saved comment: %v", saved)
+	}
+	if before := saved["context_before"].([]any); len(before) != 1 || before[0] != "one" {
+		t.Fatalf("expected captured context, got %v", saved["context_before"])
+	}
+
+	listed := responses[1]["result"].([]any)
+	if len(listed) != 1 || listed[0].(map[string]any)["stale"] != false {
+		t.Fatalf("expected one fresh comment, got %v", listed)
+	}
+
+	export := responses[2]["result"].(map[string]any)
+	if !strings.Contains(export["text"].(string), "a.go new:2: why two?") {
+		t.Fatalf("unexpected export: %v", export)
+	}
+
+	if responses[3]["result"].(map[string]any)["deleted"] != true {
+		t.Fatalf("expected delete to succeed: %v", responses[3])
+	}
+	if rest := responses[4]["result"].([]any); len(rest) != 0 {
+		t.Fatalf("expected no comments after delete, got %v", rest)
+	}
+}
+
+func TestRPCErrors(t *testing.T) {
+	s := newTestRPCServer(t)
+	responses := rpcExchange(t, s,
+		`not json`,
+		`{"jsonrpc":"2.0","id":1,"method":"nope"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"comments.save","params":{"path":"a.go","side":"new","line":9,"body":"x"}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"comments.save","params":{"path":"a.go","side":"left","line":2,"body":"x"}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"shutdown"}`,
+		`{"jsonrpc":"2.0","id":5,"method":"initialize"}`,
+	)
+	wantCodes := []float64{rpcParseError, rpcMethodNotFound, rpcInvalidParams, rpcInvalidParams}
+	if len(responses) != len(wantCodes)+1 {
+		t.Fatalf("expected shutdown to stop the server, got %d responses", len(responses))
+	}
+	for i, want := range wantCodes {
+		rerr, ok := responses[i]["error"].(map[string]any)
+		if !ok || rerr["code"] != want {
+			t.Fatalf("response %d: expected error code %v, got %v", i, want, responses[i])
+		}
+	}
+}
+
+func TestRPCEditsAndDeletesCommentByID(t *testing.T) {
+	s := newTestRPCServer(t)
+	responses := rpcExchange(t, s,
+		`{"jsonrpc":"2.0","id":1,"method":"comments.save","params":{"path":"a.go","side":"new","line":2,"body":"why two?"}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"comments.save","params":{"path":"a.go","side":"new","line":2,"body":"why two, not one?"}}`,
+	)
+	first := responses[0]["result"].(map[string]any)["comment"].(map[string]any)
+	second := responses[1]["result"].(map[string]any)["comment"].(map[string]any)
+	id, _ := first["id"].(string)
+	if id == "" || second["id"] != id {
+		t.Fatalf("expected the edit to keep the comment id, got %v then %v", first["id"], second["id"])
+	}
+
+	// Move the comment as a rename would; the id still finds it.
+	all, err := s.store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	all[0].Path = "b.go"
+	if err := s.store.Save(all); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	responses = rpcExchange(t, s,
+		`{"jsonrpc":"2.0","id":3,"method":"comments.save","params":{"id":"`+id+`","body":"renamed file, same question"}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"comments.delete","params":{"id":"`+id+`"}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"comments.delete","params":{"id":"`+id+`"}}`,
+	)
+	edited := responses[0]["result"].(map[string]any)["comment"].(map[string]any)
+	if edited["key"] != "b.go:new:2" || edited["body"] != "renamed file, same question" {
+		t.Fatalf("expected the moved comment edited by id, got %v", edited)
+	}
+	if responses[1]["result"].(map[string]any)["deleted"] != true {
+		t.Fatalf("expected delete by id to succeed: %v", responses[1])
+	}
+	if responses[2]["result"].(map[string]any)["deleted"] != false {
+		t.Fatalf("expected a second delete by id to find nothing: %v", responses[2])
+	}
+}
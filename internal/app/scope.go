@@ -0,0 +1,61 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	gitint "diffman/internal/git"
+)
+
+// ParseArgs sorts diffman's arguments into a file location to open and
+// paths to limit the review to. A single argument is a location, as in
+// "main.go:42", unless it names a directory, ends in "/" or "...", or has
+// glob characters; several arguments are all paths.
+func ParseArgs(args []string) (Location, []string, error) {
+	if len(args) == 1 && !isPathArg(args[0]) {
+		loc, err := ParseLocation(args[0])
+		return loc, nil, err
+	}
+	return Location{}, args, nil
+}
+
+func isPathArg(arg string) bool {
+	if strings.HasSuffix(arg, "...") || strings.HasSuffix(arg, "/") || strings.ContainsAny(arg, "*?[") {
+		return true
+	}
+	info, err := os.Stat(arg)
+	return err == nil && info.IsDir()
+}
+
+// reviewScope turns path arguments into a scope relative to the repository
+// at root. Paths are taken from the current directory or, failing that, from
+// the root; a trailing "/..." means the directory, as it does for go
+// packages, and "./..." the whole repository.
+func reviewScope(root string, paths []string) (gitint.Scope, error) {
+	scope := make(gitint.Scope, 0, len(paths))
+	for _, arg := range paths {
+		p := strings.TrimSuffix(strings.TrimSuffix(arg, "..."), "/")
+		if p == "" {
+			p = "."
+		}
+		if abs, err := filepath.Abs(p); err == nil && sameDir(abs, root) {
+			scope = append(scope, ".")
+			continue
+		}
+		rel, err := locationPath(root, p)
+		if err != nil {
+			return nil, err
+		}
+		scope = append(scope, rel)
+	}
+	return scope, nil
+}
+
+// scopeStatusSegment names the paths a scoped review is limited to.
+func (m Model) scopeStatusSegment() string {
+	if len(m.scope) == 0 {
+		return ""
+	}
+	return "paths: " + strings.Join(m.scope, " ")
+}
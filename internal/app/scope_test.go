@@ -0,0 +1,104 @@
+package app
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"diffman/internal/comments"
+	gitint "diffman/internal/git"
+)
+
+func TestParseArgsTellsPathsFromLocations(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if err := os.Mkdir("internal", 0o755); err != nil {
+		t.Fatalf("Mkdir() error = %v", err)
+	}
+
+	for _, tc := range []struct {
+		args  []string
+		open  Location
+		paths []string
+	}{
+		{args: []string{"main.go:42"}, open: Location{Path: "main.go", Line: 42}},
+		{args: []string{"internal"}, paths: []string{"internal"}},
+		{args: []string{"internal/..."}, paths: []string{"internal/..."}},
+		{args: []string{"*.go"}, paths: []string{"*.go"}},
+		{args: []string{"main.go", "cmd/"}, paths: []string{"main.go", "cmd/"}},
+	} {
+		open, paths, err := ParseArgs(tc.args)
+		if err != nil {
+			t.Fatalf("ParseArgs(%q) error = %v", tc.args, err)
+		}
+		if open != tc.open || strings.Join(paths, " ") != strings.Join(tc.paths, " ") {
+			t.Fatalf("ParseArgs(%q) = %+v, %q; want %+v, %q", tc.args, open, paths, tc.open, tc.paths)
+		}
+	}
+}
+
+func TestScopedReviewLeavesOtherCommentsAlone(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if os.Getenv("GIT_DIR") != "" {
+		t.Skip("GIT_DIR is set")
+	}
+	repo := t.TempDir()
+	gitCmd(t, repo, "init", "-q")
+	write := func(name, body string) {
+		t.Helper()
+		path := filepath.Join(repo, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("MkdirAll() error = %v", err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+	write("internal/app/a.go", "one\n")
+	write("main.go", "one\n")
+	gitCmd(t, repo, "add", ".")
+	gitCmd(t, repo, "commit", "-q", "-m", "init")
+	write("internal/app/a.go", "ONE\n")
+	write("main.go", "ONE\n")
+
+	root, err := gitint.DiscoverRepoRoot(t.Context(), repo)
+	if err != nil {
+		t.Fatalf("DiscoverRepoRoot() error = %v", err)
+	}
+	t.Chdir(filepath.Join(repo, "internal"))
+	scope, err := reviewScope(root, []string{"app/..."})
+	if err != nil || strings.Join(scope, " ") != "internal/app" {
+		t.Fatalf("reviewScope() = %q, %v; want internal/app relative to the root", scope, err)
+	}
+	if _, err := reviewScope(root, []string{"../../elsewhere"}); err == nil {
+		t.Fatalf("expected a path outside the repository to be rejected")
+	}
+	if all, _ := reviewScope(root, []string{"../..."}); strings.Join(all, " ") != "." {
+		t.Fatalf("expected ../... from a subdirectory to cover the repository, got %q", all)
+	}
+
+	items, err := gitint.NewScopedStatusService(scope).ListChangedFiles(t.Context(), root)
+	if err != nil || len(items) != 1 || items[0].Path != "internal/app/a.go" {
+		t.Fatalf("unexpected scoped status: %+v, %v", items, err)
+	}
+	inside := comments.Comment{Path: "internal/app/a.go", Side: comments.SideOld, Line: 9, Body: "gone"}
+	outside := comments.Comment{Path: "main.go", Side: comments.SideNew, Line: 1, Body: "elsewhere"}
+	m := Model{cwd: root, diffSvc: gitint.NewDiffService(), scope: scope}
+	msg := m.loadCommentStaleCmd(items, map[string]comments.Comment{
+		commentKey(inside):  inside,
+		commentKey(outside): outside,
+	}, gitint.DiffModeAll)().(commentStaleLoadedMsg)
+	if msg.err != nil {
+		t.Fatalf("stale detection error = %v", msg.err)
+	}
+	if !msg.stale[commentKey(inside)] || msg.stale[commentKey(outside)] {
+		t.Fatalf("expected only the comment inside the scope checked, got %v", msg.stale)
+	}
+	if got := m.scopeStatusSegment(); got != "paths: internal/app" {
+		t.Fatalf("unexpected status segment %q", got)
+	}
+}
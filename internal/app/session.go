@@ -0,0 +1,41 @@
+package app
+
+import (
+	"fmt"
+
+	"diffman/internal/session"
+)
+
+func (m *Model) applySessionState(state session.State) {
+	if state.FilePaneWidth >= filePaneWidthMin && state.FilePaneWidth <= filePaneWidthMax {
+		m.filePaneW = state.FilePaneWidth
+	}
+	if state.SplitPercent >= splitPercentMin && state.SplitPercent <= splitPercentMax {
+		m.splitPercent = state.SplitPercent
+	}
+	m.checklistDone = nil
+	for _, item := range state.Checklist {
+		if m.checklistDone == nil {
+			m.checklistDone = make(map[string]bool)
+		}
+		m.checklistDone[item] = true
+	}
+	m.sessionPosition = state.Position
+	m.lastExport = state.LastExport
+}
+
+func (m Model) sessionState() session.State {
+	return session.State{
+		FilePaneWidth: m.filePaneW,
+		SplitPercent:  m.splitPercent,
+		Checklist:     m.checkedItems(),
+		Position:      m.sessionPosition,
+		LastExport:    m.lastExport,
+	}
+}
+
+func (m *Model) saveSessionState() {
+	if err := m.sessionStore.Save(m.sessionState()); err != nil {
+		m.setAlert(fmt.Sprintf("failed to save session state: %v", err))
+	}
+}
@@ -0,0 +1,38 @@
+package app
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type shellExitMsg struct {
+	err error
+}
+
+// execShellCmd suspends the UI for an interactive $SHELL in the repository
+// root, with ROOT and FILE set as for leader commands. The shell's exit
+// status is not an error; only a shell that cannot start is reported.
+func (m Model) execShellCmd() tea.Cmd {
+	sh := strings.TrimSpace(os.Getenv("SHELL"))
+	if sh == "" {
+		sh = "/bin/sh"
+	}
+	cmd := exec.Command(sh)
+	cmd.Dir = m.cwd
+	cmd.Env = append(os.Environ(),
+		"ROOT="+m.cwd,
+		"FILE="+m.selectedF,
+		"DIFFMAN_SHELL=1",
+	)
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			err = nil
+		}
+		return shellExitMsg{err: err}
+	})
+}
@@ -0,0 +1,214 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"diffman/internal/diffview"
+	gitint "diffman/internal/git"
+	"diffman/internal/snapshot"
+)
+
+type snapshotSavedMsg struct {
+	key  string
+	snap snapshot.Snapshot
+	err  error
+}
+
+// snapshotKey names the review target a snapshot belongs to: the working
+// tree, or the PR under review.
+func (m Model) snapshotKey() string {
+	if m.reviewMode == reviewModePR && m.prCtx != nil {
+		return fmt.Sprintf("pr:%s/%s#%d", m.prCtx.Owner, m.prCtx.Repo, m.prCtx.Number)
+	}
+	return "local"
+}
+
+// diffRowsLoader fetches unfiltered diff rows for a path in the current review
+// target, reusing cached PR diffs.
+func (m Model) diffRowsLoader() func(path string) ([]diffview.DiffRow, error) {
+	if m.reviewMode == reviewModePR && m.prCtx != nil {
+		pr := *m.prCtx
+		service := m.prSvc
+		cache := make(map[string]prDiffCacheEntry, len(m.prDiffs))
+		for k, v := range m.prDiffs {
+			cache[k] = v
+		}
+		return func(path string) ([]diffview.DiffRow, error) {
+			if cached, ok := cache[path]; ok {
+				return cached.rows, nil
+			}
+			d, err := service.Diff(context.Background(), pr, path)
+			if err != nil || strings.TrimSpace(d) == "" {
+				return nil, err
+			}
+			return diffview.ParseUnifiedDiff([]byte(d))
+		}
+	}
+
+	cwd := m.cwd
+	service := m.diffSvc
+	mode := m.diffMode
+	origPaths := renamedFrom(m.staleCheckItems())
+	return func(path string) ([]diffview.DiffRow, error) {
+		rows, _, err := localDiffRows(context.Background(), service, cwd, path, origPaths[path], mode)
+		return rows, err
+	}
+}
+
+// handleTakeSnapshot records every listed file's hunks as reviewed. Files
+// hidden by delta mode are unchanged since the last snapshot, so that one is
+// carried forward underneath.
+func (m Model) handleTakeSnapshot() (tea.Model, tea.Cmd) {
+	if len(m.fileItems) == 0 {
+		m.setAlert("No changed files to snapshot.")
+		return m, nil
+	}
+	key := m.snapshotKey()
+	store := m.snapshotStore
+	items := append([]gitint.FileItem(nil), m.fileItems...)
+	load := m.diffRowsLoader()
+	return m, func() tea.Msg {
+		prev, err := store.Load(key)
+		if err != nil {
+			return snapshotSavedMsg{err: err}
+		}
+		snap := snapshot.Snapshot{TakenAt: time.Now(), Files: make(map[string]snapshot.File)}
+		if prev != nil {
+			for path, f := range prev.Files {
+				snap.Files[path] = f
+			}
+		}
+		for _, item := range items {
+			rows, err := load(item.Path)
+			if err != nil {
+				return snapshotSavedMsg{err: fmt.Errorf("%s: %w", item.Path, err)}
+			}
+			snap.Files[item.Path] = snapshot.FileFor(rows)
+		}
+		if err := store.Save(key, snap); err != nil {
+			return snapshotSavedMsg{err: err}
+		}
+		return snapshotSavedMsg{key: key, snap: snap}
+	}
+}
+
+func (m Model) handleSnapshotSaved(msg snapshotSavedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.setAlert(fmt.Sprintf("failed to save review snapshot: %v", msg.err))
+		return m, nil
+	}
+	snap := msg.snap
+	m.reviewSnapshot = &snap
+	m.reviewSnapshotKey = msg.key
+	m.setAlert(fmt.Sprintf("Saved review snapshot of %d file(s). R shows only what changes after this.", len(m.fileItems)))
+	if m.deltaMode {
+		m.loadingFiles = true
+		return m, m.loadFilesCmd()
+	}
+	return m, nil
+}
+
+// handleToggleDelta switches re-review mode, which hides files and hunks
+// the saved snapshot already covers.
+func (m Model) handleToggleDelta() (tea.Model, tea.Cmd) {
+	if m.deltaMode {
+		m.deltaMode = false
+		m.setAlert("Showing the full diff.")
+		m.loadingFiles = true
+		return m, m.loadFilesCmd()
+	}
+	key := m.snapshotKey()
+	snap, err := m.snapshotStore.Load(key)
+	if err != nil {
+		m.setAlert(fmt.Sprintf("failed to load review snapshot: %v", err))
+		return m, nil
+	}
+	if snap == nil {
+		m.setAlert("No review snapshot yet; press S after a review pass.")
+		return m, nil
+	}
+	m.reviewSnapshot = snap
+	m.reviewSnapshotKey = key
+	m.deltaMode = true
+	m.setAlert(fmt.Sprintf("Showing changes since the review snapshot of %s.", snap.TakenAt.Format("Jan 2 15:04")))
+	m.loadingFiles = true
+	return m, m.loadFilesCmd()
+}
+
+// deltaSnapshot is the snapshot to filter against, or nil outside delta mode.
+func (m Model) deltaSnapshot() *snapshot.Snapshot {
+	if !m.deltaMode || m.reviewSnapshot == nil || m.reviewSnapshotKey != m.snapshotKey() {
+		return nil
+	}
+	return m.reviewSnapshot
+}
+
+// deltaFilterCmd wraps a file listing so that, in delta mode, files whose diff
+// the snapshot fully covers are dropped. Files whose diff fails to load stay.
+func (m Model) deltaFilterCmd(list func() filesLoadedMsg) tea.Cmd {
+	snap := m.deltaSnapshot()
+	if snap == nil {
+		return func() tea.Msg { return list() }
+	}
+	load := m.diffRowsLoader()
+	return func() tea.Msg {
+		msg := list()
+		if msg.err != nil {
+			return msg
+		}
+		msg.all = msg.items
+		kept := make([]gitint.FileItem, 0, len(msg.items))
+		for _, item := range msg.items {
+			rows, err := load(item.Path)
+			if err == nil && snap.Reviewed(item.Path, rows) {
+				msg.deltaHidden++
+				continue
+			}
+			kept = append(kept, item)
+		}
+		msg.items = kept
+		return msg
+	}
+}
+
+// staleCheckItems is the full file list, including files delta mode hides,
+// so their comments are not reported as stale.
+func (m Model) staleCheckItems() []gitint.FileItem {
+	if m.deltaAllItems != nil {
+		return m.deltaAllItems
+	}
+	return m.fileItems
+}
+
+// filterDeltaRows hides the hunks of a loaded diff that the snapshot covers.
+func (m *Model) filterDeltaRows(path string, rows []diffview.DiffRow) []diffview.DiffRow {
+	m.deltaHunksHidden = 0
+	snap := m.deltaSnapshot()
+	if snap == nil {
+		return rows
+	}
+	rows, m.deltaHunksHidden = snap.FilterRows(path, rows)
+	return rows
+}
+
+func (m Model) deltaStatusSegment() string {
+	if m.deltaSnapshot() == nil {
+		return ""
+	}
+	seg := "Δ since " + m.reviewSnapshot.TakenAt.Format("Jan 2 15:04")
+	if diffview.PlainMode() {
+		seg = "delta since " + m.reviewSnapshot.TakenAt.Format("Jan 2 15:04")
+	}
+	if m.deltaHidden > 0 {
+		seg += fmt.Sprintf(", %d file(s) hidden", m.deltaHidden)
+	}
+	if m.deltaHunksHidden > 0 {
+		seg += fmt.Sprintf(", %d hunk(s) hidden", m.deltaHunksHidden)
+	}
+	return seg
+}
@@ -0,0 +1,111 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"diffman/internal/diffview"
+	gitint "diffman/internal/git"
+)
+
+type sparseAddResultMsg struct {
+	path   string
+	target string
+	err    error
+}
+
+// missingOutsideSparse reports whether path is a file the sparse checkout
+// leaves out and that is absent from the working tree, so only its index
+// and HEAD versions can be diffed.
+func (m Model) missingOutsideSparse(path string) bool {
+	for _, item := range m.fileItems {
+		if item.Path != path {
+			continue
+		}
+		if !item.OutsideSparse {
+			return false
+		}
+		_, err := os.Lstat(filepath.Join(m.cwd, path))
+		return errors.Is(err, os.ErrNotExist)
+	}
+	return false
+}
+
+// sparseNoticeRows stands in for the empty diff of a file outside the
+// sparse checkout.
+func sparseNoticeRows(path string) []diffview.DiffRow {
+	return diffview.NoticeRows(path, "Outside the sparse checkout and unchanged in the index; Enter in the file list checks it out")
+}
+
+func (m Model) handleSparseConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	item := *m.sparseConfirm
+	switch {
+	case msg.Type == tea.KeyEsc, isRuneKey(msg, "n"), isRuneKey(msg, "N"):
+		m.sparseConfirm = nil
+		m.focus = focusDiff
+		return m, nil
+	case msg.Type == tea.KeyEnter, isRuneKey(msg, "y"), isRuneKey(msg, "Y"):
+		m.sparseConfirm = nil
+		root := m.cwd
+		return m, func() tea.Msg {
+			ctx := context.Background()
+			sparse, _, err := gitint.ReadSparse(ctx, root)
+			if err != nil {
+				return sparseAddResultMsg{path: item.Path, err: err}
+			}
+			target := sparse.MaterializeTarget(item.Path)
+			return sparseAddResultMsg{path: item.Path, target: target, err: gitint.SparseCheckoutAdd(ctx, root, target)}
+		}
+	}
+	return m, nil
+}
+
+func (m *Model) handleSparseAddResult(msg sparseAddResultMsg) tea.Cmd {
+	if msg.err != nil {
+		m.setAlert(fmt.Sprintf("sparse-checkout add failed: %v", msg.err))
+		return nil
+	}
+	m.setAlert(fmt.Sprintf("Added %s to the sparse checkout.", msg.target))
+	m.loadingFiles = true
+	return tea.Batch(m.loadFilesCmd(), m.loadHeadCmd())
+}
+
+func (m Model) renderSparseConfirmModal() string {
+	body := strings.Join([]string{
+		fmt.Sprintf("%s is outside the sparse checkout.", m.sparseConfirm.Path),
+		"Check it out with git sparse-checkout add?",
+		"",
+		lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Render("Y/Enter check out | N/Esc view the index diff"),
+	}, "\n")
+
+	width := 54
+	if m.width > 0 && m.width-6 < width {
+		width = max(24, m.width-6)
+	}
+
+	title := lipgloss.NewStyle().
+		Width(max(1, width-2)).
+		Padding(0, 1).
+		Bold(true).
+		Foreground(lipgloss.Color("230")).
+		Background(lipgloss.Color("63")).
+		Render("Sparse Checkout")
+
+	bodyBlock := lipgloss.NewStyle().
+		Width(max(1, width-2)).
+		Padding(1, 2).
+		Render(body)
+
+	return lipgloss.NewStyle().
+		Width(width).
+		Border(diffview.Border(lipgloss.RoundedBorder())).
+		BorderForeground(lipgloss.Color("63")).
+		Render(title + "\n" + bodyBlock)
+}
@@ -0,0 +1,43 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"diffman/internal/diffview"
+)
+
+// renderSpellingLine lists the words in the comment dock that look
+// misspelled, with a suggestion when the bundled list has one. It is empty
+// when spellcheck is off or nothing is flagged.
+func (m Model) renderSpellingLine(width int) string {
+	if m.spell == nil {
+		return ""
+	}
+	typos := m.spell.Misspelled(m.commentInputModel.Value())
+	if len(typos) == 0 {
+		return ""
+	}
+	wordStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("203")).Underline(true)
+	if diffview.PlainMode() {
+		wordStyle = lipgloss.NewStyle()
+	}
+	seen := make(map[string]bool, len(typos))
+	parts := make([]string, 0, len(typos))
+	for _, typo := range typos {
+		if seen[typo.Text] {
+			continue
+		}
+		seen[typo.Text] = true
+		part := wordStyle.Render(typo.Text)
+		if typo.Suggestion != "" {
+			part += fmt.Sprintf(" -> %s", typo.Suggestion)
+		}
+		parts = append(parts, part)
+	}
+	label := lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Render("Spelling: ")
+	return ansi.Truncate(label+strings.Join(parts, ", "), width, "…")
+}
@@ -0,0 +1,54 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	gitint "diffman/internal/git"
+)
+
+type addToIndexResultMsg struct {
+	path       string
+	intentOnly bool
+	err        error
+}
+
+// handleAddToIndex adds the untracked file under the cursor to the index,
+// or with intentOnly only marks it as intended (git add -N), so it shows in
+// staged or unstaged diffs rather than only in the all mode.
+func (m Model) handleAddToIndex(entries []fileTreeEntry, intentOnly bool) (tea.Model, tea.Cmd) {
+	if m.reviewMode == reviewModePR {
+		m.setAlert("Adding files edits the index and is unavailable in PR mode.")
+		return m, nil
+	}
+	entry := entries[m.fileCursor]
+	if entry.IsDir || entry.FileIndex < 0 || entry.FileIndex >= len(m.fileItems) {
+		m.setAlert("Select an untracked file to add.")
+		return m, nil
+	}
+	item := m.fileItems[entry.FileIndex]
+	if !isUntracked(item) {
+		m.setAlert(fmt.Sprintf("%s is already tracked.", item.Path))
+		return m, nil
+	}
+	root := m.cwd
+	return m, func() tea.Msg {
+		err := gitint.AddToIndex(context.Background(), root, item.Path, intentOnly)
+		return addToIndexResultMsg{path: item.Path, intentOnly: intentOnly, err: err}
+	}
+}
+
+func (m *Model) handleAddToIndexResult(msg addToIndexResultMsg) tea.Cmd {
+	switch {
+	case msg.err != nil:
+		m.setAlert(fmt.Sprintf("git add failed: %v", msg.err))
+	case msg.intentOnly:
+		m.setAlert(fmt.Sprintf("Marked %s as intent-to-add; it shows in unstaged diffs.", msg.path))
+	default:
+		m.setAlert(fmt.Sprintf("Added %s to the index; it shows in staged diffs.", msg.path))
+	}
+	m.loadingFiles = true
+	return tea.Batch(m.loadFilesCmd(), m.loadHeadCmd())
+}
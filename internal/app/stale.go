@@ -0,0 +1,140 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"diffman/internal/comments"
+	"diffman/internal/diffview"
+)
+
+// staleReason says why a comment no longer anchors to the current diff.
+type staleReason int
+
+const (
+	staleReasonNone staleReason = iota
+	// staleReasonFileUnchanged: the file dropped out of the changed-file list or its diff is empty.
+	staleReasonFileUnchanged
+	// staleReasonLineGone: the file still has a diff but the commented line is not in it.
+	staleReasonLineGone
+	// staleReasonDiffFailed: the diff could not be loaded, so the anchor was not checked.
+	staleReasonDiffFailed
+)
+
+func (r staleReason) String() string {
+	switch r {
+	case staleReasonFileUnchanged:
+		return "file no longer changed"
+	case staleReasonLineGone:
+		return "line gone from diff"
+	case staleReasonDiffFailed:
+		return "diff failed to load"
+	default:
+		return ""
+	}
+}
+
+func staleMapFromReasons(reasons map[string]staleReason) map[string]bool {
+	out := make(map[string]bool, len(reasons))
+	for k, r := range reasons {
+		out[k] = r != staleReasonNone
+	}
+	return out
+}
+
+func (m Model) commentStaleReason(c comments.Comment) staleReason {
+	if !m.isCommentStale(c) {
+		return staleReasonNone
+	}
+	if r, ok := m.commentStaleReasons[commentKey(c)]; ok && r != staleReasonNone {
+		return r
+	}
+	return staleReasonFileUnchanged
+}
+
+// openStalePopup shows the stored context of a stale comment so it can be deleted, edited, or kept.
+func (m *Model) openStalePopup(c comments.Comment) {
+	m.stalePopupKey = commentKey(c)
+}
+
+func (m Model) handleStalePopup(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	c, ok := m.comments[m.stalePopupKey]
+	if !ok {
+		m.stalePopupKey = ""
+		return m, nil
+	}
+	switch {
+	case msg.Type == tea.KeyEsc, msg.Type == tea.KeyEnter, isRuneKey(msg, "q"):
+		m.stalePopupKey = ""
+	case isRuneKey(msg, "d"):
+		m.stalePopupKey = ""
+		cmd := m.deleteCommentByKey(commentKey(c))
+		next := m.commentsViewRows()
+		m.clampCommentsCursor(next)
+		m.ensureCommentsCursorVisible(next)
+		return m, cmd
+	case isRuneKey(msg, "e"):
+		m.stalePopupKey = ""
+		return m, m.startCommentEditByComment(c)
+	}
+	return m, nil
+}
+
+func (m Model) renderStalePopup() string {
+	c := m.comments[m.stalePopupKey]
+	width := min(max(24, m.width-10), 90)
+	innerW := max(1, width-6)
+	truncate := func(line string) string {
+		return ansi.Truncate(line, innerW, "…")
+	}
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+
+	lines := []string{
+		truncate(fmt.Sprintf("%s:%s:%d", c.Path, c.Side, c.Line)),
+		truncate(fmt.Sprintf("Reason: %s", m.commentStaleReason(c))),
+		"",
+	}
+	if c.HunkHeader == "" && len(c.ContextBefore) == 0 && len(c.ContextAfter) == 0 {
+		lines = append(lines, dim.Render("No context was captured for this comment."))
+	} else {
+		if c.HunkHeader != "" {
+			lines = append(lines, dim.Render(truncate(c.HunkHeader)))
+		}
+		for _, line := range c.ContextBefore {
+			lines = append(lines, truncate("  "+line))
+		}
+		for i, line := range c.ContextAfter {
+			prefix := "  "
+			if i == 0 {
+				prefix = "> "
+			}
+			lines = append(lines, truncate(prefix+line))
+		}
+	}
+	lines = append(lines, "", "Comment:")
+	lines = append(lines, lipgloss.NewStyle().Width(innerW).Render(renderCommentBody(c.Body)))
+	lines = append(lines, "", dim.Render("d delete | e edit | Esc/Enter keep"))
+
+	title := lipgloss.NewStyle().
+		Width(max(1, width-2)).
+		Padding(0, 1).
+		Bold(true).
+		Foreground(lipgloss.Color("230")).
+		Background(lipgloss.Color("214")).
+		Render("Stale Comment")
+
+	bodyBlock := lipgloss.NewStyle().
+		Width(max(1, width-2)).
+		Padding(1, 2).
+		Render(strings.Join(lines, "\n"))
+
+	return lipgloss.NewStyle().
+		Width(width).
+		Border(diffview.Border(lipgloss.RoundedBorder())).
+		BorderForeground(lipgloss.Color("214")).
+		Render(title + "\n" + bodyBlock)
+}
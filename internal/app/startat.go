@@ -0,0 +1,96 @@
+package app
+
+import (
+	"fmt"
+
+	"diffman/internal/comments"
+	"diffman/internal/session"
+)
+
+// applyStartAt opens the file the start_at setting asks for once the first
+// file list is in. A location given on the command line wins. "stale" has
+// to wait for the stale check and is finished by openFirstStaleComment.
+func (m *Model) applyStartAt() {
+	start := m.startAt
+	if start == "stale" {
+		return
+	}
+	m.startAt = ""
+	if m.pendingLocation != nil {
+		return
+	}
+	switch start {
+	case "comment":
+		for _, c := range m.sortedComments() {
+			if indexOfFilePath(m.fileItems, c.Path) < 0 {
+				continue
+			}
+			m.selectedF = c.Path
+			m.focus = focusDiff
+			m.pendingCommentJump = &commentAnchor{Path: c.Path, Side: c.Side, Line: c.Line}
+			return
+		}
+		m.setAlert("No comments on changed files; starting at the first file.")
+	case "resume":
+		pos := m.sessionPosition
+		if pos == nil || m.reviewMode != reviewModeLocal {
+			return
+		}
+		if pos.Side == comments.SideOld.String() {
+			m.selectedF = pos.Path
+			m.focus = focusDiff
+			m.pendingCommentJump = &commentAnchor{Path: pos.Path, Side: comments.SideOld, Line: pos.Line}
+			if indexOfFilePath(m.fileItems, pos.Path) < 0 {
+				m.setAlert(fmt.Sprintf("%s has no changes to review.", pos.Path))
+				m.focus = focusFiles
+			}
+			return
+		}
+		m.openLocation(Location{Path: pos.Path, Line: pos.Line})
+	}
+}
+
+// openFirstStaleComment finishes start_at "stale" once the stale check is
+// done, opening the comments view on the first stale comment.
+func (m *Model) openFirstStaleComment() {
+	if m.startAt != "stale" {
+		return
+	}
+	m.startAt = ""
+	rows := m.commentsViewRows()
+	for i, row := range rows {
+		if row.Header || !m.isCommentStale(row.Comment) {
+			continue
+		}
+		if m.focus != focusComments {
+			m.commentsReturn = m.focus
+			m.focus = focusComments
+		}
+		m.commentsCursor = i
+		m.ensureCommentsCursorVisible(rows)
+		return
+	}
+	m.setAlert("No stale comments.")
+}
+
+// cursorPosition is the diff line under the cursor, for resuming there.
+func (m Model) cursorPosition() *session.Position {
+	if m.reviewMode != reviewModeLocal || m.diffCursor < 0 || m.diffCursor >= len(m.diffRows) {
+		return m.sessionPosition
+	}
+	row := m.diffRows[m.diffCursor]
+	switch {
+	case row.NewLine > 0:
+		return &session.Position{Path: row.Path, Side: comments.SideNew.String(), Line: row.NewLine}
+	case row.OldLine > 0:
+		return &session.Position{Path: row.Path, Side: comments.SideOld.String(), Line: row.OldLine}
+	}
+	return m.sessionPosition
+}
+
+// SaveSessionPosition remembers the diff line under the cursor so start_at
+// "resume" can open there next time. It is called once the program exits.
+func (m Model) SaveSessionPosition() error {
+	m.sessionPosition = m.cursorPosition()
+	return m.sessionStore.Save(m.sessionState())
+}
@@ -0,0 +1,241 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	gitint "diffman/internal/git"
+	"diffman/internal/recent"
+)
+
+// NotRepoError is returned by NewModelWithOptions when a local review starts
+// outside any git repository.
+type NotRepoError struct {
+	Dir string
+	Err error
+}
+
+func (e *NotRepoError) Error() string {
+	return fmt.Sprintf("%s is not in a git repository: %v", e.Dir, e.Err)
+}
+
+func (e *NotRepoError) Unwrap() error {
+	return e.Err
+}
+
+// StartChoice is what the user picked on the start screen: a repository to
+// review, two paths to compare with git diff --no-index, or neither to quit.
+type StartChoice struct {
+	Repo    string
+	OldPath string
+	NewPath string
+}
+
+type startAction int
+
+const (
+	startRecent startAction = iota
+	startOpenDir
+	startNoIndex
+	startQuit
+)
+
+type startItem struct {
+	action startAction
+	path   string
+}
+
+// StartScreen is shown instead of the review when diffman starts outside a
+// git repository.
+type StartScreen struct {
+	keys   KeyMap
+	dir    string
+	items  []startItem
+	cursor int
+	// prompts holds the questions still to ask for the selected action and
+	// answers the answers given so far.
+	prompts []string
+	answers []string
+	input   textinput.Model
+	action  startAction
+	err     string
+	choice  StartChoice
+}
+
+// NewStartScreen offers the recently opened repositories that still exist,
+// opening another directory, and comparing two paths.
+func NewStartScreen(dir string) StartScreen {
+	var recents []recent.Repo
+	if path, err := recent.DefaultPath(); err == nil {
+		recents, _ = recent.NewStore(path).Load()
+	}
+	var items []startItem
+	for _, r := range recents {
+		if info, err := os.Stat(r.Path); err == nil && info.IsDir() {
+			items = append(items, startItem{action: startRecent, path: r.Path})
+		}
+	}
+	items = append(items, startItem{action: startOpenDir}, startItem{action: startNoIndex}, startItem{action: startQuit})
+
+	input := textinput.New()
+	input.Prompt = ""
+	input.CharLimit = 4096
+	input.Cursor.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("51"))
+	input.PlaceholderStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+	return StartScreen{keys: defaultKeyMap(), dir: dir, items: items, input: input}
+}
+
+// Choice is what the user picked once the screen has quit.
+func (s StartScreen) Choice() StartChoice {
+	return s.choice
+}
+
+func (s StartScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (s StartScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if msg.Type == tea.KeyCtrlC {
+			return s, tea.Quit
+		}
+		if len(s.prompts) > 0 {
+			return s.updatePrompt(msg)
+		}
+		return s.updateList(msg)
+	}
+	return s, nil
+}
+
+func (s StartScreen) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.Type == tea.KeyEsc, isRuneKey(msg, "q"):
+		return s, tea.Quit
+	case key.Matches(msg, s.keys.Down):
+		if s.cursor < len(s.items)-1 {
+			s.cursor++
+		}
+	case key.Matches(msg, s.keys.Up):
+		if s.cursor > 0 {
+			s.cursor--
+		}
+	case key.Matches(msg, s.keys.Open):
+		s.err = ""
+		item := s.items[s.cursor]
+		s.action = item.action
+		switch item.action {
+		case startRecent:
+			return s.chooseRepo(item.path)
+		case startOpenDir:
+			return s.ask("Directory to review:")
+		case startNoIndex:
+			return s.ask("Old file or directory:", "New file or directory:")
+		case startQuit:
+			return s, tea.Quit
+		}
+	}
+	return s, nil
+}
+
+func (s StartScreen) ask(prompts ...string) (tea.Model, tea.Cmd) {
+	s.prompts = prompts
+	s.answers = nil
+	s.input.SetValue("")
+	s.input.Placeholder = s.dir
+	return s, s.input.Focus()
+}
+
+func (s StartScreen) updatePrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		s.prompts = nil
+		s.input.Blur()
+		return s, nil
+	case tea.KeyEnter:
+		answer := s.dir
+		if value := strings.TrimSpace(s.input.Value()); value != "" {
+			answer, _ = resolveExportPath(s.dir, value)
+		}
+		if _, err := os.Stat(answer); err != nil {
+			s.err = err.Error()
+			return s, nil
+		}
+		s.err = ""
+		s.answers = append(s.answers, answer)
+		if len(s.answers) < len(s.prompts) {
+			s.input.SetValue("")
+			return s, nil
+		}
+		s.prompts = nil
+		s.input.Blur()
+		if s.action == startNoIndex {
+			s.choice = StartChoice{OldPath: s.answers[0], NewPath: s.answers[1]}
+			return s, tea.Quit
+		}
+		return s.chooseRepo(s.answers[0])
+	}
+	var cmd tea.Cmd
+	s.input, cmd = s.input.Update(msg)
+	return s, cmd
+}
+
+// chooseRepo quits with dir as the repository to review when it is in one.
+func (s StartScreen) chooseRepo(dir string) (tea.Model, tea.Cmd) {
+	if _, err := gitint.DiscoverRepoRoot(context.Background(), dir); err != nil {
+		s.err = fmt.Sprintf("%s is not in a git repository.", dir)
+		return s, nil
+	}
+	s.choice = StartChoice{Repo: dir}
+	return s, tea.Quit
+}
+
+func (s StartScreen) View() string {
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+	lines := []string{
+		lipgloss.NewStyle().Bold(true).Render("diffman"),
+		"",
+		s.dir + " is not in a git repository.",
+		"",
+	}
+	if len(s.prompts) > 0 {
+		lines = append(lines, s.prompts[len(s.answers)], s.input.View(), "", dim.Render("enter confirm | esc back"))
+	} else {
+		for i, item := range s.items {
+			if item.action == startRecent && i == 0 {
+				lines = append(lines, dim.Render("Recent repositories"))
+			}
+			if item.action != startRecent && i > 0 && s.items[i-1].action == startRecent {
+				lines = append(lines, "")
+			}
+			label := item.path
+			switch item.action {
+			case startOpenDir:
+				label = "Open a directory..."
+			case startNoIndex:
+				label = "Compare two paths (git diff --no-index)..."
+			case startQuit:
+				label = "Quit"
+			}
+			marker := "  "
+			style := lipgloss.NewStyle()
+			if i == s.cursor {
+				marker = "> "
+				style = style.Foreground(lipgloss.Color("39")).Bold(true)
+			}
+			lines = append(lines, style.Render(marker+label))
+		}
+		lines = append(lines, "", dim.Render("j/k move | enter choose | q quit"))
+	}
+	if s.err != "" {
+		lines = append(lines, "", lipgloss.NewStyle().Foreground(lipgloss.Color("203")).Render(s.err))
+	}
+	return lipgloss.NewStyle().Padding(1, 2).Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,88 @@
+package app
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"diffman/internal/recent"
+)
+
+func typeInto(s StartScreen, text string) StartScreen {
+	next, _ := s.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text)})
+	next, _ = next.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	return next.(StartScreen)
+}
+
+func TestStartScreenOffersRecentReposAndOpensADirectory(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if os.Getenv("GIT_DIR") != "" {
+		t.Skip("GIT_DIR is set")
+	}
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	repo := t.TempDir()
+	gitCmd(t, repo, "init", "-q")
+	path, err := recent.DefaultPath()
+	if err != nil {
+		t.Fatalf("DefaultPath() error = %v", err)
+	}
+	if err := recent.NewStore(path).Touch(repo); err != nil {
+		t.Fatalf("Touch() error = %v", err)
+	}
+	outside := t.TempDir()
+
+	s := NewStartScreen(outside)
+	if view := s.View(); !strings.Contains(view, "is not in a git repository") || !strings.Contains(view, repo) {
+		t.Fatalf("expected the recent repository to be offered:\n%s", view)
+	}
+
+	// Enter on the recent repository picks it.
+	next, cmd := s.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if got := next.(StartScreen).Choice(); got.Repo != repo || cmd == nil {
+		t.Fatalf("expected %s to be chosen, got %+v", repo, got)
+	}
+
+	// Opening a directory outside any repository is refused.
+	next, _ = s.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	next, _ = next.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	s = typeInto(next.(StartScreen), outside)
+	if s.Choice().Repo != "" || !strings.Contains(s.View(), "is not in a git repository.") {
+		t.Fatalf("expected %s to be refused, got %+v", outside, s.Choice())
+	}
+	next, _ = s.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	s = typeInto(next.(StartScreen), repo)
+	if s.Choice().Repo != repo {
+		t.Fatalf("expected %s to be chosen, got %+v", repo, s.Choice())
+	}
+}
+
+func TestStartScreenAsksForTwoPathsToCompare(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	dir := t.TempDir()
+	for _, name := range []string{"old.txt", "new.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0o644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+
+	s := NewStartScreen(dir)
+	next, _ := s.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	next, _ = next.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	s = typeInto(next.(StartScreen), "missing.txt")
+	if !strings.Contains(s.View(), "Old file or directory:") {
+		t.Fatalf("expected a missing path to be asked for again:\n%s", s.View())
+	}
+	next, _ = s.Update(tea.KeyMsg{Type: tea.KeyCtrlU})
+	s = typeInto(next.(StartScreen), "old.txt")
+	s = typeInto(s, "new.txt")
+	want := StartChoice{OldPath: filepath.Join(dir, "old.txt"), NewPath: filepath.Join(dir, "new.txt")}
+	if s.Choice() != want {
+		t.Fatalf("Choice() = %+v, want %+v", s.Choice(), want)
+	}
+}
@@ -0,0 +1,217 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"diffman/internal/diffview"
+)
+
+// reviewIdleLimit caps how much of a pause between key presses counts as
+// review time, so a session left open over lunch does not inflate it.
+const reviewIdleLimit = 2 * time.Minute
+
+// trackReviewTime charges the time since the previous key press to the file
+// that was selected during it.
+func (m *Model) trackReviewTime(prevFile string, now time.Time) {
+	if !m.reviewTimerAt.IsZero() {
+		gap := now.Sub(m.reviewTimerAt)
+		if gap > reviewIdleLimit {
+			gap = reviewIdleLimit
+		}
+		if gap > 0 {
+			m.reviewTotal += gap
+			if prevFile != "" {
+				if m.reviewTimes == nil {
+					m.reviewTimes = make(map[string]time.Duration)
+				}
+				m.reviewTimes[prevFile] += gap
+			}
+		}
+	}
+	m.reviewTimerAt = now
+}
+
+// fileReviewStats is one file's line in the statistics view.
+type fileReviewStats struct {
+	Path     string
+	Time     time.Duration
+	Comments int
+	// Reviewed is the reviewed percentage, or -1 when the file was not opened.
+	Reviewed int
+}
+
+// reviewStats lists the changed files, and any other file with comments or
+// time spent on it, ordered by time spent.
+func (m Model) reviewStats() []fileReviewStats {
+	byPath := make(map[string]*fileReviewStats)
+	get := func(path string) *fileReviewStats {
+		if s, ok := byPath[path]; ok {
+			return s
+		}
+		s := &fileReviewStats{Path: path, Reviewed: -1}
+		if done, total, ok := m.fileReviewProgress(path); ok {
+			s.Reviewed = reviewPercent(done, total)
+		}
+		byPath[path] = s
+		return s
+	}
+	for _, item := range m.fileItems {
+		get(item.Path)
+	}
+	for path, d := range m.reviewTimes {
+		get(path).Time = d
+	}
+	for _, c := range m.comments {
+		get(c.Path).Comments++
+	}
+
+	out := make([]fileReviewStats, 0, len(byPath))
+	for _, s := range byPath {
+		out = append(out, *s)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Time != out[j].Time {
+			return out[i].Time > out[j].Time
+		}
+		return out[i].Path < out[j].Path
+	})
+	return out
+}
+
+func formatReviewDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	default:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
+
+func (s fileReviewStats) summary() string {
+	reviewed := "not opened"
+	if s.Reviewed >= 0 {
+		reviewed = fmt.Sprintf("%d%% reviewed", s.Reviewed)
+	}
+	return fmt.Sprintf("%s, %d comment(s), %s", formatReviewDuration(s.Time), s.Comments, reviewed)
+}
+
+// reviewStatsHeadline is the overall line of the statistics.
+func (m Model) reviewStatsHeadline(stats []fileReviewStats) string {
+	visited := 0
+	for _, s := range stats {
+		if s.Time > 0 {
+			visited++
+		}
+	}
+	done, total, _ := m.reviewProgress()
+	return fmt.Sprintf("%s on %d of %d file(s), %d comment(s), %d%% of opened lines reviewed",
+		formatReviewDuration(m.reviewTotal), visited, len(stats), len(m.comments), reviewPercent(done, total))
+}
+
+// reviewStatsText is the plain-text summary appended to exports.
+func (m Model) reviewStatsText() string {
+	stats := m.reviewStats()
+	lines := []string{"Review summary: " + m.reviewStatsHeadline(stats)}
+	for _, s := range stats {
+		lines = append(lines, fmt.Sprintf("- %s: %s", s.Path, s.summary()))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// exportReviewStats returns the summary for plain exports when the config
+// asks for it, and "" otherwise.
+func (m Model) exportReviewStats() string {
+	if !m.exportStats {
+		return ""
+	}
+	return m.reviewStatsText()
+}
+
+// withExportSummary appends summary, when there is one, to an export.
+func withExportSummary(text, summary string) string {
+	if summary == "" {
+		return text
+	}
+	return text + "\n\n" + summary
+}
+
+func (m *Model) openStats() {
+	m.statsOpen = true
+	m.statsScroll = 0
+}
+
+func (m Model) handleStats(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.Type == tea.KeyEsc, isRuneKey(msg, "q"), key.Matches(msg, m.keys.Stats), msg.Type == tea.KeyEnter:
+		m.statsOpen = false
+	case key.Matches(msg, m.keys.Down):
+		if m.statsScroll < len(m.reviewStats())-1 {
+			m.statsScroll++
+		}
+	case key.Matches(msg, m.keys.Up):
+		if m.statsScroll > 0 {
+			m.statsScroll--
+		}
+	}
+	return m, nil
+}
+
+func (m Model) renderStatsModal() string {
+	width := max(24, min(100, m.width-10))
+	innerW := max(1, width-6)
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+
+	stats := m.reviewStats()
+	lines := []string{ansi.Truncate(m.reviewStatsHeadline(stats), innerW, "…"), ""}
+	visible := max(3, m.height-14)
+	start := min(m.statsScroll, max(0, len(stats)-1))
+	end := min(len(stats), start+visible)
+	if start > 0 {
+		lines = append(lines, dim.Render(fmt.Sprintf("… %d more above", start)))
+	}
+	for _, s := range stats[start:end] {
+		detail := "  " + s.summary()
+		name := ansi.Truncate(s.Path, max(1, innerW-ansi.StringWidth(detail)), "…")
+		lines = append(lines, name+dim.Render(detail))
+	}
+	if end < len(stats) {
+		lines = append(lines, dim.Render(fmt.Sprintf("… %d more below", len(stats)-end)))
+	}
+	if text := m.checklistText(); text != "" {
+		lines = append(lines, "")
+		for _, line := range strings.Split(text, "\n") {
+			lines = append(lines, ansi.Truncate(line, innerW, "…"))
+		}
+	}
+	lines = append(lines, "", dim.Render("j/k scroll | I/Esc close"))
+
+	title := lipgloss.NewStyle().
+		Width(max(1, width-2)).
+		Padding(0, 1).
+		Bold(true).
+		Foreground(lipgloss.Color("230")).
+		Background(lipgloss.Color("63")).
+		Render("Review statistics")
+
+	bodyBlock := lipgloss.NewStyle().
+		Width(max(1, width-2)).
+		Padding(1, 2).
+		Render(strings.Join(lines, "\n"))
+
+	return lipgloss.NewStyle().
+		Width(width).
+		Border(diffview.Border(lipgloss.RoundedBorder())).
+		BorderForeground(lipgloss.Color("63")).
+		Render(title + "\n" + bodyBlock)
+}
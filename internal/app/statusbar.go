@@ -0,0 +1,131 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"diffman/internal/diffview"
+	gitint "diffman/internal/git"
+)
+
+type headLoadedMsg struct {
+	head gitint.HeadInfo
+	err  error
+}
+
+func (m Model) loadHeadCmd() tea.Cmd {
+	cwd := m.cwd
+	return func() tea.Msg {
+		head, err := gitint.ReadHead(context.Background(), cwd)
+		return headLoadedMsg{head: head, err: err}
+	}
+}
+
+// statusBarSegments lists the status bar fields from left to right.
+func (m Model) statusBarSegments() []string {
+	segments := make([]string, 0, 6)
+	if m.reviewMode == reviewModePR && m.prCtx != nil {
+		segments = append(segments, fmt.Sprintf("%s/%s", m.prCtx.Owner, m.prCtx.Repo))
+		pr := fmt.Sprintf("PR #%d", m.prCtx.Number)
+		if m.prCtx.HeadRef != "" && m.prCtx.BaseRef != "" {
+			pr += fmt.Sprintf(" %s → %s", m.prCtx.HeadRef, m.prCtx.BaseRef)
+		}
+		segments = append(segments, pr)
+	} else {
+		segments = append(segments, filepath.Base(m.cwd))
+		switch {
+		case m.head.Detached:
+			segments = append(segments, "detached HEAD")
+		case m.head.Branch != "":
+			segments = append(segments, m.head.Branch)
+		}
+		if m.head.Unborn {
+			segments = append(segments, "no commits yet")
+		} else if m.head.ShortSHA != "" {
+			segments = append(segments, m.head.ShortSHA)
+		}
+	}
+	segments = append(segments, "mode: "+m.diffModeLabel())
+	if filters := m.filterStatusSegment(); filters != "" {
+		segments = append(segments, filters)
+	}
+	if delta := m.deltaStatusSegment(); delta != "" {
+		segments = append(segments, delta)
+	}
+	if m.copyMode && len(m.diffRows) > 0 {
+		lo, hi := m.copySelection()
+		segments = append(segments, fmt.Sprintf("COPY %d row(s)", hi-lo+1))
+	}
+
+	total := len(m.comments)
+	counts := fmt.Sprintf("%d comment(s)", total)
+	if stale := m.staleCommentCount(); stale > 0 {
+		counts += fmt.Sprintf(", %d stale", stale)
+	}
+	segments = append(segments, counts)
+	if reviewed := m.reviewStatusSegment(); reviewed != "" {
+		segments = append(segments, reviewed)
+	}
+	if checklist := m.checklistStatusSegment(); checklist != "" {
+		segments = append(segments, checklist)
+	}
+	return segments
+}
+
+// statusFilters describe the filters that hide files or comments, or return
+// "" when theirs is off. A new filter adds its description here so the status
+// bar shows it.
+var statusFilters = []func(Model) string{
+	Model.scopeStatusSegment,
+	func(m Model) string {
+		if len(m.excludes) == 0 {
+			return ""
+		}
+		return "exclude: " + strings.Join(m.excludes, " ")
+	},
+	func(m Model) string {
+		if !m.hideIndexFlagged {
+			return ""
+		}
+		return "index-flagged hidden"
+	},
+	func(m Model) string {
+		if filter := strings.TrimSpace(m.commentsFilter); filter != "" {
+			return "comments: /" + filter
+		}
+		return ""
+	},
+}
+
+// filterStatusSegment lists the active filters in one status bar field.
+func (m Model) filterStatusSegment() string {
+	var active []string
+	for _, describe := range statusFilters {
+		if s := describe(m); s != "" {
+			active = append(active, s)
+		}
+	}
+	return strings.Join(active, ", ")
+}
+
+func (m Model) renderStatusBar() string {
+	width := max(1, m.width)
+	sep := " │ "
+	if diffview.PlainMode() {
+		sep = " | "
+	}
+	text := " " + strings.Join(m.statusBarSegments(), sep)
+	text = ansi.Truncate(text, width, "")
+	return lipgloss.NewStyle().
+		Width(width).
+		MaxWidth(width).
+		Foreground(lipgloss.Color("230")).
+		Background(lipgloss.Color("237")).
+		Render(text)
+}
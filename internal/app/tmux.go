@@ -0,0 +1,43 @@
+package app
+
+import (
+	"context"
+
+	"diffman/internal/clipboard"
+	"diffman/internal/debuglog"
+)
+
+// copyExport copies an export to the clipboard and, inside tmux, into a tmux
+// paste buffer too, showing notice in tmux when it is set. The export
+// succeeds when either copy does, so it works over SSH without a clipboard.
+func copyExport(ctx context.Context, text, notice string) error {
+	err := clipboard.CopyText(ctx, text)
+	if !clipboard.InTmux() {
+		return err
+	}
+	if terr := clipboard.LoadTmuxBuffer(ctx, text); terr != nil {
+		debuglog.Printf("tmux load-buffer failed: %v", terr)
+		return err
+	}
+	if err != nil {
+		debuglog.Printf("clipboard copy failed, export is in the tmux buffer: %v", err)
+	}
+	if notice != "" {
+		if terr := clipboard.TmuxMessage(ctx, notice); terr != nil {
+			debuglog.Printf("tmux display-message failed: %v", terr)
+		}
+	}
+	return nil
+}
+
+// tmuxNotice is the tmux message for an export copied with okMsg, or empty
+// when tmux messages are off.
+func (m Model) tmuxNotice(okMsg string) string {
+	if !m.tmuxMessage {
+		return ""
+	}
+	if okMsg == "" {
+		return "diffman: copied comments export"
+	}
+	return "diffman: " + okMsg
+}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// fakeTmux puts a tmux first on PATH that records its arguments and stdin in
+// dir, and an xclip that always fails, standing in for a session without a
+// clipboard.
+func fakeTmux(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS != "linux" {
+		t.Skip("fakes the linux clipboard tool")
+	}
+	dir := t.TempDir()
+	script := "#!/bin/sh\necho \"$@\" >> \"" + dir + "/args\"\nif [ \"$1\" = load-buffer ]; then cat > \"" + dir + "/buffer\"; fi\n"
+	if err := os.WriteFile(filepath.Join(dir, "tmux"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "xclip"), []byte("#!/bin/sh\nexit 1\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return dir
+}
+
+func TestCopyExportLoadsTmuxBufferWithoutClipboard(t *testing.T) {
+	dir := fakeTmux(t)
+	t.Setenv("TMUX", "/tmp/tmux-1000/default,1,0")
+
+	if err := copyExport(context.Background(), "Review comments:\n- a.go:1 é", "diffman: copied"); err != nil {
+		t.Fatalf("copyExport: %v", err)
+	}
+	buffer, err := os.ReadFile(filepath.Join(dir, "buffer"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(buffer) != "Review comments:\n- a.go:1 é" {
+		t.Fatalf("buffer = %q", buffer)
+	}
+	args, _ := os.ReadFile(filepath.Join(dir, "args"))
+	if !strings.Contains(string(args), "display-message diffman: copied") {
+		t.Fatalf("tmux calls = %q, want a display-message", args)
+	}
+}
+
+func TestCopyExportOutsideTmuxSkipsTmux(t *testing.T) {
+	dir := fakeTmux(t)
+	t.Setenv("TMUX", "")
+
+	if err := copyExport(context.Background(), "export", ""); err == nil {
+		t.Fatal("copyExport succeeded though the clipboard failed")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "args")); err == nil {
+		t.Fatal("tmux ran outside tmux")
+	}
+}
+
+func TestTmuxNoticeOnlyWhenEnabled(t *testing.T) {
+	m := Model{}
+	if got := m.tmuxNotice("Copied 2 comment(s) to clipboard."); got != "" {
+		t.Fatalf("notice = %q with tmux messages off", got)
+	}
+	m.tmuxMessage = true
+	if got := m.tmuxNotice(""); got != "diffman: copied comments export" {
+		t.Fatalf("notice = %q", got)
+	}
+}
@@ -0,0 +1,106 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"diffman/internal/diffview"
+	gitint "diffman/internal/git"
+)
+
+// localDiffRows loads path's diff in mode as rows; empty reports that there
+// is nothing to show. origPath, when path was renamed or copied, pairs the
+// diff with the file it came from. Untracked files have no git diff, so their
+// content is shown as added lines instead.
+func localDiffRows(ctx context.Context, svc gitint.DiffService, cwd, path, origPath string, mode gitint.DiffMode) (rows []diffview.DiffRow, empty bool, err error) {
+	rows, _, empty, err = startLocalDiff(ctx, svc, cwd, path, origPath, mode, false)
+	return rows, empty, err
+}
+
+// startLocalDiff is localDiffRows that, with stream set, returns only the
+// first rows of a diff over streamDiffBytes along with the stream the rest
+// is parsed from.
+func startLocalDiff(ctx context.Context, svc gitint.DiffService, cwd, path, origPath string, mode gitint.DiffMode, stream bool) (rows []diffview.DiffRow, rest *diffview.DiffStream, empty bool, err error) {
+	var d string
+	if origPath != "" {
+		d, err = svc.DiffRename(ctx, cwd, origPath, path, mode)
+	} else {
+		d, err = svc.Diff(ctx, cwd, path, mode)
+	}
+	if err != nil {
+		return nil, nil, false, err
+	}
+	if strings.TrimSpace(d) != "" {
+		if stream && len(d) > streamDiffBytes {
+			rest = diffview.NewDiffStream([]byte(d))
+			var done bool
+			rows, done, err = nextDiffBatch(rest, path, streamDiffBatchLines)
+			if err != nil {
+				return nil, nil, false, err
+			}
+			if done {
+				rest = nil
+			}
+		} else {
+			parsed, err := diffview.ParseUnifiedDiff([]byte(d))
+			if err != nil {
+				return nil, nil, false, err
+			}
+			rows = rowsForPath(parsed, path)
+		}
+		if len(rows) == 0 && origPath != "" {
+			return diffview.NoticeRows(path, "Renamed from "+origPath+" without content changes"), nil, false, nil
+		}
+		if lfs, ok := lfsRows(path, rows); ok && rest == nil {
+			return lfs, nil, false, nil
+		}
+		return rows, rest, false, nil
+	}
+	if mode == gitint.DiffModeStaged {
+		return nil, nil, true, nil
+	}
+
+	file, ok, err := svc.Untracked(ctx, cwd, path)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	if !ok {
+		return nil, nil, true, nil
+	}
+	return untrackedRows(file), nil, false, nil
+}
+
+// rowsForPath keeps the rows of path; a rename diff also covers the source
+// path when it changed too.
+func rowsForPath(rows []diffview.DiffRow, path string) []diffview.DiffRow {
+	out := rows[:0]
+	for _, r := range rows {
+		if r.Path == path {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func untrackedRows(file gitint.UntrackedFile) []diffview.DiffRow {
+	switch {
+	case file.Binary:
+		return diffview.NoticeRows(file.Path, fmt.Sprintf("Untracked binary file (%s) not shown", formatByteSize(file.Size)))
+	case file.TooLarge:
+		return diffview.NoticeRows(file.Path, fmt.Sprintf("Untracked file is %s, over the %s display limit", formatByteSize(file.Size), formatByteSize(gitint.MaxUntrackedBytes)))
+	case len(file.Content) == 0:
+		return diffview.NoticeRows(file.Path, "Empty untracked file")
+	}
+	return diffview.NewFileRows(file.Path, file.Content)
+}
+
+func formatByteSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d bytes", n)
+}
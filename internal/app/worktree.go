@@ -0,0 +1,271 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"diffman/internal/comments"
+	"diffman/internal/diffview"
+	gitint "diffman/internal/git"
+	"diffman/internal/history"
+	"diffman/internal/session"
+	"diffman/internal/snapshot"
+)
+
+func (m Model) openWorktreePicker() (tea.Model, tea.Cmd) {
+	if m.reviewMode != reviewModeLocal {
+		m.setAlert("Worktree switching is only available for local review.")
+		return m, nil
+	}
+	if os.Getenv("GIT_DIR") != "" {
+		m.setAlert("GIT_DIR is set, so diffman stays on that repository.")
+		return m, nil
+	}
+	items, err := gitint.ListWorktrees(context.Background(), m.cwd)
+	if err != nil {
+		m.setAlert(fmt.Sprintf("failed to list worktrees: %v", err))
+		return m, nil
+	}
+	m.worktreeOpen = true
+	m.worktreeItems = items
+	m.worktreeErr = ""
+	m.worktreeCursor = 0
+	for i, wt := range items {
+		if filepath.Clean(wt.Path) == filepath.Clean(m.cwd) {
+			m.worktreeCursor = i
+		}
+	}
+	return m, nil
+}
+
+func (m Model) handleWorktreePicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.Type == tea.KeyEsc, isRuneKey(msg, "q"), key.Matches(msg, m.keys.Worktrees):
+		m.worktreeOpen = false
+	case key.Matches(msg, m.keys.Down):
+		if m.worktreeCursor < len(m.worktreeItems)-1 {
+			m.worktreeCursor++
+		}
+	case key.Matches(msg, m.keys.Up):
+		if m.worktreeCursor > 0 {
+			m.worktreeCursor--
+		}
+	case key.Matches(msg, m.keys.Open):
+		if len(m.worktreeItems) == 0 {
+			return m, nil
+		}
+		wt := m.worktreeItems[m.worktreeCursor]
+		if wt.Bare {
+			m.worktreeErr = "The bare repository has no working tree to review."
+			return m, nil
+		}
+		if filepath.Clean(wt.Path) == filepath.Clean(m.cwd) {
+			m.worktreeOpen = false
+			return m, nil
+		}
+		cmd, err := m.switchRepo(wt.Path)
+		if err != nil {
+			m.worktreeErr = err.Error()
+			return m, nil
+		}
+		m.worktreeOpen = false
+		m.setAlert("Switched to worktree " + wt.Path + ".")
+		return m, cmd
+	}
+	return m, nil
+}
+
+// switchRepo points the model at the repository containing dir, with that
+// repository's own comments, snapshots, and history. Comments stay per
+// worktree because each linked worktree has its own git dir. Path arguments
+// named paths in the previous repository, so the new one is reviewed whole.
+func (m *Model) switchRepo(dir string) (tea.Cmd, error) {
+	ctx := context.Background()
+	root, err := gitint.DiscoverRepoRoot(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	gitDir, err := gitint.DiscoverGitDir(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+	store := comments.NewStore(gitDir).WithKey(m.commentsKey)
+	loaded, err := store.Load()
+	if comments.IsRecovered(err) {
+		m.setAlert(err.Error())
+	} else if err != nil {
+		return nil, fmt.Errorf("load comments: %w", err)
+	}
+
+	if err := m.recentStore.Touch(root); err != nil {
+		m.setAlert(fmt.Sprintf("failed to record recent repository: %v", err))
+	}
+
+	// Leave the repository being switched away from resumable too.
+	m.sessionPosition = m.cursorPosition()
+	m.saveSessionState()
+
+	m.cwd = root
+	m.scope = nil
+	m.statusSvc = gitint.NewStatusService(m.excludes...)
+	m.diffSvc = gitint.NewDiffService(m.excludes...)
+	m.commentStore = store
+	m.sessionStore = session.NewStore(gitDir)
+	m.checklistDone = nil
+	m.lastExport = nil
+	if state, err := m.sessionStore.Load(); err == nil {
+		m.applySessionState(state)
+	}
+	m.snapshotStore = snapshot.NewStore(gitDir)
+	m.historyStore = history.NewStore(gitDir).WithKey(m.commentsKey)
+	m.comments = make(map[string]comments.Comment, len(loaded))
+	for _, c := range loaded {
+		m.comments[commentKey(c)] = c
+	}
+	m.stampComments()
+	m.resetReviewState()
+	m.loadStashedDrafts()
+	m.loadingFiles = true
+	return tea.Batch(m.loadFilesCmd(), m.loadHeadCmd()), nil
+}
+
+// resetReviewState drops everything derived from the files and diffs under
+// review, for when the checkout being reviewed changes underneath the model.
+// The comments themselves are left alone. TestResetReviewStateCoversModel
+// fails until a new Model field is reset here or listed as kept.
+func (m *Model) resetReviewState() {
+	m.commentStale = make(map[string]bool)
+	m.commentStaleReasons = nil
+	m.commentsSelected = nil
+	m.anchorDrafts = nil
+	m.pendingDraft = nil
+	m.reviewLines = nil
+	m.reviewed = nil
+	m.reviewTimes = nil
+	m.reviewTotal = 0
+	m.cleanupOffered = ""
+	m.deltaMode = false
+	m.reviewSnapshot = nil
+	m.reviewSnapshotKey = ""
+	m.deltaAllItems = nil
+	m.deltaHidden = 0
+	m.deltaHunksHidden = 0
+	m.head = gitint.HeadInfo{}
+	m.fileItems = nil
+	m.fileStats = nil
+	m.fileMTimes = nil
+	m.filesErr = nil
+	m.diffErr = nil
+	m.diffErrPath = ""
+	m.treeCollapsed = nil
+	m.expandedComments = nil
+	m.expandedLines = nil
+	m.commentsCollapsed = nil
+	m.commentsCursor = 0
+	m.commentsScroll = 0
+	m.address = nil
+	m.dirSummary = nil
+	m.refCompare = nil
+	m.commitPanel = nil
+	m.commitPanelOpen = false
+	m.prQueueOpen = false
+	m.prQueueLoading = false
+	m.prQueueItems = nil
+	m.prQueueCursor = 0
+	m.prQueueErr = ""
+	m.prQueueConfirm = nil
+	m.historyOpen = false
+	m.historyItems = nil
+	m.historyCursor = 0
+	m.historyViewing = false
+	m.historyScroll = 0
+	m.stalePopupKey = ""
+	m.revertConfirm = nil
+	m.sparseConfirm = nil
+	m.pendingCommentJump = nil
+	m.pendingLocation = nil
+	m.refreshAnchor = nil
+	m.selected = 0
+	m.selectedF = ""
+	m.fileCursor = 0
+	m.fileScroll = 0
+	m.diffRows = nil
+	m.diffCursor = 0
+	m.rowStarts = nil
+	m.rowHeights = nil
+	m.diffStream = nil
+	m.diffLoadPending = false
+	m.styledFrom = 0
+	m.styledTo = 0
+	m.diffUnstyled = false
+	m.diffDirty = true
+	m.copyMode = false
+	m.copyAnchor = 0
+	m.prItems = nil
+	m.prCursor = 0
+	m.prScroll = 0
+	m.err = nil
+	m.focus = focusFiles
+}
+
+func (m Model) renderWorktreePicker() string {
+	width := max(24, min(100, m.width-10))
+	innerW := max(1, width-6)
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+
+	lines := make([]string, 0, len(m.worktreeItems)+4)
+	for i, wt := range m.worktreeItems {
+		marker := "  "
+		style := lipgloss.NewStyle()
+		if i == m.worktreeCursor {
+			marker = "> "
+			style = style.Foreground(lipgloss.Color("39")).Bold(true)
+		}
+		label := wt.Branch
+		switch {
+		case wt.Bare:
+			label = "(bare)"
+		case wt.Detached && len(wt.HeadSHA) >= 7:
+			label = "(detached " + wt.HeadSHA[:7] + ")"
+		}
+		current := ""
+		if filepath.Clean(wt.Path) == filepath.Clean(m.cwd) {
+			current = " (current)"
+		}
+		text := ansi.Truncate(marker+wt.Path, max(1, innerW-ansi.StringWidth(label)-ansi.StringWidth(current)-2), "…")
+		lines = append(lines, style.Render(text)+"  "+dim.Render(label+current))
+	}
+	if m.worktreeErr != "" {
+		lines = append(lines, "", lipgloss.NewStyle().Foreground(lipgloss.Color("203")).Render(
+			ansi.Truncate("Error: "+m.worktreeErr, innerW, ""),
+		))
+	}
+	lines = append(lines, "", dim.Render("j/k move | Enter switch | w/Esc close"))
+
+	title := lipgloss.NewStyle().
+		Width(max(1, width-2)).
+		Padding(0, 1).
+		Bold(true).
+		Foreground(lipgloss.Color("230")).
+		Background(lipgloss.Color("63")).
+		Render("Worktrees")
+
+	bodyBlock := lipgloss.NewStyle().
+		Width(max(1, width-2)).
+		Padding(1, 2).
+		Render(strings.Join(lines, "\n"))
+
+	return lipgloss.NewStyle().
+		Width(width).
+		Border(diffview.Border(lipgloss.RoundedBorder())).
+		BorderForeground(lipgloss.Color("63")).
+		Render(title + "\n" + bodyBlock)
+}
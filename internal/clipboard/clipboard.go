@@ -2,11 +2,17 @@ package clipboard
 
 import (
 	"context"
+	"os/exec"
 	"runtime"
 
 	"diffman/internal/util"
 )
 
+// windowsCopyScript reads stdin as UTF-8 and copies it. clip.exe decodes its
+// input with the console code page, which mangles non-ASCII text.
+const windowsCopyScript = "[Console]::InputEncoding = [Text.UTF8Encoding]::new($false); " +
+	"Set-Clipboard -Value ([Console]::In.ReadToEnd())"
+
 func CopyText(ctx context.Context, text string) error {
 	switch runtime.GOOS {
 	case "darwin":
@@ -16,9 +22,22 @@ func CopyText(ctx context.Context, text string) error {
 		_, err := util.RunWithStdin(ctx, "", text, "xclip", "-selection", "clipboard")
 		return err
 	case "windows":
-		_, err := util.RunWithStdin(ctx, "", text, "clip")
-		return err
+		return copyWindows(ctx, text)
 	default:
 		return nil
 	}
 }
+
+// copyWindows copies with PowerShell, preferring PowerShell 7 and falling back
+// to clip only when no PowerShell is installed.
+func copyWindows(ctx context.Context, text string) error {
+	for _, shell := range []string{"pwsh", "powershell"} {
+		if _, err := exec.LookPath(shell); err != nil {
+			continue
+		}
+		_, err := util.RunWithStdin(ctx, "", text, shell, "-NoProfile", "-NonInteractive", "-Command", windowsCopyScript)
+		return err
+	}
+	_, err := util.RunWithStdin(ctx, "", text, "clip")
+	return err
+}
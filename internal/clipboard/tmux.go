@@ -0,0 +1,27 @@
+package clipboard
+
+import (
+	"context"
+	"os"
+
+	"diffman/internal/util"
+)
+
+// InTmux reports whether diffman runs inside a tmux session.
+func InTmux() bool {
+	return os.Getenv("TMUX") != ""
+}
+
+// LoadTmuxBuffer puts text in a new tmux paste buffer, which every pane of
+// the server can paste, including over SSH where no system clipboard is
+// reachable.
+func LoadTmuxBuffer(ctx context.Context, text string) error {
+	_, err := util.RunWithStdin(ctx, "", text, "tmux", "load-buffer", "-")
+	return err
+}
+
+// TmuxMessage shows message in the tmux status line of the current client.
+func TmuxMessage(ctx context.Context, message string) error {
+	_, err := util.Run(ctx, "", "tmux", "display-message", message)
+	return err
+}
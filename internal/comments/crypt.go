@@ -0,0 +1,169 @@
+package comments
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// KeyEnvVar holds a comment store key as 64 hex digits. It takes precedence
+// over the key file, so the key can come from a password manager.
+const KeyEnvVar = "DIFFMAN_COMMENTS_KEY"
+
+// KeySize is the length of a comment store key: AES-256.
+const KeySize = 32
+
+// encryptedMagic starts every encrypted file; the rest is base64 of the
+// GCM nonce followed by the sealed JSON.
+const encryptedMagic = "diffman-encrypted-v1\n"
+
+var (
+	// ErrLocked is returned for an encrypted file when the store has no key.
+	ErrLocked = errors.New("comments are encrypted; enable encrypt_comments or set " + KeyEnvVar)
+	// ErrWrongKey is returned when the store's key does not open the file.
+	ErrWrongKey = errors.New("comments are encrypted with a different key")
+)
+
+// WithKey returns a store that encrypts what it writes with key. Files
+// written without encryption are still read and get encrypted on the next
+// save. A nil key returns an unencrypted store.
+func (s Store) WithKey(key []byte) Store {
+	s.key = key
+	return s
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encode seals plain when the store has a key and returns it unchanged otherwise.
+func (s Store) encode(plain []byte) ([]byte, error) {
+	return Seal(s.key, plain)
+}
+
+// decode opens b if it is encrypted and returns it unchanged otherwise.
+func (s Store) decode(b []byte) ([]byte, error) {
+	return Open(s.key, b)
+}
+
+// Seal encrypts plain with key in the comment store's format, so other
+// files holding comment bodies, such as the review history, can be
+// protected the same way. A nil key returns plain unchanged.
+func Seal(key, plain []byte) ([]byte, error) {
+	if key == nil {
+		return plain, nil
+	}
+	gcm, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	sealed := gcm.Seal(nonce, nonce, plain, nil)
+	return []byte(encryptedMagic + base64.StdEncoding.EncodeToString(sealed) + "\n"), nil
+}
+
+func isEncrypted(b []byte) bool {
+	return bytes.HasPrefix(b, []byte(encryptedMagic))
+}
+
+// Open reverses Seal. Unencrypted input is returned unchanged; encrypted
+// input fails with ErrLocked without a key and ErrWrongKey with another.
+func Open(key, b []byte) ([]byte, error) {
+	if !isEncrypted(b) {
+		return b, nil
+	}
+	if key == nil {
+		return nil, ErrLocked
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(b[len(encryptedMagic):])))
+	if err != nil {
+		return nil, fmt.Errorf("decode encrypted comments: %w", err)
+	}
+	gcm, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("encrypted comments are truncated")
+	}
+	plain, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, ErrWrongKey
+	}
+	return plain, nil
+}
+
+// isKeyError reports errors that say the file is fine but cannot be opened,
+// which must never be mistaken for corruption.
+func isKeyError(err error) bool {
+	return errors.Is(err, ErrLocked) || errors.Is(err, ErrWrongKey)
+}
+
+// ParseKey reads a key written as 64 hex digits.
+func ParseKey(text string) ([]byte, error) {
+	key, err := hex.DecodeString(strings.TrimSpace(text))
+	if err != nil || len(key) != KeySize {
+		return nil, fmt.Errorf("key must be %d hex digits", 2*KeySize)
+	}
+	return key, nil
+}
+
+// LoadOrCreateKey reads the key in $DIFFMAN_COMMENTS_KEY or, without it, the
+// key file at path, which is created with a random key readable only by the
+// user when missing.
+func LoadOrCreateKey(path string) ([]byte, error) {
+	if env := strings.TrimSpace(os.Getenv(KeyEnvVar)); env != "" {
+		key, err := ParseKey(env)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", KeyEnvVar, err)
+		}
+		return key, nil
+	}
+
+	b, err := os.ReadFile(path)
+	if err == nil {
+		key, err := ParseKey(string(b))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return key, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.WriteString(hex.EncodeToString(key) + "\n"); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
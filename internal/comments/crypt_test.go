@@ -0,0 +1,115 @@
+package comments
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, KeySize)
+}
+
+func TestEncryptedStoreRoundTripsAndEncryptsOldPlainFiles(t *testing.T) {
+	dir := t.TempDir()
+	plain := NewStore(dir)
+	if err := plain.Save([]Comment{{Path: "a.go", Side: SideNew, Line: 1, Body: "secret plan"}}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	store := NewStore(dir).WithKey(testKey(1))
+	got, err := store.Load()
+	if err != nil || len(got) != 1 {
+		t.Fatalf("expected the plain file to load, got %+v, %v", got, err)
+	}
+	got = append(got, Comment{Path: "b.go", Side: SideOld, Line: 2, Body: "more"})
+	if err := store.Save(got); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := store.SaveDraft(Draft{Path: "a.go", Side: SideNew, Line: 3, Body: "secret draft"}); err != nil {
+		t.Fatalf("SaveDraft() error = %v", err)
+	}
+	for _, path := range []string{store.path, store.backupPath(), store.draftPath()} {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile(%s) error = %v", path, err)
+		}
+		if !isEncrypted(b) || strings.Contains(string(b), "secret") {
+			t.Fatalf("expected %s encrypted, got %q", filepath.Base(path), b)
+		}
+	}
+
+	got, err = store.Load()
+	if err != nil || len(got) != 2 || got[0].Body != "secret plan" {
+		t.Fatalf("Load() = %+v, %v", got, err)
+	}
+	d, err := store.LoadDraft()
+	if err != nil || d == nil || d.Body != "secret draft" {
+		t.Fatalf("LoadDraft() = %+v, %v", d, err)
+	}
+}
+
+func TestEncryptedStoreWithoutTheKeyIsNeverOverwritten(t *testing.T) {
+	dir := t.TempDir()
+	if err := NewStore(dir).WithKey(testKey(1)).Save([]Comment{{Path: "a.go", Line: 1, Body: "x"}}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	for _, store := range []Store{NewStore(dir), NewStore(dir).WithKey(testKey(2))} {
+		if _, err := store.Load(); !errors.Is(err, ErrLocked) && !errors.Is(err, ErrWrongKey) {
+			t.Fatalf("Load() error = %v, want a key error", err)
+		}
+		if err := store.Save(nil); err == nil {
+			t.Fatalf("expected Save() to refuse overwriting an encrypted file it cannot open")
+		}
+	}
+	entries, _ := os.ReadDir(filepath.Dir(NewStore(dir).path))
+	for _, e := range entries {
+		if strings.Contains(e.Name(), ".corrupt-") {
+			t.Fatalf("an encrypted file was treated as corrupt: %s", e.Name())
+		}
+	}
+	if got, err := NewStore(dir).WithKey(testKey(1)).Load(); err != nil || len(got) != 1 {
+		t.Fatalf("expected the comments intact, got %+v, %v", got, err)
+	}
+}
+
+func TestLoadOrCreateKey(t *testing.T) {
+	t.Setenv(KeyEnvVar, "")
+	path := filepath.Join(t.TempDir(), "diffman", "comments.key")
+	key, err := LoadOrCreateKey(path)
+	if err != nil || len(key) != KeySize {
+		t.Fatalf("LoadOrCreateKey() = %x, %v", key, err)
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected a key file only the user can read, got %v, %v", info, err)
+	}
+	again, err := LoadOrCreateKey(path)
+	if err != nil || !bytes.Equal(again, key) {
+		t.Fatalf("expected the saved key to be reused")
+	}
+
+	t.Setenv(KeyEnvVar, strings.Repeat("ab", KeySize))
+	if env, err := LoadOrCreateKey(path); err != nil || env[0] != 0xab {
+		t.Fatalf("expected the environment key to win, got %x, %v", env, err)
+	}
+	t.Setenv(KeyEnvVar, "short")
+	if _, err := LoadOrCreateKey(path); err == nil {
+		t.Fatalf("expected a malformed key to be rejected")
+	}
+}
+
+func TestRedactDropsCodeContext(t *testing.T) {
+	c := Comment{Path: "a.go", Side: SideNew, Line: 3, Body: "why?", HunkHeader: "@@ -1 +1 @@", ContextBefore: []string{"x := 1"}, ContextAfter: []string{"return x"}}
+	out := ExportPlain(Redact([]Comment{c}), "")
+	if strings.Contains(out, "x := 1") || strings.Contains(out, "```") || !strings.Contains(out, "a.go new:3: why?") {
+		t.Fatalf("unexpected redacted export:\n%s", out)
+	}
+	if len(c.ContextBefore) != 1 {
+		t.Fatalf("expected Redact to leave the original comment alone")
+	}
+}
@@ -2,10 +2,83 @@ package comments
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 )
 
+// Export groupings. With ExportGroupNone comments keep the order they are
+// given in, without headings.
+const (
+	ExportGroupNone     = ""
+	ExportGroupFile     = "file"
+	ExportGroupSeverity = "severity"
+	ExportGroupTime     = "time"
+)
+
+// ExportOptions shape a plain export.
+type ExportOptions struct {
+	// Group lists comments under a heading per file or per severity, or in
+	// the order they were written; see OrderForExport.
+	Group string
+	// NoContext leaves out the code block under each comment.
+	NoContext bool
+	// Link returns the URL to show under a comment, or "" for none. It may be nil.
+	Link func(Comment) string
+}
+
 func ExportPlain(comments []Comment, title string) string {
+	return ExportPlainWithOptions(comments, title, ExportOptions{})
+}
+
+// ExportPlainWithLinks is ExportPlain with a URL under each comment line.
+// link may be nil, and comments it returns "" for get no URL.
+func ExportPlainWithLinks(comments []Comment, title string, link func(Comment) string) string {
+	return ExportPlainWithOptions(comments, title, ExportOptions{Link: link})
+}
+
+// OrderForExport returns comments in the order an export grouped by group
+// numbers them: by path for ExportGroupFile, most severe first for
+// ExportGroupSeverity, oldest first for ExportGroupTime. Ties keep their
+// order, so ordering twice changes nothing.
+func OrderForExport(comments []Comment, group string) []Comment {
+	out := append([]Comment(nil), comments...)
+	switch group {
+	case ExportGroupFile:
+		sort.SliceStable(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	case ExportGroupSeverity:
+		sort.SliceStable(out, func(i, j int) bool { return out[i].Severity() > out[j].Severity() })
+	case ExportGroupTime:
+		sort.SliceStable(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	}
+	return out
+}
+
+// exportHeading names the group c is listed under, or "" when the grouping
+// has no headings.
+func exportHeading(c Comment, group string) string {
+	switch group {
+	case ExportGroupFile:
+		return c.Path + ":"
+	case ExportGroupSeverity:
+		switch c.Severity() {
+		case SeverityBlocker:
+			return "Blockers:"
+		case SeverityIssue:
+			return "Issues:"
+		case SeverityNit:
+			return "Nits:"
+		default:
+			return "Comments:"
+		}
+	}
+	return ""
+}
+
+// ExportPlainWithOptions renders comments as a numbered list under title,
+// shaped by opts. Numbers run on across group headings.
+func ExportPlainWithOptions(comments []Comment, title string, opts ExportOptions) string {
+	comments = OrderForExport(comments, opts.Group)
+	link := opts.Link
 	if title == "" {
 		title = "Review comments:"
 	}
@@ -14,10 +87,27 @@ func ExportPlain(comments []Comment, title string) string {
 	}
 
 	lines := []string{title, ""}
+	heading := ""
 	for i, c := range comments {
+		if h := exportHeading(c, opts.Group); h != "" && h != heading {
+			heading = h
+			lines = append(lines, h)
+		}
 		body := strings.ReplaceAll(strings.TrimSpace(c.Body), "\n", " / ")
 		lines = append(lines, fmt.Sprintf("%d) %s %s:%d: %s", i+1, c.Path, c.Side.String(), c.Line, body))
-		if ctx := exportContextLines(c); len(ctx) > 0 {
+		if link != nil {
+			if u := link(c); u != "" {
+				lines = append(lines, "   "+u)
+			}
+		}
+		if label := ResolutionLabel(c.Resolution); label != "" {
+			reply := strings.ReplaceAll(strings.TrimSpace(c.Reply), "\n", " / ")
+			if reply != "" {
+				label += ": " + reply
+			}
+			lines = append(lines, "   -> "+label)
+		}
+		if ctx := exportContextLines(c); len(ctx) > 0 && !opts.NoContext {
 			lines = append(lines, "```")
 			lines = append(lines, ctx...)
 			lines = append(lines, "```")
@@ -27,6 +117,20 @@ func ExportPlain(comments []Comment, title string) string {
 	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
 }
 
+// Redact returns copies of comments without the hunk header and the code
+// around them, for sharing where code must not be pasted; the location and
+// body are kept.
+func Redact(comments []Comment) []Comment {
+	out := make([]Comment, len(comments))
+	for i, c := range comments {
+		c.HunkHeader = ""
+		c.ContextBefore = nil
+		c.ContextAfter = nil
+		out[i] = c
+	}
+	return out
+}
+
 func exportContextLines(c Comment) []string {
 	out := make([]string, 0, len(c.ContextBefore)+len(c.ContextAfter))
 	out = append(out, c.ContextBefore...)
@@ -36,3 +140,19 @@ func exportContextLines(c Comment) []string {
 	}
 	return out
 }
+
+// ExportQuickfix renders comments as "path:line:col: message" lines, the
+// format vim's quickfix list (:cfile, :cgetexpr) and editor problem matchers
+// read. Old-side comments point at the old file's numbering, so their
+// message is marked to say so.
+func ExportQuickfix(comments []Comment) string {
+	lines := make([]string, 0, len(comments))
+	for _, c := range comments {
+		body := strings.ReplaceAll(strings.TrimSpace(c.Body), "\n", " / ")
+		if c.Side == SideOld {
+			body = "(old) " + body
+		}
+		lines = append(lines, fmt.Sprintf("%s:%d:1: %s", c.Path, c.Line, body))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,49 @@
+package comments
+
+import (
+	"testing"
+	"time"
+)
+
+func exportFixture() []Comment {
+	t0 := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	return []Comment{
+		{Path: "a.go", Side: SideNew, Line: 1, Body: "nit: spacing", CreatedAt: t0.Add(2 * time.Minute)},
+		{Path: "a.go", Side: SideNew, Line: 9, Body: "why?", CreatedAt: t0, ContextAfter: []string{"x := 1"}},
+		{Path: "b.go", Side: SideOld, Line: 4, Body: "blocker: leaks", CreatedAt: t0.Add(time.Minute)},
+	}
+}
+
+func TestExportPlainGroupsBySeverity(t *testing.T) {
+	got := ExportPlainWithOptions(exportFixture(), "", ExportOptions{Group: ExportGroupSeverity, NoContext: true})
+	want := "Review comments:\n\n" +
+		"Blockers:\n1) b.go old:4: blocker: leaks\n\n" +
+		"Comments:\n2) a.go new:9: why?\n\n" +
+		"Nits:\n3) a.go new:1: nit: spacing"
+	if got != want {
+		t.Fatalf("export =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestExportPlainGroupsByFileWithContext(t *testing.T) {
+	got := ExportPlainWithOptions(exportFixture(), "", ExportOptions{Group: ExportGroupFile})
+	want := "Review comments:\n\n" +
+		"a.go:\n1) a.go new:1: nit: spacing\n\n2) a.go new:9: why?\n```\n> x := 1\n```\n\n" +
+		"b.go:\n3) b.go old:4: blocker: leaks"
+	if got != want {
+		t.Fatalf("export =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestOrderForExportByTimeIsStable(t *testing.T) {
+	once := OrderForExport(exportFixture(), ExportGroupTime)
+	twice := OrderForExport(once, ExportGroupTime)
+	for i, want := range []int{9, 4, 1} {
+		if once[i].Line != want || twice[i].Line != want {
+			t.Fatalf("order = %v then %v, want lines 9, 4, 1", once, twice)
+		}
+	}
+	if ExportPlain(exportFixture(), "") != ExportPlainWithOptions(exportFixture(), "", ExportOptions{}) {
+		t.Fatalf("expected no grouping to keep the given order")
+	}
+}
@@ -0,0 +1,42 @@
+package comments
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// FollowUpLabel starts the body of a comment to come back to in a later
+// session, in the style of the severity labels: "follow-up: rerun the
+// benchmark once the cache lands".
+const FollowUpLabel = "follow-up"
+
+// IsFollowUp reports whether the comment is labeled a follow-up, also as
+// "followup:" or with a decoration such as "follow-up (blocking):".
+func (c Comment) IsFollowUp() bool {
+	label, _, ok := strings.Cut(strings.TrimSpace(c.Body), ":")
+	if !ok {
+		return false
+	}
+	label, _, _ = strings.Cut(strings.ToLower(strings.TrimSpace(label)), "(")
+	switch strings.TrimSpace(label) {
+	case FollowUpLabel, "followup":
+		return true
+	}
+	return false
+}
+
+// OverdueFollowUps returns the open follow-ups among list that were written
+// at least days days before now, oldest first. With days 0 every open
+// follow-up is overdue.
+func OverdueFollowUps(list []Comment, now time.Time, days int) []Comment {
+	cutoff := now.AddDate(0, 0, -days)
+	var out []Comment
+	for _, c := range list {
+		if c.IsFollowUp() && c.Resolution == "" && !c.CreatedAt.After(cutoff) {
+			out = append(out, c)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out
+}
@@ -0,0 +1,39 @@
+package comments
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIsFollowUp(t *testing.T) {
+	for body, want := range map[string]bool{
+		"follow-up: rerun the benchmark": true,
+		"Followup: check the docs":       true,
+		"follow-up (blocking): fix it":   true,
+		"nit: follow-up later":           false,
+		"follow up on this":              false,
+	} {
+		if got := (Comment{Body: body}).IsFollowUp(); got != want {
+			t.Fatalf("IsFollowUp(%q) = %v, want %v", body, got, want)
+		}
+	}
+}
+
+func TestOverdueFollowUps(t *testing.T) {
+	now := time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC)
+	list := []Comment{
+		{Line: 1, Body: "follow-up: recent", CreatedAt: now.AddDate(0, 0, -2)},
+		{Line: 2, Body: "follow-up: oldest", CreatedAt: now.AddDate(0, 0, -30)},
+		{Line: 3, Body: "follow-up: done", CreatedAt: now.AddDate(0, 0, -30), Resolution: ResolutionAddressed},
+		{Line: 4, Body: "issue: not a follow-up", CreatedAt: now.AddDate(0, 0, -30)},
+		{Line: 5, Body: "follow-up: a week old", CreatedAt: now.AddDate(0, 0, -7)},
+	}
+
+	got := OverdueFollowUps(list, now, 7)
+	if len(got) != 2 || got[0].Line != 2 || got[1].Line != 5 {
+		t.Fatalf("unexpected overdue follow-ups: %+v", got)
+	}
+	if got := OverdueFollowUps(list, now, 0); len(got) != 3 {
+		t.Fatalf("expected every open follow-up with days 0, got %+v", got)
+	}
+}
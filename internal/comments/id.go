@@ -0,0 +1,49 @@
+package comments
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"time"
+)
+
+// NewID returns a random (version 4) UUID for a new comment.
+func NewID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("comments: read random id: %v", err))
+	}
+	return formatUUID(b, 4)
+}
+
+// legacyID derives an ID for a comment saved before comments had IDs from
+// its anchor and creation time, so it is the same on every load until the
+// store is saved with it.
+func legacyID(c Comment) string {
+	sum := sha256.Sum256([]byte(AnchorKey(c.Path, c.Side, c.Line) + "\x00" + c.CreatedAt.UTC().Format(time.RFC3339Nano)))
+	var b [16]byte
+	copy(b[:], sum[:])
+	return formatUUID(b, 8)
+}
+
+func formatUUID(b [16]byte, version byte) string {
+	b[6] = b[6]&0x0f | version<<4
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// AssignIDs gives every comment without an ID one, and a fresh one to any
+// comment repeating an earlier comment's ID, so each ID names one comment.
+func AssignIDs(comments []Comment) {
+	seen := make(map[string]bool, len(comments))
+	for i := range comments {
+		c := &comments[i]
+		if c.ID == "" || seen[c.ID] {
+			c.ID = legacyID(*c)
+		}
+		if seen[c.ID] {
+			c.ID = NewID()
+		}
+		seen[c.ID] = true
+	}
+}
@@ -0,0 +1,53 @@
+package comments
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[48][0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestNewIDIsAUniqueUUID(t *testing.T) {
+	a, b := NewID(), NewID()
+	if !uuidPattern.MatchString(a) || a == b {
+		t.Fatalf("NewID() = %q, %q", a, b)
+	}
+}
+
+func TestLoadGivesLegacyCommentsStableIDs(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStore(dir)
+	legacy := `[{"path":"a.go","side":1,"line":3,"body":"x","created_at":"2024-01-02T03:04:05Z"},
+{"path":"b.go","side":1,"line":3,"body":"y","created_at":"2024-01-02T03:04:05Z"}]`
+	if err := os.MkdirAll(filepath.Join(dir, ".diffman"), 0o755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".diffman", "comments.json"), []byte(legacy), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	first, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	second, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !uuidPattern.MatchString(first[0].ID) || first[0].ID == first[1].ID {
+		t.Fatalf("expected distinct UUIDs, got %q and %q", first[0].ID, first[1].ID)
+	}
+	if first[0].ID != second[0].ID || first[1].ID != second[1].ID {
+		t.Fatalf("expected the same IDs on every load")
+	}
+}
+
+func TestAssignIDsReplacesDuplicates(t *testing.T) {
+	cs := []Comment{{ID: "same", Path: "a.go", Line: 1}, {ID: "same", Path: "b.go", Line: 1}}
+	AssignIDs(cs)
+	if cs[0].ID != "same" || cs[1].ID == "same" || cs[1].ID == "" {
+		t.Fatalf("expected only the repeated ID replaced, got %q and %q", cs[0].ID, cs[1].ID)
+	}
+}
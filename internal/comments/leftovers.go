@@ -0,0 +1,56 @@
+package comments
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Leftovers lists files in the store's directory that nothing reads:
+// temporary files from writes interrupted before tmpCutoff, and damaged
+// copies kept by Load before corruptCutoff.
+func (s Store) Leftovers(tmpCutoff, corruptCutoff time.Time) ([]string, error) {
+	dir := filepath.Dir(s.path)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var out []string
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		name := e.Name()
+		var cutoff time.Time
+		switch {
+		case strings.HasPrefix(name, ".") && strings.Contains(name, ".tmp-"):
+			cutoff = tmpCutoff
+		case strings.HasPrefix(name, filepath.Base(s.path)+".corrupt-"):
+			cutoff = corruptCutoff
+		default:
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		if info.ModTime().Before(cutoff) {
+			out = append(out, filepath.Join(dir, name))
+		}
+	}
+	return out, nil
+}
+
+// Size is the size of the saved comments file in bytes, 0 without one.
+func (s Store) Size() int64 {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
@@ -1,6 +1,9 @@
 package comments
 
-import "time"
+import (
+	"path/filepath"
+	"time"
+)
 
 type Side int
 
@@ -10,18 +13,54 @@ const (
 )
 
 type Comment struct {
+	// ID names the comment for as long as it exists, wherever its anchor
+	// moves; AnchorKey only names the line it is on now.
+	ID            string    `json:"id,omitempty"`
 	Path          string    `json:"path"`
 	Side          Side      `json:"side"`
 	Line          int       `json:"line"`
 	Body          string    `json:"body"`
 	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at,omitzero"`
 	HunkHeader    string    `json:"hunk_header"`
 	ContextBefore []string  `json:"context_before"`
 	ContextAfter  []string  `json:"context_after"`
+	// Author is set on comments imported from a pull request; they are
+	// someone else's published comments, not drafts of your own.
+	Author string `json:"author,omitempty"`
+	// PR is the number of the pull request the comment was written on in
+	// PR mode; 0 for comments on local changes.
+	PR int `json:"pr,omitempty"`
+	// Pinned comments are listed first in the comments view.
+	Pinned bool `json:"pinned,omitempty"`
+	// Resolution records how review feedback was dealt with, one of the
+	// Resolution constants; empty while the comment is open.
+	Resolution string `json:"resolution,omitempty"`
+	// Reply is what was answered when the comment was resolved.
+	Reply string `json:"reply,omitempty"`
+}
+
+// Resolutions a comment can be given when working through feedback.
+const (
+	ResolutionAddressed = "addressed"
+	ResolutionWontFix   = "wontfix"
+)
+
+// ResolutionLabel describes a resolution for people, or "" for an open comment.
+func ResolutionLabel(resolution string) string {
+	switch resolution {
+	case ResolutionAddressed:
+		return "addressed"
+	case ResolutionWontFix:
+		return "won't fix"
+	}
+	return ""
 }
 
+// AnchorKey names the line a comment is on. Paths are keyed slash-separated
+// so a path git reported with backslashes on Windows names the same file.
 func AnchorKey(path string, side Side, line int) string {
-	return path + ":" + side.String() + ":" + fmtInt(line)
+	return filepath.ToSlash(path) + ":" + side.String() + ":" + fmtInt(line)
 }
 
 func (s Side) String() string {
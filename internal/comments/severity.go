@@ -0,0 +1,61 @@
+package comments
+
+import "strings"
+
+// Severity ranks a comment by the label its body starts with, following the
+// Conventional Comments style ("issue: ...", "nit: ...").
+type Severity int
+
+const (
+	SeverityNit Severity = iota
+	SeverityNormal
+	SeverityIssue
+	SeverityBlocker
+)
+
+var severityLabels = map[string]Severity{
+	"blocker":    SeverityBlocker,
+	"blocking":   SeverityBlocker,
+	"must":       SeverityBlocker,
+	"issue":      SeverityIssue,
+	"bug":        SeverityIssue,
+	"todo":       SeverityIssue,
+	"nit":        SeverityNit,
+	"nitpick":    SeverityNit,
+	"minor":      SeverityNit,
+	"praise":     SeverityNit,
+	"suggestion": SeverityNormal,
+	"question":   SeverityNormal,
+	"thought":    SeverityNormal,
+}
+
+// Severity returns the comment's severity; unlabeled comments are SeverityNormal.
+func (c Comment) Severity() Severity {
+	body := strings.TrimSpace(c.Body)
+	label, _, ok := strings.Cut(body, ":")
+	if !ok {
+		return SeverityNormal
+	}
+	// Allow decorations such as "issue (blocking):".
+	label, decoration, _ := strings.Cut(strings.ToLower(strings.TrimSpace(label)), "(")
+	if strings.Contains(decoration, "blocking") {
+		return SeverityBlocker
+	}
+	if s, ok := severityLabels[strings.TrimSpace(label)]; ok {
+		return s
+	}
+	return SeverityNormal
+}
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityNit:
+		return "nit"
+	case SeverityIssue:
+		return "issue"
+	case SeverityBlocker:
+		return "blocker"
+	default:
+		return "normal"
+	}
+}
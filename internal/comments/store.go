@@ -3,18 +3,61 @@ package comments
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"time"
+
+	"diffman/internal/util"
 )
 
 type Store struct {
 	path string
+	// key, when set, encrypts comments.json, its backup, and the draft.
+	key []byte
 }
 
 func NewStore(gitDir string) Store {
 	return Store{path: filepath.Join(gitDir, ".diffman", "comments.json")}
 }
 
+func (s Store) backupPath() string {
+	return s.path + ".bak"
+}
+
+// corruptPath is where an unreadable comments file is kept for inspection.
+func (s Store) corruptPath() string {
+	return s.path + ".corrupt-" + time.Now().UTC().Format("20060102-150405")
+}
+
+// RecoveredError reports that comments.json could not be read and the
+// comments were restored from the backup written by the previous save. The
+// unreadable file is kept at Corrupt. Load still returns the restored
+// comments alongside it.
+type RecoveredError struct {
+	Corrupt string
+	Err     error
+}
+
+func (e *RecoveredError) Error() string {
+	return fmt.Sprintf("comments file was unreadable (%v); restored the backup and kept the damaged file as %s", e.Err, e.Corrupt)
+}
+
+func (e *RecoveredError) Unwrap() error {
+	return e.Err
+}
+
+// IsRecovered reports whether err only says that Load fell back to the
+// backup, so the comments it returned can be used.
+func IsRecovered(err error) bool {
+	var rec *RecoveredError
+	return errors.As(err, &rec)
+}
+
+// Load reads the saved comments. A truncated or corrupt file is moved aside
+// and replaced by the backup, and a *RecoveredError is returned with the
+// backup's comments. An encrypted file the store cannot open is left alone
+// and reported as ErrLocked or ErrWrongKey.
 func (s Store) Load() ([]Comment, error) {
 	b, err := os.ReadFile(s.path)
 	if err != nil {
@@ -24,21 +67,235 @@ func (s Store) Load() ([]Comment, error) {
 		return nil, err
 	}
 
+	out, parseErr := s.parse(b)
+	if parseErr == nil {
+		return out, nil
+	}
+	if isKeyError(parseErr) {
+		return nil, parseErr
+	}
+	backup, err := os.ReadFile(s.backupPath())
+	if err != nil {
+		return nil, parseErr
+	}
+	out, err = s.parse(backup)
+	if err != nil {
+		return nil, parseErr
+	}
+
+	corrupt := s.corruptPath()
+	if err := os.Rename(s.path, corrupt); err != nil {
+		return nil, fmt.Errorf("%w (keeping the damaged file failed: %v)", parseErr, err)
+	}
+	if err := writeFileAtomic(s.path, backup); err != nil {
+		return nil, fmt.Errorf("%w (restoring the backup failed: %v)", parseErr, err)
+	}
+	return out, &RecoveredError{Corrupt: corrupt, Err: parseErr}
+}
+
+func (s Store) parse(b []byte) ([]Comment, error) {
+	plain, err := s.decode(b)
+	if err != nil {
+		return nil, err
+	}
 	var out []Comment
-	if err := json.Unmarshal(b, &out); err != nil {
+	if err := json.Unmarshal(plain, &out); err != nil {
 		return nil, err
 	}
+	if out == nil {
+		out = []Comment{}
+	}
+	for i := range out {
+		out[i].Path = filepath.ToSlash(out[i].Path)
+	}
+	AssignIDs(out)
 	return out, nil
 }
 
+// Save replaces the saved comments atomically. The file it replaces becomes
+// the backup when it is still readable, so a bad write never takes the last
+// good copy with it. The backup is written the way the store writes, so
+// turning encryption on leaves no plain copy behind.
 func (s Store) Save(comments []Comment) error {
 	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
 		return err
 	}
 
-	b, err := json.MarshalIndent(comments, "", "  ")
+	plain, err := json.MarshalIndent(comments, "", "  ")
+	if err != nil {
+		return err
+	}
+	b, err := s.encode(plain)
+	if err != nil {
+		return err
+	}
+	if prev, err := os.ReadFile(s.path); err == nil {
+		if _, err := s.parse(prev); err == nil {
+			backup := prev
+			if !isEncrypted(prev) {
+				if backup, err = s.encode(prev); err != nil {
+					return err
+				}
+			}
+			if err := writeFileAtomic(s.backupPath(), backup); err != nil {
+				return fmt.Errorf("back up comments: %w", err)
+			}
+		} else if isKeyError(err) {
+			return fmt.Errorf("not overwriting comments: %w", err)
+		} else if err := os.Rename(s.path, s.corruptPath()); err != nil {
+			// Never write over a file Load could not read; it may be all
+			// that is left of the comments.
+			return fmt.Errorf("keep unreadable comments file: %w", err)
+		}
+	}
+	return writeFileAtomic(s.path, b)
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it
+// into place, so readers see either the old or the new content in full.
+func writeFileAtomic(path string, data []byte) error {
+	return util.WriteFileAtomic(path, data, 0o644)
+}
+
+// Stamp identifies one version of the comments file, so writes by other
+// processes can be noticed. The zero Stamp stands for a missing file.
+type Stamp struct {
+	info os.FileInfo
+}
+
+// Stamp describes the comments file as it is on disk now.
+func (s Store) Stamp() (Stamp, error) {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return Stamp{}, nil
+		}
+		return Stamp{}, err
+	}
+	return Stamp{info: info}, nil
+}
+
+// Equal reports whether both stamps describe the same write. Save replaces
+// the file on every write, so a new file counts as a change even when its
+// size and modification time match.
+func (a Stamp) Equal(b Stamp) bool {
+	if a.info == nil || b.info == nil {
+		return a.info == nil && b.info == nil
+	}
+	return os.SameFile(a.info, b.info) &&
+		a.info.ModTime().Equal(b.info.ModTime()) &&
+		a.info.Size() == b.info.Size()
+}
+
+// Draft is an in-progress comment body that has not been saved yet.
+type Draft struct {
+	Path string `json:"path"`
+	Side Side   `json:"side"`
+	Line int    `json:"line"`
+	Body string `json:"body"`
+	// PR is the pull request the draft was written on, as on Comment.
+	PR int `json:"pr,omitempty"`
+}
+
+func (s Store) draftPath() string {
+	return filepath.Join(filepath.Dir(s.path), "draft.json")
+}
+
+// LoadDraft returns the saved draft, or nil when there is none.
+func (s Store) LoadDraft() (*Draft, error) {
+	b, err := os.ReadFile(s.draftPath())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	plain, err := s.decode(b)
+	if err != nil {
+		return nil, err
+	}
+
+	var d Draft
+	if err := json.Unmarshal(plain, &d); err != nil {
+		return nil, err
+	}
+	if d.Path == "" || d.Body == "" {
+		return nil, nil
+	}
+	return &d, nil
+}
+
+func (s Store) SaveDraft(d Draft) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+
+	plain, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+	b, err := s.encode(plain)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.draftPath(), b)
+}
+
+func (s Store) ClearDraft() error {
+	err := os.Remove(s.draftPath())
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s Store) stashedDraftsPath() string {
+	return filepath.Join(filepath.Dir(s.path), "stashed-drafts.json")
+}
+
+// LoadStashedDrafts returns the drafts left on lines whose comment dock was
+// dismissed, or nil when there are none.
+func (s Store) LoadStashedDrafts() ([]Draft, error) {
+	b, err := os.ReadFile(s.stashedDraftsPath())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	plain, err := s.decode(b)
+	if err != nil {
+		return nil, err
+	}
+
+	var drafts []Draft
+	if err := json.Unmarshal(plain, &drafts); err != nil {
+		return nil, err
+	}
+	return drafts, nil
+}
+
+// SaveStashedDrafts replaces the stashed drafts, removing the file when
+// there are none left.
+func (s Store) SaveStashedDrafts(drafts []Draft) error {
+	if len(drafts) == 0 {
+		err := os.Remove(s.stashedDraftsPath())
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+
+	plain, err := json.MarshalIndent(drafts, "", "  ")
+	if err != nil {
+		return err
+	}
+	b, err := s.encode(plain)
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(s.path, b, 0o644)
+	return writeFileAtomic(s.stashedDraftsPath(), b)
 }
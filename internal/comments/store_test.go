@@ -0,0 +1,135 @@
+package comments
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSaveKeepsPreviousFileAsBackup(t *testing.T) {
+	store := NewStore(t.TempDir())
+	first := []Comment{{Path: "a.go", Side: SideNew, Line: 1, Body: "first"}}
+	second := []Comment{{Path: "a.go", Side: SideNew, Line: 2, Body: "second"}}
+	if err := store.Save(first); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if _, err := os.Stat(store.backupPath()); !os.IsNotExist(err) {
+		t.Fatalf("expected no backup before a second save, got %v", err)
+	}
+	if err := store.Save(second); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	backup, err := os.ReadFile(store.backupPath())
+	if err != nil || !strings.Contains(string(backup), "first") {
+		t.Fatalf("expected the first save in the backup, got %q (%v)", backup, err)
+	}
+	got, err := store.Load()
+	if err != nil || len(got) != 1 || got[0].Body != "second" {
+		t.Fatalf("Load() = %+v, %v", got, err)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(store.path))
+	for _, e := range entries {
+		if strings.Contains(e.Name(), ".tmp-") {
+			t.Fatalf("temporary file left behind: %s", e.Name())
+		}
+	}
+}
+
+func TestLoadRecoversTruncatedFileFromBackup(t *testing.T) {
+	store := NewStore(t.TempDir())
+	if err := store.Save([]Comment{{Path: "a.go", Side: SideNew, Line: 1, Body: "kept"}}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := store.Save([]Comment{{Path: "a.go", Side: SideNew, Line: 1, Body: "kept"}, {Path: "b.go", Side: SideOld, Line: 4, Body: "newer"}}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	b, _ := os.ReadFile(store.path)
+	if err := os.WriteFile(store.path, b[:len(b)/2], 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	got, err := store.Load()
+	if !IsRecovered(err) {
+		t.Fatalf("expected a RecoveredError, got %v", err)
+	}
+	if len(got) != 1 || got[0].Body != "kept" {
+		t.Fatalf("expected the backup's comments, got %+v", got)
+	}
+	rec := err.(*RecoveredError)
+	if damaged, readErr := os.ReadFile(rec.Corrupt); readErr != nil || string(damaged) != string(b[:len(b)/2]) {
+		t.Fatalf("expected the damaged file kept at %s (%v)", rec.Corrupt, readErr)
+	}
+
+	// The backup was restored in place, so the next load is clean.
+	again, err := store.Load()
+	if err != nil || len(again) != 1 {
+		t.Fatalf("Load() after recovery = %+v, %v", again, err)
+	}
+}
+
+func TestSaveDoesNotOverwriteUnreadableFile(t *testing.T) {
+	store := NewStore(t.TempDir())
+	if err := os.MkdirAll(filepath.Dir(store.path), 0o755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	if err := os.WriteFile(store.path, []byte(`[{"path": "a.go", "bo`), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if _, err := store.Load(); err == nil || IsRecovered(err) {
+		t.Fatalf("expected a plain load error without a backup, got %v", err)
+	}
+
+	if err := store.Save([]Comment{{Path: "b.go", Side: SideNew, Line: 1, Body: "new"}}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	matches, _ := filepath.Glob(store.path + ".corrupt-*")
+	if len(matches) != 1 {
+		t.Fatalf("expected the unreadable file kept aside, got %v", matches)
+	}
+	if _, err := os.Stat(store.backupPath()); !os.IsNotExist(err) {
+		t.Fatalf("expected no backup made from an unreadable file")
+	}
+}
+
+func TestLoadAcceptsNullAndMissingFiles(t *testing.T) {
+	store := NewStore(t.TempDir())
+	if got, err := store.Load(); err != nil || got == nil || len(got) != 0 {
+		t.Fatalf("missing file: %+v, %v", got, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(store.path), 0o755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	if err := os.WriteFile(store.path, []byte("null"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if got, err := store.Load(); err != nil || len(got) != 0 {
+		t.Fatalf("null file: %+v, %v", got, err)
+	}
+}
+
+func TestStampChangesWithEverySave(t *testing.T) {
+	store := NewStore(t.TempDir())
+	missing, err := store.Stamp()
+	if err != nil || !missing.Equal(Stamp{}) {
+		t.Fatalf("expected the zero stamp for a missing file, got %v", err)
+	}
+	list := []Comment{{Path: "a.go", Side: SideNew, Line: 1, Body: "same"}}
+	if err := store.Save(list); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	first, _ := store.Stamp()
+	if first.Equal(missing) {
+		t.Fatalf("expected a saved file to differ from a missing one")
+	}
+	if again, _ := store.Stamp(); !again.Equal(first) {
+		t.Fatalf("expected an unchanged file to keep its stamp")
+	}
+	if err := store.Save(list); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if second, _ := store.Stamp(); second.Equal(first) {
+		t.Fatalf("expected a rewrite with identical content to change the stamp")
+	}
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -11,11 +12,109 @@ import (
 const (
 	configDirName  = "diffman"
 	configFileName = "config.json"
+	keyFileName    = "comments.key"
+
+	// maxContextLines caps how many lines a comment captures on each side of its anchor.
+	maxContextLines = 20
+)
+
+// DefaultContextLines is how many lines a comment captures on each side of its anchor.
+const DefaultContextLines = 1
+
+// DefaultMaxLineColumns is the column past which the diff truncates a line
+// until it is expanded.
+const DefaultMaxLineColumns = 1000
+
+// DefaultFollowUpDays is how old an open follow-up comment gets before
+// startup and diffman check point it out.
+const DefaultFollowUpDays = 7
+
+// Events a hook command can be configured for.
+const (
+	HookCommentCreate = "comment_create"
+	HookCommentEdit   = "comment_edit"
+	HookCommentDelete = "comment_delete"
+	HookExport        = "export"
 )
 
 type AppConfig struct {
 	LeaderCommands map[string]string `json:"leader_commands"`
 	Theme          string            `json:"theme,omitempty"`
+	Palette        string            `json:"palette,omitempty"`
+	DiffLayout     string            `json:"diff_layout,omitempty"`
+	LineNumbers    string            `json:"line_numbers,omitempty"`
+	WordWrap       bool              `json:"word_wrap,omitempty"`
+	InlineComments string            `json:"inline_comments,omitempty"`
+	Plain          bool              `json:"plain,omitempty"`
+	ContextLines   int               `json:"context_lines"`
+	Spellcheck     bool              `json:"spellcheck"`
+	Dictionary     string            `json:"dictionary,omitempty"`
+	Permalinks     bool              `json:"permalinks"`
+	// PermalinkTemplates maps a remote host to a URL template with {host},
+	// {repo}, {commit}, {path}, and {line} placeholders.
+	PermalinkTemplates map[string]string `json:"permalink_templates,omitempty"`
+	// Hooks maps an event to a shell command that receives the event as JSON on stdin.
+	Hooks map[string]string `json:"hooks,omitempty"`
+	// WebhookURL receives published exports as a Slack-compatible {"text": ...} POST.
+	WebhookURL string `json:"webhook_url,omitempty"`
+	// CleanupAfterCommit offers to archive and clear comments once none of them match the diff.
+	CleanupAfterCommit bool `json:"cleanup_after_commit,omitempty"`
+	// ExportReviewStats appends the review time and per-file statistics to plain exports.
+	ExportReviewStats bool `json:"export_review_stats,omitempty"`
+	// Checklist lists questions to tick off during a review, such as "Tests added?".
+	Checklist []string `json:"checklist,omitempty"`
+	// RedactExports leaves the code context out of exports, keeping each
+	// comment's location and body.
+	RedactExports bool `json:"redact_exports,omitempty"`
+	// ExportGroup lists exported comments under a heading per "file" or per
+	// "severity", or in the order they were written ("time"). Empty keeps
+	// the file order without headings.
+	ExportGroup string `json:"export_group,omitempty"`
+	// ExportContext keeps the code block under each exported comment.
+	ExportContext bool `json:"export_context"`
+	// ExportTitle replaces "Review comments:" at the top of plain exports,
+	// and ExportFooter ends them, e.g. with a sign-off. Both may use {name}
+	// (git's user.name), {date} and {branch}.
+	ExportTitle  string `json:"export_title,omitempty"`
+	ExportFooter string `json:"export_footer,omitempty"`
+	// ExportOnQuit exports the non-stale comments when diffman exits: to the
+	// clipboard ("clipboard") or to the export file ("file").
+	ExportOnQuit string `json:"export_on_quit,omitempty"`
+	// HideEOLChanges shows lines whose only change is the line ending
+	// (CRLF/LF) or a byte order mark as unchanged.
+	HideEOLChanges bool `json:"hide_eol_changes,omitempty"`
+	// SkipWhitespaceHunks makes ] and [ pass over hunks that only change
+	// whitespace or line endings, and marks such files in the file tree.
+	SkipWhitespaceHunks bool `json:"skip_whitespace_hunks,omitempty"`
+	// FileIcons shows a Nerd Font icon for each file's type in the file
+	// tree instead of its ASCII marker. Plain mode keeps the ASCII marker.
+	FileIcons bool `json:"file_icons,omitempty"`
+	// EncryptComments encrypts the saved comments and draft with the key in
+	// KeyPath or $DIFFMAN_COMMENTS_KEY.
+	EncryptComments bool `json:"encrypt_comments,omitempty"`
+	// StartAt is where a review opens: the first changed file ("files"),
+	// the first file with comments ("comment"), the first stale comment
+	// ("stale"), or the last position of the previous run ("resume").
+	StartAt string `json:"start_at,omitempty"`
+	// DiffTool is a shell command that opens the selected file in an
+	// external diff tool, with $OLD and $NEW naming the two versions.
+	DiffTool string `json:"difftool,omitempty"`
+	// Exclude lists path patterns, relative to the repository root, that
+	// git leaves out of status and diffs, such as "dist/**".
+	Exclude []string `json:"exclude,omitempty"`
+	// MaxLineColumns truncates diff lines longer than this many columns,
+	// such as minified code, until they are expanded. Zero never truncates.
+	MaxLineColumns int `json:"max_line_columns"`
+	// FollowUpDays is how many days after it was written an open
+	// "follow-up:" comment is reported at startup and by diffman check.
+	// Zero reports every open follow-up.
+	FollowUpDays int `json:"follow_up_days"`
+	// RefreshSeconds reloads the file list and stale comments this often in
+	// the background. Zero only refreshes on request.
+	RefreshSeconds int `json:"refresh_seconds,omitempty"`
+	// TmuxMessage shows a tmux message when an export is copied inside
+	// tmux, where exports also go to a tmux paste buffer.
+	TmuxMessage bool `json:"tmux_message,omitempty"`
 }
 
 func Load() (AppConfig, string, error) {
@@ -27,11 +126,28 @@ func Load() (AppConfig, string, error) {
 	return cfg, path, err
 }
 
-func LoadFromPath(path string) (AppConfig, error) {
-	cfg := AppConfig{
+// Default returns the configuration used when no config file exists. Callers
+// that cannot load the config file fall back to it.
+func Default() AppConfig {
+	return AppConfig{
 		LeaderCommands: make(map[string]string),
 		Theme:          "auto",
+		Palette:        "default",
+		DiffLayout:     "side-by-side",
+		LineNumbers:    "absolute",
+		InlineComments: "side",
+		StartAt:        "files",
+		ContextLines:   DefaultContextLines,
+		Spellcheck:     true,
+		Permalinks:     true,
+		ExportContext:  true,
+		MaxLineColumns: DefaultMaxLineColumns,
+		FollowUpDays:   DefaultFollowUpDays,
 	}
+}
+
+func LoadFromPath(path string) (AppConfig, error) {
+	cfg := Default()
 
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -59,6 +175,111 @@ func LoadFromPath(path string) (AppConfig, error) {
 	}
 	cfg.Theme = theme
 
+	palette, err := normalizePalette(cfg.Palette)
+	if err != nil {
+		return AppConfig{}, err
+	}
+	cfg.Palette = palette
+
+	layout, err := normalizeDiffLayout(cfg.DiffLayout)
+	if err != nil {
+		return AppConfig{}, err
+	}
+	cfg.DiffLayout = layout
+
+	numbers, err := normalizeLineNumbers(cfg.LineNumbers)
+	if err != nil {
+		return AppConfig{}, err
+	}
+	cfg.LineNumbers = numbers
+
+	inline, err := normalizeInlineComments(cfg.InlineComments)
+	if err != nil {
+		return AppConfig{}, err
+	}
+	cfg.InlineComments = inline
+
+	startAt, err := NormalizeStartAt(cfg.StartAt)
+	if err != nil {
+		return AppConfig{}, err
+	}
+	cfg.StartAt = startAt
+	group, err := NormalizeExportGroup(cfg.ExportGroup)
+	if err != nil {
+		return AppConfig{}, err
+	}
+	cfg.ExportGroup = group
+	onQuit, err := NormalizeExportOnQuit(cfg.ExportOnQuit)
+	if err != nil {
+		return AppConfig{}, err
+	}
+	cfg.ExportOnQuit = onQuit
+	cfg.DiffTool = strings.TrimSpace(cfg.DiffTool)
+	cfg.Exclude = normalizeExclude(cfg.Exclude)
+
+	if cfg.ContextLines < 0 || cfg.ContextLines > maxContextLines {
+		return AppConfig{}, fmt.Errorf("context_lines %d must be between 0 and %d", cfg.ContextLines, maxContextLines)
+	}
+	if cfg.MaxLineColumns < 0 {
+		return AppConfig{}, fmt.Errorf("max_line_columns %d must not be negative", cfg.MaxLineColumns)
+	}
+	if cfg.FollowUpDays < 0 {
+		return AppConfig{}, fmt.Errorf("follow_up_days %d must not be negative", cfg.FollowUpDays)
+	}
+	if cfg.RefreshSeconds < 0 {
+		return AppConfig{}, fmt.Errorf("refresh_seconds %d must not be negative", cfg.RefreshSeconds)
+	}
+
+	cfg.Dictionary = strings.TrimSpace(cfg.Dictionary)
+
+	templates := make(map[string]string, len(cfg.PermalinkTemplates))
+	for host, tmpl := range cfg.PermalinkTemplates {
+		host = strings.ToLower(strings.TrimSpace(host))
+		tmpl = strings.TrimSpace(tmpl)
+		if host == "" {
+			return AppConfig{}, fmt.Errorf("permalink template host is empty")
+		}
+		if tmpl != "" && !strings.Contains(tmpl, "{path}") {
+			return AppConfig{}, fmt.Errorf("permalink template for %q must contain {path}", host)
+		}
+		templates[host] = tmpl
+	}
+	cfg.PermalinkTemplates = templates
+
+	hooks := make(map[string]string, len(cfg.Hooks))
+	for event, cmd := range cfg.Hooks {
+		event = strings.TrimSpace(event)
+		switch event {
+		case HookCommentCreate, HookCommentEdit, HookCommentDelete, HookExport:
+		default:
+			return AppConfig{}, fmt.Errorf("unknown hook event %q", event)
+		}
+		if cmd = strings.TrimSpace(cmd); cmd != "" {
+			hooks[event] = cmd
+		}
+	}
+	cfg.Hooks = hooks
+
+	cfg.WebhookURL = strings.TrimSpace(cfg.WebhookURL)
+	if cfg.WebhookURL != "" {
+		u, err := url.Parse(cfg.WebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return AppConfig{}, fmt.Errorf("webhook_url must be an http(s) URL")
+		}
+	}
+
+	checklist := make([]string, 0, len(cfg.Checklist))
+	seen := make(map[string]bool, len(cfg.Checklist))
+	for _, item := range cfg.Checklist {
+		item = strings.TrimSpace(item)
+		if item == "" || seen[item] {
+			continue
+		}
+		seen[item] = true
+		checklist = append(checklist, item)
+	}
+	cfg.Checklist = checklist
+
 	normalized := make(map[string]string, len(cfg.LeaderCommands))
 	for k, v := range cfg.LeaderCommands {
 		key := strings.TrimSpace(k)
@@ -79,6 +300,21 @@ func LoadFromPath(path string) (AppConfig, error) {
 	return cfg, nil
 }
 
+// normalizeExclude trims the patterns and drops empty and repeated ones.
+func normalizeExclude(raw []string) []string {
+	var out []string
+	seen := make(map[string]bool, len(raw))
+	for _, p := range raw {
+		p = strings.TrimSpace(p)
+		if p == "" || seen[p] {
+			continue
+		}
+		seen[p] = true
+		out = append(out, p)
+	}
+	return out
+}
+
 func normalizeTheme(raw string) (string, error) {
 	theme := strings.ToLower(strings.TrimSpace(raw))
 	if theme == "" {
@@ -92,6 +328,103 @@ func normalizeTheme(raw string) (string, error) {
 	}
 }
 
+func normalizePalette(raw string) (string, error) {
+	palette := strings.ToLower(strings.TrimSpace(raw))
+	if palette == "" {
+		return "default", nil
+	}
+	switch palette {
+	case "default", "deuteranopia", "protanopia":
+		return palette, nil
+	default:
+		return "", fmt.Errorf("palette %q must be one of default, deuteranopia, protanopia", raw)
+	}
+}
+
+func normalizeDiffLayout(raw string) (string, error) {
+	layout := strings.ToLower(strings.TrimSpace(raw))
+	if layout == "" {
+		return "side-by-side", nil
+	}
+	switch layout {
+	case "side-by-side", "stacked", "auto":
+		return layout, nil
+	default:
+		return "", fmt.Errorf("diff_layout %q must be one of side-by-side, stacked, auto", raw)
+	}
+}
+
+func normalizeLineNumbers(raw string) (string, error) {
+	mode := strings.ToLower(strings.TrimSpace(raw))
+	if mode == "" {
+		return "absolute", nil
+	}
+	switch mode {
+	case "absolute", "relative", "hidden", "both":
+		return mode, nil
+	default:
+		return "", fmt.Errorf("line_numbers %q must be one of absolute, relative, hidden, both", raw)
+	}
+}
+
+func normalizeInlineComments(raw string) (string, error) {
+	mode := strings.ToLower(strings.TrimSpace(raw))
+	if mode == "" {
+		return "side", nil
+	}
+	switch mode {
+	case "side", "across", "collapsed":
+		return mode, nil
+	default:
+		return "", fmt.Errorf("inline_comments %q must be one of side, across, collapsed", raw)
+	}
+}
+
+// NormalizeStartAt checks a start_at value, from the config or the -start
+// flag, and returns it in canonical form.
+func NormalizeStartAt(raw string) (string, error) {
+	start := strings.ToLower(strings.TrimSpace(raw))
+	if start == "" {
+		return "files", nil
+	}
+	switch start {
+	case "files", "comment", "stale", "resume":
+		return start, nil
+	default:
+		return "", fmt.Errorf("start_at %q must be one of files, comment, stale, resume", raw)
+	}
+}
+
+// NormalizeExportGroup checks an export_group value, from the config or the
+// -group flag, and returns it in canonical form.
+func NormalizeExportGroup(raw string) (string, error) {
+	group := strings.ToLower(strings.TrimSpace(raw))
+	switch group {
+	case "", "file", "severity", "time":
+		return group, nil
+	default:
+		return "", fmt.Errorf("export_group %q must be one of file, severity, time", raw)
+	}
+}
+
+// export_on_quit values; an empty value exports nothing on quit.
+const (
+	ExportOnQuitClipboard = "clipboard"
+	ExportOnQuitFile      = "file"
+)
+
+// NormalizeExportOnQuit checks an export_on_quit value and returns it in
+// canonical form.
+func NormalizeExportOnQuit(raw string) (string, error) {
+	onQuit := strings.ToLower(strings.TrimSpace(raw))
+	switch onQuit {
+	case "", ExportOnQuitClipboard, ExportOnQuitFile:
+		return onQuit, nil
+	default:
+		return "", fmt.Errorf("export_on_quit %q must be one of clipboard, file", raw)
+	}
+}
+
 func DefaultPath() (string, error) {
 	home, err := configHome()
 	if err != nil {
@@ -100,6 +433,15 @@ func DefaultPath() (string, error) {
 	return filepath.Join(home, configDirName, configFileName), nil
 }
 
+// KeyPath is where the key for encrypted comments is kept.
+func KeyPath() (string, error) {
+	home, err := configHome()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, configDirName, keyFileName), nil
+}
+
 func configHome() (string, error) {
 	if xdg := strings.TrimSpace(os.Getenv("XDG_CONFIG_HOME")); xdg != "" {
 		return xdg, nil
@@ -79,6 +79,181 @@ func TestLoadFromPathRejectsInvalidTheme(t *testing.T) {
 	}
 }
 
+func TestLoadFromPathParsesDiffLayout(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"diff_layout":"Stacked"}`), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	cfg, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatalf("LoadFromPath() error = %v", err)
+	}
+	if cfg.DiffLayout != "stacked" {
+		t.Fatalf("expected stacked layout, got %q", cfg.DiffLayout)
+	}
+}
+
+func TestLoadFromPathRejectsInvalidDiffLayout(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"diff_layout":"diagonal"}`), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	if _, err := LoadFromPath(path); err == nil {
+		t.Fatalf("expected error for invalid diff layout")
+	}
+}
+
+func TestLoadFromPathParsesPalette(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"palette":"Deuteranopia"}`), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	cfg, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatalf("LoadFromPath() error = %v", err)
+	}
+	if cfg.Palette != "deuteranopia" {
+		t.Fatalf("expected deuteranopia palette, got %q", cfg.Palette)
+	}
+}
+
+func TestLoadFromPathRejectsInvalidPalette(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"palette":"rainbow"}`), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	if _, err := LoadFromPath(path); err == nil {
+		t.Fatalf("expected error for invalid palette")
+	}
+}
+
+func TestLoadFromPathParsesLineNumbers(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"line_numbers":" Relative "}`), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	cfg, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatalf("LoadFromPath() error = %v", err)
+	}
+	if cfg.LineNumbers != "relative" {
+		t.Fatalf("expected relative line numbers, got %q", cfg.LineNumbers)
+	}
+}
+
+func TestLoadFromPathParsesPlain(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"plain":true}`), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	cfg, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatalf("LoadFromPath() error = %v", err)
+	}
+	if !cfg.Plain {
+		t.Fatalf("expected plain mode to be enabled")
+	}
+}
+
+func TestLoadFromPathParsesContextLines(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"context_lines":3}`), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	cfg, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatalf("LoadFromPath() error = %v", err)
+	}
+	if cfg.ContextLines != 3 {
+		t.Fatalf("expected 3 context lines, got %d", cfg.ContextLines)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"context_lines":-1}`), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if _, err := LoadFromPath(path); err == nil {
+		t.Fatalf("expected error for negative context_lines")
+	}
+}
+
+func TestLoadFromPathParsesMaxLineColumns(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{}`), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	cfg, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatalf("LoadFromPath() error = %v", err)
+	}
+	if cfg.MaxLineColumns != DefaultMaxLineColumns {
+		t.Fatalf("expected default %d columns, got %d", DefaultMaxLineColumns, cfg.MaxLineColumns)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"max_line_columns":0}`), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	cfg, err = LoadFromPath(path)
+	if err != nil {
+		t.Fatalf("LoadFromPath() error = %v", err)
+	}
+	if cfg.MaxLineColumns != 0 {
+		t.Fatalf("expected truncation to be disabled, got %d", cfg.MaxLineColumns)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"max_line_columns":-5}`), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if _, err := LoadFromPath(path); err == nil {
+		t.Fatalf("expected error for negative max_line_columns")
+	}
+}
+
+func TestLoadFromPathParsesSpellcheck(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{}`), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	cfg, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatalf("LoadFromPath() error = %v", err)
+	}
+	if !cfg.Spellcheck {
+		t.Fatalf("expected spellcheck on by default")
+	}
+
+	if err := os.WriteFile(path, []byte(`{"spellcheck":false,"dictionary":" /tmp/words "}`), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	cfg, err = LoadFromPath(path)
+	if err != nil {
+		t.Fatalf("LoadFromPath() error = %v", err)
+	}
+	if cfg.Spellcheck {
+		t.Fatalf("expected spellcheck off")
+	}
+	if cfg.Dictionary != "/tmp/words" {
+		t.Fatalf("expected trimmed dictionary path, got %q", cfg.Dictionary)
+	}
+}
+
 func TestDefaultPathUsesXDGConfigHome(t *testing.T) {
 	xdg := t.TempDir()
 	t.Setenv("XDG_CONFIG_HOME", xdg)
@@ -93,3 +268,311 @@ func TestDefaultPathUsesXDGConfigHome(t *testing.T) {
 		t.Fatalf("DefaultPath()=%q want %q", got, want)
 	}
 }
+
+func TestLoadFromPathParsesPermalinkTemplates(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	data := `{"permalinks":false,"permalink_templates":{" Git.Example.com ":"https://{host}/{repo}/src/{commit}/{path}#L{line}"}}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	cfg, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatalf("LoadFromPath() error = %v", err)
+	}
+	if cfg.Permalinks {
+		t.Fatalf("expected permalinks off")
+	}
+	if _, ok := cfg.PermalinkTemplates["git.example.com"]; !ok {
+		t.Fatalf("expected normalized host key, got %v", cfg.PermalinkTemplates)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"permalink_templates":{"h":"https://{host}/x"}}`), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if _, err := LoadFromPath(path); err == nil {
+		t.Fatalf("expected error for template without {path}")
+	}
+}
+
+func TestLoadFromPathParsesHooks(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"hooks":{"export":" ./post.sh ","comment_edit":""}}`), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	cfg, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatalf("LoadFromPath() error = %v", err)
+	}
+	if cfg.Hooks[HookExport] != "./post.sh" {
+		t.Fatalf("expected trimmed export hook, got %q", cfg.Hooks[HookExport])
+	}
+	if _, ok := cfg.Hooks[HookCommentEdit]; ok {
+		t.Fatalf("expected empty hook dropped")
+	}
+
+	if err := os.WriteFile(path, []byte(`{"hooks":{"comment_posted":"x"}}`), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if _, err := LoadFromPath(path); err == nil {
+		t.Fatalf("expected error for unknown hook event")
+	}
+}
+
+func TestLoadFromPathValidatesWebhookURL(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"webhook_url":" https://hooks.slack.com/services/T/B/X "}`), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	cfg, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatalf("LoadFromPath() error = %v", err)
+	}
+	if cfg.WebhookURL != "https://hooks.slack.com/services/T/B/X" {
+		t.Fatalf("expected trimmed webhook URL, got %q", cfg.WebhookURL)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"webhook_url":"hooks.slack.com/x"}`), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if _, err := LoadFromPath(path); err == nil {
+		t.Fatalf("expected error for webhook URL without scheme")
+	}
+}
+
+func TestLoadFromPathParsesCleanupAfterCommit(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"cleanup_after_commit":true}`), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	cfg, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatalf("LoadFromPath() error = %v", err)
+	}
+	if !cfg.CleanupAfterCommit {
+		t.Fatalf("expected cleanup_after_commit enabled")
+	}
+}
+
+func TestLoadFromPathParsesExportReviewStats(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"export_review_stats":true}`), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	cfg, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatalf("LoadFromPath() error = %v", err)
+	}
+	if !cfg.ExportReviewStats {
+		t.Fatalf("expected export_review_stats enabled")
+	}
+}
+
+func TestLoadFromPathNormalizesChecklist(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"checklist":[" Tests added? ","","Docs updated?","Tests added?"]}`), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	cfg, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatalf("LoadFromPath() error = %v", err)
+	}
+	if len(cfg.Checklist) != 2 || cfg.Checklist[0] != "Tests added?" || cfg.Checklist[1] != "Docs updated?" {
+		t.Fatalf("unexpected checklist %q", cfg.Checklist)
+	}
+}
+
+func TestLoadFromPathParsesRedactionAndEncryption(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"redact_exports":true,"encrypt_comments":true}`), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	cfg, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatalf("LoadFromPath() error = %v", err)
+	}
+	if !cfg.RedactExports || !cfg.EncryptComments {
+		t.Fatalf("expected redact_exports and encrypt_comments enabled, got %+v", cfg)
+	}
+}
+
+func TestLoadFromPathParsesWordWrap(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"word_wrap":true}`), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	cfg, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatalf("LoadFromPath() error = %v", err)
+	}
+	if !cfg.WordWrap {
+		t.Fatalf("expected word_wrap enabled")
+	}
+}
+
+func TestLoadFromPathParsesInlineComments(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"inline_comments":" Collapsed "}`), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	cfg, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatalf("LoadFromPath() error = %v", err)
+	}
+	if cfg.InlineComments != "collapsed" {
+		t.Fatalf("expected collapsed inline comments, got %q", cfg.InlineComments)
+	}
+}
+
+func TestLoadFromPathRejectsInvalidInlineComments(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"inline_comments":"sideways"}`), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	if _, err := LoadFromPath(path); err == nil {
+		t.Fatalf("expected error for invalid inline_comments")
+	}
+}
+
+func TestLoadFromPathParsesStartAt(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"start_at":"Resume"}`), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	cfg, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatalf("LoadFromPath() error = %v", err)
+	}
+	if cfg.StartAt != "resume" {
+		t.Fatalf("expected resume, got %q", cfg.StartAt)
+	}
+	if _, err := NormalizeStartAt("middle"); err == nil {
+		t.Fatalf("expected error for invalid start_at")
+	}
+}
+
+func TestLoadFromPathNormalizesExclude(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"exclude":[" dist/** ","","*.min.js","dist/**"]}`), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	cfg, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatalf("LoadFromPath() error = %v", err)
+	}
+	if len(cfg.Exclude) != 2 || cfg.Exclude[0] != "dist/**" || cfg.Exclude[1] != "*.min.js" {
+		t.Fatalf("Exclude = %q", cfg.Exclude)
+	}
+}
+
+func TestLoadFromPathParsesRefreshSeconds(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"refresh_seconds":10}`), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	cfg, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatalf("LoadFromPath() error = %v", err)
+	}
+	if cfg.RefreshSeconds != 10 {
+		t.Fatalf("expected 10 seconds, got %d", cfg.RefreshSeconds)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"refresh_seconds":-1}`), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if _, err := LoadFromPath(path); err == nil {
+		t.Fatalf("expected error for negative refresh_seconds")
+	}
+}
+
+func TestLoadFromPathParsesExportGrouping(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{}`), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	cfg, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatalf("LoadFromPath() error = %v", err)
+	}
+	if cfg.ExportGroup != "" || !cfg.ExportContext {
+		t.Fatalf("expected ungrouped exports with context by default, got %q %v", cfg.ExportGroup, cfg.ExportContext)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"export_group":"Severity","export_context":false}`), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	cfg, err = LoadFromPath(path)
+	if err != nil {
+		t.Fatalf("LoadFromPath() error = %v", err)
+	}
+	if cfg.ExportGroup != "severity" || cfg.ExportContext {
+		t.Fatalf("expected severity grouping without context, got %q %v", cfg.ExportGroup, cfg.ExportContext)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"export_group":"author"}`), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if _, err := LoadFromPath(path); err == nil {
+		t.Fatalf("expected an unknown export_group to be rejected")
+	}
+}
+
+func TestLoadExportOnQuit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"export_on_quit":" File "}`), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	cfg, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatalf("LoadFromPath() error = %v", err)
+	}
+	if cfg.ExportOnQuit != ExportOnQuitFile {
+		t.Fatalf("expected export_on_quit normalized to %q, got %q", ExportOnQuitFile, cfg.ExportOnQuit)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"export_on_quit":"email"}`), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if _, err := LoadFromPath(path); err == nil {
+		t.Fatalf("expected an unknown export_on_quit to be rejected")
+	}
+}
+
+func TestDefaultMatchesMissingFile(t *testing.T) {
+	cfg, err := LoadFromPath(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatalf("LoadFromPath() error = %v", err)
+	}
+	def := Default()
+	if def.FollowUpDays != DefaultFollowUpDays || def.MaxLineColumns != DefaultMaxLineColumns || !def.Permalinks || !def.ExportContext {
+		t.Fatalf("unexpected defaults %+v", def)
+	}
+	if def.FollowUpDays != cfg.FollowUpDays || def.ContextLines != cfg.ContextLines || def.Theme != cfg.Theme || def.StartAt != cfg.StartAt {
+		t.Fatalf("Default() %+v differs from a missing config file %+v", def, cfg)
+	}
+}
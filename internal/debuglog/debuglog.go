@@ -0,0 +1,104 @@
+// Package debuglog writes diagnostics (git invocations, parse and render
+// timings, errors) to a file when enabled with -log-file or DIFFMAN_DEBUG.
+// Until Open is called every function is a cheap no-op.
+package debuglog
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// EnvVar enables logging: "1" or "true" log to DefaultPath, any other
+// non-empty value is the log file path.
+const EnvVar = "DIFFMAN_DEBUG"
+
+var (
+	logger atomic.Pointer[log.Logger]
+	file   *os.File
+)
+
+// DefaultPath is $XDG_STATE_HOME/diffman/debug.log, falling back to
+// $HOME/.local/state/diffman/debug.log.
+func DefaultPath() (string, error) {
+	if xdg := strings.TrimSpace(os.Getenv("XDG_STATE_HOME")); xdg != "" {
+		return filepath.Join(xdg, "diffman", "debug.log"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "state", "diffman", "debug.log"), nil
+}
+
+// PathFromEnv returns the log file DIFFMAN_DEBUG asks for, or "" when it is
+// unset or turned off.
+func PathFromEnv() (string, error) {
+	value := strings.TrimSpace(os.Getenv(EnvVar))
+	switch strings.ToLower(value) {
+	case "", "0", "false", "off":
+		return "", nil
+	case "1", "true", "on":
+		return DefaultPath()
+	}
+	return value, nil
+}
+
+// Open starts appending to the log file at path, creating it and its
+// directory if needed. It is meant to be called once, at startup.
+func Open(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	file = f
+	l := log.New(f, "", log.LstdFlags|log.Lmicroseconds)
+	l.Printf("diffman %d started in %s", os.Getpid(), workingDir())
+	logger.Store(l)
+	return nil
+}
+
+// Close stops logging and closes the file.
+func Close() error {
+	if logger.Swap(nil) == nil || file == nil {
+		return nil
+	}
+	err := file.Close()
+	file = nil
+	return err
+}
+
+// Enabled reports whether log lines are written, so callers can skip
+// building expensive messages.
+func Enabled() bool {
+	return logger.Load() != nil
+}
+
+// Printf writes one log line when logging is enabled.
+func Printf(format string, args ...any) {
+	if l := logger.Load(); l != nil {
+		l.Output(2, fmt.Sprintf(format, args...))
+	}
+}
+
+// Timed logs what took since start, e.g. defer debuglog.Timed(time.Now(), "parse %s", path).
+func Timed(start time.Time, format string, args ...any) {
+	if l := logger.Load(); l != nil {
+		l.Output(2, fmt.Sprintf(format, args...)+" took "+time.Since(start).Round(time.Microsecond).String())
+	}
+}
+
+func workingDir() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "?"
+	}
+	return dir
+}
@@ -0,0 +1,71 @@
+package debuglog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrintfIsNoOpUntilOpened(t *testing.T) {
+	if Enabled() {
+		t.Fatalf("expected logging to start disabled")
+	}
+	Printf("dropped %d", 1)
+	Timed(time.Now(), "dropped")
+}
+
+func TestOpenWritesLinesUntilClosed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "debug.log")
+	if err := Open(path); err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if !Enabled() {
+		t.Fatalf("expected logging enabled after Open")
+	}
+	Printf("exec git %s", "status")
+	Timed(time.Now(), "parse diff: %d rows", 3)
+	if err := Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	Printf("after close")
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	text := string(b)
+	for _, want := range []string{"started in", "exec git status", "parse diff: 3 rows took "} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("expected %q in log, got:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "after close") {
+		t.Fatalf("expected nothing logged after Close, got:\n%s", text)
+	}
+}
+
+func TestPathFromEnv(t *testing.T) {
+	state := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", state)
+	cases := map[string]string{
+		"":              "",
+		"0":             "",
+		"off":           "",
+		"1":             filepath.Join(state, "diffman", "debug.log"),
+		"true":          filepath.Join(state, "diffman", "debug.log"),
+		"/tmp/dm.log":   "/tmp/dm.log",
+		" ./debug.log ": "./debug.log",
+	}
+	for value, want := range cases {
+		t.Setenv(EnvVar, value)
+		got, err := PathFromEnv()
+		if err != nil {
+			t.Fatalf("PathFromEnv(%q) error = %v", value, err)
+		}
+		if got != want {
+			t.Fatalf("PathFromEnv(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package diffview
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// CommentPlacement selects how inline comments are drawn under their rows.
+type CommentPlacement int
+
+const (
+	// CommentsBelowSide draws a comment under its line in the anchored pane only.
+	CommentsBelowSide CommentPlacement = iota
+	// CommentsAcross wraps a comment over the width of both panes, reading
+	// from the old pane into the new one, so it takes about half the rows.
+	// Rows with comments on both sides fall back to CommentsBelowSide.
+	CommentsAcross
+	// CommentsCollapsed draws a one-line marker with the comment count and
+	// the start of the first comment until the row is expanded.
+	CommentsCollapsed
+)
+
+// inlineCommentSegments renders the comment lines drawn under row in each pane.
+func inlineCommentSegments(row DiffRow, idx int, numbers lineNumbers, oldWidth, newWidth int, commentText func(path string, line int, side Side) (string, bool), opts RenderOptions) ([]string, []string) {
+	oldBody, oldHas := commentTextForSide(row, SideOld, commentText)
+	newBody, newHas := commentTextForSide(row, SideNew, commentText)
+	if !oldHas && !newHas {
+		return nil, nil
+	}
+	oldIndent := commentTextIndent(row, SideOld, numbers, idx)
+	newIndent := commentTextIndent(row, SideNew, numbers, idx)
+
+	placement := opts.CommentPlacement
+	if placement == CommentsCollapsed && opts.expanded(row, oldHas, newHas) {
+		placement = CommentsBelowSide
+	}
+	switch {
+	case placement == CommentsCollapsed:
+		if !newHas {
+			return []string{renderCommentMarker(1, oldBody, oldWidth, oldIndent)}, nil
+		}
+		count := 1
+		if oldHas {
+			count = 2
+		}
+		return nil, []string{renderCommentMarker(count, newBody, newWidth, newIndent)}
+	case placement == CommentsAcross && oldHas != newHas:
+		body := newBody
+		if oldHas {
+			body = oldBody
+		}
+		return renderAcrossCommentSegments(body, oldWidth, newWidth, oldIndent, opts.WordWrap)
+	}
+
+	var oldSegs, newSegs []string
+	if oldHas {
+		oldSegs = renderInlineCommentSegments(oldBody, oldWidth, oldIndent, opts.WordWrap)
+	}
+	if newHas {
+		newSegs = renderInlineCommentSegments(newBody, newWidth, newIndent, opts.WordWrap)
+	}
+	return oldSegs, newSegs
+}
+
+// expanded reports whether a collapsed row's comments should be drawn in full.
+func (o RenderOptions) expanded(row DiffRow, oldHas, newHas bool) bool {
+	if o.CommentExpanded == nil {
+		return false
+	}
+	if oldHas && row.OldLine > 0 && o.CommentExpanded(row.Path, row.OldLine, SideOld) {
+		return true
+	}
+	return newHas && row.NewLine > 0 && o.CommentExpanded(row.Path, row.NewLine, SideNew)
+}
+
+// renderCommentMarker is the collapsed form of a row's comments.
+func renderCommentMarker(count int, body string, width, indent int) string {
+	width = maxInt(1, width)
+	indent = min(maxInt(0, indent), width-1)
+	marker := fmt.Sprintf("💬 %d", count)
+	if plainMode {
+		marker = fmt.Sprintf("[%d comment(s)]", count)
+	}
+	first, _, _ := strings.Cut(strings.TrimSpace(body), "\n")
+	textWidth := maxInt(1, width-indent)
+	text := ansi.Truncate(marker+"  "+normalizeDisplayText(first), textWidth, "…")
+	pad := maxInt(0, textWidth-ansi.StringWidth(text))
+	return commentInlineTextStyle.Render(strings.Repeat(" ", indent) + text + strings.Repeat(" ", pad))
+}
+
+// renderAcrossCommentSegments wraps body to the text width of both panes
+// together and puts the start of each line in the old pane and the rest in
+// the new one.
+func renderAcrossCommentSegments(body string, oldWidth, newWidth, indent int, wordWrap bool) ([]string, []string) {
+	oldWidth = maxInt(1, oldWidth)
+	newWidth = maxInt(1, newWidth)
+	indent = min(maxInt(0, indent), oldWidth-1)
+	leftW := oldWidth - indent
+	if plainMode {
+		body = "[comment] " + body
+	}
+
+	var oldSegs, newSegs []string
+	for _, line := range strings.Split(body, "\n") {
+		var spans []MarkdownSpan
+		if plainMode {
+			spans = []MarkdownSpan{{Text: normalizeDisplayText(line)}}
+		} else {
+			spans = ParseMarkdownLine(normalizeDisplayText(line))
+		}
+		for _, chunk := range wrapText(MarkdownPlainText(spans), leftW+newWidth, wordWrap) {
+			runes := []rune(chunk.text)
+			split := min(len(runes), leftW)
+			left := wrappedChunk{text: string(runes[:split]), start: chunk.start}
+			right := wrappedChunk{text: string(runes[split:]), start: chunk.start + split}
+			oldSegs = append(oldSegs, commentInlineTextStyle.Render(strings.Repeat(" ", indent))+
+				renderMarkdownChunk(spans, left, commentInlineTextStyle)+
+				commentInlineTextStyle.Render(strings.Repeat(" ", leftW-split)))
+			newSegs = append(newSegs, renderMarkdownChunk(spans, right, commentInlineTextStyle)+
+				commentInlineTextStyle.Render(strings.Repeat(" ", maxInt(0, newWidth-(len(runes)-split)))))
+		}
+	}
+	return oldSegs, newSegs
+}
@@ -0,0 +1,94 @@
+package diffview
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LineNumberMode selects what the gutter shows beside each diff line.
+type LineNumberMode int
+
+const (
+	// LineNumbersAbsolute shows the line number of the pane's own side.
+	LineNumbersAbsolute LineNumberMode = iota
+	// LineNumbersHidden drops the number column, keeping only the +/- marker.
+	LineNumbersHidden
+	// LineNumbersRelative shows the row distance from the cursor; the cursor row keeps its absolute number.
+	LineNumbersRelative
+	// LineNumbersBoth shows old and new numbers side by side, which helps in single-pane views.
+	LineNumbersBoth
+)
+
+// lineNumbers carries the gutter settings for one render pass.
+type lineNumbers struct {
+	mode   LineNumberMode
+	oldW   int
+	newW   int
+	relW   int
+	cursor int
+}
+
+func (ln lineNumbers) width(side Side) int {
+	switch ln.mode {
+	case LineNumbersHidden:
+		return 0
+	case LineNumbersRelative:
+		if side == SideOld {
+			return maxInt(ln.oldW, ln.relW)
+		}
+		return maxInt(ln.newW, ln.relW)
+	case LineNumbersBoth:
+		return ln.oldW + 1 + ln.newW
+	default:
+		if side == SideOld {
+			return ln.oldW
+		}
+		return ln.newW
+	}
+}
+
+func (ln lineNumbers) text(row DiffRow, side Side, idx int) string {
+	own := row.NewLine
+	if side == SideOld {
+		own = row.OldLine
+	}
+	switch ln.mode {
+	case LineNumbersHidden:
+		return ""
+	case LineNumbersRelative:
+		if idx != ln.cursor {
+			dist := idx - ln.cursor
+			if dist < 0 {
+				dist = -dist
+			}
+			return fmt.Sprintf("%d", dist)
+		}
+	case LineNumbersBoth:
+		return fmt.Sprintf("%*s %*s", ln.oldW, formatLineNo(row.OldLine), ln.newW, formatLineNo(row.NewLine))
+	}
+	return formatLineNo(own)
+}
+
+// meta renders the marker and number column that precede the line text.
+func (ln lineNumbers) meta(row DiffRow, side Side, idx int, marker rune) string {
+	w := ln.width(side)
+	if w == 0 {
+		return fmt.Sprintf("%c ", marker)
+	}
+	return fmt.Sprintf("%c %*s ", marker, w, ln.text(row, side, idx))
+}
+
+func (ln lineNumbers) blankMeta(side Side) string {
+	w := ln.width(side)
+	if w == 0 {
+		return "  "
+	}
+	return "  " + strings.Repeat(" ", w) + " "
+}
+
+func formatLineNo(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d", n)
+}
@@ -0,0 +1,211 @@
+package diffview
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// MarkdownSpan is a run of comment text with the inline markdown emphasis it had.
+type MarkdownSpan struct {
+	Text   string
+	Bold   bool
+	Italic bool
+	Code   bool
+}
+
+// ParseMarkdownLine turns one line of a comment body into display spans. It
+// covers what review comments use: **bold**, *italic*/_italic_, `code`,
+// "- " list items, "# " headings, and "> " quotes. Unmatched markers stay literal.
+func ParseMarkdownLine(line string) []MarkdownSpan {
+	indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+	rest := line[len(indent):]
+
+	var prefix string
+	var bold, italic bool
+	switch {
+	case strings.HasPrefix(rest, "- "), strings.HasPrefix(rest, "* "), strings.HasPrefix(rest, "+ "):
+		prefix, rest = "• ", rest[2:]
+	case strings.HasPrefix(rest, "> "):
+		prefix, rest, italic = "│ ", rest[2:], true
+	case strings.HasPrefix(rest, "#"):
+		if text := strings.TrimLeft(rest, "#"); len(rest)-len(text) <= 6 && strings.HasPrefix(text, " ") {
+			rest, bold = strings.TrimSpace(text), true
+		}
+	}
+
+	spans := make([]MarkdownSpan, 0, 4)
+	if indent+prefix != "" {
+		spans = append(spans, MarkdownSpan{Text: indent + prefix})
+	}
+	spans = appendInlineSpans(spans, []rune(rest), MarkdownSpan{Bold: bold, Italic: italic})
+	return mergeMarkdownSpans(spans)
+}
+
+// MarkdownPlainText returns the line as ParseMarkdownLine displays it, without styling.
+func MarkdownPlainText(spans []MarkdownSpan) string {
+	var b strings.Builder
+	for _, s := range spans {
+		b.WriteString(s.Text)
+	}
+	return b.String()
+}
+
+func appendInlineSpans(spans []MarkdownSpan, text []rune, style MarkdownSpan) []MarkdownSpan {
+	var plain []rune
+	flush := func() {
+		if len(plain) > 0 {
+			s := style
+			s.Text = string(plain)
+			spans = append(spans, s)
+			plain = plain[:0]
+		}
+	}
+	for i := 0; i < len(text); i++ {
+		r := text[i]
+		switch {
+		case r == '`':
+			if j := indexRune(text, i+1, '`'); j > i+1 {
+				flush()
+				s := style
+				s.Text, s.Code = string(text[i+1:j]), true
+				spans = append(spans, s)
+				i = j
+				continue
+			}
+		case (r == '*' || r == '_') && i+1 < len(text) && text[i+1] == r:
+			marker := string([]rune{r, r})
+			if j := indexMarker(text, i+2, marker); j > i+2 && canOpenEmphasis(text, i, r) {
+				flush()
+				inner := style
+				inner.Bold = true
+				spans = appendInlineSpans(spans, text[i+2:j], inner)
+				i = j + 1
+				continue
+			}
+		case r == '*' || r == '_':
+			if j := indexSingleMarker(text, i+1, r); j > i+1 && canOpenEmphasis(text, i, r) && canCloseEmphasis(text, j, r) {
+				flush()
+				inner := style
+				inner.Italic = true
+				spans = appendInlineSpans(spans, text[i+1:j], inner)
+				i = j
+				continue
+			}
+		}
+		plain = append(plain, r)
+	}
+	flush()
+	return spans
+}
+
+// canOpenEmphasis keeps snake_case and spaced-out asterisks literal.
+func canOpenEmphasis(text []rune, i int, r rune) bool {
+	next := i + 1
+	for next < len(text) && text[next] == r {
+		next++
+	}
+	if next >= len(text) || unicode.IsSpace(text[next]) {
+		return false
+	}
+	return r != '_' || i == 0 || !isWordRune(text[i-1])
+}
+
+func canCloseEmphasis(text []rune, j int, r rune) bool {
+	if unicode.IsSpace(text[j-1]) {
+		return false
+	}
+	return r != '_' || j+1 >= len(text) || !isWordRune(text[j+1])
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func indexRune(text []rune, from int, r rune) int {
+	for i := from; i < len(text); i++ {
+		if text[i] == r {
+			return i
+		}
+	}
+	return -1
+}
+
+func indexMarker(text []rune, from int, marker string) int {
+	m := []rune(marker)
+	for i := from; i+len(m) <= len(text); i++ {
+		if string(text[i:i+len(m)]) == marker {
+			return i
+		}
+	}
+	return -1
+}
+
+// indexSingleMarker finds a lone r, skipping doubled markers that belong to bold.
+func indexSingleMarker(text []rune, from int, r rune) int {
+	for i := from; i < len(text); i++ {
+		if text[i] != r {
+			continue
+		}
+		if i+1 < len(text) && text[i+1] == r {
+			i++
+			continue
+		}
+		return i
+	}
+	return -1
+}
+
+func mergeMarkdownSpans(spans []MarkdownSpan) []MarkdownSpan {
+	out := spans[:0]
+	for _, s := range spans {
+		if s.Text == "" {
+			continue
+		}
+		if n := len(out); n > 0 && out[n-1].Bold == s.Bold && out[n-1].Italic == s.Italic && out[n-1].Code == s.Code {
+			out[n-1].Text += s.Text
+			continue
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// MarkdownStyle layers a span's emphasis on top of base.
+func MarkdownStyle(base lipgloss.Style, span MarkdownSpan) lipgloss.Style {
+	if span.Bold {
+		base = base.Bold(true)
+	}
+	if span.Italic {
+		base = base.Italic(true)
+	}
+	if span.Code {
+		if syntaxStringColor == "" {
+			base = base.Underline(true)
+		} else {
+			base = base.Foreground(syntaxStringColor)
+		}
+	}
+	return base
+}
+
+// renderMarkdownChunk styles the part of a wrapped line covered by chunk.
+func renderMarkdownChunk(spans []MarkdownSpan, chunk wrappedChunk, base lipgloss.Style) string {
+	if chunk.text == "" {
+		return ""
+	}
+	chunkStart := chunk.start
+	chunkEnd := chunk.start + len([]rune(chunk.text))
+	var b strings.Builder
+	offset := 0
+	for _, span := range spans {
+		runes := []rune(span.Text)
+		start, end := max(offset, chunkStart), min(offset+len(runes), chunkEnd)
+		if start < end {
+			b.WriteString(MarkdownStyle(base, span).Render(string(runes[start-offset : end-offset])))
+		}
+		offset += len(runes)
+	}
+	return b.String()
+}
@@ -0,0 +1,119 @@
+package diffview
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Palettes for add/delete hues. The color-blind palettes swap red/green for
+// blue/orange so the two stay apart for red-green color blindness; the +/-
+// gutter markers carry the same information without color.
+const (
+	PaletteDefault      = "default"
+	PaletteDeuteranopia = "deuteranopia"
+	PaletteProtanopia   = "protanopia"
+)
+
+// diffHues holds one side's colors: fg for text, bg for the row fill, and
+// accent for changed words, gutters, and line numbers.
+type diffHues struct {
+	fg     lipgloss.Color
+	bg     lipgloss.Color
+	accent lipgloss.Color
+}
+
+// Marker colors tint the compact add/delete/change cues outside the diff
+// panes, such as the minimap.
+var (
+	addMarkerColor    lipgloss.Color = "78"
+	deleteMarkerColor lipgloss.Color = "203"
+	changeMarkerColor lipgloss.Color = "214"
+)
+
+// MarkerColors returns the add, delete, and change colors of the active palette.
+func MarkerColors() (add, del, change lipgloss.Color) {
+	return addMarkerColor, deleteMarkerColor, changeMarkerColor
+}
+
+func applyPalette(palette string, dark, ansi16 bool) {
+	addMarkerColor, deleteMarkerColor, changeMarkerColor = "78", "203", "214"
+	name := strings.ToLower(strings.TrimSpace(palette))
+	if name != PaletteDeuteranopia && name != PaletteProtanopia {
+		return
+	}
+	// Orange now means delete, so changes get a neutral gray.
+	changeMarkerColor = "250"
+	if !dark {
+		changeMarkerColor = "242"
+	}
+	if ansi16 {
+		// Only blue and yellow survive in 16 colors; protanopia and deuteranopia share them.
+		add, del := lipgloss.Color("12"), lipgloss.Color("11")
+		if !dark {
+			add, del = lipgloss.Color("4"), lipgloss.Color("3")
+		}
+		addMarkerColor, deleteMarkerColor = add, del
+		applyANSIAddDelete(add, del)
+		return
+	}
+	add, del := paletteHues(name, dark)
+	addMarkerColor, deleteMarkerColor = add.fg, del.fg
+	applyAddDeleteHues(add, del)
+}
+
+func paletteHues(name string, dark bool) (diffHues, diffHues) {
+	// Protanopes see long wavelengths darker, so their "delete" leans yellow.
+	switch {
+	case dark && name == PaletteProtanopia:
+		return diffHues{fg: "117", bg: "#1a2030", accent: "25"}, diffHues{fg: "221", bg: "#28251a", accent: "136"}
+	case dark:
+		return diffHues{fg: "75", bg: "#1a2030", accent: "25"}, diffHues{fg: "215", bg: "#2a2218", accent: "130"}
+	case name == PaletteProtanopia:
+		return diffHues{fg: "25", bg: "189", accent: "153"}, diffHues{fg: "136", bg: "230", accent: "222"}
+	default:
+		return diffHues{fg: "25", bg: "189", accent: "153"}, diffHues{fg: "130", bg: "223", accent: "216"}
+	}
+}
+
+// applyAddDeleteHues follows the base themes: dark themes put light text on
+// a dark accent, light themes dark text on a light one, as paletteHues picks.
+func applyAddDeleteHues(add, del diffHues) {
+	accented := func(h diffHues) lipgloss.Style {
+		return lipgloss.NewStyle().Foreground(h.fg).Background(h.accent).Bold(true)
+	}
+
+	addBaseStyle = lipgloss.NewStyle().Foreground(add.fg).Background(add.bg)
+	deleteBaseStyle = lipgloss.NewStyle().Foreground(del.fg).Background(del.bg)
+	changeOldBaseStyle = deleteBaseStyle
+	changeNewBaseStyle = addBaseStyle
+
+	addWordStyle = accented(add)
+	deleteWordStyle = accented(del)
+	addGutterStyle = accented(add)
+	deleteGutterStyle = accented(del)
+	changeOldGutterStyle = accented(del)
+	changeNewGutterStyle = accented(add)
+	addMetaStyle = accented(add)
+	deleteMetaStyle = accented(del)
+	changeOldMetaStyle = accented(del)
+	changeNewMetaStyle = accented(add)
+}
+
+func applyANSIAddDelete(add, del lipgloss.Color) {
+	addBaseStyle = lipgloss.NewStyle().Foreground(add)
+	deleteBaseStyle = lipgloss.NewStyle().Foreground(del)
+	changeOldBaseStyle = deleteBaseStyle
+	changeNewBaseStyle = addBaseStyle
+
+	addWordStyle = lipgloss.NewStyle().Foreground(add).Bold(true).Underline(true)
+	deleteWordStyle = lipgloss.NewStyle().Foreground(del).Bold(true).Underline(true)
+	addGutterStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("0")).Background(add).Bold(true)
+	deleteGutterStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("0")).Background(del).Bold(true)
+	changeOldGutterStyle = lipgloss.NewStyle().Foreground(del).Bold(true)
+	changeNewGutterStyle = lipgloss.NewStyle().Foreground(add).Bold(true)
+	addMetaStyle = lipgloss.NewStyle().Foreground(add).Bold(true)
+	deleteMetaStyle = lipgloss.NewStyle().Foreground(del).Bold(true)
+	changeOldMetaStyle = lipgloss.NewStyle().Foreground(del)
+	changeNewMetaStyle = lipgloss.NewStyle().Foreground(add)
+}
@@ -3,11 +3,48 @@ package diffview
 import (
 	"fmt"
 	"strings"
+	"time"
+	"unique"
 
 	sgdiff "github.com/sourcegraph/go-diff/diff"
+
+	"diffman/internal/debuglog"
 )
 
+// hideEOLChanges shows lines that differ only in their line ending or a byte
+// order mark as unchanged.
+var hideEOLChanges bool
+
+// SetHideEOLChanges turns hiding line-ending-only changes on or off. Such
+// lines keep both line numbers either way, so comments stay anchored.
+func SetHideEOLChanges(on bool) {
+	hideEOLChanges = on
+}
+
+// utf8BOM is the byte order mark some editors write at the start of a file.
+const utf8BOM = "\ufeff"
+
+// trimBOM drops the byte order mark from the first line of a file, so a
+// file gaining or losing one reads the same.
+func trimBOM(line int, text string) string {
+	if line != 1 {
+		return text
+	}
+	return strings.TrimPrefix(text, utf8BOM)
+}
+
 func ParseUnifiedDiff(raw []byte) ([]DiffRow, error) {
+	start := time.Now()
+	rows, err := parseUnifiedDiff(raw)
+	if err != nil {
+		debuglog.Printf("parse diff (%d bytes) failed: %v", len(raw), err)
+	} else {
+		debuglog.Timed(start, "parse diff: %d bytes into %d rows", len(raw), len(rows))
+	}
+	return rows, err
+}
+
+func parseUnifiedDiff(raw []byte) ([]DiffRow, error) {
 	fileDiffs, err := sgdiff.ParseMultiFileDiff(raw)
 	if err != nil {
 		return nil, err
@@ -36,12 +73,13 @@ func ParseUnifiedDiff(raw []byte) ([]DiffRow, error) {
 				}
 				switch line[0] {
 				case ' ':
+					text := intern(line[1:])
 					rows = append(rows, DiffRow{
 						Kind:    RowContext,
-						OldLine: linePtr(oldLn),
-						NewLine: linePtr(newLn),
-						OldText: line[1:],
-						NewText: line[1:],
+						OldLine: oldLn,
+						NewLine: newLn,
+						OldText: trimBOM(oldLn, text),
+						NewText: trimBOM(newLn, text),
 						Path:    path,
 						HunkID:  hunkID,
 					})
@@ -89,8 +127,8 @@ func pairEditRuns(path string, hunkID int, oldLn, newLn *int, dels, adds []strin
 	count := maxInt(len(dels), len(adds))
 	out := make([]DiffRow, 0, count)
 	for i := 0; i < count; i++ {
-		var oldLine *int
-		var newLine *int
+		oldLine := 0
+		newLine := 0
 		oldText := ""
 		newText := ""
 
@@ -98,18 +136,21 @@ func pairEditRuns(path string, hunkID int, oldLn, newLn *int, dels, adds []strin
 		hasAdd := i < len(adds)
 
 		if hasDel {
-			oldLine = linePtr(*oldLn)
-			oldText = dels[i]
+			oldLine = *oldLn
+			oldText = trimBOM(oldLine, dels[i])
 			*oldLn++
 		}
 		if hasAdd {
-			newLine = linePtr(*newLn)
-			newText = adds[i]
+			newLine = *newLn
+			newText = trimBOM(newLine, adds[i])
 			*newLn++
 		}
 
 		kind := RowContext
 		switch {
+		case hasDel && hasAdd && hideEOLChanges && oldText == newText:
+			// Line endings were normalized while splitting the hunk, so
+			// equal text means only the ending or the mark changed.
 		case hasDel && hasAdd:
 			kind = RowChange
 		case hasDel:
@@ -150,6 +191,7 @@ func normalizePath(fd *sgdiff.FileDiff) string {
 	return path
 }
 
+// splitHunkBody splits a hunk into lines, dropping CRLF endings like LF ones.
 func splitHunkBody(body []byte) []string {
 	lines := strings.Split(strings.ReplaceAll(string(body), "\r\n", "\n"), "\n")
 	if len(lines) > 0 && lines[len(lines)-1] == "" {
@@ -165,14 +207,15 @@ func stripPrefix(lines []string) []string {
 			out = append(out, "")
 			continue
 		}
-		out = append(out, line[1:])
+		out = append(out, intern(line[1:]))
 	}
 	return out
 }
 
-func linePtr(n int) *int {
-	v := n
-	return &v
+// intern returns the canonical copy of a line's text, so repeated lines such
+// as braces and blank lines share memory and the raw hunk body can be freed.
+func intern(s string) string {
+	return unique.Make(s).Value()
 }
 
 func maxInt(a, b int) int {
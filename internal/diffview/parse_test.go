@@ -1,6 +1,9 @@
 package diffview
 
-import "testing"
+import (
+	"testing"
+	"unsafe"
+)
 
 func TestParseUnifiedDiffPairsDeleteAndAddRuns(t *testing.T) {
 	raw := []byte(`diff --git a/sample.txt b/sample.txt
@@ -48,8 +51,8 @@ index 1111111..2222222 100644
 	assertLine(t, content[1].NewLine, 2)
 	assertLine(t, content[2].OldLine, 3)
 	assertLine(t, content[2].NewLine, 3)
-	if content[3].OldLine != nil {
-		t.Fatalf("expected add row old line to be nil, got %d", *content[3].OldLine)
+	if content[3].OldLine > 0 {
+		t.Fatalf("expected add row to have no old line, got %d", content[3].OldLine)
 	}
 	assertLine(t, content[3].NewLine, 4)
 	assertLine(t, content[4].OldLine, 4)
@@ -81,19 +84,107 @@ index 0000000..3b18e13
 	if rows[1].Kind != RowAdd || rows[2].Kind != RowAdd {
 		t.Fatalf("expected add rows, got %v and %v", rows[1].Kind, rows[2].Kind)
 	}
-	if rows[1].OldLine != nil || rows[2].OldLine != nil {
-		t.Fatalf("expected old lines to be nil for new file additions")
+	if rows[1].OldLine > 0 || rows[2].OldLine > 0 {
+		t.Fatalf("expected no old lines for new file additions")
 	}
 	assertLine(t, rows[1].NewLine, 1)
 	assertLine(t, rows[2].NewLine, 2)
 }
 
-func assertLine(t *testing.T, got *int, want int) {
+func TestParseUnifiedDiffInternsRepeatedLines(t *testing.T) {
+	raw := []byte(`diff --git a/a.go b/a.go
+--- a/a.go
++++ b/a.go
+@@ -1,5 +1,5 @@
+ 	}
+-	return nil
++	return err
+ 	}
+ }
+`)
+
+	rows, err := ParseUnifiedDiff(raw)
+	if err != nil {
+		t.Fatalf("ParseUnifiedDiff returned error: %v", err)
+	}
+	first, second := rows[1], rows[3]
+	if first.NewText != "\t}" || second.NewText != "\t}" {
+		t.Fatalf("expected repeated context lines, got %q and %q", first.NewText, second.NewText)
+	}
+	if unsafe.StringData(first.OldText) != unsafe.StringData(first.NewText) || unsafe.StringData(first.NewText) != unsafe.StringData(second.NewText) {
+		t.Fatalf("expected both sides and both rows to share one copy of the text")
+	}
+}
+
+func assertLine(t *testing.T, got int, want int) {
 	t.Helper()
-	if got == nil {
-		t.Fatalf("line = nil, want %d", want)
+	if got != want {
+		t.Fatalf("line = %d, want %d", got, want)
+	}
+}
+
+func TestParseUnifiedDiffHidesLineEndingOnlyChanges(t *testing.T) {
+	raw := []byte("diff --git a/a.txt b/a.txt\n" +
+		"--- a/a.txt\n" +
+		"+++ b/a.txt\n" +
+		"@@ -1,3 +1,3 @@\n" +
+		"-one\n" +
+		"-two\n" +
+		"-three\n" +
+		"+\ufeffone\r\n" +
+		"+two\r\n" +
+		"+THREE\r\n")
+
+	rows, err := ParseUnifiedDiff(raw)
+	if err != nil {
+		t.Fatalf("ParseUnifiedDiff returned error: %v", err)
+	}
+	if rows[1].NewText != "one" || rows[2].NewText != "two" {
+		t.Fatalf("expected the BOM and CRLF dropped, got %q %q", rows[1].NewText, rows[2].NewText)
+	}
+	for i, row := range rows[1:] {
+		if row.Kind != RowChange {
+			t.Fatalf("row %d kind = %v, want a change while not hiding", i, row.Kind)
+		}
+	}
+
+	SetHideEOLChanges(true)
+	defer SetHideEOLChanges(false)
+	rows, err = ParseUnifiedDiff(raw)
+	if err != nil {
+		t.Fatalf("ParseUnifiedDiff returned error: %v", err)
+	}
+	want := []RowKind{RowContext, RowContext, RowChange}
+	for i, row := range rows[1:] {
+		if row.Kind != want[i] {
+			t.Fatalf("row %d kind = %v, want %v", i, row.Kind, want[i])
+		}
+		if row.OldLine != i+1 || row.NewLine != i+1 {
+			t.Fatalf("row %d lines = %d/%d, want both %d so comments stay anchored", i, row.OldLine, row.NewLine, i+1)
+		}
+	}
+}
+
+func TestWhitespaceOnlyHunks(t *testing.T) {
+	raw := []byte("diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n" +
+		"@@ -1,2 +1,3 @@\n-\tf(a, b)\n+    f(a,\n+      b)\n x\n" +
+		"@@ -10 +11 @@\n-y := 1\n+y := 2\n" +
+		"@@ -20 +21 @@\n z\n")
+	rows, err := ParseUnifiedDiff(raw)
+	if err != nil {
+		t.Fatalf("ParseUnifiedDiff returned error: %v", err)
+	}
+	got := WhitespaceOnlyHunks(rows)
+	var headers []int
+	for i, row := range rows {
+		if row.Kind == RowHunkHeader {
+			headers = append(headers, i)
+		}
+	}
+	if len(headers) != 3 {
+		t.Fatalf("expected 3 hunks, got rows %+v", rows)
 	}
-	if *got != want {
-		t.Fatalf("line = %d, want %d", *got, want)
+	if !got[headers[0]] || got[headers[1]] || got[headers[2]] {
+		t.Fatalf("expected only the reflowed hunk to be whitespace-only, got %v for headers %v", got, headers)
 	}
 }
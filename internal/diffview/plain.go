@@ -0,0 +1,86 @@
+package diffview
+
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// plainMode swaps glyph and color cues for text, for screen readers and limited terminals.
+var plainMode bool
+
+// colorProfile is the profile to restore when plain mode is turned off.
+var colorProfile termenv.Profile
+
+// SetPlainMode turns the accessible rendering mode on or off. When on, all
+// colors are dropped and the gutter uses ">>" for the cursor and "*" for comments.
+func SetPlainMode(on bool) {
+	if on == plainMode {
+		return
+	}
+	plainMode = on
+	if on {
+		colorProfile = lipgloss.ColorProfile()
+		lipgloss.SetColorProfile(termenv.Ascii)
+		noColorRenderer.SetColorProfile(termenv.Ascii)
+		return
+	}
+	lipgloss.SetColorProfile(colorProfile)
+	noColorRenderer.SetColorProfile(termenv.ANSI)
+}
+
+// PlainMode reports whether SetPlainMode(true) is in effect.
+func PlainMode() bool {
+	return plainMode
+}
+
+// gutterMarks returns the two cursor/comment cells at the start of each row.
+func gutterMarks(isCursor, hasComment bool) string {
+	if plainMode {
+		cursor := "  "
+		if isCursor {
+			cursor = ">>"
+		}
+		if hasComment {
+			// The cursor arrow wins the second cell; the inline "[comment]" label still shows.
+			if isCursor {
+				return ">*"
+			}
+			return " *"
+		}
+		return cursor
+	}
+	cursorMark := " "
+	if isCursor {
+		cursorMark = "▸"
+	}
+	commentMark := " "
+	if hasComment {
+		commentMark = "◉"
+	}
+	return cursorMark + commentMark
+}
+
+// asciiBorder draws pane frames with "+", "-" and "|" so widths match the box-drawing borders.
+var asciiBorder = lipgloss.Border{
+	Top:          "-",
+	Bottom:       "-",
+	Left:         "|",
+	Right:        "|",
+	TopLeft:      "+",
+	TopRight:     "+",
+	BottomLeft:   "+",
+	BottomRight:  "+",
+	MiddleLeft:   "+",
+	MiddleRight:  "+",
+	Middle:       "+",
+	MiddleTop:    "+",
+	MiddleBottom: "+",
+}
+
+// Border returns b, or an ASCII border of the same size in plain mode.
+func Border(b lipgloss.Border) lipgloss.Border {
+	if plainMode {
+		return asciiBorder
+	}
+	return b
+}
@@ -1,11 +1,11 @@
 package diffview
 
 import (
-	"fmt"
 	"path/filepath"
 	"strings"
 	"sync"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/alecthomas/chroma/v2"
 	"github.com/alecthomas/chroma/v2/lexers"
@@ -17,6 +17,9 @@ type SplitRender struct {
 	NewLines   []string
 	RowStarts  []int
 	RowHeights []int
+	// Unstyled reports that segments outside RenderOptions.StyleFrom and
+	// StyleTo were drawn without styling.
+	Unstyled bool
 }
 
 type textRange struct {
@@ -62,6 +65,16 @@ type syntaxCacheKey struct {
 	text string
 }
 
+type wordRangesKey struct {
+	oldText string
+	newText string
+}
+
+type wordRanges struct {
+	old []textRange
+	new []textRange
+}
+
 var (
 	addBaseStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("78")).Background(lipgloss.Color("#1a2620"))
 	deleteBaseStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("203")).Background(lipgloss.Color("#2a1f21"))
@@ -73,6 +86,7 @@ var (
 	addWordStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("121")).Background(lipgloss.Color("22")).Bold(true)
 	deleteWordStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("210")).Background(lipgloss.Color("52")).Bold(true)
 	cursorRowBg     = lipgloss.Color("236")
+	selectionRowBg  = lipgloss.Color("238")
 
 	cursorGutterStyle        = lipgloss.NewStyle().Foreground(lipgloss.Color("16")).Background(lipgloss.Color("45")).Bold(true)
 	commentGutterStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("16")).Background(lipgloss.Color("220")).Bold(true)
@@ -102,12 +116,23 @@ var (
 
 	syntaxRangesCacheMu sync.RWMutex
 	syntaxRangesCache   = make(map[syntaxCacheKey][]syntaxRange)
+
+	// wordRangesCache keeps changedWordRanges results so moving the cursor
+	// doesn't rerun the word LCS for every changed row of the file.
+	wordRangesCacheMu sync.RWMutex
+	wordRangesCache   = make(map[wordRangesKey]wordRanges)
 )
 
+// ClearSyntaxCache drops the syntax and word-diff ranges computed for
+// earlier diffs.
 func ClearSyntaxCache() {
 	syntaxRangesCacheMu.Lock()
 	syntaxRangesCache = make(map[syntaxCacheKey][]syntaxRange)
 	syntaxRangesCacheMu.Unlock()
+
+	wordRangesCacheMu.Lock()
+	wordRangesCache = make(map[wordRangesKey]wordRanges)
+	wordRangesCacheMu.Unlock()
 }
 
 func RenderSplit(
@@ -138,6 +163,100 @@ func RenderSplitWithLayoutComments(
 	cursor int,
 	hasComment func(path string, line int, side Side) bool,
 	commentText func(path string, line int, side Side) (string, bool),
+) SplitRender {
+	return RenderSplitWithOptions(rows, oldWidth, newWidth, cursor, hasComment, commentText, RenderOptions{})
+}
+
+// RenderOptions tunes how rows are drawn. The zero value matches RenderSplitWithLayoutComments.
+type RenderOptions struct {
+	LineNumbers LineNumberMode
+	// WordWrap breaks long lines and inline comments at spaces instead of
+	// at the pane edge.
+	WordWrap bool
+	// CommentPlacement picks how inline comments are drawn; CommentExpanded
+	// reports the anchors whose collapsed comments are shown in full.
+	CommentPlacement CommentPlacement
+	CommentExpanded  func(path string, line int, side Side) bool
+	// HideComments leaves out inline comment bodies; the gutter markers stay.
+	HideComments bool
+	// HasSelection highlights rows SelectFrom through SelectTo (inclusive, either order).
+	HasSelection bool
+	SelectFrom   int
+	SelectTo     int
+	// MaxLineColumns cuts lines longer than this many characters, such as
+	// minified code, with TruncatedMarker unless LineExpanded reports the
+	// row; 0 never cuts.
+	MaxLineColumns int
+	LineExpanded   func(row DiffRow) bool
+	// StyleFrom and StyleTo bound the visual lines, end exclusive, on which
+	// rows wrapping into more than lazyStyleSegments segments are styled;
+	// their other segments are drawn plain. StyleTo <= StyleFrom styles all.
+	StyleFrom int
+	StyleTo   int
+}
+
+// lazyStyleSegments is how many segments a wrapped row may have before only
+// those inside the style window are highlighted.
+const lazyStyleSegments = 8
+
+// styleWindow holds the visual lines, relative to a row's first line, that
+// get styled; to <= from means all of them.
+type styleWindow struct {
+	from int
+	to   int
+}
+
+func (w styleWindow) bounded() bool {
+	return w.to > w.from
+}
+
+func (w styleWindow) contains(line int) bool {
+	return !w.bounded() || (line >= w.from && line < w.to)
+}
+
+// TruncatedMarker ends a line cut at RenderOptions.MaxLineColumns.
+func TruncatedMarker() string {
+	if plainMode {
+		return " ... (expand)"
+	}
+	return " … (expand)"
+}
+
+// lineLimit is the column row's lines are cut at, or 0 when they are not.
+func (o RenderOptions) lineLimit(row DiffRow) int {
+	if o.MaxLineColumns <= 0 || (o.LineExpanded != nil && o.LineExpanded(row)) {
+		return 0
+	}
+	return o.MaxLineColumns
+}
+
+// IsTruncated reports whether rendering with limit cuts either side of row.
+func IsTruncated(row DiffRow, limit int) bool {
+	if limit <= 0 || (row.Kind != RowContext && row.Kind != RowDelete && row.Kind != RowAdd && row.Kind != RowChange) {
+		return false
+	}
+	return utf8.RuneCountInString(row.OldText) > limit || utf8.RuneCountInString(row.NewText) > limit
+}
+
+func (o RenderOptions) selected(idx int) bool {
+	if !o.HasSelection {
+		return false
+	}
+	lo, hi := o.SelectFrom, o.SelectTo
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	return idx >= lo && idx <= hi
+}
+
+func RenderSplitWithOptions(
+	rows []DiffRow,
+	oldWidth int,
+	newWidth int,
+	cursor int,
+	hasComment func(path string, line int, side Side) bool,
+	commentText func(path string, line int, side Side) (string, bool),
+	opts RenderOptions,
 ) SplitRender {
 	if oldWidth <= 0 {
 		oldWidth = 1
@@ -149,15 +268,20 @@ func RenderSplitWithLayoutComments(
 	maxOld := 0
 	maxNew := 0
 	for _, row := range rows {
-		if row.OldLine != nil && *row.OldLine > maxOld {
-			maxOld = *row.OldLine
+		if row.OldLine > 0 && row.OldLine > maxOld {
+			maxOld = row.OldLine
 		}
-		if row.NewLine != nil && *row.NewLine > maxNew {
-			maxNew = *row.NewLine
+		if row.NewLine > 0 && row.NewLine > maxNew {
+			maxNew = row.NewLine
 		}
 	}
-	oldNumW := maxInt(3, digits(maxOld))
-	newNumW := maxInt(3, digits(maxNew))
+	numbers := lineNumbers{
+		mode:   opts.LineNumbers,
+		oldW:   maxInt(3, digits(maxOld)),
+		newW:   maxInt(3, digits(maxNew)),
+		relW:   maxInt(3, digits(len(rows))),
+		cursor: cursor,
+	}
 
 	out := SplitRender{
 		OldLines:   make([]string, 0, len(rows)),
@@ -166,9 +290,17 @@ func RenderSplitWithLayoutComments(
 		RowHeights: make([]int, 0, len(rows)),
 	}
 
+	wsOnly := WhitespaceOnlyHunks(rows)
 	for i, row := range rows {
-		oldMain := renderRowSegments(row, SideOld, oldWidth, oldNumW, i == cursor, hasComment)
-		newMain := renderRowSegments(row, SideNew, newWidth, newNumW, i == cursor, hasComment)
+		if wsOnly[i] {
+			row.OldText += "  " + WhitespaceBadge
+		}
+		selected := opts.selected(i)
+		limit := opts.lineLimit(row)
+		window := styleWindow{from: opts.StyleFrom - len(out.OldLines), to: opts.StyleTo - len(out.OldLines)}
+		oldMain, oldUnstyled := renderRowSegments(row, SideOld, oldWidth, numbers, i, selected, opts.WordWrap, limit, window, hasComment)
+		newMain, newUnstyled := renderRowSegments(row, SideNew, newWidth, numbers, i, selected, opts.WordWrap, limit, window, hasComment)
+		out.Unstyled = out.Unstyled || oldUnstyled || newUnstyled
 		mainHeight := maxInt(len(oldMain), len(newMain))
 		if mainHeight <= 0 {
 			mainHeight = 1
@@ -176,24 +308,10 @@ func RenderSplitWithLayoutComments(
 		oldSegs := padSegments(oldMain, oldWidth, mainHeight)
 		newSegs := padSegments(newMain, newWidth, mainHeight)
 
-		if commentText != nil {
-			oldCommentBody, oldHasComment := commentTextForSide(row, SideOld, commentText)
-			newCommentBody, newHasComment := commentTextForSide(row, SideNew, commentText)
-			if oldHasComment || newHasComment {
-				oldIndent := commentTextIndent(row, SideOld, oldNumW)
-				newIndent := commentTextIndent(row, SideNew, newNumW)
-				oldCommentSegs := []string{}
-				if oldHasComment {
-					oldCommentSegs = renderInlineCommentSegments(oldCommentBody, oldWidth, oldIndent)
-				}
-				newCommentSegs := []string{}
-				if newHasComment {
-					newCommentSegs = renderInlineCommentSegments(newCommentBody, newWidth, newIndent)
-				}
+		if commentText != nil && !opts.HideComments {
+			oldCommentSegs, newCommentSegs := inlineCommentSegments(row, i, numbers, oldWidth, newWidth, commentText, opts)
+			if len(oldCommentSegs) > 0 || len(newCommentSegs) > 0 {
 				commentHeight := maxInt(len(oldCommentSegs), len(newCommentSegs))
-				if commentHeight <= 0 {
-					commentHeight = 1
-				}
 				oldCommentSegs = padCommentSegments(oldCommentSegs, oldWidth, commentHeight)
 				newCommentSegs = padCommentSegments(newCommentSegs, newWidth, commentHeight)
 				oldSegs = append(oldSegs, oldCommentSegs...)
@@ -221,10 +339,16 @@ func RenderSplitWithLayoutComments(
 func renderRowSegments(
 	row DiffRow,
 	side Side,
-	width, numW int,
-	isCursor bool,
+	width int,
+	numbers lineNumbers,
+	idx int,
+	selected bool,
+	wordWrap bool,
+	maxColumns int,
+	window styleWindow,
 	hasComment func(path string, line int, side Side) bool,
-) []string {
+) ([]string, bool) {
+	isCursor := idx == numbers.cursor
 	hasAnyComment := hasCommentOnAnySide(row, hasComment)
 	prefix := renderGutterPrefix(isCursor, hasAnyComment, row.Kind, side)
 	contPrefix := renderContinuationGutterPrefix(isCursor, hasAnyComment, row.Kind, side)
@@ -237,7 +361,7 @@ func renderRowSegments(
 			text = row.NewText
 		}
 		text = normalizeDisplayText(text)
-		chunks := wrapRunesWithOffsets(text, lineWidth)
+		chunks := wrapText(text, lineWidth, wordWrap)
 		out := make([]string, 0, len(chunks))
 		for i, chunk := range chunks {
 			p := contPrefix
@@ -247,6 +371,8 @@ func renderRowSegments(
 			hstyle := hunkBaseStyle
 			if isCursor {
 				hstyle = hstyle.Background(cursorRowBg)
+			} else if selected {
+				hstyle = hstyle.Background(selectionRowBg)
 			}
 			styled := hstyle.Render(chunk.text)
 			out = append(out, p+styled+styledPad(hstyle, lineWidth-len([]rune(chunk.text))))
@@ -254,28 +380,27 @@ func renderRowSegments(
 		if len(out) == 0 {
 			out = append(out, prefix+strings.Repeat(" ", lineWidth))
 		}
-		return out
+		return out, false
 
 	case RowFileHeader:
 		// File headers are no longer emitted by parser; keep safe behavior.
-		return []string{prefix + strings.Repeat(" ", lineWidth)}
+		return []string{prefix + strings.Repeat(" ", lineWidth)}, false
 	}
 
-	lineNo, sideText, marker, ok := sideContent(row, side)
+	_, sideText, marker, ok := sideContent(row, side)
 	if !ok {
-		return []string{prefix + strings.Repeat(" ", lineWidth)}
+		return []string{prefix + strings.Repeat(" ", lineWidth)}, false
 	}
 
-	num := ""
-	if lineNo != nil {
-		num = fmt.Sprintf("%d", *lineNo)
-	}
-	meta := fmt.Sprintf("%c %*s ", marker, numW, num)
+	meta := numbers.meta(row, side, idx, marker)
 	metaWidth := len([]rune(meta))
 	textWidth := maxInt(1, lineWidth-metaWidth)
 
 	plainText := normalizeDisplayText(sideText)
-	chunks := wrapRunesWithOffsets(plainText, textWidth)
+	if maxColumns > 0 && utf8.RuneCountInString(plainText) > maxColumns {
+		plainText = string([]rune(plainText)[:maxColumns]) + TruncatedMarker()
+	}
+	chunks := wrapText(plainText, textWidth, wordWrap)
 	if len(chunks) == 0 {
 		chunks = []wrappedChunk{{text: "", start: 0}}
 	}
@@ -283,34 +408,60 @@ func renderRowSegments(
 	baseStyle, highlightStyle := stylesForContent(row.Kind, side)
 	if isCursor {
 		baseStyle = baseStyle.Background(cursorRowBg)
+	} else if selected {
+		baseStyle = baseStyle.Background(selectionRowBg)
 	}
+	// Styling a pathological line costs time proportional to its length on
+	// every render, so long wrapped rows only style the segments in view.
+	lazy := len(chunks) > lazyStyleSegments && window.bounded()
+	if lazy && (window.to <= 0 || window.from >= len(chunks)) {
+		return plainSegments(chunks, prefix, contPrefix, meta, textWidth), true
+	}
+
 	changed := highlightRanges(row, side)
 	syntax := syntaxRangesForPath(row.Path, plainText)
 
 	out := make([]string, 0, len(chunks))
-	firstStyled := styleChunk(chunks[0].text, chunks[0].start, changed, syntax, baseStyle, highlightStyle)
+	unstyled := false
 	metaStyled := styleMeta(meta, row.Kind, side, isCursor)
-	out = append(out, prefix+metaStyled+firstStyled+styledPad(baseStyle, textWidth-len([]rune(chunks[0].text))))
-
 	contMeta := styleMeta(strings.Repeat(" ", metaWidth), row.Kind, side, isCursor)
-	for _, chunk := range chunks[1:] {
+	for i, chunk := range chunks {
+		p, m := contPrefix, contMeta
+		if i == 0 {
+			p, m = prefix, metaStyled
+		}
+		if lazy && !window.contains(i) {
+			out = append(out, plainSegment(chunk, i, prefix, contPrefix, meta, textWidth))
+			unstyled = true
+			continue
+		}
 		styled := styleChunk(chunk.text, chunk.start, changed, syntax, baseStyle, highlightStyle)
-		out = append(out, contPrefix+contMeta+styled+styledPad(baseStyle, textWidth-len([]rune(chunk.text))))
+		out = append(out, p+m+styled+styledPad(baseStyle, textWidth-len([]rune(chunk.text))))
 	}
 
-	return out
+	return out, unstyled
 }
 
-func renderGutterPrefix(isCursor, hasComment bool, kind RowKind, side Side) string {
-	cursorMark := " "
-	if isCursor {
-		cursorMark = "▸"
+// plainSegments draws chunks without styling, for a wrapped row that lies
+// entirely outside the style window.
+func plainSegments(chunks []wrappedChunk, prefix, contPrefix, meta string, textWidth int) []string {
+	out := make([]string, 0, len(chunks))
+	for i, chunk := range chunks {
+		out = append(out, plainSegment(chunk, i, prefix, contPrefix, meta, textWidth))
 	}
-	commentMark := " "
-	if hasComment {
-		commentMark = "◉"
+	return out
+}
+
+func plainSegment(chunk wrappedChunk, i int, prefix, contPrefix, meta string, textWidth int) string {
+	p, m := contPrefix, strings.Repeat(" ", len([]rune(meta)))
+	if i == 0 {
+		p, m = prefix, meta
 	}
-	marks := cursorMark + commentMark
+	return p + m + chunk.text + strings.Repeat(" ", maxInt(0, textWidth-len([]rune(chunk.text))))
+}
+
+func renderGutterPrefix(isCursor, hasComment bool, kind RowKind, side Side) string {
+	marks := gutterMarks(isCursor, hasComment)
 
 	switch {
 	case isCursor && hasComment:
@@ -415,9 +566,7 @@ func highlightRanges(row DiffRow, side Side) []textRange {
 	if row.Kind != RowChange {
 		return nil
 	}
-	oldText := normalizeDisplayText(row.OldText)
-	newText := normalizeDisplayText(row.NewText)
-	oldRanges, newRanges := changedWordRanges(oldText, newText)
+	oldRanges, newRanges := cachedWordRanges(normalizeDisplayText(row.OldText), normalizeDisplayText(row.NewText))
 	if side == SideOld {
 		return oldRanges
 	}
@@ -632,6 +781,22 @@ func inRanges(pos int, ranges []textRange) bool {
 	return false
 }
 
+func cachedWordRanges(oldText, newText string) ([]textRange, []textRange) {
+	key := wordRangesKey{oldText: oldText, newText: newText}
+	wordRangesCacheMu.RLock()
+	ranges, ok := wordRangesCache[key]
+	wordRangesCacheMu.RUnlock()
+	if ok {
+		return ranges.old, ranges.new
+	}
+
+	ranges.old, ranges.new = changedWordRanges(oldText, newText)
+	wordRangesCacheMu.Lock()
+	wordRangesCache[key] = ranges
+	wordRangesCacheMu.Unlock()
+	return ranges.old, ranges.new
+}
+
 func changedWordRanges(oldText, newText string) ([]textRange, []textRange) {
 	oldTokens := tokenizeWords(oldText)
 	newTokens := tokenizeWords(newText)
@@ -758,11 +923,11 @@ func mergeRanges(in []textRange) []textRange {
 	return out
 }
 
-func sideContent(row DiffRow, side Side) (*int, string, rune, bool) {
+func sideContent(row DiffRow, side Side) (int, string, rune, bool) {
 	switch side {
 	case SideOld:
-		if row.OldLine == nil {
-			return nil, "", ' ', false
+		if row.OldLine == 0 {
+			return 0, "", ' ', false
 		}
 		marker := ' '
 		if row.Kind == RowDelete || row.Kind == RowChange {
@@ -771,8 +936,8 @@ func sideContent(row DiffRow, side Side) (*int, string, rune, bool) {
 		return row.OldLine, row.OldText, marker, true
 
 	case SideNew:
-		if row.NewLine == nil {
-			return nil, "", ' ', false
+		if row.NewLine == 0 {
+			return 0, "", ' ', false
 		}
 		marker := ' '
 		if row.Kind == RowAdd || row.Kind == RowChange {
@@ -781,17 +946,17 @@ func sideContent(row DiffRow, side Side) (*int, string, rune, bool) {
 		return row.NewLine, row.NewText, marker, true
 	}
 
-	return nil, "", ' ', false
+	return 0, "", ' ', false
 }
 
 func hasCommentOnAnySide(row DiffRow, hasComment func(path string, line int, side Side) bool) bool {
 	if hasComment == nil {
 		return false
 	}
-	if row.OldLine != nil && hasComment(row.Path, *row.OldLine, SideOld) {
+	if row.OldLine > 0 && hasComment(row.Path, row.OldLine, SideOld) {
 		return true
 	}
-	if row.NewLine != nil && hasComment(row.Path, *row.NewLine, SideNew) {
+	if row.NewLine > 0 && hasComment(row.Path, row.NewLine, SideNew) {
 		return true
 	}
 	return false
@@ -803,35 +968,29 @@ func commentTextForSide(row DiffRow, side Side, commentText func(path string, li
 	}
 	switch side {
 	case SideOld:
-		if row.OldLine == nil {
+		if row.OldLine == 0 {
 			return "", false
 		}
-		return commentText(row.Path, *row.OldLine, SideOld)
+		return commentText(row.Path, row.OldLine, SideOld)
 	case SideNew:
-		if row.NewLine == nil {
+		if row.NewLine == 0 {
 			return "", false
 		}
-		return commentText(row.Path, *row.NewLine, SideNew)
+		return commentText(row.Path, row.NewLine, SideNew)
 	default:
 		return "", false
 	}
 }
 
-func commentTextIndent(row DiffRow, side Side, numW int) int {
-	lineNo, _, marker, ok := sideContent(row, side)
+func commentTextIndent(row DiffRow, side Side, numbers lineNumbers, idx int) int {
+	_, _, marker, ok := sideContent(row, side)
 	if !ok {
-		meta := fmt.Sprintf("%c %*s ", ' ', numW, "")
-		return 3 + len([]rune(meta))
-	}
-	num := ""
-	if lineNo != nil {
-		num = fmt.Sprintf("%d", *lineNo)
+		return 3 + len([]rune(numbers.blankMeta(side)))
 	}
-	meta := fmt.Sprintf("%c %*s ", marker, numW, num)
-	return 3 + len([]rune(meta))
+	return 3 + len([]rune(numbers.meta(row, side, idx, marker)))
 }
 
-func renderInlineCommentSegments(commentBody string, width, indent int) []string {
+func renderInlineCommentSegments(commentBody string, width, indent int, wordWrap bool) []string {
 	if width <= 0 {
 		width = 1
 	}
@@ -842,21 +1001,31 @@ func renderInlineCommentSegments(commentBody string, width, indent int) []string
 		indent = width - 1
 	}
 	textWidth := maxInt(1, width-indent)
+	if plainMode {
+		commentBody = "[comment] " + commentBody
+	}
 	lines := strings.Split(commentBody, "\n")
 	out := make([]string, 0, len(lines))
 	for _, line := range lines {
-		plain := normalizeDisplayText(line)
-		chunks := wrapRunesWithOffsets(plain, textWidth)
+		var spans []MarkdownSpan
+		if plainMode {
+			spans = []MarkdownSpan{{Text: normalizeDisplayText(line)}}
+		} else {
+			spans = ParseMarkdownLine(normalizeDisplayText(line))
+		}
+		plain := MarkdownPlainText(spans)
+		chunks := wrapText(plain, textWidth, wordWrap)
 		if len(chunks) == 0 {
 			chunks = []wrappedChunk{{text: "", start: 0}}
 		}
 		for _, chunk := range chunks {
-			raw := strings.Repeat(" ", indent) + chunk.text
-			pad := width - len([]rune(raw))
+			pad := width - indent - len([]rune(chunk.text))
 			if pad < 0 {
 				pad = 0
 			}
-			out = append(out, commentInlineTextStyle.Render(raw+strings.Repeat(" ", pad)))
+			out = append(out, commentInlineTextStyle.Render(strings.Repeat(" ", indent))+
+				renderMarkdownChunk(spans, chunk, commentInlineTextStyle)+
+				commentInlineTextStyle.Render(strings.Repeat(" ", pad)))
 		}
 	}
 	if len(out) == 0 {
@@ -900,6 +1069,13 @@ func expandTabs(s string, tabSize int) string {
 	return b.String()
 }
 
+func wrapText(s string, width int, wordWrap bool) []wrappedChunk {
+	if wordWrap {
+		return wrapWordsWithOffsets(s, width)
+	}
+	return wrapRunesWithOffsets(s, width)
+}
+
 func wrapRunesWithOffsets(s string, width int) []wrappedChunk {
 	if width <= 0 {
 		return []wrappedChunk{{text: "", start: 0}}
@@ -920,6 +1096,44 @@ func wrapRunesWithOffsets(s string, width int) []wrappedChunk {
 	return out
 }
 
+// wrapWordsWithOffsets wraps like wrapRunesWithOffsets but breaks at the
+// last space that fits, leaving out the spaces at the break. Words longer
+// than width are still cut. Each chunk keeps its offset into s, so change
+// and syntax highlight ranges map onto it as before.
+func wrapWordsWithOffsets(s string, width int) []wrappedChunk {
+	if width <= 0 {
+		return []wrappedChunk{{text: "", start: 0}}
+	}
+	runes := []rune(s)
+	if len(runes) == 0 {
+		return []wrappedChunk{{text: "", start: 0}}
+	}
+
+	out := make([]wrappedChunk, 0, len(runes)/width+1)
+	for start := 0; start < len(runes); {
+		end := start + width
+		if end >= len(runes) {
+			out = append(out, wrappedChunk{text: string(runes[start:]), start: start})
+			break
+		}
+		brk := end
+		for i := end; i > start; i-- {
+			if unicode.IsSpace(runes[i]) && !unicode.IsSpace(runes[i-1]) {
+				brk = i
+				break
+			}
+		}
+		out = append(out, wrappedChunk{text: string(runes[start:brk]), start: start})
+		start = brk
+		if brk < end || unicode.IsSpace(runes[brk]) {
+			for start < len(runes) && unicode.IsSpace(runes[start]) {
+				start++
+			}
+		}
+	}
+	return out
+}
+
 func padSegments(segs []string, width, height int) []string {
 	if len(segs) >= height {
 		return segs
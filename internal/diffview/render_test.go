@@ -1,20 +1,22 @@
 package diffview
 
 import (
+	"reflect"
 	"regexp"
 	"strings"
 	"testing"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 )
 
 var ansiRE = regexp.MustCompile(`\x1b\[[0-9;]*m`)
 
 func TestRenderSplitIncludesCursorAndSideCommentMarkers(t *testing.T) {
 	rows := []DiffRow{
-		{Kind: RowContext, Path: "a.txt", OldLine: intPtr(1), NewLine: intPtr(1), OldText: "before", NewText: "before"},
-		{Kind: RowChange, Path: "a.txt", OldLine: intPtr(2), NewLine: intPtr(2), OldText: "old", NewText: "new"},
-		{Kind: RowAdd, Path: "a.txt", NewLine: intPtr(3), NewText: "added"},
+		{Kind: RowContext, Path: "a.txt", OldLine: 1, NewLine: 1, OldText: "before", NewText: "before"},
+		{Kind: RowChange, Path: "a.txt", OldLine: 2, NewLine: 2, OldText: "old", NewText: "new"},
+		{Kind: RowAdd, Path: "a.txt", NewLine: 3, NewText: "added"},
 	}
 
 	oldLines, newLines := RenderSplit(rows, 30, 30, 1, func(path string, line int, side Side) bool {
@@ -46,8 +48,8 @@ func TestRenderSplitIncludesCursorAndSideCommentMarkers(t *testing.T) {
 
 func TestRenderSplitUsesAddRemoveMarkersForSingleSidedRows(t *testing.T) {
 	rows := []DiffRow{
-		{Kind: RowDelete, Path: "a.txt", OldLine: intPtr(5), OldText: "gone"},
-		{Kind: RowAdd, Path: "a.txt", NewLine: intPtr(8), NewText: "new"},
+		{Kind: RowDelete, Path: "a.txt", OldLine: 5, OldText: "gone"},
+		{Kind: RowAdd, Path: "a.txt", NewLine: 8, NewText: "new"},
 	}
 
 	oldLines, newLines := RenderSplit(rows, 40, 40, 0, nil)
@@ -76,16 +78,16 @@ func TestRenderSplitWithLayoutKeepsRowsAlignedWhenWrapping(t *testing.T) {
 		{
 			Kind:    RowChange,
 			Path:    "a.txt",
-			OldLine: intPtr(10),
-			NewLine: intPtr(10),
+			OldLine: 10,
+			NewLine: 10,
 			OldText: "old side has a much longer line than new side",
 			NewText: "short",
 		},
 		{
 			Kind:    RowContext,
 			Path:    "a.txt",
-			OldLine: intPtr(11),
-			NewLine: intPtr(11),
+			OldLine: 11,
+			NewLine: 11,
 			OldText: "next",
 			NewText: "next",
 		},
@@ -111,7 +113,7 @@ func TestRenderSplitWithLayoutExpandsTabsBeforeWrapping(t *testing.T) {
 		{
 			Kind:    RowAdd,
 			Path:    "a.txt",
-			NewLine: intPtr(5),
+			NewLine: 5,
 			NewText: "\tif len(items) > 0 {\treturn items[0]\t}",
 		},
 	}
@@ -136,7 +138,7 @@ func TestRenderSplitWithLayoutContinuationKeepsLineNumberIndent(t *testing.T) {
 		{
 			Kind:    RowAdd,
 			Path:    "a.txt",
-			NewLine: intPtr(12),
+			NewLine: 12,
 			NewText: "abcdefghijklmnopqrstuvwxyz",
 		},
 	}
@@ -162,8 +164,8 @@ func TestRenderSplitWithLayoutHighlightsChangedWords(t *testing.T) {
 		{
 			Kind:    RowChange,
 			Path:    "a.txt",
-			OldLine: intPtr(3),
-			NewLine: intPtr(3),
+			OldLine: 3,
+			NewLine: 3,
 			OldText: "alpha beta gamma",
 			NewText: "alpha zeta gamma",
 		},
@@ -180,6 +182,25 @@ func TestRenderSplitWithLayoutHighlightsChangedWords(t *testing.T) {
 	}
 }
 
+func TestCachedWordRangesReusesResultUntilCleared(t *testing.T) {
+	ClearSyntaxCache()
+	key := wordRangesKey{oldText: "alpha beta gamma", newText: "alpha zeta gamma"}
+
+	oldRanges, newRanges := cachedWordRanges(key.oldText, key.newText)
+	wantOld, wantNew := changedWordRanges(key.oldText, key.newText)
+	if !reflect.DeepEqual(oldRanges, wantOld) || !reflect.DeepEqual(newRanges, wantNew) {
+		t.Fatalf("cachedWordRanges() = %v %v, want %v %v", oldRanges, newRanges, wantOld, wantNew)
+	}
+	if _, ok := wordRangesCache[key]; !ok {
+		t.Fatalf("expected the ranges cached")
+	}
+
+	ClearSyntaxCache()
+	if len(wordRangesCache) != 0 {
+		t.Fatalf("expected ClearSyntaxCache to drop the word ranges, got %d entries", len(wordRangesCache))
+	}
+}
+
 func TestDiffAccentStyleSelection(t *testing.T) {
 	if _, ok := gutterStyleFor(RowAdd, SideNew); !ok {
 		t.Fatalf("expected add gutter style")
@@ -323,7 +344,7 @@ func TestClearSyntaxCacheResetsEntries(t *testing.T) {
 
 func TestRenderSplitShowsCommentMarkerOnBothPanes(t *testing.T) {
 	rows := []DiffRow{
-		{Kind: RowChange, Path: "a.txt", OldLine: intPtr(4), NewLine: intPtr(4), OldText: "old", NewText: "new"},
+		{Kind: RowChange, Path: "a.txt", OldLine: 4, NewLine: 4, OldText: "old", NewText: "new"},
 	}
 
 	out := RenderSplitWithLayout(rows, 30, 30, 0, func(path string, line int, side Side) bool {
@@ -343,7 +364,7 @@ func TestRenderSplitWithLayoutCursorStylingDoesNotChangeWrapWidth(t *testing.T)
 		{
 			Kind:    RowAdd,
 			Path:    "a.txt",
-			NewLine: intPtr(7),
+			NewLine: 7,
 			NewText: "this is a fairly long wrapped line for cursor width checks",
 		},
 	}
@@ -368,8 +389,8 @@ func TestRenderSplitWithLayoutCommentsShowsInlineCommentBelowAnchoredSide(t *tes
 		{
 			Kind:    RowChange,
 			Path:    "a.txt",
-			OldLine: intPtr(12),
-			NewLine: intPtr(12),
+			OldLine: 12,
+			NewLine: 12,
 			OldText: "old",
 			NewText: "new",
 		},
@@ -417,7 +438,7 @@ func TestRenderSplitWithLayoutCommentsWrapsLongCommentWithoutWidthOverflow(t *te
 		{
 			Kind:    RowAdd,
 			Path:    "a.txt",
-			NewLine: intPtr(7),
+			NewLine: 7,
 			NewText: "x",
 		},
 	}
@@ -475,7 +496,292 @@ func stripANSILines(lines []string) []string {
 	return out
 }
 
-func intPtr(n int) *int {
-	v := n
-	return &v
+func TestRenderSplitWithOptionsLineNumberModes(t *testing.T) {
+	rows := []DiffRow{
+		{Kind: RowContext, Path: "a.txt", OldLine: 7, NewLine: 9, OldText: "one", NewText: "one"},
+		{Kind: RowContext, Path: "a.txt", OldLine: 8, NewLine: 10, OldText: "two", NewText: "two"},
+		{Kind: RowContext, Path: "a.txt", OldLine: 9, NewLine: 11, OldText: "three", NewText: "three"},
+	}
+
+	cases := []struct {
+		mode LineNumberMode
+		want []string
+	}{
+		{LineNumbersAbsolute, []string{"    9 one", "   10 two", "   11 three"}},
+		{LineNumbersHidden, []string{"  one", "  two", "  three"}},
+		{LineNumbersRelative, []string{"    1 one", "   10 two", "    1 three"}},
+		{LineNumbersBoth, []string{"    7   9 one", "    8  10 two", "    9  11 three"}},
+	}
+	for _, tc := range cases {
+		out := RenderSplitWithOptions(rows, 40, 40, 1, nil, nil, RenderOptions{LineNumbers: tc.mode})
+		for i, want := range tc.want {
+			got := strings.TrimRight(string([]rune(stripANSI(out.NewLines[i]))[3:]), " ")
+			if got != want {
+				t.Fatalf("mode %d row %d: got %q want %q", tc.mode, i, got, want)
+			}
+		}
+	}
+}
+
+func TestRenderSplitWithLayoutCommentsPlainModeUsesTextMarkers(t *testing.T) {
+	SetPlainMode(true)
+	defer SetPlainMode(false)
+
+	rows := []DiffRow{
+		{Kind: RowChange, Path: "a.txt", OldLine: 3, NewLine: 3, OldText: "old", NewText: "new"},
+		{Kind: RowAdd, Path: "a.txt", NewLine: 4, NewText: "added"},
+	}
+
+	out := RenderSplitWithLayoutComments(
+		rows,
+		40,
+		40,
+		0,
+		func(path string, line int, side Side) bool {
+			return line == 3 && side == SideNew
+		},
+		func(path string, line int, side Side) (string, bool) {
+			if line == 3 && side == SideNew {
+				return "check this", true
+			}
+			return "", false
+		},
+	)
+
+	for i, line := range append(append([]string{}, out.OldLines...), out.NewLines...) {
+		if line != stripANSI(line) {
+			t.Fatalf("expected no escape sequences in plain mode, line %d = %q", i, line)
+		}
+	}
+	if !strings.HasPrefix(out.NewLines[0], ">*") {
+		t.Fatalf("expected cursor+comment text marker, got %q", out.NewLines[0])
+	}
+	if !strings.Contains(out.NewLines[1], "[comment] check this") {
+		t.Fatalf("expected labelled inline comment, got %q", out.NewLines[1])
+	}
+	if !strings.HasPrefix(out.NewLines[2], "  ") {
+		t.Fatalf("expected blank gutter on non-cursor row, got %q", out.NewLines[2])
+	}
+}
+
+func TestParseMarkdownLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want []MarkdownSpan
+	}{
+		{"plain text", []MarkdownSpan{{Text: "plain text"}}},
+		{"use **bold** here", []MarkdownSpan{{Text: "use "}, {Text: "bold", Bold: true}, {Text: " here"}}},
+		{"call `foo()` *now*", []MarkdownSpan{{Text: "call "}, {Text: "foo()", Code: true}, {Text: " "}, {Text: "now", Italic: true}}},
+		{"- list item", []MarkdownSpan{{Text: "• list item"}}},
+		{"## Heading", []MarkdownSpan{{Text: "Heading", Bold: true}}},
+		{"keep snake_case_name and 2 * 3", []MarkdownSpan{{Text: "keep snake_case_name and 2 * 3"}}},
+		{"unmatched **bold", []MarkdownSpan{{Text: "unmatched **bold"}}},
+	}
+	for _, tt := range tests {
+		got := ParseMarkdownLine(tt.line)
+		if len(got) != len(tt.want) {
+			t.Fatalf("ParseMarkdownLine(%q) = %+v, want %+v", tt.line, got, tt.want)
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Fatalf("ParseMarkdownLine(%q)[%d] = %+v, want %+v", tt.line, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
+
+func TestRenderSplitWithLayoutCommentsRendersMarkdown(t *testing.T) {
+	rows := []DiffRow{
+		{Kind: RowChange, Path: "a.txt", OldLine: 3, NewLine: 3, OldText: "old", NewText: "new"},
+	}
+
+	out := RenderSplitWithLayoutComments(
+		rows,
+		40,
+		40,
+		0,
+		func(path string, line int, side Side) bool { return false },
+		func(path string, line int, side Side) (string, bool) {
+			if side == SideNew {
+				return "**must** fix\n- rename `x`", true
+			}
+			return "", false
+		},
+	)
+
+	if got := stripANSI(out.NewLines[1]); !strings.Contains(got, "must fix") || strings.Contains(got, "**") {
+		t.Fatalf("expected markers stripped from inline comment, got %q", got)
+	}
+	if got := stripANSI(out.NewLines[2]); !strings.Contains(got, "• rename x") {
+		t.Fatalf("expected list bullet and code span text, got %q", got)
+	}
+	for i := 1; i <= 2; i++ {
+		if lipgloss.Width(out.NewLines[i]) != 40 {
+			t.Fatalf("expected full-width comment row %d, got width=%d", i, lipgloss.Width(out.NewLines[i]))
+		}
+	}
+}
+
+func TestWrapWordsWithOffsetsBreaksAtSpaces(t *testing.T) {
+	text := "the quick brown fox   jumps supercalifragilistic"
+	chunks := wrapWordsWithOffsets(text, 10)
+	var got []string
+	for _, c := range chunks {
+		got = append(got, c.text)
+		if string([]rune(text)[c.start:c.start+len([]rune(c.text))]) != c.text {
+			t.Fatalf("chunk %q does not match its offset %d", c.text, c.start)
+		}
+	}
+	want := []string{"the quick", "brown fox", "jumps", "supercalif", "ragilistic"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("wrapWordsWithOffsets() = %q, want %q", got, want)
+	}
+}
+
+func TestRenderSplitWithOptionsWordWrapKeepsWordsWhole(t *testing.T) {
+	rows := []DiffRow{{
+		Kind:    RowChange,
+		Path:    "notes.txt",
+		OldLine: 1,
+		NewLine: 1,
+		OldText: "reviewers should read this sentence slowly",
+		NewText: "reviewers should read this paragraph slowly",
+	}}
+	opts := RenderOptions{WordWrap: true}
+	out := RenderSplitWithOptions(rows, 30, 30, -1, nil, nil, opts)
+	var lines []string
+	for _, line := range out.NewLines {
+		lines = append(lines, strings.TrimSpace(stripANSI(line)))
+	}
+	joined := strings.Join(lines, "\n")
+	if !strings.Contains(joined, "paragraph") || strings.Contains(joined, "paragr\n") {
+		t.Fatalf("expected the changed word kept whole, got:\n%s", joined)
+	}
+	for _, line := range out.NewLines {
+		if w := lipgloss.Width(line); w != 30 {
+			t.Fatalf("expected every line padded to the pane width, got %d in %q", w, stripANSI(line))
+		}
+	}
+}
+
+func TestRenderSplitWithOptionsTruncatesLongLinesUntilExpanded(t *testing.T) {
+	long := strings.Repeat("x", 40) + "TAIL"
+	rows := []DiffRow{{Kind: RowAdd, Path: "bundle.min.js", NewLine: 1, NewText: long}}
+	if !IsTruncated(rows[0], 20) || IsTruncated(rows[0], 0) || IsTruncated(rows[0], 100) {
+		t.Fatalf("unexpected IsTruncated results for a %d character line", len(long))
+	}
+
+	joined := func(opts RenderOptions) string {
+		out := RenderSplitWithOptions(rows, 40, 40, -1, nil, nil, opts)
+		var lines []string
+		for _, line := range out.NewLines {
+			lines = append(lines, stripANSI(line))
+		}
+		return strings.Join(lines, "")
+	}
+
+	opts := RenderOptions{WordWrap: true, MaxLineColumns: 20}
+	got := joined(opts)
+	if strings.Contains(got, "TAIL") || !strings.Contains(got, "(expand)") {
+		t.Fatalf("expected the line cut with an expand marker, got %q", got)
+	}
+
+	opts.LineExpanded = func(row DiffRow) bool { return true }
+	got = joined(opts)
+	if !strings.Contains(got, "TAIL") || strings.Contains(got, "(expand)") {
+		t.Fatalf("expected the expanded line in full, got %q", got)
+	}
+}
+
+func TestRenderSplitWithOptionsStylesLongRowsOnlyInsideWindow(t *testing.T) {
+	profile := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.ANSI256)
+	defer lipgloss.SetColorProfile(profile)
+
+	rows := []DiffRow{
+		{Kind: RowContext, Path: "a.txt", OldLine: 1, NewLine: 1, OldText: "short", NewText: "short"},
+		{Kind: RowAdd, Path: "a.txt", NewLine: 2, NewText: strings.Repeat("abcdefghij", 40)},
+	}
+	full := RenderSplitWithOptions(rows, 30, 30, -1, nil, nil, RenderOptions{})
+	if full.Unstyled {
+		t.Fatalf("expected every line styled without a window")
+	}
+
+	lazy := RenderSplitWithOptions(rows, 30, 30, -1, nil, nil, RenderOptions{StyleFrom: 0, StyleTo: 4})
+	if !lazy.Unstyled {
+		t.Fatalf("expected the long row's lines past the window unstyled")
+	}
+	if got, want := strings.Join(stripANSILines(lazy.NewLines), "\n"), strings.Join(stripANSILines(full.NewLines), "\n"); got != want {
+		t.Fatalf("expected the same text with and without a window, got:\n%s\nwant:\n%s", got, want)
+	}
+	for i, line := range lazy.NewLines {
+		// The gutter is always styled; the rest of a plain line is not.
+		styled := strings.Count(line, "\x1b[") > 2
+		if i < 4 && !styled {
+			t.Fatalf("expected line %d inside the window styled, got %q", i, line)
+		}
+		if i >= 4 && styled {
+			t.Fatalf("expected line %d outside the window plain, got %q", i, line)
+		}
+	}
+
+	short := RenderSplitWithOptions(rows[:1], 30, 30, -1, nil, nil, RenderOptions{StyleFrom: 5, StyleTo: 10})
+	if short.Unstyled {
+		t.Fatalf("expected rows that wrap briefly to be styled regardless of the window")
+	}
+}
+
+func TestRenderSplitWithOptionsCollapsedCommentShowsMarker(t *testing.T) {
+	rows := []DiffRow{
+		{Kind: RowChange, Path: "a.txt", OldLine: 3, NewLine: 3, OldText: "old", NewText: "new"},
+	}
+	commentText := func(path string, line int, side Side) (string, bool) {
+		if side == SideNew {
+			return "first line of a long note\nsecond line", true
+		}
+		return "", false
+	}
+
+	opts := RenderOptions{CommentPlacement: CommentsCollapsed}
+	out := RenderSplitWithOptions(rows, 40, 40, -1, nil, commentText, opts)
+	if len(out.NewLines) != 2 {
+		t.Fatalf("expected one marker line under the row, got %d lines", len(out.NewLines))
+	}
+	if got := stripANSI(out.NewLines[1]); !strings.Contains(got, "💬 1") || !strings.Contains(got, "first line") {
+		t.Fatalf("expected count marker with comment start, got %q", got)
+	}
+	if lipgloss.Width(out.NewLines[1]) != 40 {
+		t.Fatalf("expected full-width marker, got width=%d", lipgloss.Width(out.NewLines[1]))
+	}
+
+	opts.CommentExpanded = func(path string, line int, side Side) bool { return side == SideNew && line == 3 }
+	out = RenderSplitWithOptions(rows, 40, 40, -1, nil, commentText, opts)
+	if len(out.NewLines) != 3 || !strings.Contains(stripANSI(out.NewLines[2]), "second line") {
+		t.Fatalf("expected expanded comment in full, got %q", out.NewLines)
+	}
+}
+
+func TestRenderSplitWithOptionsAcrossCommentSpansBothPanes(t *testing.T) {
+	rows := []DiffRow{
+		{Kind: RowChange, Path: "a.txt", OldLine: 3, NewLine: 3, OldText: "old", NewText: "new"},
+	}
+	body := strings.Repeat("x", 50)
+	commentText := func(path string, line int, side Side) (string, bool) {
+		if side == SideNew {
+			return body, true
+		}
+		return "", false
+	}
+
+	out := RenderSplitWithOptions(rows, 40, 40, -1, nil, commentText, RenderOptions{CommentPlacement: CommentsAcross})
+	if len(out.OldLines) != 2 || len(out.NewLines) != 2 {
+		t.Fatalf("expected the comment to fit one row across both panes, got %d/%d lines", len(out.OldLines), len(out.NewLines))
+	}
+	joined := strings.TrimSpace(stripANSI(out.OldLines[1])) + strings.TrimSpace(stripANSI(out.NewLines[1]))
+	if !strings.HasSuffix(joined, body) {
+		t.Fatalf("expected the comment split between the panes, got %q", joined)
+	}
+	if lipgloss.Width(out.OldLines[1]) != 40 || lipgloss.Width(out.NewLines[1]) != 40 {
+		t.Fatalf("expected full-width comment segments, got %d/%d", lipgloss.Width(out.OldLines[1]), lipgloss.Width(out.NewLines[1]))
+	}
 }
@@ -1,5 +1,10 @@
 package diffview
 
+import (
+	"fmt"
+	"strings"
+)
+
 type Side int
 
 const (
@@ -18,12 +23,44 @@ const (
 	RowFileHeader
 )
 
+// DiffRow is one line of a side-by-side diff. Large diffs hold hundreds of
+// thousands of rows, so it keeps no pointers of its own: OldLine and NewLine
+// are 1-based with 0 meaning the side has no line, and the parser interns
+// the text so repeated lines and both sides of a context row share memory.
 type DiffRow struct {
 	Kind    RowKind
-	OldLine *int
-	NewLine *int
+	OldLine int
+	NewLine int
 	OldText string
 	NewText string
 	Path    string
 	HunkID  int
 }
+
+// NewFileRows shows content as a file added in full: one hunk of RowAdd rows.
+func NewFileRows(path string, content []byte) []DiffRow {
+	text := strings.ReplaceAll(string(content), "\r\n", "\n")
+	text = strings.TrimPrefix(strings.TrimSuffix(text, "\n"), utf8BOM)
+	lines := strings.Split(text, "\n")
+	rows := make([]DiffRow, 0, len(lines)+1)
+	rows = append(rows, DiffRow{
+		Kind:    RowHunkHeader,
+		OldText: fmt.Sprintf("@@ -0,0 +1,%d @@", len(lines)),
+		Path:    path,
+	})
+	for i, line := range lines {
+		rows = append(rows, DiffRow{
+			Kind:    RowAdd,
+			NewLine: i + 1,
+			NewText: line,
+			Path:    path,
+		})
+	}
+	return rows
+}
+
+// NoticeRows stands in for a diff that is not shown, such as a binary file,
+// with a single header row carrying text.
+func NoticeRows(path, text string) []DiffRow {
+	return []DiffRow{{Kind: RowHunkHeader, OldText: text, Path: path}}
+}
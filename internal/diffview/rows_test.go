@@ -0,0 +1,26 @@
+package diffview
+
+import "testing"
+
+func TestNewFileRows(t *testing.T) {
+	rows := NewFileRows("a.txt", []byte("\ufeffone\r\ntwo\n\nfour\n"))
+	if len(rows) != 5 {
+		t.Fatalf("expected header and 4 lines, got %d rows", len(rows))
+	}
+	if rows[0].Kind != RowHunkHeader || rows[0].OldText != "@@ -0,0 +1,4 @@" {
+		t.Fatalf("unexpected header %+v", rows[0])
+	}
+	for i, want := range []string{"one", "two", "", "four"} {
+		r := rows[i+1]
+		if r.Kind != RowAdd || r.OldLine > 0 || r.NewLine == 0 || r.NewLine != i+1 || r.NewText != want || r.Path != "a.txt" {
+			t.Fatalf("row %d: unexpected %+v", i+1, r)
+		}
+	}
+}
+
+func TestNewFileRowsWithoutTrailingNewline(t *testing.T) {
+	rows := NewFileRows("a.txt", []byte("only"))
+	if len(rows) != 2 || rows[1].NewText != "only" {
+		t.Fatalf("unexpected rows %+v", rows)
+	}
+}
@@ -0,0 +1,150 @@
+package diffview
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+var hunkHeaderRE = regexp.MustCompile(`^@@ -\d+(?:,(\d+))? \+\d+(?:,(\d+))? @@`)
+
+// DiffStream parses a unified diff a few hunks at a time, so the start of a
+// huge diff can be shown while the rest is still being parsed. It is not
+// safe for concurrent use.
+type DiffStream struct {
+	raw []byte
+	pos int
+	// header holds the lines introducing the current file, which every
+	// batch of its hunks is parsed under; fileHunks counts the hunks of the
+	// file returned so far, to keep HunkID running across batches.
+	header    []byte
+	file      int
+	fileHunks int
+	inHunks   bool
+}
+
+// NewDiffStream starts parsing raw from its first hunk.
+func NewDiffStream(raw []byte) *DiffStream {
+	return &DiffStream{raw: raw}
+}
+
+// Done reports whether every hunk has been returned by Next.
+func (s *DiffStream) Done() bool {
+	return s.pos >= len(s.raw)
+}
+
+type streamGroup struct {
+	file   int
+	header []byte
+	offset int
+	hunks  []byte
+}
+
+// Next parses whole hunks until they span at least maxLines lines or the
+// diff ends, and returns their rows.
+func (s *DiffStream) Next(maxLines int) ([]DiffRow, error) {
+	var groups []*streamGroup
+	lines := 0
+	for s.pos < len(s.raw) && (lines == 0 || lines < maxLines) {
+		line, next := s.lineAt(s.pos)
+		if !bytes.HasPrefix(line, []byte("@@")) {
+			if s.inHunks {
+				s.header = nil
+				s.file++
+				s.fileHunks = 0
+				s.inHunks = false
+			}
+			s.header = append(s.header, s.raw[s.pos:next]...)
+			s.pos = next
+			continue
+		}
+
+		end, n, err := s.hunkEnd(line, next)
+		if err != nil {
+			return nil, err
+		}
+		if len(groups) == 0 || groups[len(groups)-1].file != s.file {
+			groups = append(groups, &streamGroup{file: s.file, header: s.header, offset: s.fileHunks})
+		}
+		g := groups[len(groups)-1]
+		g.hunks = append(g.hunks, s.raw[s.pos:end]...)
+		s.fileHunks++
+		s.inHunks = true
+		lines += n
+		s.pos = end
+	}
+
+	var rows []DiffRow
+	for _, g := range groups {
+		parsed, err := parseUnifiedDiff(append(append([]byte(nil), g.header...), g.hunks...))
+		if err != nil {
+			return nil, err
+		}
+		for i := range parsed {
+			parsed[i].HunkID += g.offset
+		}
+		rows = append(rows, parsed...)
+	}
+	return rows, nil
+}
+
+// lineAt returns the line starting at pos without its line ending, and the
+// offset of the line after it.
+func (s *DiffStream) lineAt(pos int) ([]byte, int) {
+	end := bytes.IndexByte(s.raw[pos:], '\n')
+	if end < 0 {
+		return bytes.TrimSuffix(s.raw[pos:], []byte("\r")), len(s.raw)
+	}
+	return bytes.TrimSuffix(s.raw[pos:pos+end], []byte("\r")), pos + end + 1
+}
+
+// hunkEnd finds where the hunk whose header is header ends, counting its
+// body lines against the header's line counts. pos is the offset of the
+// first body line.
+func (s *DiffStream) hunkEnd(header []byte, pos int) (int, int, error) {
+	m := hunkHeaderRE.FindSubmatch(header)
+	if m == nil {
+		return 0, 0, fmt.Errorf("unexpected hunk header %q", header)
+	}
+	oldLeft, newLeft := hunkCount(m[1]), hunkCount(m[2])
+	lines := 0
+	for pos < len(s.raw) && (oldLeft > 0 || newLeft > 0) {
+		line, next := s.lineAt(pos)
+		switch {
+		case len(line) == 0 || line[0] == ' ':
+			oldLeft--
+			newLeft--
+		case line[0] == '-':
+			oldLeft--
+		case line[0] == '+':
+			newLeft--
+		case line[0] == '\\':
+		default:
+			return 0, 0, fmt.Errorf("unexpected hunk line prefix %q", line)
+		}
+		lines++
+		pos = next
+	}
+	// "\ No newline at end of file" can follow the last line.
+	for pos < len(s.raw) {
+		line, next := s.lineAt(pos)
+		if len(line) == 0 || line[0] != '\\' {
+			break
+		}
+		pos = next
+	}
+	return pos, lines, nil
+}
+
+// hunkCount reads a hunk header's line count, which defaults to 1.
+func hunkCount(b []byte) int {
+	if len(b) == 0 {
+		return 1
+	}
+	n, err := strconv.Atoi(string(b))
+	if err != nil {
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,62 @@
+package diffview
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffStreamMatchesFullParse(t *testing.T) {
+	raw := []byte(`diff --git a/a.txt b/a.txt
+index 1111111..2222222 100644
+--- a/a.txt
++++ b/a.txt
+@@ -1,3 +1,3 @@
+ one
+-two
++TWO
+ three
+@@ -10,2 +10,3 @@ func tail()
+ ten
++ten and a half
+ eleven
+diff --git a/b.txt b/b.txt
+new file mode 100644
+index 0000000..3333333
+--- /dev/null
++++ b/b.txt
+@@ -0,0 +1,2 @@
++first
++last
+\ No newline at end of file
+`)
+
+	want, err := ParseUnifiedDiff(raw)
+	if err != nil {
+		t.Fatalf("ParseUnifiedDiff() error = %v", err)
+	}
+
+	stream := NewDiffStream(raw)
+	var got []DiffRow
+	batches := 0
+	for !stream.Done() {
+		rows, err := stream.Next(1)
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		got = append(got, rows...)
+		batches++
+	}
+	if batches != 3 {
+		t.Fatalf("expected one batch per hunk, got %d", batches)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("streamed rows differ from a full parse:\ngot  %+v\nwant %+v", got, want)
+	}
+}
+
+func TestDiffStreamRejectsMalformedHunk(t *testing.T) {
+	stream := NewDiffStream([]byte("--- a/a.txt\n+++ b/a.txt\n@@ -1 +1 @@\n*bad\n"))
+	if _, err := stream.Next(100); err == nil {
+		t.Fatalf("expected an error for a bad hunk line")
+	}
+}
@@ -10,7 +10,15 @@ import (
 )
 
 // InitializeTheme configures diff colors from one of: auto, dark, light.
+// The palette follows the terminal: 16 colors when 256 are not available,
+// and bold/underline only when NO_COLOR is set.
 func InitializeTheme(mode string) {
+	InitializeThemeWithPalette(mode, PaletteDefault)
+}
+
+// InitializeThemeWithPalette is InitializeTheme with the add/delete hues
+// swapped for one of the color-blind safe palettes.
+func InitializeThemeWithPalette(mode, palette string) {
 	theme := strings.ToLower(strings.TrimSpace(mode))
 	if theme == "" {
 		theme = "auto"
@@ -26,13 +34,53 @@ func InitializeTheme(mode string) {
 		dark = detectDarkBackground()
 	}
 
-	if dark {
-		applyDarkTheme()
+	depth := detectColorDepth(lipgloss.ColorProfile(), termenv.EnvNoColor())
+	switch depth {
+	case colorDepthNone:
+		// Bold/underline already avoid hue entirely.
+		applyMonoTheme()
 		return
+	case colorDepth16:
+		applyANSITheme(dark)
+	default:
+		if dark {
+			applyDarkTheme()
+		} else {
+			applyLightTheme()
+		}
+	}
+	applyPalette(palette, dark, depth == colorDepth16)
+}
+
+type colorDepth int
+
+const (
+	colorDepthFull colorDepth = iota
+	colorDepth16
+	colorDepthNone
+)
+
+// detectColorDepth picks the palette size. A bare Ascii profile (not a TTY,
+// TERM=dumb) keeps the full palette since lipgloss strips it anyway; only an
+// explicit NO_COLOR switches to attribute-only styles.
+func detectColorDepth(profile termenv.Profile, noColor bool) colorDepth {
+	if noColor {
+		return colorDepthNone
+	}
+	if profile == termenv.ANSI {
+		return colorDepth16
 	}
-	applyLightTheme()
+	return colorDepthFull
 }
 
+// noColorRenderer emits bold/underline under NO_COLOR, where the default
+// renderer drops every escape sequence. Its styles never carry colors.
+var noColorRenderer = func() *lipgloss.Renderer {
+	r := lipgloss.NewRenderer(os.Stdout)
+	r.SetColorProfile(termenv.ANSI)
+	return r
+}()
+
 func detectDarkBackground() bool {
 	if dark, ok := darkFromColorFGBG(os.Getenv("COLORFGBG")); ok {
 		return dark
@@ -98,6 +146,7 @@ func applyDarkTheme() {
 	addWordStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("121")).Background(lipgloss.Color("22")).Bold(true)
 	deleteWordStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("210")).Background(lipgloss.Color("52")).Bold(true)
 	cursorRowBg = lipgloss.Color("236")
+	selectionRowBg = lipgloss.Color("238")
 
 	cursorGutterStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("16")).Background(lipgloss.Color("45")).Bold(true)
 	commentGutterStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("16")).Background(lipgloss.Color("220")).Bold(true)
@@ -134,6 +183,7 @@ func applyLightTheme() {
 	addWordStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("22")).Background(lipgloss.Color("121")).Bold(true)
 	deleteWordStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("88")).Background(lipgloss.Color("217")).Bold(true)
 	cursorRowBg = lipgloss.Color("254")
+	selectionRowBg = lipgloss.Color("252")
 
 	cursorGutterStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("255")).Background(lipgloss.Color("25")).Bold(true)
 	commentGutterStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("16")).Background(lipgloss.Color("220")).Bold(true)
@@ -158,3 +208,90 @@ func applyLightTheme() {
 	syntaxOperatorColor = lipgloss.Color("161")
 	syntaxPreprocessorColor = lipgloss.Color("89")
 }
+
+func applyANSITheme(dark bool) {
+	// Bright shades read better on dark backgrounds, normal ones on light.
+	green, red, yellow, cyan := lipgloss.Color("10"), lipgloss.Color("9"), lipgloss.Color("11"), lipgloss.Color("14")
+	text, cursorBg, selectionBg := lipgloss.Color("15"), lipgloss.Color("8"), lipgloss.Color("4")
+	if !dark {
+		green, red, yellow, cyan = lipgloss.Color("2"), lipgloss.Color("1"), lipgloss.Color("3"), lipgloss.Color("6")
+		text, cursorBg, selectionBg = lipgloss.Color("0"), lipgloss.Color("7"), lipgloss.Color("14")
+	}
+
+	// Backgrounds are kept to the gutter; 16-color row fills are too loud to read.
+	addBaseStyle = lipgloss.NewStyle().Foreground(green)
+	deleteBaseStyle = lipgloss.NewStyle().Foreground(red)
+	changeOldBaseStyle = lipgloss.NewStyle().Foreground(red)
+	changeNewBaseStyle = lipgloss.NewStyle().Foreground(green)
+	contextBaseStyle = lipgloss.NewStyle()
+	hunkBaseStyle = lipgloss.NewStyle().Foreground(cyan).Bold(true)
+
+	addWordStyle = lipgloss.NewStyle().Foreground(green).Bold(true).Underline(true)
+	deleteWordStyle = lipgloss.NewStyle().Foreground(red).Bold(true).Underline(true)
+	cursorRowBg = cursorBg
+	selectionRowBg = selectionBg
+
+	cursorGutterStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("0")).Background(cyan).Bold(true)
+	commentGutterStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("0")).Background(yellow).Bold(true)
+	cursorCommentGutterStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("0")).Background(lipgloss.Color("13")).Bold(true)
+	addGutterStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("0")).Background(green).Bold(true)
+	deleteGutterStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("0")).Background(red).Bold(true)
+	changeOldGutterStyle = lipgloss.NewStyle().Foreground(red).Bold(true)
+	changeNewGutterStyle = lipgloss.NewStyle().Foreground(green).Bold(true)
+	addMetaStyle = lipgloss.NewStyle().Foreground(green).Bold(true)
+	deleteMetaStyle = lipgloss.NewStyle().Foreground(red).Bold(true)
+	changeOldMetaStyle = lipgloss.NewStyle().Foreground(red)
+	changeNewMetaStyle = lipgloss.NewStyle().Foreground(green)
+
+	commentInlineTextStyle = lipgloss.NewStyle().Foreground(text).Italic(true)
+
+	syntaxKeywordColor = lipgloss.Color("13")
+	syntaxStringColor = yellow
+	syntaxCommentColor = lipgloss.Color("8")
+	syntaxTypeColor = cyan
+	syntaxFunctionColor = lipgloss.Color("12")
+	syntaxNumberColor = yellow
+	syntaxOperatorColor = lipgloss.Color("5")
+	syntaxPreprocessorColor = lipgloss.Color("5")
+}
+
+// applyMonoTheme tells adds and deletes apart by bold and underline alone.
+func applyMonoTheme() {
+	r := noColorRenderer
+	none := lipgloss.Color("")
+
+	addBaseStyle = r.NewStyle().Bold(true)
+	deleteBaseStyle = r.NewStyle().Underline(true)
+	changeOldBaseStyle = r.NewStyle().Underline(true)
+	changeNewBaseStyle = r.NewStyle().Bold(true)
+	contextBaseStyle = r.NewStyle()
+	hunkBaseStyle = r.NewStyle().Bold(true)
+
+	addWordStyle = r.NewStyle().Bold(true).Reverse(true)
+	deleteWordStyle = r.NewStyle().Underline(true).Reverse(true)
+	cursorRowBg = none
+	selectionRowBg = none
+
+	cursorGutterStyle = r.NewStyle().Reverse(true).Bold(true)
+	commentGutterStyle = r.NewStyle().Bold(true)
+	cursorCommentGutterStyle = r.NewStyle().Reverse(true).Bold(true).Underline(true)
+	addGutterStyle = r.NewStyle().Bold(true)
+	deleteGutterStyle = r.NewStyle().Underline(true)
+	changeOldGutterStyle = r.NewStyle().Underline(true)
+	changeNewGutterStyle = r.NewStyle().Bold(true)
+	addMetaStyle = r.NewStyle().Bold(true)
+	deleteMetaStyle = r.NewStyle().Underline(true)
+	changeOldMetaStyle = r.NewStyle().Underline(true)
+	changeNewMetaStyle = r.NewStyle().Bold(true)
+
+	commentInlineTextStyle = r.NewStyle().Italic(true)
+
+	syntaxKeywordColor = none
+	syntaxStringColor = none
+	syntaxCommentColor = none
+	syntaxTypeColor = none
+	syntaxFunctionColor = none
+	syntaxNumberColor = none
+	syntaxOperatorColor = none
+	syntaxPreprocessorColor = none
+}
@@ -1,9 +1,11 @@
 package diffview
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 )
 
 func TestDarkFromColorFGBG(t *testing.T) {
@@ -46,3 +48,78 @@ func TestInitializeThemeExplicitModes(t *testing.T) {
 		t.Fatalf("dark theme did not set expected cursor row color")
 	}
 }
+
+func TestDetectColorDepth(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile termenv.Profile
+		noColor bool
+		want    colorDepth
+	}{
+		{name: "truecolor", profile: termenv.TrueColor, want: colorDepthFull},
+		{name: "256 colors", profile: termenv.ANSI256, want: colorDepthFull},
+		{name: "16 colors", profile: termenv.ANSI, want: colorDepth16},
+		{name: "not a terminal", profile: termenv.Ascii, want: colorDepthFull},
+		{name: "no color wins", profile: termenv.TrueColor, noColor: true, want: colorDepthNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectColorDepth(tt.profile, tt.noColor); got != tt.want {
+				t.Fatalf("detectColorDepth(%v, %v) = %v want %v", tt.profile, tt.noColor, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyANSIThemeUsesSixteenColorPalette(t *testing.T) {
+	defer applyDarkTheme()
+
+	applyANSITheme(true)
+	if addBaseStyle.GetForeground() != lipgloss.Color("10") || deleteBaseStyle.GetForeground() != lipgloss.Color("9") {
+		t.Fatalf("dark 16-color theme should use bright green/red for adds/deletes")
+	}
+	applyANSITheme(false)
+	if addBaseStyle.GetForeground() != lipgloss.Color("2") || deleteBaseStyle.GetForeground() != lipgloss.Color("1") {
+		t.Fatalf("light 16-color theme should use green/red for adds/deletes")
+	}
+}
+
+func TestApplyMonoThemeKeepsAddsAndDeletesDistinct(t *testing.T) {
+	defer applyDarkTheme()
+
+	applyMonoTheme()
+	added := addBaseStyle.Render("x")
+	deleted := deleteBaseStyle.Render("x")
+	if added == deleted {
+		t.Fatalf("expected add and delete rows to render differently, both got %q", added)
+	}
+	if strings.Contains(added, "38;") || strings.Contains(deleted, "38;") {
+		t.Fatalf("expected no foreground colors under NO_COLOR, got %q / %q", added, deleted)
+	}
+}
+
+func TestApplyPaletteReplacesRedGreen(t *testing.T) {
+	defer func() {
+		applyDarkTheme()
+		applyPalette(PaletteDefault, true, false)
+	}()
+
+	for _, palette := range []string{PaletteDeuteranopia, PaletteProtanopia} {
+		applyDarkTheme()
+		applyPalette(palette, true, false)
+		if addBaseStyle.GetForeground() == lipgloss.Color("78") || deleteBaseStyle.GetForeground() == lipgloss.Color("203") {
+			t.Fatalf("%s palette kept the red/green add/delete colors", palette)
+		}
+		add, del, _ := MarkerColors()
+		if add != addBaseStyle.GetForeground() || del != deleteBaseStyle.GetForeground() {
+			t.Fatalf("%s palette marker colors do not match diff colors", palette)
+		}
+	}
+
+	applyDarkTheme()
+	applyPalette(PaletteDefault, true, false)
+	if addBaseStyle.GetForeground() != lipgloss.Color("78") {
+		t.Fatalf("default palette should keep the theme colors")
+	}
+}
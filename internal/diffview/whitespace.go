@@ -0,0 +1,89 @@
+package diffview
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// WhitespaceBadge marks the header of a hunk whose changes are only
+// whitespace or line endings.
+const WhitespaceBadge = "[ws-only]"
+
+// WhitespaceOnlyHunks returns the row indexes of the headers of hunks whose
+// changed lines, taken together, differ only in whitespace or line endings,
+// such as reindented blocks or a line split in two.
+func WhitespaceOnlyHunks(rows []DiffRow) map[int]bool {
+	out := make(map[int]bool)
+	header := -1
+	changed := false
+	var oldLines, newLines []string
+	flush := func() {
+		if header >= 0 && changed && sameIgnoringSpace(oldLines, newLines) {
+			out[header] = true
+		}
+	}
+	for i, row := range rows {
+		switch row.Kind {
+		case RowHunkHeader, RowFileHeader:
+			flush()
+			header, changed = -1, false
+			if row.Kind == RowHunkHeader {
+				header = i
+			}
+			oldLines, newLines = oldLines[:0], newLines[:0]
+		case RowDelete, RowAdd, RowChange:
+			changed = true
+			if row.OldLine > 0 {
+				oldLines = append(oldLines, row.OldText)
+			}
+			if row.NewLine > 0 {
+				newLines = append(newLines, row.NewText)
+			}
+		}
+	}
+	flush()
+	return out
+}
+
+// sameIgnoringSpace reports whether two runs of lines hold the same text once
+// all whitespace, including the line breaks between them, is dropped.
+func sameIgnoringSpace(a, b []string) bool {
+	ra, rb := newSpaceSkipper(a), newSpaceSkipper(b)
+	for {
+		ca, okA := ra.next()
+		cb, okB := rb.next()
+		if okA != okB || ca != cb {
+			return false
+		}
+		if !okA {
+			return true
+		}
+	}
+}
+
+// spaceSkipper reads the non-space runes of a run of lines.
+type spaceSkipper struct {
+	lines []string
+	line  int
+	pos   int
+}
+
+func newSpaceSkipper(lines []string) *spaceSkipper {
+	return &spaceSkipper{lines: lines}
+}
+
+func (s *spaceSkipper) next() (rune, bool) {
+	for s.line < len(s.lines) {
+		text := s.lines[s.line]
+		for s.pos < len(text) {
+			r, size := utf8.DecodeRuneInString(text[s.pos:])
+			s.pos += size
+			if !unicode.IsSpace(r) {
+				return r, true
+			}
+		}
+		s.line++
+		s.pos = 0
+	}
+	return 0, false
+}
@@ -0,0 +1,22 @@
+package git
+
+import (
+	"context"
+
+	"diffman/internal/util"
+)
+
+// ReadBlob returns the content of path, relative to the repository root cwd,
+// at rev (such as "HEAD"), or in the index when rev is empty. ok is false
+// when path does not exist there, as for files added since.
+func ReadBlob(ctx context.Context, cwd, rev, path string) (content []byte, ok bool, err error) {
+	object := rev + ":" + path
+	if _, err := util.Run(ctx, cwd, "git", "cat-file", "-e", object); err != nil {
+		return nil, false, nil
+	}
+	out, err := util.Run(ctx, cwd, "git", "cat-file", "blob", object)
+	if err != nil {
+		return nil, false, err
+	}
+	return []byte(out), true, nil
+}
@@ -0,0 +1,56 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadBlob(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if os.Getenv("GIT_DIR") != "" {
+		t.Skip("GIT_DIR is set")
+	}
+	root := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = root
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v (%s)", args, err, out)
+		}
+	}
+	write := func(name, body string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(root, name), []byte(body), 0o644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+	write("a.txt", "committed\n")
+	run("init", "-q")
+	run("add", ".")
+	run("commit", "-q", "-m", "init")
+	write("a.txt", "staged\n")
+	write("new file.txt", "new\n")
+	run("add", ".")
+	write("a.txt", "working\n")
+
+	for _, tc := range []struct {
+		rev, path, want string
+		ok              bool
+	}{
+		{"HEAD", "a.txt", "committed\n", true},
+		{"", "a.txt", "staged\n", true},
+		{"", "new file.txt", "new\n", true},
+		{"HEAD", "new file.txt", "", false},
+	} {
+		got, ok, err := ReadBlob(t.Context(), root, tc.rev, tc.path)
+		if err != nil || ok != tc.ok || string(got) != tc.want {
+			t.Fatalf("ReadBlob(%q, %q) = %q, %v, %v; want %q, %v", tc.rev, tc.path, got, ok, err, tc.want, tc.ok)
+		}
+	}
+}
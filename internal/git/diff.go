@@ -3,7 +3,9 @@ package git
 import (
 	"context"
 	"errors"
-	"os/exec"
+	"fmt"
+	"os"
+	"slices"
 	"strings"
 
 	"diffman/internal/util"
@@ -32,52 +34,112 @@ func (m DiffMode) String() string {
 
 type DiffService interface {
 	Diff(ctx context.Context, cwd, path string, mode DiffMode) (string, error)
+	// DiffRename diffs a renamed or copied file against the path it came
+	// from, so git pairs the two sides instead of showing an add.
+	DiffRename(ctx context.Context, cwd, origPath, path string, mode DiffMode) (string, error)
+	// Untracked reads path when git does not track it yet; ok is false for
+	// tracked, ignored, and missing paths.
+	Untracked(ctx context.Context, cwd, path string) (file UntrackedFile, ok bool, err error)
 }
 
-type diffService struct{}
+type diffService struct {
+	excludes []string
+}
 
-func NewDiffService() DiffService {
-	return diffService{}
+// NewDiffService diffs files, treating paths that match the exclude
+// patterns (see excludePathspecs) as unchanged.
+func NewDiffService(excludes ...string) DiffService {
+	return diffService{excludes: excludePathspecs(excludes)}
 }
 
-func (diffService) Diff(ctx context.Context, cwd, path string, mode DiffMode) (string, error) {
-	args := []string{"diff", "-U3", "--", path}
-	switch mode {
-	case DiffModeAll:
-		args = []string{"diff", "HEAD", "-U3", "--", path}
-	case DiffModeStaged:
-		args = []string{"diff", "--cached", "-U3", "--", path}
-	case DiffModeUnstaged:
-		args = []string{"diff", "-U3", "--", path}
-	}
+// Diff returns git's unified diff for path, or "" when git has none. Untracked
+// files have no git diff; read them with Untracked.
+func (s diffService) Diff(ctx context.Context, cwd, path string, mode DiffMode) (string, error) {
+	return runDiff(ctx, cwd, append(diffArgs(mode, path), s.excludes...))
+}
 
-	out, err := util.Run(ctx, cwd, "git", args...)
-	if err != nil {
-		return "", err
+// DiffNoIndex compares two files or directories that need not be in a
+// repository, as git diff --no-index does.
+func DiffNoIndex(ctx context.Context, oldPath, newPath string) (string, error) {
+	// git reports a missing path with the same exit status as a difference.
+	for _, p := range []string{oldPath, newPath} {
+		if _, err := os.Stat(p); err != nil {
+			return "", err
+		}
 	}
-	if strings.TrimSpace(out) != "" {
-		return out, nil
+	out, err := util.Run(ctx, "", "git", "diff", "--no-index", "-U3", "--", oldPath, newPath)
+	// git diff --no-index exits 1 when the paths differ.
+	var cmdErr *util.CommandError
+	if errors.As(err, &cmdErr) && cmdErr.ExitCode() == 1 {
+		return cmdErr.Output, nil
 	}
+	return out, err
+}
 
-	if mode == DiffModeStaged {
-		return "", nil
+// DiffRefs compares path as it was at two revisions, such as a tag before a
+// refactor and HEAD. A path missing at one of them shows as added or deleted.
+func DiffRefs(ctx context.Context, cwd, from, to, path string) (string, error) {
+	// An unknown revision would otherwise be taken for a path, and one
+	// starting with a dash for an option.
+	for _, rev := range []string{from, to} {
+		if strings.HasPrefix(rev, "-") {
+			return "", fmt.Errorf("unknown revision %q", rev)
+		}
+		if _, err := util.Run(ctx, cwd, "git", "rev-parse", "--verify", "-q", rev+"^{commit}"); err != nil {
+			return "", fmt.Errorf("unknown revision %q", rev)
+		}
 	}
+	return util.Run(ctx, cwd, "git", "diff", "-U3", from, to, "--", path)
+}
+
+func (s diffService) DiffRename(ctx context.Context, cwd, origPath, path string, mode DiffMode) (string, error) {
+	args := append(diffArgs(mode, origPath, path), s.excludes...)
+	// Copies keep their source, so ask for them explicitly.
+	args = append([]string{args[0], "-M", "-C"}, args[1:]...)
+	return runDiff(ctx, cwd, args)
+}
 
-	// Fallback for untracked paths; --no-index returns exit code 1 when diff exists.
-	cmd := exec.CommandContext(ctx, "git", "diff", "--no-index", "--", "/dev/null", path)
-	if cwd != "" {
-		cmd.Dir = cwd
+// runDiff runs git with args. Before the first commit there is no HEAD to
+// diff against, so a failed diff against HEAD compares with the empty tree.
+func runDiff(ctx context.Context, cwd string, args []string) (string, error) {
+	out, err := util.Run(ctx, cwd, "git", args...)
+	if err == nil {
+		return out, nil
 	}
-	noIndexOut, noIndexErr := cmd.CombinedOutput()
-	if noIndexErr == nil {
-		return string(noIndexOut), nil
+	opts := args
+	if dash := slices.Index(args, "--"); dash >= 0 {
+		opts = args[:dash]
+	}
+	i := slices.Index(opts, "HEAD")
+	if i < 0 || hasHead(ctx, cwd) {
+		return "", err
 	}
+	fallback := slices.Clone(args)
+	fallback[i] = emptyTree
+	return util.Run(ctx, cwd, "git", fallback...)
+}
 
-	var exitErr *exec.ExitError
-	if errors.As(noIndexErr, &exitErr) && exitErr.ExitCode() == 1 {
-		return string(noIndexOut), nil
+func diffArgs(mode DiffMode, paths ...string) []string {
+	var args []string
+	switch mode {
+	case DiffModeAll:
+		args = []string{"diff", "HEAD", "-U3"}
+	case DiffModeStaged:
+		args = []string{"diff", "--cached", "-U3"}
+	default:
+		args = []string{"diff", "-U3"}
 	}
+	return append(append(args, "--"), paths...)
+}
 
-	// If the path isn't an untracked file (or can't be diffed this way), treat as no diff.
-	return "", nil
+func (s diffService) Untracked(ctx context.Context, cwd, path string) (UntrackedFile, bool, error) {
+	args := append([]string{"ls-files", "--others", "--exclude-standard", "-z", "--", ":(literal)" + path}, s.excludes...)
+	out, err := util.Run(ctx, cwd, "git", args...)
+	if err != nil {
+		return UntrackedFile{}, false, err
+	}
+	if strings.TrimRight(out, "\x00") != path {
+		return UntrackedFile{}, false, nil
+	}
+	return ReadUntracked(cwd, path)
 }
@@ -0,0 +1,88 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiffNoIndexComparesPathsOutsideARepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	oldPath, newPath := filepath.Join(dir, "old.txt"), filepath.Join(dir, "new.txt")
+	if err := os.WriteFile(oldPath, []byte("one\ntwo\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := os.WriteFile(newPath, []byte("one\nTWO\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	d, err := DiffNoIndex(t.Context(), oldPath, newPath)
+	if err != nil {
+		t.Fatalf("DiffNoIndex() error = %v", err)
+	}
+	if !strings.Contains(d, "-two\n") || !strings.Contains(d, "+TWO\n") {
+		t.Fatalf("unexpected diff:\n%s", d)
+	}
+
+	if d, err := DiffNoIndex(t.Context(), oldPath, oldPath); err != nil || d != "" {
+		t.Fatalf("expected no diff for identical paths, got %q (err=%v)", d, err)
+	}
+	if _, err := DiffNoIndex(t.Context(), oldPath, filepath.Join(dir, "missing")); err == nil {
+		t.Fatalf("expected an error for a missing path")
+	}
+}
+
+func TestDiffRefsComparesAPathBetweenRevisions(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if os.Getenv("GIT_DIR") != "" {
+		t.Skip("GIT_DIR is set")
+	}
+	root := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = root
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v (%s)", args, err, out)
+		}
+	}
+	write := func(body string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(root, "a.txt"), []byte(body), 0o644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+	write("one\ntwo\n")
+	run("init", "-q")
+	run("add", ".")
+	run("commit", "-q", "-m", "first")
+	run("tag", "before")
+	write("one\nTWO\n")
+	run("commit", "-q", "-am", "second")
+	// The working file differs from both revisions and must not show.
+	write("one\nthree\n")
+
+	d, err := DiffRefs(t.Context(), root, "before", "HEAD", "a.txt")
+	if err != nil {
+		t.Fatalf("DiffRefs() error = %v", err)
+	}
+	if !strings.Contains(d, "-two\n") || !strings.Contains(d, "+TWO\n") || strings.Contains(d, "three") {
+		t.Fatalf("unexpected diff:\n%s", d)
+	}
+	if d, err := DiffRefs(t.Context(), root, "HEAD", "HEAD", "a.txt"); err != nil || d != "" {
+		t.Fatalf("expected no diff between the same revision, got %q (err=%v)", d, err)
+	}
+	for _, rev := range []string{"missing", "--output=x"} {
+		if _, err := DiffRefs(t.Context(), root, rev, "HEAD", "a.txt"); err == nil || !strings.Contains(err.Error(), "unknown revision") {
+			t.Fatalf("DiffRefs(%q) error = %v, want unknown revision", rev, err)
+		}
+	}
+}
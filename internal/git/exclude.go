@@ -0,0 +1,19 @@
+package git
+
+import "strings"
+
+// excludePathspecs turns exclude patterns such as "dist/**" into pathspecs
+// git applies itself, so excluded trees are never statted or diffed.
+// Patterns that already are pathspecs (starting with ":") pass through.
+// Patterns are relative to the repository root.
+func excludePathspecs(patterns []string) []string {
+	out := make([]string, 0, len(patterns))
+	for _, p := range patterns {
+		if strings.HasPrefix(p, ":") {
+			out = append(out, p)
+			continue
+		}
+		out = append(out, ":(top,exclude)"+p)
+	}
+	return out
+}
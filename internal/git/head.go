@@ -0,0 +1,54 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"diffman/internal/util"
+)
+
+// emptyTree is the id of git's empty tree, which stands in for HEAD before
+// the first commit.
+const emptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// HeadInfo describes the checked-out HEAD of a repository.
+type HeadInfo struct {
+	Branch   string
+	ShortSHA string
+	// Detached is set when HEAD is a commit rather than a branch, and
+	// Unborn when the branch has no commits yet.
+	Detached bool
+	Unborn   bool
+}
+
+func ReadHead(ctx context.Context, cwd string) (HeadInfo, error) {
+	var head HeadInfo
+	branch, err := util.Run(ctx, cwd, "git", "symbolic-ref", "--short", "-q", "HEAD")
+	if err != nil {
+		// symbolic-ref -q exits 1 without a message when HEAD is detached.
+		var cmdErr *util.CommandError
+		if !errors.As(err, &cmdErr) || cmdErr.ExitCode() != 1 {
+			return HeadInfo{}, err
+		}
+		head.Detached = true
+	}
+	head.Branch = strings.TrimSpace(branch)
+	sha, err := util.Run(ctx, cwd, "git", "rev-parse", "--short", "-q", "--verify", "HEAD")
+	if err != nil {
+		if head.Detached {
+			return HeadInfo{}, err
+		}
+		head.Unborn = true
+		return head, nil
+	}
+	head.ShortSHA = strings.TrimSpace(sha)
+	return head, nil
+}
+
+// hasHead reports whether HEAD names a commit, which it does not before the
+// first commit.
+func hasHead(ctx context.Context, cwd string) bool {
+	_, err := util.Run(ctx, cwd, "git", "rev-parse", "--verify", "-q", "HEAD")
+	return err == nil
+}
@@ -0,0 +1,57 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadHeadAndDiffBeforeFirstCommitAndWhenDetached(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if os.Getenv("GIT_DIR") != "" {
+		t.Skip("GIT_DIR is set")
+	}
+	root := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...)
+		cmd.Dir = root
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v (%s)", args, err, out)
+		}
+	}
+	run("init", "-q", "-b", "main")
+	if err := os.WriteFile(filepath.Join(root, "a.txt"), []byte("one\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	run("add", "a.txt")
+
+	head, err := ReadHead(t.Context(), root)
+	if err != nil {
+		t.Fatalf("ReadHead() error = %v", err)
+	}
+	if head != (HeadInfo{Branch: "main", Unborn: true}) {
+		t.Fatalf("unexpected unborn head: %+v", head)
+	}
+	d, err := NewDiffService().Diff(t.Context(), root, "a.txt", DiffModeAll)
+	if err != nil {
+		t.Fatalf("Diff() before the first commit error = %v", err)
+	}
+	if !strings.Contains(d, "+one") {
+		t.Fatalf("expected the staged file as an addition, got:\n%s", d)
+	}
+
+	run("commit", "-q", "-m", "init")
+	run("checkout", "-q", "--detach")
+	head, err = ReadHead(t.Context(), root)
+	if err != nil {
+		t.Fatalf("ReadHead() error = %v", err)
+	}
+	if !head.Detached || head.Branch != "" || head.ShortSHA == "" || head.Unborn {
+		t.Fatalf("unexpected detached head: %+v", head)
+	}
+}
@@ -0,0 +1,20 @@
+package git
+
+import (
+	"context"
+
+	"diffman/internal/util"
+)
+
+// AddToIndex stages path with git add or, with intentOnly, records only the
+// intent to add it (git add -N), so its lines show in unstaged diffs
+// without staging its content.
+func AddToIndex(ctx context.Context, cwd, path string, intentOnly bool) error {
+	args := []string{"add"}
+	if intentOnly {
+		args = append(args, "-N")
+	}
+	args = append(args, "--", ":(literal)"+path)
+	_, err := util.Run(ctx, cwd, "git", args...)
+	return err
+}
@@ -0,0 +1,49 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestAddToIndex(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if os.Getenv("GIT_DIR") != "" {
+		t.Skip("GIT_DIR is set")
+	}
+	root := t.TempDir()
+	cmd := exec.Command("git", "init", "-q")
+	cmd.Dir = root
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v (%s)", err, out)
+	}
+	for _, name := range []string{"staged.txt", "intent.txt"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte("x\n"), 0o644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+
+	if err := AddToIndex(t.Context(), root, "staged.txt", false); err != nil {
+		t.Fatalf("AddToIndex() error = %v", err)
+	}
+	if err := AddToIndex(t.Context(), root, "intent.txt", true); err != nil {
+		t.Fatalf("AddToIndex(intent) error = %v", err)
+	}
+	items, err := NewStatusService().ListChangedFiles(t.Context(), root)
+	if err != nil {
+		t.Fatalf("ListChangedFiles() error = %v", err)
+	}
+	got := map[string]FileItem{}
+	for _, it := range items {
+		got[it.Path] = it
+	}
+	if it := got["staged.txt"]; !it.HasStaged || it.HasUnstaged {
+		t.Fatalf("staged.txt = %+v, want staged only", it)
+	}
+	if it := got["intent.txt"]; it.HasStaged || !it.HasUnstaged || it.Status == "??" {
+		t.Fatalf("intent.txt = %+v, want an unstaged tracked file", it)
+	}
+}
@@ -0,0 +1,59 @@
+package git
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// NestedRepoDepth bounds how many directory levels FindRepos descends below
+// its root, so starting in a large workspace stays fast.
+const NestedRepoDepth = 4
+
+// skippedRepoDirs are never searched for nested repositories, along with
+// hidden directories such as .git itself.
+var skippedRepoDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+}
+
+// FindRepos lists root and the directories below it that are the top of a
+// git working tree (they contain a .git directory or, for submodules and
+// linked worktrees, a .git file). Paths are absolute and sorted; unreadable
+// directories are skipped.
+func FindRepos(root string, maxDepth int) ([]string, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	var repos []string
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return fs.SkipDir
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && (skippedRepoDirs[d.Name()] || strings.HasPrefix(d.Name(), ".")) {
+			return fs.SkipDir
+		}
+		if _, err := os.Lstat(filepath.Join(path, ".git")); err == nil {
+			repos = append(repos, path)
+		}
+		rel, _ := filepath.Rel(root, path)
+		if rel != "." && strings.Count(rel, string(filepath.Separator))+1 >= maxDepth {
+			return fs.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(repos)
+	return repos, nil
+}
@@ -0,0 +1,43 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFindRepos(t *testing.T) {
+	root := t.TempDir()
+	mkdir := func(parts ...string) string {
+		dir := filepath.Join(append([]string{root}, parts...)...)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("MkdirAll() error = %v", err)
+		}
+		return dir
+	}
+	mkdir("api", ".git")
+	mkdir("web", ".git")
+	mkdir("web", "plugins", "auth", ".git")
+	mkdir("node_modules", "dep", ".git")
+	mkdir(".cache", "clone", ".git")
+	mkdir("a", "b", "c", "d", "deep", ".git")
+	sub := mkdir("libs", "sub")
+	if err := os.WriteFile(filepath.Join(sub, ".git"), []byte("gitdir: ../../.git/modules/sub\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	got, err := FindRepos(root, NestedRepoDepth)
+	if err != nil {
+		t.Fatalf("FindRepos() error = %v", err)
+	}
+	want := []string{
+		filepath.Join(root, "api"),
+		filepath.Join(root, "libs", "sub"),
+		filepath.Join(root, "web"),
+		filepath.Join(root, "web", "plugins", "auth"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("FindRepos() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,116 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"diffman/internal/util"
+)
+
+// FileStat counts the lines a file's changes add and delete.
+type FileStat struct {
+	Added   int
+	Deleted int
+	// Binary reports a file git has no line counts for.
+	Binary bool
+	// WhitespaceOnly reports changes that only touch whitespace or line
+	// endings.
+	WhitespaceOnly bool
+}
+
+// DiffStats counts the added and deleted lines of every tracked file with
+// changes in mode, keyed by path. Renamed files are keyed by their new path.
+// Untracked files have no entry. WhitespaceOnly is only filled in when
+// whitespace is set, as finding it takes a second git diff.
+func (s statusService) DiffStats(ctx context.Context, cwd string, mode DiffMode, whitespace bool) (map[string]FileStat, error) {
+	var args []string
+	switch mode {
+	case DiffModeAll:
+		base := "HEAD"
+		if !hasHead(ctx, cwd) {
+			base = emptyTree
+		}
+		args = []string{"diff", base}
+	case DiffModeStaged:
+		args = []string{"diff", "--cached"}
+	default:
+		args = []string{"diff"}
+	}
+	args = append(args, "--numstat", "-z", "-M")
+	stats, err := s.numstat(ctx, cwd, args)
+	if err != nil || !whitespace {
+		return stats, err
+	}
+	// Ignoring whitespace, git leaves out the files that only change it.
+	changed, err := s.numstat(ctx, cwd, append(slices.Clone(args), "-w"))
+	if err != nil {
+		return nil, err
+	}
+	for path, stat := range stats {
+		if stat.Binary || stat.Added+stat.Deleted == 0 {
+			continue
+		}
+		if other, ok := changed[path]; !ok || other.Added+other.Deleted == 0 {
+			stat.WhitespaceOnly = true
+			stats[path] = stat
+		}
+	}
+	return stats, nil
+}
+
+func (s statusService) numstat(ctx context.Context, cwd string, args []string) (map[string]FileStat, error) {
+	out, err := util.Run(ctx, cwd, "git", s.pathspecArgs(args)...)
+	if err != nil {
+		return nil, err
+	}
+	return parseNumstatZ(out)
+}
+
+// parseNumstatZ reads git diff --numstat -z output, whose records are
+// "added\tdeleted\tpath\x00", or "added\tdeleted\t\x00orig\x00path\x00" for
+// a rename. Binary files count "-" for both.
+func parseNumstatZ(out string) (map[string]FileStat, error) {
+	stats := make(map[string]FileStat)
+	fields := strings.Split(out, "\x00")
+	for i := 0; i < len(fields); i++ {
+		rec := fields[i]
+		if rec == "" {
+			continue
+		}
+		added, rest, ok := strings.Cut(rec, "\t")
+		if !ok {
+			return nil, fmt.Errorf("unexpected numstat record %q", rec)
+		}
+		deleted, path, ok := strings.Cut(rest, "\t")
+		if !ok {
+			return nil, fmt.Errorf("unexpected numstat record %q", rec)
+		}
+		if path == "" {
+			if i+2 >= len(fields) {
+				return nil, fmt.Errorf("truncated numstat rename record %q", rec)
+			}
+			path = fields[i+2]
+			i += 2
+		}
+		var stat FileStat
+		if added == "-" && deleted == "-" {
+			stat.Binary = true
+		} else {
+			a, err := strconv.Atoi(added)
+			if err != nil {
+				return nil, fmt.Errorf("unexpected numstat count %q", added)
+			}
+			d, err := strconv.Atoi(deleted)
+			if err != nil {
+				return nil, fmt.Errorf("unexpected numstat count %q", deleted)
+			}
+			stat.Added, stat.Deleted = a, d
+		}
+		stats[filepath.ToSlash(path)] = stat
+	}
+	return stats, nil
+}
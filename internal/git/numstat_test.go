@@ -0,0 +1,110 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseNumstatZRecords(t *testing.T) {
+	out := strings.Join([]string{
+		"3\t1\tsrc/main.go",
+		"-\t-\tlogo.png",
+		"2\t0\t", "old name.go", "new name.go",
+	}, "\x00") + "\x00"
+	stats, err := parseNumstatZ(out)
+	if err != nil {
+		t.Fatalf("parseNumstatZ() error = %v", err)
+	}
+	if got := stats["src/main.go"]; got != (FileStat{Added: 3, Deleted: 1}) {
+		t.Fatalf("unexpected stat for src/main.go: %+v", got)
+	}
+	if got := stats["logo.png"]; !got.Binary {
+		t.Fatalf("expected logo.png to be binary, got %+v", got)
+	}
+	if got, ok := stats["new name.go"]; !ok || got.Added != 2 {
+		t.Fatalf("expected rename to be keyed by its new path, got %+v", stats)
+	}
+	if len(stats) != 3 {
+		t.Fatalf("expected 3 stats, got %+v", stats)
+	}
+}
+
+func TestDiffStatsCountsChangesAgainstHead(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if os.Getenv("GIT_DIR") != "" {
+		t.Skip("GIT_DIR is set")
+	}
+	root := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...)
+		cmd.Dir = root
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v (%s)", args, err, out)
+		}
+	}
+	write := func(name, body string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(root, name), []byte(body), 0o644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+	run("init", "-q")
+	write("a.txt", "one\n")
+
+	// An unborn HEAD is diffed against the empty tree.
+	run("add", "a.txt")
+	stats, err := NewStatusService().DiffStats(t.Context(), root, DiffModeAll, false)
+	if err != nil {
+		t.Fatalf("DiffStats() error = %v", err)
+	}
+	if got := stats["a.txt"]; got != (FileStat{Added: 1}) {
+		t.Fatalf("unexpected stat before the first commit: %+v", stats)
+	}
+
+	run("commit", "-q", "-m", "init")
+	write("a.txt", "ONE\ntwo\n")
+	write("new.txt", "untracked\n")
+	stats, err = NewStatusService().DiffStats(t.Context(), root, DiffModeAll, false)
+	if err != nil {
+		t.Fatalf("DiffStats() error = %v", err)
+	}
+	if got := stats["a.txt"]; got != (FileStat{Added: 2, Deleted: 1}) {
+		t.Fatalf("unexpected stat for a.txt: %+v", stats)
+	}
+	if _, ok := stats["new.txt"]; ok {
+		t.Fatalf("expected no stat for an untracked file, got %+v", stats)
+	}
+
+	run("add", "a.txt")
+	write("a.txt", "ONE\ntwo\nthree\n")
+	stats, err = NewStatusService().DiffStats(t.Context(), root, DiffModeUnstaged, false)
+	if err != nil {
+		t.Fatalf("DiffStats() error = %v", err)
+	}
+	if got := stats["a.txt"]; got != (FileStat{Added: 1}) {
+		t.Fatalf("unexpected unstaged stat for a.txt: %+v", stats)
+	}
+
+	run("commit", "-q", "-am", "second")
+	write("a.txt", "ONE\r\n  two\nthree\n")
+	stats, err = NewStatusService().DiffStats(t.Context(), root, DiffModeAll, false)
+	if err != nil {
+		t.Fatalf("DiffStats() error = %v", err)
+	}
+	if got := stats["a.txt"]; got != (FileStat{Added: 2, Deleted: 2}) {
+		t.Fatalf("expected no whitespace check unless asked: %+v", stats)
+	}
+	stats, err = NewStatusService().DiffStats(t.Context(), root, DiffModeAll, true)
+	if err != nil {
+		t.Fatalf("DiffStats() error = %v", err)
+	}
+	if got := stats["a.txt"]; got != (FileStat{Added: 2, Deleted: 2, WhitespaceOnly: true}) {
+		t.Fatalf("unexpected whitespace-only stat for a.txt: %+v", stats)
+	}
+}
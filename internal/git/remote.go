@@ -0,0 +1,69 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"diffman/internal/util"
+)
+
+// Remote is a parsed remote URL: the host and the repository path on it, such as "org/repo".
+type Remote struct {
+	Host string
+	Path string
+}
+
+// ReadOriginRemote parses the URL of the origin remote.
+func ReadOriginRemote(ctx context.Context, cwd string) (Remote, error) {
+	out, err := util.Run(ctx, cwd, "git", "config", "--get", "remote.origin.url")
+	if err != nil {
+		return Remote{}, err
+	}
+	return ParseRemoteURL(out)
+}
+
+// ReadHeadSHA returns the full commit SHA of HEAD.
+func ReadHeadSHA(ctx context.Context, cwd string) (string, error) {
+	out, err := util.Run(ctx, cwd, "git", "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// ParseRemoteURL understands scp-like ("git@host:org/repo.git"), ssh://, and http(s):// remotes.
+func ParseRemoteURL(raw string) (Remote, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return Remote{}, fmt.Errorf("remote url is empty")
+	}
+
+	var host, path string
+	if !strings.Contains(raw, "://") {
+		at := strings.Index(raw, "@")
+		colon := strings.Index(raw, ":")
+		if colon < 0 || colon < at {
+			return Remote{}, fmt.Errorf("unsupported remote url %q", raw)
+		}
+		host, path = raw[at+1:colon], raw[colon+1:]
+	} else {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return Remote{}, fmt.Errorf("parse remote url: %w", err)
+		}
+		switch u.Scheme {
+		case "http", "https", "ssh", "git":
+		default:
+			return Remote{}, fmt.Errorf("unsupported remote url %q", raw)
+		}
+		host, path = u.Hostname(), u.Path
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if host == "" || !strings.Contains(path, "/") {
+		return Remote{}, fmt.Errorf("unsupported remote url %q", raw)
+	}
+	return Remote{Host: strings.ToLower(host), Path: path}, nil
+}
@@ -2,6 +2,7 @@ package git
 
 import (
 	"context"
+	"errors"
 	"strings"
 
 	"diffman/internal/util"
@@ -22,3 +23,18 @@ func DiscoverGitDir(ctx context.Context, cwd string) (string, error) {
 	}
 	return strings.TrimSpace(out), nil
 }
+
+// UserName returns the user.name git commits as in the repository at cwd, or
+// "" when it is not set.
+func UserName(ctx context.Context, cwd string) (string, error) {
+	out, err := util.Run(ctx, cwd, "git", "config", "--get", "user.name")
+	// git config --get exits 1 when the key is not set.
+	var cmdErr *util.CommandError
+	if errors.As(err, &cmdErr) && cmdErr.ExitCode() == 1 {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
@@ -0,0 +1,30 @@
+package git
+
+import (
+	"context"
+
+	"diffman/internal/util"
+)
+
+// RestoreToHead discards the staged and unstaged changes to paths, leaving
+// them as they are in HEAD. Tracked paths that HEAD lacks, such as newly
+// added files, are removed; before the first commit that is every path.
+func RestoreToHead(ctx context.Context, cwd string, paths ...string) error {
+	source := "HEAD"
+	if !hasHead(ctx, cwd) {
+		source = emptyTree
+	}
+	args := []string{"restore", "--source=" + source, "--staged", "--worktree", "--"}
+	for _, p := range paths {
+		args = append(args, ":(literal)"+p)
+	}
+	_, err := util.Run(ctx, cwd, "git", args...)
+	return err
+}
+
+// RemoveUntracked deletes the untracked file at path, like git clean for a
+// single path; tracked and ignored files are left alone.
+func RemoveUntracked(ctx context.Context, cwd, path string) error {
+	_, err := util.Run(ctx, cwd, "git", "clean", "-f", "-q", "--", ":(literal)"+path)
+	return err
+}
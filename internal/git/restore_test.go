@@ -0,0 +1,115 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestRestoreToHead(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if os.Getenv("GIT_DIR") != "" {
+		t.Skip("GIT_DIR is set")
+	}
+	root := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = root
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v (%s)", args, err, out)
+		}
+	}
+	write := func(name, body string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(root, name), []byte(body), 0o644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+	write("a.txt", "committed\n")
+	run("init", "-q")
+	run("add", ".")
+	run("commit", "-q", "-m", "init")
+	write("a.txt", "staged\n")
+	write("added.txt", "new\n")
+	run("add", ".")
+	write("a.txt", "working\n")
+
+	if err := RestoreToHead(t.Context(), root, "a.txt", "added.txt"); err != nil {
+		t.Fatalf("RestoreToHead() error = %v", err)
+	}
+	if b, err := os.ReadFile(filepath.Join(root, "a.txt")); err != nil || string(b) != "committed\n" {
+		t.Fatalf("a.txt = %q, %v; want the committed content", b, err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "added.txt")); !os.IsNotExist(err) {
+		t.Fatalf("added.txt should be removed, stat error = %v", err)
+	}
+	items, err := NewStatusService().ListChangedFiles(t.Context(), root)
+	if err != nil || len(items) != 0 {
+		t.Fatalf("changed files = %+v, %v; want none", items, err)
+	}
+}
+
+func TestRemoveUntracked(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if os.Getenv("GIT_DIR") != "" {
+		t.Skip("GIT_DIR is set")
+	}
+	root := t.TempDir()
+	cmd := exec.Command("git", "init", "-q")
+	cmd.Dir = root
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v (%s)", err, out)
+	}
+	for _, name := range []string{"junk.log", "other.log"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte("x\n"), 0o644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+
+	if err := RemoveUntracked(t.Context(), root, "junk.log"); err != nil {
+		t.Fatalf("RemoveUntracked() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "junk.log")); !os.IsNotExist(err) {
+		t.Fatalf("junk.log should be removed, stat error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "other.log")); err != nil {
+		t.Fatalf("other.log should be kept: %v", err)
+	}
+}
+
+func TestRestoreToHeadBeforeFirstCommitRemovesAddedFile(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if os.Getenv("GIT_DIR") != "" {
+		t.Skip("GIT_DIR is set")
+	}
+	root := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = root
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v (%s)", args, err, out)
+		}
+	}
+	run("init", "-q")
+	if err := os.WriteFile(filepath.Join(root, "new.txt"), []byte("x\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	run("add", "new.txt")
+
+	if err := RestoreToHead(t.Context(), root, "new.txt"); err != nil {
+		t.Fatalf("RestoreToHead() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "new.txt")); !os.IsNotExist(err) {
+		t.Fatalf("expected new.txt to be removed, got %v", err)
+	}
+}
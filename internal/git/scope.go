@@ -0,0 +1,85 @@
+package git
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Scope limits a review to some paths of the repository, relative to its
+// root, such as a few packages of a monorepo. A directory covers everything
+// under it. An entry with glob characters is a pattern over the whole path
+// in which, as in exclude patterns, "*" also matches "/". An empty Scope
+// covers the whole repository.
+type Scope []string
+
+// Contains reports whether the file at p, relative to the repository root,
+// is in the scope.
+func (s Scope) Contains(p string) bool {
+	if len(s) == 0 {
+		return true
+	}
+	for _, entry := range s {
+		if isGlob(entry) {
+			if globRegexp(entry).MatchString(p) {
+				return true
+			}
+			continue
+		}
+		if entry == "." || p == entry || strings.HasPrefix(p, entry+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// pathspecs passes the scope to git, anchored at the repository root.
+func (s Scope) pathspecs() []string {
+	out := make([]string, 0, len(s))
+	for _, entry := range s {
+		if isGlob(entry) {
+			out = append(out, ":(top)"+entry)
+			continue
+		}
+		out = append(out, ":(top,literal)"+entry)
+	}
+	return out
+}
+
+func isGlob(p string) bool {
+	return strings.ContainsAny(p, "*?[")
+}
+
+// globRegexp matches the way git matches a pattern pathspec: fnmatch over
+// the whole path, with "*" and "?" also matching "/".
+func globRegexp(pattern string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return regexp.MustCompile(`^` + regexp.QuoteMeta(pattern) + `$`)
+	}
+	return re
+}
@@ -0,0 +1,76 @@
+package git
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"diffman/internal/util"
+)
+
+// Sparse describes a sparse checkout. In cone mode Dirs lists the cone's
+// directories; otherwise git's patterns decide and only the skip-worktree
+// flag tells which files are left out.
+type Sparse struct {
+	Cone bool
+	Dirs []string
+}
+
+// ReadSparse returns the repository's sparse checkout; ok is false when it
+// does not use one.
+func ReadSparse(ctx context.Context, cwd string) (sparse Sparse, ok bool, err error) {
+	// git config exits non-zero when the key is unset.
+	if out, err := util.Run(ctx, cwd, "git", "config", "--bool", "core.sparseCheckout"); err != nil || strings.TrimSpace(out) != "true" {
+		return Sparse{}, false, nil
+	}
+	out, _ := util.Run(ctx, cwd, "git", "config", "--bool", "core.sparseCheckoutCone")
+	if strings.TrimSpace(out) != "true" {
+		return Sparse{}, true, nil
+	}
+	out, err = util.Run(ctx, cwd, "git", "sparse-checkout", "list")
+	if err != nil {
+		return Sparse{}, false, err
+	}
+	sparse.Cone = true
+	for _, line := range strings.Split(out, "\n") {
+		if dir := strings.Trim(strings.TrimSpace(line), "/"); dir != "" {
+			sparse.Dirs = append(sparse.Dirs, dir)
+		}
+	}
+	return sparse, true, nil
+}
+
+// Contains reports whether a cone mode checkout includes path: files at the
+// root, in or below a cone directory, or directly in one of its parents.
+// Outside cone mode it reports true.
+func (s Sparse) Contains(p string) bool {
+	if !s.Cone {
+		return true
+	}
+	dir := path.Dir(p)
+	if dir == "." {
+		return true
+	}
+	for _, d := range s.Dirs {
+		if dir == d || strings.HasPrefix(dir, d+"/") || strings.HasPrefix(d, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// MaterializeTarget is what git sparse-checkout add needs to check out p:
+// its directory in cone mode, or a pattern for the file itself otherwise.
+func (s Sparse) MaterializeTarget(p string) string {
+	if s.Cone {
+		return path.Dir(p)
+	}
+	return "/" + p
+}
+
+// SparseCheckoutAdd widens the sparse checkout by target, a cone directory
+// or pattern from MaterializeTarget, checking out the files it covers.
+func SparseCheckoutAdd(ctx context.Context, cwd, target string) error {
+	_, err := util.Run(ctx, cwd, "git", "sparse-checkout", "add", target)
+	return err
+}
@@ -0,0 +1,94 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestSparseContains(t *testing.T) {
+	s := Sparse{Cone: true, Dirs: []string{"app/web"}}
+	for path, want := range map[string]bool{
+		"README.md":          true,
+		"app/go.mod":         true,
+		"app/web/index.html": true,
+		"app/web/js/main.js": true,
+		"app/api/server.go":  false,
+		"docs/guide.md":      false,
+	} {
+		if got := s.Contains(path); got != want {
+			t.Fatalf("Contains(%q) = %v, want %v", path, got, want)
+		}
+	}
+	if !(Sparse{}).Contains("docs/guide.md") {
+		t.Fatalf("non-cone checkouts should leave the decision to skip-worktree")
+	}
+	if got := s.MaterializeTarget("docs/guide.md"); got != "docs" {
+		t.Fatalf("MaterializeTarget() = %q, want docs", got)
+	}
+}
+
+func TestListChangedFilesMarksFilesOutsideSparseCheckout(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if os.Getenv("GIT_DIR") != "" {
+		t.Skip("GIT_DIR is set")
+	}
+	root := t.TempDir()
+	git := func(args ...string) ([]byte, error) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = root
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com")
+		return cmd.CombinedOutput()
+	}
+	run := func(args ...string) {
+		t.Helper()
+		if out, err := git(args...); err != nil {
+			t.Fatalf("git %v: %v (%s)", args, err, out)
+		}
+	}
+	for _, name := range []string{"a/x.txt", "b/y.txt"} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("MkdirAll() error = %v", err)
+		}
+		if err := os.WriteFile(path, []byte("one\n"), 0o644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+	run("init", "-q")
+	run("add", ".")
+	run("commit", "-q", "-m", "init")
+	if out, err := git("sparse-checkout", "set", "--cone", "a"); err != nil {
+		t.Skipf("git sparse-checkout unsupported: %v (%s)", err, out)
+	}
+	if err := os.WriteFile(filepath.Join(root, "a/x.txt"), []byte("two\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	run("rm", "-q", "--cached", "--sparse", "b/y.txt")
+
+	items, err := NewStatusService().ListChangedFiles(t.Context(), root)
+	if err != nil {
+		t.Fatalf("ListChangedFiles() error = %v", err)
+	}
+	got := map[string]bool{}
+	for _, it := range items {
+		got[it.Path] = it.OutsideSparse
+	}
+	if outside, ok := got["a/x.txt"]; !ok || outside {
+		t.Fatalf("a/x.txt should be listed inside the cone: %+v", items)
+	}
+	if outside, ok := got["b/y.txt"]; !ok || !outside {
+		t.Fatalf("b/y.txt should be listed outside the cone: %+v", items)
+	}
+
+	if err := SparseCheckoutAdd(t.Context(), root, "b"); err != nil {
+		t.Fatalf("SparseCheckoutAdd() error = %v", err)
+	}
+	sparse, ok, err := ReadSparse(t.Context(), root)
+	if err != nil || !ok || !sparse.Contains("b/y.txt") {
+		t.Fatalf("ReadSparse() = %+v, %v, %v; want b in the cone", sparse, ok, err)
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"path/filepath"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 
 	"diffman/internal/util"
@@ -12,24 +15,77 @@ import (
 
 // FileItem is one changed file from git status.
 type FileItem struct {
-	Path        string
-	Status      string
+	Path   string
+	Status string
+	// OrigPath is the path a renamed or copied file came from.
+	OrigPath string
+	// Similarity is git's rename or copy score, 0-100.
+	Similarity  int
 	HasStaged   bool
 	HasUnstaged bool
+	// SkipWorktree and AssumeUnchanged report the index flags of the same
+	// names, which make git ignore the file's working tree changes.
+	SkipWorktree    bool
+	AssumeUnchanged bool
+	// OutsideSparse reports a tracked file the sparse checkout leaves out,
+	// whose working tree copy is usually missing.
+	OutsideSparse bool
+}
+
+// IndexFlags names the file's skip-worktree and assume-unchanged flags,
+// or returns "" when neither is set.
+func (f FileItem) IndexFlags() string {
+	switch {
+	case f.OutsideSparse:
+		return "outside sparse checkout"
+	case f.SkipWorktree && f.AssumeUnchanged:
+		return "skip-worktree, assume-unchanged"
+	case f.SkipWorktree:
+		return "skip-worktree"
+	case f.AssumeUnchanged:
+		return "assume-unchanged"
+	}
+	return ""
+}
+
+// Renamed reports whether the file was renamed or copied from OrigPath.
+func (f FileItem) Renamed() bool {
+	return f.OrigPath != ""
 }
 
 type StatusService interface {
 	ListChangedFiles(ctx context.Context, cwd string) ([]FileItem, error)
+	DiffStats(ctx context.Context, cwd string, mode DiffMode, whitespace bool) (map[string]FileStat, error)
 }
 
-type statusService struct{}
+type statusService struct {
+	scope    Scope
+	excludes []string
+}
+
+// NewStatusService lists changed files, leaving out paths that match the
+// exclude patterns (see excludePathspecs).
+func NewStatusService(excludes ...string) StatusService {
+	return NewScopedStatusService(nil, excludes...)
+}
+
+// NewScopedStatusService is NewStatusService for the files in scope only.
+func NewScopedStatusService(scope Scope, excludes ...string) StatusService {
+	return statusService{scope: scope, excludes: excludePathspecs(excludes)}
+}
 
-func NewStatusService() StatusService {
-	return statusService{}
+// pathspecArgs ends a git command line with the scope and the excludes.
+func (s statusService) pathspecArgs(args []string) []string {
+	if len(s.scope) == 0 && len(s.excludes) == 0 {
+		return args
+	}
+	args = append(slices.Clone(args), "--")
+	return append(append(args, s.scope.pathspecs()...), s.excludes...)
 }
 
-func (statusService) ListChangedFiles(ctx context.Context, cwd string) ([]FileItem, error) {
-	out, err := util.Run(ctx, cwd, "git", "status", "--porcelain=v2", "--untracked-files=all", "-z")
+func (s statusService) ListChangedFiles(ctx context.Context, cwd string) ([]FileItem, error) {
+	args := s.pathspecArgs([]string{"status", "--porcelain=v2", "--untracked-files=all", "-z"})
+	out, err := util.Run(ctx, cwd, "git", args...)
 	if err != nil {
 		return nil, err
 	}
@@ -39,6 +95,23 @@ func (statusService) ListChangedFiles(ctx context.Context, cwd string) ([]FileIt
 		return nil, err
 	}
 
+	if err := markIndexFlags(ctx, cwd, items); err != nil {
+		return nil, err
+	}
+	sparse, ok, err := ReadSparse(ctx, cwd)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		for i, it := range items {
+			outside := it.SkipWorktree
+			if sparse.Cone {
+				outside = !sparse.Contains(it.Path)
+			}
+			items[i].OutsideSparse = it.Status != "??" && outside
+		}
+	}
+
 	sort.Slice(items, func(i, j int) bool {
 		return items[i].Path < items[j].Path
 	})
@@ -46,6 +119,42 @@ func (statusService) ListChangedFiles(ctx context.Context, cwd string) ([]FileIt
 	return items, nil
 }
 
+// indexFlagBatch caps how many paths one git ls-files call is given.
+const indexFlagBatch = 500
+
+// markIndexFlags sets SkipWorktree and AssumeUnchanged on the tracked items
+// from git ls-files -v, whose tag is S for skip-worktree and lower case for
+// assume-unchanged.
+func markIndexFlags(ctx context.Context, cwd string, items []FileItem) error {
+	byPath := make(map[string]int, len(items))
+	var paths []string
+	for i, it := range items {
+		if it.Status == "??" {
+			continue
+		}
+		byPath[it.Path] = i
+		paths = append(paths, ":(literal)"+it.Path)
+	}
+	for len(paths) > 0 {
+		batch := paths[:min(len(paths), indexFlagBatch)]
+		paths = paths[len(batch):]
+		out, err := util.Run(ctx, cwd, "git", append([]string{"ls-files", "-v", "-z", "--"}, batch...)...)
+		if err != nil {
+			return err
+		}
+		for _, rec := range strings.Split(out, "\x00") {
+			tag, path, ok := strings.Cut(rec, " ")
+			i, found := byPath[path]
+			if !ok || !found || tag == "" {
+				continue
+			}
+			items[i].SkipWorktree = tag == "S" || tag == "s"
+			items[i].AssumeUnchanged = tag != strings.ToUpper(tag)
+		}
+	}
+	return nil
+}
+
 func parsePorcelainV2Z(data []byte) ([]FileItem, error) {
 	records := bytes.Split(data, []byte{0})
 	items := make([]FileItem, 0, len(records))
@@ -57,29 +166,44 @@ func parsePorcelainV2Z(data []byte) ([]FileItem, error) {
 		}
 
 		switch rec[0] {
-		case '1', 'u':
-			fields := strings.Fields(rec)
-			if len(fields) < 2 {
+		case '1':
+			// 1 <XY> <sub> <mH> <mI> <mW> <hH> <hI> <path>
+			fields := strings.SplitN(rec, " ", 9)
+			if len(fields) < 9 {
 				return nil, fmt.Errorf("unexpected porcelain record: %q", rec)
 			}
-			path := fields[len(fields)-1]
-			item := itemFromXY(path, fields[1])
-			items = append(items, item)
+			items = append(items, itemFromXY(fields[8], fields[1]))
+
+		case 'u':
+			// u <XY> <sub> <m1> <m2> <m3> <mW> <h1> <h2> <h3> <path>
+			fields := strings.SplitN(rec, " ", 11)
+			if len(fields) < 11 {
+				return nil, fmt.Errorf("unexpected unmerged record: %q", rec)
+			}
+			items = append(items, itemFromXY(fields[10], fields[1]))
 
 		case '2':
-			fields := strings.Fields(rec)
-			if len(fields) < 2 {
+			// 2 <XY> <sub> <mH> <mI> <mW> <hH> <hI> <X><score> <path>, then
+			// the original path as its own NUL-terminated record.
+			fields := strings.SplitN(rec, " ", 10)
+			if len(fields) < 10 {
 				return nil, fmt.Errorf("unexpected rename/copy record: %q", rec)
 			}
-			path := fields[len(fields)-1]
-			item := itemFromXY(path, fields[1])
-			items = append(items, item)
-			if i+1 < len(records) {
-				i++ // consume the original path record emitted for -z rename/copy entries
+			score, err := parseRenameScore(fields[8])
+			if err != nil {
+				return nil, fmt.Errorf("unexpected rename/copy record: %q: %w", rec, err)
 			}
+			if i+1 >= len(records) || len(records[i+1]) == 0 {
+				return nil, fmt.Errorf("rename/copy record without original path: %q", rec)
+			}
+			i++
+			item := itemFromXY(fields[9], fields[1])
+			item.OrigPath = filepath.ToSlash(string(records[i]))
+			item.Similarity = score
+			items = append(items, item)
 
 		case '?':
-			path := strings.TrimPrefix(rec, "? ")
+			path := filepath.ToSlash(strings.TrimPrefix(rec, "? "))
 			items = append(items, FileItem{
 				Path:        path,
 				Status:      "??",
@@ -110,9 +234,21 @@ func itemFromXY(path, xy string) FileItem {
 	}
 
 	return FileItem{
-		Path:        path,
+		Path:        filepath.ToSlash(path),
 		Status:      status,
 		HasStaged:   hasStaged,
 		HasUnstaged: hasUnstaged,
 	}
 }
+
+// parseRenameScore reads a score field such as "R100" or "C75".
+func parseRenameScore(field string) (int, error) {
+	if len(field) < 2 || (field[0] != 'R' && field[0] != 'C') {
+		return 0, fmt.Errorf("bad score %q", field)
+	}
+	score, err := strconv.Atoi(field[1:])
+	if err != nil || score < 0 || score > 100 {
+		return 0, fmt.Errorf("bad score %q", field)
+	}
+	return score, nil
+}
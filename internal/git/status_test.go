@@ -0,0 +1,237 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParsePorcelainV2ZRecords(t *testing.T) {
+	records := []string{
+		"# branch.oid 1111",
+		"1 .M N... 100644 100644 100644 aaaa aaaa docs/release notes.md",
+		"2 R. N... 100644 100644 100644 bbbb bbbb R87 src/new name.go",
+		"src/old name.go",
+		"2 C. N... 100644 100644 100644 cccc cccc C100 lib/copy.go",
+		"lib/orig.go",
+		"u UU N... 100644 100644 100644 100644 dddd eeee ffff merge me.txt",
+		"? scratch file.txt",
+		"! ignored.log",
+	}
+	items, err := parsePorcelainV2Z([]byte(strings.Join(records, "\x00") + "\x00"))
+	if err != nil {
+		t.Fatalf("parsePorcelainV2Z() error = %v", err)
+	}
+	if len(items) != 5 {
+		t.Fatalf("expected 5 items, got %+v", items)
+	}
+	if items[0].Path != "docs/release notes.md" || items[0].Status != ".M" || items[0].Renamed() {
+		t.Fatalf("unexpected ordinary record %+v", items[0])
+	}
+	if got := items[1]; got.Path != "src/new name.go" || got.OrigPath != "src/old name.go" || got.Similarity != 87 || got.Status != "R." || !got.HasStaged {
+		t.Fatalf("unexpected rename record %+v", got)
+	}
+	if got := items[2]; got.Path != "lib/copy.go" || got.OrigPath != "lib/orig.go" || got.Similarity != 100 {
+		t.Fatalf("unexpected copy record %+v", got)
+	}
+	if got := items[3]; got.Path != "merge me.txt" || got.Status != "UU" {
+		t.Fatalf("unexpected unmerged record %+v", got)
+	}
+	if got := items[4]; got.Path != "scratch file.txt" || got.Status != "??" {
+		t.Fatalf("unexpected untracked record %+v", got)
+	}
+}
+
+func TestParsePorcelainV2ZRejectsMalformedRenames(t *testing.T) {
+	for name, data := range map[string]string{
+		"missing original path": "2 R. N... 100644 100644 100644 bbbb bbbb R87 new.go\x00",
+		"bad score":             "2 R. N... 100644 100644 100644 bbbb bbbb X87 new.go\x00old.go\x00",
+		"short record":          "2 R. N... R87 new.go\x00old.go\x00",
+	} {
+		if _, err := parsePorcelainV2Z([]byte(data)); err == nil {
+			t.Fatalf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestStatusAndDiffFollowStagedRename(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if os.Getenv("GIT_DIR") != "" {
+		t.Skip("GIT_DIR is set")
+	}
+	root := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = root
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v (%s)", args, err, out)
+		}
+	}
+	body := "one\ntwo\nthree\nfour\nfive\nsix\n"
+	if err := os.WriteFile(filepath.Join(root, "old name.txt"), []byte(body), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	run("init", "-q")
+	run("add", ".")
+	run("commit", "-q", "-m", "init")
+	run("mv", "old name.txt", "new name.txt")
+	if err := os.WriteFile(filepath.Join(root, "new name.txt"), []byte(strings.Replace(body, "three", "THREE", 1)), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	run("add", "new name.txt")
+
+	items, err := NewStatusService().ListChangedFiles(t.Context(), root)
+	if err != nil {
+		t.Fatalf("ListChangedFiles() error = %v", err)
+	}
+	if len(items) != 1 || items[0].Path != "new name.txt" || items[0].OrigPath != "old name.txt" || items[0].Similarity == 0 {
+		t.Fatalf("expected a single rename item, got %+v", items)
+	}
+
+	d, err := NewDiffService().DiffRename(t.Context(), root, "old name.txt", "new name.txt", DiffModeAll)
+	if err != nil {
+		t.Fatalf("DiffRename() error = %v", err)
+	}
+	if !strings.Contains(d, "rename from old name.txt") || !strings.Contains(d, "-three") || !strings.Contains(d, "+THREE") {
+		t.Fatalf("expected a rename diff, got:\n%s", d)
+	}
+}
+
+func TestListChangedFilesHonorsExcludes(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if os.Getenv("GIT_DIR") != "" {
+		t.Skip("GIT_DIR is set")
+	}
+	root := t.TempDir()
+	cmd := exec.Command("git", "init", "-q")
+	cmd.Dir = root
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v (%s)", err, out)
+	}
+	for _, name := range []string{"main.go", "dist/app.js", "dist/deep/chunk.js", "vendor.min.js"} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("MkdirAll() error = %v", err)
+		}
+		if err := os.WriteFile(path, []byte("x\n"), 0o644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+
+	items, err := NewStatusService("dist/**", ":(exclude)*.min.js").ListChangedFiles(t.Context(), root)
+	if err != nil {
+		t.Fatalf("ListChangedFiles() error = %v", err)
+	}
+	if len(items) != 1 || items[0].Path != "main.go" {
+		t.Fatalf("items = %+v, want only main.go", items)
+	}
+	if _, ok, err := NewDiffService("dist/**").Untracked(t.Context(), root, "dist/app.js"); err != nil || ok {
+		t.Fatalf("Untracked(excluded) ok = %v, err = %v; want not found", ok, err)
+	}
+}
+
+func TestListChangedFilesHonorsScope(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if os.Getenv("GIT_DIR") != "" {
+		t.Skip("GIT_DIR is set")
+	}
+	root := t.TempDir()
+	cmd := exec.Command("git", "init", "-q")
+	cmd.Dir = root
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v (%s)", err, out)
+	}
+	for _, name := range []string{"main.go", "internal/app/a.go", "internal/app/gen.pb.go", "cmd/tool/main.go", "docs/x.md"} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("MkdirAll() error = %v", err)
+		}
+		if err := os.WriteFile(path, []byte("x\n"), 0o644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+
+	scope := Scope{"internal", "cmd/*"}
+	items, err := NewScopedStatusService(scope, "*.pb.go").ListChangedFiles(t.Context(), root)
+	if err != nil {
+		t.Fatalf("ListChangedFiles() error = %v", err)
+	}
+	var got []string
+	for _, it := range items {
+		got = append(got, it.Path)
+		if !scope.Contains(it.Path) {
+			t.Fatalf("Contains(%q) = false for a listed file", it.Path)
+		}
+	}
+	if strings.Join(got, " ") != "cmd/tool/main.go internal/app/a.go" {
+		t.Fatalf("items = %v, want the scoped files without the excluded one", got)
+	}
+	for _, p := range []string{"main.go", "docs/x.md", "internals/a.go"} {
+		if scope.Contains(p) {
+			t.Fatalf("Contains(%q) = true, want false", p)
+		}
+	}
+}
+
+func TestListChangedFilesMarksIndexFlags(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if os.Getenv("GIT_DIR") != "" {
+		t.Skip("GIT_DIR is set")
+	}
+	root := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = root
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v (%s)", args, err, out)
+		}
+	}
+	write := func(name, body string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(root, name), []byte(body), 0o644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+	for _, name := range []string{"plain.txt", "skip.txt", "assume.txt"} {
+		write(name, "one\n")
+	}
+	run("init", "-q")
+	run("add", ".")
+	run("commit", "-q", "-m", "init")
+	// Staged changes stay visible after the flags are set.
+	for _, name := range []string{"plain.txt", "skip.txt", "assume.txt"} {
+		write(name, "two\n")
+	}
+	run("add", ".")
+	run("update-index", "--skip-worktree", "skip.txt")
+	run("update-index", "--assume-unchanged", "assume.txt")
+
+	items, err := NewStatusService().ListChangedFiles(t.Context(), root)
+	if err != nil {
+		t.Fatalf("ListChangedFiles() error = %v", err)
+	}
+	got := map[string]string{}
+	for _, it := range items {
+		got[it.Path] = it.IndexFlags()
+	}
+	want := map[string]string{"plain.txt": "", "skip.txt": "skip-worktree", "assume.txt": "assume-unchanged"}
+	for path, flags := range want {
+		if g, ok := got[path]; !ok || g != flags {
+			t.Fatalf("%s flags = %q (listed %v), want %q; all %+v", path, g, ok, flags, items)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package git
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// MaxUntrackedBytes is the largest untracked file whose content is shown.
+const MaxUntrackedBytes = 1 << 20
+
+// binarySniffBytes is how much of a file is checked for NUL bytes, the same
+// heuristic git uses to call a file binary.
+const binarySniffBytes = 8000
+
+// UntrackedFile is a file git does not track yet. Content is empty when the
+// file is binary or larger than MaxUntrackedBytes.
+type UntrackedFile struct {
+	Path     string
+	Size     int64
+	Binary   bool
+	TooLarge bool
+	Content  []byte
+}
+
+// ReadUntracked reads path relative to root. Symlinks are read as their
+// target, like git shows them; directories (such as nested repositories)
+// report ok false.
+func ReadUntracked(root, path string) (file UntrackedFile, ok bool, err error) {
+	full := filepath.Join(root, filepath.FromSlash(path))
+	info, err := os.Lstat(full)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return UntrackedFile{}, false, nil
+		}
+		return UntrackedFile{}, false, err
+	}
+	file = UntrackedFile{Path: path, Size: info.Size()}
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(full)
+		if err != nil {
+			return UntrackedFile{}, false, err
+		}
+		file.Content = []byte(target)
+		return file, true, nil
+	case !info.Mode().IsRegular():
+		return UntrackedFile{}, false, nil
+	}
+
+	f, err := os.Open(full)
+	if err != nil {
+		return UntrackedFile{}, false, err
+	}
+	defer f.Close()
+
+	// Sniff first so a large binary file is reported as binary.
+	head := make([]byte, binarySniffBytes)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return UntrackedFile{}, false, err
+	}
+	head = head[:n]
+	if bytes.IndexByte(head, 0) >= 0 {
+		file.Binary = true
+		return file, true, nil
+	}
+	if info.Size() > MaxUntrackedBytes {
+		file.TooLarge = true
+		return file, true, nil
+	}
+	rest, err := io.ReadAll(f)
+	if err != nil {
+		return UntrackedFile{}, false, err
+	}
+	file.Content = append(head, rest...)
+	return file, true, nil
+}
@@ -0,0 +1,88 @@
+package git
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadUntracked(t *testing.T) {
+	root := t.TempDir()
+	write := func(name string, data []byte) {
+		if err := os.WriteFile(filepath.Join(root, name), data, 0o644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+	write("notes.txt", []byte("one\ntwo\n"))
+	write("logo.png", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"))
+	write("dump.log", bytes.Repeat([]byte("line\n"), MaxUntrackedBytes/5+1))
+	if err := os.Mkdir(filepath.Join(root, "vendored"), 0o755); err != nil {
+		t.Fatalf("Mkdir() error = %v", err)
+	}
+	if err := os.Symlink("notes.txt", filepath.Join(root, "link")); err != nil {
+		t.Fatalf("Symlink() error = %v", err)
+	}
+
+	file, ok, err := ReadUntracked(root, "notes.txt")
+	if err != nil || !ok || string(file.Content) != "one\ntwo\n" || file.Binary || file.TooLarge {
+		t.Fatalf("text file: %+v ok=%v err=%v", file, ok, err)
+	}
+	file, ok, err = ReadUntracked(root, "logo.png")
+	if err != nil || !ok || !file.Binary || len(file.Content) != 0 {
+		t.Fatalf("binary file: %+v ok=%v err=%v", file, ok, err)
+	}
+	file, ok, err = ReadUntracked(root, "dump.log")
+	if err != nil || !ok || !file.TooLarge || len(file.Content) != 0 || file.Size <= MaxUntrackedBytes {
+		t.Fatalf("large file: size=%d tooLarge=%v ok=%v err=%v", file.Size, file.TooLarge, ok, err)
+	}
+	file, ok, err = ReadUntracked(root, "link")
+	if err != nil || !ok || string(file.Content) != "notes.txt" {
+		t.Fatalf("symlink: %+v ok=%v err=%v", file, ok, err)
+	}
+	if _, ok, err := ReadUntracked(root, "vendored"); ok || err != nil {
+		t.Fatalf("directory: ok=%v err=%v", ok, err)
+	}
+	if _, ok, err := ReadUntracked(root, "missing.txt"); ok || err != nil {
+		t.Fatalf("missing file: ok=%v err=%v", ok, err)
+	}
+}
+
+func TestDiffServiceUntrackedSkipsTrackedAndIgnoredFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if os.Getenv("GIT_DIR") != "" {
+		t.Skip("GIT_DIR is set")
+	}
+	root := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = root
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v (%s)", args, err, out)
+		}
+	}
+	for name, body := range map[string]string{"tracked.txt": "a\n", ".gitignore": "*.log\n", "new [draft].txt": "b\n", "debug.log": "c\n"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(body), 0o644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+	run("init", "-q")
+	run("add", "tracked.txt", ".gitignore")
+	run("commit", "-q", "-m", "init")
+
+	svc := NewDiffService()
+	file, ok, err := svc.Untracked(t.Context(), root, "new [draft].txt")
+	if err != nil || !ok || string(file.Content) != "b\n" {
+		t.Fatalf("untracked file: %+v ok=%v err=%v", file, ok, err)
+	}
+	for _, path := range []string{"tracked.txt", "debug.log", "missing.txt"} {
+		if _, ok, err := svc.Untracked(t.Context(), root, path); ok || err != nil {
+			t.Fatalf("%s: expected not untracked, got ok=%v err=%v", path, ok, err)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"diffman/internal/util"
+)
+
+// Worktree is one entry of `git worktree list`.
+type Worktree struct {
+	Path     string
+	Branch   string
+	HeadSHA  string
+	Bare     bool
+	Detached bool
+}
+
+func ListWorktrees(ctx context.Context, cwd string) ([]Worktree, error) {
+	out, err := util.Run(ctx, cwd, "git", "worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+	return parseWorktreePorcelain(out), nil
+}
+
+func parseWorktreePorcelain(out string) []Worktree {
+	var list []Worktree
+	var cur *Worktree
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimRight(line, "\r")
+		switch {
+		case strings.HasPrefix(line, "worktree "):
+			list = append(list, Worktree{Path: strings.TrimPrefix(line, "worktree ")})
+			cur = &list[len(list)-1]
+		case cur == nil:
+		case strings.HasPrefix(line, "HEAD "):
+			cur.HeadSHA = strings.TrimPrefix(line, "HEAD ")
+		case strings.HasPrefix(line, "branch "):
+			cur.Branch = strings.TrimPrefix(strings.TrimPrefix(line, "branch "), "refs/heads/")
+		case line == "bare":
+			cur.Bare = true
+		case line == "detached":
+			cur.Detached = true
+		}
+	}
+	return list
+}
+
+// AbsoluteGitEnv rewrites relative GIT_DIR and GIT_WORK_TREE values as
+// absolute paths. git resolves them against its working directory, and
+// diffman runs git from the repository root rather than where it was started.
+func AbsoluteGitEnv() error {
+	for _, name := range []string{"GIT_DIR", "GIT_WORK_TREE"} {
+		v := os.Getenv(name)
+		if v == "" || filepath.IsAbs(v) {
+			continue
+		}
+		abs, err := filepath.Abs(v)
+		if err != nil {
+			return err
+		}
+		if err := os.Setenv(name, abs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StartDir is where repository discovery begins: repo when given, otherwise
+// the current directory.
+func StartDir(repo string) (string, error) {
+	if strings.TrimSpace(repo) == "" {
+		return os.Getwd()
+	}
+	abs, err := filepath.Abs(repo)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%s is not a directory", repo)
+	}
+	return abs, nil
+}
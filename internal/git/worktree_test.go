@@ -0,0 +1,40 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseWorktreePorcelain(t *testing.T) {
+	out := "worktree /src/app\nHEAD 1111\nbranch refs/heads/main\n\n" +
+		"worktree /src/app-fix\nHEAD 2222\nbranch refs/heads/fix/login\n\n" +
+		"worktree /src/app-probe\nHEAD 3333\ndetached\n"
+
+	got := parseWorktreePorcelain(out)
+	if len(got) != 3 {
+		t.Fatalf("expected 3 worktrees, got %+v", got)
+	}
+	if got[0].Path != "/src/app" || got[0].Branch != "main" || got[0].HeadSHA != "1111" {
+		t.Fatalf("unexpected main worktree: %+v", got[0])
+	}
+	if got[1].Branch != "fix/login" {
+		t.Fatalf("expected branch without refs/heads/, got %q", got[1].Branch)
+	}
+	if !got[2].Detached || got[2].Branch != "" {
+		t.Fatalf("expected detached worktree, got %+v", got[2])
+	}
+}
+
+func TestStartDir(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "f")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if _, err := StartDir(file); err == nil {
+		t.Fatalf("expected error for a file")
+	}
+	if got, err := StartDir(""); err != nil || got == "" {
+		t.Fatalf("expected current directory, got %q (err=%v)", got, err)
+	}
+}
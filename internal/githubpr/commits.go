@@ -0,0 +1,82 @@
+package githubpr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"diffman/internal/util"
+)
+
+// Commit is one commit of a pull request, oldest first.
+type Commit struct {
+	SHA     string
+	Author  string
+	Date    time.Time
+	Message string
+}
+
+type prCommit struct {
+	SHA    string `json:"sha"`
+	Commit struct {
+		Author struct {
+			Name string    `json:"name"`
+			Date time.Time `json:"date"`
+		} `json:"author"`
+		Message string `json:"message"`
+	} `json:"commit"`
+	// Author is the GitHub account, which is null when the commit's email
+	// belongs to none.
+	Author *struct {
+		Login string `json:"login"`
+	} `json:"author"`
+}
+
+func (ghService) ListCommits(ctx context.Context, pr Context) ([]Commit, error) {
+	body, err := util.Run(
+		ctx,
+		"",
+		"gh",
+		"api",
+		"--paginate",
+		"--slurp",
+		fmt.Sprintf("repos/%s/%s/pulls/%d/commits?per_page=100", pr.Owner, pr.Repo, pr.Number),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return parseCommitsJSON([]byte(body))
+}
+
+// parseCommitsJSON reads the PR commits, naming each author by GitHub login
+// when the commit is linked to an account and by git author name otherwise.
+func parseCommitsJSON(body []byte) ([]Commit, error) {
+	var raw []prCommit
+	if err := json.Unmarshal(body, &raw); err != nil {
+		var pages [][]prCommit
+		if err := json.Unmarshal(body, &pages); err != nil {
+			return nil, fmt.Errorf("parse pr commits: %w", err)
+		}
+		// The failed attempt may have filled in empty commits.
+		raw = nil
+		for _, page := range pages {
+			raw = append(raw, page...)
+		}
+	}
+
+	out := make([]Commit, 0, len(raw))
+	for _, rc := range raw {
+		author := rc.Commit.Author.Name
+		if rc.Author != nil && rc.Author.Login != "" {
+			author = rc.Author.Login
+		}
+		out = append(out, Commit{
+			SHA:     rc.SHA,
+			Author:  author,
+			Date:    rc.Commit.Author.Date,
+			Message: rc.Commit.Message,
+		})
+	}
+	return out, nil
+}
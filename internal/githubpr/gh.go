@@ -115,6 +115,7 @@ func (ghService) ResolvePR(ctx context.Context, cwd, input string) (Context, err
 		} `json:"head"`
 		Base struct {
 			Ref string `json:"ref"`
+			SHA string `json:"sha"`
 		} `json:"base"`
 	}
 
@@ -135,6 +136,7 @@ func (ghService) ResolvePR(ctx context.Context, cwd, input string) (Context, err
 		HeadSHA: payload.Head.SHA,
 		HeadRef: payload.Head.Ref,
 		BaseRef: payload.Base.Ref,
+		BaseSHA: payload.Base.SHA,
 	}, nil
 }
 
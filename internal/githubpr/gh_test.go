@@ -112,3 +112,58 @@ func TestBuildSubmitReviewPayload_MapsCommentSides(t *testing.T) {
 		t.Fatalf("expected old-side comment mapped to LEFT, got %q", payload.Comments[1].Side)
 	}
 }
+
+func TestParseReviewCommentsJSON(t *testing.T) {
+	body := `[[{"path":"a.go","line":3,"side":"RIGHT","body":"looks off","user":{"login":"alice"}},
+{"path":"a.go","line":null,"side":"RIGHT","body":"outdated","user":{"login":"bob"}}],
+[{"path":"b.go","line":8,"side":"LEFT","body":"why remove?","user":{"login":"bob"}}]]`
+
+	got, err := parseReviewCommentsJSON([]byte(body))
+	if err != nil {
+		t.Fatalf("parseReviewCommentsJSON returned error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected outdated comment skipped, got %+v", got)
+	}
+	if got[0].Path != "a.go" || got[0].Line != 3 || got[0].Side != comments.SideNew || got[0].Author != "alice" {
+		t.Fatalf("unexpected first comment: %+v", got[0])
+	}
+	if got[1].Side != comments.SideOld || got[1].Author != "bob" {
+		t.Fatalf("unexpected second comment: %+v", got[1])
+	}
+}
+
+func TestMergeReviewQueue(t *testing.T) {
+	requested := `[{"number":5,"title":"Fix","headRefName":"fix","baseRefName":"main"},{"number":8,"title":"Feat"}]`
+	assigned := `[{"number":5,"title":"Fix"},{"number":2,"title":"Old"}]`
+
+	got, err := mergeReviewQueue("acme", "widgets", []byte(requested), []byte(assigned))
+	if err != nil {
+		t.Fatalf("mergeReviewQueue returned error: %v", err)
+	}
+	if len(got) != 3 || got[0].Number != 8 || got[1].Number != 5 || got[2].Number != 2 {
+		t.Fatalf("expected deduplicated newest-first queue, got %+v", got)
+	}
+	if got[1].Reason != "review requested, assigned" || got[1].BaseRef != "main" || got[1].Owner != "acme" {
+		t.Fatalf("unexpected merged entry: %+v", got[1])
+	}
+}
+
+func TestParseCommitsJSON(t *testing.T) {
+	body := `[[{"sha":"abc123","commit":{"author":{"name":"Alice A","date":"2024-05-01T10:00:00Z"},"message":"Split parser\n\nThe old one mixed lexing in."},"author":{"login":"alice"}}],
+[{"sha":"def456","commit":{"author":{"name":"Bob B","date":"2024-05-02T11:00:00Z"},"message":"Fix typo"},"author":null}]]`
+
+	got, err := parseCommitsJSON([]byte(body))
+	if err != nil {
+		t.Fatalf("parseCommitsJSON returned error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected both pages, got %+v", got)
+	}
+	if got[0].SHA != "abc123" || got[0].Author != "alice" || got[0].Message != "Split parser\n\nThe old one mixed lexing in." || got[0].Date.Day() != 1 {
+		t.Fatalf("unexpected first commit: %+v", got[0])
+	}
+	if got[1].Author != "Bob B" {
+		t.Fatalf("expected the git author name without an account, got %+v", got[1])
+	}
+}
@@ -0,0 +1,84 @@
+package githubpr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"diffman/internal/util"
+)
+
+const prListFields = "number,title,url,headRefName,headRefOid,baseRefName"
+
+type prListItem struct {
+	Number      int    `json:"number"`
+	Title       string `json:"title"`
+	URL         string `json:"url"`
+	HeadRefName string `json:"headRefName"`
+	HeadRefOid  string `json:"headRefOid"`
+	BaseRefName string `json:"baseRefName"`
+}
+
+func (ghService) ListReviewQueue(ctx context.Context, cwd string) ([]Summary, error) {
+	owner, repo, err := discoverGitHubRepo(ctx, cwd)
+	if err != nil {
+		return nil, err
+	}
+	slug := owner + "/" + repo
+
+	requested, err := util.Run(ctx, "", "gh", "pr", "list", "--repo", slug, "--search", "review-requested:@me", "--json", prListFields)
+	if err != nil {
+		return nil, err
+	}
+	assigned, err := util.Run(ctx, "", "gh", "pr", "list", "--repo", slug, "--assignee", "@me", "--json", prListFields)
+	if err != nil {
+		return nil, err
+	}
+	return mergeReviewQueue(owner, repo, []byte(requested), []byte(assigned))
+}
+
+// mergeReviewQueue combines the review-requested and assigned PR lists,
+// newest first, noting on each why it is in the queue.
+func mergeReviewQueue(owner, repo string, requested, assigned []byte) ([]Summary, error) {
+	byNumber := make(map[int]*Summary)
+	add := func(body []byte, reason string) error {
+		var items []prListItem
+		if err := json.Unmarshal(body, &items); err != nil {
+			return fmt.Errorf("parse pr list: %w", err)
+		}
+		for _, it := range items {
+			if s, ok := byNumber[it.Number]; ok {
+				s.Reason += ", " + reason
+				continue
+			}
+			byNumber[it.Number] = &Summary{
+				Owner:   owner,
+				Repo:    repo,
+				Number:  it.Number,
+				Title:   it.Title,
+				URL:     it.URL,
+				HeadSHA: it.HeadRefOid,
+				HeadRef: it.HeadRefName,
+				BaseRef: it.BaseRefName,
+				Reason:  reason,
+			}
+		}
+		return nil
+	}
+	if err := add(requested, "review requested"); err != nil {
+		return nil, err
+	}
+	if err := add(assigned, "assigned"); err != nil {
+		return nil, err
+	}
+
+	out := make([]Summary, 0, len(byNumber))
+	for _, s := range byNumber {
+		out = append(out, *s)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Number > out[j].Number
+	})
+	return out, nil
+}
@@ -0,0 +1,93 @@
+package githubpr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"diffman/internal/comments"
+	"diffman/internal/util"
+)
+
+type prReviewComment struct {
+	Path      string    `json:"path"`
+	Line      *int      `json:"line"`
+	Side      string    `json:"side"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+	User      struct {
+		Login string `json:"login"`
+	} `json:"user"`
+}
+
+func (ghService) Checkout(ctx context.Context, cwd string, pr Context) error {
+	_, err := util.Run(
+		ctx,
+		cwd,
+		"gh",
+		"pr",
+		"checkout",
+		strconv.Itoa(pr.Number),
+		"--repo",
+		pr.Owner+"/"+pr.Repo,
+	)
+	return err
+}
+
+func (ghService) ListReviewComments(ctx context.Context, pr Context) ([]comments.Comment, error) {
+	body, err := util.Run(
+		ctx,
+		"",
+		"gh",
+		"api",
+		"--paginate",
+		"--slurp",
+		fmt.Sprintf("repos/%s/%s/pulls/%d/comments?per_page=100", pr.Owner, pr.Repo, pr.Number),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return parseReviewCommentsJSON([]byte(body))
+}
+
+// parseReviewCommentsJSON converts GitHub review comments to local comments
+// carrying their author. Outdated comments, which GitHub reports without a
+// line, are skipped since they no longer anchor to the diff.
+func parseReviewCommentsJSON(body []byte) ([]comments.Comment, error) {
+	var raw []prReviewComment
+	if err := json.Unmarshal(body, &raw); err != nil {
+		var pages [][]prReviewComment
+		if err := json.Unmarshal(body, &pages); err != nil {
+			return nil, fmt.Errorf("parse pr review comments: %w", err)
+		}
+		for _, page := range pages {
+			raw = append(raw, page...)
+		}
+	}
+
+	out := make([]comments.Comment, 0, len(raw))
+	for _, rc := range raw {
+		if rc.Line == nil || rc.Path == "" {
+			continue
+		}
+		side := comments.SideNew
+		if rc.Side == "LEFT" {
+			side = comments.SideOld
+		}
+		author := rc.User.Login
+		if author == "" {
+			author = "unknown"
+		}
+		out = append(out, comments.Comment{
+			Path:      rc.Path,
+			Side:      side,
+			Line:      *rc.Line,
+			Body:      rc.Body,
+			Author:    author,
+			CreatedAt: rc.CreatedAt,
+		})
+	}
+	return out, nil
+}
@@ -16,6 +16,7 @@ type Context struct {
 	HeadSHA string
 	HeadRef string
 	BaseRef string
+	BaseSHA string
 }
 
 type Summary struct {
@@ -27,14 +28,24 @@ type Summary struct {
 	HeadSHA string
 	HeadRef string
 	BaseRef string
+	// Reason says why the PR is in the review queue, e.g. "review requested".
+	Reason string
 }
 
 type Service interface {
 	ListOpenPRs(ctx context.Context, cwd string) ([]Summary, error)
+	// ListReviewQueue returns open PRs that request your review or are assigned to you.
+	ListReviewQueue(ctx context.Context, cwd string) ([]Summary, error)
 	ResolvePR(ctx context.Context, cwd, input string) (Context, error)
 	ListFiles(ctx context.Context, pr Context) ([]gitint.FileItem, error)
 	Diff(ctx context.Context, pr Context, path string) (string, error)
 	SubmitReviewComments(ctx context.Context, pr Context, body, event string, draft []comments.Comment) error
+	// Checkout switches the repository at cwd to the PR's head branch.
+	Checkout(ctx context.Context, cwd string, pr Context) error
+	// ListReviewComments returns the PR's existing line comments with Author set.
+	ListReviewComments(ctx context.Context, pr Context) ([]comments.Comment, error)
+	// ListCommits returns the commits of the PR's branch, oldest first.
+	ListCommits(ctx context.Context, pr Context) ([]Commit, error)
 }
 
 func NewService() Service {
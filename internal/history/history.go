@@ -0,0 +1,185 @@
+// Package history archives finished reviews so they can be reopened later.
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"diffman/internal/comments"
+)
+
+// Review is one archived review: its comments plus what they were made against.
+type Review struct {
+	ID         string    `json:"id"`
+	ArchivedAt time.Time `json:"archived_at"`
+	// Outcome says how the review ended, e.g. "archived" or "submitted (approve)".
+	Outcome  string             `json:"outcome"`
+	Repo     string             `json:"repo"`
+	Branch   string             `json:"branch,omitempty"`
+	BaseSHA  string             `json:"base_sha,omitempty"`
+	HeadSHA  string             `json:"head_sha,omitempty"`
+	PR       *PR                `json:"pr,omitempty"`
+	Comments []comments.Comment `json:"comments"`
+}
+
+// PR identifies the pull request a review was made on.
+type PR struct {
+	Owner   string `json:"owner"`
+	Repo    string `json:"repo"`
+	Number  int    `json:"number"`
+	Title   string `json:"title,omitempty"`
+	URL     string `json:"url,omitempty"`
+	BaseRef string `json:"base_ref,omitempty"`
+	HeadRef string `json:"head_ref,omitempty"`
+}
+
+// Label is a one-line description for lists.
+func (r Review) Label() string {
+	target := r.Branch
+	if r.PR != nil {
+		target = fmt.Sprintf("PR #%d", r.PR.Number)
+		if r.PR.Title != "" {
+			target += " " + r.PR.Title
+		}
+	}
+	if target == "" {
+		target = filepath.Base(r.Repo)
+	}
+	return fmt.Sprintf("%s  %s  %d comment(s), %s", r.ArchivedAt.Format("2006-01-02 15:04"), target, len(r.Comments), r.Outcome)
+}
+
+type Store struct {
+	dir string
+	// key, when set, encrypts the archives as the comment store does.
+	key []byte
+}
+
+func NewStore(gitDir string) Store {
+	return Store{dir: filepath.Join(gitDir, ".diffman", "history")}
+}
+
+// WithKey returns a store that encrypts the archives it writes with key, the
+// comment store's key. Archives written without encryption are still read.
+func (s Store) WithKey(key []byte) Store {
+	s.key = key
+	return s
+}
+
+// Save writes r under a new ID derived from its archive time and returns it.
+func (s Store) Save(r Review) (Review, error) {
+	if r.ArchivedAt.IsZero() {
+		r.ArchivedAt = time.Now()
+	}
+	if s.dir == "" {
+		return r, nil
+	}
+	// Archives hold comment bodies, so only the user may read them.
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return Review{}, err
+	}
+	if err := os.Chmod(s.dir, 0o700); err != nil {
+		return Review{}, err
+	}
+	base := r.ArchivedAt.UTC().Format("20060102-150405")
+	r.ID = base
+	for i := 2; ; i++ {
+		if _, err := os.Stat(s.path(r.ID)); errors.Is(err, os.ErrNotExist) {
+			break
+		}
+		r.ID = fmt.Sprintf("%s-%d", base, i)
+	}
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return Review{}, err
+	}
+	if b, err = comments.Seal(s.key, b); err != nil {
+		return Review{}, err
+	}
+	if err := os.WriteFile(s.path(r.ID), b, 0o600); err != nil {
+		return Review{}, err
+	}
+	return r, nil
+}
+
+// SkippedError reports archives List could not read. The reviews returned
+// with it are the readable ones.
+type SkippedError struct {
+	Files []string
+	// Err is why the first file was skipped.
+	Err error
+}
+
+func (e *SkippedError) Error() string {
+	return fmt.Sprintf("skipped %d unreadable archive(s): %v", len(e.Files), e.Err)
+}
+
+func (e *SkippedError) Unwrap() error {
+	return e.Err
+}
+
+// IsSkipped reports whether err only says that List left out unreadable
+// archives, so the reviews it returned can be used.
+func IsSkipped(err error) bool {
+	var skipped *SkippedError
+	return errors.As(err, &skipped)
+}
+
+// List returns the archived reviews, newest first. Archives that cannot be
+// read or decoded are left out and reported as a *SkippedError.
+func (s Store) List() ([]Review, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	out := make([]Review, 0, len(entries))
+	var skipped *SkippedError
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		r, err := s.read(e.Name())
+		if err != nil {
+			if skipped == nil {
+				skipped = &SkippedError{Err: fmt.Errorf("%s: %w", e.Name(), err)}
+			}
+			skipped.Files = append(skipped.Files, e.Name())
+			continue
+		}
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].ArchivedAt.After(out[j].ArchivedAt)
+	})
+	if skipped != nil {
+		return out, skipped
+	}
+	return out, nil
+}
+
+func (s Store) read(name string) (Review, error) {
+	b, err := os.ReadFile(filepath.Join(s.dir, name))
+	if err != nil {
+		return Review{}, err
+	}
+	if b, err = comments.Open(s.key, b); err != nil {
+		return Review{}, err
+	}
+	var r Review
+	if err := json.Unmarshal(b, &r); err != nil {
+		return Review{}, err
+	}
+	return r, nil
+}
+
+func (s Store) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
@@ -0,0 +1,99 @@
+package history
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"diffman/internal/comments"
+)
+
+func TestStoreSaveAndListNewestFirst(t *testing.T) {
+	store := NewStore(t.TempDir())
+	at := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	first, err := store.Save(Review{ArchivedAt: at, Outcome: "archived", Comments: []comments.Comment{{Path: "a.go", Line: 1, Body: "x"}}})
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	second, err := store.Save(Review{ArchivedAt: at, Outcome: "submitted (approve)", PR: &PR{Owner: "acme", Repo: "widgets", Number: 4}})
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if first.ID != "20260301-100000" || second.ID != "20260301-100000-2" {
+		t.Fatalf("unexpected IDs %q and %q", first.ID, second.ID)
+	}
+	if _, err := store.Save(Review{ArchivedAt: at.Add(time.Hour), Outcome: "archived"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	list, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list) != 3 || !list[0].ArchivedAt.Equal(at.Add(time.Hour)) {
+		t.Fatalf("expected newest first, got %+v", list)
+	}
+	if got := list[1].Label(); got == "" {
+		t.Fatalf("expected a label")
+	}
+}
+
+func TestListWithoutHistory(t *testing.T) {
+	list, err := NewStore(t.TempDir()).List()
+	if err != nil || len(list) != 0 {
+		t.Fatalf("expected empty history, got %+v (err=%v)", list, err)
+	}
+}
+
+func TestStoreWithKeyEncryptsArchives(t *testing.T) {
+	gitDir := t.TempDir()
+	key := make([]byte, comments.KeySize)
+	store := NewStore(gitDir).WithKey(key)
+	r, err := store.Save(Review{Outcome: "archived", Comments: []comments.Comment{{Path: "a.go", Line: 1, Body: "secret plan"}}})
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	b, err := os.ReadFile(store.path(r.ID))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if strings.Contains(string(b), "secret plan") {
+		t.Fatalf("expected the archive encrypted, got:\n%s", b)
+	}
+	for path, want := range map[string]os.FileMode{store.dir: 0o700, store.path(r.ID): 0o600} {
+		if info, err := os.Stat(path); err != nil || info.Mode().Perm() != want {
+			t.Fatalf("%s: mode %v, %v, want %v", path, info.Mode().Perm(), err, want)
+		}
+	}
+
+	list, err := store.List()
+	if err != nil || len(list) != 1 || list[0].Comments[0].Body != "secret plan" {
+		t.Fatalf("expected the archive read back with the key, got %+v, %v", list, err)
+	}
+	if _, err := NewStore(gitDir).List(); !errors.Is(err, comments.ErrLocked) {
+		t.Fatalf("expected ErrLocked without the key, got %v", err)
+	}
+}
+
+func TestListSkipsUnreadableArchives(t *testing.T) {
+	gitDir := t.TempDir()
+	store := NewStore(gitDir)
+	if _, err := store.Save(Review{Outcome: "archived"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := os.WriteFile(store.path("broken"), []byte("not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	list, err := store.List()
+	var skipped *SkippedError
+	if !errors.As(err, &skipped) || !IsSkipped(err) || len(skipped.Files) != 1 || skipped.Files[0] != "broken.json" {
+		t.Fatalf("expected broken.json reported as skipped, got %v", err)
+	}
+	if len(list) != 1 || list[0].Outcome != "archived" {
+		t.Fatalf("expected the readable archive, got %+v", list)
+	}
+}
@@ -0,0 +1,69 @@
+// Package permalink builds host URLs that point at a file line in a commit.
+package permalink
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	// GitHubTemplate links a line of a file in a commit on GitHub.
+	GitHubTemplate = "https://{host}/{repo}/blob/{commit}/{path}#L{line}"
+	// GitLabTemplate links a line of a file in a commit on GitLab.
+	GitLabTemplate = "https://{host}/{repo}/-/blob/{commit}/{path}#L{line}"
+)
+
+// Target is the line a permalink points at. Repo is the repository path on
+// the host, such as "org/repo".
+type Target struct {
+	Host   string
+	Repo   string
+	Commit string
+	Path   string
+	Line   int
+}
+
+// Template picks the URL template for host. Configured templates win, keyed
+// by host; otherwise hosts named like GitHub or GitLab get the built-in ones.
+func Template(templates map[string]string, host string) (string, bool) {
+	host = strings.ToLower(host)
+	if tmpl, ok := templates[host]; ok {
+		return tmpl, tmpl != ""
+	}
+	switch {
+	case strings.Contains(host, "github"):
+		return GitHubTemplate, true
+	case strings.Contains(host, "gitlab"):
+		return GitLabTemplate, true
+	default:
+		return "", false
+	}
+}
+
+// Build expands the template for t.Host. It reports false when the host has
+// no template or the target is incomplete.
+func Build(templates map[string]string, t Target) (string, bool) {
+	if t.Host == "" || t.Repo == "" || t.Commit == "" || t.Path == "" || t.Line <= 0 {
+		return "", false
+	}
+	tmpl, ok := Template(templates, t.Host)
+	if !ok {
+		return "", false
+	}
+	return strings.NewReplacer(
+		"{host}", t.Host,
+		"{repo}", t.Repo,
+		"{commit}", url.PathEscape(t.Commit),
+		"{path}", escapePath(t.Path),
+		"{line}", strconv.Itoa(t.Line),
+	).Replace(tmpl), true
+}
+
+func escapePath(path string) string {
+	parts := strings.Split(path, "/")
+	for i, p := range parts {
+		parts[i] = url.PathEscape(p)
+	}
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,32 @@
+package permalink
+
+import "testing"
+
+func TestBuildUsesHostTemplates(t *testing.T) {
+	target := Target{Host: "github.com", Repo: "org/repo", Commit: "abc123", Path: "dir/my file.go", Line: 21}
+	got, ok := Build(nil, target)
+	if !ok || got != "https://github.com/org/repo/blob/abc123/dir/my%20file.go#L21" {
+		t.Fatalf("Build() = %q, %v", got, ok)
+	}
+
+	target.Host = "gitlab.example.com"
+	target.Repo = "group/sub/repo"
+	got, ok = Build(nil, target)
+	if !ok || got != "https://gitlab.example.com/group/sub/repo/-/blob/abc123/dir/my%20file.go#L21" {
+		t.Fatalf("Build() = %q, %v", got, ok)
+	}
+
+	target.Host = "git.internal"
+	if _, ok := Build(nil, target); ok {
+		t.Fatalf("expected no link for unknown host")
+	}
+	templates := map[string]string{"git.internal": "https://{host}/{repo}/src/{commit}/{path}?line={line}"}
+	got, ok = Build(templates, target)
+	if !ok || got != "https://git.internal/group/sub/repo/src/abc123/dir/my%20file.go?line=21" {
+		t.Fatalf("Build() = %q, %v", got, ok)
+	}
+
+	if _, ok := Build(map[string]string{"git.internal": ""}, target); ok {
+		t.Fatalf("expected empty template to disable links for the host")
+	}
+}
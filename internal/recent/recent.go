@@ -0,0 +1,94 @@
+// Package recent remembers the repositories diffman has opened, across all
+// repositories, so the running UI can switch back to them.
+package recent
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// MaxRepos is how many repositories the list keeps, most recent first.
+const MaxRepos = 20
+
+// Repo is one remembered repository root.
+type Repo struct {
+	Path     string    `json:"path"`
+	OpenedAt time.Time `json:"opened_at"`
+}
+
+type Store struct {
+	path string
+}
+
+// NewStore keeps the list in the file at path. An empty path gives a store
+// that loads nothing and never writes.
+func NewStore(path string) Store {
+	return Store{path: path}
+}
+
+// DefaultPath is $XDG_STATE_HOME/diffman/recent.json, falling back to
+// $HOME/.local/state/diffman/recent.json.
+func DefaultPath() (string, error) {
+	if xdg := strings.TrimSpace(os.Getenv("XDG_STATE_HOME")); xdg != "" {
+		return filepath.Join(xdg, "diffman", "recent.json"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "state", "diffman", "recent.json"), nil
+}
+
+// Load returns the remembered repositories, most recent first.
+func (s Store) Load() ([]Repo, error) {
+	if s.path == "" {
+		return nil, nil
+	}
+	b, err := os.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var out []Repo
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Touch moves dir to the front of the list, opened now.
+func (s Store) Touch(dir string) error {
+	if s.path == "" || dir == "" {
+		return nil
+	}
+	list, err := s.Load()
+	if err != nil {
+		// A corrupt list is not worth failing over; start a fresh one.
+		list = nil
+	}
+	next := []Repo{{Path: filepath.Clean(dir), OpenedAt: time.Now()}}
+	for _, r := range list {
+		if filepath.Clean(r.Path) != next[0].Path {
+			next = append(next, r)
+		}
+	}
+	if len(next) > MaxRepos {
+		next = next[:MaxRepos]
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(next, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, b, 0o644)
+}
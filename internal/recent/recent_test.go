@@ -0,0 +1,68 @@
+package recent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTouchKeepsMostRecentFirst(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "state", "recent.json"))
+	for _, dir := range []string{"/src/a", "/src/b", "/src/a/", "/src/c"} {
+		if err := store.Touch(dir); err != nil {
+			t.Fatalf("Touch(%q) error = %v", dir, err)
+		}
+	}
+
+	got, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	var paths []string
+	for _, r := range got {
+		paths = append(paths, r.Path)
+	}
+	if fmt.Sprint(paths) != "[/src/c /src/a /src/b]" {
+		t.Fatalf("unexpected order %v", paths)
+	}
+}
+
+func TestTouchCapsList(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "recent.json"))
+	for i := 0; i < MaxRepos+5; i++ {
+		if err := store.Touch(fmt.Sprintf("/src/r%d", i)); err != nil {
+			t.Fatalf("Touch() error = %v", err)
+		}
+	}
+	got, _ := store.Load()
+	if len(got) != MaxRepos || got[0].Path != fmt.Sprintf("/src/r%d", MaxRepos+4) {
+		t.Fatalf("expected %d newest repos, got %d starting %+v", MaxRepos, len(got), got[0])
+	}
+}
+
+func TestEmptyPathStoreDoesNothing(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	var store Store
+	if err := store.Touch("/src/a"); err != nil {
+		t.Fatalf("Touch() error = %v", err)
+	}
+	if got, err := store.Load(); err != nil || got != nil {
+		t.Fatalf("Load() = %v, %v", got, err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Fatalf("expected no files written, got %v", entries)
+	}
+}
+
+func TestDefaultPathUsesXDGStateHome(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/tmp/state")
+	got, err := DefaultPath()
+	if err != nil {
+		t.Fatalf("DefaultPath() error = %v", err)
+	}
+	if want := filepath.Join("/tmp/state", "diffman", "recent.json"); got != want {
+		t.Fatalf("DefaultPath()=%q want %q", got, want)
+	}
+}
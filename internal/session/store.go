@@ -0,0 +1,68 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// State is UI state remembered per repository between runs.
+type State struct {
+	FilePaneWidth int `json:"file_pane_width,omitempty"`
+	SplitPercent  int `json:"split_percent,omitempty"`
+	// Checklist holds the review checklist items that are ticked off.
+	Checklist []string `json:"checklist,omitempty"`
+	// Position is where the diff cursor was when diffman last exited.
+	Position *Position `json:"position,omitempty"`
+	// LastExport holds the IDs of the comments the latest export numbered,
+	// in order.
+	LastExport []string `json:"last_export,omitempty"`
+}
+
+// Position is a line in the diff of one file.
+type Position struct {
+	Path string `json:"path"`
+	// Side is "old" for a line only on the old side, "new" otherwise.
+	Side string `json:"side"`
+	Line int    `json:"line"`
+}
+
+type Store struct {
+	path string
+}
+
+func NewStore(gitDir string) Store {
+	return Store{path: filepath.Join(gitDir, ".diffman", "session.json")}
+}
+
+func (s Store) Load() (State, error) {
+	b, err := os.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return State{}, nil
+		}
+		return State{}, err
+	}
+
+	var out State
+	if err := json.Unmarshal(b, &out); err != nil {
+		return State{}, err
+	}
+	return out, nil
+}
+
+func (s Store) Save(state State) error {
+	if s.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, b, 0o644)
+}
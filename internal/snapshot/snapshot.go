@@ -0,0 +1,168 @@
+// Package snapshot records what a review pass covered so a later pass can
+// show only the hunks that changed since.
+package snapshot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"diffman/internal/diffview"
+)
+
+// File is the reviewed state of one file: a hash per hunk, and a file hash
+// over all of them.
+type File struct {
+	Hash  string   `json:"hash"`
+	Hunks []string `json:"hunks"`
+}
+
+type Snapshot struct {
+	TakenAt time.Time       `json:"taken_at"`
+	Files   map[string]File `json:"files"`
+}
+
+// HunkHashes hashes each hunk in rows by its added and removed lines, keyed
+// by HunkID. Line numbers and context are left out so a hunk that only moved,
+// e.g. after a rebase, keeps its hash.
+func HunkHashes(rows []diffview.DiffRow) map[int]string {
+	sums := make(map[int]*strings.Builder)
+	order := make([]int, 0, 8)
+	for _, r := range rows {
+		if r.Kind == diffview.RowHunkHeader || r.Kind == diffview.RowFileHeader {
+			continue
+		}
+		b, ok := sums[r.HunkID]
+		if !ok {
+			b = &strings.Builder{}
+			sums[r.HunkID] = b
+			order = append(order, r.HunkID)
+		}
+		switch r.Kind {
+		case diffview.RowDelete:
+			b.WriteString("-" + r.OldText + "\n")
+		case diffview.RowAdd:
+			b.WriteString("+" + r.NewText + "\n")
+		case diffview.RowChange:
+			b.WriteString("-" + r.OldText + "\n+" + r.NewText + "\n")
+		}
+	}
+	out := make(map[int]string, len(order))
+	for _, id := range order {
+		out[id] = hashString(sums[id].String())
+	}
+	return out
+}
+
+// FileFor builds the reviewed state of a file from its diff rows.
+func FileFor(rows []diffview.DiffRow) File {
+	byID := HunkHashes(rows)
+	hunks := make([]string, 0, len(byID))
+	for _, h := range byID {
+		hunks = append(hunks, h)
+	}
+	sort.Strings(hunks)
+	return File{Hash: hashString(strings.Join(hunks, "\n")), Hunks: hunks}
+}
+
+// Reviewed reports whether path's diff is exactly what the snapshot saw.
+func (s Snapshot) Reviewed(path string, rows []diffview.DiffRow) bool {
+	f, ok := s.Files[path]
+	return ok && f.Hash == FileFor(rows).Hash
+}
+
+// FilterRows drops the hunks of path that the snapshot already covered and
+// returns the remaining rows with the number of hunks dropped.
+func (s Snapshot) FilterRows(path string, rows []diffview.DiffRow) ([]diffview.DiffRow, int) {
+	f, ok := s.Files[path]
+	if !ok {
+		return rows, 0
+	}
+	seen := make(map[string]bool, len(f.Hunks))
+	for _, h := range f.Hunks {
+		seen[h] = true
+	}
+	drop := make(map[int]bool)
+	for id, h := range HunkHashes(rows) {
+		if seen[h] {
+			drop[id] = true
+		}
+	}
+	if len(drop) == 0 {
+		return rows, 0
+	}
+	out := make([]diffview.DiffRow, 0, len(rows))
+	for _, r := range rows {
+		if !drop[r.HunkID] {
+			out = append(out, r)
+		}
+	}
+	return out, len(drop)
+}
+
+func hashString(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Store keeps one snapshot per review target (the working tree or a PR).
+type Store struct {
+	path string
+}
+
+func NewStore(gitDir string) Store {
+	return Store{path: filepath.Join(gitDir, ".diffman", "snapshots.json")}
+}
+
+func (s Store) loadAll() (map[string]Snapshot, error) {
+	b, err := os.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return map[string]Snapshot{}, nil
+		}
+		return nil, err
+	}
+	out := map[string]Snapshot{}
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Load returns the snapshot saved under key, or nil if there is none.
+func (s Store) Load(key string) (*Snapshot, error) {
+	all, err := s.loadAll()
+	if err != nil {
+		return nil, err
+	}
+	snap, ok := all[key]
+	if !ok {
+		return nil, nil
+	}
+	return &snap, nil
+}
+
+func (s Store) Save(key string, snap Snapshot) error {
+	if s.path == "" {
+		return nil
+	}
+	all, err := s.loadAll()
+	if err != nil {
+		return err
+	}
+	all[key] = snap
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, b, 0o644)
+}
@@ -0,0 +1,62 @@
+package snapshot
+
+import (
+	"testing"
+	"time"
+
+	"diffman/internal/diffview"
+)
+
+func mustRows(t *testing.T, diff string) []diffview.DiffRow {
+	t.Helper()
+	rows, err := diffview.ParseUnifiedDiff([]byte(diff))
+	if err != nil {
+		t.Fatalf("ParseUnifiedDiff() error = %v", err)
+	}
+	return rows
+}
+
+const reviewedDiff = "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n" +
+	"@@ -1,2 +1,3 @@\n one\n+two\n three\n" +
+	"@@ -20,2 +21,2 @@\n x\n-old\n+new\n"
+
+func TestFilterRowsDropsReviewedHunksEvenWhenMoved(t *testing.T) {
+	snap := Snapshot{TakenAt: time.Now(), Files: map[string]File{"a.go": FileFor(mustRows(t, reviewedDiff))}}
+
+	// The first hunk is unchanged but shifted; the second one was rewritten.
+	later := mustRows(t, "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n"+
+		"@@ -5,2 +5,3 @@\n one\n+two\n three\n"+
+		"@@ -20,2 +21,2 @@\n x\n-old\n+newer\n")
+	if snap.Reviewed("a.go", later) {
+		t.Fatalf("expected rewritten file to need review")
+	}
+	kept, hidden := snap.FilterRows("a.go", later)
+	if hidden != 1 {
+		t.Fatalf("expected one reviewed hunk hidden, got %d", hidden)
+	}
+	for _, r := range kept {
+		if r.HunkID != 1 {
+			t.Fatalf("expected only the rewritten hunk kept, got %+v", r)
+		}
+	}
+	if !snap.Reviewed("a.go", mustRows(t, reviewedDiff)) {
+		t.Fatalf("expected identical diff to count as reviewed")
+	}
+}
+
+func TestStoreKeepsOneSnapshotPerKey(t *testing.T) {
+	store := NewStore(t.TempDir())
+	if snap, err := store.Load("local"); err != nil || snap != nil {
+		t.Fatalf("expected no snapshot yet, got %+v (err=%v)", snap, err)
+	}
+	if err := store.Save("local", Snapshot{Files: map[string]File{"a.go": {Hash: "1"}}}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := store.Save("pr:acme/widgets#7", Snapshot{Files: map[string]File{"b.go": {Hash: "2"}}}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	snap, err := store.Load("local")
+	if err != nil || snap == nil || snap.Files["a.go"].Hash != "1" {
+		t.Fatalf("unexpected local snapshot %+v (err=%v)", snap, err)
+	}
+}
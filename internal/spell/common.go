@@ -0,0 +1,76 @@
+package spell
+
+// commonMisspellings are flagged even without a system dictionary.
+var commonMisspellings = map[string]string{
+	"accomodate":     "accommodate",
+	"acheive":        "achieve",
+	"accross":        "across",
+	"adress":         "address",
+	"agian":          "again",
+	"alot":           "a lot",
+	"aparent":        "apparent",
+	"apparantly":     "apparently",
+	"arguement":      "argument",
+	"begining":       "beginning",
+	"beleive":        "believe",
+	"calender":       "calendar",
+	"cant":           "can't",
+	"commited":       "committed",
+	"comming":        "coming",
+	"compatability":  "compatibility",
+	"concious":       "conscious",
+	"definately":     "definitely",
+	"dependancy":     "dependency",
+	"dependant":      "dependent",
+	"doesnt":         "doesn't",
+	"dont":           "don't",
+	"enviroment":     "environment",
+	"existance":      "existence",
+	"explicitely":    "explicitly",
+	"finaly":         "finally",
+	"funtion":        "function",
+	"goverment":      "government",
+	"guarentee":      "guarantee",
+	"happend":        "happened",
+	"immediatly":     "immediately",
+	"independant":    "independent",
+	"initalize":      "initialize",
+	"isnt":           "isn't",
+	"lenght":         "length",
+	"neccessary":     "necessary",
+	"necessery":      "necessary",
+	"occured":        "occurred",
+	"occurence":      "occurrence",
+	"paramter":       "parameter",
+	"parrallel":      "parallel",
+	"performace":     "performance",
+	"posible":        "possible",
+	"prefered":       "preferred",
+	"priviledge":     "privilege",
+	"recieve":        "receive",
+	"recieved":       "received",
+	"recomend":       "recommend",
+	"refered":        "referred",
+	"relevent":       "relevant",
+	"repositry":      "repository",
+	"responsability": "responsibility",
+	"retreive":       "retrieve",
+	"seperate":       "separate",
+	"seperately":     "separately",
+	"shoudl":         "should",
+	"succesful":      "successful",
+	"successfull":    "successful",
+	"sucess":         "success",
+	"teh":            "the",
+	"thier":          "their",
+	"threshhold":     "threshold",
+	"tommorow":       "tomorrow",
+	"truely":         "truly",
+	"unneccessary":   "unnecessary",
+	"untill":         "until",
+	"usefull":        "useful",
+	"wich":           "which",
+	"wierd":          "weird",
+	"wont":           "won't",
+	"wouldnt":        "wouldn't",
+}
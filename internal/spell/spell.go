@@ -0,0 +1,202 @@
+// Package spell flags likely typos in comment text.
+package spell
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// systemDictionaries are word lists tried in order when no dictionary is configured.
+var systemDictionaries = []string{
+	"/usr/share/dict/words",
+	"/usr/dict/words",
+	"/usr/share/dict/american-english",
+	"/usr/share/dict/british-english",
+}
+
+// Word is a flagged word in the checked text. Start and End are rune offsets.
+type Word struct {
+	Text       string
+	Start      int
+	End        int
+	Suggestion string
+}
+
+// Checker looks words up in a dictionary. Without one it only flags the
+// bundled list of common misspellings.
+type Checker struct {
+	words map[string]struct{}
+}
+
+// Load builds a checker from path, or from the first system word list found
+// when path is empty. A missing system list is not an error.
+func Load(path string) (*Checker, error) {
+	if path != "" {
+		words, err := readWords(path)
+		if err != nil {
+			return &Checker{}, fmt.Errorf("load dictionary: %w", err)
+		}
+		return &Checker{words: words}, nil
+	}
+	for _, candidate := range systemDictionaries {
+		words, err := readWords(candidate)
+		if err == nil {
+			return &Checker{words: words}, nil
+		}
+	}
+	return &Checker{}, nil
+}
+
+// NewFromWords builds a checker over the given word list.
+func NewFromWords(words []string) *Checker {
+	set := make(map[string]struct{}, len(words))
+	for _, w := range words {
+		set[strings.ToLower(strings.TrimSpace(w))] = struct{}{}
+	}
+	return &Checker{words: set}
+}
+
+// HasDictionary reports whether a word list backs the checker.
+func (c *Checker) HasDictionary() bool {
+	return c != nil && len(c.words) > 0
+}
+
+func readWords(path string) (map[string]struct{}, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	words := make(map[string]struct{}, 100000)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		w := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if w != "" && !strings.HasPrefix(w, "#") {
+			words[w] = struct{}{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return words, nil
+}
+
+// Misspelled returns the words in text that look misspelled. Code spans in
+// backticks, URLs, and identifier-like tokens (digits, underscores, camelCase,
+// ALLCAPS) are skipped.
+func (c *Checker) Misspelled(text string) []Word {
+	if c == nil {
+		return nil
+	}
+	runes := []rune(text)
+	var out []Word
+	inCode := false
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		if r == '`' {
+			inCode = !inCode
+			i++
+			continue
+		}
+		if inCode || unicode.IsSpace(r) {
+			i++
+			continue
+		}
+		end := i
+		for end < len(runes) && !unicode.IsSpace(runes[end]) && runes[end] != '`' {
+			end++
+		}
+		token := string(runes[i:end])
+		if !strings.Contains(token, "://") && !strings.HasPrefix(token, "www.") {
+			out = append(out, c.checkToken(runes[i:end], i)...)
+		}
+		i = end
+	}
+	return out
+}
+
+// checkToken splits a whitespace-delimited token into words on punctuation,
+// keeping tokens glued together by underscores, dots, or slashes whole.
+func (c *Checker) checkToken(token []rune, offset int) []Word {
+	trimmed := strings.TrimFunc(string(token), func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	if strings.ContainsAny(trimmed, "_./\\:=()[]{}<>@#$%&+") {
+		return nil
+	}
+	var out []Word
+	for i := 0; i < len(token); {
+		if !unicode.IsLetter(token[i]) {
+			i++
+			continue
+		}
+		end := i
+		for end < len(token) && (unicode.IsLetter(token[end]) || unicode.IsDigit(token[end]) || (token[end] == '\'' && end+1 < len(token) && unicode.IsLetter(token[end+1]))) {
+			end++
+		}
+		word := string(token[i:end])
+		if suggestion, bad := c.check(word); bad {
+			out = append(out, Word{Text: word, Start: offset + i, End: offset + end, Suggestion: suggestion})
+		}
+		i = end
+	}
+	return out
+}
+
+func (c *Checker) check(word string) (string, bool) {
+	if len([]rune(word)) < 3 || looksLikeIdentifier(word) {
+		return "", false
+	}
+	lower := strings.ToLower(word)
+	if fix, ok := commonMisspellings[lower]; ok {
+		return fix, true
+	}
+	if !c.HasDictionary() {
+		return "", false
+	}
+	if c.known(lower) {
+		return "", false
+	}
+	return "", true
+}
+
+func (c *Checker) known(lower string) bool {
+	if _, ok := c.words[lower]; ok {
+		return true
+	}
+	lower = strings.TrimSuffix(lower, "'s")
+	for _, suffix := range []string{"", "s", "es", "ed", "d", "ing", "ly", "er"} {
+		stem, ok := strings.CutSuffix(lower, suffix)
+		if !ok || stem == "" {
+			continue
+		}
+		if _, ok := c.words[stem]; ok {
+			return true
+		}
+		if suffix == "ing" || suffix == "ed" || suffix == "er" {
+			if _, ok := c.words[stem+"e"]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func looksLikeIdentifier(word string) bool {
+	runes := []rune(word)
+	upper := 0
+	for i, r := range runes {
+		if unicode.IsDigit(r) {
+			return true
+		}
+		if unicode.IsUpper(r) {
+			upper++
+			if i > 0 {
+				return true
+			}
+		}
+	}
+	return upper == len(runes)
+}
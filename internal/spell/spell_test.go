@@ -0,0 +1,51 @@
+package spell
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMisspelledUsesDictionaryAndSkipsCode(t *testing.T) {
+	c := NewFromWords([]string{"rename", "this", "variable", "before", "merge", "use", "and", "the"})
+
+	got := c.Misspelled("Rename this varable before merging, use `fooBar_baz` and teh parseURL helper")
+	want := []Word{
+		{Text: "varable", Start: 12, End: 19},
+		{Text: "teh", Start: 57, End: 60, Suggestion: "the"},
+		{Text: "helper", Start: 70, End: 76},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Misspelled() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Misspelled()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestMisspelledWithoutDictionaryOnlyFlagsCommonTypos(t *testing.T) {
+	c := &Checker{}
+	got := c.Misspelled("we shoudl recieve anything zzqx here")
+	if len(got) != 2 || got[0].Suggestion != "should" || got[1].Suggestion != "receive" {
+		t.Fatalf("Misspelled() = %+v", got)
+	}
+}
+
+func TestLoadReadsDictionaryFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words")
+	if err := os.WriteFile(path, []byte("Hello\nworld\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := c.Misspelled("hello worlds wrold"); len(got) != 1 || got[0].Text != "wrold" {
+		t.Fatalf("Misspelled() = %+v", got)
+	}
+	if _, err := Load(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Fatalf("expected error for missing dictionary")
+	}
+}
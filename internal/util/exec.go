@@ -2,9 +2,14 @@ package util
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os/exec"
 	"strings"
+	"sync"
+	"time"
+
+	"diffman/internal/debuglog"
 )
 
 func Run(ctx context.Context, cwd string, name string, args ...string) (string, error) {
@@ -13,10 +18,85 @@ func Run(ctx context.Context, cwd string, name string, args ...string) (string,
 		cmd.Dir = cwd
 	}
 
+	start := time.Now()
 	out, err := cmd.CombinedOutput()
+	logCommand(start, cwd, name, args, len(out), err)
 	if err != nil {
-		return "", fmt.Errorf("command failed: %s %s: %w (%s)", name, strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+		return "", &CommandError{Name: name, Args: args, Output: string(out), Err: err}
 	}
 
 	return string(out), nil
 }
+
+// CommandError is returned when an external command fails, keeping the
+// command line and what it printed for error displays.
+type CommandError struct {
+	Name string
+	Args []string
+	// Output is everything the command printed, stdout and stderr.
+	Output string
+	Err    error
+}
+
+func (e *CommandError) Error() string {
+	return fmt.Sprintf("command failed: %s: %v (%s)", e.CommandLine(), e.Err, strings.TrimSpace(e.Output))
+}
+
+// ExitCode is the command's exit status, or -1 when it did not exit.
+func (e *CommandError) ExitCode() int {
+	var exitErr *exec.ExitError
+	if errors.As(e.Err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+// CommandLine is the failed command with its arguments.
+func (e *CommandError) CommandLine() string {
+	return strings.TrimSpace(e.Name + " " + strings.Join(e.Args, " "))
+}
+
+// CommandStat describes a finished external command.
+type CommandStat struct {
+	Name     string
+	Args     []string
+	Duration time.Duration
+	Err      error
+}
+
+var (
+	lastMu      sync.Mutex
+	lastCommand CommandStat
+	lastGit     CommandStat
+)
+
+// LastCommand returns the most recently finished command, and the most
+// recent git command, for the performance overlay.
+func LastCommand() (last, git CommandStat) {
+	lastMu.Lock()
+	defer lastMu.Unlock()
+	return lastCommand, lastGit
+}
+
+// logCommand remembers a finished command and records it in the debug log.
+func logCommand(start time.Time, cwd, name string, args []string, outBytes int, err error) {
+	stat := CommandStat{Name: name, Args: args, Duration: time.Since(start), Err: err}
+	lastMu.Lock()
+	lastCommand = stat
+	if name == "git" {
+		lastGit = stat
+	}
+	lastMu.Unlock()
+	if !debuglog.Enabled() {
+		return
+	}
+	result := fmt.Sprintf("%d bytes", outBytes)
+	if err != nil {
+		result = "error: " + err.Error()
+	}
+	debuglog.Printf("exec %s %s (in %s): %s, %s", name, strings.Join(args, " "), cwd, result, stat.Duration.Round(time.Microsecond))
+}
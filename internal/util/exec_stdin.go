@@ -2,9 +2,9 @@ package util
 
 import (
 	"context"
-	"fmt"
 	"os/exec"
 	"strings"
+	"time"
 )
 
 func RunWithStdin(ctx context.Context, cwd, stdin, name string, args ...string) (string, error) {
@@ -14,9 +14,11 @@ func RunWithStdin(ctx context.Context, cwd, stdin, name string, args ...string)
 	}
 	cmd.Stdin = strings.NewReader(stdin)
 
+	start := time.Now()
 	out, err := cmd.CombinedOutput()
+	logCommand(start, cwd, name, args, len(out), err)
 	if err != nil {
-		return "", fmt.Errorf("command failed: %s %s: %w (%s)", name, strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+		return "", &CommandError{Name: name, Args: args, Output: string(out), Err: err}
 	}
 	return string(out), nil
 }
@@ -0,0 +1,70 @@
+// Package webhook posts review exports to a chat webhook.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout bounds a publish when the caller's context has no deadline.
+const DefaultTimeout = 15 * time.Second
+
+// payload is the Slack incoming-webhook shape, which Mattermost, Rocket.Chat,
+// and most generic receivers also accept.
+type payload struct {
+	Text string `json:"text"`
+}
+
+// Publish POSTs text to webhookURL as {"text": ...}. Non-2xx responses are
+// errors that include the start of the response body.
+func Publish(ctx context.Context, client *http.Client, webhookURL, text string) error {
+	if client == nil {
+		client = &http.Client{Timeout: DefaultTimeout}
+	}
+	body, err := json.Marshal(payload{Text: text})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		// The URL usually embeds a secret token; report only the host.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("post to %s: %w", Host(webhookURL), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		msg := strings.TrimSpace(string(snippet))
+		if msg == "" {
+			return fmt.Errorf("%s returned %s", Host(webhookURL), resp.Status)
+		}
+		return fmt.Errorf("%s returned %s: %s", Host(webhookURL), resp.Status, msg)
+	}
+	return nil
+}
+
+// Host is the host part of webhookURL, safe to show where the full URL is a secret.
+func Host(webhookURL string) string {
+	u, err := url.Parse(webhookURL)
+	if err != nil || u.Host == "" {
+		return "webhook"
+	}
+	return u.Host
+}
@@ -0,0 +1,51 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPublishPostsSlackPayload(t *testing.T) {
+	var got payload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected request %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	if err := Publish(context.Background(), srv.Client(), srv.URL+"/services/T000/SECRET", "Review comments:"); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if got.Text != "Review comments:" {
+		t.Fatalf("expected export text in payload, got %q", got.Text)
+	}
+}
+
+func TestPublishReportsStatusWithoutLeakingURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_token", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	err := Publish(context.Background(), srv.Client(), srv.URL+"/services/SECRET", "x")
+	if err == nil || !strings.Contains(err.Error(), "403") || !strings.Contains(err.Error(), "invalid_token") {
+		t.Fatalf("expected status error, got %v", err)
+	}
+	if strings.Contains(err.Error(), "SECRET") {
+		t.Fatalf("error leaks the webhook path: %v", err)
+	}
+
+	err = Publish(context.Background(), srv.Client(), "http://127.0.0.1:1/services/SECRET", "x")
+	if err == nil || strings.Contains(err.Error(), "SECRET") {
+		t.Fatalf("expected connection error without the webhook path, got %v", err)
+	}
+}